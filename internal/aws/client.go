@@ -3,19 +3,61 @@ package aws
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/openshift-online/regional-cli/pkg/dualstack"
+	"github.com/openshift-online/regional-cli/pkg/proxy"
+	"github.com/openshift-online/regional-cli/pkg/ratelimit"
+	"github.com/openshift-online/regional-cli/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ClientConfig holds AWS client configuration options
 type ClientConfig struct {
 	Profile string
 	Region  string
+	RoleARN string // Optional: assume this role using the base credentials
+
+	// UseDualStack resolves dual-stack (IPv4 and IPv6) service endpoints and dials
+	// them IPv6-first, for networks without IPv4 egress
+	UseDualStack bool
+
+	// Proxy is the HTTP(S) proxy URL for every request made with the config. When
+	// empty, HTTPS_PROXY, HTTP_PROXY, and NO_PROXY from the environment apply.
+	Proxy string
+
+	// RateLimits throttles requests per service on the client side; nil applies
+	// ratelimit.DefaultLimits. Clients built from the config share the limits.
+	RateLimits ratelimit.Limits
+
+	// MaxRetries is the number of times a failed request is retried; nil keeps the
+	// SDK default of 2 retries (3 attempts) and 0 disables retries
+	MaxRetries *int
+
+	// RequestTimeout bounds each attempt of a request, including reading the response,
+	// so a stalled connection is retried instead of hanging; zero means no limit
+	RequestTimeout time.Duration
+
+	// TracerProvider records a span for each API call made with the config; nil
+	// disables tracing
+	TracerProvider trace.TracerProvider
 }
 
 // NewConfig creates an AWS SDK v2 config from the provided options
@@ -30,14 +72,82 @@ func NewConfig(ctx context.Context, cfg ClientConfig) (aws.Config, error) {
 		opts = append(opts, config.WithRegion(cfg.Region))
 	}
 
+	var proxyURL *url.URL
+	if cfg.Proxy != "" {
+		var err error
+		if proxyURL, err = proxy.Parse(cfg.Proxy); err != nil {
+			return aws.Config{}, err
+		}
+	}
+
+	// Clients built from the config, including credential providers, share this HTTP
+	// client, so the proxy applies uniformly
+	opts = append(opts, config.WithHTTPClient(awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		tr.Proxy = proxy.New(proxyURL)
+		if cfg.UseDualStack {
+			tr.DialContext = dualstack.NewDialer().DialContext
+		}
+	}).WithTimeout(cfg.RequestTimeout)))
+
+	if cfg.MaxRetries != nil {
+		opts = append(opts, config.WithRetryMaxAttempts(*cfg.MaxRetries+1))
+	}
+
+	if cfg.UseDualStack {
+		opts = append(opts, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+
 	awsCfg, err := config.LoadDefaultConfig(ctx, opts...)
 	if err != nil {
 		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
+	// The SDK only reads region from the selected profile itself; like the AWS CLI,
+	// fall back to the profiles it chains to. The sso_region of an SSO session is
+	// where IAM Identity Center runs, not where to deploy, so it is never used.
+	if awsCfg.Region == "" {
+		awsCfg.Region = chainedRegion(awsCfg.ConfigSources)
+	}
+
+	limits := cfg.RateLimits
+	if limits == nil {
+		limits = ratelimit.DefaultLimits()
+	}
+	awsCfg.APIOptions = append(awsCfg.APIOptions, ratelimit.New(limits).AddMiddleware)
+
+	if cfg.TracerProvider != nil {
+		awsCfg.APIOptions = append(awsCfg.APIOptions, telemetry.AWSMiddleware(cfg.TracerProvider))
+	}
+
+	if cfg.RoleARN != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), cfg.RoleARN,
+			func(o *stscreds.AssumeRoleOptions) {
+				o.RoleSessionName = "rosactl"
+			})
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+
 	return awsCfg, nil
 }
 
+// chainedRegion returns the region of the first profile in the source_profile chain
+// of the loaded shared config profile that sets one
+func chainedRegion(sources []interface{}) string {
+	for _, source := range sources {
+		sharedCfg, ok := source.(config.SharedConfig)
+		if !ok {
+			continue
+		}
+		for profile := &sharedCfg; profile != nil; profile = profile.Source {
+			if profile.Region != "" {
+				return profile.Region
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
 // NewLambdaClient creates a new Lambda client
 func NewLambdaClient(cfg aws.Config) LambdaAPI {
 	return lambda.NewFromConfig(cfg)
@@ -57,3 +167,48 @@ func NewSTSClient(cfg aws.Config) STSAPI {
 func NewCloudWatchLogsClient(cfg aws.Config) CloudWatchLogsAPI {
 	return cloudwatchlogs.NewFromConfig(cfg)
 }
+
+// NewCloudWatchClient creates a new CloudWatch Metrics client
+func NewCloudWatchClient(cfg aws.Config) CloudWatchAPI {
+	return cloudwatch.NewFromConfig(cfg)
+}
+
+// NewLogsInsightsClient creates a new CloudWatch Logs client for Logs Insights queries
+func NewLogsInsightsClient(cfg aws.Config) LogsInsightsAPI {
+	return cloudwatchlogs.NewFromConfig(cfg)
+}
+
+// NewLogEventsClient creates a new CloudWatch Logs client for reading log events
+func NewLogEventsClient(cfg aws.Config) LogEventsAPI {
+	return cloudwatchlogs.NewFromConfig(cfg)
+}
+
+// NewOrganizationsClient creates a new AWS Organizations client
+func NewOrganizationsClient(cfg aws.Config) OrganizationsAPI {
+	return organizations.NewFromConfig(cfg)
+}
+
+// NewSSMClient creates a new SSM client
+func NewSSMClient(cfg aws.Config) SSMAPI {
+	return ssm.NewFromConfig(cfg)
+}
+
+// NewS3Client creates a new S3 client
+func NewS3Client(cfg aws.Config) S3API {
+	return s3.NewFromConfig(cfg)
+}
+
+// NewCloudTrailClient creates a new CloudTrail client for the config's region
+func NewCloudTrailClient(cfg aws.Config) CloudTrailAPI {
+	return cloudtrail.NewFromConfig(cfg)
+}
+
+// NewConfigRulesClient creates a new AWS Config client for the config's region
+func NewConfigRulesClient(cfg aws.Config) ConfigRulesAPI {
+	return configservice.NewFromConfig(cfg)
+}
+
+// NewDynamoDBClient creates a new DynamoDB client
+func NewDynamoDBClient(cfg aws.Config) DynamoDBAPI {
+	return dynamodb.NewFromConfig(cfg)
+}
@@ -2,7 +2,18 @@ package aws
 
 import (
 	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/openshift-online/regional-cli/pkg/proxy"
+	"github.com/openshift-online/regional-cli/pkg/ratelimit"
+	"go.opentelemetry.io/otel/trace/noop"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,6 +65,219 @@ func TestNewConfig(t *testing.T) {
 	}
 }
 
+func TestNewConfig_AssumeRole(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), ClientConfig{
+		Region:  "us-east-1",
+		RoleARN: "arn:aws:iam::123456789012:role/clm-service-role",
+	})
+	require.NoError(t, err)
+
+	// Assumed-role credentials are wrapped in a cache so STS is only called on expiry
+	_, ok := cfg.Credentials.(*aws.CredentialsCache)
+	assert.True(t, ok)
+}
+
+func TestNewConfig_Proxy(t *testing.T) {
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("no_proxy", "")
+	cfg, err := NewConfig(context.Background(), ClientConfig{
+		Region: "us-east-1",
+		Proxy:  "proxy.corp:3128",
+	})
+	require.NoError(t, err)
+
+	// Every client built from the config shares its proxy
+	proxyURL, err := proxy.ForURL(proxy.FromConfig(cfg), "https://lambda.us-east-1.amazonaws.com")
+	require.NoError(t, err)
+	require.NotNil(t, proxyURL)
+	assert.Equal(t, "http://proxy.corp:3128", proxyURL.String())
+
+	_, err = NewConfig(context.Background(), ClientConfig{Region: "us-east-1", Proxy: "ftp://proxy.corp"})
+	assert.Error(t, err)
+}
+
+func TestNewConfig_RateLimits(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), ClientConfig{Region: "us-east-1"})
+	require.NoError(t, err)
+	assert.Len(t, cfg.APIOptions, 1, "default limits are applied")
+
+	cfg, err = NewConfig(context.Background(), ClientConfig{
+		Region:     "us-east-1",
+		RateLimits: ratelimit.Limits{"iam": {RPS: 1}},
+	})
+	require.NoError(t, err)
+	assert.Len(t, cfg.APIOptions, 1)
+}
+
+func TestNewConfig_Tracing(t *testing.T) {
+	cfg, err := NewConfig(context.Background(), ClientConfig{
+		Region:         "us-east-1",
+		TracerProvider: noop.NewTracerProvider(),
+	})
+	require.NoError(t, err)
+	assert.Len(t, cfg.APIOptions, 2, "rate limiting and tracing")
+}
+
+func TestNewConfig_Retries(t *testing.T) {
+	t.Setenv("AWS_MAX_ATTEMPTS", "")
+	cfg, err := NewConfig(context.Background(), ClientConfig{Region: "us-east-1"})
+	require.NoError(t, err)
+	assert.Zero(t, cfg.RetryMaxAttempts, "the SDK default applies")
+
+	for retries, attempts := range map[int]int{0: 1, 5: 6} {
+		cfg, err := NewConfig(context.Background(), ClientConfig{Region: "us-east-1", MaxRetries: &retries})
+		require.NoError(t, err)
+		assert.Equal(t, attempts, cfg.RetryMaxAttempts)
+	}
+}
+
+// useSharedConfig points the SDK at synthetic shared config and credentials files,
+// clearing the environment that would take precedence over them
+func useSharedConfig(t *testing.T, configFile, credentialsFile string) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte(configFile), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "credentials"), []byte(credentialsFile), 0600))
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	for _, name := range []string{"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION", "AWS_RETRY_MODE", "AWS_MAX_ATTEMPTS",
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"} {
+		t.Setenv(name, "")
+	}
+}
+
+func TestNewConfig_SharedConfigRetries(t *testing.T) {
+	useSharedConfig(t, `
+[profile flaky]
+region = us-east-1
+retry_mode = adaptive
+max_attempts = 7
+`, "")
+
+	cfg, err := NewConfig(context.Background(), ClientConfig{Profile: "flaky"})
+	require.NoError(t, err)
+	assert.Equal(t, aws.RetryModeAdaptive, cfg.RetryMode)
+	assert.Equal(t, 7, cfg.RetryMaxAttempts)
+
+	t.Setenv("AWS_RETRY_MODE", "standard")
+	t.Setenv("AWS_MAX_ATTEMPTS", "4")
+	cfg, err = NewConfig(context.Background(), ClientConfig{Profile: "flaky"})
+	require.NoError(t, err)
+	assert.Equal(t, aws.RetryModeStandard, cfg.RetryMode, "the environment overrides the profile")
+	assert.Equal(t, 4, cfg.RetryMaxAttempts)
+
+	retries := 1
+	cfg, err = NewConfig(context.Background(), ClientConfig{Profile: "flaky", MaxRetries: &retries})
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.RetryMaxAttempts, "--max-retries overrides both")
+}
+
+func TestNewConfig_ProfileRegion(t *testing.T) {
+	useSharedConfig(t, `
+[profile base]
+region = us-west-2
+
+[profile admin]
+source_profile = base
+role_arn = arn:aws:iam::123456789012:role/admin
+
+[profile nested]
+source_profile = admin
+role_arn = arn:aws:iam::123456789012:role/nested
+
+[profile pinned]
+source_profile = base
+role_arn = arn:aws:iam::123456789012:role/admin
+region = eu-west-1
+
+[sso-session corp]
+sso_region = eu-central-1
+sso_start_url = https://corp.awsapps.com/start
+
+[profile sso]
+sso_session = corp
+sso_account_id = 123456789012
+sso_role_name = Admin
+region = ap-southeast-2
+
+[profile sso-session-region]
+sso_session = corp
+sso_account_id = 123456789012
+sso_role_name = Admin
+`, `
+[base]
+aws_access_key_id = AKIDBASE
+aws_secret_access_key = secret
+`)
+
+	tests := []struct {
+		name     string
+		cfg      ClientConfig
+		expected string
+	}{
+		{"own region", ClientConfig{Profile: "base"}, "us-west-2"},
+		{"source profile region", ClientConfig{Profile: "admin"}, "us-west-2"},
+		{"nested source profiles", ClientConfig{Profile: "nested"}, "us-west-2"},
+		{"region overrides source profile", ClientConfig{Profile: "pinned"}, "eu-west-1"},
+		{"sso profile region", ClientConfig{Profile: "sso"}, "ap-southeast-2"},
+		{"sso region is not a deployment region", ClientConfig{Profile: "sso-session-region"}, ""},
+		{"flag overrides profile", ClientConfig{Profile: "admin", Region: "us-east-2"}, "us-east-2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := NewConfig(context.Background(), tt.cfg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.Region)
+		})
+	}
+
+	t.Run("AWS_PROFILE", func(t *testing.T) {
+		t.Setenv("AWS_PROFILE", "nested")
+		cfg, err := NewConfig(context.Background(), ClientConfig{})
+		require.NoError(t, err)
+		assert.Equal(t, "us-west-2", cfg.Region)
+	})
+}
+
+func TestNewConfig_CredentialProcess(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "credentials.sh")
+	require.NoError(t, os.WriteFile(script,
+		[]byte("#!/bin/sh\necho '{\"Version\": 1, \"AccessKeyId\": \"AKIDPROCESS\", \"SecretAccessKey\": \"secret\"}'\n"), 0700))
+	useSharedConfig(t, "[profile process]\ncredential_process = "+script+"\nregion = ca-central-1\n", "")
+
+	cfg, err := NewConfig(context.Background(), ClientConfig{Profile: "process"})
+	require.NoError(t, err)
+	assert.Equal(t, "ca-central-1", cfg.Region)
+
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKIDPROCESS", creds.AccessKeyID)
+}
+
+func TestNewConfig_RequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	cfg, err := NewConfig(context.Background(), ClientConfig{
+		Region:         "us-east-1",
+		RequestTimeout: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = cfg.HTTPClient.Do(req)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
+
 func TestNewClients(t *testing.T) {
 	ctx := context.Background()
 	cfg, err := NewConfig(ctx, ClientConfig{Region: "us-east-1"})
@@ -78,4 +302,9 @@ func TestNewClients(t *testing.T) {
 		client := NewCloudWatchLogsClient(cfg)
 		assert.NotNil(t, client)
 	})
+
+	t.Run("NewOrganizationsClient", func(t *testing.T) {
+		client := NewOrganizationsClient(cfg)
+		assert.NotNil(t, client)
+	})
 }
@@ -3,9 +3,16 @@ package aws
 import (
 	"context"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
@@ -19,12 +26,46 @@ type LambdaAPI interface {
 		optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error)
 	GetFunction(ctx context.Context, params *lambda.GetFunctionInput,
 		optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
+	ListFunctions(ctx context.Context, params *lambda.ListFunctionsInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error)
 	AddPermission(ctx context.Context, params *lambda.AddPermissionInput,
 		optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error)
+	RemovePermission(ctx context.Context, params *lambda.RemovePermissionInput,
+		optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error)
+	GetPolicy(ctx context.Context, params *lambda.GetPolicyInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error)
 	Invoke(ctx context.Context, params *lambda.InvokeInput,
 		optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
 	TagResource(ctx context.Context, params *lambda.TagResourceInput,
 		optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error)
+	PublishVersion(ctx context.Context, params *lambda.PublishVersionInput,
+		optFns ...func(*lambda.Options)) (*lambda.PublishVersionOutput, error)
+	ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error)
+	ListAliases(ctx context.Context, params *lambda.ListAliasesInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error)
+	DeleteFunction(ctx context.Context, params *lambda.DeleteFunctionInput,
+		optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error)
+	GetAlias(ctx context.Context, params *lambda.GetAliasInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error)
+	CreateAlias(ctx context.Context, params *lambda.CreateAliasInput,
+		optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error)
+	UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput,
+		optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error)
+	GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error)
+	GetFunctionConcurrency(ctx context.Context, params *lambda.GetFunctionConcurrencyInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetFunctionConcurrencyOutput, error)
+	PutFunctionConcurrency(ctx context.Context, params *lambda.PutFunctionConcurrencyInput,
+		optFns ...func(*lambda.Options)) (*lambda.PutFunctionConcurrencyOutput, error)
+	GetCodeSigningConfig(ctx context.Context, params *lambda.GetCodeSigningConfigInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetCodeSigningConfigOutput, error)
+	ListCodeSigningConfigs(ctx context.Context, params *lambda.ListCodeSigningConfigsInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListCodeSigningConfigsOutput, error)
+	GetFunctionCodeSigningConfig(ctx context.Context, params *lambda.GetFunctionCodeSigningConfigInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetFunctionCodeSigningConfigOutput, error)
+	PutFunctionCodeSigningConfig(ctx context.Context, params *lambda.PutFunctionCodeSigningConfigInput,
+		optFns ...func(*lambda.Options)) (*lambda.PutFunctionCodeSigningConfigOutput, error)
 }
 
 // IAMAPI defines testable IAM operations
@@ -35,6 +76,20 @@ type IAMAPI interface {
 		optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
 	PutRolePolicy(ctx context.Context, params *iam.PutRolePolicyInput,
 		optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error)
+	UpdateAssumeRolePolicy(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error)
+	TagRole(ctx context.Context, params *iam.TagRoleInput,
+		optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error)
+	ListRoleTags(ctx context.Context, params *iam.ListRoleTagsInput,
+		optFns ...func(*iam.Options)) (*iam.ListRoleTagsOutput, error)
+	GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+	DeleteRolePolicy(ctx context.Context, params *iam.DeleteRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error)
+	DeleteRole(ctx context.Context, params *iam.DeleteRoleInput,
+		optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error)
+	ListAccountAliases(ctx context.Context, params *iam.ListAccountAliasesInput,
+		optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error)
 	CreateOpenIDConnectProvider(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
 		optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error)
 	GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
@@ -43,6 +98,12 @@ type IAMAPI interface {
 		optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error)
 	ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
 		optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error)
+	ListOpenIDConnectProviderTags(ctx context.Context, params *iam.ListOpenIDConnectProviderTagsInput,
+		optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProviderTagsOutput, error)
+	DeleteOpenIDConnectProvider(ctx context.Context, params *iam.DeleteOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.DeleteOpenIDConnectProviderOutput, error)
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput,
+		optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
 }
 
 // STSAPI defines testable STS operations
@@ -61,4 +122,89 @@ type CloudWatchLogsAPI interface {
 		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
 	TagLogGroup(ctx context.Context, params *cloudwatchlogs.TagLogGroupInput,
 		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagLogGroupOutput, error)
+	PutDataProtectionPolicy(ctx context.Context, params *cloudwatchlogs.PutDataProtectionPolicyInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutDataProtectionPolicyOutput, error)
+	DeleteLogGroup(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error)
+	ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+}
+
+// CloudWatchAPI defines testable CloudWatch Metrics operations
+type CloudWatchAPI interface {
+	GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput,
+		optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// LogsInsightsAPI defines testable CloudWatch Logs Insights operations
+type LogsInsightsAPI interface {
+	StartQuery(ctx context.Context, params *cloudwatchlogs.StartQueryInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error)
+	GetQueryResults(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error)
+	StopQuery(ctx context.Context, params *cloudwatchlogs.StopQueryInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error)
+}
+
+// LogEventsAPI defines testable CloudWatch Logs operations for reading log events
+type LogEventsAPI interface {
+	FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error)
+}
+
+// OrganizationsAPI defines testable AWS Organizations operations
+type OrganizationsAPI interface {
+	DescribeOrganization(ctx context.Context, params *organizations.DescribeOrganizationInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error)
+	DescribeAccount(ctx context.Context, params *organizations.DescribeAccountInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeAccountOutput, error)
+	DescribeEffectivePolicy(ctx context.Context, params *organizations.DescribeEffectivePolicyInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeEffectivePolicyOutput, error)
+}
+
+// CloudTrailAPI defines testable CloudTrail operations
+type CloudTrailAPI interface {
+	LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput,
+		optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error)
+}
+
+// ConfigRulesAPI defines testable AWS Config operations
+type ConfigRulesAPI interface {
+	DescribeConfigRules(ctx context.Context, params *configservice.DescribeConfigRulesInput,
+		optFns ...func(*configservice.Options)) (*configservice.DescribeConfigRulesOutput, error)
+}
+
+// S3API defines testable S3 operations
+type S3API interface {
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput,
+		optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput,
+		optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// SSMAPI defines testable SSM Parameter Store operations
+type SSMAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput,
+		optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput,
+		optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+// DynamoDBAPI defines testable DynamoDB operations for managing and reading the
+// provisioner's ledger table
+type DynamoDBAPI interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
+	ListTagsOfResource(ctx context.Context, params *dynamodb.ListTagsOfResourceInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error)
+	TagResource(ctx context.Context, params *dynamodb.TagResourceInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.TagResourceOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
 }
@@ -0,0 +1,160 @@
+// Package checkpoint records the progress of long multi-step commands, such as
+// onboard, so a rerun resumes after the last completed step instead of repeating work
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Checkpoint records the steps one run of an operation has completed
+type Checkpoint struct {
+	Operation string    `json:"operation"` // Command that is running, e.g. "onboard"
+	Key       string    `json:"key"`       // What it operates on, e.g. "us-east-1/rosa-oidc-provisioner"
+	Completed []string  `json:"completed"` // Completed steps, in the order they completed
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Done reports whether step has completed
+func (c *Checkpoint) Done(step string) bool {
+	return slices.Contains(c.Completed, step)
+}
+
+// Complete records step as completed
+func (c *Checkpoint) Complete(step string) {
+	if !c.Done(step) {
+		c.Completed = append(c.Completed, step)
+	}
+}
+
+// Store persists checkpoints as JSON files on disk
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// NewStore creates a checkpoint store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{
+		dir: dir,
+		now: time.Now,
+	}
+}
+
+// Save writes c, replacing any previous checkpoint of the same operation and key
+func (s *Store) Save(c *Checkpoint) error {
+	if c.Operation == "" || c.Key == "" {
+		return fmt.Errorf("checkpoint requires an operation and key")
+	}
+
+	c.UpdatedAt = s.now().UTC()
+	if c.StartedAt.IsZero() {
+		c.StartedAt = c.UpdatedAt
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+
+	// Write atomically so an interrupted save never corrupts the previous checkpoint
+	tmpFile, err := os.CreateTemp(s.dir, "checkpoint-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), s.path(c.Operation, c.Key)); err != nil {
+		return fmt.Errorf("failed to store checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Load returns the checkpoint of operation on key, or nil if none is recorded
+func (s *Store) Load(operation, key string) (*Checkpoint, error) {
+	data, err := os.ReadFile(s.path(operation, key))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var c Checkpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	return &c, nil
+}
+
+// Delete removes the checkpoint of operation on key. Deleting a missing checkpoint is not an error.
+func (s *Store) Delete(operation, key string) error {
+	if err := os.Remove(s.path(operation, key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// List returns all recorded checkpoints sorted by operation and key
+func (s *Store) List() ([]*Checkpoint, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read checkpoint directory: %w", err)
+	}
+
+	var checkpoints []*Checkpoint
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint %s: %w", entry.Name(), err)
+		}
+
+		var c Checkpoint
+		if err := json.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("failed to parse checkpoint %s: %w", entry.Name(), err)
+		}
+		checkpoints = append(checkpoints, &c)
+	}
+
+	sort.Slice(checkpoints, func(i, j int) bool {
+		if checkpoints[i].Operation != checkpoints[j].Operation {
+			return checkpoints[i].Operation < checkpoints[j].Operation
+		}
+		return checkpoints[i].Key < checkpoints[j].Key
+	})
+	return checkpoints, nil
+}
+
+// path returns the file path for the checkpoint of operation on key. The key is
+// escaped since it may contain path separators.
+func (s *Store) path(operation, key string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s_%s.json", operation, url.PathEscape(key)))
+}
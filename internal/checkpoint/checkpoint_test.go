@@ -0,0 +1,77 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	store.now = func() time.Time { return started }
+
+	c := &Checkpoint{Operation: "onboard", Key: "us-east-1/rosa-oidc-provisioner"}
+	c.Complete("validate")
+	require.NoError(t, store.Save(c))
+
+	updated := started.Add(time.Minute)
+	store.now = func() time.Time { return updated }
+	c.Complete("deploy")
+	c.Complete("deploy")
+	require.NoError(t, store.Save(c))
+
+	loaded, err := store.Load("onboard", "us-east-1/rosa-oidc-provisioner")
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, []string{"validate", "deploy"}, loaded.Completed)
+	assert.True(t, loaded.Done("deploy"))
+	assert.False(t, loaded.Done("smoke-test"))
+	assert.Equal(t, started, loaded.StartedAt)
+	assert.Equal(t, updated, loaded.UpdatedAt)
+}
+
+func TestStore_LoadMissing(t *testing.T) {
+	c, err := NewStore(t.TempDir()).Load("onboard", "us-east-1/missing")
+	require.NoError(t, err)
+	assert.Nil(t, c)
+}
+
+func TestStore_SaveRequiresKey(t *testing.T) {
+	err := NewStore(t.TempDir()).Save(&Checkpoint{Operation: "onboard"})
+	assert.ErrorContains(t, err, "requires an operation and key")
+}
+
+func TestStore_Delete(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.Save(&Checkpoint{Operation: "onboard", Key: "us-east-1/fn"}))
+
+	require.NoError(t, store.Delete("onboard", "us-east-1/fn"))
+	c, err := store.Load("onboard", "us-east-1/fn")
+	require.NoError(t, err)
+	assert.Nil(t, c)
+
+	assert.NoError(t, store.Delete("onboard", "us-east-1/fn"), "deleting a missing checkpoint is not an error")
+}
+
+func TestStore_List(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	require.NoError(t, store.Save(&Checkpoint{Operation: "onboard", Key: "us-west-2/fn"}))
+	require.NoError(t, store.Save(&Checkpoint{Operation: "onboard", Key: "us-east-1/fn"}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600))
+
+	checkpoints, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	assert.Equal(t, "us-east-1/fn", checkpoints[0].Key)
+	assert.Equal(t, "us-west-2/fn", checkpoints[1].Key)
+
+	checkpoints, err = NewStore(filepath.Join(dir, "missing")).List()
+	require.NoError(t, err)
+	assert.Empty(t, checkpoints)
+}
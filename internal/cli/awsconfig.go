@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/openshift-online/regional-cli/internal/aws"
+)
+
+// newAWSConfig loads the AWS config for profile and region. Every command loads its
+// config here, so none can miss the transport settings of the global flags: dual-stack
+// endpoints, the proxy, rate limits, retries, the request timeout, and tracing.
+func newAWSConfig(ctx context.Context, profile, region string) (awssdk.Config, error) {
+	return newAWSConfigWithRole(ctx, profile, region, "")
+}
+
+// newAWSConfigWithRole is newAWSConfig with roleARN, when set, assumed from the
+// profile's credentials
+func newAWSConfigWithRole(ctx context.Context, profile, region, roleARN string) (awssdk.Config, error) {
+	awsConfig, err := aws.NewConfig(ctx, aws.ClientConfig{
+		Profile:        profile,
+		Region:         region,
+		RoleARN:        roleARN,
+		UseDualStack:   useDualStack,
+		Proxy:          proxyURL,
+		RateLimits:     rateLimits,
+		MaxRetries:     maxRetries,
+		RequestTimeout: requestTimeout,
+		TracerProvider: tracerProvider,
+	})
+	if err != nil {
+		return awssdk.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return awsConfig, nil
+}
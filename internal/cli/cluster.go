@@ -0,0 +1,714 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/pkg/lambda/functions/oidc-provisioner/provisioner"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/openshift-online/regional-cli/pkg/oidc"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultAccessDuration = time.Hour
+
+	defaultInstanceType = "m5.xlarge"
+	defaultReplicas     = 2
+)
+
+var (
+	createClusterName  string
+	createInstanceType string
+	createReplicas     int
+
+	createOIDCIssuerURL    string
+	createOIDCThumbprint   string
+	createOIDCFunctionName string
+	createOIDCStrict       bool
+
+	listClustersOutputFormat string
+
+	statusWatch        bool
+	statusInterval     time.Duration
+	statusTimeout      time.Duration
+	statusOutputFormat string
+
+	grantClusterID string
+	grantUserARN   string
+	grantDuration  time.Duration
+	grantReason    string
+
+	revokeClusterID string
+	revokeGrantID   string
+)
+
+// NewClusterCommand creates the cluster command
+func NewClusterCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster",
+		Short: "Create, list, and watch hosted clusters and manage access to them",
+	}
+
+	cmd.AddCommand(newClusterCreateCommand())
+	cmd.AddCommand(newClusterListCommand())
+	cmd.AddCommand(newClusterStatusCommand())
+	cmd.AddCommand(newClusterGrantAccessCommand())
+	cmd.AddCommand(newClusterListAccessCommand())
+	cmd.AddCommand(newClusterRevokeAccessCommand())
+
+	return cmd
+}
+
+func newClusterCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a hosted cluster",
+		Long: `Asks the Platform API to create a hosted cluster in --region with a pool of
+worker nodes. The request is signed with SigV4 using the credentials of --profile.
+Creation continues on the platform after the command returns.
+
+With --oidc-issuer-url, the cluster's IAM OIDC provider is ensured before the
+request is sent, and its ARN is sent with the issuer. The provisioner Lambda
+creates or tags the provider when it is deployed; otherwise the account's
+providers are reconciled with the issuer locally, with the same checks and tags.
+The provider is tagged with the cluster name until the Platform API returns the
+cluster ID, then retagged with the ID. A provider for the issuer that is already
+tagged for another cluster is refused.
+
+Prints the cluster ID.`,
+		Example: `  rosactl cluster create --name prod-1 --region us-east-1 --replicas 3
+  rosactl cluster create --name prod-1 --region us-east-1 --oidc-issuer-url https://oidc.example.com/prod-1`,
+		Args: cobra.NoArgs,
+		RunE: runClusterCreate,
+	}
+
+	cmd.Flags().StringVar(&createClusterName, "name", "", "Cluster name (required)")
+	cmd.Flags().StringVar(&createInstanceType, "instance-type", defaultInstanceType, "EC2 instance type of the worker nodes")
+	cmd.Flags().IntVar(&createReplicas, "replicas", defaultReplicas,
+		fmt.Sprintf("Number of worker nodes, between %d and %d", platform.MinReplicas, platform.MaxReplicas))
+	cmd.Flags().StringVar(&createOIDCIssuerURL, "oidc-issuer-url", "", "https URL of the cluster's OIDC issuer; its IAM provider is ensured before the cluster is created")
+	cmd.Flags().StringVar(&createOIDCThumbprint, "oidc-thumbprint", "", "SHA-1 thumbprint of the issuer's certificate (default: fetched from the issuer)")
+	cmd.Flags().StringVar(&createOIDCFunctionName, "function-name", defaultFunctionName, "OIDC provisioner Lambda function name")
+	cmd.Flags().BoolVar(&createOIDCStrict, "strict-thumbprint", false, "Fail instead of warning when the thumbprint does not match the issuer's certificate")
+	_ = cmd.MarkFlagRequired("name")
+
+	return cmd
+}
+
+func runClusterCreate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, platformAPIURL := getGlobalFlags()
+
+	if platformAPIURL == "" {
+		return errors.New("--platform-api-url is required (or set platform_api_url in the config file)")
+	}
+	if err := platform.ValidateClusterName(createClusterName); err != nil {
+		return fmt.Errorf("invalid --name: %w", err)
+	}
+	if err := platform.ValidateReplicas(createReplicas); err != nil {
+		return fmt.Errorf("invalid --replicas: %w", err)
+	}
+	if createOIDCIssuerURL != "" && !strings.HasPrefix(createOIDCIssuerURL, "https://") {
+		return fmt.Errorf("invalid --oidc-issuer-url %q: must be an https URL", createOIDCIssuerURL)
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+	if awsConfig.Region == "" {
+		return errors.New("--region is required (or set a region in the AWS profile)")
+	}
+
+	// The cluster has no ID until the Platform API creates it, so the provider is
+	// tagged with the cluster's name until then
+	var expected oidc.Expected
+	var clusterProvisioner oidc.Provisioner
+	var providerARN string
+	if createOIDCIssuerURL != "" {
+		thumbprint, err := resolveThumbprint(ctx, createOIDCIssuerURL, createOIDCThumbprint, createOIDCStrict, "--oidc-thumbprint")
+		if err != nil {
+			return err
+		}
+		expected = oidc.Expected{ClusterID: createClusterName, IssuerURL: createOIDCIssuerURL, Thumbprint: thumbprint}
+		clusterProvisioner, providerARN, err = ensureClusterProvider(ctx, awsConfig, expected, verbose)
+		if err != nil {
+			return err
+		}
+		infof("✓ OIDC provider %s is ready\n", providerARN)
+	}
+
+	platformClient := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack))
+	if verbose {
+		infof("Creating cluster %s in %s with %s...\n", createClusterName, awsConfig.Region, platformClient.BaseURL())
+	}
+	cluster, err := platformClient.CreateCluster(ctx, platform.CreateClusterRequest{
+		Name:   createClusterName,
+		Region: awsConfig.Region,
+		NodePool: platform.NodePool{
+			InstanceType: createInstanceType,
+			Replicas:     createReplicas,
+		},
+		OIDCIssuerURL:   createOIDCIssuerURL,
+		OIDCProviderARN: providerARN,
+	})
+	if err != nil {
+		if providerARN != "" {
+			warnf("⚠ %s is left tagged for cluster %s; create the cluster again to use it\n", providerARN, createClusterName)
+		}
+		return err
+	}
+
+	infof("✓ Created cluster %s in %s with %d %s worker nodes\n", cluster.Name, awsConfig.Region, createReplicas, createInstanceType)
+	if clusterProvisioner != nil {
+		expected.ClusterID = cluster.ID
+		if _, err := clusterProvisioner.Invoke(ctx, invoker.Request{
+			IssuerURL:  expected.IssuerURL,
+			Thumbprint: expected.Thumbprint,
+			ClusterID:  expected.ClusterID,
+		}); err != nil {
+			warnf("⚠ Failed to tag %s for cluster %s: %v; run rosactl oidc reconcile to tag it\n", providerARN, cluster.ID, err)
+		}
+	}
+	fmt.Println(cluster.ID)
+	return nil
+}
+
+// ensureClusterProvider creates or tags the OIDC provider of expected, returning the
+// provisioner that did so and the provider's ARN. A provider already tagged for
+// another cluster is refused, since tagging it would take it from that cluster. The
+// provisioner Lambda is invoked when it is deployed; otherwise the account's providers
+// are reconciled with expected through the Lambda's handler run in-process.
+func ensureClusterProvider(ctx context.Context, awsConfig awssdk.Config, expected oidc.Expected, verbose bool) (oidc.Provisioner, string, error) {
+	iamClient := iam.NewFromConfig(awsConfig)
+	providers, err := oidc.ListProviders(ctx, iamClient, oidc.WithTagKeyFormat(tagKeyFormat))
+	if err != nil {
+		return nil, "", err
+	}
+	if provider, ok := oidc.FindProvider(providers, expected.IssuerURL); ok &&
+		provider.Managed && provider.ClusterID != "" && provider.ClusterID != expected.ClusterID {
+		return nil, "", fmt.Errorf("OIDC provider %s for %s is tagged for cluster %s; use another issuer or delete the provider",
+			provider.ARN, expected.IssuerURL, provider.ClusterID)
+	}
+
+	functionName := resourceNaming.Apply(createOIDCFunctionName)
+	deployed := invoker.NewInvoker(aws.NewLambdaClient(awsConfig), functionName)
+	resp, err := deployed.Invoke(ctx, invoker.Request{
+		IssuerURL:        expected.IssuerURL,
+		Thumbprint:       expected.Thumbprint,
+		ClusterID:        expected.ClusterID,
+		StrictThumbprint: createOIDCStrict,
+	})
+	var notFoundErr *lambdaTypes.ResourceNotFoundException
+	switch {
+	case err == nil:
+		for _, warning := range resp.Warnings {
+			warnf("⚠ %s\n", warning)
+		}
+		return deployed, resp.OIDCProviderARN, nil
+	case !errors.As(err, &notFoundErr):
+		return nil, "", fmt.Errorf("failed to provision provider for cluster %s: %w", expected.ClusterID, err)
+	}
+
+	infof("Provisioner %s is not deployed; reconciling the OIDC provider locally\n", functionName)
+	var logOutput io.Writer = io.Discard
+	if verbose {
+		logOutput = os.Stderr
+	}
+	local := localProvisioner{
+		handler: provisioner.NewHandler(iamClient,
+			provisioner.WithTagKeyFormat(tagKeyFormat),
+			provisioner.WithThumbprintFetcher(provisioner.FetchThumbprint),
+			provisioner.WithLogOutput(logOutput)),
+		strict: createOIDCStrict,
+	}
+
+	plan := oidc.Diff([]oidc.Expected{expected}, providers, nil)
+	if plan.InSync > 0 {
+		provider, _ := oidc.FindProvider(providers, expected.IssuerURL)
+		return local, provider.ARN, nil
+	}
+	outcomes, err := oidc.Apply(ctx, plan, iamClient, local)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, outcome := range outcomes {
+		if outcome.ProviderARN != "" {
+			return local, outcome.ProviderARN, nil
+		}
+	}
+	return nil, "", fmt.Errorf("no OIDC provider was provisioned for %s", expected.IssuerURL)
+}
+
+// localProvisioner provisions providers with the provisioner Lambda's handler run
+// in-process, for accounts where the Lambda is not deployed
+type localProvisioner struct {
+	handler *provisioner.Handler
+	strict  bool // Sets StrictThumbprint on every request, as oidc.Apply does not
+}
+
+// Invoke handles req as the provisioner Lambda would
+func (p localProvisioner) Invoke(ctx context.Context, req invoker.Request) (*invoker.Response, error) {
+	resp, err := p.handler.Handle(ctx, provisioner.OIDCProvisionerRequest{
+		IssuerURL:        req.IssuerURL,
+		Thumbprint:       req.Thumbprint,
+		ClusterID:        req.ClusterID,
+		ClientIDs:        req.ClientIDs,
+		StrictThumbprint: req.StrictThumbprint || p.strict,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range resp.Warnings {
+		warnf("⚠ %s\n", warning)
+	}
+	return &invoker.Response{
+		OIDCProviderARN: resp.OIDCProviderARN,
+		Status:          resp.Status,
+		Message:         resp.Message,
+		Warnings:        resp.Warnings,
+	}, nil
+}
+
+func newClusterListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the hosted clusters of the current AWS account",
+		Long: `Lists the hosted clusters the Platform API returns for the AWS account of
+--profile, with their name, ID, state, region, and creation time. The request is
+signed with SigV4 using the credentials of --profile.`,
+		Args: cobra.NoArgs,
+		RunE: runClusterList,
+	}
+
+	cmd.Flags().StringVarP(&listClustersOutputFormat, "output", "o", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func runClusterList(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, platformAPIURL := getGlobalFlags()
+
+	if listClustersOutputFormat != "text" && listClustersOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", listClustersOutputFormat)
+	}
+	if platformAPIURL == "" {
+		return errors.New("--platform-api-url is required (or set platform_api_url in the config file)")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	platformClient := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack))
+	if verbose {
+		infof("Listing clusters from %s...\n", platformClient.BaseURL())
+	}
+	clusters, err := platformClient.ListClusters(ctx)
+	if err != nil {
+		return err
+	}
+
+	if listClustersOutputFormat == "json" {
+		if clusters == nil {
+			clusters = []platform.Cluster{}
+		}
+		return writeJSON(clusters)
+	}
+	if len(clusters) == 0 {
+		infoln("No clusters found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tID\tSTATE\tREGION\tCREATED AT")
+	for _, cluster := range clusters {
+		createdAt := "-"
+		if cluster.CreatedAt != nil {
+			createdAt = cluster.CreatedAt.Local().Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			valueOrDash(cluster.Name),
+			cluster.ID,
+			valueOrDash(cluster.State),
+			valueOrDash(cluster.Region),
+			createdAt)
+	}
+	return w.Flush()
+}
+
+func newClusterStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status <cluster-id>",
+		Short: "Show a hosted cluster's state, or watch it until it settles",
+		Long: `Shows the state of a hosted cluster as the Platform API reports it, with its
+name, region, creation time, and OIDC issuer.
+
+With --watch, the cluster is polled every --interval and each change of state is
+reported on stderr as it happens, until the cluster is ready, has failed, or, if
+it was uninstalling, is gone. The cluster is then shown as without --watch. The
+command exits non-zero if the cluster ends in an error state, or does not settle
+within --timeout.`,
+		Example: `  rosactl cluster status cluster-000001
+  rosactl cluster status cluster-000001 --watch --interval 1m --timeout 1h`,
+		Args: cobra.ExactArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeClusterIDs(cmd, args, toComplete)
+		},
+		RunE: runClusterStatus,
+	}
+
+	cmd.Flags().BoolVarP(&statusWatch, "watch", "w", false, "Poll the cluster until it is ready, has failed, or is gone, reporting each change of state")
+	cmd.Flags().DurationVar(&statusInterval, "interval", platform.DefaultWatchInterval, "Time between polls with --watch")
+	cmd.Flags().DurationVar(&statusTimeout, "timeout", 0, "Give up watching after this long (0 watches until the cluster settles)")
+	cmd.Flags().StringVarP(&statusOutputFormat, "output", "o", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func runClusterStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, platformAPIURL := getGlobalFlags()
+	clusterID := args[0]
+
+	if statusOutputFormat != "text" && statusOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", statusOutputFormat)
+	}
+	if platformAPIURL == "" {
+		return errors.New("--platform-api-url is required (or set platform_api_url in the config file)")
+	}
+	if statusInterval < time.Second {
+		return fmt.Errorf("--interval must be at least 1s")
+	}
+	if statusTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	platformClient := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack))
+	if !statusWatch {
+		cluster, err := platformClient.GetCluster(ctx, clusterID)
+		if err != nil {
+			return err
+		}
+		return printClusterStatus(cluster)
+	}
+
+	if verbose {
+		infof("Watching cluster %s with %s every %s...\n", clusterID, platformClient.BaseURL(), statusInterval)
+	}
+	watchCtx := ctx
+	if statusTimeout > 0 {
+		var cancel context.CancelFunc
+		watchCtx, cancel = context.WithTimeout(ctx, statusTimeout)
+		defer cancel()
+	}
+	cluster, err := platformClient.WatchCluster(watchCtx, clusterID, platform.WatchOptions{
+		Interval: statusInterval,
+		OnChange: func(previous string, cluster platform.Cluster) {
+			now := time.Now().Format(time.RFC3339)
+			if previous == "" {
+				infof("%s  %s: %s\n", now, cluster.ID, valueOrDash(cluster.State))
+				return
+			}
+			infof("%s  %s: %s → %s\n", now, cluster.ID, previous, valueOrDash(cluster.State))
+		},
+	})
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil && cluster != nil {
+			return fmt.Errorf("cluster %s did not settle within %s; it is still %s", clusterID, statusTimeout, valueOrDash(cluster.State))
+		}
+		return err
+	}
+
+	if err := printClusterStatus(cluster); err != nil {
+		return err
+	}
+	if cluster.Failed() {
+		return fmt.Errorf("cluster %s ended in state %s", clusterID, cluster.State)
+	}
+	return nil
+}
+
+// printClusterStatus writes a cluster to stdout in the --output format
+func printClusterStatus(cluster *platform.Cluster) error {
+	if statusOutputFormat == "json" {
+		return writeJSON(cluster)
+	}
+
+	createdAt := "-"
+	if cluster.CreatedAt != nil {
+		createdAt = cluster.CreatedAt.Local().Format(time.RFC3339)
+	}
+	fmt.Printf("ID:           %s\n", cluster.ID)
+	fmt.Printf("Name:         %s\n", valueOrDash(cluster.Name))
+	fmt.Printf("State:        %s\n", valueOrDash(cluster.State))
+	fmt.Printf("Region:       %s\n", valueOrDash(cluster.Region))
+	fmt.Printf("Created at:   %s\n", createdAt)
+	fmt.Printf("OIDC issuer:  %s\n", valueOrDash(cluster.OIDCIssuerURL))
+	return nil
+}
+
+func newClusterGrantAccessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "grant-access",
+		Short: "Grant an IAM principal temporary break-glass access to a cluster",
+		Long: `Asks the Platform API to add a time-boxed access entry for an IAM user or role
+to the cluster's IAM authenticator. The Platform API removes the entry when
+--duration has passed; rosactl records the grant locally so it can be listed
+with list-access and revoked early with revoke-access.
+
+Prints the grant ID.`,
+		Args: cobra.NoArgs,
+		RunE: runClusterGrantAccess,
+	}
+
+	cmd.Flags().StringVar(&grantClusterID, "cluster-id", "", "Cluster to grant access to (required)")
+	cmd.Flags().StringVar(&grantUserARN, "user-arn", "", "IAM user or role ARN to grant access to (required)")
+	cmd.Flags().DurationVar(&grantDuration, "duration", defaultAccessDuration,
+		fmt.Sprintf("How long access lasts, between %s and %s", platform.MinAccessDuration, platform.MaxAccessDuration))
+	cmd.Flags().StringVar(&grantReason, "reason", "", "Why access is needed, such as an incident ID; recorded with the grant")
+	_ = cmd.MarkFlagRequired("cluster-id")
+	_ = cmd.MarkFlagRequired("user-arn")
+
+	return cmd
+}
+
+func runClusterGrantAccess(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, platformAPIURL := getGlobalFlags()
+
+	if platformAPIURL == "" {
+		return errors.New("--platform-api-url is required (or set platform_api_url in the config file)")
+	}
+	if err := validatePrincipalARN(grantUserARN); err != nil {
+		return err
+	}
+	if err := platform.ValidateAccessDuration(grantDuration); err != nil {
+		return err
+	}
+
+	store, err := grantStore()
+	if err != nil {
+		return err
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	platformClient := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack))
+	if verbose {
+		infof("Requesting access to cluster %s from %s...\n", grantClusterID, platformClient.BaseURL())
+	}
+	grant, err := platformClient.GrantAccess(ctx, grantClusterID, platform.GrantAccessRequest{
+		PrincipalARN:    grantUserARN,
+		DurationSeconds: int64(grantDuration / time.Second),
+		Reason:          grantReason,
+	})
+	if err != nil {
+		return err
+	}
+
+	record := manifest.AccessGrant{
+		ID:             grant.ID,
+		ClusterID:      grant.ClusterID,
+		PrincipalARN:   grant.PrincipalARN,
+		PlatformAPIURL: platformClient.BaseURL(),
+		Reason:         grantReason,
+		GrantedAt:      time.Now().UTC(),
+		ExpiresAt:      grant.ExpiresAt,
+	}
+	if record.PrincipalARN == "" {
+		record.PrincipalARN = grantUserARN
+	}
+	if record.ExpiresAt.IsZero() {
+		record.ExpiresAt = record.GrantedAt.Add(grantDuration)
+	}
+	if identity, err := aws.NewSTSClient(awsConfig).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}); err == nil {
+		record.GrantedBy = awssdk.ToString(identity.Arn)
+	}
+
+	infof("✓ Granted %s access to cluster %s until %s\n",
+		record.PrincipalARN, record.ClusterID, record.ExpiresAt.Local().Format(time.RFC3339))
+	if len(grant.Groups) > 0 {
+		infof("  Kubernetes groups: %s\n", strings.Join(grant.Groups, ", "))
+	}
+	if err := store.SaveGrant(record); err != nil {
+		warnf("⚠ Failed to record the access grant locally: %v\n", err)
+	}
+	pruneExpiredGrants(store, verbose)
+
+	fmt.Println(record.ID)
+	return nil
+}
+
+func newClusterListAccessCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list-access",
+		Short: "List the unexpired access grants made with grant-access",
+		Long: `Lists the access grants recorded by grant-access that have not expired, soonest
+to expire first. Expired grants are removed from the local record.`,
+		Args: cobra.NoArgs,
+		RunE: runClusterListAccess,
+	}
+}
+
+func runClusterListAccess(cmd *cobra.Command, args []string) error {
+	_, _, verbose, _ := getGlobalFlags()
+
+	store, err := grantStore()
+	if err != nil {
+		return err
+	}
+	pruneExpiredGrants(store, verbose)
+
+	grants, err := store.Grants()
+	if err != nil {
+		return err
+	}
+	if len(grants) == 0 {
+		infoln("No active access grants.")
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GRANT ID\tCLUSTER\tPRINCIPAL\tEXPIRES AT\tREMAINING\tREASON")
+	for _, g := range grants {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			g.ID,
+			g.ClusterID,
+			g.PrincipalARN,
+			g.ExpiresAt.Local().Format(time.RFC3339),
+			g.ExpiresAt.Sub(now).Round(time.Minute),
+			valueOrDash(g.Reason))
+	}
+	return w.Flush()
+}
+
+func newClusterRevokeAccessCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "revoke-access",
+		Short: "Revoke an access grant before it expires",
+		Args:  cobra.NoArgs,
+		RunE:  runClusterRevokeAccess,
+	}
+
+	cmd.Flags().StringVar(&revokeGrantID, "grant-id", "", "Grant to revoke, as printed by grant-access (required)")
+	cmd.Flags().StringVar(&revokeClusterID, "cluster-id", "", "Cluster the grant is for (default: from the local record)")
+	_ = cmd.MarkFlagRequired("grant-id")
+
+	return cmd
+}
+
+func runClusterRevokeAccess(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, platformAPIURL := getGlobalFlags()
+
+	store, err := grantStore()
+	if err != nil {
+		return err
+	}
+	grants, err := store.Grants()
+	if err != nil {
+		return err
+	}
+
+	clusterID := revokeClusterID
+	for _, g := range grants {
+		if g.ID != revokeGrantID {
+			continue
+		}
+		if clusterID == "" {
+			clusterID = g.ClusterID
+		}
+		if platformAPIURL == "" {
+			platformAPIURL = g.PlatformAPIURL
+		}
+	}
+	if clusterID == "" {
+		return fmt.Errorf("grant %s is not recorded locally; use --cluster-id", revokeGrantID)
+	}
+	if platformAPIURL == "" {
+		return errors.New("--platform-api-url is required (or set platform_api_url in the config file)")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	platformClient := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack))
+	if err := platformClient.RevokeAccess(ctx, clusterID, revokeGrantID); err != nil {
+		return err
+	}
+	infof("✓ Revoked access grant %s on cluster %s\n", revokeGrantID, clusterID)
+
+	if err := store.DeleteGrant(revokeGrantID); err != nil {
+		warnf("⚠ Failed to update the local access grant record: %v\n", err)
+	}
+	return nil
+}
+
+// grantStore returns the store recording access grants
+func grantStore() (*manifest.Store, error) {
+	dir, err := config.ManifestDir()
+	if err != nil {
+		return nil, err
+	}
+	return manifest.NewStore(dir), nil
+}
+
+// pruneExpiredGrants drops expired grants from the local record
+func pruneExpiredGrants(store *manifest.Store, verbose bool) {
+	expired, err := store.PruneGrants()
+	if err != nil {
+		warnf("⚠ Failed to prune expired access grants: %v\n", err)
+		return
+	}
+	if verbose {
+		for _, g := range expired {
+			infof("  Access grant %s for %s on cluster %s expired at %s\n",
+				g.ID, g.PrincipalARN, g.ClusterID, g.ExpiresAt.Local().Format(time.RFC3339))
+		}
+	}
+}
+
+// validatePrincipalARN checks that principal is an IAM user or role ARN
+func validatePrincipalARN(principal string) error {
+	parsed, err := arn.Parse(principal)
+	if err != nil {
+		return fmt.Errorf("invalid --user-arn %q: %w", principal, err)
+	}
+	if parsed.Service != "iam" || !(strings.HasPrefix(parsed.Resource, "user/") || strings.HasPrefix(parsed.Resource, "role/")) {
+		return fmt.Errorf("invalid --user-arn %q: must be an IAM user or role ARN", principal)
+	}
+	return nil
+}
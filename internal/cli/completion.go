@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/internal/validator"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+	"github.com/spf13/cobra"
+)
+
+// completionTimeout bounds the AWS and Platform API calls made by --refresh completions,
+// so a slow or unreachable endpoint never hangs the shell
+const completionTimeout = 5 * time.Second
+
+// completionRefresh makes completions list live resources instead of reading the
+// local manifests. It only affects shell completion.
+var completionRefresh bool
+
+// registerFlagCompletions completes --region, and --function-name and --cluster-id on
+// every command defining them, from the local manifests, or from AWS and the Platform
+// API with --refresh
+func registerFlagCompletions(rootCmd *cobra.Command) {
+	_ = rootCmd.RegisterFlagCompletionFunc("region", completeRegions)
+
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		if cmd.LocalNonPersistentFlags().Lookup("function-name") != nil {
+			_ = cmd.RegisterFlagCompletionFunc("function-name", completeFunctionNames)
+		}
+		if cmd.LocalNonPersistentFlags().Lookup("cluster-id") != nil {
+			_ = cmd.RegisterFlagCompletionFunc("cluster-id", completeClusterIDs)
+		}
+		for _, child := range cmd.Commands() {
+			walk(child)
+		}
+	}
+	walk(rootCmd)
+}
+
+// completeFunctionNames completes provisioner function names, in the --region given
+// when there is one
+func completeFunctionNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := loadConfig(cmd, args); err != nil {
+		return completionError(err)
+	}
+
+	if completionRefresh {
+		ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+		defer cancel()
+		names, err := liveFunctionNames(ctx)
+		if err != nil {
+			return completionError(err)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manifests, err := completionManifests()
+	if err != nil {
+		return completionError(err)
+	}
+	regions := make(map[string][]string)
+	for _, m := range manifests {
+		// --function-name takes the name before the configured naming is applied
+		name, ok := resourceNaming.Base(m.FunctionName)
+		if ok && (region == "" || m.Region == region) {
+			regions[name] = append(regions[name], m.Region)
+		}
+	}
+	var names []string
+	for name, inRegions := range regions {
+		names = append(names, name+"\t"+strings.Join(inRegions, ", "))
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeClusterIDs completes the IDs of clusters with a recorded access grant
+func completeClusterIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := loadConfig(cmd, args); err != nil {
+		return completionError(err)
+	}
+
+	if completionRefresh {
+		ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+		defer cancel()
+		ids, err := liveClusterIDs(ctx)
+		if err != nil {
+			return completionError(err)
+		}
+		return ids, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	store, err := grantStore()
+	if err != nil {
+		return completionError(err)
+	}
+	grants, err := store.Grants()
+	if err != nil {
+		return completionError(err)
+	}
+	var ids []string
+	for _, g := range grants {
+		if !slices.Contains(ids, g.ClusterID) {
+			ids = append(ids, g.ClusterID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRegions completes the regions with a recorded deployment, or every supported
+// region when there is none
+func completeRegions(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := loadConfig(cmd, args); err != nil {
+		return completionError(err)
+	}
+
+	if completionRefresh {
+		ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+		defer cancel()
+		regions, err := liveRegions(ctx)
+		if err != nil {
+			return completionError(err)
+		}
+		return regions, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	manifests, err := completionManifests()
+	if err != nil {
+		return completionError(err)
+	}
+	var regions []string
+	for _, m := range manifests {
+		if !slices.Contains(regions, m.Region) {
+			regions = append(regions, m.Region)
+		}
+	}
+	if len(regions) == 0 {
+		regions = validator.SupportedRegions()
+	}
+	return regions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completionManifests returns the locally recorded deployment manifests
+func completionManifests() ([]*manifest.Manifest, error) {
+	dir, err := config.ManifestDir()
+	if err != nil {
+		return nil, err
+	}
+	return manifest.NewStore(dir).List()
+}
+
+// liveFunctionNames lists the provisioner functions deployed in --region
+func liveFunctionNames(ctx context.Context) ([]string, error) {
+	if region == "" {
+		return nil, fmt.Errorf("--region is required to list functions")
+	}
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	paginator := lambda.NewListFunctionsPaginator(aws.NewLambdaClient(awsConfig), &lambda.ListFunctionsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, function := range page.Functions {
+			if function.Description == nil || !deployer.IsProvisionerDescription(*function.Description) {
+				continue
+			}
+			if name, ok := resourceNaming.Base(*function.FunctionName); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// liveClusterIDs lists the clusters the Platform API reports, with their names
+func liveClusterIDs(ctx context.Context) ([]string, error) {
+	if platformAPIURL == "" {
+		return nil, fmt.Errorf("--platform-api-url is required to list clusters")
+	}
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack)).ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(clusters))
+	for _, cluster := range clusters {
+		if cluster.Name != "" && cluster.Name != cluster.ID {
+			ids = append(ids, cluster.ID+"\t"+cluster.Name)
+		} else {
+			ids = append(ids, cluster.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// liveRegions lists the supported regions enabled for the account
+func liveRegions(ctx context.Context) ([]string, error) {
+	awsConfig, err := newAWSConfig(ctx, profile, "")
+	if err != nil {
+		return nil, err
+	}
+	return validator.NewRegionOptInChecker(awsConfig).EnabledSupportedRegions(ctx)
+}
+
+// completionError reports err on stderr without offering file names as completions
+func completionError(err error) ([]string, cobra.ShellCompDirective) {
+	cobra.CompErrorln(err.Error())
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/secrets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	configOutputFormat string
+)
+
+// NewConfigCommand creates the config command
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect rosactl configuration",
+	}
+
+	cmd.AddCommand(newConfigViewCommand())
+	cmd.AddCommand(newConfigEncryptCommand())
+
+	return cmd
+}
+
+func newConfigViewCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "view",
+		Short: "Print the effective configuration",
+		Long: `Prints the effective configuration after merging the config file, ROSACTL_*
+environment variables, and command-line flags (highest precedence). Each value
+is annotated with the source that supplied it. Secrets and credentials embedded
+in URLs are redacted. Secrets not set in the config file, environment, or flags
+are read from the secret store.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigView,
+	}
+
+	cmd.Flags().StringVarP(&configOutputFormat, "output", "o", "yaml", "Output format: yaml or json")
+
+	return cmd
+}
+
+func runConfigView(cmd *cobra.Command, args []string) error {
+	if effectiveConfig == nil {
+		return fmt.Errorf("configuration has not been loaded")
+	}
+
+	if err := resolveSecrets(); err != nil {
+		warnf("⚠ Failed to read secret store: %v\n", err)
+	}
+
+	switch configOutputFormat {
+	case "yaml":
+		if effectiveConfig.File != "" && verbose {
+			fmt.Printf("# Config file: %s\n", effectiveConfig.File)
+		}
+		return effectiveConfig.WriteYAML(os.Stdout)
+	case "json":
+		return effectiveConfig.WriteJSON(os.Stdout)
+	default:
+		return fmt.Errorf("unsupported output format %q (expected yaml or json)", configOutputFormat)
+	}
+}
+
+func newConfigEncryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encrypt",
+		Short: "Move plain-text secrets from the config file into the secret store",
+		Long: `Moves secret values such as platform_token out of the plain-text config file
+and into the secret store, then rewrites the config file without them.
+
+The secret store is the OS keyring (macOS Keychain, Windows Credential Manager,
+or the Secret Service on Linux). When no keyring is available, secrets are kept
+in ~/.rosactl/secrets.enc, encrypted with a key derived from
+ROSACTL_SECRETS_PASSPHRASE. Set ROSACTL_SECRETS_BACKEND=keyring or file to
+choose a backend explicitly.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigEncrypt,
+	}
+
+	return cmd
+}
+
+func runConfigEncrypt(cmd *cobra.Command, args []string) error {
+	if effectiveConfig == nil || effectiveConfig.File == "" {
+		return fmt.Errorf("no config file found to encrypt")
+	}
+
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+
+	moved, err := config.EncryptFile(effectiveConfig.File, store)
+	for _, key := range moved {
+		infof("✓ Moved %s to %s\n", key, store.Description())
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(moved) == 0 {
+		infof("No plain-text secrets found in %s\n", effectiveConfig.File)
+		return nil
+	}
+
+	infof("✓ Rewrote %s without plain-text secrets\n", effectiveConfig.File)
+	return nil
+}
+
+// openSecretStore opens the OS keyring, falling back to the encrypted secrets file
+func openSecretStore() (secrets.Store, error) {
+	path, err := config.SecretsFile()
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Open(path)
+}
+
+// resolveSecrets fills secret settings that were not set elsewhere from the secret store.
+// It is called only by commands that need secrets, so other commands never touch the keyring.
+func resolveSecrets() error {
+	if effectiveConfig == nil {
+		return fmt.Errorf("configuration has not been loaded")
+	}
+
+	store, err := openSecretStore()
+	if err != nil {
+		return err
+	}
+	return effectiveConfig.ApplySecrets(store)
+}
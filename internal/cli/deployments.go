@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyFunctionName string
+	historyFromParam    string
+)
+
+// NewDeploymentsCommand creates the deployments command
+func NewDeploymentsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deployments",
+		Short: "Inspect OIDC provisioner deployments",
+	}
+
+	cmd.AddCommand(newDeploymentsHistoryCommand())
+
+	return cmd
+}
+
+func newDeploymentsHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show who deployed the OIDC provisioner and when",
+		Long: `Shows recent deployments of the OIDC provisioner, newest first, with the
+caller that ran each one. By default the history recorded in the local manifest
+is shown; use --parameter to read the history shared in an SSM parameter by
+setup-account --history-parameter.`,
+		Args: cobra.NoArgs,
+		RunE: runDeploymentsHistory,
+	}
+
+	cmd.Flags().StringVar(&historyFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().StringVar(&historyFromParam, "parameter", "", "Read the history from this SSM parameter instead of the local manifest")
+
+	return cmd
+}
+
+func runDeploymentsHistory(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+	if region == "" {
+		region = awsConfig.Region
+	}
+
+	var history []manifest.HistoryEntry
+	if historyFromParam != "" {
+		history, err = manifest.NewParameterHistory(aws.NewSSMClient(awsConfig), historyFromParam).Load(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		dir, err := config.ManifestDir()
+		if err != nil {
+			return err
+		}
+		m, err := manifest.NewStore(dir).Load(region, resourceNaming.Apply(historyFunctionName))
+		if err != nil {
+			return err
+		}
+		if m != nil {
+			history = m.History
+		}
+	}
+
+	if len(history) == 0 {
+		infoln("No deployments recorded.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DEPLOYED AT\tACCOUNT\tDEPLOYED BY\tSTATUS\tVERSION\tCHECKSUM")
+	for i := len(history) - 1; i >= 0; i-- {
+		entry := history[i]
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.DeployedAt.Local().Format(time.RFC3339),
+			accountLabel(entry.AccountID, entry.AccountAlias),
+			valueOrDash(entry.DeployedBy),
+			entry.Status,
+			valueOrDash(entry.Version),
+			shortChecksum(entry.PackageChecksum))
+	}
+	return w.Flush()
+}
+
+// valueOrDash returns "-" for empty table cells
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// accountLabel shows an account ID with its alias, when it has one
+func accountLabel(accountID, alias string) string {
+	if accountID == "" {
+		return "-"
+	}
+	if alias == "" {
+		return accountID
+	}
+	return fmt.Sprintf("%s (%s)", accountID, alias)
+}
+
+// shortChecksum abbreviates a package checksum for display
+func shortChecksum(checksum string) string {
+	if len(checksum) > 12 {
+		return checksum[:12]
+	}
+	return valueOrDash(checksum)
+}
@@ -0,0 +1,356 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/openshift-online/regional-cli/internal/platform/fake"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/lambda/functions/oidc-provisioner/provisioner"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultMockAPIListen = "127.0.0.1:8080"
+
+	// mockAPIShutdownTimeout bounds how long in-flight requests may take after an interrupt
+	mockAPIShutdownTimeout = 5 * time.Second
+
+	// invokeLocalRegion signs requests to --aws-endpoint when no region is configured
+	invokeLocalRegion = "us-east-1"
+
+	// invokeLocalTrustPolicy is the trust policy of the roles --dry-run stubs
+	invokeLocalTrustPolicy = `{"Version":"2012-10-17","Statement":[]}`
+)
+
+var (
+	mockAPIListen           string
+	mockAPILatencies        []string
+	mockAPIFailures         []string
+	mockAPIClusters         int
+	mockAPIInstallTime      time.Duration
+	mockAPIVerifySignatures bool
+
+	invokeLocalEndpoint string
+	invokeLocalDryRun   bool
+	invokeLocalTimeout  time.Duration
+)
+
+// NewDevCommand creates the dev command
+func NewDevCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "Tools for developing against and demonstrating rosactl",
+	}
+
+	cmd.AddCommand(newDevMockAPICommand())
+	cmd.AddCommand(newDevInvokeLocalCommand())
+
+	return cmd
+}
+
+func newDevMockAPICommand() *cobra.Command {
+	endpoints := make([]string, 0, len(fake.Endpoints()))
+	for _, endpoint := range fake.Endpoints() {
+		endpoints = append(endpoints, string(endpoint))
+	}
+
+	cmd := &cobra.Command{
+		Use:   "mock-api",
+		Short: "Serve a local mock of the Platform API",
+		Long: `Serves an in-memory Platform API on --listen until interrupted, so commands can
+be demonstrated or developed without a deployed API. Point them at it with
+--platform-api-url. The mock serves the live endpoint, cluster listing, creation,
+and status, and access grants; state is lost when it exits. Created clusters stay
+installing, or become ready after --install-time.
+
+Requests must be signed with SigV4. With --verify-signatures the signature is
+checked against the credentials of --profile; otherwise any well-formed signature
+is accepted.
+
+Use --latency and --fail to exercise slow and failing endpoints. Endpoints are
+` + strings.Join(endpoints, ", ") + `, or * for all of them.
+
+Prints the API's URL.`,
+		Example: `  rosactl dev mock-api --latency live=2s --fail list-clusters=503:1
+  rosactl init --platform-api-url http://127.0.0.1:8080`,
+		Args: cobra.NoArgs,
+		RunE: runDevMockAPI,
+	}
+
+	cmd.Flags().StringVar(&mockAPIListen, "listen", defaultMockAPIListen, "Address to listen on")
+	cmd.Flags().StringArrayVar(&mockAPILatencies, "latency", nil, "Delay responses from an endpoint, as endpoint=duration (repeatable)")
+	cmd.Flags().StringArrayVar(&mockAPIFailures, "fail", nil,
+		"Fail requests to an endpoint with an HTTP status, as endpoint=status[:count]; without a count every request fails (repeatable)")
+	cmd.Flags().IntVar(&mockAPIClusters, "clusters", 3, "Number of demo clusters to serve")
+	cmd.Flags().DurationVar(&mockAPIInstallTime, "install-time", 0, "Report created clusters ready after this long, e.g. 2m (0 keeps them installing)")
+	cmd.Flags().BoolVar(&mockAPIVerifySignatures, "verify-signatures", false, "Verify request signatures against the credentials of --profile")
+
+	return cmd
+}
+
+func runDevMockAPI(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+
+	opts, err := mockAPIOptions()
+	if err != nil {
+		return err
+	}
+	demoCreatedAt := time.Now().UTC().Truncate(time.Second)
+	for i := 1; i <= mockAPIClusters; i++ {
+		id := fmt.Sprintf("demo-%d", i)
+		opts = append(opts, fake.WithClusters(platform.Cluster{
+			ID:            id,
+			Name:          id,
+			State:         "ready",
+			Region:        region,
+			CreatedAt:     &demoCreatedAt,
+			OIDCIssuerURL: "https://oidc.example.com/" + id,
+		}))
+	}
+
+	if mockAPIVerifySignatures {
+		awsConfig, err := newAWSConfig(ctx, profile, region)
+		if err != nil {
+			return err
+		}
+		creds, err := awsConfig.Credentials.Retrieve(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+		}
+		opts = append(opts, fake.WithCredentials(creds))
+	}
+
+	listener, err := net.Listen("tcp", mockAPIListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", mockAPIListen, err)
+	}
+	server := &http.Server{Handler: fake.New(opts...), ReadHeaderTimeout: 10 * time.Second}
+
+	apiURL := "http://" + listener.Addr().String()
+	infof("Mock Platform API listening on %s; press Ctrl-C to stop\n", apiURL)
+	fmt.Println(apiURL)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("mock API stopped: %w", err)
+	case <-ctx.Done():
+	}
+
+	infoln("Stopping mock Platform API")
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), mockAPIShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to stop mock API: %w", err)
+	}
+	return nil
+}
+
+// mockAPIOptions parses --install-time, --latency, and --fail
+func mockAPIOptions() ([]fake.Option, error) {
+	var opts []fake.Option
+	if mockAPIInstallTime < 0 {
+		return nil, fmt.Errorf("--install-time must not be negative")
+	}
+	if mockAPIInstallTime > 0 {
+		opts = append(opts, fake.WithInstallTime(mockAPIInstallTime))
+	}
+	for _, value := range mockAPILatencies {
+		endpoint, raw, err := parseMockAPIEndpoint("--latency", value, "endpoint=duration")
+		if err != nil {
+			return nil, err
+		}
+		latency, err := time.ParseDuration(raw)
+		if err != nil || latency < 0 {
+			return nil, fmt.Errorf("invalid --latency %q: expected a non-negative duration such as 500ms", value)
+		}
+		opts = append(opts, fake.WithLatency(endpoint, latency))
+	}
+
+	for _, value := range mockAPIFailures {
+		endpoint, raw, err := parseMockAPIEndpoint("--fail", value, "endpoint=status[:count]")
+		if err != nil {
+			return nil, err
+		}
+		status, count, hasCount := strings.Cut(raw, ":")
+		failure := fake.Failure{}
+		if failure.StatusCode, err = strconv.Atoi(status); err != nil || failure.StatusCode < 400 || failure.StatusCode > 599 {
+			return nil, fmt.Errorf("invalid --fail %q: status must be an HTTP error status between 400 and 599", value)
+		}
+		if hasCount {
+			if failure.Count, err = strconv.Atoi(count); err != nil || failure.Count < 1 {
+				return nil, fmt.Errorf("invalid --fail %q: count must be a positive integer", value)
+			}
+		}
+		opts = append(opts, fake.WithFailure(endpoint, failure))
+	}
+	return opts, nil
+}
+
+// parseMockAPIEndpoint splits an endpoint=value flag, checking the endpoint name
+func parseMockAPIEndpoint(flag, value, format string) (fake.Endpoint, string, error) {
+	name, raw, ok := strings.Cut(value, "=")
+	if !ok {
+		return "", "", fmt.Errorf("invalid %s %q: expected %s", flag, value, format)
+	}
+	endpoint := fake.Endpoint(name)
+	if endpoint != fake.AllEndpoints && !slices.Contains(fake.Endpoints(), endpoint) {
+		return "", "", fmt.Errorf("invalid %s %q: unknown endpoint %q", flag, value, name)
+	}
+	return endpoint, raw, nil
+}
+
+func newDevInvokeLocalCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "invoke-local <payload-file>",
+		Short: "Run the OIDC provisioner handler locally against a payload",
+		Long: `Runs the OIDC provisioner Lambda's handler in process with the request in
+payload-file, or stdin when it is -, so handler changes can be tried without
+deploying. Unknown fields in the payload are refused, since the deployed function
+would silently ignore them.
+
+By default the handler calls IAM with the credentials of --profile, creating real
+OIDC providers. Use --aws-endpoint to call an IAM emulator such as LocalStack
+instead, or --dry-run to call an in-memory IAM stub that describes the changes it
+would make. With --dry-run, the request's issuer already has a provider when the
+request binds roles, and its roles exist with an empty trust policy; the issuer's
+live thumbprint is not checked.
+
+The handler is configured from the same environment variables the deployed function
+reads, such as ROSA_BINDABLE_ROLE_PATH and ROSA_ALLOWED_ISSUER_HOSTS. Its log records
+and warnings are written to stderr and its response to stdout.`,
+		Example: `  rosactl dev invoke-local request.json --dry-run
+  AWS_ACCESS_KEY_ID=test AWS_SECRET_ACCESS_KEY=test \
+    rosactl dev invoke-local request.json --aws-endpoint http://localhost:4566`,
+		Args: cobra.ExactArgs(1),
+		RunE: runDevInvokeLocal,
+	}
+
+	cmd.Flags().StringVar(&invokeLocalEndpoint, "aws-endpoint", "", "Send IAM requests to this endpoint, such as LocalStack's http://localhost:4566")
+	cmd.Flags().BoolVar(&invokeLocalDryRun, "dry-run", false, "Call an in-memory IAM stub instead of AWS")
+	cmd.Flags().DurationVar(&invokeLocalTimeout, "timeout", time.Duration(deployer.DefaultTimeout)*time.Second,
+		"Invocation deadline, like the function's timeout")
+	cmd.MarkFlagsMutuallyExclusive("aws-endpoint", "dry-run")
+
+	return cmd
+}
+
+func runDevInvokeLocal(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+
+	if invokeLocalTimeout <= 0 {
+		return fmt.Errorf("invalid --timeout %s: must be positive", invokeLocalTimeout)
+	}
+	if invokeLocalEndpoint != "" {
+		if endpoint, err := url.Parse(invokeLocalEndpoint); err != nil || endpoint.Host == "" ||
+			(endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+			return fmt.Errorf("invalid --aws-endpoint %q: expected an http or https URL", invokeLocalEndpoint)
+		}
+	}
+
+	req, err := readInvokeLocalPayload(args[0])
+	if err != nil {
+		return err
+	}
+
+	opts := append(provisioner.OptionsFromEnv(), provisioner.WithLogOutput(os.Stderr))
+	var iamClient provisioner.IAMAPI
+	if invokeLocalDryRun {
+		iamClient = dryRunIAM(req)
+	} else {
+		awsConfig, err := newAWSConfig(ctx, profile, region)
+		if err != nil {
+			return err
+		}
+		if invokeLocalEndpoint != "" && awsConfig.Region == "" {
+			awsConfig.Region = invokeLocalRegion
+		}
+		iamClient = iam.NewFromConfig(awsConfig, func(o *iam.Options) {
+			if invokeLocalEndpoint != "" {
+				// A local endpoint has no dual-stack variant
+				o.BaseEndpoint = awssdk.String(invokeLocalEndpoint)
+				o.EndpointOptions.UseDualStackEndpoint = awssdk.DualStackEndpointStateDisabled
+			}
+		})
+		opts = append(opts, provisioner.WithThumbprintFetcher(provisioner.FetchThumbprint))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, invokeLocalTimeout)
+	defer cancel()
+	resp, err := provisioner.NewHandler(iamClient, opts...).Handle(ctx, req)
+	if err != nil {
+		return fmt.Errorf("handler failed: %w", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(resp)
+}
+
+// readInvokeLocalPayload reads and decodes the request in path, or stdin for -,
+// refusing fields the request does not have
+func readInvokeLocalPayload(path string) (provisioner.OIDCProvisionerRequest, error) {
+	var req provisioner.OIDCProvisionerRequest
+	var data []byte
+	var err error
+	if path == "-" {
+		path = "stdin"
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return req, fmt.Errorf("failed to read payload: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		return req, fmt.Errorf("invalid payload %s: %w", path, err)
+	}
+	return req, nil
+}
+
+// dryRunIAM returns the IAM stub for --dry-run. A bind-roles request's issuer has a
+// provider and its roles exist, in the account of its first role, so the handler
+// can bind them.
+func dryRunIAM(req provisioner.OIDCProvisionerRequest) *provisioner.StubIAM {
+	var accountID string
+	if len(req.Roles) > 0 {
+		if role, err := arn.Parse(req.Roles[0].RoleARN); err == nil {
+			accountID = role.AccountID
+		}
+	}
+
+	stub := provisioner.NewStubIAM(accountID, os.Stderr)
+	if req.Action == "bind-roles" {
+		stub.AddProvider(req.IssuerURL)
+		for _, binding := range req.Roles {
+			stub.AddRole(binding.RoleARN, invokeLocalTrustPolicy)
+		}
+	}
+	return stub
+}
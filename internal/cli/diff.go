@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffFunctionName string
+	diffManifestFile string
+	diffFromRegion   string
+	diffFromProfile  string
+	diffOutputFormat string
+)
+
+// deploymentDiffReport is the JSON form of a diff
+type deploymentDiffReport struct {
+	From        string                `json:"from"`
+	To          string                `json:"to"`
+	Differences []deployer.Difference `json:"differences"`
+}
+
+// NewDiffCommand creates the diff command
+func NewDiffCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare a deployment with its manifest or another region's deployment",
+		Long: `Compares the live OIDC provisioner deployment in --region, its function
+configuration, resource policy, execution role policies, tags, log retention, and
+code checksum, with a reference:
+
+  (default)              the local deployment manifest for the function and region
+  --manifest <file>      a saved deployment manifest
+  --from-region <region> the live deployment in another region, optionally in the
+                         account of --from-profile
+
+Account IDs and regions are replaced by placeholders before comparing, so two
+regions configured identically have no differences. A manifest only records the
+function, execution role, log group, and package checksum; other settings are
+compared only between live deployments.
+
+Exits with an error when differences are found.`,
+		Args: cobra.NoArgs,
+		RunE: runDiff,
+	}
+
+	cmd.Flags().StringVar(&diffFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().StringVar(&diffManifestFile, "manifest", "", "Compare with this saved deployment manifest instead of the local one")
+	cmd.Flags().StringVar(&diffFromRegion, "from-region", "", "Compare with the live deployment in this region")
+	cmd.Flags().StringVar(&diffFromProfile, "from-profile", "", "AWS credential profile for --from-region (default: --profile)")
+	cmd.Flags().StringVarP(&diffOutputFormat, "output", "o", "text", "Output format: text or json")
+	cmd.MarkFlagsMutuallyExclusive("manifest", "from-region")
+
+	return cmd
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+	functionName := resourceNaming.Apply(diffFunctionName)
+
+	if diffOutputFormat != "text" && diffOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", diffOutputFormat)
+	}
+	if diffFromProfile != "" && diffFromRegion == "" {
+		return fmt.Errorf("--from-profile requires --from-region")
+	}
+
+	awsConfig, err := diffAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+	if region == "" {
+		region = awsConfig.Region
+	}
+
+	var from *deployer.Snapshot
+	switch {
+	case diffFromRegion != "":
+		fromProfile := diffFromProfile
+		if fromProfile == "" {
+			fromProfile = profile
+		}
+		fromConfig, err := diffAWSConfig(ctx, fromProfile, diffFromRegion)
+		if err != nil {
+			return err
+		}
+		if from, err = liveSnapshot(ctx, fromConfig); err != nil {
+			return err
+		}
+	case diffManifestFile != "":
+		m, err := manifest.ReadFile(diffManifestFile)
+		if err != nil {
+			return err
+		}
+		if m.FunctionName != functionName {
+			warnf("⚠ %s records function %s, comparing it with %s\n", diffManifestFile, m.FunctionName, functionName)
+		}
+		from = manifestSnapshot(m)
+	default:
+		dir, err := config.ManifestDir()
+		if err != nil {
+			return err
+		}
+		m, err := manifest.NewStore(dir).Load(region, functionName)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return fmt.Errorf("no deployment of %s in %s is recorded in the local manifests; use --manifest or --from-region", functionName, region)
+		}
+		from = manifestSnapshot(m)
+	}
+
+	to, err := liveSnapshot(ctx, awsConfig)
+	if err != nil {
+		return err
+	}
+
+	differences := deployer.CompareSnapshots(from, to)
+	report := deploymentDiffReport{
+		From:        snapshotLabel(from),
+		To:          snapshotLabel(to),
+		Differences: differences,
+	}
+
+	if diffOutputFormat == "json" {
+		if report.Differences == nil {
+			report.Differences = []deployer.Difference{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to write diff: %w", err)
+		}
+	} else {
+		infof("Comparing %s with %s\n", report.From, report.To)
+		for _, difference := range differences {
+			fmt.Printf("~ %s: %s -> %s\n", difference.Setting, valueOrDash(difference.Expected), valueOrDash(difference.Actual))
+		}
+		if len(differences) == 0 {
+			fmt.Println("✓ No differences found")
+		}
+	}
+
+	if len(differences) > 0 {
+		return fmt.Errorf("deployments differ: %d difference(s) found", len(differences))
+	}
+	return nil
+}
+
+// diffAWSConfig loads the AWS configuration for one side of a diff
+func diffAWSConfig(ctx context.Context, profile, region string) (awssdk.Config, error) {
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return awssdk.Config{}, err
+	}
+	return awsConfig, nil
+}
+
+// liveSnapshot reads the deployed function's configuration in the config's region
+func liveSnapshot(ctx context.Context, awsConfig awssdk.Config) (*deployer.Snapshot, error) {
+	functionName := resourceNaming.Apply(diffFunctionName)
+	snapshot, err := deployer.TakeSnapshot(ctx, aws.NewLambdaClient(awsConfig), aws.NewIAMClient(awsConfig),
+		aws.NewCloudWatchLogsClient(awsConfig), functionName, tagKeyFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s in %s: %w", functionName, awsConfig.Region, err)
+	}
+	return snapshot, nil
+}
+
+// manifestSnapshot returns the settings a deployment manifest records
+func manifestSnapshot(m *manifest.Manifest) *deployer.Snapshot {
+	snapshot := &deployer.Snapshot{
+		FunctionName: m.FunctionName,
+		Region:       m.Region,
+		AccountID:    m.AccountID,
+		Settings:     make(map[string]string),
+		Partial:      true,
+	}
+	if parsed, err := arn.Parse(m.FunctionARN); err == nil && snapshot.AccountID == "" {
+		snapshot.AccountID = parsed.AccountID
+	}
+
+	for setting, value := range map[string]string{
+		deployer.SettingFunctionARN:     m.FunctionARN,
+		deployer.SettingExecutionRole:   m.ExecutionRoleARN,
+		deployer.SettingLogGroup:        m.LogGroupName,
+		deployer.SettingPackageChecksum: m.PackageChecksum,
+	} {
+		if value != "" {
+			snapshot.Settings[setting] = value
+		}
+	}
+	return snapshot
+}
+
+// snapshotLabel names the deployment a snapshot was taken of
+func snapshotLabel(s *deployer.Snapshot) string {
+	label := fmt.Sprintf("%s in %s", s.FunctionName, s.Region)
+	if s.AccountID != "" {
+		label += fmt.Sprintf(" (%s)", s.AccountID)
+	}
+	if s.Partial {
+		label += " as recorded in its manifest"
+	}
+	return label
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"errors"
+
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+)
+
+// Exit codes of deployment failures scripts may handle differently, such as retrying
+// a conflict but not a denied role. Other errors exit with exitCodeFailure.
+const (
+	exitCodeFailure            = 1
+	exitCodeRoleCreationDenied = 2
+	exitCodePackageTooLarge    = 3
+	exitCodeFunctionConflict   = 4
+	exitCodeIAMPropagation     = 5
+	exitCodeCodeSigning        = 6
+)
+
+// exitCodes maps deployment failure categories to their exit codes
+var exitCodes = []struct {
+	category error
+	code     int
+}{
+	{deployer.ErrRoleCreationDenied, exitCodeRoleCreationDenied},
+	{deployer.ErrPackageTooLarge, exitCodePackageTooLarge},
+	{deployer.ErrFunctionConflict, exitCodeFunctionConflict},
+	{deployer.ErrIAMPropagationTimeout, exitCodeIAMPropagation},
+	{deployer.ErrCodeSigning, exitCodeCodeSigning},
+}
+
+// exitCode returns the exit code for a command's error
+func exitCode(err error) int {
+	for _, mapping := range exitCodes {
+		if errors.Is(err, mapping.category) {
+			return mapping.code
+		}
+	}
+	return exitCodeFailure
+}
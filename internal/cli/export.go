@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/oidc"
+	"github.com/openshift-online/regional-cli/pkg/terraform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportImportFormat string
+	exportImportOut    string
+)
+
+// Terraform resource types of the resources rosactl manages
+var terraformResourceTypes = map[string]string{
+	deployer.ResourceTypeFunction:       "aws_lambda_function",
+	deployer.ResourceTypeAlias:          "aws_lambda_alias",
+	deployer.ResourceTypeResourcePolicy: "aws_lambda_permission",
+	deployer.ResourceTypeExecutionRole:  "aws_iam_role",
+	deployer.ResourceTypeRolePolicy:     "aws_iam_role_policy",
+	deployer.ResourceTypeLogGroup:       "aws_cloudwatch_log_group",
+}
+
+// NewExportCommand creates the export command
+func NewExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export rosactl-managed resources for other tools",
+	}
+
+	cmd.AddCommand(newExportTerraformImportCommand())
+
+	return cmd
+}
+
+func newExportTerraformImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "terraform-import",
+		Short: "Print the Terraform imports that move rosa-managed resources into a Terraform stack",
+		Long: `Finds the resources rosactl manages in the account and region, those tagged
+rosa:managed=true, and prints how to import each into Terraform:
+
+  - each OIDC provisioner function (aws_lambda_function), its aliases
+    (aws_lambda_alias), and the statements allowing CLM to invoke it
+    (aws_lambda_permission)
+  - its execution role (aws_iam_role) and inline policy (aws_iam_role_policy)
+  - its log group (aws_cloudwatch_log_group)
+  - the OIDC providers created for clusters (aws_iam_openid_connect_provider)
+
+With --format commands (the default) a terraform import command is printed for
+each resource; the Terraform configuration must already declare the resource at
+that address. With --format blocks, import blocks for Terraform 1.5 and later are
+printed instead, and 'terraform plan -generate-config-out=generated.tf' can write
+the matching configuration.
+
+Nothing in the account is changed. Once Terraform manages the resources, stop
+running setup-account and teardown against them.`,
+		Args: cobra.NoArgs,
+		RunE: runExportTerraformImport,
+	}
+
+	cmd.Flags().StringVar(&exportImportFormat, "format", "commands", "Output format: commands (terraform import commands) or blocks (import blocks)")
+	cmd.Flags().StringVar(&exportImportOut, "out", "", "File to write the imports to instead of printing them")
+
+	return cmd
+}
+
+func runExportTerraformImport(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+
+	write := terraform.WriteCommands
+	switch exportImportFormat {
+	case "commands":
+	case "blocks":
+		write = terraform.WriteImportBlocks
+	default:
+		return fmt.Errorf("unsupported format %q (expected commands or blocks)", exportImportFormat)
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+	if region == "" {
+		region = awsConfig.Region
+	}
+
+	infof("Finding rosa-managed resources in %s...\n", region)
+	iamClient := aws.NewIAMClient(awsConfig)
+	resources, err := deployer.FindManagedResources(ctx, aws.NewLambdaClient(awsConfig), iamClient,
+		aws.NewCloudWatchLogsClient(awsConfig), tagKeyFormat)
+	if err != nil {
+		return err
+	}
+	providers, err := oidc.ListProviders(ctx, iamClient, oidc.WithTagKeyFormat(tagKeyFormat))
+	if err != nil {
+		return err
+	}
+
+	var imports terraform.Imports
+	for _, resource := range resources {
+		addTerraformImport(&imports, resource)
+	}
+	for _, provider := range providers {
+		if !provider.Managed {
+			continue
+		}
+		name := provider.ClusterID
+		if name == "" {
+			name = provider.IssuerURL
+		}
+		imports.Add("aws_iam_openid_connect_provider", provider.ARN, "oidc", name)
+	}
+
+	if len(imports.List()) == 0 {
+		infoln("No rosa-managed resources found.")
+		return nil
+	}
+
+	if exportImportOut == "" {
+		return write(os.Stdout, imports.List())
+	}
+	var buf bytes.Buffer
+	if err := write(&buf, imports.List()); err != nil {
+		return err
+	}
+	if err := os.WriteFile(exportImportOut, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportImportOut, err)
+	}
+	infof("✓ Wrote %d import(s) to %s\n", len(imports.List()), exportImportOut)
+	return nil
+}
+
+// addTerraformImport adds the import of a managed resource, in the ID format the
+// Terraform AWS provider expects for its type, named after its function
+func addTerraformImport(imports *terraform.Imports, r deployer.ManagedResource) {
+	resourceType := terraformResourceTypes[r.Type]
+	switch r.Type {
+	case deployer.ResourceTypeAlias:
+		imports.Add(resourceType, r.Function+"/"+r.Name, r.Function, r.Name)
+	case deployer.ResourceTypeResourcePolicy:
+		if r.Qualifier != "" {
+			imports.Add(resourceType, r.Function+":"+r.Qualifier+"/"+r.Name, r.Function, r.Qualifier)
+			return
+		}
+		imports.Add(resourceType, r.Function+"/"+r.Name, r.Function)
+	case deployer.ResourceTypeRolePolicy:
+		imports.Add(resourceType, r.Role+":"+r.Name, r.Function)
+	default:
+		imports.Add(resourceType, r.Name, r.Function)
+	}
+}
@@ -0,0 +1,476 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/fleet"
+	"github.com/openshift-online/regional-cli/internal/report"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/openshift-online/regional-cli/pkg/lambda/metrics"
+	"github.com/spf13/cobra"
+)
+
+var (
+	fleetFile              string
+	fleetMaxParallel       int
+	fleetBatchSize         int
+	fleetPause             time.Duration
+	fleetReportPath        string
+	fleetOutputFormat      string
+	fleetFunctionName      string
+	fleetExecutionRoleName string
+	fleetTags              []string
+	fleetAdopt             bool
+	fleetNoPackageCache    bool
+
+	fleetStatusFile             string
+	fleetStatusMaxParallel      int
+	fleetStatusOutputFormat     string
+	fleetStatusFunctionName     string
+	fleetStatusSince            time.Duration
+	fleetStatusExpectedVersion  string
+	fleetStatusExpectedChecksum string
+)
+
+// NewFleetCommand creates the fleet command
+func NewFleetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fleet",
+		Short: "Manage the OIDC provisioner across many AWS accounts",
+	}
+
+	cmd.AddCommand(newFleetDeployCommand())
+	cmd.AddCommand(newFleetStatusCommand())
+
+	return cmd
+}
+
+func newFleetDeployCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "deploy -f <file>",
+		Short: "Deploy the OIDC provisioner to a fleet of accounts in staged batches",
+		Long: `Deploys the OIDC provisioner Lambda function to every account listed in a YAML
+file, assuming each account's role_arn with your credentials:
+
+  accounts:
+    - name: payments-prod
+      role_arn: arn:aws:iam::111111111111:role/rosactl-deployer
+      region: us-east-1
+    - role_arn: arn:aws:iam::222222222222:role/rosactl-deployer
+
+An account's name defaults to its account ID and its region to --region or your
+profile's region.
+
+Accounts are deployed in batches of --batch-size, at most --max-parallel at once.
+Once a batch is deployed, each of its functions is verified with a ping
+invocation. If any deployment or verification in a batch fails, the rollout
+stops and the remaining accounts are skipped; otherwise it waits
+--pause-between-batches and starts the next batch. Deployments in other accounts
+are not recorded in the local deployment manifests.
+
+A summary is printed when the rollout ends; use --report to also write the full
+report, as HTML for .html and .htm paths and JSON otherwise. The command fails
+if any account failed or was skipped.`,
+		Args: cobra.NoArgs,
+		RunE: runFleetDeploy,
+	}
+
+	cmd.Flags().StringVarP(&fleetFile, "file", "f", "", "YAML file listing the accounts to deploy to, or - for stdin (required)")
+	cmd.Flags().IntVar(&fleetMaxParallel, "max-parallel", fleet.DefaultMaxParallel, "Maximum number of accounts deployed at once")
+	cmd.Flags().IntVar(&fleetBatchSize, "batch-size", fleet.DefaultBatchSize, "Number of accounts verified together before the next batch starts")
+	cmd.Flags().DurationVar(&fleetPause, "pause-between-batches", 0, "Time to wait after a verified batch before starting the next (e.g. 10m)")
+	cmd.Flags().StringVar(&fleetReportPath, "report", "", "Write the rollout report to this file (.html for HTML, JSON otherwise)")
+	cmd.Flags().StringVarP(&fleetOutputFormat, "output", "o", "text", "Output format: text or json")
+	cmd.Flags().StringVar(&fleetFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().StringVar(&fleetExecutionRoleName, "execution-role-name", defaultExecutionRoleName, "Lambda execution role name")
+	cmd.Flags().StringArrayVar(&fleetTags, "tag", nil, "Tag to apply to deployed resources as key=value (repeatable, overrides config file tags)")
+	cmd.Flags().BoolVar(&fleetAdopt, "adopt", false, "Take ownership of pre-existing function, role, and log group resources not managed by rosactl")
+	cmd.Flags().BoolVar(&fleetNoPackageCache, "no-package-cache", false, "Compile the Lambda package even when a package built from the same source is cached")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func runFleetDeploy(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+
+	if fleetOutputFormat != "text" && fleetOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", fleetOutputFormat)
+	}
+	if fleetMaxParallel < 1 {
+		return errors.New("--max-parallel must be at least 1")
+	}
+	if fleetBatchSize < 1 {
+		return errors.New("--batch-size must be at least 1")
+	}
+	if fleetPause < 0 {
+		return errors.New("--pause-between-batches cannot be negative")
+	}
+	if err := deployer.ValidateNames(resourceNaming.Apply(fleetFunctionName), resourceNaming.Apply(fleetExecutionRoleName)); err != nil {
+		return err
+	}
+	tags, err := deploymentTags(fleetTags)
+	if err != nil {
+		return err
+	}
+
+	sourceDir, err := deployer.ResolveSourceDir(deployer.DefaultSourceDir)
+	if err != nil {
+		return err
+	}
+	if err := deployer.ValidateSourceDir(sourceDir); err != nil {
+		return err
+	}
+
+	accounts, err := loadFleetAccounts(ctx, profile, region, fleetFile)
+	if err != nil {
+		return err
+	}
+
+	// Build the package once up front, so every account's deployment reuses it from the
+	// package cache instead of compiling it again
+	if cacheDir := packageCacheDir(fleetNoPackageCache); cacheDir != "" {
+		infof("Building the Lambda package from %s...\n", sourceDir)
+		builder := deployer.NewPackageBuilder(sourceDir, deployer.WithPackageCache(deployer.NewPackageCache(cacheDir)))
+		_, checksum, err := builder.BuildContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to build Lambda package: %w", err)
+		}
+		if verbose {
+			infof("  Package checksum: %s\n", checksum)
+		}
+	}
+
+	deploy := func(ctx context.Context, account fleet.Account) (*fleet.Deployment, error) {
+		cfg, err := fleetAccountConfig(ctx, profile, account)
+		if err != nil {
+			return nil, err
+		}
+
+		var warnings bytes.Buffer
+		lambdaClient := aws.NewLambdaClient(cfg)
+		lambdaDeployer := deployer.NewDeployer(lambdaClient, aws.NewIAMClient(cfg),
+			aws.NewCloudWatchLogsClient(cfg), deployer.DeploymentConfig{
+				FunctionName:      fleetFunctionName,
+				ExecutionRoleName: fleetExecutionRoleName,
+				Naming:            resourceNaming,
+				SourceDir:         sourceDir,
+				Region:            account.Region,
+				Runtime:           deployer.DefaultRuntime(account.Region),
+				MemorySize:        defaultMemorySize,
+				Timeout:           defaultTimeout,
+				Architecture:      lambdaTypes.ArchitectureX8664,
+				Tags:              tags,
+				Adopt:             fleetAdopt,
+				CLIVersion:        version,
+				TagKeyFormat:      tagKeyFormat,
+				PackageCacheDir:   packageCacheDir(fleetNoPackageCache),
+			},
+			deployer.WithSTSClient(aws.NewSTSClient(cfg)),
+			deployer.WithCodeSigningClient(lambdaClient),
+			deployer.WithWarningOutput(&warnings))
+
+		result, err := lambdaDeployer.Deploy(ctx)
+		if err != nil {
+			var unmanagedErr *deployer.UnmanagedResourceError
+			if errors.As(err, &unmanagedErr) {
+				return nil, fmt.Errorf("%s %s already exists but is not managed by rosactl; re-run with --adopt to take ownership of it",
+					unmanagedErr.Type, unmanagedErr.Identifier)
+			}
+			var policyErr *deployer.TagPolicyAPIError
+			if errors.As(err, &policyErr) {
+				return nil, tagPolicyAPIError(policyErr)
+			}
+			return nil, err
+		}
+
+		deployment := &fleet.Deployment{
+			FunctionARN: result.FunctionARN,
+			Status:      result.Status,
+			Version:     result.Version,
+		}
+		for _, line := range strings.Split(strings.TrimSpace(warnings.String()), "\n") {
+			if line = strings.TrimSpace(strings.TrimPrefix(line, "Warning:")); line != "" {
+				deployment.Warnings = append(deployment.Warnings, line)
+			}
+		}
+		return deployment, nil
+	}
+
+	verify := func(ctx context.Context, account fleet.Account, deployment *fleet.Deployment) error {
+		cfg, err := fleetAccountConfig(ctx, profile, account)
+		if err != nil {
+			return err
+		}
+		_, _, err = invoker.NewInvoker(aws.NewLambdaClient(cfg), deployment.FunctionARN).Ping(ctx)
+		return err
+	}
+
+	infof("Deploying %s to %d accounts in batches of %d (%d at a time)...\n",
+		resourceNaming.Apply(fleetFunctionName), len(accounts), fleetBatchSize, fleetMaxParallel)
+	rollout := fleet.Rollout(ctx, accounts, deploy, verify, fleet.Options{
+		MaxParallel: fleetMaxParallel,
+		BatchSize:   fleetBatchSize,
+		Pause:       fleetPause,
+		OnBatch: func(batch, batches int) {
+			if batch > 1 && fleetPause > 0 {
+				infof("Paused %s after batch %d\n", fleetPause, batch-1)
+			}
+			infof("\n[%d/%d] Batch %d\n", batch, batches, batch)
+		},
+		OnResult: func(result fleet.AccountResult) {
+			switch result.Status {
+			case fleet.StatusSucceeded:
+				infof("✓ %s (%s): function %s and verified: %s\n", result.Account.Name, result.Account.Region, result.DeployStatus, result.FunctionARN)
+			case fleet.StatusDeployFailed:
+				infof("✗ %s (%s): deployment failed: %s\n", result.Account.Name, result.Account.Region, result.Error)
+			case fleet.StatusVerificationFailed:
+				infof("✗ %s (%s): verification failed: %s\n", result.Account.Name, result.Account.Region, result.Error)
+			}
+			for _, warning := range result.Warnings {
+				warnf("⚠ %s (%s): %s\n", result.Account.Name, result.Account.Region, warning)
+			}
+			if verbose {
+				infof("  in %dms\n", result.DurationMS)
+			}
+		},
+	})
+
+	if fleetReportPath != "" {
+		if err := report.WriteFleetFile(fleetReportPath, rollout); err != nil {
+			warnf("⚠ Failed to write rollout report: %v\n", err)
+		} else {
+			infof("Rollout report written to %s\n", fleetReportPath)
+		}
+	}
+
+	if fleetOutputFormat == "json" {
+		if err := writeJSON(rollout); err != nil {
+			return err
+		}
+	} else {
+		printFleetSummary(rollout)
+	}
+
+	succeeded := rollout.Count(fleet.StatusSucceeded)
+	if succeeded < rollout.Total() {
+		return fmt.Errorf("fleet rollout halted: %d of %d accounts deployed and verified, %d skipped; fix the failures above and run it again with the same file",
+			succeeded, rollout.Total(), rollout.Count(fleet.StatusSkipped))
+	}
+	return nil
+}
+
+// loadFleetAccounts reads the accounts file at path. Accounts without a region get
+// region, or the region of profile when region is empty.
+func loadFleetAccounts(ctx context.Context, profile, region, path string) ([]fleet.Account, error) {
+	baseConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return fleet.LoadAccounts(path, baseConfig.Region)
+}
+
+// fleetAccountConfig loads the AWS config of an account of the fleet, with the
+// credentials of its role assumed with those of profile
+func fleetAccountConfig(ctx context.Context, profile string, account fleet.Account) (awssdk.Config, error) {
+	cfg, err := newAWSConfigWithRole(ctx, profile, account.Region, account.RoleARN)
+	if err != nil {
+		return awssdk.Config{}, err
+	}
+	return cfg, nil
+}
+
+// printFleetSummary prints the outcome of every account of a rollout as a table
+func printFleetSummary(rollout *fleet.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "BATCH\tACCOUNT\tACCOUNT ID\tREGION\tSTATUS\tFUNCTION ARN")
+	for _, batch := range rollout.Batches {
+		for _, result := range batch.Accounts {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", batch.Number, result.Account.Name, result.AccountID,
+				result.Account.Region, result.Status, valueOrDash(result.FunctionARN))
+		}
+	}
+	w.Flush()
+}
+
+func newFleetStatusCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status -f <file>",
+		Short: "Report the OIDC provisioner release, drift, and errors across a fleet of accounts",
+		Long: `Reads the OIDC provisioner Lambda in every account listed in a YAML file, as for
+fleet deploy, assuming each account's role_arn with your credentials. For each
+account it reports the function's version, state, and the rosactl release and
+package checksum stamped on it, the drift from --expected-version and
+--expected-checksum as provisioner drift does, and the invocations, errors, and
+throttles of the last --since from CloudWatch.
+
+With -o json the consolidated dataset is printed for dashboards tracking
+provisioner releases across the fleet, including the number of accounts on each
+release. The command fails if any account's status could not be read; the
+dataset is printed first.`,
+		Example: `  rosactl fleet status -f accounts.yaml --output json > fleet-status.json`,
+		Args:    cobra.NoArgs,
+		RunE:    runFleetStatus,
+	}
+
+	cmd.Flags().StringVarP(&fleetStatusFile, "file", "f", "", "YAML file listing the accounts, or - for stdin (required)")
+	cmd.Flags().IntVar(&fleetStatusMaxParallel, "max-parallel", fleet.DefaultMaxParallel, "Maximum number of accounts read at once")
+	cmd.Flags().StringVarP(&fleetStatusOutputFormat, "output", "o", "text", "Output format: text or json")
+	cmd.Flags().StringVar(&fleetStatusFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().DurationVar(&fleetStatusSince, "since", defaultMetricsSince, "Summarize metrics from this long ago until now")
+	cmd.Flags().StringVar(&fleetStatusExpectedVersion, "expected-version", version, "rosactl version the functions should have been deployed with")
+	cmd.Flags().StringVar(&fleetStatusExpectedChecksum, "expected-checksum", "", "Package checksum the functions should be running (not compared by default)")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func runFleetStatus(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+	functionName := resourceNaming.Apply(fleetStatusFunctionName)
+
+	if fleetStatusOutputFormat != "text" && fleetStatusOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", fleetStatusOutputFormat)
+	}
+	if fleetStatusMaxParallel < 1 {
+		return errors.New("--max-parallel must be at least 1")
+	}
+	if fleetStatusSince <= 0 {
+		return errors.New("--since must be positive")
+	}
+
+	accounts, err := loadFleetAccounts(ctx, profile, region, fleetStatusFile)
+	if err != nil {
+		return err
+	}
+
+	expected := fleet.ExpectedRelease{
+		CLIVersion:      fleetStatusExpectedVersion,
+		PackageChecksum: fleetStatusExpectedChecksum,
+	}
+	end := time.Now()
+	start := end.Add(-fleetStatusSince)
+
+	status := func(ctx context.Context, account fleet.Account) (*fleet.AccountStatus, error) {
+		cfg, err := fleetAccountConfig(ctx, profile, account)
+		if err != nil {
+			return nil, err
+		}
+
+		function, err := aws.NewLambdaClient(cfg).GetFunction(ctx, &lambda.GetFunctionInput{
+			FunctionName: awssdk.String(functionName),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get function %s: %w", functionName, err)
+		}
+
+		stamp := deployer.ReadStamp(function, tagKeyFormat)
+		result := &fleet.AccountStatus{
+			CLIVersion:      stamp.CLIVersion,
+			PackageChecksum: stamp.PackageChecksum,
+		}
+		if configuration := function.Configuration; configuration != nil {
+			result.FunctionARN = awssdk.ToString(configuration.FunctionArn)
+			result.Version = awssdk.ToString(configuration.Version)
+			result.CodeSha256 = awssdk.ToString(configuration.CodeSha256)
+			result.State = string(configuration.State)
+			result.LastUpdateStatus = string(configuration.LastUpdateStatus)
+			result.LastModified = awssdk.ToString(configuration.LastModified)
+		}
+		drifts := deployer.CompareStamp(deployer.Stamp{
+			CLIVersion:      expected.CLIVersion,
+			PackageChecksum: expected.PackageChecksum,
+		}, stamp, result.CodeSha256)
+		for _, drift := range drifts {
+			result.Drift = append(result.Drift, drift.Message)
+		}
+
+		summary, err := metrics.NewCollector(aws.NewCloudWatchClient(cfg), functionName).Summarize(ctx, start, end)
+		if err != nil {
+			return result, fmt.Errorf("failed to read metrics: %w", err)
+		}
+		result.Metrics = &fleet.Metrics{
+			Invocations:   summary.Invocations,
+			Errors:        summary.Errors,
+			Throttles:     summary.Throttles,
+			ErrorRate:     summary.ErrorRate(),
+			DurationP95MS: summary.DurationP95.Milliseconds(),
+		}
+		return result, nil
+	}
+
+	infof("Reading the status of %s in %d accounts (%d at a time)...\n", functionName, len(accounts), fleetStatusMaxParallel)
+	statusReport := fleet.NewStatusReport(fleet.GatherStatus(ctx, accounts, status, fleetStatusMaxParallel), expected, start)
+	for _, account := range statusReport.Accounts {
+		if account.Error != "" {
+			infof("✗ %s (%s): %s\n", account.Account.Name, account.Account.Region, account.Error)
+		}
+	}
+
+	if fleetStatusOutputFormat == "json" {
+		if err := writeJSON(statusReport); err != nil {
+			return err
+		}
+	} else {
+		printFleetStatus(statusReport)
+	}
+
+	if statusReport.Unreadable > 0 {
+		return fmt.Errorf("fleet status incomplete: the status of %d of %d accounts could not be read",
+			statusReport.Unreadable, len(statusReport.Accounts))
+	}
+	return nil
+}
+
+// printFleetStatus prints a fleet status report as a table of accounts followed by
+// the number of accounts on each rosactl release
+func printFleetStatus(report *fleet.StatusReport) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACCOUNT\tACCOUNT ID\tREGION\tSTATE\tROSACTL VERSION\tCHECKSUM\tDRIFT\tERRORS")
+	for _, account := range report.Accounts {
+		state, drift, errorRate := valueOrDash(account.State), "-", "-"
+		if account.FunctionARN != "" {
+			drift = "none"
+			if len(account.Drift) > 0 {
+				drift = strings.Join(account.Drift, "; ")
+			}
+		}
+		if account.Metrics != nil {
+			errorRate = fmt.Sprintf("%.0f (%.1f%%)", account.Metrics.Errors, account.Metrics.ErrorRate*100)
+		}
+		if account.Error != "" {
+			state = "unreadable"
+		}
+		checksum := account.PackageChecksum
+		if len(checksum) > 12 {
+			checksum = checksum[:12]
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", account.Account.Name, account.AccountID, account.Account.Region,
+			state, valueOrDash(account.CLIVersion), valueOrDash(checksum), drift, errorRate)
+	}
+	w.Flush()
+
+	fmt.Println()
+	for _, count := range report.VersionCounts() {
+		release := count.CLIVersion
+		if release == "" {
+			release = "unstamped"
+		}
+		fmt.Printf("%s: %d accounts\n", release, count.Accounts)
+	}
+	fmt.Printf("%d drifted, %d unreadable\n", report.Drifted, report.Unreadable)
+}
@@ -2,43 +2,94 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/cache"
+	"github.com/openshift-online/regional-cli/internal/config"
 	"github.com/openshift-online/regional-cli/internal/validator"
+	"github.com/openshift-online/regional-cli/pkg/dualstack"
+	"github.com/openshift-online/regional-cli/pkg/proxy"
 	"github.com/spf13/cobra"
 )
 
+const (
+	// validationCacheTTL bounds how long successful validation results are reused
+	validationCacheTTL = 5 * time.Minute
+)
+
+var (
+	allowRoot        bool
+	initOutputFormat string
+)
+
+// initReport is the machine-readable result of the init command. Status and Checks
+// summarize the run for onboarding automation; the remaining fields carry the raw results.
+type initReport struct {
+	Status validator.CheckStatus `json:"status"`
+	Error  string                `json:"error,omitempty"`
+	Checks validator.Checks      `json:"checks"`
+	Cached bool                  `json:"cached"`
+	AWS    *validator.AWSResult  `json:"aws,omitempty"`
+}
+
 // NewInitCommand creates the init command
 func NewInitCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Validate AWS credentials and Platform API connectivity",
 		Long: `Validates that:
+  - The HTTP(S) proxy, if one is configured, accepts connections
   - AWS credentials are configured and valid
+  - AWS credentials do not belong to the account root user
+  - AWS account is active and not the organization management account
   - AWS region is set and supported
-  - Platform API is reachable (if URL is provided)`,
+  - Platform API is reachable (if URL is provided)
+  - The caller can invoke the Platform API in the target region from this network,
+    telling network failures apart from authorization failures such as a VPC
+    endpoint policy denying execute-api
+  - Organization-provided plugins in ~/.rosactl/validators.d/ pass
+
+Commands and webhooks configured under validation_hooks run before (stage: pre) and
+after (stage: post) these checks; post hooks receive the aggregated results. A failed
+hook fails init, so onboarding can be gated on internal approval systems.`,
 		RunE: runInit,
 	}
 
+	cmd.Flags().BoolVar(&allowRoot, "allow-root", false, "Warn instead of failing when using AWS root account credentials")
+	cmd.Flags().StringVarP(&initOutputFormat, "output", "o", "text", "Output format: text or json")
+
 	return cmd
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
 	profile, region, verbose, platformAPIURL := getGlobalFlags()
 
+	if initOutputFormat != "text" && initOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", initOutputFormat)
+	}
+	jsonOutput := initOutputFormat == "json"
+	if jsonOutput {
+		verbose = false
+	}
+	report := &initReport{}
+
 	if verbose {
-		fmt.Println("Validating AWS credentials and configuration...")
+		infoln("Validating AWS credentials and configuration...")
 	}
 
 	// Create AWS config
-	awsConfig, err := aws.NewConfig(ctx, aws.ClientConfig{
-		Profile: profile,
-		Region:  region,
-	})
+	awsConfig, err := newAWSConfig(ctx, profile, region)
 	if err != nil {
-		return fmt.Errorf("failed to load AWS config: %w", err)
+		return err
 	}
 
 	// If region not specified via flag, get it from config
@@ -46,60 +97,313 @@ func runInit(cmd *cobra.Command, args []string) error {
 		region = awsConfig.Region
 	}
 
-	// Validate AWS credentials
-	stsClient := aws.NewSTSClient(awsConfig)
-	awsValidator := validator.NewAWSValidator(stsClient, region)
+	// Reuse a recent successful validation for the same identity and region. Hooks
+	// and plugins gate every run, so their results are never served from the cache:
+	// hooks disable it, and plugins run again on a hit.
+	validationCache := openValidationCache()
+	if len(effectiveConfig.Config.ValidationHooks) > 0 {
+		validationCache = nil
+	}
+	cacheKey := initCacheKey(ctx, awsConfig, region, platformAPIURL)
+	if validationCache != nil && cacheKey != "" {
+		var cached initReport
+		if hit, _ := validationCache.Get(cacheKey, &cached); hit {
+			cached.Cached = true
+			return printCachedInitReport(ctx, &cached, platformAPIURL, jsonOutput, verbose)
+		}
+	}
 
-	awsResult, err := awsValidator.Validate(ctx)
+	if err := validateAccount(ctx, report, awsConfig, region, platformAPIURL, jsonOutput, verbose); err != nil {
+		return report.fail(jsonOutput, err)
+	}
+
+	if validationCache != nil && cacheKey != "" {
+		cached := *report
+		cached.Checks = report.Checks.WithoutPlugins()
+		if err := validationCache.Put(cacheKey, cached); err != nil && verbose {
+			warnf("Warning: failed to cache validation results: %v\n", err)
+		}
+	}
+
+	if jsonOutput {
+		return printInitReport(report, nil)
+	}
+
+	infoln("\nValidation complete. Your environment is configured correctly.")
+	return nil
+}
+
+// validateAccount runs the pre validation hooks, the validation suite, and the post
+// hooks, recording every check in the report. A failed pre hook stops validation.
+func validateAccount(ctx context.Context, report *initReport, awsConfig awssdk.Config, region, platformAPIURL string, jsonOutput, verbose bool) error {
+	hookRunner, err := validator.NewHookRunner(effectiveConfig.Config.ValidationHooks,
+		validator.WithHookHTTPClient(&http.Client{Transport: proxy.Transport(awsConfig)}))
 	if err != nil {
-		fmt.Printf("✗ AWS credentials validation failed\n")
+		return fmt.Errorf("invalid validation_hooks: %w", err)
+	}
+
+	hookInput := validator.HookInput{PluginInput: validator.PluginInput{
+		Region:         region,
+		PlatformAPIURL: platformAPIURL,
+		Command:        "init",
+	}}
+	if err := runValidationHooks(ctx, hookRunner, validator.HookPre, hookInput, report, jsonOutput, verbose); err != nil {
 		return err
 	}
 
-	if !awsResult.Valid {
-		fmt.Printf("✗ AWS validation failed: %s\n", awsResult.ErrorMessage)
-		return fmt.Errorf("AWS validation failed")
+	validationErr := validateInit(ctx, report, awsConfig, region, platformAPIURL, jsonOutput, verbose)
+
+	// Post hooks receive the aggregated results whether or not validation passed
+	if report.AWS != nil {
+		hookInput.AccountID = report.AWS.AccountID
+		hookInput.UserARN = report.AWS.UserARN
+	}
+	hookInput.Status = report.Checks.Status()
+	if validationErr != nil {
+		hookInput.Status = validator.CheckFailed
+		hookInput.Error = validationErr.Error()
+	}
+	hookInput.Checks = report.Checks
+	if err := runValidationHooks(ctx, hookRunner, validator.HookPost, hookInput, report, jsonOutput, verbose); err != nil && validationErr == nil {
+		validationErr = err
+	}
+	return validationErr
+}
+
+// validateInit runs the validation suite: the proxy, AWS, and Platform API checks and
+// the validator plugins, recording their results in the report
+func validateInit(ctx context.Context, report *initReport, awsConfig awssdk.Config, region, platformAPIURL string, jsonOutput, verbose bool) error {
+	// Check the proxy first so an unreachable proxy is not reported as failed AWS calls
+	proxyCheck := validator.NewProxyValidator(proxy.FromConfig(awsConfig)).Validate(ctx, initProxyTargets(region, platformAPIURL)...)
+	report.addChecks(jsonOutput, verbose, proxyCheck)
+	if proxyCheck.Status == validator.CheckFailed {
+		return fmt.Errorf("proxy check failed")
 	}
 
-	fmt.Printf("✓ AWS credentials valid\n")
+	// Validate AWS credentials
+	stsClient := aws.NewSTSClient(awsConfig)
+	orgClient := aws.NewOrganizationsClient(awsConfig)
+	awsValidator := validator.NewAWSValidator(stsClient, region,
+		validator.WithAllowRoot(allowRoot),
+		validator.WithOrganizationsClient(orgClient),
+		validator.WithRegionOptInChecker(validator.NewRegionOptInChecker(awsConfig)),
+	)
+
+	awsResult, err := awsValidator.Validate(ctx)
+	report.AWS = awsResult
+	report.addChecks(jsonOutput, verbose, awsResult.Checks...)
+	if err != nil {
+		return err
+	}
 	if verbose {
-		fmt.Printf("  Account ID: %s\n", awsResult.AccountID)
-		fmt.Printf("  User ARN: %s\n", awsResult.UserARN)
-		fmt.Printf("  Region: %s\n", awsResult.Region)
+		infof("  Account ID: %s\n", awsResult.AccountID)
+		if awsResult.OrganizationID != "" {
+			infof("  Organization ID: %s\n", awsResult.OrganizationID)
+		}
+		if awsResult.AccountState != "" {
+			infof("  Account State: %s\n", awsResult.AccountState)
+		}
 	}
 
 	// Validate Platform API connectivity (if URL provided)
 	if platformAPIURL != "" {
+		platformValidator := validator.NewPlatformValidator(platformAPIURL, awsConfig,
+			validator.WithDualStack(useDualStack))
 		if verbose {
-			fmt.Printf("Validating Platform API connectivity to %s...\n", platformAPIURL)
+			infof("Validating Platform API connectivity to %s...\n", platformValidator.APIURL())
 		}
 
-		platformValidator := validator.NewPlatformValidator(platformAPIURL, awsConfig)
-		platformResult, err := platformValidator.Validate(ctx)
+		platformCheck, err := platformValidator.Validate(ctx)
+		report.addChecks(jsonOutput, verbose, platformCheck)
+		if err != nil {
+			return err
+		}
 
+		accessCheck, err := platformValidator.ValidateAccess(ctx, region)
+		report.addChecks(jsonOutput, verbose, accessCheck)
 		if err != nil {
-			fmt.Printf("✗ Platform API validation failed\n")
-			fmt.Printf("  Error: %s\n", platformResult.ErrorMessage)
 			return err
 		}
+	} else {
+		report.addChecks(jsonOutput, verbose, validator.CheckResult{
+			Name:        validator.CheckPlatformAPI,
+			Status:      validator.CheckSkipped,
+			Detail:      "no Platform API URL configured",
+			Remediation: validator.RemediationPlatformURL,
+		})
+	}
 
-		if !platformResult.Valid {
-			fmt.Printf("✗ Platform API validation failed: %s\n", platformResult.ErrorMessage)
-			return fmt.Errorf("Platform API validation failed")
+	// Run organization-provided validator plugins
+	return runValidatorPlugins(ctx, report, platformAPIURL, jsonOutput, verbose)
+}
+
+// addChecks records checks in the report and, for text output, prints them
+func (r *initReport) addChecks(jsonOutput, verbose bool, checks ...validator.CheckResult) {
+	r.Checks = append(r.Checks, checks...)
+	if jsonOutput {
+		return
+	}
+	for _, check := range checks {
+		printCheck(check, verbose, "")
+	}
+}
+
+// fail ends validation with err, first writing the report for JSON output
+func (r *initReport) fail(jsonOutput bool, err error) error {
+	if jsonOutput {
+		return printInitReport(r, err)
+	}
+	return err
+}
+
+// runValidatorPlugins executes external validator plugins and records their results in the report
+func runValidatorPlugins(ctx context.Context, report *initReport, platformAPIURL string, jsonOutput, verbose bool) error {
+	pluginDir, err := config.ValidatorPluginDir()
+	if err != nil {
+		return nil
+	}
+
+	pluginValidator := validator.NewPluginValidator(pluginDir)
+	results, err := pluginValidator.Validate(ctx, validator.PluginInput{
+		AccountID:      report.AWS.AccountID,
+		UserARN:        report.AWS.UserARN,
+		Region:         report.AWS.Region,
+		PlatformAPIURL: platformAPIURL,
+		Command:        "init",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run validator plugins: %w", err)
+	}
+
+	failed := 0
+	for _, result := range results {
+		report.addChecks(jsonOutput, verbose, result.Check())
+		if !result.Valid {
+			failed++
 		}
+	}
 
-		fmt.Printf("✓ Platform API reachable\n")
-		if verbose {
-			fmt.Printf("  Base URL: %s\n", platformAPIURL)
-			fmt.Printf("  Live endpoint: %s/prod/v0/live\n", platformAPIURL)
-			fmt.Printf("  Response: %s\n", platformResult.APIVersion)
+	if failed > 0 {
+		return fmt.Errorf("%d validator plugin(s) failed", failed)
+	}
+	return nil
+}
+
+// runValidationHooks runs the validation hooks of a stage and records their results in the report
+func runValidationHooks(ctx context.Context, runner *validator.HookRunner, stage string, input validator.HookInput, report *initReport, jsonOutput, verbose bool) error {
+	checks, err := runner.Run(ctx, stage, input)
+	if err != nil {
+		return fmt.Errorf("failed to run validation hooks: %w", err)
+	}
+	report.addChecks(jsonOutput, verbose, checks...)
+
+	failed := 0
+	for _, check := range checks {
+		if check.Status == validator.CheckFailed {
+			failed++
 		}
-	} else {
-		if verbose {
-			fmt.Println("Skipping Platform API validation (no URL provided)")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d %s-validation hook(s) failed", failed, stage)
+	}
+	return nil
+}
+
+// initProxyTargets returns the endpoints init connects to, for the proxy check
+func initProxyTargets(region, platformAPIURL string) []string {
+	domain := "amazonaws.com"
+	if useDualStack {
+		domain = "api.aws"
+	}
+	targets := []string{fmt.Sprintf("https://sts.%s.%s", region, domain)}
+	if region == "" {
+		targets[0] = "https://sts.amazonaws.com"
+	}
+
+	if platformAPIURL != "" {
+		if useDualStack {
+			platformAPIURL = dualstack.EndpointURL(platformAPIURL)
+		}
+		targets = append(targets, platformAPIURL)
+	}
+	return targets
+}
+
+// openValidationCache returns the validation results cache, or nil when caching is disabled
+func openValidationCache() *cache.Cache {
+	if noCache {
+		return nil
+	}
+
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		return nil
+	}
+
+	return cache.New(filepath.Join(cacheDir, "validation"), validationCacheTTL)
+}
+
+// initCacheKey identifies a validation run by credentials, region, and checked endpoints.
+// The access key ID maps to a single account, so cached results are effectively keyed by
+// account and region without calling STS; the cache stores only a digest of the key.
+// An empty key disables caching.
+func initCacheKey(ctx context.Context, awsConfig awssdk.Config, region, platformAPIURL string) string {
+	if awsConfig.Credentials == nil {
+		return ""
+	}
+
+	credentials, err := awsConfig.Credentials.Retrieve(ctx)
+	if err != nil || credentials.AccessKeyID == "" {
+		return ""
+	}
+
+	return strings.Join([]string{
+		"init",
+		credentials.AccessKeyID,
+		region,
+		platformAPIURL,
+		fmt.Sprintf("allow-root=%t", allowRoot),
+		"proxy=" + proxyURL,
+	}, "|")
+}
+
+// printCachedInitReport renders a previously successful validation, running the
+// validator plugins again since their results are not cached
+func printCachedInitReport(ctx context.Context, report *initReport, platformAPIURL string, jsonOutput, verbose bool) error {
+	if !jsonOutput {
+		for _, check := range report.Checks {
+			printCheck(check, verbose, " (cached)")
 		}
 	}
+	if err := runValidatorPlugins(ctx, report, platformAPIURL, jsonOutput, verbose); err != nil {
+		return report.fail(jsonOutput, err)
+	}
+	if jsonOutput {
+		return printInitReport(report, nil)
+	}
 
-	fmt.Println("\nValidation complete. Your environment is configured correctly.")
+	infoln("\nValidation complete. Your environment is configured correctly.")
+	if verbose {
+		infoln("Results were served from the local cache; use --no-cache to re-validate.")
+	}
 	return nil
 }
+
+// printInitReport writes the init report as JSON to stdout and passes through the validation error
+func printInitReport(report *initReport, validationErr error) error {
+	report.Status = report.Checks.Status()
+	if validationErr != nil {
+		report.Status = validator.CheckFailed
+		report.Error = validationErr.Error()
+	}
+	if report.Checks == nil {
+		report.Checks = validator.Checks{}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to encode init report: %w", err)
+	}
+	return validationErr
+}
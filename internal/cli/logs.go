@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/lambda/insights"
+	"github.com/openshift-online/regional-cli/pkg/lambda/logtail"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultInsightsSince = 24 * time.Hour
+)
+
+var (
+	insightsFunctionName string
+	insightsSince        time.Duration
+	insightsList         bool
+)
+
+// NewLogsCommand creates the logs command
+func NewLogsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Inspect OIDC provisioner Lambda logs",
+	}
+
+	cmd.AddCommand(newLogsInsightsCommand())
+
+	return cmd
+}
+
+func newLogsInsightsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "insights [query...]",
+		Short: "Run saved CloudWatch Logs Insights queries against the provisioner log group",
+		Long: `Runs canned CloudWatch Logs Insights queries against the OIDC provisioner's
+log group and prints the results as tables. With no arguments every saved query
+is run. Use --list to show the available queries.`,
+		RunE: runLogsInsights,
+	}
+
+	cmd.Flags().StringVar(&insightsFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().DurationVar(&insightsSince, "since", defaultInsightsSince, "Query logs from this long ago until now")
+	cmd.Flags().BoolVar(&insightsList, "list", false, "List the saved queries and exit")
+
+	return cmd
+}
+
+func runLogsInsights(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+
+	if insightsList {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, q := range insights.SavedQueries() {
+			fmt.Fprintf(w, "%s\t%s\n", q.Name, q.Description)
+		}
+		return w.Flush()
+	}
+
+	if insightsSince <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+
+	queries := insights.SavedQueries()
+	if len(args) > 0 {
+		queries = nil
+		for _, name := range args {
+			q, ok := insights.LookupSavedQuery(name)
+			if !ok {
+				return fmt.Errorf("unknown query %q (run with --list to see saved queries)", name)
+			}
+			queries = append(queries, q)
+		}
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	logGroupName := functionLogGroupName(ctx, aws.NewLambdaClient(awsConfig), resourceNaming.Apply(insightsFunctionName))
+	runner := insights.NewRunner(aws.NewLogsInsightsClient(awsConfig))
+
+	end := time.Now()
+	start := end.Add(-insightsSince)
+
+	for i, q := range queries {
+		if i > 0 {
+			infoln()
+		}
+		infof("%s: %s\n", q.Name, q.Description)
+		if verbose {
+			infof("  Log Group: %s\n", logGroupName)
+			infof("  Query: %s\n", strings.ReplaceAll(q.Query, "\n", " "))
+		}
+
+		result, err := runner.Run(ctx, logGroupName, q.Query, start, end)
+		if err != nil {
+			infof("✗ Query %s failed\n", q.Name)
+			return err
+		}
+
+		if err := printInsightsResult(result); err != nil {
+			return err
+		}
+		if verbose {
+			infof("  Records matched: %.0f, scanned: %.0f\n", result.RecordsMatched, result.RecordsScanned)
+		}
+	}
+
+	return nil
+}
+
+// functionLogGroupName returns the log group a function writes to, which its logging
+// config may set to other than Lambda's default, falling back to the default when the
+// function cannot be read
+func functionLogGroupName(ctx context.Context, client aws.LambdaAPI, functionName string) string {
+	output, err := client.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: awssdk.String(functionName),
+	})
+	if err != nil || output.Configuration == nil {
+		return logtail.LogGroupName(functionName)
+	}
+	return deployer.FunctionLogGroupName(output.Configuration, functionName)
+}
+
+// printInsightsResult renders query results as an aligned table
+func printInsightsResult(result *insights.Result) error {
+	if len(result.Rows) == 0 {
+		infoln("No results.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(result.Fields, "\t")))
+	for _, row := range result.Rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,651 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	"github.com/openshift-online/regional-cli/pkg/oidc"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reconcileFunctionName string
+	reconcileIssuerHosts  []string
+	reconcileDryRun       bool
+
+	oidcListAll          bool
+	oidcListOutputFormat string
+	oidcDescribeFormat   string
+
+	backfillFile         string
+	backfillFunctionName string
+	backfillConcurrency  int
+	backfillRetries      int
+	backfillOutputFormat string
+	backfillStrict       bool
+
+	createClusterID    string
+	createIssuerURL    string
+	createThumbprint   string
+	createClientIDs    []string
+	createFunctionName string
+	createStrict       bool
+
+	historyTable        string
+	historyIssuerURL    string
+	historyClusterID    string
+	historySince        time.Duration
+	historyLimit        int
+	historyOutputFormat string
+)
+
+// NewOIDCCommand creates the oidc command
+func NewOIDCCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "oidc",
+		Short: "Manage the account's cluster OIDC providers",
+	}
+
+	cmd.AddCommand(newOIDCListCommand())
+	cmd.AddCommand(newOIDCDescribeCommand())
+	cmd.AddCommand(newOIDCCreateCommand())
+	cmd.AddCommand(newOIDCReconcileCommand())
+	cmd.AddCommand(newOIDCBackfillCommand())
+	cmd.AddCommand(newOIDCHistoryCommand())
+
+	return cmd
+}
+
+func newOIDCListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the OIDC providers created for clusters, with their metadata",
+		Long: `Lists the account's OIDC providers tagged rosa:component=oidc-provider with the
+cluster, platform environment, and description the provisioner tagged them with.
+IAM OIDC providers have no description of their own, so the provisioner records
+these as rosa: tags; see setup-account --provider-description and
+--platform-environment.`,
+		Args: cobra.NoArgs,
+		RunE: runOIDCList,
+	}
+
+	cmd.Flags().BoolVar(&oidcListAll, "all", false, "Also list providers not created by the provisioner")
+	cmd.Flags().StringVarP(&oidcListOutputFormat, "output", "o", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func runOIDCList(cmd *cobra.Command, args []string) error {
+	if oidcListOutputFormat != "text" && oidcListOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", oidcListOutputFormat)
+	}
+
+	providers, err := listOIDCProviders(cmd.Context())
+	if err != nil {
+		return err
+	}
+	listed := []oidcProviderView{}
+	for _, provider := range providers {
+		if provider.Managed || oidcListAll {
+			listed = append(listed, newOIDCProviderView(provider))
+		}
+	}
+
+	if oidcListOutputFormat == "json" {
+		return writeJSON(listed)
+	}
+	if len(listed) == 0 {
+		infoln("No OIDC providers found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ISSUER\tCLUSTER\tENVIRONMENT\tCREATED AT\tDESCRIPTION")
+	for _, provider := range listed {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			provider.IssuerURL,
+			valueOrDash(provider.ClusterID),
+			valueOrDash(provider.Metadata.PlatformEnvironment),
+			valueOrDash(provider.Metadata.CreatedAt),
+			valueOrDash(provider.Metadata.Description))
+	}
+	return w.Flush()
+}
+
+func newOIDCDescribeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "describe <cluster-id|issuer-url|provider-arn>",
+		Short: "Show an OIDC provider and the metadata the provisioner tagged it with",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runOIDCDescribe,
+	}
+
+	cmd.Flags().StringVarP(&oidcDescribeFormat, "output", "o", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func runOIDCDescribe(cmd *cobra.Command, args []string) error {
+	if oidcDescribeFormat != "text" && oidcDescribeFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", oidcDescribeFormat)
+	}
+
+	providers, err := listOIDCProviders(cmd.Context())
+	if err != nil {
+		return err
+	}
+	provider, ok := oidc.FindProvider(providers, args[0])
+	if !ok {
+		return fmt.Errorf("no OIDC provider found for %s", args[0])
+	}
+	view := newOIDCProviderView(*provider)
+
+	if oidcDescribeFormat == "json" {
+		return writeJSON(view)
+	}
+
+	fmt.Printf("ARN:                   %s\n", view.ARN)
+	fmt.Printf("Issuer:                %s\n", view.IssuerURL)
+	fmt.Printf("Cluster:               %s\n", valueOrDash(view.ClusterID))
+	fmt.Printf("Managed by rosactl:    %t\n", view.Managed)
+	fmt.Printf("Description:           %s\n", valueOrDash(view.Metadata.Description))
+	fmt.Printf("Platform environment:  %s\n", valueOrDash(view.Metadata.PlatformEnvironment))
+	fmt.Printf("Created at:            %s\n", valueOrDash(view.Metadata.CreatedAt))
+	fmt.Printf("Created by:            %s\n", valueOrDash(view.Metadata.CreatedBy))
+	fmt.Printf("rosactl version:       %s\n", valueOrDash(view.Metadata.RosactlVersion))
+	return nil
+}
+
+func newOIDCHistoryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show the provisioning requests recorded in the provisioner's ledger table",
+		Long: `Reads the provisioning and bind-roles requests the provisioner recorded in its
+DynamoDB ledger table, most recent first. The provisioner only records requests
+when it was deployed with setup-account --ledger-table; each request is recorded
+once, however often it was retried, unless it failed and a retry succeeded.
+
+--issuer-url reads one issuer's history with a query; other filters scan the
+table.`,
+		Args: cobra.NoArgs,
+		RunE: runOIDCHistory,
+	}
+
+	cmd.Flags().StringVar(&historyTable, "table", ledger.DefaultTableName, "Ledger table the provisioner records requests in")
+	cmd.Flags().StringVar(&historyIssuerURL, "issuer-url", "", "Only show requests for this OIDC issuer")
+	cmd.Flags().StringVar(&historyClusterID, "cluster-id", "", "Only show requests for this cluster")
+	cmd.Flags().DurationVar(&historySince, "since", 0, "Only show requests recorded within this long, e.g. 24h (0 shows all)")
+	cmd.Flags().IntVar(&historyLimit, "limit", 50, "Show at most this many requests (0 shows all)")
+	cmd.Flags().StringVarP(&historyOutputFormat, "output", "o", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func runOIDCHistory(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+
+	if historyOutputFormat != "text" && historyOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", historyOutputFormat)
+	}
+	if err := ledger.ValidateTableName(historyTable); err != nil {
+		return err
+	}
+	if historySince < 0 {
+		return fmt.Errorf("--since must not be negative")
+	}
+	if historyLimit < 0 {
+		return fmt.Errorf("--limit must not be negative")
+	}
+
+	filter := ledger.Filter{
+		IssuerURL: strings.TrimSuffix(historyIssuerURL, "/"),
+		ClusterID: historyClusterID,
+		Limit:     historyLimit,
+	}
+	if historySince > 0 {
+		filter.Since = time.Now().Add(-historySince)
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	entries, err := ledger.List(ctx, aws.NewDynamoDBClient(awsConfig), historyTable, filter)
+	if err != nil {
+		var notFoundErr *dynamodbTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return fmt.Errorf("ledger table %s not found; deploy the provisioner with setup-account --ledger-table to record requests", historyTable)
+		}
+		return err
+	}
+
+	if historyOutputFormat == "json" {
+		return writeJSON(entries)
+	}
+	if len(entries) == 0 {
+		infoln("No ledger entries found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RECORDED AT\tISSUER\tCLUSTER\tACTION\tSTATUS\tPROVIDER ARN\tREQUEST ID")
+	for _, entry := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.RecordedAt.Format(time.RFC3339),
+			entry.IssuerURL,
+			valueOrDash(entry.ClusterID),
+			entry.Action,
+			entry.Status,
+			valueOrDash(entry.ProviderARN),
+			entry.RequestID)
+	}
+	return w.Flush()
+}
+
+// oidcProviderView is an OIDC provider as oidc list and describe print it
+type oidcProviderView struct {
+	ARN       string        `json:"arn"`
+	IssuerURL string        `json:"issuer_url"`
+	ClusterID string        `json:"cluster_id,omitempty"`
+	Managed   bool          `json:"managed"`
+	Metadata  oidc.Metadata `json:"metadata"`
+}
+
+func newOIDCProviderView(provider oidc.Provider) oidcProviderView {
+	return oidcProviderView{
+		ARN:       provider.ARN,
+		IssuerURL: provider.IssuerURL,
+		ClusterID: provider.ClusterID,
+		Managed:   provider.Managed,
+		Metadata:  provider.Metadata,
+	}
+}
+
+// listOIDCProviders reads the account's OIDC providers with their rosa tags
+func listOIDCProviders(ctx context.Context) ([]oidc.Provider, error) {
+	profile, region, _, _ := getGlobalFlags()
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return oidc.ListProviders(ctx, aws.NewIAMClient(awsConfig), oidc.WithTagKeyFormat(tagKeyFormat))
+}
+
+// writeJSON writes v to stdout as indented JSON
+func writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func newOIDCCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Provision the OIDC provider for one cluster",
+		Long: `Invokes the OIDC provisioner Lambda to create the IAM OIDC provider for a
+cluster's issuer, or tag the existing one for the cluster.
+
+IAM accepts any thumbprint, but a provider whose thumbprint is not that of the
+certificate its issuer serves never validates a token. The thumbprint is checked
+against the issuer before invoking the provisioner, and a mismatch is reported as
+a warning, or with --strict-thumbprint as an error; the provisioner checks it
+again when it can reach the issuer. Without --thumbprint, the issuer's current
+thumbprint is used.`,
+		Args: cobra.NoArgs,
+		RunE: runOIDCCreate,
+	}
+
+	cmd.Flags().StringVar(&createClusterID, "cluster-id", "", "Cluster the provider is tagged for (required)")
+	cmd.Flags().StringVar(&createIssuerURL, "issuer-url", "", "https URL of the cluster's OIDC issuer (required)")
+	cmd.Flags().StringVar(&createThumbprint, "thumbprint", "", "SHA-1 thumbprint of the issuer's certificate (default: fetched from the issuer)")
+	cmd.Flags().StringSliceVar(&createClientIDs, "client-id", nil, "Audience of the provider (repeatable; default: openshift and sts.amazonaws.com)")
+	cmd.Flags().StringVar(&createFunctionName, "function-name", defaultFunctionName, "OIDC provisioner Lambda function name")
+	cmd.Flags().BoolVar(&createStrict, "strict-thumbprint", false, "Fail instead of warning when the thumbprint does not match the issuer's certificate")
+	_ = cmd.MarkFlagRequired("cluster-id")
+	_ = cmd.MarkFlagRequired("issuer-url")
+
+	return cmd
+}
+
+func runOIDCCreate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+
+	thumbprint, err := resolveThumbprint(ctx, createIssuerURL, createThumbprint, createStrict, "--thumbprint")
+	if err != nil {
+		return err
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	provisioner := invoker.NewInvoker(aws.NewLambdaClient(awsConfig), resourceNaming.Apply(createFunctionName))
+	resp, err := provisioner.Invoke(ctx, invoker.Request{
+		IssuerURL:        createIssuerURL,
+		Thumbprint:       thumbprint,
+		ClusterID:        createClusterID,
+		ClientIDs:        createClientIDs,
+		StrictThumbprint: createStrict,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to provision provider for cluster %s: %w", createClusterID, err)
+	}
+	for _, warning := range resp.Warnings {
+		warnf("⚠ %s\n", warning)
+	}
+
+	if resp.Status == "already_exists" {
+		infof("✓ Provider already exists and is tagged for cluster %s\n", createClusterID)
+	} else {
+		infof("✓ Created provider for cluster %s\n", createClusterID)
+	}
+	fmt.Println(resp.OIDCProviderARN)
+	return nil
+}
+
+// resolveThumbprint returns the thumbprint issuerURL serves when thumbprint is empty.
+// Otherwise thumbprint is checked against the issuer's certificate: a mismatch fails
+// when strict and warns when not. flag names the thumbprint's flag in errors.
+func resolveThumbprint(ctx context.Context, issuerURL, thumbprint string, strict bool, flag string) (string, error) {
+	if thumbprint == "" {
+		live, err := oidc.FetchThumbprint(ctx, issuerURL)
+		if err != nil {
+			return "", fmt.Errorf("%s is required when the issuer's thumbprint cannot be fetched: %w", flag, err)
+		}
+		infof("Using thumbprint %s served by %s\n", live, issuerURL)
+		return live, nil
+	}
+	if err := oidc.VerifyThumbprint(ctx, issuerURL, thumbprint); err != nil {
+		switch {
+		case oidc.IsThumbprintMismatch(err) && strict:
+			return "", err
+		case oidc.IsThumbprintMismatch(err):
+			warnf("⚠ %v; the provider will not validate tokens from this issuer\n", err)
+		default:
+			warnf("⚠ Thumbprint not verified: %v\n", err)
+		}
+	}
+	return thumbprint, nil
+}
+
+func newOIDCReconcileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Converge the account's OIDC providers with the Platform API cluster list",
+		Long: `Fetches every cluster and its OIDC issuer from the Platform API and compares
+them with the account's IAM OIDC providers:
+
+  + create  a cluster's provider is missing
+  ~ tag     a provider exists but is not tagged rosa:cluster-id for its cluster
+  - delete  a provider tagged rosa:component=oidc-provider is used by no cluster
+
+Providers are created and tagged by invoking the OIDC provisioner Lambda, so they
+match those created for new clusters. Only rosa-tagged providers whose issuer host
+serves at least one Platform API cluster, or is listed with --issuer-host, are
+deleted; other providers in the account are never changed.`,
+		Args: cobra.NoArgs,
+		RunE: runOIDCReconcile,
+	}
+
+	cmd.Flags().StringVar(&reconcileFunctionName, "function-name", defaultFunctionName, "OIDC provisioner Lambda function name")
+	cmd.Flags().StringSliceVar(&reconcileIssuerHosts, "issuer-host", nil, "Also delete stale rosa-tagged providers for this issuer host (repeatable)")
+	cmd.Flags().BoolVar(&reconcileDryRun, "dry-run", false, "Show the changes without making them")
+
+	return cmd
+}
+
+func runOIDCReconcile(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, platformAPIURL := getGlobalFlags()
+
+	if platformAPIURL == "" {
+		return errors.New("--platform-api-url is required (or set platform_api_url in the config file)")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	platformClient := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack))
+	if verbose {
+		infof("Fetching clusters from %s...\n", platformClient.BaseURL())
+	}
+	clusters, err := platformClient.ListClusters(ctx)
+	if err != nil {
+		return err
+	}
+
+	var expected []oidc.Expected
+	for _, cluster := range clusters {
+		if cluster.OIDCIssuerURL == "" {
+			if verbose {
+				infof("  Cluster %s has no OIDC issuer yet\n", cluster.ID)
+			}
+			continue
+		}
+		expected = append(expected, oidc.Expected{
+			ClusterID:  cluster.ID,
+			IssuerURL:  cluster.OIDCIssuerURL,
+			Thumbprint: cluster.OIDCThumbprint,
+			ClientIDs:  cluster.OIDCClientIDs,
+		})
+	}
+
+	iamClient := aws.NewIAMClient(awsConfig)
+	providers, err := oidc.ListProviders(ctx, iamClient, oidc.WithTagKeyFormat(tagKeyFormat))
+	if err != nil {
+		return err
+	}
+
+	plan := oidc.Diff(expected, providers, append(oidc.IssuerHosts(expected), reconcileIssuerHosts...))
+	for _, action := range plan.Actions {
+		switch action.Kind {
+		case oidc.ActionCreate:
+			fmt.Printf("+ create %s (cluster %s)\n", action.IssuerURL, action.ClusterID)
+		case oidc.ActionTag:
+			fmt.Printf("~ tag %s (cluster %s): %s\n", action.ProviderARN, action.ClusterID, action.Reason)
+		case oidc.ActionDelete:
+			fmt.Printf("- delete %s: %s\n", action.ProviderARN, action.Reason)
+		case oidc.ActionSkip:
+			warnf("⚠ Skipping cluster %s (%s): %s\n", action.ClusterID, action.IssuerURL, action.Reason)
+		}
+	}
+
+	infof("%d clusters, %d in sync, %d to change\n", len(expected), plan.InSync, plan.Changes())
+	if plan.OutOfScope > 0 {
+		infof("%d rosa-tagged providers for other issuer hosts were left alone; use --issuer-host to include them\n", plan.OutOfScope)
+	}
+
+	if reconcileDryRun || plan.Changes() == 0 {
+		return nil
+	}
+
+	provisioner := invoker.NewInvoker(aws.NewLambdaClient(awsConfig), resourceNaming.Apply(reconcileFunctionName))
+	outcomes, err := oidc.Apply(ctx, plan, iamClient, provisioner)
+	for _, outcome := range outcomes {
+		if outcome.Err != nil {
+			infof("✗ %s\n", outcome.Err)
+			continue
+		}
+		switch outcome.Action.Kind {
+		case oidc.ActionCreate:
+			infof("✓ Created %s for cluster %s\n", outcome.ProviderARN, outcome.Action.ClusterID)
+		case oidc.ActionTag:
+			infof("✓ Tagged %s for cluster %s\n", outcome.ProviderARN, outcome.Action.ClusterID)
+		case oidc.ActionDelete:
+			infof("✓ Deleted %s\n", outcome.ProviderARN)
+		}
+	}
+	if err != nil {
+		return errors.New("reconcile incomplete; see the failures above")
+	}
+
+	return nil
+}
+
+func newOIDCBackfillCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "backfill -f <file>",
+		Short: "Provision OIDC providers for a list of existing clusters",
+		Long: `Invokes the OIDC provisioner Lambda for every cluster listed in a CSV file, for
+clusters migrated into the regional platform from elsewhere. The file's header
+names the cluster_id, issuer_url, and thumbprint columns, and optionally
+client_ids, a space-separated list of audiences; lines starting with # are
+ignored. Use - to read the file from stdin.
+
+At most --concurrency clusters are provisioned at once, and a failed invocation
+is retried up to --retries times with exponential backoff, except when the
+provisioner rejects the request as invalid. Providers that already exist are
+reported as such and not changed, so an interrupted backfill can be run again
+with the same file. The command fails if any cluster failed.`,
+		Args: cobra.NoArgs,
+		RunE: runOIDCBackfill,
+	}
+
+	cmd.Flags().StringVarP(&backfillFile, "file", "f", "", "CSV file listing the clusters to backfill, or - for stdin (required)")
+	cmd.Flags().StringVar(&backfillFunctionName, "function-name", defaultFunctionName, "OIDC provisioner Lambda function name")
+	cmd.Flags().IntVar(&backfillConcurrency, "concurrency", oidc.DefaultBackfillConcurrency, "Maximum number of clusters provisioned at once")
+	cmd.Flags().IntVar(&backfillRetries, "retries", oidc.DefaultBackfillAttempts-1, "Retries per cluster after a failed invocation")
+	cmd.Flags().StringVarP(&backfillOutputFormat, "output", "o", "text", "Output format: text or json")
+	cmd.Flags().BoolVar(&backfillStrict, "strict-thumbprint", false, "Fail clusters whose thumbprint does not match their issuer's certificate instead of warning")
+	_ = cmd.MarkFlagRequired("file")
+
+	return cmd
+}
+
+func runOIDCBackfill(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+	functionName := resourceNaming.Apply(backfillFunctionName)
+
+	if backfillOutputFormat != "text" && backfillOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", backfillOutputFormat)
+	}
+	if backfillConcurrency < 1 {
+		return errors.New("--concurrency must be at least 1")
+	}
+	if backfillRetries < 0 {
+		return errors.New("--retries cannot be negative")
+	}
+
+	var input io.Reader = os.Stdin
+	if backfillFile != "-" {
+		file, err := os.Open(backfillFile)
+		if err != nil {
+			return fmt.Errorf("failed to open backfill file: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+	items, err := oidc.ReadBackfillCSV(input)
+	if err != nil {
+		return err
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	infof("Backfilling OIDC providers for %d clusters with %s (%d at a time)...\n", len(items), functionName, backfillConcurrency)
+	provisioner := invoker.NewInvoker(aws.NewLambdaClient(awsConfig), functionName)
+	report := oidc.Backfill(ctx, provisioner, items, oidc.BackfillOptions{
+		Concurrency:      backfillConcurrency,
+		Attempts:         backfillRetries + 1,
+		StrictThumbprint: backfillStrict,
+		OnResult: func(result oidc.BackfillResult) {
+			switch {
+			case result.Err != nil:
+				infof("✗ %s\n", result.Err)
+			case result.Status == "already_exists":
+				infof("✓ Cluster %s: %s already exists\n", result.Item.ClusterID, result.ProviderARN)
+			default:
+				infof("✓ Cluster %s: created %s\n", result.Item.ClusterID, result.ProviderARN)
+			}
+			for _, warning := range result.Warnings {
+				warnf("⚠ Cluster %s: %s\n", result.Item.ClusterID, warning)
+			}
+			if verbose && result.Attempts > 1 {
+				infof("  after %d attempts\n", result.Attempts)
+			}
+		},
+	})
+
+	summary := newBackfillSummary(report)
+	if backfillOutputFormat == "json" {
+		if err := writeJSON(summary); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("%d clusters: %d created, %d already existed, %d failed\n",
+			summary.Total, summary.Created, summary.AlreadyExisted, summary.Failed)
+	}
+
+	if summary.Failed > 0 {
+		return fmt.Errorf("backfill incomplete: %d of %d clusters failed; fix the failures above and run it again with the same file", summary.Failed, summary.Total)
+	}
+	return nil
+}
+
+// backfillSummary is the report oidc backfill prints
+type backfillSummary struct {
+	Total          int                `json:"total"`
+	Created        int                `json:"created"`
+	AlreadyExisted int                `json:"already_exists"`
+	Failed         int                `json:"failed"`
+	Clusters       []backfillItemView `json:"clusters"`
+}
+
+// backfillItemView is the outcome for one cluster in the oidc backfill report
+type backfillItemView struct {
+	ClusterID   string   `json:"cluster_id"`
+	IssuerURL   string   `json:"issuer_url"`
+	ProviderARN string   `json:"provider_arn,omitempty"`
+	Status      string   `json:"status"`
+	Attempts    int      `json:"attempts"`
+	Warnings    []string `json:"warnings,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+func newBackfillSummary(report *oidc.BackfillReport) backfillSummary {
+	summary := backfillSummary{
+		Total:          len(report.Results),
+		Created:        report.Count("created"),
+		AlreadyExisted: report.Count("already_exists"),
+		Failed:         report.Count(""),
+		Clusters:       []backfillItemView{},
+	}
+	for _, result := range report.Results {
+		view := backfillItemView{
+			ClusterID:   result.Item.ClusterID,
+			IssuerURL:   result.Item.IssuerURL,
+			ProviderARN: result.ProviderARN,
+			Status:      result.Status,
+			Attempts:    result.Attempts,
+			Warnings:    result.Warnings,
+		}
+		if result.Err != nil {
+			view.Status = "failed"
+			view.Error = result.Err.Error()
+		}
+		summary.Clusters = append(summary.Clusters, view)
+	}
+	return summary
+}
@@ -0,0 +1,384 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/checkpoint"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/spf13/cobra"
+)
+
+// Onboarding steps, in the order onboard runs them
+const (
+	onboardStepValidate       = "validate"
+	onboardStepDeploy         = "deploy"
+	onboardStepResourcePolicy = "resource-policy"
+	onboardStepSmokeTest      = "smoke-test"
+	onboardStepPublishOutputs = "publish-outputs"
+)
+
+var onboardSteps = []string{
+	onboardStepValidate,
+	onboardStepDeploy,
+	onboardStepResourcePolicy,
+	onboardStepSmokeTest,
+	onboardStepPublishOutputs,
+}
+
+// onboardOperation names onboard checkpoints
+const onboardOperation = "onboard"
+
+// errNotDeployed is returned by steps that need the deployed function when no
+// deployment is recorded, so onboarding is resumed from the deploy step
+var errNotDeployed = errors.New("no deployment is recorded in the local manifests")
+
+var (
+	onboardResumeFrom        string
+	onboardRestart           bool
+	onboardFunctionName      string
+	onboardExecutionRoleName string
+	onboardCLMServiceRoleARN string
+	onboardSourceAccountID   string
+	onboardAssumeRoleARN     string
+	onboardOutputsPrefix     string
+	onboardTags              []string
+	onboardAdopt             bool
+)
+
+// onboarding carries what earlier steps produced to later ones
+type onboarding struct {
+	awsConfig awssdk.Config
+	region    string
+	verbose   bool
+
+	// deployment is the deployed function, from the deploy step or, when resuming past
+	// it, the local deployment manifest
+	deployment *deployer.DeploymentResult
+}
+
+// NewOnboardCommand creates the onboard command
+func NewOnboardCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "onboard",
+		Short: "Onboard an AWS account end to end",
+		Long: `Runs every step of onboarding an AWS account for ROSA, in order:
+
+  validate         the init validations, including validation hooks and plugins
+  deploy           deploy the OIDC provisioner Lambda function (as setup-account)
+  resource-policy  allow the CLM service role to invoke it (with --clm-service-role-arn)
+  smoke-test       invoke the function with a ping payload
+  publish-outputs  write the deployment outputs to SSM parameters
+
+A checkpoint is recorded after each completed step, so if a step fails, fix the
+problem and re-run onboard to continue from it. Use --restart to ignore the
+checkpoint and run every step again, or --resume-from <step> to start from a
+given step. Steps after deploy read the deployed function from the local
+deployment manifest, so they can be resumed from a later run. Use setup-account
+for the full set of deployment options.`,
+		RunE: runOnboard,
+	}
+
+	cmd.Flags().StringVar(&onboardResumeFrom, "resume-from", "", "Skip the steps before this one: "+strings.Join(onboardSteps, ", "))
+	cmd.Flags().BoolVar(&onboardRestart, "restart", false, "Ignore the checkpoint of an earlier run and run every step again")
+	cmd.Flags().StringVar(&onboardFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().StringVar(&onboardExecutionRoleName, "execution-role-name", defaultExecutionRoleName, "Lambda execution role name")
+	cmd.Flags().StringVar(&onboardCLMServiceRoleARN, "clm-service-role-arn", "", "CLM service role allowed to invoke the function")
+	cmd.Flags().StringVar(&onboardSourceAccountID, "source-account-id", "", "Source account ID for the resource policy (defaults to the deploying account)")
+	cmd.Flags().StringVar(&onboardAssumeRoleARN, "assume-role-arn", "", "Role to assume for the smoke test (e.g. the CLM service role)")
+	cmd.Flags().StringVar(&onboardOutputsPrefix, "outputs-prefix", deployer.DefaultOutputsPrefix, "SSM path the deployment outputs are written under")
+	cmd.Flags().StringArrayVar(&onboardTags, "tag", nil, "Tag to apply to deployed resources as key=value (repeatable, overrides config file tags)")
+	cmd.Flags().BoolVar(&onboardAdopt, "adopt", false, "Take ownership of pre-existing function, role, and log group resources not managed by rosactl")
+
+	return cmd
+}
+
+func runOnboard(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, platformAPIURL := getGlobalFlags()
+
+	if onboardRestart && onboardResumeFrom != "" {
+		return fmt.Errorf("--restart and --resume-from cannot be used together")
+	}
+
+	start := 0
+	if onboardResumeFrom != "" {
+		start = slices.Index(onboardSteps, onboardResumeFrom)
+		if start < 0 {
+			return fmt.Errorf("unknown step %q for --resume-from (expected one of %s)", onboardResumeFrom, strings.Join(onboardSteps, ", "))
+		}
+	}
+	if err := deployer.ValidateNames(resourceNaming.Apply(onboardFunctionName), resourceNaming.Apply(onboardExecutionRoleName)); err != nil {
+		return err
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	// If region not specified via flag, get it from config
+	if region == "" {
+		region = awsConfig.Region
+	}
+
+	checkpoints, progress, err := loadOnboardCheckpoint(region)
+	if err != nil {
+		return err
+	}
+	if onboardResumeFrom == "" && len(progress.Completed) > 0 {
+		infof("Resuming onboarding of %s in %s; completed steps are skipped (use --restart to start over)\n", onboardFunctionName, region)
+	}
+
+	o := &onboarding{awsConfig: awsConfig, region: region, verbose: verbose}
+	run := map[string]func(context.Context) error{
+		onboardStepValidate: func(ctx context.Context) error {
+			return validateAccount(ctx, &initReport{}, awsConfig, region, platformAPIURL, false, verbose)
+		},
+		onboardStepDeploy:         o.deploy,
+		onboardStepResourcePolicy: o.configureResourcePolicy,
+		onboardStepSmokeTest:      o.smokeTest,
+		onboardStepPublishOutputs: o.publishOutputs,
+	}
+
+	for i, step := range onboardSteps {
+		if i < start {
+			continue
+		}
+		infof("\n[%d/%d] %s\n", i+1, len(onboardSteps), step)
+		if onboardResumeFrom == "" && progress.Done(step) {
+			infoln("- Skipped: completed by an earlier run")
+			continue
+		}
+		if err := run[step](ctx); err != nil {
+			infof("✗ Step %s failed\n", step)
+			if errors.Is(err, errNotDeployed) {
+				return fmt.Errorf("%s: %w; re-run with --resume-from %s", step, err, onboardStepDeploy)
+			}
+			return fmt.Errorf("%s: %w; fix the problem and re-run to resume from this step", step, err)
+		}
+
+		progress.Complete(step)
+		if err := checkpoints.Save(progress); err != nil {
+			warnf("⚠ Failed to record onboarding checkpoint: %v\n", err)
+		}
+	}
+
+	if err := checkpoints.Delete(onboardOperation, progress.Key); err != nil {
+		warnf("⚠ Failed to remove onboarding checkpoint: %v\n", err)
+	}
+
+	infoln("\nOnboarding complete. Your AWS account is now configured for ROSA cluster provisioning.")
+	fmt.Println(o.deployment.FunctionARN)
+	return nil
+}
+
+// loadOnboardCheckpoint returns the checkpoint store and the progress of an earlier
+// onboarding of the function in region, which --restart discards
+func loadOnboardCheckpoint(region string) (*checkpoint.Store, *checkpoint.Checkpoint, error) {
+	dir, err := config.StateDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	store := checkpoint.NewStore(dir)
+	key := region + "/" + onboardFunctionName
+
+	if onboardRestart {
+		if err := store.Delete(onboardOperation, key); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		progress, err := store.Load(onboardOperation, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if progress != nil {
+			return store, progress, nil
+		}
+	}
+	return store, &checkpoint.Checkpoint{Operation: onboardOperation, Key: key}, nil
+}
+
+// deploy builds and deploys the OIDC provisioner with the default settings
+func (o *onboarding) deploy(ctx context.Context) error {
+	tags, err := deploymentTags(onboardTags)
+	if err != nil {
+		return err
+	}
+
+	sourceDir, err := deployer.ResolveSourceDir(deployer.DefaultSourceDir)
+	if err != nil {
+		return err
+	}
+	if err := deployer.ValidateSourceDir(sourceDir); err != nil {
+		return err
+	}
+
+	lambdaClient := aws.NewLambdaClient(o.awsConfig)
+	lambdaDeployer := deployer.NewDeployer(lambdaClient, aws.NewIAMClient(o.awsConfig),
+		aws.NewCloudWatchLogsClient(o.awsConfig), deployer.DeploymentConfig{
+			FunctionName:      onboardFunctionName,
+			ExecutionRoleName: onboardExecutionRoleName,
+			Naming:            resourceNaming,
+			SourceDir:         sourceDir,
+			Region:            o.region,
+			Runtime:           deployer.DefaultRuntime(o.region),
+			MemorySize:        defaultMemorySize,
+			Timeout:           defaultTimeout,
+			Architecture:      lambdaTypes.ArchitectureX8664,
+			Tags:              tags,
+			Adopt:             onboardAdopt,
+			CLIVersion:        version,
+			TagKeyFormat:      tagKeyFormat,
+			PackageCacheDir:   packageCacheDir(false),
+		},
+		deployer.WithSTSClient(aws.NewSTSClient(o.awsConfig)),
+		deployer.WithCodeSigningClient(lambdaClient))
+
+	result, err := lambdaDeployer.Deploy(ctx)
+	if err != nil {
+		var unmanagedErr *deployer.UnmanagedResourceError
+		if errors.As(err, &unmanagedErr) {
+			return fmt.Errorf("%s %s already exists but is not managed by rosactl; re-run with --adopt to take ownership of it",
+				unmanagedErr.Type, unmanagedErr.Identifier)
+		}
+		var partialErr *deployer.PartialFailureError
+		if errors.As(err, &partialErr) {
+			printPartialFailure(partialErr)
+		}
+		var policyErr *deployer.TagPolicyAPIError
+		if errors.As(err, &policyErr) {
+			return tagPolicyAPIError(policyErr)
+		}
+		return err
+	}
+	o.deployment = result
+
+	if _, err := saveManifest(o.region, result); err != nil {
+		warnf("⚠ Failed to record deployment manifest: %v\n", err)
+	}
+
+	infof("✓ Lambda function %s: %s\n", result.Status, result.FunctionName)
+	for _, resource := range result.Resources {
+		if resource.Action == deployer.ResourceActionUnchanged && !o.verbose {
+			continue
+		}
+		infof("✓ %s %s: %s\n", resource.Type, resource.Action, resource.Identifier)
+	}
+	return nil
+}
+
+// configureResourcePolicy allows the CLM service role to invoke the function
+func (o *onboarding) configureResourcePolicy(ctx context.Context) error {
+	if onboardCLMServiceRoleARN == "" {
+		infoln("- Skipped: no --clm-service-role-arn")
+		return nil
+	}
+
+	lambdaDeployer := deployer.NewDeployer(aws.NewLambdaClient(o.awsConfig), nil, nil, deployer.DeploymentConfig{
+		FunctionName:      onboardFunctionName,
+		Naming:            resourceNaming,
+		CLMServiceRoleARN: onboardCLMServiceRoleARN,
+		SourceAccountID:   onboardSourceAccountID,
+		Region:            o.region,
+	}, deployer.WithSTSClient(aws.NewSTSClient(o.awsConfig)))
+
+	action, err := lambdaDeployer.ConfigureResourcePolicy(ctx)
+	if err != nil {
+		return err
+	}
+	infof("✓ Resource policy for CLM invocation %s\n", action)
+	return nil
+}
+
+// smokeTest invokes the deployed function with a ping payload
+func (o *onboarding) smokeTest(ctx context.Context) error {
+	deployment, err := o.deployed()
+	if err != nil {
+		return err
+	}
+
+	awsConfig := o.awsConfig
+	if onboardAssumeRoleARN != "" {
+		profile, _, _, _ := getGlobalFlags()
+		awsConfig, err = newAWSConfigWithRole(ctx, profile, o.region, onboardAssumeRoleARN)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp, latency, err := invoker.NewInvoker(aws.NewLambdaClient(awsConfig), deployment.FunctionARN).Ping(ctx)
+	if err != nil {
+		return err
+	}
+	infof("✓ Function invocable: %s (%dms)\n", deployment.FunctionARN, latency.Milliseconds())
+	if o.verbose {
+		infof("  Status: %s\n", resp.Status)
+		infof("  Message: %s\n", resp.Message)
+	}
+	return nil
+}
+
+// publishOutputs writes the deployment outputs to SSM parameters
+func (o *onboarding) publishOutputs(ctx context.Context) error {
+	deployment, err := o.deployed()
+	if err != nil {
+		return err
+	}
+
+	names, err := deployer.PublishOutputs(ctx, aws.NewSSMClient(o.awsConfig), onboardOutputsPrefix, deployment)
+	if o.verbose {
+		for _, name := range names {
+			infof("✓ Parameter written: %s\n", name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	infof("✓ Deployment outputs published under %s\n", onboardOutputsPrefix)
+	return nil
+}
+
+// deployed returns the deployed function, reading it from the local deployment
+// manifest when the deploy step was skipped by --resume-from
+func (o *onboarding) deployed() (*deployer.DeploymentResult, error) {
+	if o.deployment != nil {
+		return o.deployment, nil
+	}
+
+	dir, err := config.ManifestDir()
+	if err != nil {
+		return nil, err
+	}
+	m, err := manifest.NewStore(dir).Load(o.region, resourceNaming.Apply(onboardFunctionName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deployment manifest: %w", err)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%w for %s in %s", errNotDeployed, resourceNaming.Apply(onboardFunctionName), o.region)
+	}
+
+	o.deployment = &deployer.DeploymentResult{
+		FunctionName:    m.FunctionName,
+		FunctionARN:     m.FunctionARN,
+		ExecutionRole:   m.ExecutionRoleARN,
+		LogGroupName:    m.LogGroupName,
+		Version:         m.Version,
+		PackageChecksum: m.PackageChecksum,
+		AccountID:       m.AccountID,
+		AccountAlias:    m.AccountAlias,
+		Partition:       m.Partition,
+		Region:          m.Region,
+		DeployedAt:      m.UpdatedAt,
+	}
+	return o.deployment, nil
+}
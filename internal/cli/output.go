@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openshift-online/regional-cli/internal/validator"
+)
+
+// Command results (identifiers, tables, JSON) are written to stdout so they can be piped.
+// Progress and status text is written to stderr and discarded with --quiet; warnings are
+// written to stderr even with --quiet.
+
+// infoOutput returns the writer for informational output
+func infoOutput() io.Writer {
+	if quiet {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// infof writes informational output
+func infof(format string, args ...interface{}) {
+	fmt.Fprintf(infoOutput(), format, args...)
+}
+
+// infoln writes a line of informational output
+func infoln(args ...interface{}) {
+	fmt.Fprintln(infoOutput(), args...)
+}
+
+// warnf writes a warning to stderr, regardless of --quiet
+func warnf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// printCheck renders a check result the same way for every command: passes with their
+// detail in verbose mode, skips only in verbose mode, and warnings and failures with
+// their detail, even with --quiet, so a quiet run never fails without a reason.
+// suffix is appended to passed checks, for example " (cached)".
+func printCheck(check validator.CheckResult, verbose bool, suffix string) {
+	switch check.Status {
+	case validator.CheckPassed:
+		infof("✓ %s%s\n", check.Title(), suffix)
+		if verbose && check.Detail != "" {
+			infof("  %s\n", check.Detail)
+		}
+	case validator.CheckWarning:
+		warnf("⚠ %s: %s\n", check.Title(), check.Detail)
+	case validator.CheckFailed:
+		warnf("✗ %s: %s\n", check.Title(), check.Detail)
+	case validator.CheckSkipped:
+		if !verbose {
+			return
+		}
+		if check.Detail != "" {
+			infof("- %s skipped: %s\n", check.Title(), check.Detail)
+		} else {
+			infof("- %s skipped\n", check.Title())
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	packageAllArch   bool
+	packageArch      string
+	packageOutDir    string
+	packageSourceDir string
+
+	publishDir       string
+	publishBucket    string
+	publishPrefix    string
+	publishOverwrite bool
+)
+
+// packageCacheDir returns the directory deployments cache their packages in,
+// ~/.rosactl/cache/packages, or an empty string to always compile when disabled is
+// set or the directory cannot be determined
+func packageCacheDir(disabled bool) string {
+	if disabled {
+		return ""
+	}
+	dir, err := config.CacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "packages")
+}
+
+// NewPackageCommand creates the package command
+func NewPackageCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "package",
+		Short: "Build OIDC provisioner Lambda packages",
+	}
+
+	cmd.AddCommand(newPackageBuildCommand())
+	cmd.AddCommand(newPackagePublishCommand())
+
+	return cmd
+}
+
+func newPackageBuildCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build release packages of the OIDC provisioner Lambda",
+		Long: `Cross-compiles the OIDC provisioner for Linux and writes a ZIP package per
+architecture to the output directory, together with a SHA256SUMS file and a
+manifest.json listing each package's architecture, checksum, and size. With
+--all-arch, the x86_64 and arm64 packages are built in parallel.`,
+		RunE: runPackageBuild,
+	}
+
+	cmd.Flags().BoolVar(&packageAllArch, "all-arch", false, "Build packages for every supported Lambda architecture")
+	cmd.Flags().StringVar(&packageArch, "arch", string(lambdaTypes.ArchitectureX8664), "Lambda architecture to build (x86_64 or arm64); ignored with --all-arch")
+	cmd.Flags().StringVar(&packageOutDir, "out", "", "Directory to write the packages, checksums, and manifest to (required)")
+	cmd.Flags().StringVar(&packageSourceDir, "source-dir", deployer.DefaultSourceDir, "Directory of the Lambda function's main package")
+	_ = cmd.MarkFlagRequired("out")
+
+	return cmd
+}
+
+func runPackageBuild(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	_, _, verbose, _ := getGlobalFlags()
+
+	archs := deployer.ReleaseArchitectures
+	if !packageAllArch {
+		arch, err := parseArchitecture(packageArch)
+		if err != nil {
+			return err
+		}
+		archs = []lambdaTypes.Architecture{arch}
+	}
+
+	sourceDir, err := deployer.ResolveSourceDir(packageSourceDir)
+	if err != nil {
+		return err
+	}
+	if err := deployer.ValidateSourceDir(sourceDir); err != nil {
+		return err
+	}
+	if verbose {
+		infof("Building Lambda packages from %s\n", sourceDir)
+	}
+
+	infof("Building %d package(s)...\n", len(archs))
+	manifest, err := deployer.BuildRelease(ctx, sourceDir, packageOutDir, archs, version)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ARCHITECTURE\tFILE\tSIZE\tSHA256")
+	for _, artifact := range manifest.Artifacts {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", artifact.Architecture, artifact.File, artifact.Size, artifact.SHA256)
+	}
+	w.Flush()
+
+	infof("✓ Packages, %s, and %s written to %s\n", deployer.ReleaseChecksumsFile, deployer.ReleaseManifestFile, packageOutDir)
+	return nil
+}
+
+func newPackagePublishCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "publish",
+		Short: "Upload built release packages to S3",
+		Long: `Uploads the packages written by 'rosactl package build' to an S3 bucket under
+a release prefix, together with SHA256SUMS and an index.json listing each
+package's key, architecture, and checksum. Package checksums are verified
+against the build manifest before uploading and stored as object metadata.
+
+A prefix that already holds an index.json is refused unless --overwrite is set,
+so a published release stays pinned.`,
+		RunE: runPackagePublish,
+	}
+
+	cmd.Flags().StringVar(&publishDir, "dir", "", "Directory written by 'rosactl package build --out' (required)")
+	cmd.Flags().StringVar(&publishBucket, "bucket", "", "S3 bucket to upload the release to (required)")
+	cmd.Flags().StringVar(&publishPrefix, "prefix", "", "Key prefix for the release, e.g. releases/v0.1.0 (required)")
+	cmd.Flags().BoolVar(&publishOverwrite, "overwrite", false, "Replace a release already published under the prefix")
+	_ = cmd.MarkFlagRequired("dir")
+	_ = cmd.MarkFlagRequired("bucket")
+	_ = cmd.MarkFlagRequired("prefix")
+
+	return cmd
+}
+
+func runPackagePublish(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, _, _ := getGlobalFlags()
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	infof("Publishing release to s3://%s/%s/...\n", publishBucket, strings.Trim(publishPrefix, "/"))
+	index, err := deployer.PublishRelease(ctx, aws.NewS3Client(awsConfig), publishDir, publishBucket, publishPrefix, publishOverwrite)
+	if err != nil {
+		if errors.Is(err, deployer.ErrReleaseExists) {
+			return fmt.Errorf("%w; pass --overwrite to replace it", err)
+		}
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ARCHITECTURE\tKEY\tSHA256")
+	for _, artifact := range index.Artifacts {
+		fmt.Fprintf(w, "%s\ts3://%s/%s\t%s\n", artifact.Architecture, index.Bucket, artifact.Key, artifact.SHA256)
+	}
+	w.Flush()
+
+	infof("✓ Release index written to s3://%s/%s\n", index.Bucket, deployer.ReleaseKey(index.Prefix, deployer.ReleaseIndexFile))
+	return nil
+}
+
+// parseArchitecture validates a Lambda architecture name
+func parseArchitecture(value string) (lambdaTypes.Architecture, error) {
+	for _, arch := range deployer.ReleaseArchitectures {
+		if string(arch) == value {
+			return arch, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported architecture %q (valid: x86_64, arm64)", value)
+}
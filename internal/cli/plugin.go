@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openshift-online/regional-cli/internal/plugin"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// NewPluginCommand creates the plugin command
+func NewPluginCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Manage rosactl plugins",
+		Long: `Plugins extend rosactl with commands that are not built in. Any executable on
+PATH named rosactl-<name> is run by ` + "`rosactl <name>`" + `, receiving the remaining
+arguments and the effective global settings as ROSACTL_* environment variables:
+
+  ROSACTL_PROFILE, ROSACTL_REGION, ROSACTL_PLATFORM_API_URL, ROSACTL_VERBOSE,
+  ROSACTL_QUIET, ROSACTL_NO_CACHE, ROSACTL_USE_DUALSTACK, and ROSACTL_CONFIG
+
+Global flags must come before the plugin name; arguments after it are passed to
+the plugin unchanged. Built-in commands take precedence over plugins.`,
+	}
+
+	cmd.AddCommand(newPluginListCommand())
+
+	return cmd
+}
+
+func newPluginListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the plugins found on PATH",
+		Args:  cobra.NoArgs,
+		RunE:  runPluginList,
+	}
+}
+
+func runPluginList(cmd *cobra.Command, args []string) error {
+	plugins := plugin.Discover(os.Getenv("PATH"))
+	if len(plugins) == 0 {
+		infoln("No plugins found on PATH.")
+		return nil
+	}
+
+	root := cmd.Root()
+	for _, p := range plugins {
+		fmt.Printf("%s\t%s\n", p.Name, p.Path)
+		switch {
+		case isBuiltinCommand(root, p.Name):
+			warnf("⚠ %s is ignored; it has the same name as the built-in command %s\n", p.Path, p.Name)
+		case p.ShadowedBy != "":
+			warnf("⚠ %s is ignored; it is shadowed by %s\n", p.Path, p.ShadowedBy)
+		}
+	}
+	return nil
+}
+
+// runPlugin runs the plugin args name, if any. It reports whether args named a
+// plugin; built-in commands are never run as plugins. Global flags before the plugin
+// name are resolved with the config file and environment and passed to the plugin.
+func runPlugin(ctx context.Context, root *cobra.Command, args []string) (bool, error) {
+	globalArgs, name, pluginArgs := splitPluginArgs(root, args)
+	if name == "" || isBuiltinCommand(root, name) {
+		return false, nil
+	}
+	p, err := plugin.Lookup(name)
+	if err != nil {
+		return false, nil
+	}
+
+	if err := root.ParseFlags(globalArgs); err != nil {
+		return true, err
+	}
+	if err := loadConfig(root, nil); err != nil {
+		return true, err
+	}
+	if verbose {
+		infof("Running plugin %s\n", p.Path)
+	}
+
+	return true, plugin.Run(ctx, p, pluginArgs, effectiveConfig.Environ(), os.Stdin, os.Stdout, os.Stderr)
+}
+
+// splitPluginArgs splits args at the first argument that is not a global flag or a
+// flag's value, returning the flags before it, the argument, and the rest. Nothing
+// is returned if an unknown flag or "--" comes first.
+func splitPluginArgs(root *cobra.Command, args []string) ([]string, string, []string) {
+	flags := root.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return nil, "", nil
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			return args[:i], arg, args[i+1:]
+		}
+
+		// A --name=value or -n=value flag carries its value
+		name, _, inline := strings.Cut(arg, "=")
+		var flag *pflag.Flag
+		if long, ok := strings.CutPrefix(name, "--"); ok {
+			flag = flags.Lookup(long)
+		} else if len(name) == 2 {
+			flag = flags.ShorthandLookup(name[1:])
+		}
+		if flag == nil {
+			return nil, "", nil
+		}
+		if !inline && flag.NoOptDefVal == "" {
+			i++ // Skip the flag's value
+		}
+	}
+	return nil, "", nil
+}
+
+// isBuiltinCommand reports whether name is a built-in command or one of its aliases
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	if name == "help" || name == "completion" || name == "__complete" {
+		return true
+	}
+	for _, cmd := range root.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintTrustPolicy  string
+	lintStrict       bool
+	lintOutputFormat string
+)
+
+// policyLintReport is the machine-readable result of policy lint
+type policyLintReport struct {
+	Passed   bool                   `json:"passed"`
+	Strict   bool                   `json:"strict"`
+	Findings []deployer.LintFinding `json:"findings"`
+}
+
+// NewPolicyCommand creates the policy command
+func NewPolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "policy",
+		Short: "Check customer-provided IAM policies",
+	}
+
+	cmd.AddCommand(newPolicyLintCommand())
+
+	return cmd
+}
+
+func newPolicyLintCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Lint a trust policy override before passing it to setup-account",
+		Long: `Checks a policy given to setup-account --trust-policy and reports:
+
+  error    the policy is not valid IAM policy syntax, does not allow
+           lambda.amazonaws.com to sts:AssumeRole, or lets any AWS account
+           assume the role
+  warning  the policy uses wildcard actions, NotPrincipal or NotAction, or trusts
+           a principal without aws:SourceAccount/aws:SourceArn (services) or
+           sts:ExternalId/aws:PrincipalOrgID (AWS principals)
+
+The command fails on errors, and on warnings too with --strict. setup-account runs
+the same checks on --trust-policy before deploying.`,
+		Args: cobra.NoArgs,
+		RunE: runPolicyLint,
+	}
+
+	cmd.Flags().StringVar(&lintTrustPolicy, "trust-policy", "", "Trust policy to lint, as inline JSON or a path to a JSON file")
+	cmd.Flags().BoolVar(&lintStrict, "strict", false, "Fail on warnings as well as errors")
+	cmd.Flags().StringVarP(&lintOutputFormat, "output", "o", "text", "Output format: text or json")
+	_ = cmd.MarkFlagRequired("trust-policy")
+
+	return cmd
+}
+
+func runPolicyLint(cmd *cobra.Command, args []string) error {
+	if lintOutputFormat != "text" && lintOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", lintOutputFormat)
+	}
+
+	document, err := deployer.ReadTrustPolicy(lintTrustPolicy)
+	if err != nil {
+		return err
+	}
+	findings := deployer.LintTrustPolicy(document)
+	lintErr := deployer.CheckLintFindings(findings, lintStrict)
+
+	if lintOutputFormat == "json" {
+		report := policyLintReport{Passed: lintErr == nil, Strict: lintStrict, Findings: findings}
+		if report.Findings == nil {
+			report.Findings = []deployer.LintFinding{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return fmt.Errorf("failed to write lint report: %w", err)
+		}
+	} else {
+		for _, finding := range findings {
+			fmt.Println(finding)
+		}
+		if len(findings) == 0 {
+			infoln("✓ No problems found")
+		}
+	}
+
+	return lintFailure(lintErr)
+}
+
+// lintTrustPolicyOverride lints a --trust-policy override before it is deployed,
+// reporting every finding and failing on errors, or on warnings when strict is set
+func lintTrustPolicyOverride(document string, strict bool) error {
+	findings := deployer.LintTrustPolicy(document)
+	for _, finding := range findings {
+		if finding.Severity == deployer.LintError {
+			infof("✗ Trust policy: %s\n", finding)
+		} else {
+			warnf("⚠ Trust policy: %s\n", finding)
+		}
+	}
+	return lintFailure(deployer.CheckLintFindings(findings, strict))
+}
+
+// lintFailure summarizes a *deployer.PolicyLintError, whose findings have already
+// been printed
+func lintFailure(err error) error {
+	var lintErr *deployer.PolicyLintError
+	if !errors.As(err, &lintErr) {
+		return err
+	}
+	if len(lintErr.Findings) == 1 {
+		return errors.New("trust policy failed linting with 1 finding")
+	}
+	return fmt.Errorf("trust policy failed linting with %d findings", len(lintErr.Findings))
+}
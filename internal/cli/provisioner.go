@@ -0,0 +1,743 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/pkg/lambda/concurrency"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/openshift-online/regional-cli/pkg/lambda/logtail"
+	"github.com/openshift-online/regional-cli/pkg/lambda/metrics"
+	"github.com/openshift-online/regional-cli/pkg/lambda/schema"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultMetricsSince = 24 * time.Hour
+
+	// logTailTimeout bounds how long --tail-logs waits for CloudWatch Logs to ingest the
+	// invocation's log
+	logTailTimeout = 30 * time.Second
+)
+
+var (
+	healthFunctionARN   string
+	healthAssumeRoleARN string
+	healthTailLogs      bool
+
+	metricsFunctionName       string
+	metricsSince              time.Duration
+	metricsErrorRateThreshold float64
+	metricsThrottleThreshold  float64
+	metricsDurationThreshold  time.Duration
+
+	driftFunctionName     string
+	driftExpectedVersion  string
+	driftExpectedChecksum string
+
+	preflightFunctionName        string
+	preflightExpectedInvocations int
+	preflightWindow              time.Duration
+	preflightSince               time.Duration
+	preflightApply               bool
+
+	schemaOutDir string
+
+	resourcePolicyFunctionName string
+	resourcePolicyQualifier    string
+	resourcePolicyOutputFormat string
+	resourcePolicyAdd          []string
+	resourcePolicyRemove       []string
+	resourcePolicyYes          bool
+)
+
+// NewProvisionerCommand creates the provisioner command
+func NewProvisionerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "provisioner",
+		Short: "Operate the deployed OIDC provisioner Lambda",
+	}
+
+	cmd.AddCommand(newProvisionerHealthCommand())
+	cmd.AddCommand(newProvisionerMetricsCommand())
+	cmd.AddCommand(newProvisionerDriftCommand())
+	cmd.AddCommand(newProvisionerPreflightCommand())
+	cmd.AddCommand(newProvisionerSchemaCommand())
+	cmd.AddCommand(newProvisionerResourcePolicyCommand())
+
+	return cmd
+}
+
+func newProvisionerHealthCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "health",
+		Short: "Verify the OIDC provisioner Lambda is invocable end to end",
+		Long: `Invokes the OIDC provisioner Lambda with a ping payload and confirms it responds.
+
+When --assume-role-arn is set to the CLM service role, the invocation is made
+with that role's credentials, exercising the same resource-based policy path
+CLM uses and verifying the AddPermission configuration.
+
+With --tail-logs, the invocation's log is printed after the result, followed in
+CloudWatch Logs until the function's REPORT line. When the log cannot be read
+there, for example because the assumed role cannot read logs, the last 4 KB
+returned by the invocation is printed instead.`,
+		RunE: runProvisionerHealth,
+	}
+
+	cmd.Flags().StringVar(&healthFunctionARN, "function-arn", "", "ARN of the OIDC provisioner Lambda function")
+	cmd.Flags().StringVar(&healthAssumeRoleARN, "assume-role-arn", "", "Role to assume before invoking (e.g. the CLM service role)")
+	cmd.Flags().BoolVar(&healthTailLogs, "tail-logs", false, "Print the invocation's log from CloudWatch Logs")
+	_ = cmd.MarkFlagRequired("function-arn")
+
+	return cmd
+}
+
+func runProvisionerHealth(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+
+	functionARN, err := arn.Parse(healthFunctionARN)
+	if err != nil || functionARN.Service != "lambda" {
+		return fmt.Errorf("invalid --function-arn %q: expected a Lambda function ARN", healthFunctionARN)
+	}
+
+	// The function's own region always wins so cross-region checks work
+	if functionARN.Region != "" {
+		region = functionARN.Region
+	}
+
+	awsConfig, err := newAWSConfigWithRole(ctx, profile, region, healthAssumeRoleARN)
+	if err != nil {
+		return err
+	}
+
+	if healthAssumeRoleARN != "" {
+		if _, err := awsConfig.Credentials.Retrieve(ctx); err != nil {
+			infof("✗ Unable to assume %s\n", healthAssumeRoleARN)
+			return fmt.Errorf("failed to assume role: %w", err)
+		}
+		infof("✓ Assumed role %s\n", healthAssumeRoleARN)
+	}
+
+	if verbose {
+		infof("Invoking %s with a ping payload...\n", healthFunctionARN)
+	}
+
+	var invokerOpts []invoker.Option
+	var logs *invoker.Logs
+	if healthTailLogs {
+		invokerOpts = append(invokerOpts, invoker.WithLogs(func(l invoker.Logs) {
+			logs = &l
+		}))
+	}
+	invokedAt := time.Now()
+
+	lambdaInvoker := invoker.NewInvoker(aws.NewLambdaClient(awsConfig), healthFunctionARN, invokerOpts...)
+	resp, latency, err := lambdaInvoker.Ping(ctx)
+	if err != nil {
+		infof("✗ Health check failed\n")
+	} else {
+		infof("✓ Function invocable: %s (%dms)\n", healthFunctionARN, latency.Milliseconds())
+		if verbose {
+			infof("  Status: %s\n", resp.Status)
+			infof("  Message: %s\n", resp.Message)
+			if stats := resp.Runtime; stats != nil {
+				memory := fmt.Sprintf("%d MB", stats.SysMB)
+				if stats.MemoryLimitMB > 0 {
+					memory = fmt.Sprintf("%d of %d MB", stats.SysMB, stats.MemoryLimitMB)
+				}
+				infof("  Memory: %s (heap %d MB, %d GC cycles)\n", memory, stats.HeapMB, stats.GCCycles)
+				infof("  Execution Environment: up %s, %d invocations\n",
+					time.Duration(stats.UptimeSeconds)*time.Second, stats.Invocations)
+			}
+		}
+	}
+
+	// The log is most useful when the invocation failed, so print it either way
+	if logs != nil {
+		functionName, _, _ := strings.Cut(strings.TrimPrefix(functionARN.Resource, "function:"), ":")
+		printInvocationLogs(ctx, awsConfig, functionName, invokedAt, *logs)
+	}
+
+	return err
+}
+
+// printInvocationLogs prints the log of an invocation, following it in CloudWatch Logs,
+// or the tail the invocation returned when the log cannot be read from there
+func printInvocationLogs(ctx context.Context, awsConfig awssdk.Config, functionName string, invokedAt time.Time, logs invoker.Logs) {
+	if logs.RequestID == "" {
+		infof("Function log:\n")
+		warnf("Warning: the invocation returned no request ID, showing the last 4 KB of its log\n")
+		printLogLines(logs.Tail)
+		return
+	}
+	infof("Function log for request %s:\n", logs.RequestID)
+
+	ctx, cancel := context.WithTimeout(ctx, logTailTimeout)
+	defer cancel()
+
+	// Lambda timestamps the START line by its own clock, which may be slightly behind
+	// the local one
+	printed := 0
+	follower := logtail.NewFollower(aws.NewLogEventsClient(awsConfig))
+	err := follower.Follow(ctx, functionLogGroupName(ctx, aws.NewLambdaClient(awsConfig), functionName), logs.RequestID, invokedAt.Add(-time.Minute), func(line string) {
+		printLogLines([]string{line})
+		printed++
+	})
+	switch {
+	case err == nil:
+	case printed > 0:
+		warnf("Warning: stopped following the log in CloudWatch Logs: %v\n", err)
+	default:
+		warnf("Warning: failed to read the log from CloudWatch Logs, showing the last 4 KB returned by the invocation: %v\n", err)
+		printLogLines(logs.Tail)
+	}
+}
+
+// printLogLines writes function log lines to stdout, indented under their heading
+func printLogLines(lines []string) {
+	for _, line := range lines {
+		fmt.Println("  " + line)
+	}
+}
+
+func newProvisionerMetricsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Summarize OIDC provisioner health from CloudWatch metrics",
+		Long: `Reads the Invocations, Errors, Throttles, and Duration metrics of the OIDC
+provisioner Lambda from CloudWatch and prints a summary, highlighting values
+beyond their thresholds. The p95 duration threshold defaults to 80% of the
+function's configured timeout.`,
+		Args: cobra.NoArgs,
+		RunE: runProvisionerMetrics,
+	}
+
+	cmd.Flags().StringVar(&metricsFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().DurationVar(&metricsSince, "since", defaultMetricsSince, "Summarize metrics from this long ago until now")
+	cmd.Flags().Float64Var(&metricsErrorRateThreshold, "error-rate-threshold", metrics.DefaultErrorRateThreshold*100, "Highlight error rates above this percentage of invocations")
+	cmd.Flags().Float64Var(&metricsThrottleThreshold, "throttle-threshold", 0, "Highlight throttle counts above this number")
+	cmd.Flags().DurationVar(&metricsDurationThreshold, "duration-threshold", 0, "Highlight p95 durations above this (defaults to 80% of the function timeout)")
+
+	return cmd
+}
+
+func runProvisionerMetrics(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+	functionName := resourceNaming.Apply(metricsFunctionName)
+
+	if metricsSince <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	thresholds := metrics.Thresholds{
+		ErrorRate:   metricsErrorRateThreshold / 100,
+		Throttles:   metricsThrottleThreshold,
+		DurationP95: metricsDurationThreshold,
+	}
+	if thresholds.DurationP95 == 0 {
+		output, err := aws.NewLambdaClient(awsConfig).GetFunction(ctx, &lambda.GetFunctionInput{
+			FunctionName: awssdk.String(functionName),
+		})
+		if err != nil {
+			warnf("⚠ Unable to read function timeout, skipping the duration threshold: %v\n", err)
+		} else if output.Configuration != nil && output.Configuration.Timeout != nil {
+			timeout := time.Duration(*output.Configuration.Timeout) * time.Second
+			thresholds.DurationP95 = time.Duration(float64(timeout) * metrics.DefaultDurationThreshold)
+		}
+	}
+
+	end := time.Now()
+	collector := metrics.NewCollector(aws.NewCloudWatchClient(awsConfig), functionName)
+	summary, err := collector.Summarize(ctx, end.Add(-metricsSince), end)
+	if err != nil {
+		infof("✗ Unable to read metrics\n")
+		return err
+	}
+
+	infof("OIDC provisioner metrics for %s (last %s)\n", functionName, metricsSince)
+	for _, check := range summary.Evaluate(thresholds) {
+		if check.Healthy {
+			fmt.Printf("✓ %s: %s\n", check.Name, check.Value)
+		} else {
+			fmt.Printf("⚠ %s: %s, %s\n", check.Name, check.Value, check.Detail)
+		}
+	}
+
+	if verbose && thresholds.DurationP95 > 0 {
+		infof("  p95 duration threshold: %s\n", thresholds.DurationP95)
+	}
+
+	return nil
+}
+
+func newProvisionerDriftCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drift",
+		Short: "Check which rosactl release and package the OIDC provisioner is running",
+		Long: `Reads the rosactl version and package checksum stamped on the OIDC provisioner
+Lambda by setup-account and reports any differences from this release and the
+package recorded in the local manifest. The code Lambda is running is also
+compared with the stamped checksum to catch code uploaded outside rosactl.
+
+Exits with an error when drift is found.`,
+		Args: cobra.NoArgs,
+		RunE: runProvisionerDrift,
+	}
+
+	cmd.Flags().StringVar(&driftFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().StringVar(&driftExpectedVersion, "expected-version", version, "rosactl version the function should have been deployed with")
+	cmd.Flags().StringVar(&driftExpectedChecksum, "expected-checksum", "", "Package checksum the function should be running (defaults to the last deployment in the local manifest)")
+
+	return cmd
+}
+
+func runProvisionerDrift(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+	functionName := resourceNaming.Apply(driftFunctionName)
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+	if region == "" {
+		region = awsConfig.Region
+	}
+
+	expected := deployer.Stamp{
+		CLIVersion:      driftExpectedVersion,
+		PackageChecksum: driftExpectedChecksum,
+	}
+	if expected.PackageChecksum == "" {
+		dir, err := config.ManifestDir()
+		if err != nil {
+			return err
+		}
+		m, err := manifest.NewStore(dir).Load(region, functionName)
+		if err != nil {
+			warnf("⚠ Unable to read the local manifest, skipping the checksum comparison: %v\n", err)
+		} else if m != nil {
+			expected.PackageChecksum = m.PackageChecksum
+		}
+	}
+
+	report, err := deployer.DetectDrift(ctx, aws.NewLambdaClient(awsConfig), functionName, expected, tagKeyFormat)
+	if err != nil {
+		infof("✗ Unable to read the function\n")
+		return err
+	}
+
+	infof("OIDC provisioner stamp for %s\n", functionName)
+	fmt.Printf("rosactl version: %s\n", valueOrDash(report.Deployed.CLIVersion))
+	fmt.Printf("package checksum: %s\n", valueOrDash(report.Deployed.PackageChecksum))
+	if verbose {
+		infof("  Function: %s\n", report.FunctionARN)
+		infof("  Code SHA256: %s\n", report.CodeSha256)
+	}
+
+	if !report.HasDrift() {
+		fmt.Println("✓ No drift detected")
+		return nil
+	}
+
+	for _, drift := range report.Drifts {
+		fmt.Printf("✗ %s\n", drift.Message)
+	}
+	return fmt.Errorf("drift detected: %d difference(s) found", len(report.Drifts))
+}
+
+func newProvisionerPreflightCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "preflight",
+		Short: "Check Lambda concurrency before a bulk cluster provisioning run",
+		Long: `Estimates the concurrency the OIDC provisioner needs for a run of
+--expected-invocations requests over --window, from the function's recent p95
+duration (or its timeout when it has not been invoked), and checks it against
+the account's unreserved concurrency, the function's reserved concurrency, and
+recent throttling. With --apply, the recommended reserved concurrency is set on
+the function.
+
+Exits with an error when the function cannot absorb the run.`,
+		Args: cobra.NoArgs,
+		RunE: runProvisionerPreflight,
+	}
+
+	cmd.Flags().StringVar(&preflightFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().IntVar(&preflightExpectedInvocations, "expected-invocations", 0, "Number of provisioning requests expected during the run (required)")
+	cmd.Flags().DurationVar(&preflightWindow, "window", 0, "How long the run takes (default: all requests arrive at once)")
+	cmd.Flags().DurationVar(&preflightSince, "since", defaultMetricsSince, "Look for throttling and invocation durations from this long ago until now")
+	cmd.Flags().BoolVar(&preflightApply, "apply", false, "Set the recommended reserved concurrency on the function")
+	_ = cmd.MarkFlagRequired("expected-invocations")
+
+	return cmd
+}
+
+func runProvisionerPreflight(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+	functionName := resourceNaming.Apply(preflightFunctionName)
+
+	if preflightExpectedInvocations <= 0 {
+		return fmt.Errorf("--expected-invocations must be positive")
+	}
+	if preflightWindow < 0 {
+		return fmt.Errorf("--window must not be negative")
+	}
+	if preflightSince <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+	lambdaClient := aws.NewLambdaClient(awsConfig)
+
+	end := time.Now()
+	summary, err := metrics.NewCollector(aws.NewCloudWatchClient(awsConfig), functionName).
+		Summarize(ctx, end.Add(-preflightSince), end)
+	if err != nil {
+		infof("✗ Unable to read metrics\n")
+		return err
+	}
+
+	burst := concurrency.Burst{
+		Invocations: preflightExpectedInvocations,
+		Window:      preflightWindow,
+		Duration:    summary.DurationP95,
+	}
+	if burst.Duration == 0 {
+		output, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+			FunctionName: awssdk.String(functionName),
+		})
+		if err != nil {
+			infof("✗ Unable to read the function\n")
+			return err
+		}
+		if output.Configuration != nil && output.Configuration.Timeout != nil {
+			burst.Duration = time.Duration(*output.Configuration.Timeout) * time.Second
+		}
+		if verbose {
+			infof("  No recent invocations; assuming each takes the function timeout (%s)\n", burst.Duration)
+		}
+	} else if verbose {
+		infof("  p95 duration over the last %s: %s\n", preflightSince, burst.Duration.Round(time.Millisecond))
+	}
+
+	preflight, err := concurrency.Run(ctx, lambdaClient, functionName, burst, summary.Throttles)
+	if err != nil {
+		infof("✗ Preflight failed\n")
+		return err
+	}
+
+	infof("Concurrency preflight for %s (%d invocations)\n", functionName, preflightExpectedInvocations)
+	fmt.Printf("Required concurrency: %d\n", preflight.Required)
+	for _, check := range preflight.Checks {
+		if check.Healthy {
+			fmt.Printf("✓ %s: %s\n", check.Name, check.Value)
+		} else {
+			fmt.Printf("⚠ %s: %s, %s\n", check.Name, check.Value, check.Detail)
+		}
+	}
+
+	recommended := preflight.Recommended()
+	if recommended > 0 {
+		if !preflightApply {
+			fmt.Printf("Recommended reserved concurrency: %d (re-run with --apply to set it)\n", recommended)
+		} else {
+			if err := concurrency.Apply(ctx, lambdaClient, functionName, recommended); err != nil {
+				infof("✗ Unable to reserve concurrency\n")
+				return err
+			}
+			fmt.Printf("✓ Reserved concurrency set to %d\n", recommended)
+			return nil
+		}
+	}
+
+	if preflight.Required > preflight.Available {
+		return fmt.Errorf("the account cannot provide the %d concurrent executions the run needs", preflight.Required)
+	}
+	if preflight.Reserved == nil || *preflight.Reserved < preflight.Required {
+		return fmt.Errorf("the function has not reserved the %d concurrent executions the run needs", preflight.Required)
+	}
+	return nil
+}
+
+func newProvisionerSchemaCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schema [" + strings.Join(schema.Names, "|") + "]",
+		Short: "Print the JSON Schemas of the OIDC provisioner's request and response",
+		Long: `Prints the JSON Schema (draft 2020-12) of the OIDC provisioner Lambda's request
+or response payload, generated from the Lambda's Go types, so CLM and other
+callers can validate payloads against the contract of the function this version
+of rosactl deploys.
+
+Without an argument, both schemas are printed. With --out, they are written to
+request.schema.json and response.schema.json in the directory instead.`,
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: schema.Names,
+		RunE:      runProvisionerSchema,
+	}
+
+	cmd.Flags().StringVar(&schemaOutDir, "out", "", "Directory to write the schema files to instead of printing them")
+
+	return cmd
+}
+
+func runProvisionerSchema(cmd *cobra.Command, args []string) error {
+	names := schema.Names
+	if len(args) == 1 {
+		names = args
+	}
+
+	for _, name := range names {
+		data, err := schema.Get(name)
+		if err != nil {
+			return err
+		}
+
+		if schemaOutDir == "" {
+			fmt.Print(string(data))
+			continue
+		}
+		if err := os.MkdirAll(schemaOutDir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", schemaOutDir, err)
+		}
+		path := filepath.Join(schemaOutDir, schema.FileName(name))
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		infof("✓ Wrote %s\n", path)
+	}
+
+	return nil
+}
+
+func newProvisionerResourcePolicyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resource-policy",
+		Short: "Show and edit who may invoke the OIDC provisioner Lambda",
+	}
+
+	cmd.PersistentFlags().StringVar(&resourcePolicyFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.PersistentFlags().StringVar(&resourcePolicyQualifier, "qualifier", "", "Alias or version whose policy to use instead of the function's")
+
+	cmd.AddCommand(newProvisionerResourcePolicyShowCommand())
+	cmd.AddCommand(newProvisionerResourcePolicyEditCommand())
+
+	return cmd
+}
+
+func newProvisionerResourcePolicyShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the statements of the function's resource-based policy",
+		Long: `Reads the OIDC provisioner Lambda's resource-based policy and prints a statement
+per row with its ID, principal, action, and conditions. The AllowCLMInvoke
+statement is the one setup-account maintains for CLM.`,
+		Args: cobra.NoArgs,
+		RunE: runProvisionerResourcePolicyShow,
+	}
+
+	cmd.Flags().StringVarP(&resourcePolicyOutputFormat, "output", "o", "text", "Output format: text or json")
+
+	return cmd
+}
+
+func runProvisionerResourcePolicyShow(cmd *cobra.Command, args []string) error {
+	functionName := resourceNaming.Apply(resourcePolicyFunctionName)
+	if resourcePolicyOutputFormat != "text" && resourcePolicyOutputFormat != "json" {
+		return fmt.Errorf("unsupported output format %q (expected text or json)", resourcePolicyOutputFormat)
+	}
+
+	client, err := newResourcePolicyClient(cmd.Context())
+	if err != nil {
+		return err
+	}
+	statements, err := deployer.ReadFunctionPolicy(cmd.Context(), client, functionName, resourcePolicyQualifier)
+	if err != nil {
+		return err
+	}
+
+	if resourcePolicyOutputFormat == "json" {
+		if statements == nil {
+			statements = []deployer.PolicyStatement{}
+		}
+		return writeJSON(statements)
+	}
+	if len(statements) == 0 {
+		infof("%s has no resource-based policy; nothing may invoke it from another account.\n", functionName)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SID\tPRINCIPAL\tACTION\tCONDITIONS")
+	for _, statement := range statements {
+		sid := statement.Sid
+		if statement.Managed {
+			sid += " (setup-account)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", sid, valueOrDash(statement.Principal),
+			valueOrDash(statement.Action), valueOrDash(strings.Join(statement.Conditions, "; ")))
+	}
+	return w.Flush()
+}
+
+func newProvisionerResourcePolicyEditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Allow or revoke invokers of the function",
+		Long: `Adds and removes statements of the OIDC provisioner Lambda's resource-based
+policy. --add allows an IAM role, user, or account to invoke the function;
+--remove revokes the statements with that principal, or the statement with that
+ID. The AllowCLMInvoke statement setup-account maintains is never removed.
+
+Without --add or --remove, the changes are read interactively from the terminal
+and applied after confirmation.`,
+		Args: cobra.NoArgs,
+		RunE: runProvisionerResourcePolicyEdit,
+	}
+
+	cmd.Flags().StringSliceVar(&resourcePolicyAdd, "add", nil, "IAM role, user, or account ARN, or account ID, to allow to invoke the function (repeatable)")
+	cmd.Flags().StringSliceVar(&resourcePolicyRemove, "remove", nil, "Principal or statement ID to revoke (repeatable)")
+	cmd.Flags().BoolVarP(&resourcePolicyYes, "yes", "y", false, "Apply interactive changes without asking for confirmation")
+
+	return cmd
+}
+
+func runProvisionerResourcePolicyEdit(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	functionName := resourceNaming.Apply(resourcePolicyFunctionName)
+
+	adds, removes := resourcePolicyAdd, resourcePolicyRemove
+	for _, principal := range adds {
+		if err := deployer.ValidateInvoker(principal); err != nil {
+			return err
+		}
+	}
+
+	if len(adds) == 0 && len(removes) == 0 {
+		if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return errors.New("--add or --remove is required when stdin is not a terminal")
+		}
+		in := bufio.NewScanner(os.Stdin)
+		adds, removes = promptInvokerChanges(in)
+		if len(adds) == 0 && len(removes) == 0 {
+			infoln("No changes.")
+			return nil
+		}
+		if !resourcePolicyYes && !confirmInvokerChanges(in, adds, removes) {
+			return errors.New("resource policy edit cancelled")
+		}
+	}
+
+	client, err := newResourcePolicyClient(ctx)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, principal := range adds {
+		added, err := deployer.AllowInvoker(ctx, client, functionName, resourcePolicyQualifier, principal)
+		switch {
+		case err != nil:
+			failed++
+			infof("✗ %v\n", err)
+		case added:
+			infof("✓ Allowed %s to invoke %s (statement %s)\n", principal, functionName, deployer.InvokerStatementID(principal))
+		default:
+			infof("✓ %s may already invoke %s\n", principal, functionName)
+		}
+	}
+	for _, ref := range removes {
+		sids, err := deployer.RevokeInvoker(ctx, client, functionName, resourcePolicyQualifier, ref)
+		if err != nil {
+			failed++
+			infof("✗ %v\n", err)
+			continue
+		}
+		infof("✓ Revoked %s (statement %s)\n", ref, strings.Join(sids, ", "))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("resource policy edit incomplete: %d of %d change(s) failed", failed, len(adds)+len(removes))
+	}
+	return nil
+}
+
+// promptInvokerChanges reads invokers to allow and revoke from the terminal, one per
+// line, until an empty line
+func promptInvokerChanges(in *bufio.Scanner) (adds, removes []string) {
+	fmt.Fprintf(os.Stderr, "Editing the resource-based policy of %s.\n", resourceNaming.Apply(resourcePolicyFunctionName))
+	fmt.Fprintln(os.Stderr, "Enter +<ARN or account ID> to allow an invoker, -<ARN, account ID, or statement ID> to revoke one, and an empty line to finish.")
+	for {
+		fmt.Fprint(os.Stderr, "> ")
+		if !in.Scan() {
+			return adds, removes
+		}
+		line := strings.TrimSpace(in.Text())
+		ref := strings.TrimSpace(strings.TrimLeft(line, "+-"))
+		switch {
+		case line == "":
+			return adds, removes
+		case strings.HasPrefix(line, "+"):
+			if err := deployer.ValidateInvoker(ref); err != nil {
+				warnf("⚠ %v\n", err)
+				continue
+			}
+			adds = append(adds, ref)
+		case strings.HasPrefix(line, "-") && ref != "":
+			removes = append(removes, ref)
+		default:
+			warnf("⚠ Start the line with + to allow an invoker or - to revoke one\n")
+		}
+	}
+}
+
+// confirmInvokerChanges lists the changes and asks whether to apply them
+func confirmInvokerChanges(in *bufio.Scanner, adds, removes []string) bool {
+	for _, principal := range adds {
+		fmt.Fprintf(os.Stderr, "  + allow %s\n", principal)
+	}
+	for _, ref := range removes {
+		fmt.Fprintf(os.Stderr, "  - revoke %s\n", ref)
+	}
+	fmt.Fprintf(os.Stderr, "Apply %d change(s) to %s? [y/N] ", len(adds)+len(removes), resourceNaming.Apply(resourcePolicyFunctionName))
+	if !in.Scan() {
+		return false
+	}
+	answer := strings.ToLower(strings.TrimSpace(in.Text()))
+	return answer == "y" || answer == "yes"
+}
+
+// newResourcePolicyClient creates the Lambda client for the resource-policy commands
+func newResourcePolicyClient(ctx context.Context) (deployer.FunctionPolicyAPI, error) {
+	profile, region, _, _ := getGlobalFlags()
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return aws.NewLambdaClient(awsConfig), nil
+}
@@ -1,9 +1,22 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/plugin"
+	"github.com/openshift-online/regional-cli/internal/validator"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/proxy"
+	"github.com/openshift-online/regional-cli/pkg/ratelimit"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/openshift-online/regional-cli/pkg/telemetry"
 	"github.com/spf13/cobra"
 )
 
@@ -16,7 +29,36 @@ var (
 	profile        string
 	region         string
 	verbose        bool
+	quiet          bool
 	platformAPIURL string
+	noCache        bool
+	useDualStack   bool
+	proxyURL       string
+	rateLimitFlags []string
+	maxRetriesFlag int
+	requestTimeout time.Duration
+	tagKeyPrefix   string
+	tagKeyCase     string
+	namePrefix     string
+	nameSuffix     string
+	environment    string
+	configFile     string
+
+	// effectiveConfig is the merged config file, environment, and flag configuration
+	effectiveConfig *config.Resolved
+
+	// rateLimits are the default, config file, and --rate-limit AWS request limits
+	rateLimits ratelimit.Limits
+
+	// maxRetries is how often failed AWS requests are retried, nil for the SDK default
+	maxRetries *int
+
+	// tagKeyFormat is how rosactl's own tag keys are written, from --tag-key-prefix and --tag-key-case
+	tagKeyFormat tagkey.Format
+
+	// resourceNaming is the naming convention applied to resource names, from --name-prefix,
+	// --name-suffix, and --environment
+	resourceNaming deployer.Naming
 )
 
 // NewRootCommand creates the root command for rosactl
@@ -28,29 +70,198 @@ func NewRootCommand() *cobra.Command {
 It enables customers to provision and manage HyperShift clusters with AWS IAM authentication.`,
 		Version: version,
 		SilenceUsage: true,
+		PersistentPreRunE: loadConfig,
 	}
 
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "AWS credential profile")
 	rootCmd.PersistentFlags().StringVar(&region, "region", "", "AWS region")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress informational output, printing only command results")
 	rootCmd.PersistentFlags().StringVar(&platformAPIURL, "platform-api-url", "", "Platform API endpoint URL")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Bypass cached validation results")
+	rootCmd.PersistentFlags().BoolVar(&useDualStack, "use-dualstack", false, "Use dual-stack (IPv4 and IPv6) AWS and Platform API endpoints, connecting over IPv6 first")
+	rootCmd.PersistentFlags().StringVar(&proxyURL, "proxy", "", "HTTP(S) proxy URL for AWS and Platform API requests (default from HTTPS_PROXY and HTTP_PROXY)")
+	rootCmd.PersistentFlags().StringArrayVar(&rateLimitFlags, "rate-limit", nil, "Client-side AWS request limit as service=rps[:burst], e.g. iam=2:5 (repeatable, 0 disables)")
+	rootCmd.PersistentFlags().IntVar(&maxRetriesFlag, "max-retries", -1, "Times to retry a failed AWS request; 0 disables retries and -1 keeps the AWS SDK default of 2")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "request-timeout", 0, "Maximum duration of each AWS request attempt, after which it is retried (default no limit)")
+	rootCmd.PersistentFlags().StringVar(&tagKeyPrefix, "tag-key-prefix", "", "Prefix replacing rosa: in the keys of tags rosactl sets, for tag policies that require one")
+	rootCmd.PersistentFlags().StringVar(&tagKeyCase, "tag-key-case", "", "Case of the keys of tags rosactl sets: lower, upper, or title (default as written, e.g. rosa:managed)")
+	rootCmd.PersistentFlags().StringVar(&namePrefix, "name-prefix", "", "Prefix added to the function, execution role, and log group names, for naming conventions")
+	rootCmd.PersistentFlags().StringVar(&nameSuffix, "name-suffix", "", "Suffix added to the function, execution role, and log group names, for naming conventions")
+	rootCmd.PersistentFlags().StringVar(&environment, "environment", "", "Deployment environment, such as dev or stage, added to resource names and tagged (rosa:environment) so several deployments can share an account")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Config file (default ~/.rosactl/config.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&completionRefresh, "refresh", false, "Complete flag values from AWS and the Platform API instead of local deployment manifests")
+	_ = rootCmd.PersistentFlags().MarkHidden("refresh")
 
 	// Add subcommands
 	rootCmd.AddCommand(NewInitCommand())
 	rootCmd.AddCommand(NewSetupAccountCommand())
+	rootCmd.AddCommand(NewOnboardCommand())
 	rootCmd.AddCommand(NewWhoamiCommand())
+	rootCmd.AddCommand(NewVersionsCommand())
+	rootCmd.AddCommand(NewPackageCommand())
+	rootCmd.AddCommand(NewProvisionerCommand())
+	rootCmd.AddCommand(NewConfigCommand())
+	rootCmd.AddCommand(NewLogsCommand())
+	rootCmd.AddCommand(NewDeploymentsCommand())
+	rootCmd.AddCommand(NewDiffCommand())
+	rootCmd.AddCommand(NewExportCommand())
+	rootCmd.AddCommand(NewStateCommand())
+	rootCmd.AddCommand(NewTeardownCommand())
+	rootCmd.AddCommand(NewOIDCCommand())
+	rootCmd.AddCommand(NewPolicyCommand())
+	rootCmd.AddCommand(NewClusterCommand())
+	rootCmd.AddCommand(NewFleetCommand())
+	rootCmd.AddCommand(NewDevCommand())
+	rootCmd.AddCommand(NewPluginCommand())
+
+	registerFlagCompletions(rootCmd)
 
 	return rootCmd
 }
 
-// Execute runs the root command
+// Execute runs the root command, or the plugin named by the first argument when it is
+// not a built-in command. SIGINT and SIGTERM cancel the command's context so in-flight
+// work stops cleanly; a second signal terminates immediately.
 func Execute() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	start := time.Now()
 	rootCmd := NewRootCommand()
-	if err := rootCmd.Execute(); err != nil {
+	if ran, err := runPlugin(ctx, rootCmd, os.Args[1:]); ran {
+		_, name, _ := splitPluginArgs(rootCmd, os.Args[1:])
+		recordCommandRun(ctx, rootCmd.Name()+" "+name, time.Since(start), err)
+		if err != nil {
+			// A plugin reports its own errors; exit with its status
+			if code := plugin.ExitCode(err); code > 0 {
+				os.Exit(code)
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, endTrace := startTrace(ctx, rootCmd)
+	err := rootCmd.ExecuteContext(ctx)
+	endTrace(err)
+	if command := commandPath(rootCmd); !strings.Contains(command, cobra.ShellCompRequestCmd) {
+		recordCommandRun(ctx, command, time.Since(start), err)
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		os.Exit(exitCode(err))
+	}
+}
+
+// loadConfig merges the config file and ROSACTL_* environment variables into the global flags.
+// Explicitly set flags always win.
+func loadConfig(cmd *cobra.Command, args []string) error {
+	path := configFile
+	if path == "" {
+		defaultPath, err := config.ConfigFile()
+		if err != nil {
+			return err
+		}
+		path = defaultPath
+	}
+
+	resolved, err := config.Load(path, cmd.Flags())
+	if err != nil {
+		return err
+	}
+
+	effectiveConfig = resolved
+	profile = resolved.Config.Profile
+	region = resolved.Config.Region
+	platformAPIURL = resolved.Config.PlatformAPIURL
+	verbose = resolved.Config.Verbose
+	quiet = resolved.Config.Quiet
+	noCache = resolved.Config.NoCache
+	useDualStack = resolved.Config.UseDualStack
+	proxyURL = resolved.Config.Proxy
+	tagKeyPrefix = resolved.Config.TagKeyPrefix
+	tagKeyCase = resolved.Config.TagKeyCase
+	namePrefix = resolved.Config.NamePrefix
+	nameSuffix = resolved.Config.NameSuffix
+	environment = resolved.Config.Environment
+	maxRetriesFlag = resolved.Config.MaxRetries
+	requestTimeout = resolved.Config.RequestTimeout
+
+	if quiet && verbose {
+		return fmt.Errorf("--quiet and --verbose cannot be used together")
+	}
+	if proxyURL != "" {
+		if _, err := proxy.Parse(proxyURL); err != nil {
+			return fmt.Errorf("invalid --proxy: %w", err)
+		}
+	}
+
+	overrides, err := parseRateLimits(rateLimitFlags)
+	if err != nil {
+		return err
+	}
+	rateLimits = ratelimit.DefaultLimits().Merge(resolved.Config.RateLimits).Merge(overrides)
+
+	switch {
+	case maxRetriesFlag < -1:
+		return fmt.Errorf("--max-retries must be 0 or more, or -1 for the AWS SDK default")
+	case maxRetriesFlag == -1:
+		maxRetries = nil
+	default:
+		retries := maxRetriesFlag
+		maxRetries = &retries
+	}
+	if requestTimeout < 0 {
+		return fmt.Errorf("--request-timeout must not be negative")
+	}
+	if endpoint := resolved.Config.MetricsEndpoint; endpoint != "" {
+		if err := telemetry.ValidateMetricsEndpoint(endpoint, resolved.Config.MetricsProtocol); err != nil {
+			return fmt.Errorf("invalid metrics configuration: %w", err)
+		}
+	}
+
+	if err := validator.ValidateHooks(resolved.Config.ValidationHooks); err != nil {
+		return fmt.Errorf("invalid validation_hooks: %w", err)
+	}
+
+	keyCase, err := tagkey.ParseCase(tagKeyCase)
+	if err != nil {
+		return fmt.Errorf("invalid --tag-key-case: %w", err)
+	}
+	tagKeyFormat = tagkey.Format{Prefix: tagKeyPrefix, Case: keyCase}
+	if err := tagKeyFormat.Validate(); err != nil {
+		return fmt.Errorf("invalid --tag-key-prefix: %w", err)
+	}
+	resourceNaming = deployer.Naming{Prefix: namePrefix, Suffix: nameSuffix, Environment: environment}
+	if err := resourceNaming.Validate(); err != nil {
+		return fmt.Errorf("invalid resource naming: %w", err)
+	}
+
+	return nil
+}
+
+// parseRateLimits parses --rate-limit service=rps[:burst] values
+func parseRateLimits(values []string) (ratelimit.Limits, error) {
+	limits := make(ratelimit.Limits, len(values))
+	for _, value := range values {
+		service, raw, ok := strings.Cut(value, "=")
+		if !ok || service == "" {
+			return nil, fmt.Errorf("invalid --rate-limit %q: expected service=rps[:burst]", value)
+		}
+		limit, err := ratelimit.ParseLimit(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --rate-limit %q: %w", value, err)
+		}
+		limits[service] = limit
 	}
+	return limits, nil
 }
 
 // getGlobalFlags returns the global flag values
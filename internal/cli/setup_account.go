@@ -2,27 +2,94 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"path/filepath"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/internal/report"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
 	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/lambda/metrics"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/spf13/cobra"
 )
 
 const (
-	defaultFunctionName      = "rosa-oidc-provisioner"
-	defaultExecutionRoleName = "rosa-oidc-provisioner-execution"
-	defaultMemorySize        = 128
-	defaultTimeout           = 60
+	defaultFunctionName      = deployer.DefaultFunctionName
+	defaultExecutionRoleName = deployer.DefaultExecutionRoleName
+	defaultMemorySize        = deployer.DefaultMemorySize
+	defaultTimeout           = deployer.DefaultTimeout
 )
 
 var (
 	functionName      string
 	executionRoleName string
+	executionRoleARN  string
 	clmServiceRoleARN string
+
+	codeSigningConfigARN string
 	sourceAccountID   string
+	policyQualifier   string
+	publishVersion    bool
+	keepVersions      int
+	tagPolicyFile     string
+	checkTagPolicy    bool
+	adoptResources    bool
+	noRollback        bool
+	primeFunction     bool
+	trustPolicy       string
+	strictPolicyLint  bool
+	logDataProtection bool
+	logGroupName      string
+	logDataPolicyFile string
+	historyParameter  string
+	ledgerTable       string
+	publishOutputs    bool
+	reportPath        string
+	outputsPrefix     string
+	buildArtifactsDir string
+	noPackageCache    bool
+	resourceTags      []string
+	memorySize        int32
+	timeout           int32
+	ephemeralStorage  int32
+	setOverrides      []string
+	snapStart         bool
+	runtimeName       string
+	skipRuntimeCheck  bool
+	setupDryRun       bool
+	verifyCloudTrail  bool
+	checkConfigRules  bool
+	logRetentionDays  int32
+	bindableRolePath  string
+	onlySteps         []string
+	skipSteps         []string
+	setupSourceDir    string
+	waitForInvocable  bool
+	testInvoke        bool
+
+	providerDescription string
+	platformEnvironment string
+
+	compileTimeout        time.Duration
+	uploadTimeout         time.Duration
+	iamPropagationTimeout time.Duration
+	verifyTimeout         time.Duration
+	invocableTimeout      time.Duration
+
+	canaryPercent        int
+	canaryInterval       time.Duration
+	canaryAlias          string
+	canaryErrorThreshold float64
 )
 
 // NewSetupAccountCommand creates the setup-account command
@@ -34,35 +101,276 @@ func NewSetupAccountCommand() *cobra.Command {
 for cluster authentication. This command:
   - Creates Lambda execution IAM role with minimal permissions
   - Builds and deploys the OIDC provisioner Lambda function
-  - Configures CloudWatch Logs with 90-day retention
-  - Optionally adds resource policy for CLM invocation`,
+  - Configures CloudWatch Logs with 90-day retention (see --log-retention-days)
+  - Optionally adds resource policy for CLM invocation
+
+An existing Lambda function not tagged rosa:managed=true is refused unless --adopt
+is set. With --adopt, pre-existing function, role, and log group resources are
+tagged, reconciled to the desired configuration, and recorded in the local
+deployment manifest.
+
+If a deployment fails after creating resources, the resources created by that run
+are deleted. With --no-rollback they are left in place and the commands needed to
+remove them are printed instead. Interrupting the command with Ctrl-C stops the
+deployment before its next step and rolls back the same way.
+
+With --execution-role-arn, a role the account's owners created, for example with
+their own IaC, is used instead: its trust policy is read to check Lambda can assume
+it, and it is never created, changed, tagged, or deleted, so the deployment needs no
+IAM write permissions. The role must grant the function the permissions listed in
+the README.
+
+With --code-signing-config-arn, the Lambda code signing config is attached to the
+function, for accounts that require one. The package is uploaded unsigned, so the
+config's untrusted artifact policy must be Warn; a config that enforces signed code
+is refused before anything is deployed. When a policy denies creating the function
+without a code signing config, the account's configs are listed in the error.
+
+With --dry-run, each resource is compared with the account and the changes a
+deployment would make are printed; nothing is built or changed.
+
+--only and --skip limit the deployment to some of its steps, for example
+--only function-code to refresh the code without calling IAM. The steps are
+execution-role, function-code, function-config (function selects both),
+resource-policy, log-group, tag-function, publish-version, and prime.
+
+With --check-config-rules, the account's AWS Config rules in the region are read
+before deploying, and the AWS managed rules expected to mark the deployed resources
+noncompliant, such as lambda-inside-vpc or cw-loggroup-retention-period-check, are
+printed as warnings with the settings that would avoid them.
+
+With --bindable-role-path, the function also accepts bind-roles requests, which
+add statements trusting a cluster's OIDC provider to the trust policies of
+operator roles under that IAM path. The execution role is granted iam:GetRole and
+iam:UpdateAssumeRolePolicy on those roles only; without the flag bind-roles is
+refused.
+
+A new resource policy can take minutes to be enforced, so CLM invocations may be
+denied at first even though setup reports success. With --wait-for-invocable, the
+function policy is read back until it allows CLM to invoke the function, and with
+--test-invoke the function is then invoked with the CLM service role's credentials,
+retrying until Lambda allows it. --test-invoke requires permission to assume the
+CLM service role.`,
 		RunE: runSetupAccount,
 	}
 
 	// Command-specific flags
 	cmd.Flags().StringVar(&functionName, "function-name", defaultFunctionName, "Lambda function name")
 	cmd.Flags().StringVar(&executionRoleName, "execution-role-name", defaultExecutionRoleName, "Lambda execution role name")
+	cmd.Flags().StringVar(&executionRoleARN, "execution-role-arn", "", "Use this pre-created execution role instead of creating one; IAM is only read to check its trust policy")
+	cmd.Flags().StringVar(&codeSigningConfigARN, "code-signing-config-arn", "", "Attach this Lambda code signing config to the function, for accounts that require one; its untrusted artifact policy must be Warn")
 	cmd.Flags().StringVar(&clmServiceRoleARN, "clm-service-role-arn", "", "CLM service role ARN for resource policy")
-	cmd.Flags().StringVar(&sourceAccountID, "source-account-id", "", "Source account ID for resource policy")
+	cmd.Flags().StringVar(&sourceAccountID, "source-account-id", "", "Source account ID for resource policy (defaults to the deploying account)")
+	cmd.Flags().StringVar(&policyQualifier, "resource-policy-qualifier", "", "Alias or version CLM may invoke, e.g. live; the function's $LATEST code is no longer invocable by CLM")
+	cmd.Flags().BoolVar(&publishVersion, "publish-version", false, "Publish an immutable Lambda version after deploying")
+	cmd.Flags().IntVar(&keepVersions, "keep-versions", 0, "Prune published versions beyond the newest N (requires --publish-version, 0 keeps all)")
+	cmd.Flags().StringVar(&tagPolicyFile, "tag-policy-file", "", "Validate tags against a local tag policy file before deploying")
+	cmd.Flags().BoolVar(&checkTagPolicy, "check-tag-policy", false, "Validate tags against the account's effective AWS Organizations tag policy before deploying")
+	cmd.Flags().Int32Var(&memorySize, "memory", defaultMemorySize,
+		fmt.Sprintf("Lambda memory size in MB (%d-%d)", deployer.MinMemorySize, deployer.MaxMemorySize))
+	cmd.Flags().Int32Var(&timeout, "timeout", defaultTimeout,
+		fmt.Sprintf("Lambda timeout in seconds (%d-%d)", deployer.MinTimeout, deployer.MaxTimeout))
+	cmd.Flags().Int32Var(&ephemeralStorage, "ephemeral-storage", 0,
+		fmt.Sprintf("Lambda /tmp storage in MB (%d-%d, defaults to %d)", deployer.MinEphemeralStorage, deployer.MaxEphemeralStorage, deployer.MinEphemeralStorage))
+	cmd.Flags().StringArrayVar(&setOverrides, "set", nil,
+		fmt.Sprintf("Override a function setting as key=value, applied over the other flags (repeatable; keys: %s)", strings.Join(deployer.OverrideKeys, ", ")))
+	cmd.Flags().BoolVar(&snapStart, "snapstart", false, "Enable Lambda SnapStart for published versions (requires a runtime that supports it)")
+	cmd.Flags().StringVar(&runtimeName, "runtime", "",
+		fmt.Sprintf("Lambda runtime: %s (defaults to the newest runtime available in the region)", runtimeNames()))
+	cmd.Flags().BoolVar(&skipRuntimeCheck, "skip-runtime-check", false, "Use --runtime even if it is not listed as available in the region")
+	cmd.Flags().DurationVar(&compileTimeout, "compile-timeout", deployer.DefaultCompileTimeout, "Maximum time to build the Lambda package")
+	cmd.Flags().DurationVar(&uploadTimeout, "upload-timeout", deployer.DefaultUploadTimeout, "Maximum time for each function create or update call")
+	cmd.Flags().DurationVar(&iamPropagationTimeout, "iam-propagation-timeout", deployer.DefaultIAMPropagationTimeout, "Maximum time to wait for a new execution role to become assumable by Lambda")
+	cmd.Flags().DurationVar(&verifyTimeout, "verify-timeout", deployer.DefaultVerifyTimeout, "Maximum time to wait for the function to become active after deploying")
+	cmd.Flags().StringArrayVar(&resourceTags, "tag", nil, "Tag to apply to deployed resources as key=value (repeatable, overrides config file tags)")
+	cmd.Flags().StringVar(&buildArtifactsDir, "keep-build-artifacts", "", "Save the compiled binary, package, and go build log to this directory")
+	cmd.Flags().BoolVar(&noPackageCache, "no-package-cache", false, "Compile the Lambda package even when a package built from the same source is cached")
+	cmd.Flags().BoolVar(&adoptResources, "adopt", false, "Take ownership of pre-existing function, role, and log group resources not managed by rosactl")
+	cmd.Flags().BoolVar(&setupDryRun, "dry-run", false, "Show what would be created or changed without deploying")
+	cmd.Flags().BoolVar(&primeFunction, "prime", false, "Invoke the function once after deploying so the first cluster provisioning request does not wait for a cold start")
+	cmd.Flags().BoolVar(&noRollback, "no-rollback", false, "Leave resources created by a failed deployment in place and print cleanup commands instead of deleting them")
+	cmd.Flags().StringVar(&trustPolicy, "trust-policy", "", "Execution role trust policy to use instead of the default, as inline JSON or a path to a JSON file")
+	cmd.Flags().BoolVar(&strictPolicyLint, "strict-policy-lint", false, "Refuse a --trust-policy with lint warnings, not just errors (see rosactl policy lint)")
+	cmd.Flags().StringVar(&logGroupName, "log-group-name", "", "Log group the function writes to, for log naming conventions (default /aws/lambda/<function-name>)")
+	cmd.Flags().Int32Var(&logRetentionDays, "log-retention-days", deployer.DefaultLogRetentionDays, "Days the log group retains the function's logs (one of the periods CloudWatch Logs accepts, such as 30, 90, 365, or 3653)")
+	cmd.Flags().StringSliceVar(&onlySteps, "only", nil, "Run only these deployment steps, comma-separated: "+strings.Join(deployer.SelectableSteps, ", "))
+	cmd.Flags().StringSliceVar(&skipSteps, "skip", nil, "Skip these deployment steps, comma-separated (see --only)")
+	cmd.MarkFlagsMutuallyExclusive("only", "skip")
+	cmd.MarkFlagsMutuallyExclusive("execution-role-arn", "execution-role-name")
+	cmd.MarkFlagsMutuallyExclusive("execution-role-arn", "trust-policy")
+	cmd.Flags().StringVar(&bindableRolePath, "bindable-role-path", "", "Allow bind-roles requests to federate roles under this IAM path, such as /rosa-operators/, with cluster OIDC providers")
+	cmd.Flags().BoolVar(&logDataProtection, "log-data-protection", false, "Attach a CloudWatch Logs data protection policy that masks account IDs and ARNs")
+	cmd.Flags().StringVar(&logDataPolicyFile, "log-data-protection-policy", "", "Attach this data protection policy file instead of the default (implies --log-data-protection)")
+	cmd.Flags().StringVar(&historyParameter, "history-parameter", "", "Also record the deployment in this SSM parameter")
+	cmd.Flags().StringVar(&ledgerTable, "ledger-table", "", "Create this DynamoDB table and have the provisioner record every provisioning request in it, for rosactl oidc history (e.g. "+ledger.DefaultTableName+")")
+	cmd.Flags().StringVar(&reportPath, "report", "", "Write an account onboarding report to this file (Markdown, or HTML for .html)")
+	cmd.Flags().BoolVar(&publishOutputs, "publish-outputs", false, "Write the function ARN, version, and package checksum to SSM parameters after deploying")
+	cmd.Flags().StringVar(&outputsPrefix, "outputs-prefix", deployer.DefaultOutputsPrefix, "SSM path the --publish-outputs parameters are written under")
+	cmd.Flags().StringVar(&setupSourceDir, "source-dir", deployer.DefaultSourceDir, "Directory of the Lambda function's main package; relative paths resolve against the working directory, then the rosactl module root")
+	cmd.Flags().IntVar(&canaryPercent, "canary-percent", 0, "Route this percentage of the alias's traffic to the new version and promote it after --canary-interval if its error rate stays healthy (implies --publish-version)")
+	cmd.Flags().DurationVar(&canaryInterval, "canary-interval", deployer.DefaultCanaryInterval, "How long the canary bakes before it is promoted")
+	cmd.Flags().StringVar(&canaryAlias, "canary-alias", deployer.DefaultCanaryAlias, "Alias callers invoke, shifted to the new version by --canary-percent")
+	cmd.Flags().Float64Var(&canaryErrorThreshold, "canary-error-threshold", metrics.DefaultErrorRateThreshold, "Highest canary error rate, as a fraction of invocations, before it is rolled back")
+	cmd.Flags().StringVar(&providerDescription, "provider-description", "", "Description tagged (rosa:description) on the OIDC providers the provisioner creates")
+	cmd.Flags().StringVar(&platformEnvironment, "platform-environment", "", "Platform environment, such as staging or production, tagged (rosa:platform-environment) on the OIDC providers the provisioner creates")
+	cmd.Flags().BoolVar(&checkConfigRules, "check-config-rules", false, "Before deploying, warn about AWS Config rules expected to mark the deployed resources noncompliant")
+	cmd.Flags().BoolVar(&verifyCloudTrail, "verify-cloudtrail", false, "After deploying, check CloudTrail for calls by the deploying principal that were denied")
+	cmd.Flags().BoolVar(&waitForInvocable, "wait-for-invocable", false, "After deploying, wait until the function policy read back allows CLM to invoke the function (requires --clm-service-role-arn)")
+	cmd.Flags().BoolVar(&testInvoke, "test-invoke", false, "With --wait-for-invocable, also assume the CLM service role and wait until it can invoke the function")
+	cmd.Flags().DurationVar(&invocableTimeout, "invocable-timeout", deployer.DefaultInvocableTimeout, "Maximum time --wait-for-invocable waits for CLM's permission to take effect")
 
 	return cmd
 }
 
 func runSetupAccount(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
 	profile, region, verbose, _ := getGlobalFlags()
 
+	if keepVersions < 0 {
+		return fmt.Errorf("--keep-versions must not be negative")
+	}
+	if keepVersions > 0 && !publishVersion && canaryPercent == 0 {
+		return fmt.Errorf("--keep-versions requires --publish-version")
+	}
+	if canaryPercent != 0 {
+		if err := canaryConfig().Validate(); err != nil {
+			return fmt.Errorf("invalid canary settings: %w", err)
+		}
+	}
+	steps := deployer.StepSelection{Only: onlySteps, Skip: skipSteps}
+	if err := steps.Validate(); err != nil {
+		return err
+	}
+	if canaryPercent > 0 && !steps.Runs(deployer.StepPublishVersion) {
+		return fmt.Errorf("--canary-percent requires the %s step", deployer.StepPublishVersion)
+	}
+
+	if executionRoleARN != "" {
+		if _, err := deployer.ValidateExecutionRoleARN(executionRoleARN); err != nil {
+			return err
+		}
+	}
+	if codeSigningConfigARN != "" {
+		if err := deployer.ValidateCodeSigningConfigARN(codeSigningConfigARN); err != nil {
+			return err
+		}
+	}
+	if err := deployer.ValidateNames(resourceNaming.Apply(functionName), resourceNaming.Apply(executionRoleName)); err != nil {
+		return err
+	}
+	if ledgerTable != "" {
+		if err := ledger.ValidateTableName(ledgerTable); err != nil {
+			return err
+		}
+	}
+	if policyQualifier != "" {
+		if clmServiceRoleARN == "" {
+			return fmt.Errorf("--resource-policy-qualifier requires --clm-service-role-arn")
+		}
+		if err := deployer.ValidateQualifier(policyQualifier); err != nil {
+			return err
+		}
+	}
+	if err := deployer.ValidateProviderMetadata(providerDescription, platformEnvironment); err != nil {
+		return err
+	}
+	if waitForInvocable && clmServiceRoleARN == "" {
+		return fmt.Errorf("--wait-for-invocable requires --clm-service-role-arn")
+	}
+	if testInvoke && !waitForInvocable {
+		return fmt.Errorf("--test-invoke requires --wait-for-invocable")
+	}
+	if logGroupName != "" {
+		if err := deployer.ValidateLogGroupName(resourceNaming.ApplyLogGroup(logGroupName)); err != nil {
+			return err
+		}
+	}
+	if err := deployer.ValidateLogRetention(logRetentionDays); err != nil {
+		return err
+	}
+	if err := deployer.ValidateBindableRolePath(bindableRolePath); err != nil {
+		return err
+	}
+
+	settings := deployer.FunctionSettings{MemorySize: memorySize, Timeout: timeout, EphemeralStorage: ephemeralStorage}
+	for _, override := range setOverrides {
+		if err := settings.Set(override); err != nil {
+			return fmt.Errorf("invalid --set %q: %w", override, err)
+		}
+	}
+	if err := deployer.ValidateFunctionLimits(settings.MemorySize, settings.Timeout); err != nil {
+		return err
+	}
+	if err := deployer.ValidateEphemeralStorage(settings.EphemeralStorage); err != nil {
+		return err
+	}
+
+	var runtime lambdaTypes.Runtime
+	if runtimeName != "" {
+		parsed, err := deployer.ParseRuntime(runtimeName)
+		if err != nil {
+			return err
+		}
+		runtime = parsed
+	}
+
+	stepTimeouts := deployer.StepTimeouts{
+		Compile:        compileTimeout,
+		Upload:         uploadTimeout,
+		IAMPropagation: iamPropagationTimeout,
+		Verify:         verifyTimeout,
+		Invocable:      invocableTimeout,
+	}
+	for _, t := range []struct {
+		flag  string
+		value time.Duration
+	}{
+		{"compile-timeout", compileTimeout},
+		{"upload-timeout", uploadTimeout},
+		{"iam-propagation-timeout", iamPropagationTimeout},
+		{"verify-timeout", verifyTimeout},
+		{"invocable-timeout", invocableTimeout},
+	} {
+		if t.value <= 0 {
+			return fmt.Errorf("--%s must be positive", t.flag)
+		}
+	}
+
+	tags, err := deploymentTags(resourceTags)
+	if err != nil {
+		return err
+	}
+
+	var logDataPolicy string
+	if logDataPolicyFile != "" {
+		data, err := os.ReadFile(logDataPolicyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read data protection policy file: %w", err)
+		}
+		logDataPolicy = string(data)
+		if err := deployer.ValidateLogDataProtectionPolicy(logDataPolicy); err != nil {
+			return err
+		}
+	}
+
+	var trustPolicyOverride string
+	if trustPolicy != "" {
+		if trustPolicyOverride, err = deployer.ReadTrustPolicy(trustPolicy); err != nil {
+			return err
+		}
+		if err := lintTrustPolicyOverride(trustPolicyOverride, strictPolicyLint); err != nil {
+			return err
+		}
+	}
+
 	if verbose {
-		fmt.Println("Setting up customer AWS account for ROSA...")
+		infoln("Setting up customer AWS account for ROSA...")
 	}
 
 	// Create AWS config
-	awsConfig, err := aws.NewConfig(ctx, aws.ClientConfig{
-		Profile: profile,
-		Region:  region,
-	})
+	awsConfig, err := newAWSConfig(ctx, profile, region)
 	if err != nil {
-		return fmt.Errorf("failed to load AWS config: %w", err)
+		return err
 	}
 
 	// If region not specified via flag, get it from config
@@ -70,68 +378,565 @@ func runSetupAccount(cmd *cobra.Command, args []string) error {
 		region = awsConfig.Region
 	}
 
+	if runtime == "" {
+		runtime = deployer.DefaultRuntime(region)
+	} else if !skipRuntimeCheck {
+		if err := deployer.ValidateRuntimeAvailability(runtime, region); err != nil {
+			return fmt.Errorf("%w; use --skip-runtime-check if it has since become available", err)
+		}
+	}
+	if verbose {
+		infof("Using runtime %s\n", runtime)
+	}
+	if snapStart {
+		if err := deployer.ValidateSnapStart(runtime, settings.EphemeralStorage, publishVersion || canaryPercent > 0); err != nil {
+			return err
+		}
+	}
+
 	// Create AWS service clients
 	lambdaClient := aws.NewLambdaClient(awsConfig)
 	iamClient := aws.NewIAMClient(awsConfig)
 	cwLogsClient := aws.NewCloudWatchLogsClient(awsConfig)
 
-	// Determine source directory for Lambda function
-	// In production, this would be embedded or downloaded
-	// For now, use relative path
-	sourceDir := filepath.Join("pkg", "lambda", "functions", "oidc-provisioner")
+	// Locate the Lambda function source; relative paths also resolve against the module root.
+	// It is only built when the function code is deployed.
+	var sourceDir string
+	if steps.Runs(deployer.StepFunctionCode) {
+		sourceDir, err = deployer.ResolveSourceDir(setupSourceDir)
+		if err != nil {
+			return err
+		}
+		if err := deployer.ValidateSourceDir(sourceDir); err != nil {
+			return err
+		}
+		if verbose {
+			infof("Building Lambda function from %s\n", sourceDir)
+		}
+	}
+	if skipped := steps.Skipped(); len(skipped) > 0 {
+		infof("Skipping steps: %s\n", strings.Join(skipped, ", "))
+	}
 
 	// Create deployment config
 	deployConfig := deployer.DeploymentConfig{
 		FunctionName:      functionName,
 		ExecutionRoleName: executionRoleName,
+		ExecutionRoleARN:  executionRoleARN,
+		LogGroupName:      logGroupName,
+		Naming:            resourceNaming,
+		LogRetentionDays:  logRetentionDays,
+		BindableRolePath:  bindableRolePath,
+		Steps:             steps,
 		SourceDir:         sourceDir,
 		CLMServiceRoleARN: clmServiceRoleARN,
 		SourceAccountID:   sourceAccountID,
-		Runtime:           lambdaTypes.RuntimeProvidedal2023,
-		MemorySize:        defaultMemorySize,
-		Timeout:           defaultTimeout,
+		Region:            region,
+		Runtime:           runtime,
+		MemorySize:        settings.MemorySize,
+		Timeout:           settings.Timeout,
+		EphemeralStorage:  settings.EphemeralStorage,
+		Environment:       settings.Environment,
+		Tracing:           settings.Tracing,
+		SnapStart:         snapStart,
 		Architecture:      lambdaTypes.ArchitectureX8664,
-		Tags: map[string]string{
-			"rosa:component": "oidc-provisioner",
-			"rosa:managed":   "true",
-		},
+		Tags:              tags,
+		PublishVersion:    publishVersion || canaryPercent > 0,
+		KeepVersions:      keepVersions,
+		Adopt:             adoptResources,
+		BuildArtifactsDir: buildArtifactsDir,
+		PackageCacheDir:   packageCacheDir(noPackageCache),
+		NoRollback:        noRollback,
+		Prime:             primeFunction,
+		Timeouts:          stepTimeouts,
+		CLIVersion:        version,
+		TagKeyFormat:      tagKeyFormat,
+
+		ResourcePolicyQualifier: policyQualifier,
+		TrustPolicyOverride:     trustPolicyOverride,
+		CodeSigningConfigARN:    codeSigningConfigARN,
+		ProviderDescription:     providerDescription,
+		PlatformEnvironment:     platformEnvironment,
+		LogDataProtection:       logDataProtection || logDataPolicy != "",
+		LogDataProtectionPolicy: logDataPolicy,
+		LedgerTableName:         ledgerTable,
+	}
+
+	// Load tag policy requirements (local file takes precedence over Organizations)
+	tagPolicy, err := loadTagPolicy(ctx, aws.NewOrganizationsClient(awsConfig), verbose)
+	if err != nil {
+		return err
 	}
+	deployConfig.TagPolicy = tagPolicy
 
 	// Create deployer
-	lambdaDeployer := deployer.NewDeployer(lambdaClient, iamClient, cwLogsClient, deployConfig)
+	lambdaDeployer := deployer.NewDeployer(lambdaClient, iamClient, cwLogsClient, deployConfig,
+		deployer.WithSTSClient(aws.NewSTSClient(awsConfig)),
+		deployer.WithCodeSigningClient(lambdaClient),
+		deployer.WithLedgerTableClient(aws.NewDynamoDBClient(awsConfig)))
+
+	if checkConfigRules {
+		checkConfigRuleFindings(ctx, lambdaDeployer, aws.NewConfigRulesClient(awsConfig))
+	}
+
+	if setupDryRun {
+		diffs, err := lambdaDeployer.Plan(ctx)
+		if err != nil {
+			return err
+		}
+		printPlan(diffs)
+		return nil
+	}
 
 	// Deploy Lambda function
-	fmt.Println("Deploying OIDC provisioner Lambda function...")
+	infoln("Deploying OIDC provisioner Lambda function...")
 
 	result, err := lambdaDeployer.Deploy(ctx)
 	if err != nil {
-		fmt.Printf("✗ Deployment failed\n")
+		var cancelledErr *deployer.CancelledError
+		if errors.As(err, &cancelledErr) {
+			infof("✗ Deployment cancelled during step %s\n", cancelledErr.Step)
+			for _, step := range cancelledErr.Completed {
+				infof("✓ Completed step %s\n", step)
+			}
+		} else {
+			infof("✗ Deployment failed\n")
+		}
+		var unmanagedErr *deployer.UnmanagedResourceError
+		if errors.As(err, &unmanagedErr) {
+			return fmt.Errorf("%s %s already exists but is not managed by rosactl; re-run with --adopt to take ownership of it",
+				unmanagedErr.Type, unmanagedErr.Identifier)
+		}
+		var partialErr *deployer.PartialFailureError
+		if errors.As(err, &partialErr) {
+			printPartialFailure(partialErr)
+		}
+		var policyErr *deployer.TagPolicyAPIError
+		if errors.As(err, &policyErr) {
+			return tagPolicyAPIError(policyErr)
+		}
 		return err
 	}
 
+	savedManifest, err := saveManifest(region, result)
+	if err != nil {
+		warnf("⚠ Failed to record deployment manifest: %v\n", err)
+	}
+
+	if historyParameter != "" {
+		history := manifest.NewParameterHistory(aws.NewSSMClient(awsConfig), historyParameter)
+		if err := history.Append(ctx, historyEntry(result)); err != nil {
+			warnf("⚠ Failed to record deployment history: %v\n", err)
+		} else if verbose {
+			infof("✓ Deployment recorded in %s\n", historyParameter)
+		}
+	}
+
 	// Display results
-	fmt.Printf("✓ Lambda function %s: %s\n", result.Status, result.FunctionName)
+	infof("✓ Lambda function %s: %s\n", result.Status, result.FunctionName)
 	if verbose {
-		fmt.Printf("  Function ARN: %s\n", result.FunctionARN)
-		fmt.Printf("  Execution Role: %s\n", result.ExecutionRole)
-		fmt.Printf("  Log Group: %s\n", result.LogGroupName)
-		fmt.Printf("  Package Size: %d bytes\n", result.PackageSize)
-		fmt.Printf("  Package Checksum: %s\n", result.PackageChecksum)
+		infof("  Account: %s\n", accountLabel(result.AccountID, result.AccountAlias))
+		infof("  Function ARN: %s\n", result.FunctionARN)
+		infof("  Execution Role: %s\n", result.ExecutionRole)
+		infof("  Log Group: %s\n", result.LogGroupName)
+		if !result.Naming.IsEmpty() {
+			infof("  Naming: %s\n", result.Naming)
+		}
+		if codeSigningConfigARN != "" {
+			infof("  Code Signing Config: %s\n", codeSigningConfigARN)
+		}
+		if ledgerTable != "" {
+			infof("  Ledger Table: %s\n", ledgerTable)
+		}
+		infof("  Package Size: %d bytes\n", result.PackageSize)
+		infof("  Package Checksum: %s\n", result.PackageChecksum)
+		if result.PackageCached {
+			infoln("  Package reused from the package cache")
+		}
+	}
+
+	for _, resource := range result.Resources {
+		if resource.Action == deployer.ResourceActionUnchanged && !verbose {
+			continue
+		}
+		infof("✓ %s %s: %s\n", resource.Type, resource.Action, resource.Identifier)
+	}
+
+	if clmServiceRoleARN != "" && steps.Runs(deployer.StepResourcePolicy) {
+		infoln("✓ Resource policy configured for CLM invocation")
+	}
+
+	if result.LogDataProtection {
+		infof("✓ Data protection policy attached to %s\n", result.LogGroupName)
+	}
+
+	if result.Version != "" {
+		infof("✓ Published version %s\n", result.Version)
+	}
+	if len(result.PrunedVersions) > 0 {
+		infof("✓ Pruned %d old version(s)\n", len(result.PrunedVersions))
+	}
+	if result.PrimeLatency > 0 {
+		infof("✓ Function primed (%s)\n", result.PrimeLatency.Round(time.Millisecond))
+	}
+
+	if canaryPercent > 0 {
+		if err := runCanary(ctx, lambdaDeployer, aws.NewCloudWatchClient(awsConfig), result); err != nil {
+			return err
+		}
+	}
+
+	if waitForInvocable {
+		if err := waitUntilInvocable(ctx, lambdaDeployer, region, result); err != nil {
+			infof("✗ CLM cannot invoke the function yet\n")
+			return fmt.Errorf("deployment succeeded but %w", err)
+		}
+	}
+
+	if verifyCloudTrail {
+		checkCloudTrailDenials(ctx, awsConfig, region, result)
 	}
 
-	if result.Status == "created" {
-		fmt.Println("✓ IAM execution role created")
-		fmt.Println("✓ CloudWatch Log Group created")
-	} else {
-		fmt.Println("✓ Lambda function updated")
+	if reportPath != "" {
+		err := report.WriteFile(reportPath, &report.Onboarding{
+			Result:            result,
+			Manifest:          savedManifest,
+			Region:            region,
+			CLMServiceRoleARN: clmServiceRoleARN,
+			GeneratedAt:       time.Now(),
+		})
+		if err != nil {
+			infof("✗ Failed to write onboarding report\n")
+			return fmt.Errorf("deployment succeeded but writing the onboarding report failed: %w", err)
+		}
+		infof("✓ Onboarding report written to %s\n", reportPath)
 	}
 
-	if clmServiceRoleARN != "" && sourceAccountID != "" {
-		fmt.Println("✓ Resource policy configured for CLM invocation")
+	if publishOutputs {
+		names, err := deployer.PublishOutputs(ctx, aws.NewSSMClient(awsConfig), outputsPrefix, result)
+		if verbose {
+			for _, name := range names {
+				infof("✓ Parameter written: %s\n", name)
+			}
+		}
+		if err != nil {
+			infof("✗ Failed to publish deployment outputs\n")
+			return fmt.Errorf("deployment succeeded but publishing outputs to %s failed: %w", outputsPrefix, err)
+		}
+		infof("✓ Deployment outputs published under %s\n", outputsPrefix)
 	}
 
-	fmt.Printf("\nSetup complete. Lambda function deployed: %s\n", result.FunctionARN)
-	fmt.Println("Your AWS account is now configured for ROSA cluster provisioning.")
+	infoln("\nSetup complete. Your AWS account is now configured for ROSA cluster provisioning.")
+	infoln("Lambda function deployed:")
+	fmt.Println(result.FunctionARN)
 
 	return nil
 }
+
+// canaryConfig returns the canary settings from the command flags
+func canaryConfig() deployer.CanaryConfig {
+	return deployer.CanaryConfig{
+		Alias:              canaryAlias,
+		Percent:            canaryPercent,
+		Interval:           canaryInterval,
+		ErrorRateThreshold: canaryErrorThreshold,
+	}
+}
+
+// runCanary shifts the alias to the published version through a weighted canary
+func runCanary(ctx context.Context, lambdaDeployer *deployer.Deployer, cwClient aws.CloudWatchAPI, result *deployer.DeploymentResult) error {
+	cfg := canaryConfig()
+	cfg.OnCheck = func(elapsed time.Duration, summary *metrics.Summary) {
+		infof("  %s: %.0f invocations, %.0f errors (%.1f%%)\n",
+			elapsed.Round(time.Second), summary.Invocations, summary.Errors, summary.ErrorRate()*100)
+	}
+	collector := metrics.NewAliasVersionCollector(cwClient, result.FunctionName, cfg.Alias, result.Version)
+
+	infof("Routing %d%% of alias %s to version %s for %s...\n", cfg.Percent, cfg.Alias, result.Version, cfg.Interval)
+	canary, err := lambdaDeployer.Canary(ctx, result.Version, cfg, collector)
+	if err != nil {
+		var rollbackErr *deployer.CanaryRollbackError
+		if errors.As(err, &rollbackErr) {
+			infof("✗ Canary rolled back; alias %s still points to version %s\n", cfg.Alias, canary.StableVersion)
+		}
+		return err
+	}
+
+	switch canary.Outcome {
+	case deployer.CanaryAliasCreated:
+		infof("✓ Alias %s created at version %s (no previous version to canary against)\n", cfg.Alias, result.Version)
+	case deployer.CanaryUnchanged:
+		infof("✓ Alias %s already points to version %s\n", cfg.Alias, result.Version)
+	default:
+		infof("✓ Canary healthy; alias %s promoted from version %s to %s\n", cfg.Alias, canary.StableVersion, result.Version)
+	}
+	return nil
+}
+
+// waitUntilInvocable waits for CLM's permission to invoke the deployed function to take
+// effect, invoking it as the CLM service role with --test-invoke
+func waitUntilInvocable(ctx context.Context, lambdaDeployer *deployer.Deployer, region string, result *deployer.DeploymentResult) error {
+	var clmLambda aws.LambdaAPI
+	if testInvoke {
+		clmConfig, err := newAWSConfigWithRole(ctx, profile, region, clmServiceRoleARN)
+		if err != nil {
+			return err
+		}
+		if _, err := clmConfig.Credentials.Retrieve(ctx); err != nil {
+			return fmt.Errorf("cannot assume CLM service role %s for --test-invoke: %w", clmServiceRoleARN, err)
+		}
+		clmLambda = aws.NewLambdaClient(clmConfig)
+	}
+
+	infoln("Waiting for CLM to be able to invoke the function...")
+	invocable, err := lambdaDeployer.WaitForInvocable(ctx, result.FunctionARN, clmLambda)
+	if err != nil {
+		return err
+	}
+
+	infof("✓ Resource policy of %s allows CLM invocation (after %s)\n", invocable.Target, invocable.PolicyWait.Round(time.Second))
+	if invocable.Invoked {
+		infof("✓ Test invocation as %s succeeded (after %s)\n", clmServiceRoleARN, invocable.InvokeWait.Round(time.Second))
+	}
+	return nil
+}
+
+// checkConfigRuleFindings warns about the AWS Config rules expected to mark the deployed
+// resources noncompliant. Failing to read the rules is reported but not fatal.
+func checkConfigRuleFindings(ctx context.Context, lambdaDeployer *deployer.Deployer, client aws.ConfigRulesAPI) {
+	findings, err := lambdaDeployer.CheckConfigRules(ctx, client)
+	if err != nil {
+		warnf("⚠ AWS Config rule check failed: %v\n", err)
+		return
+	}
+
+	if len(findings) == 0 {
+		infoln("✓ No AWS Config managed rules are expected to flag the deployed resources")
+		return
+	}
+	for _, finding := range findings {
+		warnf("⚠ AWS Config rule %s (%s) is expected to flag %s %s: %s\n", finding.RuleName, finding.Identifier,
+			finding.ResourceType, finding.Resource, finding.Detail)
+		warnf("  Suggestion: %s\n", finding.Suggestion)
+	}
+}
+
+// checkCloudTrailDenials reports deployment calls CloudTrail recorded as denied, including
+// failures the deployer tolerated as warnings. Lookup failures are reported but not fatal.
+func checkCloudTrailDenials(ctx context.Context, awsConfig awssdk.Config, region string, result *deployer.DeploymentResult) {
+	clients := []deployer.CloudTrailAPI{aws.NewCloudTrailClient(awsConfig)}
+	if globalRegion := deployer.GlobalEventsRegion(region); globalRegion != region {
+		globalConfig := awsConfig.Copy()
+		globalConfig.Region = globalRegion
+		clients = append(clients, aws.NewCloudTrailClient(globalConfig))
+	}
+
+	denials, err := deployer.VerifyCloudTrail(ctx, clients, result, time.Now())
+	if err != nil {
+		warnf("⚠ CloudTrail verification failed: %v\n", err)
+		return
+	}
+
+	if len(denials) == 0 {
+		infoln("✓ CloudTrail shows no denied calls by the deploying principal")
+	}
+	for _, denial := range denials {
+		warnf("⚠ CloudTrail: %s %s was denied at %s (%s)", denial.EventSource, denial.EventName,
+			denial.Time.Format(time.RFC3339), denial.ErrorCode)
+		if denial.ErrorMessage != "" {
+			warnf(": %s", denial.ErrorMessage)
+		}
+		warnf("\n")
+	}
+	infoln("  CloudTrail delivers events with a delay of up to 15 minutes; calls from the last few minutes may not be included.")
+}
+
+// deploymentTags merges the default tags, config file tags, and --tag flag values, in increasing precedence
+func deploymentTags(flagTags []string) (map[string]string, error) {
+	tags := map[string]string{
+		"rosa:component": "oidc-provisioner",
+		"rosa:managed":   "true",
+	}
+
+	if effectiveConfig != nil {
+		for key, value := range effectiveConfig.Config.Tags {
+			tags[key] = value
+		}
+	}
+
+	for _, tag := range flagTags {
+		key, value, ok := strings.Cut(tag, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --tag %q (expected key=value)", tag)
+		}
+		tags[key] = value
+	}
+
+	if err := deployer.ValidateTagConstraints(tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// printPartialFailure reports what a failed deployment rolled back and what it left behind
+func printPartialFailure(failure *deployer.PartialFailureError) {
+	for _, resource := range failure.RolledBack {
+		infof("✓ Rolled back %s: %s\n", resource.Type, resource.Identifier)
+	}
+	for _, err := range failure.RollbackErrors {
+		warnf("⚠ %v\n", err)
+	}
+
+	if len(failure.Remaining) == 0 {
+		return
+	}
+
+	for _, resource := range failure.Remaining {
+		warnf("⚠ Left in place %s: %s\n", resource.Type, resource.Identifier)
+	}
+	warnf("To remove them, run:\n")
+	for _, command := range failure.CleanupPlan() {
+		warnf("  %s\n", command)
+	}
+}
+
+// printPlan prints what a deployment would do to each resource
+func printPlan(diffs []deploy.Diff) {
+	for _, diff := range diffs {
+		switch {
+		case !diff.Exists:
+			fmt.Printf("+ create %s\n", diff.Ref)
+		case diff.InSync():
+			fmt.Printf("= unchanged %s\n", diff.Ref)
+		default:
+			fmt.Printf("~ update %s\n", diff.Ref)
+			for _, change := range diff.Changes {
+				fmt.Printf("    %s: %s -> %s\n", change.Field, valueOrDash(change.Current), valueOrDash(change.Desired))
+			}
+		}
+		if diff.Exists && !diff.Managed {
+			warnf("⚠ %s is not managed by rosactl; deploying requires --adopt\n", diff.Ref)
+		}
+	}
+}
+
+// saveManifest records the resources owned by a deployment in the local manifest store and returns the saved manifest
+func saveManifest(region string, result *deployer.DeploymentResult) (*manifest.Manifest, error) {
+	dir, err := config.ManifestDir()
+	if err != nil {
+		return nil, err
+	}
+
+	store := manifest.NewStore(dir)
+
+	// Carry the deployment history forward from the previous manifest
+	previous, err := store.Load(region, result.FunctionName)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &manifest.Manifest{
+		FunctionName:     result.FunctionName,
+		Region:           region,
+		AccountID:        result.AccountID,
+		AccountAlias:     result.AccountAlias,
+		Partition:        result.Partition,
+		FunctionARN:      result.FunctionARN,
+		ExecutionRoleARN: result.ExecutionRole,
+		LogGroupName:     result.LogGroupName,
+		PackageChecksum:  result.PackageChecksum,
+		Version:          result.Version,
+	}
+	m.Environment = result.Naming.Environment
+	if result.Naming.Prefix != "" || result.Naming.Suffix != "" {
+		m.Naming = &manifest.Naming{Prefix: result.Naming.Prefix, Suffix: result.Naming.Suffix}
+	}
+	for _, resource := range result.Resources {
+		m.Resources = append(m.Resources, manifest.Resource{
+			Type:       resource.Type,
+			Identifier: resource.Identifier,
+			Action:     resource.Action,
+		})
+	}
+
+	if previous != nil {
+		m.History = previous.History
+	}
+	m.History = manifest.AppendHistory(m.History, historyEntry(result), manifest.MaxHistory)
+
+	if err := store.Save(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// historyEntry describes a completed deployment for the deployment history
+func historyEntry(result *deployer.DeploymentResult) manifest.HistoryEntry {
+	return manifest.HistoryEntry{
+		DeployedAt:      result.DeployedAt,
+		DeployedBy:      result.DeployedBy,
+		AccountID:       result.AccountID,
+		AccountAlias:    result.AccountAlias,
+		Region:          result.Region,
+		Partition:       result.Partition,
+		Status:          result.Status,
+		Version:         result.Version,
+		PackageChecksum: result.PackageChecksum,
+	}
+}
+
+// loadTagPolicy returns the tag policy to validate against, or nil when tag policy checks are disabled
+func loadTagPolicy(ctx context.Context, orgClient aws.OrganizationsAPI, verbose bool) (*deployer.TagPolicy, error) {
+	if tagPolicyFile != "" {
+		return deployer.LoadTagPolicyFile(tagPolicyFile)
+	}
+
+	if !checkTagPolicy {
+		return nil, nil
+	}
+
+	output, err := orgClient.DescribeEffectivePolicy(ctx, &organizations.DescribeEffectivePolicyInput{
+		PolicyType: orgTypes.EffectivePolicyTypeTagPolicy,
+	})
+	if err != nil {
+		var notFoundErr *orgTypes.EffectivePolicyNotFoundException
+		var notInUseErr *orgTypes.AWSOrganizationsNotInUseException
+		if errors.As(err, &notFoundErr) || errors.As(err, &notInUseErr) {
+			if verbose {
+				infoln("No effective tag policy applies to this account")
+			}
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read effective tag policy: %w", err)
+	}
+
+	if output.EffectivePolicy == nil || output.EffectivePolicy.PolicyContent == nil {
+		return nil, nil
+	}
+
+	return deployer.ParseTagPolicy(*output.EffectivePolicy.PolicyContent)
+}
+
+// runtimeNames lists the supported runtimes for help text
+func runtimeNames() string {
+	names := make([]string, 0, len(deployer.SupportedRuntimes))
+	for _, runtime := range deployer.SupportedRuntimes {
+		names = append(names, string(runtime))
+	}
+	return strings.Join(names, " or ")
+}
+
+// tagPolicyAPIError explains how to satisfy the tag policy AWS enforced during deployment
+func tagPolicyAPIError(err *deployer.TagPolicyAPIError) error {
+	key := "a tag"
+	if err.Key != "" {
+		key = fmt.Sprintf("tag key %q", err.Key)
+	}
+	if err.Reserved || err.Key == "" {
+		return fmt.Errorf("the account's tag policy rejected %s set by rosactl; re-run with --tag-key-case or "+
+			"--tag-key-prefix matching the spelling the policy requires, or with --check-tag-policy or "+
+			"--tag-policy-file to detect it: %w", key, err.Err)
+	}
+	return fmt.Errorf("the account's tag policy rejected %s; change the --tag value or key to satisfy it: %w", key, err.Err)
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openshift-online/regional-cli/internal/checkpoint"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewStateCommand creates the state command
+func NewStateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "state",
+		Short: "Inspect the local state of multi-step commands",
+	}
+
+	cmd.AddCommand(newStateShowCommand())
+
+	return cmd
+}
+
+func newStateShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show operations that a rerun will resume",
+		Long: `Shows the checkpoints recorded by multi-step commands such as onboard that
+have not completed, with the steps each has finished. Re-running the command
+resumes after the last completed step; pass --restart to start over.`,
+		Args: cobra.NoArgs,
+		RunE: runStateShow,
+	}
+}
+
+func runStateShow(cmd *cobra.Command, args []string) error {
+	dir, err := config.StateDir()
+	if err != nil {
+		return err
+	}
+	checkpoints, err := checkpoint.NewStore(dir).List()
+	if err != nil {
+		return err
+	}
+
+	if len(checkpoints) == 0 {
+		infoln("No operations in progress.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OPERATION\tKEY\tCOMPLETED\tUPDATED AT")
+	for _, c := range checkpoints {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+			c.Operation,
+			c.Key,
+			valueOrDash(strings.Join(c.Completed, ",")),
+			c.UpdatedAt.Local().Format(time.RFC3339))
+	}
+	return w.Flush()
+}
@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/config"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/oidc"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+	"github.com/spf13/cobra"
+)
+
+var (
+	teardownFunctionName      string
+	teardownExecutionRoleName string
+	teardownExecutionRoleARN  string
+	teardownLogGroupName      string
+	teardownDryRun            bool
+	teardownForce             bool
+)
+
+// NewTeardownCommand creates the teardown command
+func NewTeardownCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "teardown",
+		Short: "Remove the OIDC provisioner Lambda and its resources from the account",
+		Long: `Deletes the resources setup-account deployed, in reverse order: the log group,
+the Lambda function with its resource policy, and the execution role with its
+inline policy. Resources that do not exist are skipped. If any resource exists
+but is not tagged rosa:managed=true, nothing is deleted. With --execution-role-arn,
+the pre-created role the function was deployed with is left in place.
+Names are derived with the --name-prefix and --name-suffix recorded in the
+deployment manifest, or else the configured ones; only the deployment of the
+configured --environment is torn down.
+
+Teardown first asks the Platform API for the clusters bound to the account's OIDC
+providers, and refuses to run while any remain, listing them. Use --force to skip
+the check, for example when the Platform API is unreachable.
+
+OIDC providers created by the provisioner are not deleted.`,
+		Args: cobra.NoArgs,
+		RunE: runTeardown,
+	}
+
+	cmd.Flags().StringVar(&teardownFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().StringVar(&teardownExecutionRoleName, "execution-role-name", defaultExecutionRoleName, "Lambda execution role name")
+	cmd.Flags().StringVar(&teardownExecutionRoleARN, "execution-role-arn", "", "Pre-created execution role the function was deployed with, which is not deleted")
+	cmd.Flags().StringVar(&teardownLogGroupName, "log-group-name", "", "Log group to delete (default the group the function writes to)")
+	cmd.Flags().BoolVar(&teardownDryRun, "dry-run", false, "Show what would be deleted without deleting anything")
+	cmd.Flags().BoolVar(&teardownForce, "force", false, "Tear down even if clusters still depend on the account, or the Platform API cannot be checked")
+	cmd.MarkFlagsMutuallyExclusive("execution-role-arn", "execution-role-name")
+
+	return cmd
+}
+
+func runTeardown(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, platformAPIURL := getGlobalFlags()
+
+	if teardownExecutionRoleARN != "" {
+		if _, err := deployer.ValidateExecutionRoleARN(teardownExecutionRoleARN); err != nil {
+			return err
+		}
+	}
+	if teardownLogGroupName != "" {
+		if err := deployer.ValidateLogGroupName(teardownLogGroupName); err != nil {
+			return err
+		}
+	}
+
+	if platformAPIURL == "" && !teardownForce && !teardownDryRun {
+		return errors.New("--platform-api-url is required to check that no cluster depends on the account (or set platform_api_url in the config file); use --force to skip the check")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+	if region == "" {
+		region = awsConfig.Region
+	}
+
+	naming := teardownNaming(region)
+	lambdaDeployer := deployer.NewDeployer(
+		aws.NewLambdaClient(awsConfig),
+		aws.NewIAMClient(awsConfig),
+		aws.NewCloudWatchLogsClient(awsConfig),
+		deployer.DeploymentConfig{
+			FunctionName:      teardownFunctionName,
+			ExecutionRoleName: teardownExecutionRoleName,
+			ExecutionRoleARN:  teardownExecutionRoleARN,
+			LogGroupName:      teardownLogGroupName,
+			Naming:            naming,
+			Region:            region,
+			TagKeyFormat:      tagKeyFormat,
+		},
+		deployer.WithSTSClient(aws.NewSTSClient(awsConfig)))
+
+	var dependents []oidc.Dependent
+	if platformAPIURL != "" && !teardownForce {
+		dependents, err = teardownDependents(ctx, awsConfig, platformAPIURL, verbose)
+		if err != nil {
+			return fmt.Errorf("failed to check for clusters depending on the account: %w; use --force to skip the check", err)
+		}
+		for _, dependent := range dependents {
+			warnf("⚠ Cluster %s depends on %s\n", dependent.ClusterID, dependent.ProviderARN)
+		}
+	}
+
+	if teardownDryRun {
+		diffs, err := lambdaDeployer.PlanTeardown(ctx)
+		if err != nil {
+			return err
+		}
+		for i := len(diffs) - 1; i >= 0; i-- {
+			diff := diffs[i]
+			switch {
+			case !diff.Exists:
+				if verbose {
+					infof("  %s does not exist\n", diff.Ref)
+				}
+			case !diff.Managed:
+				warnf("⚠ %s is not managed by rosactl; teardown will refuse to run\n", diff.Ref)
+			default:
+				fmt.Printf("- delete %s\n", diff.Ref)
+			}
+		}
+		switch {
+		case len(dependents) > 0:
+			warnf("⚠ Teardown will refuse to run while %d clusters depend on the account\n", len(dependents))
+		case platformAPIURL == "" && !teardownForce:
+			warnf("⚠ Teardown will require --platform-api-url, or --force, to check for dependent clusters\n")
+		}
+		return nil
+	}
+
+	if len(dependents) > 0 {
+		infof("✗ Teardown refused; nothing was deleted\n")
+		return fmt.Errorf("%d clusters still depend on the account: %s; delete them first or use --force", len(dependents), dependentIDs(dependents))
+	}
+
+	infoln("Tearing down OIDC provisioner resources...")
+
+	deleted, err := lambdaDeployer.Teardown(ctx)
+	for _, ref := range deleted {
+		infof("✓ Deleted %s\n", ref)
+	}
+	if err != nil {
+		var unmanagedErr *deploy.UnmanagedError
+		if errors.As(err, &unmanagedErr) {
+			infof("✗ Teardown refused; nothing was deleted\n")
+		} else {
+			infof("✗ Teardown incomplete\n")
+		}
+		return err
+	}
+
+	if len(deleted) == 0 {
+		infoln("Nothing to delete.")
+	}
+
+	dir, err := config.ManifestDir()
+	if err == nil {
+		err = manifest.NewStore(dir).Delete(region, naming.Apply(teardownFunctionName))
+	}
+	if err != nil {
+		warnf("⚠ Failed to remove the deployment manifest: %v\n", err)
+	}
+
+	return nil
+}
+
+// teardownNaming returns the naming convention to find the deployment's resources by:
+// the one recorded in the manifest of the deployment of --function-name in region, so
+// resources deployed with a --name-prefix or --name-suffix since removed from the
+// config are still found, or else the configured one. Only deployments of the
+// configured --environment are considered, and when several recorded namings match,
+// none is guessed.
+func teardownNaming(region string) deployer.Naming {
+	dir, err := config.ManifestDir()
+	if err != nil {
+		return resourceNaming
+	}
+	manifests, err := manifest.NewStore(dir).List()
+	if err != nil {
+		warnf("⚠ Failed to read the deployment manifests; using the configured resource naming: %v\n", err)
+		return resourceNaming
+	}
+
+	var recorded []deployer.Naming
+	for _, m := range manifests {
+		if m.Region != region || m.Environment != resourceNaming.Environment {
+			continue
+		}
+		naming := deployer.Naming{Environment: m.Environment}
+		if m.Naming != nil {
+			naming.Prefix, naming.Suffix = m.Naming.Prefix, m.Naming.Suffix
+		}
+		switch {
+		case m.FunctionName == resourceNaming.Apply(teardownFunctionName):
+			return resourceNaming
+		case m.FunctionName == naming.Apply(teardownFunctionName):
+			recorded = append(recorded, naming)
+		}
+	}
+	switch len(recorded) {
+	case 0:
+		return resourceNaming
+	case 1:
+		infof("Using the resource naming %s recorded for %s\n", recorded[0], recorded[0].Apply(teardownFunctionName))
+		return recorded[0]
+	default:
+		warnf("⚠ %d recorded deployments of %s match; set --name-prefix and --name-suffix to choose one\n", len(recorded), teardownFunctionName)
+		return resourceNaming
+	}
+}
+
+// teardownDependents returns the Platform API clusters bound to the account's OIDC
+// providers, which stop working once the provisioner is gone
+func teardownDependents(ctx context.Context, awsConfig awssdk.Config, platformAPIURL string, verbose bool) ([]oidc.Dependent, error) {
+	platformClient := platform.NewClient(platformAPIURL, awsConfig, platform.WithDualStack(useDualStack))
+	if verbose {
+		infof("Checking %s for clusters depending on the account...\n", platformClient.BaseURL())
+	}
+	clusters, err := platformClient.ListClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := make([]oidc.Expected, 0, len(clusters))
+	for _, cluster := range clusters {
+		expected = append(expected, oidc.Expected{ClusterID: cluster.ID, IssuerURL: cluster.OIDCIssuerURL})
+	}
+
+	providers, err := oidc.ListProviders(ctx, aws.NewIAMClient(awsConfig), oidc.WithTagKeyFormat(tagKeyFormat))
+	if err != nil {
+		return nil, err
+	}
+	return oidc.Dependents(expected, providers), nil
+}
+
+// dependentIDs lists the dependents' cluster IDs for an error message
+func dependentIDs(dependents []oidc.Dependent) string {
+	ids := make([]string, 0, len(dependents))
+	for _, dependent := range dependents {
+		ids = append(ids, dependent.ClusterID)
+	}
+	return strings.Join(ids, ", ")
+}
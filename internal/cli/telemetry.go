@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/openshift-online/regional-cli/pkg/telemetry"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// telemetryFlushTimeout bounds how long exiting waits for spans and metrics to be exported
+const telemetryFlushTimeout = 5 * time.Second
+
+// tracerProvider exports spans when OTEL_EXPORTER_OTLP_ENDPOINT is set; nil otherwise.
+// AWS configs built with it record a span per API call.
+var tracerProvider trace.TracerProvider
+
+// startTrace enables tracing when the OTEL_* environment configures an exporter and
+// starts a root span for the command being run, nested under TRACEPARENT when set.
+// The returned function ends the span with the command's error and flushes it.
+func startTrace(ctx context.Context, rootCmd *cobra.Command) (context.Context, func(error)) {
+	tp, err := telemetry.Setup(ctx, version)
+	if err != nil {
+		warnf("⚠ Tracing disabled: %v\n", err)
+		return ctx, func(error) {}
+	}
+	if tp == nil {
+		return ctx, func(error) {}
+	}
+	tracerProvider = tp
+	otel.SetTracerProvider(tp)
+
+	ctx, span := tp.Tracer(telemetry.TracerName).Start(telemetry.ContextWithEnvParent(ctx), commandPath(rootCmd))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+		// Flush even when the command was interrupted
+		flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), telemetryFlushTimeout)
+		defer cancel()
+		if err := tp.Shutdown(flushCtx); err != nil {
+			warnf("⚠ Failed to export traces: %v\n", err)
+		}
+	}
+}
+
+// recordCommandRun exports the duration, exit class, and region of a command run to
+// the configured metrics_endpoint, if any. Runs that fail before the configuration is
+// loaded, such as with an unknown flag, and shell completion requests are not recorded.
+func recordCommandRun(ctx context.Context, command string, duration time.Duration, err error) {
+	if effectiveConfig == nil || effectiveConfig.Config.MetricsEndpoint == "" {
+		return
+	}
+	cfg := effectiveConfig.Config
+	if telemetry.ValidateMetricsEndpoint(cfg.MetricsEndpoint, cfg.MetricsProtocol) != nil {
+		return // loadConfig has already failed the command for it
+	}
+
+	// Export even when the command was interrupted
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), telemetryFlushTimeout)
+	defer cancel()
+
+	metrics, metricsErr := telemetry.NewCommandMetrics(ctx, cfg.MetricsEndpoint, cfg.MetricsProtocol, version)
+	if metricsErr != nil {
+		warnf("⚠ Failed to export command metrics: %v\n", metricsErr)
+		return
+	}
+	metrics.Record(ctx, telemetry.CommandRun{
+		Command:   command,
+		Duration:  duration,
+		ExitClass: telemetry.ExitClass(err),
+		Region:    region,
+	})
+	if err := metrics.Shutdown(ctx); err != nil {
+		warnf("⚠ Failed to export command metrics: %v\n", err)
+	}
+}
+
+// commandPath returns the path of the built-in command os.Args runs, such as
+// "rosactl provisioner health", or the root command's name if it names none
+func commandPath(rootCmd *cobra.Command) string {
+	if cmd, _, err := rootCmd.Find(os.Args[1:]); err == nil {
+		return cmd.CommandPath()
+	}
+	return rootCmd.Name()
+}
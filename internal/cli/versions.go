@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/spf13/cobra"
+)
+
+const (
+	defaultKeepVersions = 5
+)
+
+var (
+	pruneFunctionName string
+	pruneKeepVersions int
+)
+
+// NewVersionsCommand creates the versions command
+func NewVersionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "versions",
+		Short: "Manage published versions of the OIDC provisioner Lambda",
+	}
+
+	cmd.AddCommand(newVersionsPruneCommand())
+
+	return cmd
+}
+
+func newVersionsPruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old published Lambda versions",
+		Long: `Deletes published versions of the OIDC provisioner Lambda beyond the newest N,
+freeing space against the account's Lambda code storage limit. $LATEST and
+versions referenced by an alias are never deleted.`,
+		RunE: runVersionsPrune,
+	}
+
+	cmd.Flags().StringVar(&pruneFunctionName, "function-name", defaultFunctionName, "Lambda function name")
+	cmd.Flags().IntVar(&pruneKeepVersions, "keep", defaultKeepVersions, "Number of most recent versions to keep")
+
+	return cmd
+}
+
+func runVersionsPrune(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	profile, region, verbose, _ := getGlobalFlags()
+	functionName := resourceNaming.Apply(pruneFunctionName)
+
+	if pruneKeepVersions < 1 {
+		return fmt.Errorf("--keep must be at least 1")
+	}
+
+	awsConfig, err := newAWSConfig(ctx, profile, region)
+	if err != nil {
+		return err
+	}
+
+	lambdaClient := aws.NewLambdaClient(awsConfig)
+	lambdaDeployer := deployer.NewDeployer(lambdaClient, nil, nil, deployer.DeploymentConfig{
+		FunctionName: functionName,
+	})
+
+	if verbose {
+		infof("Pruning versions of %s, keeping the newest %d...\n", functionName, pruneKeepVersions)
+	}
+
+	pruned, err := lambdaDeployer.PruneVersions(ctx, pruneKeepVersions)
+	for _, version := range pruned {
+		infof("✓ Deleted version %s\n", version)
+	}
+	if err != nil {
+		infof("✗ Pruning failed\n")
+		return err
+	}
+
+	if len(pruned) == 0 {
+		infoln("No versions to prune.")
+	} else {
+		infof("\nPruned %d version(s) of %s.\n", len(pruned), functionName)
+	}
+
+	return nil
+}
@@ -1,38 +1,54 @@
 package cli
 
 import (
-	"context"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/openshift-online/regional-cli/internal/aws"
+	"github.com/openshift-online/regional-cli/internal/permissions"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/spf13/cobra"
 )
 
+var whoamiCheckPermissions string
+
 // NewWhoamiCommand creates the whoami command
 func NewWhoamiCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "whoami",
 		Short: "Display current AWS identity information",
-		Long:  `Display the current AWS STS caller identity including UserId and Account.`,
-		RunE:  runWhoami,
+		Long: `Display the current AWS STS caller identity including UserId and Account.
+
+With --check-permissions, also simulate the caller's IAM policies for the actions a
+rosactl operation needs and print whether each action is allowed. The simulation
+uses iam:SimulatePrincipalPolicy and evaluates identity-based policies against all
+resources; service control policies and resource policies are not considered.`,
+		RunE: runWhoami,
 	}
 
+	cmd.Flags().StringVar(&whoamiCheckPermissions, "check-permissions", "",
+		"Simulate the permissions needed for an operation: "+strings.Join(permissions.SetNames(), ", "))
+
 	return cmd
 }
 
 func runWhoami(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx := cmd.Context()
 	profile, region, _, _ := getGlobalFlags()
 
+	if whoamiCheckPermissions != "" {
+		if _, err := permissions.Actions(whoamiCheckPermissions); err != nil {
+			return err
+		}
+	}
+
 	// Create AWS config
-	awsConfig, err := aws.NewConfig(ctx, aws.ClientConfig{
-		Profile: profile,
-		Region:  region,
-	})
+	awsConfig, err := newAWSConfig(ctx, profile, region)
 	if err != nil {
-		return fmt.Errorf("failed to load AWS config: %w", err)
+		return err
 	}
 
 	// Get caller identity
@@ -47,5 +63,34 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Account: %s\n", awssdk.ToString(output.Account))
 	fmt.Printf("Arn:     %s\n", awssdk.ToString(output.Arn))
 
+	if whoamiCheckPermissions == "" {
+		return nil
+	}
+
+	results, err := permissions.Simulate(ctx, aws.NewIAMClient(awsConfig), awssdk.ToString(output.Arn), whoamiCheckPermissions)
+	if err != nil {
+		return err
+	}
+
+	denied := 0
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ACTION\tRESULT\tDECISION")
+	for _, result := range results {
+		status := "allow"
+		if !result.Allowed {
+			status = "deny"
+			denied++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", result.Action, status, result.Decision)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if denied > 0 {
+		return fmt.Errorf("%d of %d actions needed for %s are denied", denied, len(results), whoamiCheckPermissions)
+	}
+	infof("\n✓ All %d actions needed for %s are allowed\n", len(results), whoamiCheckPermissions)
 	return nil
 }
@@ -0,0 +1,330 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/openshift-online/regional-cli/internal/validator"
+	"github.com/openshift-online/regional-cli/pkg/ratelimit"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// configFileEnv overrides the default config file location
+	configFileEnv = "ROSACTL_CONFIG"
+
+	defaultConfigFileName = "config.yaml"
+
+	// redactedValue replaces secret values in printed configuration
+	redactedValue = "REDACTED"
+)
+
+// Source identifies where an effective configuration value came from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	SourceSecret  Source = "secret-store"
+)
+
+// Config holds settings that can be provided by the config file, environment, or flags.
+// Each field is tagged with its config file key and, where supported, its environment
+// variable, flag name, and a default other than the zero value; fields tagged
+// secret:"true" are redacted when printed.
+type Config struct {
+	Profile        string `yaml:"profile" env:"ROSACTL_PROFILE" flag:"profile"`
+	Region         string `yaml:"region" env:"ROSACTL_REGION" flag:"region"`
+	PlatformAPIURL string `yaml:"platform_api_url" env:"ROSACTL_PLATFORM_API_URL" flag:"platform-api-url"`
+	Verbose        bool   `yaml:"verbose" env:"ROSACTL_VERBOSE" flag:"verbose"`
+	Quiet          bool   `yaml:"quiet" env:"ROSACTL_QUIET" flag:"quiet"`
+	NoCache        bool   `yaml:"no_cache" env:"ROSACTL_NO_CACHE" flag:"no-cache"`
+	UseDualStack   bool   `yaml:"use_dualstack" env:"ROSACTL_USE_DUALSTACK" flag:"use-dualstack"`
+	Proxy          string `yaml:"proxy" env:"ROSACTL_PROXY" flag:"proxy"`
+	TagKeyPrefix   string `yaml:"tag_key_prefix" env:"ROSACTL_TAG_KEY_PREFIX" flag:"tag-key-prefix"`
+	TagKeyCase     string `yaml:"tag_key_case" env:"ROSACTL_TAG_KEY_CASE" flag:"tag-key-case"`
+	NamePrefix     string `yaml:"name_prefix" env:"ROSACTL_NAME_PREFIX" flag:"name-prefix"`
+	NameSuffix     string `yaml:"name_suffix" env:"ROSACTL_NAME_SUFFIX" flag:"name-suffix"`
+	Environment    string `yaml:"environment" env:"ROSACTL_ENVIRONMENT" flag:"environment"`
+
+	// MaxRetries is how often a failed AWS request is retried; -1 keeps the SDK default.
+	// RequestTimeout bounds each attempt; zero means no limit.
+	MaxRetries     int           `yaml:"max_retries" env:"ROSACTL_MAX_RETRIES" flag:"max-retries" default:"-1"`
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"ROSACTL_REQUEST_TIMEOUT" flag:"request-timeout"`
+
+	// MetricsEndpoint is the OTLP collector that receives the duration and exit class of
+	// each command run; empty disables command metrics. MetricsProtocol selects OTLP
+	// over HTTP (http/protobuf) or gRPC (grpc).
+	MetricsEndpoint string `yaml:"metrics_endpoint" env:"ROSACTL_METRICS_ENDPOINT"`
+	MetricsProtocol string `yaml:"metrics_protocol" env:"ROSACTL_METRICS_PROTOCOL" default:"http/protobuf"`
+
+	// PlatformToken authenticates to the Platform API. It belongs in the secret store;
+	// `rosactl config encrypt` moves a plain-text value out of the config file.
+	PlatformToken string `yaml:"platform_token" env:"ROSACTL_PLATFORM_TOKEN" secret:"true"`
+
+	// Tags are applied to deployed resources; setup-account --tag values override them
+	Tags map[string]string `yaml:"tags"`
+
+	// RateLimits override the default client-side AWS request limits per service;
+	// --rate-limit values override them
+	RateLimits ratelimit.Limits `yaml:"rate_limits"`
+
+	// ValidationHooks run before and after the init validation suite so onboarding can
+	// be gated on internal approval systems
+	ValidationHooks []validator.Hook `yaml:"validation_hooks"`
+}
+
+// Provenance records which source supplied a field's effective value
+type Provenance struct {
+	Source Source
+	Origin string // File path, environment variable, or flag name
+}
+
+// Resolved is the effective configuration after merging file, environment, and flags
+type Resolved struct {
+	Config     Config
+	File       string                // Config file that was read, empty if none exists
+	Provenance map[string]Provenance // Keyed by config file key
+}
+
+// ConfigFile returns the config file path (~/.rosactl/config.yaml unless ROSACTL_CONFIG is set)
+func ConfigFile() (string, error) {
+	if path := os.Getenv(configFileEnv); path != "" {
+		return path, nil
+	}
+
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, defaultConfigFileName), nil
+}
+
+// Load merges the config file at path, ROSACTL_* environment variables, and explicitly
+// set flags, in increasing order of precedence. A missing config file is not an error.
+func Load(path string, flags *pflag.FlagSet) (*Resolved, error) {
+	return load(path, os.LookupEnv, flags)
+}
+
+func load(path string, lookupEnv func(string) (string, bool), flags *pflag.FlagSet) (*Resolved, error) {
+	resolved := &Resolved{
+		Provenance: make(map[string]Provenance),
+	}
+
+	v := reflect.ValueOf(&resolved.Config).Elem()
+
+	for _, f := range configFields() {
+		resolved.Provenance[f.key] = Provenance{Source: SourceDefault}
+		if f.defaultValue != "" {
+			if err := setField(v.Field(f.index), f.defaultValue); err != nil {
+				return nil, fmt.Errorf("invalid default for %s: %w", f.key, err)
+			}
+		}
+	}
+
+	if path != "" {
+		if err := resolved.applyFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range configFields() {
+		if f.env == "" {
+			continue
+		}
+		if raw, ok := lookupEnv(f.env); ok && raw != "" {
+			if err := setField(v.Field(f.index), raw); err != nil {
+				return nil, fmt.Errorf("invalid value for %s: %w", f.env, err)
+			}
+			resolved.Provenance[f.key] = Provenance{Source: SourceEnv, Origin: f.env}
+		}
+	}
+
+	for _, f := range configFields() {
+		if flags == nil || f.flag == "" {
+			continue
+		}
+		if flag := flags.Lookup(f.flag); flag != nil && flag.Changed {
+			if err := setField(v.Field(f.index), flag.Value.String()); err != nil {
+				return nil, fmt.Errorf("invalid value for --%s: %w", f.flag, err)
+			}
+			resolved.Provenance[f.key] = Provenance{Source: SourceFlag, Origin: "--" + f.flag}
+		}
+	}
+
+	return resolved, nil
+}
+
+// applyFile reads the YAML config file at path into r
+func (r *Resolved) applyFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, &r.Config); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	r.File = path
+
+	known := make(map[string]bool)
+	for _, f := range configFields() {
+		known[f.key] = true
+		if _, ok := raw[f.key]; ok {
+			r.Provenance[f.key] = Provenance{Source: SourceFile, Origin: path}
+		}
+	}
+
+	for key := range raw {
+		if !known[key] {
+			return fmt.Errorf("unknown key %q in config file %s", key, path)
+		}
+	}
+
+	return nil
+}
+
+// Environ returns the effective configuration as ROSACTL_* environment variable
+// assignments, so a child process such as a plugin sees the same settings. Secrets
+// are left out; the child inherits them only if they were set in the environment.
+func (r *Resolved) Environ() []string {
+	v := reflect.ValueOf(r.Config)
+
+	var env []string
+	for _, f := range configFields() {
+		if f.env == "" || f.secret {
+			continue
+		}
+		env = append(env, fmt.Sprintf("%s=%v", f.env, v.Field(f.index).Interface()))
+	}
+	if r.File != "" {
+		env = append(env, configFileEnv+"="+r.File)
+	}
+	return env
+}
+
+// FieldView is a single redacted configuration value with its provenance
+type FieldView struct {
+	Key        string
+	Value      interface{}
+	Provenance Provenance
+}
+
+// View returns the effective configuration in declaration order with secrets redacted
+func (r *Resolved) View() []FieldView {
+	v := reflect.ValueOf(r.Config)
+
+	var fields []FieldView
+	for _, f := range configFields() {
+		value := v.Field(f.index).Interface()
+		if d, ok := value.(time.Duration); ok {
+			// As written in the config file, rather than in nanoseconds
+			value = d.String()
+		}
+		fields = append(fields, FieldView{
+			Key:        f.key,
+			Value:      redact(value, f.secret),
+			Provenance: r.Provenance[f.key],
+		})
+	}
+
+	return fields
+}
+
+// redact hides secret values and credentials embedded in URLs
+func redact(value interface{}, secret bool) interface{} {
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return value
+	}
+
+	if secret {
+		return redactedValue
+	}
+
+	if u, err := url.Parse(s); err == nil && u.User != nil {
+		u.User = url.User(redactedValue)
+		return u.String()
+	}
+
+	return s
+}
+
+// configField describes how a Config field is sourced
+type configField struct {
+	index        int
+	key          string
+	env          string
+	flag         string
+	defaultValue string
+	secret       bool
+}
+
+// configFields returns the tagged fields of Config in declaration order
+func configFields() []configField {
+	t := reflect.TypeOf(Config{})
+
+	fields := make([]configField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fields = append(fields, configField{
+			index:        i,
+			key:          sf.Tag.Get("yaml"),
+			env:          sf.Tag.Get("env"),
+			flag:         sf.Tag.Get("flag"),
+			defaultValue: sf.Tag.Get("default"),
+			secret:       sf.Tag.Get("secret") == "true",
+		})
+	}
+
+	return fields
+}
+
+// setField parses raw into a string, bool, int, or time.Duration field
+func setField(field reflect.Value, raw string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("expected a duration such as 30s, got %q", raw)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", raw)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("expected a boolean, got %q", raw)
+		}
+		field.SetBool(b)
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Kind())
+	}
+	return nil
+}
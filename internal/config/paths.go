@@ -0,0 +1,73 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// homeDirEnv overrides the default rosactl state directory
+	homeDirEnv = "ROSACTL_HOME"
+
+	defaultHomeDirName = ".rosactl"
+)
+
+// HomeDir returns the rosactl state directory (~/.rosactl unless ROSACTL_HOME is set)
+func HomeDir() (string, error) {
+	if dir := os.Getenv(homeDirEnv); dir != "" {
+		return dir, nil
+	}
+
+	userHome, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine user home directory: %w", err)
+	}
+
+	return filepath.Join(userHome, defaultHomeDirName), nil
+}
+
+// CacheDir returns the directory used for cached results
+func CacheDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "cache"), nil
+}
+
+// ValidatorPluginDir returns the directory scanned for external validator plugins
+func ValidatorPluginDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "validators.d"), nil
+}
+
+// ManifestDir returns the directory holding deployment manifests
+func ManifestDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "manifests"), nil
+}
+
+// StateDir returns the directory holding checkpoints of multi-step commands
+func StateDir() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "state"), nil
+}
+
+// SecretsFile returns the encrypted secrets file used when no OS keyring is available
+func SecretsFile() (string, error) {
+	home, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, "secrets.enc"), nil
+}
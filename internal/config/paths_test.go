@@ -0,0 +1,43 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomeDir_EnvOverride(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(homeDirEnv, dir)
+
+	home, err := HomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, dir, home)
+
+	cacheDir, err := CacheDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cache"), cacheDir)
+
+	pluginDir, err := ValidatorPluginDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "validators.d"), pluginDir)
+
+	manifestDir, err := ManifestDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "manifests"), manifestDir)
+
+	stateDir, err := StateDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "state"), stateDir)
+}
+
+func TestHomeDir_Default(t *testing.T) {
+	t.Setenv(homeDirEnv, "")
+	t.Setenv("HOME", "/tmp/rosactl-home")
+
+	home, err := HomeDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("/tmp/rosactl-home", defaultHomeDirName), home)
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+
+	"github.com/openshift-online/regional-cli/internal/secrets"
+	"gopkg.in/yaml.v3"
+)
+
+// ApplySecrets fills secret fields that no other source set from store
+func (r *Resolved) ApplySecrets(store secrets.Store) error {
+	v := reflect.ValueOf(&r.Config).Elem()
+
+	for _, f := range configFields() {
+		if !f.secret || r.Provenance[f.key].Source != SourceDefault {
+			continue
+		}
+
+		value, err := store.Get(f.key)
+		if err != nil {
+			if errors.Is(err, secrets.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+
+		if err := setField(v.Field(f.index), value); err != nil {
+			return fmt.Errorf("invalid value for %s in %s: %w", f.key, store.Description(), err)
+		}
+		r.Provenance[f.key] = Provenance{Source: SourceSecret, Origin: store.Description()}
+	}
+
+	return nil
+}
+
+// EncryptFile moves plain-text secret values out of the config file at path into store
+// and rewrites the file without them, preserving its other keys and comments. It returns
+// the keys that were moved.
+func EncryptFile(path string, store secrets.Store) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	mapping := doc.Content[0]
+
+	secretKeys := make(map[string]bool)
+	for _, f := range configFields() {
+		if f.secret {
+			secretKeys[f.key] = true
+		}
+	}
+
+	var moved []string
+	var kept []*yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if !secretKeys[key.Value] || value.Kind != yaml.ScalarNode || value.Value == "" {
+			kept = append(kept, key, value)
+			continue
+		}
+
+		if err := store.Set(key.Value, value.Value); err != nil {
+			return moved, err
+		}
+		moved = append(moved, key.Value)
+	}
+
+	if len(moved) == 0 {
+		return nil, nil
+	}
+	mapping.Content = kept
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		return moved, fmt.Errorf("failed to encode config file: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return moved, fmt.Errorf("failed to read config file: %w", err)
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		return moved, fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	return moved, nil
+}
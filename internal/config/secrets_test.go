@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"github.com/openshift-online/regional-cli/internal/secrets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapStore is an in-memory secrets.Store
+type mapStore map[string]string
+
+func (m mapStore) Get(key string) (string, error) {
+	value, ok := m[key]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return value, nil
+}
+
+func (m mapStore) Set(key, value string) error {
+	m[key] = value
+	return nil
+}
+
+func (m mapStore) Delete(key string) error {
+	delete(m, key)
+	return nil
+}
+
+func (m mapStore) Description() string { return "test store" }
+
+func TestApplySecrets(t *testing.T) {
+	resolved, err := load("", noEnv, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, resolved.ApplySecrets(mapStore{"platform_token": "stored-token"}))
+	assert.Equal(t, "stored-token", resolved.Config.PlatformToken)
+	assert.Equal(t, Provenance{Source: SourceSecret, Origin: "test store"}, resolved.Provenance["platform_token"])
+
+	for _, field := range resolved.View() {
+		if field.Key == "platform_token" {
+			assert.Equal(t, redactedValue, field.Value)
+		}
+	}
+}
+
+func TestApplySecrets_EnvWins(t *testing.T) {
+	lookupEnv := func(key string) (string, bool) {
+		if key == "ROSACTL_PLATFORM_TOKEN" {
+			return "env-token", true
+		}
+		return "", false
+	}
+
+	resolved, err := load("", lookupEnv, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, resolved.ApplySecrets(mapStore{"platform_token": "stored-token"}))
+	assert.Equal(t, "env-token", resolved.Config.PlatformToken)
+	assert.Equal(t, SourceEnv, resolved.Provenance["platform_token"].Source)
+}
+
+func TestEncryptFile(t *testing.T) {
+	path := writeConfigFile(t, "# rosactl settings\nregion: us-east-1 # home region\nplatform_token: plain-token\nverbose: true\n")
+	store := mapStore{}
+
+	moved, err := EncryptFile(path, store)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform_token"}, moved)
+	assert.Equal(t, "plain-token", store["platform_token"])
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "plain-token")
+	assert.Contains(t, string(data), "# rosactl settings")
+	assert.Contains(t, string(data), "region: us-east-1 # home region")
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	resolved, err := load(path, noEnv, nil)
+	require.NoError(t, err)
+	assert.Empty(t, resolved.Config.PlatformToken)
+	assert.True(t, resolved.Config.Verbose)
+
+	moved, err = EncryptFile(path, store)
+	require.NoError(t, err)
+	assert.Empty(t, moved)
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// viewEntry is the JSON representation of a single configuration value
+type viewEntry struct {
+	Value  interface{} `json:"value"`
+	Source Source      `json:"source"`
+	Origin string      `json:"origin,omitempty"`
+}
+
+// WriteYAML writes the redacted effective configuration as YAML, annotating each
+// key with the source that supplied its value
+func (r *Resolved) WriteYAML(w io.Writer) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, field := range r.View() {
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: field.Key}
+
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(field.Value); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", field.Key, err)
+		}
+		valueNode.LineComment = provenanceComment(field.Provenance)
+
+		doc.Content = append(doc.Content, keyNode, valueNode)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// WriteJSON writes the redacted effective configuration as JSON, with each key
+// mapped to its value and provenance
+func (r *Resolved) WriteJSON(w io.Writer) error {
+	entries := make(map[string]viewEntry)
+	for _, field := range r.View() {
+		entries[field.Key] = viewEntry{
+			Value:  field.Value,
+			Source: field.Provenance.Source,
+			Origin: field.Provenance.Origin,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// provenanceComment renders a provenance as a YAML line comment
+func provenanceComment(p Provenance) string {
+	if p.Origin == "" {
+		return string(p.Source)
+	}
+	return fmt.Sprintf("%s (%s)", p.Source, p.Origin)
+}
@@ -0,0 +1,330 @@
+// Package fleet rolls the OIDC provisioner out to many AWS accounts in stages. The
+// accounts are deployed in batches, several at a time, and each batch is verified
+// before the next one starts, so a bad release stops after one batch instead of
+// reaching every account.
+package fleet
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"gopkg.in/yaml.v3"
+)
+
+// Rollout defaults
+const (
+	DefaultMaxParallel = 5
+	DefaultBatchSize   = 20
+)
+
+// Account statuses in a rollout report
+const (
+	StatusSucceeded          = "succeeded"
+	StatusDeployFailed       = "deploy_failed"
+	StatusVerificationFailed = "verification_failed"
+	StatusSkipped            = "skipped"
+)
+
+// Account is an AWS account of the fleet. The deployment runs with the credentials of
+// RoleARN, assumed with the caller's credentials.
+type Account struct {
+	Name    string `yaml:"name" json:"name"`
+	RoleARN string `yaml:"role_arn" json:"role_arn"`
+	Region  string `yaml:"region" json:"region"`
+}
+
+// AccountID returns the ID of the account RoleARN is in
+func (a Account) AccountID() string {
+	parsed, err := arn.Parse(a.RoleARN)
+	if err != nil {
+		return ""
+	}
+	return parsed.AccountID
+}
+
+// accountsFile is the layout of an accounts file
+type accountsFile struct {
+	Accounts []Account `yaml:"accounts"`
+}
+
+// LoadAccounts reads the accounts of a fleet from a YAML file with an accounts list.
+// Each account needs a role_arn; its name defaults to the role's account ID and its
+// region to defaultRegion. An account and region listed twice is refused, since both
+// entries would deploy the same function.
+func LoadAccounts(path, defaultRegion string) ([]Account, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open accounts file: %w", err)
+		}
+		defer file.Close()
+		r = file
+	}
+	return ReadAccounts(r, defaultRegion)
+}
+
+// ReadAccounts reads the accounts of a fleet as LoadAccounts does
+func ReadAccounts(r io.Reader, defaultRegion string) ([]Account, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read accounts file: %w", err)
+	}
+
+	var file accountsFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("accounts file is empty")
+		}
+		return nil, fmt.Errorf("invalid accounts file: %w", err)
+	}
+	if len(file.Accounts) == 0 {
+		return nil, errors.New("accounts file lists no accounts")
+	}
+
+	seen := make(map[string]int)
+	for i := range file.Accounts {
+		account := &file.Accounts[i]
+		parsed, err := arn.Parse(account.RoleARN)
+		if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+			return nil, fmt.Errorf("account %d: role_arn %q is not an IAM role ARN", i+1, account.RoleARN)
+		}
+		if account.Name == "" {
+			account.Name = parsed.AccountID
+		}
+		if account.Region == "" {
+			account.Region = defaultRegion
+		}
+		if account.Region == "" {
+			return nil, fmt.Errorf("account %s: region is required when no default region is set", account.Name)
+		}
+
+		key := parsed.AccountID + "/" + account.Region
+		if first, ok := seen[key]; ok {
+			return nil, fmt.Errorf("account %s: account %s in %s is already listed as account %d",
+				account.Name, parsed.AccountID, account.Region, first)
+		}
+		seen[key] = i + 1
+	}
+	return file.Accounts, nil
+}
+
+// Deployment is what deploying the provisioner to an account produced
+type Deployment struct {
+	FunctionARN string
+	Status      string // The deployer's status, such as created or updated
+	Version     string
+	Warnings    []string
+}
+
+// DeployFunc deploys the provisioner to an account
+type DeployFunc func(ctx context.Context, account Account) (*Deployment, error)
+
+// VerifyFunc checks the provisioner deployed to an account works
+type VerifyFunc func(ctx context.Context, account Account, deployment *Deployment) error
+
+// Options stage a rollout
+type Options struct {
+	MaxParallel int           // Accounts deployed at once; zero uses DefaultMaxParallel
+	BatchSize   int           // Accounts per batch; zero uses DefaultBatchSize
+	Pause       time.Duration // Wait after a verified batch before starting the next
+
+	// OnBatch, when set, is called as each batch starts, with its 1-based number and
+	// the number of batches
+	OnBatch func(batch, batches int)
+
+	// OnResult, when set, is called as each account finishes. Calls are serialized.
+	OnResult func(AccountResult)
+}
+
+// AccountResult is the outcome of rolling out to one account
+type AccountResult struct {
+	Account      Account  `json:"account"`
+	AccountID    string   `json:"account_id"`
+	Status       string   `json:"status"`
+	DeployStatus string   `json:"deploy_status,omitempty"`
+	FunctionARN  string   `json:"function_arn,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	DurationMS   int64    `json:"duration_ms"`
+}
+
+// Batch is the outcome of one batch of a rollout
+type Batch struct {
+	Number   int             `json:"number"`
+	Status   string          `json:"status"` // succeeded, or the first failure status of its accounts, or skipped
+	Accounts []AccountResult `json:"accounts"`
+}
+
+// Report is the outcome of a rollout, batch by batch
+type Report struct {
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	MaxParallel int       `json:"max_parallel"`
+	BatchSize   int       `json:"batch_size"`
+	Halted      bool      `json:"halted"` // A batch failed or the rollout was interrupted, so later accounts were skipped
+	Batches     []Batch   `json:"batches"`
+}
+
+// Count returns the number of accounts that ended with status
+func (r *Report) Count(status string) int {
+	count := 0
+	for _, batch := range r.Batches {
+		for _, result := range batch.Accounts {
+			if result.Status == status {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// Total returns the number of accounts in the rollout
+func (r *Report) Total() int {
+	total := 0
+	for _, batch := range r.Batches {
+		total += len(batch.Accounts)
+	}
+	return total
+}
+
+// Rollout deploys to accounts in batches of opts.BatchSize, with at most
+// opts.MaxParallel deployments in flight. Once a batch's deployments finish, every
+// account deployed is verified. A batch with a failed deployment or verification
+// halts the rollout and later batches are skipped; otherwise the rollout waits
+// opts.Pause and starts the next batch. Once ctx is done no further accounts are
+// started and they are reported as skipped.
+func Rollout(ctx context.Context, accounts []Account, deploy DeployFunc, verify VerifyFunc, opts Options) *Report {
+	maxParallel := opts.MaxParallel
+	if maxParallel <= 0 {
+		maxParallel = DefaultMaxParallel
+	}
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	report := &Report{StartedAt: time.Now().UTC(), MaxParallel: maxParallel, BatchSize: batchSize}
+	var mu sync.Mutex
+	finish := func(result *AccountResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		if opts.OnResult != nil {
+			opts.OnResult(*result)
+		}
+	}
+
+	batches := (len(accounts) + batchSize - 1) / batchSize
+	for b := 0; b < batches; b++ {
+		members := accounts[b*batchSize : min((b+1)*batchSize, len(accounts))]
+		batch := Batch{Number: b + 1, Accounts: make([]AccountResult, len(members))}
+		for i, account := range members {
+			batch.Accounts[i] = AccountResult{Account: account, AccountID: account.AccountID(), Status: StatusSkipped}
+		}
+
+		if report.Halted || ctx.Err() != nil {
+			report.Halted = true
+			batch.Status = StatusSkipped
+			report.Batches = append(report.Batches, batch)
+			continue
+		}
+		if opts.OnBatch != nil {
+			opts.OnBatch(b+1, batches)
+		}
+
+		runBatch(ctx, batch.Accounts, deploy, verify, maxParallel, finish)
+
+		batch.Status = StatusSucceeded
+		for _, result := range batch.Accounts {
+			if result.Status != StatusSucceeded {
+				batch.Status = result.Status
+				report.Halted = true
+				break
+			}
+		}
+		report.Batches = append(report.Batches, batch)
+
+		if !report.Halted && b < batches-1 && opts.Pause > 0 {
+			timer := time.NewTimer(opts.Pause)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+			case <-timer.C:
+			}
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	return report
+}
+
+// runBatch deploys to the batch's accounts, at most maxParallel at once, then verifies
+// each account deployed, recording the outcomes in results
+func runBatch(ctx context.Context, results []AccountResult, deploy DeployFunc, verify VerifyFunc,
+	maxParallel int, finish func(*AccountResult)) {
+	deployments := make([]*Deployment, len(results))
+	started := make([]time.Time, len(results))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < maxParallel && w < len(results); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				started[i] = time.Now()
+				deployment, err := deploy(ctx, results[i].Account)
+				if err != nil {
+					results[i].Status = StatusDeployFailed
+					results[i].Error = err.Error()
+					results[i].DurationMS = time.Since(started[i]).Milliseconds()
+					finish(&results[i])
+					continue
+				}
+				deployments[i] = deployment
+				results[i].DeployStatus = deployment.Status
+				results[i].FunctionARN = deployment.FunctionARN
+				results[i].Version = deployment.Version
+				results[i].Warnings = deployment.Warnings
+			}
+		}()
+	}
+
+	for i := range results {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	// Verification starts once every deployment of the batch has finished
+	for i := range results {
+		if deployments[i] == nil {
+			continue
+		}
+		if err := verify(ctx, results[i].Account, deployments[i]); err != nil {
+			results[i].Status = StatusVerificationFailed
+			results[i].Error = err.Error()
+		} else {
+			results[i].Status = StatusSucceeded
+		}
+		results[i].DurationMS = time.Since(started[i]).Milliseconds()
+		finish(&results[i])
+	}
+}
@@ -0,0 +1,173 @@
+package fleet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAccounts(n int) []Account {
+	accounts := make([]Account, n)
+	for i := range accounts {
+		accounts[i] = Account{
+			Name:    fmt.Sprintf("account-%d", i+1),
+			RoleARN: fmt.Sprintf("arn:aws:iam::%012d:role/rosactl-deployer", i+1),
+			Region:  "us-east-1",
+		}
+	}
+	return accounts
+}
+
+func deployOK(ctx context.Context, account Account) (*Deployment, error) {
+	return &Deployment{
+		FunctionARN: "arn:aws:lambda:us-east-1:" + account.AccountID() + ":function:rosa-oidc-provisioner",
+		Status:      "updated",
+	}, nil
+}
+
+func verifyOK(ctx context.Context, account Account, deployment *Deployment) error {
+	return nil
+}
+
+func TestReadAccounts(t *testing.T) {
+	accounts, err := ReadAccounts(strings.NewReader(`accounts:
+  - name: payments-prod
+    role_arn: arn:aws:iam::111111111111:role/rosactl-deployer
+    region: eu-west-1
+  - role_arn: arn:aws:iam::222222222222:role/rosactl-deployer
+`), "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, []Account{
+		{Name: "payments-prod", RoleARN: "arn:aws:iam::111111111111:role/rosactl-deployer", Region: "eu-west-1"},
+		{Name: "222222222222", RoleARN: "arn:aws:iam::222222222222:role/rosactl-deployer", Region: "us-east-1"},
+	}, accounts)
+}
+
+func TestReadAccounts_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		region  string
+		wantErr string
+	}{
+		{name: "empty", input: "", region: "us-east-1", wantErr: "accounts file is empty"},
+		{name: "no accounts", input: "accounts: []", region: "us-east-1", wantErr: "lists no accounts"},
+		{name: "unknown field", input: "accounts:\n  - role: x", region: "us-east-1", wantErr: "field role not found"},
+		{name: "user ARN", input: "accounts:\n  - role_arn: arn:aws:iam::111111111111:user/alice", region: "us-east-1", wantErr: "not an IAM role ARN"},
+		{name: "no region", input: "accounts:\n  - role_arn: arn:aws:iam::111111111111:role/a", wantErr: "region is required"},
+		{
+			name:    "duplicate",
+			input:   "accounts:\n  - role_arn: arn:aws:iam::111111111111:role/a\n  - role_arn: arn:aws:iam::111111111111:role/b",
+			region:  "us-east-1",
+			wantErr: "already listed as account 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadAccounts(strings.NewReader(tt.input), tt.region)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRollout_Batches(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	deploy := func(ctx context.Context, account Account) (*Deployment, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return deployOK(ctx, account)
+	}
+
+	var batches []int
+	var results []string
+	report := Rollout(context.Background(), testAccounts(5), deploy, verifyOK, Options{
+		MaxParallel: 2,
+		BatchSize:   3,
+		OnBatch:     func(batch, total int) { batches = append(batches, batch*10+total) },
+		OnResult:    func(result AccountResult) { results = append(results, result.Account.Name) },
+	})
+
+	assert.False(t, report.Halted)
+	assert.Equal(t, []int{12, 22}, batches)
+	assert.Len(t, results, 5)
+	assert.Equal(t, int32(2), maxInFlight.Load())
+	require.Len(t, report.Batches, 2)
+	assert.Len(t, report.Batches[0].Accounts, 3)
+	assert.Len(t, report.Batches[1].Accounts, 2)
+	assert.Equal(t, 5, report.Count(StatusSucceeded))
+	assert.Equal(t, StatusSucceeded, report.Batches[1].Status)
+	assert.Equal(t, "000000000005", report.Batches[1].Accounts[1].AccountID)
+	assert.Equal(t, "updated", report.Batches[1].Accounts[1].DeployStatus)
+}
+
+func TestRollout_HaltsAfterFailedBatch(t *testing.T) {
+	deploy := func(ctx context.Context, account Account) (*Deployment, error) {
+		if account.Name == "account-2" {
+			return nil, errors.New("access denied")
+		}
+		return deployOK(ctx, account)
+	}
+
+	var deployed sync.Map
+	verify := func(ctx context.Context, account Account, deployment *Deployment) error {
+		deployed.Store(account.Name, true)
+		return nil
+	}
+	report := Rollout(context.Background(), testAccounts(6), deploy, verify, Options{BatchSize: 2})
+
+	assert.True(t, report.Halted)
+	require.Len(t, report.Batches, 3)
+	assert.Equal(t, StatusDeployFailed, report.Batches[0].Status)
+	assert.Equal(t, StatusSucceeded, report.Batches[0].Accounts[0].Status)
+	assert.Equal(t, "access denied", report.Batches[0].Accounts[1].Error)
+	assert.Equal(t, StatusSkipped, report.Batches[1].Status)
+	assert.Equal(t, StatusSkipped, report.Batches[2].Accounts[1].Status)
+	assert.Equal(t, 4, report.Count(StatusSkipped))
+	assert.Equal(t, 6, report.Total())
+	_, verified := deployed.Load("account-3")
+	assert.False(t, verified)
+}
+
+func TestRollout_HaltsAfterFailedVerification(t *testing.T) {
+	verify := func(ctx context.Context, account Account, deployment *Deployment) error {
+		if account.Name == "account-1" {
+			return errors.New("ping failed")
+		}
+		return nil
+	}
+	report := Rollout(context.Background(), testAccounts(2), deployOK, verify, Options{BatchSize: 1, Pause: time.Hour})
+
+	assert.True(t, report.Halted)
+	assert.Equal(t, StatusVerificationFailed, report.Batches[0].Status)
+	assert.Equal(t, "ping failed", report.Batches[0].Accounts[0].Error)
+	assert.NotEmpty(t, report.Batches[0].Accounts[0].FunctionARN)
+	assert.Equal(t, StatusSkipped, report.Batches[1].Status)
+}
+
+func TestRollout_InterruptedDuringPause(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	verify := func(ctx context.Context, account Account, deployment *Deployment) error {
+		cancel()
+		return nil
+	}
+	report := Rollout(ctx, testAccounts(2), deployOK, verify, Options{BatchSize: 1, Pause: time.Hour})
+
+	assert.True(t, report.Halted)
+	assert.Equal(t, StatusSucceeded, report.Batches[0].Status)
+	assert.Equal(t, StatusSkipped, report.Batches[1].Status)
+}
@@ -0,0 +1,152 @@
+package fleet
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AccountStatus is what the provisioner in one account reports: the deployed release,
+// its drift from the expected release, and its recent errors
+type AccountStatus struct {
+	Account          Account  `json:"account"`
+	AccountID        string   `json:"account_id"`
+	FunctionARN      string   `json:"function_arn,omitempty"`
+	Version          string   `json:"version,omitempty"`            // Lambda version of $LATEST or the published version
+	CLIVersion       string   `json:"cli_version,omitempty"`        // rosactl release stamped on the function
+	PackageChecksum  string   `json:"package_checksum,omitempty"`   // Hex-encoded SHA256 stamped on the function
+	CodeSha256       string   `json:"code_sha256,omitempty"`        // Base64-encoded SHA256 Lambda reports
+	State            string   `json:"state,omitempty"`              // Lambda function state, such as Active
+	LastUpdateStatus string   `json:"last_update_status,omitempty"` // Lambda last update status, such as Successful
+	LastModified     string   `json:"last_modified,omitempty"`
+	Drift            []string `json:"drift"`
+	Metrics          *Metrics `json:"metrics,omitempty"`
+	Error            string   `json:"error,omitempty"` // Why the account's status could not be read
+}
+
+// Metrics summarizes the provisioner's invocations over a status report's window
+type Metrics struct {
+	Invocations   float64 `json:"invocations"`
+	Errors        float64 `json:"errors"`
+	Throttles     float64 `json:"throttles"`
+	ErrorRate     float64 `json:"error_rate"` // Errors as a fraction of invocations
+	DurationP95MS int64   `json:"duration_p95_ms"`
+}
+
+// StatusFunc reads the status of the provisioner in an account. It returns what it
+// could read along with any error.
+type StatusFunc func(ctx context.Context, account Account) (*AccountStatus, error)
+
+// StatusReport is the status of the provisioner across a fleet, for dashboards
+type StatusReport struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Since       time.Time       `json:"metrics_since"`
+	Expected    ExpectedRelease `json:"expected"`
+	Versions    map[string]int  `json:"cli_versions"` // Number of accounts by stamped rosactl release; "" is unknown
+	Drifted     int             `json:"drifted"`
+	Unreadable  int             `json:"unreadable"` // Accounts whose status could not be fully read
+	Accounts    []AccountStatus `json:"accounts"`
+}
+
+// ExpectedRelease is the release the fleet's drift is measured against
+type ExpectedRelease struct {
+	CLIVersion      string `json:"cli_version,omitempty"`
+	PackageChecksum string `json:"package_checksum,omitempty"`
+}
+
+// GatherStatus reads the status of every account, at most maxParallel at once, and
+// returns them in the order of accounts. Accounts not started once ctx is done are
+// reported with its error.
+func GatherStatus(ctx context.Context, accounts []Account, status StatusFunc, maxParallel int) []AccountStatus {
+	if maxParallel <= 0 {
+		maxParallel = DefaultMaxParallel
+	}
+
+	results := make([]AccountStatus, len(accounts))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < maxParallel && w < len(accounts); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = readStatus(ctx, accounts[i], status)
+			}
+		}()
+	}
+
+	for i := range accounts {
+		if ctx.Err() != nil {
+			results[i] = AccountStatus{Account: accounts[i], AccountID: accounts[i].AccountID(), Drift: []string{}, Error: ctx.Err().Error()}
+			continue
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// readStatus reads one account's status, filling in the fields the report relies on
+func readStatus(ctx context.Context, account Account, status StatusFunc) AccountStatus {
+	result, err := status(ctx, account)
+	if result == nil {
+		result = &AccountStatus{}
+	}
+	result.Account = account
+	result.AccountID = account.AccountID()
+	if result.Drift == nil {
+		result.Drift = []string{}
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return *result
+}
+
+// NewStatusReport summarizes account statuses read since the given time
+func NewStatusReport(accounts []AccountStatus, expected ExpectedRelease, since time.Time) *StatusReport {
+	report := &StatusReport{
+		GeneratedAt: time.Now().UTC(),
+		Since:       since.UTC(),
+		Expected:    expected,
+		Versions:    make(map[string]int),
+		Accounts:    accounts,
+	}
+	for _, account := range accounts {
+		if account.Error != "" {
+			report.Unreadable++
+		}
+		// An account whose function could not be read has no release to count
+		if account.FunctionARN == "" {
+			continue
+		}
+		report.Versions[account.CLIVersion]++
+		if len(account.Drift) > 0 {
+			report.Drifted++
+		}
+	}
+	return report
+}
+
+// VersionCounts returns the report's rosactl releases, most common first
+func (r *StatusReport) VersionCounts() []VersionCount {
+	counts := make([]VersionCount, 0, len(r.Versions))
+	for version, accounts := range r.Versions {
+		counts = append(counts, VersionCount{CLIVersion: version, Accounts: accounts})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Accounts != counts[j].Accounts {
+			return counts[i].Accounts > counts[j].Accounts
+		}
+		return counts[i].CLIVersion < counts[j].CLIVersion
+	})
+	return counts
+}
+
+// VersionCount is the number of accounts running a rosactl release
+type VersionCount struct {
+	CLIVersion string
+	Accounts   int
+}
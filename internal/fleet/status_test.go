@@ -0,0 +1,64 @@
+package fleet
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGatherStatus(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	status := func(ctx context.Context, account Account) (*AccountStatus, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		switch account.Name {
+		case "account-2":
+			return &AccountStatus{FunctionARN: "arn", CLIVersion: "v1.0.0", Drift: []string{"rosactl version is v1.0.0, expected v1.1.0"}}, nil
+		case "account-3":
+			return nil, errors.New("access denied")
+		}
+		return &AccountStatus{FunctionARN: "arn", CLIVersion: "v1.1.0", State: "Active"}, nil
+	}
+
+	accounts := GatherStatus(context.Background(), testAccounts(4), status, 2)
+	require.Len(t, accounts, 4)
+	assert.Equal(t, int32(2), maxInFlight.Load())
+	assert.Equal(t, "account-1", accounts[0].Account.Name)
+	assert.Equal(t, "000000000001", accounts[0].AccountID)
+	assert.Equal(t, "Active", accounts[0].State)
+	assert.Equal(t, []string{}, accounts[0].Drift)
+	assert.Equal(t, "access denied", accounts[2].Error)
+	assert.Equal(t, "account-3", accounts[2].Account.Name)
+
+	since := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	report := NewStatusReport(accounts, ExpectedRelease{CLIVersion: "v1.1.0"}, since)
+	assert.Equal(t, since, report.Since)
+	assert.Equal(t, map[string]int{"v1.1.0": 2, "v1.0.0": 1}, report.Versions)
+	assert.Equal(t, 1, report.Drifted)
+	assert.Equal(t, 1, report.Unreadable)
+	assert.Equal(t, []VersionCount{{CLIVersion: "v1.1.0", Accounts: 2}, {CLIVersion: "v1.0.0", Accounts: 1}}, report.VersionCounts())
+}
+
+func TestGatherStatus_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	accounts := GatherStatus(ctx, testAccounts(2), func(ctx context.Context, account Account) (*AccountStatus, error) {
+		t.Fatal("status read after cancellation")
+		return nil, nil
+	}, 1)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, context.Canceled.Error(), accounts[1].Error)
+}
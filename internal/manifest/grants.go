@@ -0,0 +1,123 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// grantsFileName is the file in the store's directory recording access grants
+const grantsFileName = "access-grants.json"
+
+// AccessGrant records break-glass access to a cluster granted with rosactl, so it
+// can be listed and revoked until it expires
+type AccessGrant struct {
+	ID             string    `json:"id"`
+	ClusterID      string    `json:"cluster_id"`
+	PrincipalARN   string    `json:"principal_arn"`
+	PlatformAPIURL string    `json:"platform_api_url"`
+	Reason         string    `json:"reason,omitempty"`
+	GrantedBy      string    `json:"granted_by,omitempty"`
+	GrantedAt      time.Time `json:"granted_at"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the grant has expired at now
+func (g AccessGrant) Expired(now time.Time) bool {
+	return !now.Before(g.ExpiresAt)
+}
+
+// SaveGrant records g, replacing any grant with the same ID
+func (s *Store) SaveGrant(g AccessGrant) error {
+	if g.ID == "" || g.ClusterID == "" {
+		return fmt.Errorf("access grant requires an ID and cluster ID")
+	}
+
+	grants, err := s.Grants()
+	if err != nil {
+		return err
+	}
+	kept := grants[:0]
+	for _, existing := range grants {
+		if existing.ID != g.ID {
+			kept = append(kept, existing)
+		}
+	}
+	return s.saveGrants(append(kept, g))
+}
+
+// Grants returns the recorded access grants, soonest to expire first
+func (s *Store) Grants() ([]AccessGrant, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, grantsFileName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read access grants: %w", err)
+	}
+
+	var grants []AccessGrant
+	if err := json.Unmarshal(data, &grants); err != nil {
+		return nil, fmt.Errorf("failed to parse access grants: %w", err)
+	}
+	return grants, nil
+}
+
+// DeleteGrant removes the grant with id. Deleting a missing grant is not an error.
+func (s *Store) DeleteGrant(id string) error {
+	grants, err := s.Grants()
+	if err != nil {
+		return err
+	}
+	kept := grants[:0]
+	for _, g := range grants {
+		if g.ID != id {
+			kept = append(kept, g)
+		}
+	}
+	if len(kept) == len(grants) {
+		return nil
+	}
+	return s.saveGrants(kept)
+}
+
+// PruneGrants removes the grants that have expired and returns them. The Platform API
+// removes expired access itself; pruning only keeps the local record current.
+func (s *Store) PruneGrants() ([]AccessGrant, error) {
+	grants, err := s.Grants()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var active, expired []AccessGrant
+	for _, g := range grants {
+		if g.Expired(now) {
+			expired = append(expired, g)
+		} else {
+			active = append(active, g)
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+	return expired, s.saveGrants(active)
+}
+
+// saveGrants writes grants sorted by expiry
+func (s *Store) saveGrants(grants []AccessGrant) error {
+	sort.SliceStable(grants, func(i, j int) bool { return grants[i].ExpiresAt.Before(grants[j].ExpiresAt) })
+	if grants == nil {
+		grants = []AccessGrant{}
+	}
+
+	data, err := json.MarshalIndent(grants, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal access grants: %w", err)
+	}
+	return s.writeFile(filepath.Join(s.dir, grantsFileName), data)
+}
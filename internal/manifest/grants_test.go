@@ -0,0 +1,76 @@
+package manifest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Grants(t *testing.T) {
+	store := NewStore(t.TempDir())
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	grants, err := store.Grants()
+	require.NoError(t, err)
+	assert.Empty(t, grants)
+
+	later := AccessGrant{ID: "g-2", ClusterID: "c-1", ExpiresAt: now.Add(2 * time.Hour)}
+	sooner := AccessGrant{ID: "g-1", ClusterID: "c-1", ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, store.SaveGrant(later))
+	require.NoError(t, store.SaveGrant(sooner))
+
+	grants, err = store.Grants()
+	require.NoError(t, err)
+	assert.Equal(t, []AccessGrant{sooner, later}, grants)
+
+	// Saving a grant again replaces it
+	sooner.Reason = "INC-1"
+	require.NoError(t, store.SaveGrant(sooner))
+	grants, err = store.Grants()
+	require.NoError(t, err)
+	assert.Equal(t, []AccessGrant{sooner, later}, grants)
+
+	require.NoError(t, store.DeleteGrant("g-2"))
+	require.NoError(t, store.DeleteGrant("missing"))
+	grants, err = store.Grants()
+	require.NoError(t, err)
+	assert.Equal(t, []AccessGrant{sooner}, grants)
+
+	assert.Error(t, store.SaveGrant(AccessGrant{ID: "g-3"}))
+}
+
+func TestStore_PruneGrants(t *testing.T) {
+	store := NewStore(t.TempDir())
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	expired := AccessGrant{ID: "g-1", ClusterID: "c-1", ExpiresAt: now}
+	active := AccessGrant{ID: "g-2", ClusterID: "c-1", ExpiresAt: now.Add(time.Minute)}
+	require.NoError(t, store.SaveGrant(expired))
+	require.NoError(t, store.SaveGrant(active))
+
+	pruned, err := store.PruneGrants()
+	require.NoError(t, err)
+	assert.Equal(t, []AccessGrant{expired}, pruned)
+
+	grants, err := store.Grants()
+	require.NoError(t, err)
+	assert.Equal(t, []AccessGrant{active}, grants)
+
+	pruned, err = store.PruneGrants()
+	require.NoError(t, err)
+	assert.Empty(t, pruned)
+}
+
+func TestStore_ListSkipsGrants(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.SaveGrant(AccessGrant{ID: "g-1", ClusterID: "c-1"}))
+	require.NoError(t, store.Save(&Manifest{FunctionName: "rosa-oidc-provisioner", Region: "us-east-1"}))
+
+	manifests, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, manifests, 1)
+}
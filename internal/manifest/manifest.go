@@ -0,0 +1,217 @@
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Resource records a single resource owned by a deployment
+type Resource struct {
+	Type       string `json:"type"`
+	Identifier string `json:"identifier"`
+	Action     string `json:"action"`
+}
+
+// MaxHistory is the number of deployments kept in a manifest's history
+const MaxHistory = 20
+
+// HistoryEntry records who ran a deployment, when, and what it deployed
+type HistoryEntry struct {
+	DeployedAt      time.Time `json:"deployed_at"`
+	DeployedBy      string    `json:"deployed_by,omitempty"`
+	AccountID       string    `json:"account_id,omitempty"`
+	AccountAlias    string    `json:"account_alias,omitempty"`
+	Region          string    `json:"region,omitempty"`
+	Partition       string    `json:"partition,omitempty"`
+	Status          string    `json:"status"`
+	Version         string    `json:"version,omitempty"`
+	PackageChecksum string    `json:"package_checksum"`
+}
+
+// AppendHistory appends entry to history, dropping the oldest entries beyond limit
+func AppendHistory(history []HistoryEntry, entry HistoryEntry, limit int) []HistoryEntry {
+	history = append(history, entry)
+	if limit > 0 && len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+	return history
+}
+
+// Naming records the resource naming convention a deployment applied, so later
+// commands can derive the same names
+type Naming struct {
+	Prefix string `json:"prefix,omitempty"`
+	Suffix string `json:"suffix,omitempty"`
+}
+
+// Manifest records the resources rosactl manages for one deployed function
+type Manifest struct {
+	FunctionName     string     `json:"function_name"`
+	Region           string     `json:"region"`
+	AccountID        string     `json:"account_id,omitempty"`
+	AccountAlias     string     `json:"account_alias,omitempty"`
+	Partition        string     `json:"partition,omitempty"`
+	FunctionARN      string     `json:"function_arn"`
+	ExecutionRoleARN string     `json:"execution_role_arn"`
+	LogGroupName     string     `json:"log_group_name"`
+	PackageChecksum  string     `json:"package_checksum"`
+	Version          string     `json:"version,omitempty"`
+	Environment      string     `json:"environment,omitempty"`
+	Naming           *Naming    `json:"naming,omitempty"`
+	Resources        []Resource `json:"resources"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// History lists recent deployments, oldest first
+	History []HistoryEntry `json:"history,omitempty"`
+}
+
+// Store persists deployment manifests as JSON files on disk
+type Store struct {
+	dir string
+	now func() time.Time
+}
+
+// NewStore creates a manifest store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{
+		dir: dir,
+		now: time.Now,
+	}
+}
+
+// Save writes m, replacing any previous manifest for the same region and function
+func (s *Store) Save(m *Manifest) error {
+	if m.FunctionName == "" || m.Region == "" {
+		return fmt.Errorf("manifest requires a function name and region")
+	}
+
+	m.UpdatedAt = s.now().UTC()
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	return s.writeFile(s.path(m.Region, m.FunctionName), data)
+}
+
+// writeFile writes data to path in the store's directory atomically, so a failed
+// write never corrupts the previous file
+func (s *Store) writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp(s.dir, "manifest-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create manifest: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	if err := os.Rename(tmpFile.Name(), path); err != nil {
+		return fmt.Errorf("failed to store manifest: %w", err)
+	}
+
+	return nil
+}
+
+// Load returns the manifest for functionName in region, or nil if none has been recorded
+func (s *Store) Load(region, functionName string) (*Manifest, error) {
+	data, err := os.ReadFile(s.path(region, functionName))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// ReadFile reads a manifest saved outside the store, such as a copy from another machine
+func ReadFile(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if m.FunctionName == "" || m.Region == "" {
+		return nil, fmt.Errorf("%s is not a deployment manifest: it has no function name and region", path)
+	}
+
+	return &m, nil
+}
+
+// Delete removes the manifest for functionName in region. Deleting a missing manifest is not an error.
+func (s *Store) Delete(region, functionName string) error {
+	if err := os.Remove(s.path(region, functionName)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete manifest: %w", err)
+	}
+	return nil
+}
+
+// List returns all recorded manifests sorted by region and function name
+func (s *Store) List() ([]*Manifest, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read manifest directory: %w", err)
+	}
+
+	var manifests []*Manifest
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") || entry.Name() == grantsFileName {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest %s: %w", entry.Name(), err)
+		}
+
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest %s: %w", entry.Name(), err)
+		}
+		manifests = append(manifests, &m)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool {
+		if manifests[i].Region != manifests[j].Region {
+			return manifests[i].Region < manifests[j].Region
+		}
+		return manifests[i].FunctionName < manifests[j].FunctionName
+	})
+
+	return manifests, nil
+}
+
+// path returns the file path for the manifest of functionName in region
+func (s *Store) path(region, functionName string) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%s_%s.json", region, functionName))
+}
@@ -0,0 +1,146 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	err := store.Save(&Manifest{
+		FunctionName: "rosa-oidc-provisioner",
+		Region:       "us-east-1",
+		FunctionARN:  "arn:aws:lambda:us-east-1:123456789012:function:rosa-oidc-provisioner",
+		Resources: []Resource{
+			{Type: "lambda-function", Identifier: "rosa-oidc-provisioner", Action: "adopted"},
+		},
+	})
+	require.NoError(t, err)
+
+	m, err := store.Load("us-east-1", "rosa-oidc-provisioner")
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, "arn:aws:lambda:us-east-1:123456789012:function:rosa-oidc-provisioner", m.FunctionARN)
+	assert.Equal(t, now, m.UpdatedAt)
+	require.Len(t, m.Resources, 1)
+	assert.Equal(t, "adopted", m.Resources[0].Action)
+}
+
+func TestStore_LoadMissing(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	m, err := store.Load("us-east-1", "missing")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+	require.NoError(t, store.Save(&Manifest{FunctionName: "fn", Region: "us-east-1", PackageChecksum: "abc123"}))
+
+	m, err := ReadFile(filepath.Join(dir, "us-east-1_fn.json"))
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", m.PackageChecksum)
+
+	other := filepath.Join(dir, "other.json")
+	require.NoError(t, os.WriteFile(other, []byte(`{"bucket": "releases"}`), 0600))
+	_, err = ReadFile(other)
+	assert.ErrorContains(t, err, "is not a deployment manifest")
+
+	_, err = ReadFile(filepath.Join(dir, "missing.json"))
+	assert.ErrorContains(t, err, "failed to read manifest")
+}
+
+func TestStore_Delete(t *testing.T) {
+	store := NewStore(t.TempDir())
+	require.NoError(t, store.Save(&Manifest{FunctionName: "fn", Region: "us-east-1"}))
+
+	require.NoError(t, store.Delete("us-east-1", "fn"))
+	m, err := store.Load("us-east-1", "fn")
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	// Deleting again is not an error
+	require.NoError(t, store.Delete("us-east-1", "fn"))
+}
+
+func TestStore_SaveRequiresKey(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	err := store.Save(&Manifest{FunctionName: "rosa-oidc-provisioner"})
+	assert.Error(t, err)
+}
+
+func TestStore_List(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStore(dir)
+
+	require.NoError(t, store.Save(&Manifest{FunctionName: "b", Region: "us-west-2"}))
+	require.NoError(t, store.Save(&Manifest{FunctionName: "a", Region: "us-west-2"}))
+	require.NoError(t, store.Save(&Manifest{FunctionName: "z", Region: "eu-west-1"}))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600))
+
+	manifests, err := store.List()
+	require.NoError(t, err)
+	require.Len(t, manifests, 3)
+	assert.Equal(t, "eu-west-1", manifests[0].Region)
+	assert.Equal(t, "a", manifests[1].FunctionName)
+	assert.Equal(t, "b", manifests[2].FunctionName)
+}
+
+func TestStore_ListMissingDir(t *testing.T) {
+	store := NewStore(filepath.Join(t.TempDir(), "missing"))
+
+	manifests, err := store.List()
+	require.NoError(t, err)
+	assert.Empty(t, manifests)
+}
+
+func TestAppendHistory(t *testing.T) {
+	var history []HistoryEntry
+	for i := 0; i < 5; i++ {
+		history = AppendHistory(history, HistoryEntry{Version: string(rune('1' + i))}, 3)
+	}
+
+	require.Len(t, history, 3)
+	assert.Equal(t, "3", history[0].Version)
+	assert.Equal(t, "5", history[2].Version)
+
+	assert.Len(t, AppendHistory(history, HistoryEntry{}, 0), 4, "a zero limit keeps everything")
+}
+
+func TestStore_SaveLoadHistory(t *testing.T) {
+	store := NewStore(t.TempDir())
+	deployedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	err := store.Save(&Manifest{
+		FunctionName: "rosa-oidc-provisioner",
+		Region:       "us-east-1",
+		AccountID:    "123456789012",
+		AccountAlias: "acme-prod",
+		Partition:    "aws",
+		History: []HistoryEntry{
+			{DeployedAt: deployedAt, DeployedBy: "arn:aws:iam::123456789012:user/alice", AccountID: "123456789012", AccountAlias: "acme-prod", Status: "created", PackageChecksum: "abc"},
+		},
+	})
+	require.NoError(t, err)
+
+	m, err := store.Load("us-east-1", "rosa-oidc-provisioner")
+	require.NoError(t, err)
+	require.Len(t, m.History, 1)
+	assert.Equal(t, deployedAt, m.History[0].DeployedAt)
+	assert.Equal(t, "arn:aws:iam::123456789012:user/alice", m.History[0].DeployedBy)
+	assert.Equal(t, "acme-prod", m.History[0].AccountAlias)
+	assert.Equal(t, "123456789012", m.AccountID)
+	assert.Equal(t, "acme-prod", m.AccountAlias)
+	assert.Equal(t, "aws", m.Partition)
+}
@@ -0,0 +1,96 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// maxParameterSize is the value size limit of a standard-tier SSM parameter
+const maxParameterSize = 4096
+
+// ParameterAPI defines the SSM operations needed to store deployment history
+type ParameterAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput,
+		optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput,
+		optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+// ParameterHistory keeps a rolling deployment history in an SSM parameter, so it is
+// shared by everyone deploying to the account
+type ParameterHistory struct {
+	client ParameterAPI
+	name   string
+}
+
+// NewParameterHistory creates a history stored in the named SSM parameter
+func NewParameterHistory(client ParameterAPI, name string) *ParameterHistory {
+	return &ParameterHistory{
+		client: client,
+		name:   name,
+	}
+}
+
+// Load returns the stored history, oldest first, or nil if the parameter does not exist
+func (p *ParameterHistory) Load(ctx context.Context) ([]HistoryEntry, error) {
+	output, err := p.client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(p.name),
+	})
+	if err != nil {
+		var notFoundErr *types.ParameterNotFound
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read parameter %s: %w", p.name, err)
+	}
+
+	var history []HistoryEntry
+	if output.Parameter != nil {
+		if err := json.Unmarshal([]byte(aws.ToString(output.Parameter.Value)), &history); err != nil {
+			return nil, fmt.Errorf("failed to parse parameter %s: %w", p.name, err)
+		}
+	}
+
+	return history, nil
+}
+
+// Append adds entry to the stored history. The oldest entries are dropped beyond
+// MaxHistory or when the history no longer fits in a standard parameter.
+func (p *ParameterHistory) Append(ctx context.Context, entry HistoryEntry) error {
+	history, err := p.Load(ctx)
+	if err != nil {
+		return err
+	}
+	history = AppendHistory(history, entry, MaxHistory)
+
+	var value []byte
+	for {
+		value, err = json.Marshal(history)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history: %w", err)
+		}
+		if len(value) <= maxParameterSize || len(history) == 1 {
+			break
+		}
+		history = history[1:]
+	}
+
+	_, err = p.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:        aws.String(p.name),
+		Value:       aws.String(string(value)),
+		Type:        types.ParameterTypeString,
+		Description: aws.String("rosactl OIDC provisioner deployment history"),
+		Overwrite:   aws.Bool(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write parameter %s: %w", p.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package manifest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockParameterClient stores a single parameter value in memory
+type mockParameterClient struct {
+	value  *string
+	getErr error
+	puts   []*ssm.PutParameterInput
+}
+
+func (m *mockParameterClient) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+	if m.value == nil {
+		return nil, &types.ParameterNotFound{}
+	}
+	return &ssm.GetParameterOutput{Parameter: &types.Parameter{Name: params.Name, Value: m.value}}, nil
+}
+
+func (m *mockParameterClient) PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	m.puts = append(m.puts, params)
+	m.value = params.Value
+	return &ssm.PutParameterOutput{}, nil
+}
+
+func TestParameterHistory_LoadMissing(t *testing.T) {
+	history, err := NewParameterHistory(&mockParameterClient{}, "/rosa/history").Load(context.Background())
+	require.NoError(t, err)
+	assert.Nil(t, history)
+}
+
+func TestParameterHistory_LoadError(t *testing.T) {
+	client := &mockParameterClient{getErr: errors.New("access denied")}
+	_, err := NewParameterHistory(client, "/rosa/history").Load(context.Background())
+	assert.ErrorContains(t, err, "failed to read parameter /rosa/history")
+
+	client = &mockParameterClient{value: aws.String("not json")}
+	_, err = NewParameterHistory(client, "/rosa/history").Load(context.Background())
+	assert.ErrorContains(t, err, "failed to parse parameter /rosa/history")
+}
+
+func TestParameterHistory_Append(t *testing.T) {
+	client := &mockParameterClient{}
+	history := NewParameterHistory(client, "/rosa/history")
+	deployedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	require.NoError(t, history.Append(context.Background(), HistoryEntry{DeployedAt: deployedAt, Status: "created"}))
+	require.NoError(t, history.Append(context.Background(), HistoryEntry{DeployedAt: deployedAt.Add(time.Hour), Status: "updated"}))
+
+	require.Len(t, client.puts, 2)
+	assert.Equal(t, "/rosa/history", *client.puts[1].Name)
+	assert.True(t, *client.puts[1].Overwrite)
+	assert.Equal(t, types.ParameterTypeString, client.puts[1].Type)
+
+	entries, err := history.Load(context.Background())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "created", entries[0].Status)
+	assert.Equal(t, "updated", entries[1].Status)
+}
+
+func TestParameterHistory_AppendFitsStandardParameter(t *testing.T) {
+	client := &mockParameterClient{}
+	history := NewParameterHistory(client, "/rosa/history")
+
+	longCaller := "arn:aws:sts::123456789012:assumed-role/" + strings.Repeat("r", 300) + "/session"
+	for i := 0; i < MaxHistory; i++ {
+		require.NoError(t, history.Append(context.Background(), HistoryEntry{DeployedBy: longCaller, Status: "updated"}))
+	}
+
+	assert.LessOrEqual(t, len(*client.value), maxParameterSize)
+
+	var entries []HistoryEntry
+	require.NoError(t, json.Unmarshal([]byte(*client.value), &entries))
+	assert.Greater(t, len(entries), 1)
+	assert.Less(t, len(entries), MaxHistory)
+}
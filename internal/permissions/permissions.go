@@ -0,0 +1,176 @@
+package permissions
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// Permission set names accepted by whoami --check-permissions
+const (
+	SetSetupAccount = "setup-account"
+	SetTeardown     = "teardown"
+	SetClusterAdmin = "cluster-admin"
+)
+
+// sets lists the IAM actions each rosactl operation needs
+var sets = map[string][]string{
+	SetSetupAccount: {
+		"iam:GetRole",
+		"iam:CreateRole",
+		"iam:TagRole",
+		"iam:ListRoleTags",
+		"iam:PutRolePolicy",
+		"iam:UpdateAssumeRolePolicy",
+		"iam:PassRole",
+		"iam:DeleteRolePolicy",
+		"iam:DeleteRole",
+		"lambda:GetFunction",
+		"lambda:CreateFunction",
+		"lambda:UpdateFunctionCode",
+		"lambda:UpdateFunctionConfiguration",
+		"lambda:AddPermission",
+		"lambda:RemovePermission",
+		"lambda:GetPolicy",
+		"lambda:TagResource",
+		"lambda:DeleteFunction",
+		"logs:CreateLogGroup",
+		"logs:DescribeLogGroups",
+		"logs:PutRetentionPolicy",
+		"logs:TagLogGroup",
+		"logs:DeleteLogGroup",
+	},
+	SetTeardown: {
+		"iam:GetRole",
+		"iam:DeleteRolePolicy",
+		"iam:DeleteRole",
+		"iam:ListOpenIDConnectProviders",
+		"iam:ListOpenIDConnectProviderTags",
+		"lambda:GetFunction",
+		"lambda:GetPolicy",
+		"lambda:RemovePermission",
+		"lambda:DeleteFunction",
+		"logs:DescribeLogGroups",
+		"logs:ListTagsForResource",
+		"logs:DeleteLogGroup",
+	},
+	SetClusterAdmin: {
+		"lambda:GetFunction",
+		"lambda:InvokeFunction",
+		"iam:ListOpenIDConnectProviders",
+		"iam:GetOpenIDConnectProvider",
+		"iam:CreateOpenIDConnectProvider",
+		"iam:TagOpenIDConnectProvider",
+		"iam:ListOpenIDConnectProviderTags",
+		"iam:DeleteOpenIDConnectProvider",
+		"cloudwatch:GetMetricData",
+		"logs:StartQuery",
+		"logs:GetQueryResults",
+	},
+}
+
+// SetNames returns the known permission set names, sorted
+func SetNames() []string {
+	names := make([]string, 0, len(sets))
+	for name := range sets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Actions returns the IAM actions in a permission set
+func Actions(set string) ([]string, error) {
+	actions, ok := sets[set]
+	if !ok {
+		return nil, fmt.Errorf("unknown permission set %q (valid: %s)", set, strings.Join(SetNames(), ", "))
+	}
+	return append([]string(nil), actions...), nil
+}
+
+// IAMAPI defines the IAM operations needed to simulate permissions
+type IAMAPI interface {
+	GetRole(ctx context.Context, params *iam.GetRoleInput,
+		optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput,
+		optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+// Result is the simulated decision for one action
+type Result struct {
+	Action   string `json:"action"`
+	Decision string `json:"decision"` // allowed, explicitDeny, or implicitDeny
+	Allowed  bool   `json:"allowed"`
+}
+
+// Simulate runs SimulatePrincipalPolicy for every action in set against all resources.
+// principalARN is the caller ARN from STS; assumed-role session ARNs are resolved to
+// the underlying role.
+func Simulate(ctx context.Context, client IAMAPI, principalARN, set string) ([]Result, error) {
+	actions, err := Actions(set)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := policySourceARN(ctx, client, principalARN)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(actions))
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(client, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(source),
+		ActionNames:     actions,
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate permissions for %s: %w", source, err)
+		}
+		for _, evaluation := range page.EvaluationResults {
+			results = append(results, Result{
+				Action:   aws.ToString(evaluation.EvalActionName),
+				Decision: string(evaluation.EvalDecision),
+				Allowed:  evaluation.EvalDecision == iamTypes.PolicyEvaluationDecisionTypeAllowed,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// policySourceARN converts a caller ARN into an ARN SimulatePrincipalPolicy accepts.
+// An assumed-role session becomes its role; the role is looked up so that roles with
+// a path resolve correctly, falling back to the path-less ARN if the lookup fails.
+func policySourceARN(ctx context.Context, client IAMAPI, principalARN string) (string, error) {
+	parsed, err := arn.Parse(principalARN)
+	if err != nil {
+		return "", fmt.Errorf("invalid principal ARN %q: %w", principalARN, err)
+	}
+	if parsed.Service != "sts" {
+		return principalARN, nil
+	}
+
+	parts := strings.Split(parsed.Resource, "/")
+	if len(parts) < 2 || parts[0] != "assumed-role" {
+		return "", fmt.Errorf("cannot simulate permissions for %s", principalARN)
+	}
+	roleName := parts[1]
+
+	output, err := client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err == nil && output.Role != nil && output.Role.Arn != nil {
+		return aws.ToString(output.Role.Arn), nil
+	}
+	return arn.ARN{
+		Partition: parsed.Partition,
+		Service:   "iam",
+		AccountID: parsed.AccountID,
+		Resource:  "role/" + roleName,
+	}.String(), nil
+}
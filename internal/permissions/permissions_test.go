@@ -0,0 +1,113 @@
+package permissions
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockIAMClient struct {
+	getRoleFunc  func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	simulateFunc func(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+func (m *mockIAMClient) GetRole(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	if m.getRoleFunc != nil {
+		return m.getRoleFunc(ctx, params, optFns...)
+	}
+	return nil, errors.New("AccessDenied")
+}
+
+func (m *mockIAMClient) SimulatePrincipalPolicy(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	return m.simulateFunc(ctx, params, optFns...)
+}
+
+// denyAction allows every requested action except deny
+func denyAction(t *testing.T, source, deny string) func(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	return func(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+		assert.Equal(t, source, aws.ToString(params.PolicySourceArn))
+		output := &iam.SimulatePrincipalPolicyOutput{}
+		for _, action := range params.ActionNames {
+			decision := iamTypes.PolicyEvaluationDecisionTypeAllowed
+			if action == deny {
+				decision = iamTypes.PolicyEvaluationDecisionTypeImplicitDeny
+			}
+			output.EvaluationResults = append(output.EvaluationResults, iamTypes.EvaluationResult{
+				EvalActionName: aws.String(action),
+				EvalDecision:   decision,
+			})
+		}
+		return output, nil
+	}
+}
+
+func TestActions(t *testing.T) {
+	assert.Equal(t, []string{SetClusterAdmin, SetSetupAccount, SetTeardown}, SetNames())
+
+	actions, err := Actions(SetTeardown)
+	require.NoError(t, err)
+	assert.Contains(t, actions, "lambda:DeleteFunction")
+
+	_, err = Actions("admin")
+	assert.ErrorContains(t, err, "valid: cluster-admin, setup-account, teardown")
+}
+
+func TestSimulate_User(t *testing.T) {
+	userARN := "arn:aws:iam::123456789012:user/dev"
+	client := &mockIAMClient{simulateFunc: denyAction(t, userARN, "iam:DeleteRole")}
+
+	results, err := Simulate(context.Background(), client, userARN, SetTeardown)
+	require.NoError(t, err)
+	require.Len(t, results, 12)
+
+	var denied []string
+	for _, r := range results {
+		if !r.Allowed {
+			denied = append(denied, r.Action+" "+r.Decision)
+		}
+	}
+	assert.Equal(t, []string{"iam:DeleteRole implicitDeny"}, denied)
+}
+
+func TestSimulate_AssumedRole(t *testing.T) {
+	sessionARN := "arn:aws:sts::123456789012:assumed-role/Admin/session"
+
+	t.Run("resolves the role path", func(t *testing.T) {
+		roleARN := "arn:aws:iam::123456789012:role/ops/Admin"
+		client := &mockIAMClient{
+			getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+				assert.Equal(t, "Admin", aws.ToString(params.RoleName))
+				return &iam.GetRoleOutput{Role: &iamTypes.Role{Arn: aws.String(roleARN)}}, nil
+			},
+			simulateFunc: denyAction(t, roleARN, ""),
+		}
+		_, err := Simulate(context.Background(), client, sessionARN, SetClusterAdmin)
+		require.NoError(t, err)
+	})
+
+	t.Run("falls back without GetRole", func(t *testing.T) {
+		client := &mockIAMClient{simulateFunc: denyAction(t, "arn:aws:iam::123456789012:role/Admin", "")}
+		_, err := Simulate(context.Background(), client, sessionARN, SetClusterAdmin)
+		require.NoError(t, err)
+	})
+}
+
+func TestSimulate_Errors(t *testing.T) {
+	client := &mockIAMClient{
+		simulateFunc: func(ctx context.Context, params *iam.SimulatePrincipalPolicyInput, optFns ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+			return nil, errors.New("AccessDenied: iam:SimulatePrincipalPolicy")
+		},
+	}
+
+	_, err := Simulate(context.Background(), client, "arn:aws:iam::123456789012:user/dev", SetSetupAccount)
+	assert.ErrorContains(t, err, "AccessDenied")
+
+	_, err = Simulate(context.Background(), client, "arn:aws:sts::123456789012:federated-user/dev", SetSetupAccount)
+	assert.ErrorContains(t, err, "cannot simulate permissions")
+}
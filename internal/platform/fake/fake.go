@@ -0,0 +1,478 @@
+// Package fake is an in-memory Platform API for tests and demos. It serves the same
+// routes as the real API, verifies that requests are signed with SigV4 for
+// execute-api, and can be told to respond slowly or fail so callers' error handling
+// can be exercised without a deployed API.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+)
+
+// basePath is the stage and version prefix of every Platform API resource
+const basePath = "/prod/v0"
+
+// Endpoint names a route of the API, for latencies and failures
+type Endpoint string
+
+const (
+	EndpointLive          Endpoint = "live"
+	EndpointListClusters  Endpoint = "list-clusters"
+	EndpointCreateCluster Endpoint = "create-cluster"
+	EndpointGetCluster    Endpoint = "get-cluster"
+	EndpointGrantAccess   Endpoint = "grant-access"
+	EndpointRevokeAccess  Endpoint = "revoke-access"
+
+	// AllEndpoints applies a latency or failure to every route
+	AllEndpoints Endpoint = "*"
+)
+
+// Endpoints returns the API's routes
+func Endpoints() []Endpoint {
+	return []Endpoint{EndpointLive, EndpointListClusters, EndpointCreateCluster, EndpointGetCluster, EndpointGrantAccess, EndpointRevokeAccess}
+}
+
+// Failure makes an endpoint respond with an error status
+type Failure struct {
+	StatusCode int
+	Body       string // Defaults to an API Gateway style message
+	Count      int    // Number of requests to fail; 0 fails every request
+}
+
+// Request is a request received by the API
+type Request struct {
+	Endpoint    Endpoint
+	Method      string
+	Path        string
+	AccessKeyID string
+}
+
+// API is a fake Platform API. It is an http.Handler and is safe for concurrent use.
+type API struct {
+	mux         *http.ServeMux
+	credentials map[string]aws.Credentials // Keyed by access key ID
+	pageSize    int
+	installTime time.Duration
+	now         func() time.Time
+
+	mu         sync.Mutex
+	latencies  map[Endpoint]time.Duration
+	failures   map[Endpoint]*Failure
+	clusters   []platform.Cluster
+	clusterSeq int
+	grants     map[string][]platform.AccessGrant // Keyed by cluster ID
+	grantSeq   int
+	requests   []Request
+}
+
+// Option configures optional API behavior
+type Option func(*API)
+
+// WithCredentials verifies request signatures against creds. Without credentials,
+// requests must carry a well-formed SigV4 Authorization header but the signature
+// itself is not checked.
+func WithCredentials(creds ...aws.Credentials) Option {
+	return func(a *API) {
+		for _, c := range creds {
+			a.credentials[c.AccessKeyID] = c
+		}
+	}
+}
+
+// WithClusters seeds the clusters the API returns
+func WithClusters(clusters ...platform.Cluster) Option {
+	return func(a *API) {
+		a.clusters = append(a.clusters, clusters...)
+	}
+}
+
+// WithLatency delays every response from endpoint by d
+func WithLatency(endpoint Endpoint, d time.Duration) Option {
+	return func(a *API) {
+		a.latencies[endpoint] = d
+	}
+}
+
+// WithFailure makes endpoint fail as described by f
+func WithFailure(endpoint Endpoint, f Failure) Option {
+	return func(a *API) {
+		a.failures[endpoint] = &f
+	}
+}
+
+// WithPageSize sets how many clusters are returned per page of GET /clusters
+func WithPageSize(n int) Option {
+	return func(a *API) {
+		if n > 0 {
+			a.pageSize = n
+		}
+	}
+}
+
+// WithInstallTime reports installing clusters ready once d has passed since their
+// creation; without it they stay installing
+func WithInstallTime(d time.Duration) Option {
+	return func(a *API) {
+		a.installTime = d
+	}
+}
+
+// New creates a fake Platform API
+func New(opts ...Option) *API {
+	a := &API{
+		mux:         http.NewServeMux(),
+		credentials: make(map[string]aws.Credentials),
+		pageSize:    100,
+		now:         time.Now,
+		latencies:   make(map[Endpoint]time.Duration),
+		failures:    make(map[Endpoint]*Failure),
+		grants:      make(map[string][]platform.AccessGrant),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	a.handle("GET "+basePath+"/live", EndpointLive, a.live)
+	a.handle("GET "+basePath+"/clusters", EndpointListClusters, a.listClusters)
+	a.handle("POST "+basePath+"/clusters", EndpointCreateCluster, a.createCluster)
+	a.handle("GET "+basePath+"/clusters/{cluster}", EndpointGetCluster, a.getCluster)
+	a.handle("POST "+basePath+"/clusters/{cluster}/access_grants", EndpointGrantAccess, a.grantAccess)
+	a.handle("DELETE "+basePath+"/clusters/{cluster}/access_grants/{grant}", EndpointRevokeAccess, a.revokeAccess)
+	return a
+}
+
+// Server is an API listening on a local port
+type Server struct {
+	*API
+	*httptest.Server
+}
+
+// NewServer starts an API on a local port; callers must Close it
+func NewServer(opts ...Option) *Server {
+	api := New(opts...)
+	return &Server{API: api, Server: httptest.NewServer(api)}
+}
+
+// ServeHTTP serves the API. Unknown routes get API Gateway's response for them, a 403
+// "Missing Authentication Token".
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := a.mux.Handler(r); pattern == "" {
+		writeMessage(w, http.StatusForbidden, "Missing Authentication Token")
+		return
+	}
+	a.mux.ServeHTTP(w, r)
+}
+
+// SetLatency delays every response from endpoint by d; 0 removes the delay
+func (a *API) SetLatency(endpoint Endpoint, d time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.latencies[endpoint] = d
+}
+
+// SetFailure makes endpoint fail as described by f; a zero StatusCode removes the failure
+func (a *API) SetFailure(endpoint Endpoint, f Failure) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if f.StatusCode == 0 {
+		delete(a.failures, endpoint)
+		return
+	}
+	a.failures[endpoint] = &f
+}
+
+// AddCluster adds a cluster to the API
+func (a *API) AddCluster(cluster platform.Cluster) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clusters = append(a.clusters, cluster)
+}
+
+// SetClusterState changes the state of a cluster, as the platform would while installing
+// or uninstalling it, and reports whether the cluster exists
+func (a *API) SetClusterState(clusterID, state string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := range a.clusters {
+		if a.clusters[i].ID == clusterID {
+			a.clusters[i].State = state
+			return true
+		}
+	}
+	return false
+}
+
+// Grants returns the unexpired access grants of a cluster
+func (a *API) Grants(clusterID string) []platform.AccessGrant {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var active []platform.AccessGrant
+	for _, grant := range a.grants[clusterID] {
+		if grant.ExpiresAt.After(a.now()) {
+			active = append(active, grant)
+		}
+	}
+	return active
+}
+
+// Requests returns the requests received so far, in order
+func (a *API) Requests() []Request {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]Request(nil), a.requests...)
+}
+
+// handle registers a route. Each request is recorded, delayed, authenticated, and
+// failed if configured, in that order, before h runs.
+func (a *API) handle(pattern string, endpoint Endpoint, h http.HandlerFunc) {
+	a.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		accessKeyID, authErr := a.authenticate(r)
+
+		a.mu.Lock()
+		a.requests = append(a.requests, Request{Endpoint: endpoint, Method: r.Method, Path: r.URL.Path, AccessKeyID: accessKeyID})
+		latency := a.latency(endpoint)
+		failure := a.takeFailure(endpoint)
+		a.mu.Unlock()
+
+		if latency > 0 {
+			select {
+			case <-time.After(latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if authErr != nil {
+			writeMessage(w, http.StatusForbidden, authErr.Error())
+			return
+		}
+		if failure != nil {
+			body := failure.Body
+			if body == "" {
+				body = fmt.Sprintf(`{"message":%q}`, http.StatusText(failure.StatusCode))
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(failure.StatusCode)
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		h(w, r)
+	})
+}
+
+// latency returns the delay for endpoint; a.mu must be held
+func (a *API) latency(endpoint Endpoint) time.Duration {
+	if d, ok := a.latencies[endpoint]; ok {
+		return d
+	}
+	return a.latencies[AllEndpoints]
+}
+
+// takeFailure returns the failure for endpoint, if any, counting it against the
+// failure's Count; a.mu must be held
+func (a *API) takeFailure(endpoint Endpoint) *Failure {
+	for _, key := range []Endpoint{endpoint, AllEndpoints} {
+		f, ok := a.failures[key]
+		if !ok {
+			continue
+		}
+		if f.Count > 0 {
+			f.Count--
+			if f.Count == 0 {
+				delete(a.failures, key)
+			}
+		}
+		return f
+	}
+	return nil
+}
+
+func (a *API) live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (a *API) listClusters(w http.ResponseWriter, r *http.Request) {
+	start := 0
+	if token := r.URL.Query().Get("next_token"); token != "" {
+		var err error
+		if start, err = strconv.Atoi(token); err != nil || start < 0 {
+			writeMessage(w, http.StatusBadRequest, "invalid next_token")
+			return
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.install()
+
+	page := struct {
+		Items     []platform.Cluster `json:"items"`
+		NextToken string             `json:"next_token,omitempty"`
+	}{Items: []platform.Cluster{}}
+	end := min(start+a.pageSize, len(a.clusters))
+	if start < end {
+		page.Items = a.clusters[start:end]
+	}
+	if end < len(a.clusters) {
+		page.NextToken = strconv.Itoa(end)
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (a *API) createCluster(w http.ResponseWriter, r *http.Request) {
+	var req platform.CreateClusterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMessage(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if err := platform.ValidateClusterName(req.Name); err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Region == "" || req.NodePool.InstanceType == "" {
+		writeMessage(w, http.StatusBadRequest, "region and node_pool.instance_type are required")
+		return
+	}
+	if err := platform.ValidateReplicas(req.NodePool.Replicas); err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, cluster := range a.clusters {
+		if cluster.Name == req.Name {
+			writeMessage(w, http.StatusConflict, "cluster "+req.Name+" already exists")
+			return
+		}
+	}
+	a.clusterSeq++
+	id := fmt.Sprintf("cluster-%06d", a.clusterSeq)
+	createdAt := a.now().UTC()
+	issuerURL := req.OIDCIssuerURL
+	if issuerURL == "" {
+		issuerURL = "https://oidc.example.com/" + id
+	}
+	cluster := platform.Cluster{
+		ID:            id,
+		Name:          req.Name,
+		State:         platform.StateInstalling,
+		Region:        req.Region,
+		CreatedAt:     &createdAt,
+		OIDCIssuerURL: issuerURL,
+	}
+	a.clusters = append(a.clusters, cluster)
+	writeJSON(w, http.StatusCreated, cluster)
+}
+
+func (a *API) getCluster(w http.ResponseWriter, r *http.Request) {
+	clusterID := r.PathValue("cluster")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.install()
+
+	for _, cluster := range a.clusters {
+		if cluster.ID == clusterID {
+			writeJSON(w, http.StatusOK, cluster)
+			return
+		}
+	}
+	writeMessage(w, http.StatusNotFound, "cluster "+clusterID+" not found")
+}
+
+func (a *API) grantAccess(w http.ResponseWriter, r *http.Request) {
+	clusterID := r.PathValue("cluster")
+
+	var req platform.GrantAccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMessage(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if req.PrincipalARN == "" {
+		writeMessage(w, http.StatusBadRequest, "principal_arn is required")
+		return
+	}
+	if err := platform.ValidateAccessDuration(time.Duration(req.DurationSeconds) * time.Second); err != nil {
+		writeMessage(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.hasCluster(clusterID) {
+		writeMessage(w, http.StatusNotFound, "cluster "+clusterID+" not found")
+		return
+	}
+	a.grantSeq++
+	grant := platform.AccessGrant{
+		ID:           fmt.Sprintf("ag-%06d", a.grantSeq),
+		ClusterID:    clusterID,
+		PrincipalARN: req.PrincipalARN,
+		ExpiresAt:    a.now().Add(time.Duration(req.DurationSeconds) * time.Second).UTC(),
+	}
+	a.grants[clusterID] = append(a.grants[clusterID], grant)
+	writeJSON(w, http.StatusCreated, grant)
+}
+
+func (a *API) revokeAccess(w http.ResponseWriter, r *http.Request) {
+	clusterID, grantID := r.PathValue("cluster"), r.PathValue("grant")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	grants := a.grants[clusterID]
+	for i, grant := range grants {
+		if grant.ID == grantID {
+			a.grants[clusterID] = append(grants[:i:i], grants[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeMessage(w, http.StatusNotFound, "access grant "+grantID+" not found")
+}
+
+// install moves clusters created more than the install time ago from installing to
+// ready; a.mu must be held
+func (a *API) install() {
+	if a.installTime <= 0 {
+		return
+	}
+	for i := range a.clusters {
+		cluster := &a.clusters[i]
+		if cluster.State == platform.StateInstalling && cluster.CreatedAt != nil && a.now().Sub(*cluster.CreatedAt) >= a.installTime {
+			cluster.State = platform.StateReady
+		}
+	}
+}
+
+// hasCluster reports whether the API knows clusterID; a.mu must be held
+func (a *API) hasCluster(clusterID string) bool {
+	for _, cluster := range a.clusters {
+		if cluster.ID == clusterID {
+			return true
+		}
+	}
+	return false
+}
+
+// writeMessage writes an API Gateway style error body
+func writeMessage(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,123 @@
+package fake
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+const (
+	// signingService is the SigV4 service name for API Gateway
+	signingService = "execute-api"
+
+	// maxClockSkew is how far a request's signing time may be from the server's
+	// clock, as in AWS
+	maxClockSkew = 15 * time.Minute
+
+	amzDateFormat = "20060102T150405Z"
+)
+
+// authorization is a parsed SigV4 Authorization header
+type authorization struct {
+	accessKeyID   string
+	region        string
+	service       string
+	signedHeaders []string
+	signature     string
+}
+
+// parseAuthorization parses a header such as "AWS4-HMAC-SHA256
+// Credential=AKID/20260101/us-east-1/execute-api/aws4_request, SignedHeaders=host;x-amz-date,
+// Signature=abc"
+func parseAuthorization(header string) (authorization, error) {
+	fields, ok := strings.CutPrefix(header, "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return authorization{}, errors.New("Missing Authentication Token")
+	}
+
+	var auth authorization
+	for _, field := range strings.Split(fields, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "Credential":
+			scope := strings.Split(value, "/")
+			if len(scope) != 5 || scope[4] != "aws4_request" {
+				return authorization{}, fmt.Errorf("Authorization header requires a valid Credential, got %q", value)
+			}
+			auth.accessKeyID, auth.region, auth.service = scope[0], scope[2], scope[3]
+		case "SignedHeaders":
+			auth.signedHeaders = strings.Split(value, ";")
+		case "Signature":
+			auth.signature = value
+		}
+	}
+	if auth.accessKeyID == "" || len(auth.signedHeaders) == 0 || auth.signature == "" {
+		return authorization{}, errors.New("Authorization header requires Credential, SignedHeaders, and Signature")
+	}
+	return auth, nil
+}
+
+// authenticate checks the request's SigV4 signature, returning the caller's access
+// key ID. The error is the message API Gateway would return with a 403. The body is
+// read and replaced so handlers can still read it.
+func (a *API) authenticate(r *http.Request) (string, error) {
+	auth, err := parseAuthorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return "", err
+	}
+	if auth.service != signingService {
+		return auth.accessKeyID, fmt.Errorf("Credential should be scoped to correct service: '%s'", signingService)
+	}
+
+	signingTime, err := time.Parse(amzDateFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return auth.accessKeyID, errors.New("Authorization header requires existence of either a 'X-Amz-Date' or a 'Date' header")
+	}
+	if skew := a.now().Sub(signingTime); skew > maxClockSkew || skew < -maxClockSkew {
+		return auth.accessKeyID, errors.New("Signature expired")
+	}
+
+	// Without the secret key the signature cannot be checked, only its presence
+	if len(a.credentials) == 0 {
+		return auth.accessKeyID, nil
+	}
+	creds, ok := a.credentials[auth.accessKeyID]
+	if !ok {
+		return auth.accessKeyID, errors.New("The security token included in the request is invalid.")
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return auth.accessKeyID, fmt.Errorf("failed to read request body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Re-sign a copy holding only the headers the caller signed, and compare
+	signed := r.Clone(r.Context())
+	signed.URL.Scheme = "http"
+	signed.URL.Host = r.Host
+	signed.Header = http.Header{}
+	for _, name := range auth.signedHeaders {
+		if name != "host" && name != "content-length" {
+			signed.Header[http.CanonicalHeaderKey(name)] = r.Header.Values(name)
+		}
+	}
+	payloadHash := fmt.Sprintf("%x", sha256.Sum256(body))
+	if err := v4.NewSigner().SignHTTP(r.Context(), creds, signed, payloadHash, auth.service, auth.region, signingTime); err != nil {
+		return auth.accessKeyID, fmt.Errorf("failed to verify signature: %w", err)
+	}
+
+	expected, err := parseAuthorization(signed.Header.Get("Authorization"))
+	if err != nil || expected.signature != auth.signature {
+		return auth.accessKeyID, errors.New("The request signature we calculated does not match the signature you provided. " +
+			"Check your AWS Secret Access Key and signing method.")
+	}
+	return auth.accessKeyID, nil
+}
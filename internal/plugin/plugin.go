@@ -0,0 +1,142 @@
+// Package plugin discovers and runs rosactl plugins: executables on PATH named
+// rosactl-<name>, which become the `rosactl <name>` command.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Prefix is the file name prefix of plugin executables
+const Prefix = "rosactl-"
+
+// interruptGrace is how long a plugin has to exit after rosactl is interrupted
+// before it is killed
+const interruptGrace = 10 * time.Second
+
+// Plugin is a plugin executable found on PATH
+type Plugin struct {
+	Name string // Command name, the file name without the prefix
+	Path string
+
+	// ShadowedBy is the path of an earlier plugin with the same name, which is the one run
+	ShadowedBy string
+}
+
+// Discover returns the plugins in the directories of pathList, a PATH-style list, in
+// name order. A plugin whose name also appears earlier on the path is returned with
+// ShadowedBy set.
+func Discover(pathList string) []Plugin {
+	var plugins []Plugin
+	first := make(map[string]string)
+	seenDirs := make(map[string]bool)
+
+	for _, dir := range filepath.SplitList(pathList) {
+		if dir == "" || seenDirs[dir] {
+			continue
+		}
+		seenDirs[dir] = true
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := commandName(entry.Name())
+			if !ok {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+
+			plugin := Plugin{Name: name, Path: path}
+			if earlier, ok := first[name]; ok {
+				plugin.ShadowedBy = earlier
+			} else {
+				first[name] = path
+			}
+			plugins = append(plugins, plugin)
+		}
+	}
+
+	sort.SliceStable(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins
+}
+
+// Lookup returns the plugin run for `rosactl <name>`, searching PATH like a shell
+func Lookup(name string) (*Plugin, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return nil, exec.ErrNotFound
+	}
+	path, err := exec.LookPath(Prefix + name)
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{Name: name, Path: path}, nil
+}
+
+// Run runs the plugin with args, connected to the given streams, and with env added
+// to rosactl's environment. When ctx is cancelled the plugin is interrupted and then
+// killed if it does not exit. A plugin that exits non-zero returns an *exec.ExitError.
+func Run(ctx context.Context, p *Plugin, args, env []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	cmd := exec.CommandContext(ctx, p.Path, args...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.Cancel = func() error {
+		// Interrupt is not supported on Windows; the plugin is killed after the grace period
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill()
+		}
+		return nil
+	}
+	cmd.WaitDelay = interruptGrace
+
+	return cmd.Run()
+}
+
+// ExitCode returns the exit code to report for a plugin's error, or 0 if err is
+// not a plugin exit status
+func ExitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() > 0 {
+		return exitErr.ExitCode()
+	}
+	return 0
+}
+
+// commandName returns the command a plugin file name provides
+func commandName(fileName string) (string, bool) {
+	if runtime.GOOS == "windows" {
+		fileName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+	name, ok := strings.CutPrefix(fileName, Prefix)
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// isExecutable reports whether path is a file the current user may run
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return info.Mode().Perm()&0111 != 0
+}
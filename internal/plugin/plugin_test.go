@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeExecutable(t *testing.T, dir, name, script string, mode os.FileMode) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), mode))
+	return path
+}
+
+func skipOnWindows(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	skipOnWindows(t)
+	first, second := t.TempDir(), t.TempDir()
+	billing := writeExecutable(t, first, "rosactl-billing", "", 0755)
+	shadowed := writeExecutable(t, second, "rosactl-billing", "", 0755)
+	sre := writeExecutable(t, second, "rosactl-sre-tools", "", 0755)
+	writeExecutable(t, first, "rosactl-notes", "", 0644)
+	writeExecutable(t, first, "kubectl-foo", "", 0755)
+	writeExecutable(t, first, "rosactl-", "", 0755)
+	require.NoError(t, os.Mkdir(filepath.Join(first, "rosactl-dir"), 0755))
+
+	pathList := strings.Join([]string{first, filepath.Join(first, "missing"), second, first}, string(os.PathListSeparator))
+	assert.Equal(t, []Plugin{
+		{Name: "billing", Path: billing},
+		{Name: "billing", Path: shadowed, ShadowedBy: billing},
+		{Name: "sre-tools", Path: sre},
+	}, Discover(pathList))
+}
+
+func TestLookup(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	path := writeExecutable(t, dir, "rosactl-billing", "", 0755)
+	t.Setenv("PATH", dir)
+
+	p, err := Lookup("billing")
+	require.NoError(t, err)
+	assert.Equal(t, &Plugin{Name: "billing", Path: path}, p)
+
+	_, err = Lookup("missing")
+	assert.Error(t, err)
+
+	_, err = Lookup("../billing")
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	path := writeExecutable(t, dir, "rosactl-echo", `echo "$@" "$ROSACTL_REGION"; read line; echo "$line"; echo oops >&2`, 0755)
+
+	var stdout, stderr bytes.Buffer
+	err := Run(context.Background(), &Plugin{Name: "echo", Path: path}, []string{"--flag", "value"},
+		[]string{"ROSACTL_REGION=us-east-2"}, strings.NewReader("input\n"), &stdout, &stderr)
+	require.NoError(t, err)
+	assert.Equal(t, "--flag value us-east-2\ninput\n", stdout.String())
+	assert.Equal(t, "oops\n", stderr.String())
+	assert.Equal(t, 0, ExitCode(err))
+}
+
+func TestRun_ExitCode(t *testing.T) {
+	skipOnWindows(t)
+	path := writeExecutable(t, t.TempDir(), "rosactl-fail", "exit 3", 0755)
+
+	err := Run(context.Background(), &Plugin{Name: "fail", Path: path}, nil, nil, nil, &bytes.Buffer{}, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Equal(t, 3, ExitCode(err))
+}
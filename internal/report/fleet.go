@@ -0,0 +1,97 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+
+	"github.com/openshift-online/regional-cli/internal/fleet"
+)
+
+// WriteFleetFile writes a fleet rollout report to path: HTML for .html and .htm, JSON
+// for anything else
+func WriteFleetFile(path string, r *fleet.Report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	if FormatForPath(path) == FormatHTML {
+		err = WriteFleetHTML(f, r)
+	} else {
+		err = WriteFleetJSON(f, r)
+	}
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		return fmt.Errorf("failed to write report: %w", closeErr)
+	}
+	return err
+}
+
+// WriteFleetJSON writes a fleet rollout report as indented JSON
+func WriteFleetJSON(w io.Writer, r *fleet.Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// WriteFleetHTML writes a fleet rollout report as a standalone HTML page
+func WriteFleetHTML(w io.Writer, r *fleet.Report) error {
+	if err := fleetHTMLTemplate.Execute(w, newFleetView(r)); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// fleetView is the template data derived from a fleet.Report
+type fleetView struct {
+	*fleet.Report
+	Total     int
+	Succeeded int
+	Failed    int
+	Skipped   int
+}
+
+func newFleetView(r *fleet.Report) *fleetView {
+	return &fleetView{
+		Report:    r,
+		Total:     r.Total(),
+		Succeeded: r.Count(fleet.StatusSucceeded),
+		Failed:    r.Count(fleet.StatusDeployFailed) + r.Count(fleet.StatusVerificationFailed),
+		Skipped:   r.Count(fleet.StatusSkipped),
+	}
+}
+
+var fleetHTMLTemplate = htmltemplate.Must(htmltemplate.New("fleet").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ROSA Fleet Rollout Report</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+code { background: #f4f4f4; }
+</style>
+</head>
+<body>
+<h1>ROSA Fleet Rollout Report</h1>
+<p>Started {{rfc3339 .StartedAt}}, finished {{rfc3339 .FinishedAt}}, in batches of {{.BatchSize}} with up to {{.MaxParallel}} accounts at once.</p>
+<p>{{.Total}} accounts: {{.Succeeded}} succeeded, {{.Failed}} failed, {{.Skipped}} skipped.{{if .Halted}} The rollout was halted.{{end}}</p>
+{{- range .Batches}}
+
+<h2>Batch {{.Number}}: {{.Status}}</h2>
+<table>
+<tr><th>Account</th><th>Account ID</th><th>Region</th><th>Status</th><th>Function ARN</th><th>Details</th></tr>
+{{- range .Accounts}}
+<tr><td>{{.Account.Name}}</td><td>{{.AccountID}}</td><td>{{.Account.Region}}</td><td>{{.Status}}{{if .DeployStatus}} ({{.DeployStatus}}){{end}}</td><td>{{valueOr .FunctionARN "-"}}</td><td>{{.Error}}{{range .Warnings}}<br>⚠ {{.}}{{end}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`))
@@ -0,0 +1,70 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift-online/regional-cli/internal/fleet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFleetReport() *fleet.Report {
+	startedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &fleet.Report{
+		StartedAt:   startedAt,
+		FinishedAt:  startedAt.Add(time.Minute),
+		MaxParallel: 5,
+		BatchSize:   1,
+		Halted:      true,
+		Batches: []fleet.Batch{
+			{Number: 1, Status: fleet.StatusVerificationFailed, Accounts: []fleet.AccountResult{{
+				Account:      fleet.Account{Name: "payments-prod", RoleARN: "arn:aws:iam::111111111111:role/deployer", Region: "us-east-1"},
+				AccountID:    "111111111111",
+				Status:       fleet.StatusVerificationFailed,
+				DeployStatus: "updated",
+				FunctionARN:  "arn:aws:lambda:us-east-1:111111111111:function:rosa-oidc-provisioner",
+				Error:        "ping failed",
+			}}},
+			{Number: 2, Status: fleet.StatusSkipped, Accounts: []fleet.AccountResult{{
+				Account:   fleet.Account{Name: "222222222222", RoleARN: "arn:aws:iam::222222222222:role/deployer", Region: "us-east-1"},
+				AccountID: "222222222222",
+				Status:    fleet.StatusSkipped,
+			}}},
+		},
+	}
+}
+
+func TestWriteFleetHTML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteFleetHTML(&buf, testFleetReport()))
+	out := buf.String()
+
+	assert.Contains(t, out, "<p>2 accounts: 0 succeeded, 1 failed, 1 skipped. The rollout was halted.</p>")
+	assert.Contains(t, out, "<h2>Batch 1: verification_failed</h2>")
+	assert.Contains(t, out, "<td>payments-prod</td><td>111111111111</td><td>us-east-1</td><td>verification_failed (updated)</td>")
+	assert.Contains(t, out, "<td>ping failed</td>")
+	assert.Contains(t, out, "<h2>Batch 2: skipped</h2>")
+}
+
+func TestWriteFleetFile(t *testing.T) {
+	dir := t.TempDir()
+
+	jsonPath := filepath.Join(dir, "fleet.json")
+	require.NoError(t, WriteFleetFile(jsonPath, testFleetReport()))
+	data, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var decoded fleet.Report
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, testFleetReport(), &decoded)
+
+	htmlPath := filepath.Join(dir, "fleet.html")
+	require.NoError(t, WriteFleetFile(htmlPath, testFleetReport()))
+	data, err = os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<title>ROSA Fleet Rollout Report</title>")
+}
@@ -0,0 +1,299 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+)
+
+// Report formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// historyLimit is the number of recent deployments listed in the report
+const historyLimit = 5
+
+// Onboarding is the data behind an account onboarding report
+type Onboarding struct {
+	Result            *deployer.DeploymentResult
+	Manifest          *manifest.Manifest // Optional: adds the deployment history
+	Region            string
+	CLMServiceRoleARN string
+	GeneratedAt       time.Time
+}
+
+// view is the template data derived from an Onboarding
+type view struct {
+	*Onboarding
+	AccountID    string
+	LogGroupURL  string
+	Retention    int32
+	History      []manifest.HistoryEntry
+	NextSteps    []nextStep
+	GeneratedUTC string
+}
+
+// FormatForPath picks the report format from a file extension: .html and .htm are
+// HTML, anything else is Markdown
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return FormatHTML
+	default:
+		return FormatMarkdown
+	}
+}
+
+// WriteFile writes the report to path in the format its extension implies
+func WriteFile(path string, o *Onboarding) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+
+	if FormatForPath(path) == FormatHTML {
+		err = WriteHTML(f, o)
+	} else {
+		err = WriteMarkdown(f, o)
+	}
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		return fmt.Errorf("failed to write report: %w", closeErr)
+	}
+	return err
+}
+
+// WriteMarkdown writes the report as Markdown
+func WriteMarkdown(w io.Writer, o *Onboarding) error {
+	if err := markdownTemplate.Execute(w, newView(o)); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML writes the report as a standalone HTML page
+func WriteHTML(w io.Writer, o *Onboarding) error {
+	if err := htmlTemplate.Execute(w, newView(o)); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+func newView(o *Onboarding) *view {
+	v := &view{
+		Onboarding:   o,
+		GeneratedUTC: o.GeneratedAt.UTC().Format(time.RFC3339),
+	}
+
+	partition := "aws"
+	if parsed, err := arn.Parse(o.Result.FunctionARN); err == nil {
+		v.AccountID = parsed.AccountID
+		partition = parsed.Partition
+	}
+	v.LogGroupURL = logGroupConsoleURL(partition, o.Region, o.Result.LogGroupName)
+
+	v.Retention = o.Result.LogRetentionDays
+	if v.Retention == 0 {
+		v.Retention = deployer.DefaultLogRetentionDays
+	}
+
+	if o.Manifest != nil {
+		history := o.Manifest.History
+		if len(history) > historyLimit {
+			history = history[len(history)-historyLimit:]
+		}
+		for i := len(history) - 1; i >= 0; i-- {
+			v.History = append(v.History, history[i])
+		}
+	}
+
+	v.NextSteps = nextSteps(o)
+	return v
+}
+
+// nextStep is a follow-up action, optionally with the command that performs it
+type nextStep struct {
+	Text    string
+	Command string
+}
+
+// nextSteps lists what the customer's cloud team should do after setup
+func nextSteps(o *Onboarding) []nextStep {
+	health := fmt.Sprintf("rosactl provisioner health --function-arn %s", o.Result.FunctionARN)
+	if o.CLMServiceRoleARN != "" {
+		health += fmt.Sprintf(" --assume-role-arn %s", o.CLMServiceRoleARN)
+	}
+
+	steps := []nextStep{
+		{Text: "Verify the provisioner responds", Command: health},
+	}
+	if o.CLMServiceRoleARN == "" {
+		steps = append(steps, nextStep{
+			Text:    "Allow the ROSA platform to invoke the provisioner",
+			Command: "rosactl setup-account --clm-service-role-arn <role-arn>",
+		})
+	}
+	steps = append(steps,
+		nextStep{Text: fmt.Sprintf("Share the function ARN %s with the ROSA platform team to complete onboarding", o.Result.FunctionARN)},
+		nextStep{Text: "Monitor the provisioner", Command: "rosactl provisioner metrics"},
+	)
+	return steps
+}
+
+// logGroupConsoleURL links to the log group in the CloudWatch console of its partition
+func logGroupConsoleURL(partition, region, logGroupName string) string {
+	domain := "console.aws.amazon.com"
+	switch partition {
+	case "aws-us-gov":
+		domain = "console.amazonaws-us-gov.com"
+	case "aws-cn":
+		domain = "console.amazonaws.cn"
+	}
+
+	// The console fragment expects the log group name URL-encoded twice, with $ for %
+	encoded := strings.ReplaceAll(url.QueryEscape(url.QueryEscape(logGroupName)), "%", "$")
+	return fmt.Sprintf("https://%s.%s/cloudwatch/home?region=%s#logsV2:log-groups/log-group/%s",
+		region, domain, region, encoded)
+}
+
+// valueOr returns fallback for empty values
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+var funcs = map[string]interface{}{
+	"valueOr": valueOr,
+	"rfc3339": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	"inc":     func(i int) int { return i + 1 },
+}
+
+var markdownTemplate = texttemplate.Must(texttemplate.New("markdown").Funcs(funcs).Parse(`# ROSA Account Onboarding Report
+
+Generated {{.GeneratedUTC}} for account {{valueOr .AccountID "unknown"}} in {{.Region}}.
+
+## Identities
+
+| Identity | ARN |
+|----------|-----|
+| Deployed by | {{valueOr .Result.DeployedBy "unknown"}} |
+| Provisioner execution role | {{.Result.ExecutionRole}} |
+| CLM service role (invokes the provisioner) | {{valueOr .CLMServiceRoleARN "not configured"}} |
+
+## Resources
+
+| Type | Action | Identifier |
+|------|--------|------------|
+{{- range .Result.Resources}}
+| {{.Type}} | {{.Action}} | {{.Identifier}} |
+{{- end}}
+
+Function ARN: {{.Result.FunctionARN}}{{if .Result.Version}} (version {{.Result.Version}}){{end}}
+
+Package checksum (SHA-256): {{.Result.PackageChecksum}}
+
+## Policies
+
+| Policy | Type | Attached to | Description |
+|--------|------|-------------|-------------|
+{{- range .Result.Policies}}
+| {{.Name}} | {{.Type}} | {{.AttachedTo}} | {{.Description}} |
+{{- end}}
+
+## Logs
+
+Provisioner logs are written to [{{.Result.LogGroupName}}]({{.LogGroupURL}}) and retained for {{.Retention}} days.
+{{- if .History}}
+
+## Deployment History
+
+| Deployed at | Deployed by | Status | Version |
+|-------------|-------------|--------|---------|
+{{- range .History}}
+| {{rfc3339 .DeployedAt}} | {{valueOr .DeployedBy "-"}} | {{.Status}} | {{valueOr .Version "-"}} |
+{{- end}}
+{{- end}}
+
+## Next Steps
+{{range $i, $step := .NextSteps}}
+{{inc $i}}. {{$step.Text}}{{if $step.Command}}: ` + "`{{$step.Command}}`" + `{{end}}
+{{- end}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>ROSA Account Onboarding Report</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+code { background: #f4f4f4; }
+</style>
+</head>
+<body>
+<h1>ROSA Account Onboarding Report</h1>
+<p>Generated {{.GeneratedUTC}} for account {{valueOr .AccountID "unknown"}} in {{.Region}}.</p>
+
+<h2>Identities</h2>
+<table>
+<tr><th>Identity</th><th>ARN</th></tr>
+<tr><td>Deployed by</td><td>{{valueOr .Result.DeployedBy "unknown"}}</td></tr>
+<tr><td>Provisioner execution role</td><td>{{.Result.ExecutionRole}}</td></tr>
+<tr><td>CLM service role (invokes the provisioner)</td><td>{{valueOr .CLMServiceRoleARN "not configured"}}</td></tr>
+</table>
+
+<h2>Resources</h2>
+<table>
+<tr><th>Type</th><th>Action</th><th>Identifier</th></tr>
+{{- range .Result.Resources}}
+<tr><td>{{.Type}}</td><td>{{.Action}}</td><td>{{.Identifier}}</td></tr>
+{{- end}}
+</table>
+<p>Function ARN: {{.Result.FunctionARN}}{{if .Result.Version}} (version {{.Result.Version}}){{end}}</p>
+<p>Package checksum (SHA-256): {{.Result.PackageChecksum}}</p>
+
+<h2>Policies</h2>
+<table>
+<tr><th>Policy</th><th>Type</th><th>Attached to</th><th>Description</th></tr>
+{{- range .Result.Policies}}
+<tr><td>{{.Name}}</td><td>{{.Type}}</td><td>{{.AttachedTo}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+
+<h2>Logs</h2>
+<p>Provisioner logs are written to <a href="{{.LogGroupURL}}">{{.Result.LogGroupName}}</a> and retained for {{.Retention}} days.</p>
+{{- if .History}}
+
+<h2>Deployment History</h2>
+<table>
+<tr><th>Deployed at</th><th>Deployed by</th><th>Status</th><th>Version</th></tr>
+{{- range .History}}
+<tr><td>{{rfc3339 .DeployedAt}}</td><td>{{valueOr .DeployedBy "-"}}</td><td>{{.Status}}</td><td>{{valueOr .Version "-"}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+
+<h2>Next Steps</h2>
+<ol>
+{{- range .NextSteps}}
+<li>{{.Text}}{{if .Command}}: <code>{{.Command}}</code>{{end}}</li>
+{{- end}}
+</ol>
+</body>
+</html>
+`))
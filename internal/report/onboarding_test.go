@@ -0,0 +1,116 @@
+package report
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/openshift-online/regional-cli/internal/manifest"
+	"github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testOnboarding() *Onboarding {
+	deployedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return &Onboarding{
+		Result: &deployer.DeploymentResult{
+			FunctionARN:     "arn:aws:lambda:us-east-1:123456789012:function:rosa-oidc-provisioner",
+			FunctionName:    "rosa-oidc-provisioner",
+			ExecutionRole:   "arn:aws:iam::123456789012:role/rosa-oidc-provisioner-execution",
+			LogGroupName:    "/aws/lambda/rosa-oidc-provisioner",
+			Status:          "created",
+			PackageChecksum: "abc123",
+			DeployedBy:      "arn:aws:iam::123456789012:user/alice",
+			DeployedAt:      deployedAt,
+			Resources: []deployer.ResourceRecord{
+				{Type: deployer.ResourceTypeExecutionRole, Identifier: "arn:aws:iam::123456789012:role/rosa-oidc-provisioner-execution", Action: deployer.ResourceActionCreated},
+				{Type: deployer.ResourceTypeFunction, Identifier: "arn:aws:lambda:us-east-1:123456789012:function:rosa-oidc-provisioner", Action: deployer.ResourceActionCreated},
+			},
+			Policies: []deployer.AttachedPolicy{
+				{Type: deployer.PolicyTypeInline, Name: "OIDCProvisionerPermissions", AttachedTo: "arn:aws:iam::123456789012:role/rosa-oidc-provisioner-execution", Description: "Manages OIDC providers"},
+			},
+		},
+		Manifest: &manifest.Manifest{
+			History: []manifest.HistoryEntry{
+				{DeployedAt: deployedAt, DeployedBy: "arn:aws:iam::123456789012:user/alice", Status: "created"},
+			},
+		},
+		Region:      "us-east-1",
+		GeneratedAt: deployedAt,
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, testOnboarding()))
+	out := buf.String()
+
+	assert.Contains(t, out, "Generated 2024-05-01T12:00:00Z for account 123456789012 in us-east-1.")
+	assert.Contains(t, out, "| Deployed by | arn:aws:iam::123456789012:user/alice |")
+	assert.Contains(t, out, "| CLM service role (invokes the provisioner) | not configured |")
+	assert.Contains(t, out, "| lambda-function | created | arn:aws:lambda:us-east-1:123456789012:function:rosa-oidc-provisioner |")
+	assert.Contains(t, out, "| OIDCProvisionerPermissions | inline |")
+	assert.Contains(t, out, "[/aws/lambda/rosa-oidc-provisioner](https://us-east-1.console.aws.amazon.com/cloudwatch/home?region=us-east-1#logsV2:log-groups/log-group/$252Faws$252Flambda$252Frosa-oidc-provisioner)")
+	assert.Contains(t, out, "| 2024-05-01T12:00:00Z | arn:aws:iam::123456789012:user/alice | created | - |")
+	assert.Contains(t, out, "1. Verify the provisioner responds: `rosactl provisioner health --function-arn arn:aws:lambda:us-east-1:123456789012:function:rosa-oidc-provisioner`")
+	assert.Contains(t, out, "2. Allow the ROSA platform to invoke the provisioner: `rosactl setup-account --clm-service-role-arn <role-arn>`")
+}
+
+func TestWriteMarkdown_CLMRole(t *testing.T) {
+	onboarding := testOnboarding()
+	onboarding.CLMServiceRoleARN = "arn:aws:iam::111111111111:role/clm"
+	onboarding.Manifest = nil
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteMarkdown(&buf, onboarding))
+	out := buf.String()
+
+	assert.Contains(t, out, "--assume-role-arn arn:aws:iam::111111111111:role/clm`")
+	assert.NotContains(t, out, "Allow the ROSA platform to invoke the provisioner")
+	assert.NotContains(t, out, "Deployment History")
+}
+
+func TestWriteHTML(t *testing.T) {
+	onboarding := testOnboarding()
+	onboarding.Result.DeployedBy = "<script>alert(1)</script>"
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteHTML(&buf, onboarding))
+	out := buf.String()
+
+	assert.Contains(t, out, "<title>ROSA Account Onboarding Report</title>")
+	assert.Contains(t, out, "&lt;script&gt;")
+	assert.NotContains(t, out, "<script>")
+	assert.Contains(t, out, "<code>rosactl provisioner health --function-arn")
+}
+
+func TestLogGroupConsoleURL(t *testing.T) {
+	assert.Equal(t,
+		"https://us-gov-west-1.console.amazonaws-us-gov.com/cloudwatch/home?region=us-gov-west-1#logsV2:log-groups/log-group/$252Faws$252Flambda$252Ffn",
+		logGroupConsoleURL("aws-us-gov", "us-gov-west-1", "/aws/lambda/fn"))
+	assert.Equal(t,
+		"https://cn-north-1.console.amazonaws.cn/cloudwatch/home?region=cn-north-1#logsV2:log-groups/log-group/$252Faws$252Flambda$252Ffn",
+		logGroupConsoleURL("aws-cn", "cn-north-1", "/aws/lambda/fn"))
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.Equal(t, FormatHTML, FormatForPath("report.HTML"))
+	assert.Equal(t, FormatMarkdown, FormatForPath("report.md"))
+
+	htmlPath := filepath.Join(dir, "onboarding.html")
+	require.NoError(t, WriteFile(htmlPath, testOnboarding()))
+	data, err := os.ReadFile(htmlPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<!DOCTYPE html>")
+
+	mdPath := filepath.Join(dir, "onboarding.md")
+	require.NoError(t, WriteFile(mdPath, testOnboarding()))
+	data, err = os.ReadFile(mdPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# ROSA Account Onboarding Report")
+}
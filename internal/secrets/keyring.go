@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	// keyringService is the service name secrets are stored under in the OS keyring
+	keyringService = "rosactl"
+
+	// probeKey is looked up to check that a keyring backend is reachable
+	probeKey = "rosactl-probe"
+)
+
+// KeyringStore stores secrets in the OS keyring (macOS Keychain, Windows Credential
+// Manager, or the Secret Service on Linux)
+type KeyringStore struct {
+	service string
+}
+
+// NewKeyringStore creates a store backed by the OS keyring
+func NewKeyringStore() *KeyringStore {
+	return &KeyringStore{service: keyringService}
+}
+
+// Get returns the secret stored under key
+func (s *KeyringStore) Get(key string) (string, error) {
+	value, err := keyring.Get(s.service, key)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("failed to read %s from OS keyring: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set stores value under key
+func (s *KeyringStore) Set(key, value string) error {
+	if err := keyring.Set(s.service, key, value); err != nil {
+		return fmt.Errorf("failed to write %s to OS keyring: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the secret stored under key
+func (s *KeyringStore) Delete(key string) error {
+	if err := keyring.Delete(s.service, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("failed to delete %s from OS keyring: %w", key, err)
+	}
+	return nil
+}
+
+// Description names the backend
+func (s *KeyringStore) Description() string {
+	return "OS keyring"
+}
+
+// probe returns an error when no keyring backend is reachable
+func (s *KeyringStore) probe() error {
+	_, err := keyring.Get(s.service, probeKey)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// backendEnv selects the secret store backend: keyring or file
+	backendEnv = "ROSACTL_SECRETS_BACKEND"
+
+	BackendKeyring = "keyring"
+	BackendFile    = "file"
+)
+
+// ErrNotFound is returned when no secret is stored under a key
+var ErrNotFound = errors.New("secret not found")
+
+// Store persists secrets such as cached Platform tokens outside the plain-text config file
+type Store interface {
+	// Get returns the secret stored under key, or ErrNotFound
+	Get(key string) (string, error)
+	// Set stores value under key, replacing any existing value
+	Set(key, value string) error
+	// Delete removes the secret stored under key. Deleting a missing key is not an error.
+	Delete(key string) error
+	// Description names the backend for user-facing messages
+	Description() string
+}
+
+// Open returns the OS keyring when one is available and otherwise the encrypted
+// file store at path. ROSACTL_SECRETS_BACKEND=keyring|file forces a backend.
+func Open(path string) (Store, error) {
+	keyringStore := NewKeyringStore()
+
+	switch backend := os.Getenv(backendEnv); backend {
+	case BackendKeyring:
+		if err := keyringStore.probe(); err != nil {
+			return nil, fmt.Errorf("OS keyring is not available: %w", err)
+		}
+		return keyringStore, nil
+	case BackendFile:
+		return NewFileStore(path), nil
+	case "":
+		if keyringStore.probe() == nil {
+			return keyringStore, nil
+		}
+		return NewFileStore(path), nil
+	default:
+		return nil, fmt.Errorf("invalid %s %q (expected %s or %s)", backendEnv, backend, BackendKeyring, BackendFile)
+	}
+}
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func testFileStore(t *testing.T, passphrase string) *FileStore {
+	t.Helper()
+	store := NewFileStore(filepath.Join(t.TempDir(), "secrets.enc"))
+	store.passphrase = func() (string, error) { return passphrase, nil }
+	return store
+}
+
+func TestFileStore_RoundTrip(t *testing.T) {
+	store := testFileStore(t, "correct horse")
+
+	_, err := store.Get("platform_token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set("platform_token", "s3cr3t"))
+	require.NoError(t, store.Set("other", "value"))
+
+	value, err := store.Get("platform_token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	data, err := os.ReadFile(store.path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+
+	info, err := os.Stat(store.path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	require.NoError(t, store.Delete("platform_token"))
+	require.NoError(t, store.Delete("platform_token"))
+	_, err = store.Get("platform_token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	value, err = store.Get("other")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestFileStore_WrongPassphrase(t *testing.T) {
+	store := testFileStore(t, "correct horse")
+	require.NoError(t, store.Set("platform_token", "s3cr3t"))
+
+	store.passphrase = func() (string, error) { return "battery staple", nil }
+	_, err := store.Get("platform_token")
+	assert.ErrorContains(t, err, "failed to decrypt")
+}
+
+func TestFileStore_MissingFileNeedsNoPassphrase(t *testing.T) {
+	t.Setenv(passphraseEnv, "")
+	store := NewFileStore(filepath.Join(t.TempDir(), "secrets.enc"))
+
+	_, err := store.Get("platform_token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	err = store.Set("platform_token", "s3cr3t")
+	assert.ErrorContains(t, err, passphraseEnv)
+}
+
+func TestKeyringStore(t *testing.T) {
+	keyring.MockInit()
+	store := NewKeyringStore()
+
+	_, err := store.Get("platform_token")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, store.Set("platform_token", "s3cr3t"))
+	value, err := store.Get("platform_token")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", value)
+
+	require.NoError(t, store.Delete("platform_token"))
+	require.NoError(t, store.Delete("platform_token"))
+}
+
+func TestOpen(t *testing.T) {
+	keyring.MockInit()
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+
+	t.Setenv(backendEnv, "")
+	store, err := Open(path)
+	require.NoError(t, err)
+	assert.IsType(t, &KeyringStore{}, store)
+
+	t.Setenv(backendEnv, BackendFile)
+	store, err = Open(path)
+	require.NoError(t, err)
+	assert.IsType(t, &FileStore{}, store)
+
+	t.Setenv(backendEnv, "vault")
+	_, err = Open(path)
+	assert.ErrorContains(t, err, "invalid ROSACTL_SECRETS_BACKEND")
+}
@@ -2,9 +2,15 @@ package validator
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
@@ -14,90 +20,264 @@ type STSAPI interface {
 		optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
 }
 
+// OrganizationsAPI defines the AWS Organizations operations needed for validation
+type OrganizationsAPI interface {
+	DescribeOrganization(ctx context.Context, params *organizations.DescribeOrganizationInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error)
+	DescribeAccount(ctx context.Context, params *organizations.DescribeAccountInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeAccountOutput, error)
+}
+
 // AWSValidator validates AWS credentials and configuration
 type AWSValidator struct {
-	stsClient STSAPI
-	region    string
+	stsClient   STSAPI
+	orgClient   OrganizationsAPI
+	optInClient RegionOptInAPI
+	region      string
+	allowRoot   bool
+}
+
+// AWSValidatorOption configures optional AWSValidator behavior
+type AWSValidatorOption func(*AWSValidator)
+
+// WithAllowRoot downgrades root account usage from a failure to a warning
+func WithAllowRoot(allow bool) AWSValidatorOption {
+	return func(v *AWSValidator) {
+		v.allowRoot = allow
+	}
+}
+
+// WithOrganizationsClient enables organization membership and account state checks
+func WithOrganizationsClient(orgClient OrganizationsAPI) AWSValidatorOption {
+	return func(v *AWSValidator) {
+		v.orgClient = orgClient
+	}
+}
+
+// WithRegionOptInChecker enables checking whether an opt-in region is enabled for the account
+func WithRegionOptInChecker(optInClient RegionOptInAPI) AWSValidatorOption {
+	return func(v *AWSValidator) {
+		v.optInClient = optInClient
+	}
 }
 
 // NewAWSValidator creates a new AWS validator
-func NewAWSValidator(stsClient STSAPI, region string) *AWSValidator {
-	return &AWSValidator{
+func NewAWSValidator(stsClient STSAPI, region string, opts ...AWSValidatorOption) *AWSValidator {
+	v := &AWSValidator{
 		stsClient: stsClient,
 		region:    region,
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
-// ValidationResult holds the result of AWS validation
-type ValidationResult struct {
-	Valid         bool
-	AccountID     string
-	UserARN       string
-	Region        string
-	ErrorMessage  string
+// AWSResult describes the caller's AWS account and lists the checks AWS validation
+// ran, in order. Whether validation passed, and why not, is reported by the checks.
+type AWSResult struct {
+	AccountID           string `json:"account_id,omitempty"`
+	UserARN             string `json:"user_arn,omitempty"`
+	Region              string `json:"region,omitempty"`
+	IsRoot              bool   `json:"is_root"`
+	OrganizationID      string `json:"organization_id,omitempty"`
+	ManagementAccountID string `json:"management_account_id,omitempty"`
+	IsManagementAccount bool   `json:"is_management_account"`
+	AccountState        string `json:"account_state,omitempty"`
+
+	Checks Checks `json:"-"`
 }
 
-// Validate validates AWS credentials and returns account information
-func (v *AWSValidator) Validate(ctx context.Context) (*ValidationResult, error) {
+// Validate validates AWS credentials and returns account information. The returned
+// error is set when a check failed.
+func (v *AWSValidator) Validate(ctx context.Context) (*AWSResult, error) {
+	result := &AWSResult{}
+
 	// Validate credentials by calling GetCallerIdentity
+	start := time.Now()
 	output, err := v.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	if err != nil {
-		return &ValidationResult{
-			Valid:        false,
-			ErrorMessage: fmt.Sprintf("Failed to validate AWS credentials: %v", err),
-		}, err
+		detail := fmt.Sprintf("Failed to validate AWS credentials: %v", err)
+		remediation := RemediationAWSCredentials
+		// STS rejects otherwise valid credentials in a region the account has not enabled
+		if v.regionNotEnabled(ctx) {
+			result.Region = v.region
+			detail = optInErrorMessage(v.region)
+			remediation = RemediationAWSRegionOptIn
+		}
+		result.Checks = Checks{newCheck(CheckAWSCredentials, CheckFailed, start, detail, remediation)}
+		return result, err
 	}
+	result.Checks = Checks{newCheck(CheckAWSCredentials, CheckPassed, start, aws.ToString(output.Arn), "")}
 
 	// Validate region
+	start = time.Now()
 	if v.region == "" {
-		return &ValidationResult{
-			Valid:        false,
-			ErrorMessage: "AWS region is not configured",
-		}, fmt.Errorf("region not configured")
+		result.Checks = append(result.Checks,
+			newCheck(CheckAWSRegion, CheckFailed, start, "AWS region is not configured", RemediationAWSRegionMissing))
+		return result, fmt.Errorf("region not configured")
 	}
+	result.Region = v.region
 
 	// Check if region is in supported list
 	if !isSupportedRegion(v.region) {
-		return &ValidationResult{
-			Valid:        false,
-			Region:       v.region,
-			ErrorMessage: fmt.Sprintf("AWS region '%s' is not supported", v.region),
-		}, fmt.Errorf("unsupported region: %s", v.region)
-	}
-
-	return &ValidationResult{
-		Valid:     true,
-		AccountID: aws.ToString(output.Account),
-		UserARN:   aws.ToString(output.Arn),
-		Region:    v.region,
-	}, nil
+		result.Checks = append(result.Checks, newCheck(CheckAWSRegion, CheckFailed, start,
+			fmt.Sprintf("AWS region '%s' is not supported by ROSA regional HCP", v.region), RemediationAWSRegionSupport))
+		return result, fmt.Errorf("unsupported region: %s", v.region)
+	}
+
+	result.AccountID = aws.ToString(output.Account)
+	result.UserARN = aws.ToString(output.Arn)
+
+	// Opt-in regions are supported but must also be enabled in this account
+	if err := v.checkRegionOptIn(ctx, result, start); err != nil {
+		return result, err
+	}
+
+	// Root credentials violate ROSA prerequisites; refuse unless explicitly allowed
+	start = time.Now()
+	if isRootARN(result.UserARN) {
+		result.IsRoot = true
+		if !v.allowRoot {
+			result.Checks = append(result.Checks, newCheck(CheckAWSRootUser, CheckFailed, start,
+				"AWS root account credentials detected; use an IAM role or IAM user instead "+
+					"(or pass --allow-root to continue anyway)", RemediationAWSRootUser))
+			return result, fmt.Errorf("root account credentials are not allowed")
+		}
+		result.Checks = append(result.Checks, newCheck(CheckAWSRootUser, CheckWarning, start,
+			"Using AWS root account credentials is discouraged; switch to an IAM role for ROSA operations", RemediationAWSRootUser))
+	} else {
+		result.Checks = append(result.Checks, newCheck(CheckAWSRootUser, CheckPassed, start, "", ""))
+	}
+
+	if v.orgClient == nil {
+		result.Checks = append(result.Checks, CheckResult{Name: CheckAWSAccount, Status: CheckSkipped})
+		return result, nil
+	}
+
+	start = time.Now()
+	warnings, err := v.checkOrganization(ctx, result)
+	result.Checks = append(result.Checks, accountCheck(result, warnings, start, err))
+
+	return result, err
+}
+
+// checkRegionOptIn records the region check, failing when an opt-in region is not
+// enabled for the account. A failed opt-in lookup only produces a warning.
+func (v *AWSValidator) checkRegionOptIn(ctx context.Context, result *AWSResult, start time.Time) error {
+	if !isOptInRegion(v.region) || v.optInClient == nil {
+		result.Checks = append(result.Checks, newCheck(CheckAWSRegion, CheckPassed, start, v.region, ""))
+		return nil
+	}
+
+	status, err := v.optInClient.RegionOptInStatus(ctx, v.region)
+	switch {
+	case err != nil:
+		warning := fmt.Sprintf("Could not determine whether opt-in region '%s' is enabled: %v", v.region, err)
+		result.Checks = append(result.Checks, newCheck(CheckAWSRegion, CheckWarning, start, warning, RemediationAWSRegionOptIn))
+	case status == RegionNotOptedIn:
+		result.Checks = append(result.Checks, newCheck(CheckAWSRegion, CheckFailed, start, optInErrorMessage(v.region), RemediationAWSRegionOptIn))
+		return fmt.Errorf("region not enabled: %s", v.region)
+	default:
+		result.Checks = append(result.Checks, newCheck(CheckAWSRegion, CheckPassed, start, v.region+" ("+status+")", ""))
+	}
+	return nil
+}
+
+// regionNotEnabled reports whether the configured region is a known opt-in region
+// that the account has not enabled. Lookup failures are treated as enabled.
+func (v *AWSValidator) regionNotEnabled(ctx context.Context) bool {
+	if !isOptInRegion(v.region) || v.optInClient == nil {
+		return false
+	}
+	status, err := v.optInClient.RegionOptInStatus(ctx, v.region)
+	return err == nil && status == RegionNotOptedIn
+}
+
+// optInErrorMessage explains how to enable an opt-in region
+func optInErrorMessage(region string) string {
+	return fmt.Sprintf("AWS region '%s' is an opt-in region that is not enabled in this account; "+
+		"enable it under Account > AWS Regions in the AWS console or with "+
+		"'aws account enable-region --region-name %s', then retry once the region status is ENABLED", region, region)
+}
+
+// accountCheck summarizes the organization and account state check from the
+// warnings and error checkOrganization returned
+func accountCheck(result *AWSResult, warnings []string, start time.Time, err error) CheckResult {
+	if err != nil {
+		return newCheck(CheckAWSAccount, CheckFailed, start,
+			fmt.Sprintf("AWS account %s is %s and cannot host clusters", result.AccountID, result.AccountState),
+			RemediationAWSAccountInactive)
+	}
+
+	if len(warnings) == 0 {
+		details := "account " + result.AccountID
+		if result.OrganizationID != "" {
+			details += " in organization " + result.OrganizationID
+		}
+		return newCheck(CheckAWSAccount, CheckPassed, start, details, "")
+	}
+
+	remediation := RemediationAWSOrganizations
+	if result.IsManagementAccount {
+		remediation = RemediationAWSManagement
+	}
+	return newCheck(CheckAWSAccount, CheckWarning, start, strings.Join(warnings, "; "), remediation)
 }
 
-// isSupportedRegion checks if the region is in the supported list
-func isSupportedRegion(region string) bool {
-	supportedRegions := []string{
-		"us-east-1",
-		"us-east-2",
-		"us-west-1",
-		"us-west-2",
-		"eu-west-1",
-		"eu-west-2",
-		"eu-west-3",
-		"eu-central-1",
-		"eu-north-1",
-		"ap-southeast-1",
-		"ap-southeast-2",
-		"ap-northeast-1",
-		"ap-northeast-2",
-		"ap-south-1",
-		"sa-east-1",
-		"ca-central-1",
-	}
-
-	for _, supported := range supportedRegions {
-		if region == supported {
-			return true
+// checkOrganization records organization membership and rejects accounts that are not
+// active, returning warnings about the account. Organizations calls are best-effort:
+// member accounts usually cannot call DescribeAccount, and standalone accounts have no
+// organization at all.
+func (v *AWSValidator) checkOrganization(ctx context.Context, result *AWSResult) ([]string, error) {
+	orgOutput, err := v.orgClient.DescribeOrganization(ctx, &organizations.DescribeOrganizationInput{})
+	if err != nil {
+		var notInUseErr *orgTypes.AWSOrganizationsNotInUseException
+		if !errors.As(err, &notInUseErr) {
+			return []string{fmt.Sprintf("Unable to determine organization membership: %v", err)}, nil
 		}
+		return nil, nil
+	}
+
+	if orgOutput.Organization != nil {
+		result.OrganizationID = aws.ToString(orgOutput.Organization.Id)
+		result.ManagementAccountID = aws.ToString(orgOutput.Organization.MasterAccountId)
+	}
+
+	var warnings []string
+	if result.ManagementAccountID != "" && result.ManagementAccountID == result.AccountID {
+		result.IsManagementAccount = true
+		warnings = append(warnings,
+			"This account is the organization management account; hosting clusters here is discouraged")
+	}
+
+	accountOutput, err := v.orgClient.DescribeAccount(ctx, &organizations.DescribeAccountInput{
+		AccountId: aws.String(result.AccountID),
+	})
+	if err != nil || accountOutput.Account == nil {
+		// DescribeAccount is only permitted from the management or delegated admin account
+		return warnings, nil
+	}
+
+	result.AccountState = string(accountOutput.Account.State)
+	if result.AccountState == "" {
+		result.AccountState = string(accountOutput.Account.Status)
+	}
+
+	switch result.AccountState {
+	case string(orgTypes.AccountStateSuspended), string(orgTypes.AccountStatePendingClosure),
+		string(orgTypes.AccountStateClosed):
+		return warnings, fmt.Errorf("account %s is %s", result.AccountID, result.AccountState)
+	}
+
+	return warnings, nil
+}
+
+// isRootARN reports whether the caller ARN identifies the account root user
+func isRootARN(callerARN string) bool {
+	parsed, err := arn.Parse(callerARN)
+	if err != nil {
+		return false
 	}
-	return false
+	return parsed.Service == "iam" && parsed.Resource == "root"
 }
@@ -6,6 +6,8 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgTypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,6 +26,29 @@ func (m *mockSTSClient) GetCallerIdentity(ctx context.Context, params *sts.GetCa
 	return &sts.GetCallerIdentityOutput{}, nil
 }
 
+type mockOrganizationsClient struct {
+	describeOrganizationFunc func(ctx context.Context, params *organizations.DescribeOrganizationInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error)
+	describeAccountFunc func(ctx context.Context, params *organizations.DescribeAccountInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeAccountOutput, error)
+}
+
+func (m *mockOrganizationsClient) DescribeOrganization(ctx context.Context, params *organizations.DescribeOrganizationInput,
+	optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error) {
+	if m.describeOrganizationFunc != nil {
+		return m.describeOrganizationFunc(ctx, params, optFns...)
+	}
+	return &organizations.DescribeOrganizationOutput{}, nil
+}
+
+func (m *mockOrganizationsClient) DescribeAccount(ctx context.Context, params *organizations.DescribeAccountInput,
+	optFns ...func(*organizations.Options)) (*organizations.DescribeAccountOutput, error) {
+	if m.describeAccountFunc != nil {
+		return m.describeAccountFunc(ctx, params, optFns...)
+	}
+	return &organizations.DescribeAccountOutput{}, nil
+}
+
 func TestValidate_Success(t *testing.T) {
 	ctx := context.Background()
 	expectedAccountID := "123456789012"
@@ -43,11 +68,34 @@ func TestValidate_Success(t *testing.T) {
 	result, err := validator.Validate(ctx)
 
 	require.NoError(t, err)
-	assert.True(t, result.Valid)
+	assert.NotEqual(t, CheckFailed, result.Checks.Status())
 	assert.Equal(t, expectedAccountID, result.AccountID)
 	assert.Equal(t, expectedUserARN, result.UserARN)
 	assert.Equal(t, "us-east-1", result.Region)
-	assert.Empty(t, result.ErrorMessage)
+	assert.Equal(t, CheckPassed, result.Checks.Status())
+
+	require.Len(t, result.Checks, 4)
+	assert.Equal(t, []string{CheckAWSCredentials, CheckAWSRegion, CheckAWSRootUser, CheckAWSAccount}, checkNames(result.Checks))
+	assert.Equal(t, CheckPassed, result.Checks[0].Status)
+	assert.Equal(t, expectedUserARN, result.Checks[0].Detail)
+	assert.Equal(t, CheckSkipped, result.Checks[3].Status, "no Organizations client")
+}
+
+// checkNames returns the names of checks, in order
+func checkNames(checks []CheckResult) []string {
+	names := make([]string, 0, len(checks))
+	for _, check := range checks {
+		names = append(names, check.Name)
+	}
+	return names
+}
+
+// failure returns the first failed check, failing the test if none failed
+func failure(t *testing.T, checks Checks) CheckResult {
+	t.Helper()
+	check, ok := checks.Failure()
+	require.True(t, ok, "no check failed")
+	return check
 }
 
 func TestValidate_InvalidCredentials(t *testing.T) {
@@ -64,8 +112,12 @@ func TestValidate_InvalidCredentials(t *testing.T) {
 	result, err := validator.Validate(ctx)
 
 	assert.Error(t, err)
-	assert.False(t, result.Valid)
-	assert.Contains(t, result.ErrorMessage, "Failed to validate AWS credentials")
+	assert.Equal(t, CheckFailed, result.Checks.Status())
+	assert.Contains(t, failure(t, result.Checks).Detail, "Failed to validate AWS credentials")
+	assert.Equal(t, RemediationAWSCredentials, failure(t, result.Checks).Remediation)
+	require.Len(t, result.Checks, 1)
+	assert.Equal(t, CheckFailed, result.Checks[0].Status)
+	assert.Equal(t, RemediationAWSCredentials, result.Checks[0].Remediation)
 }
 
 func TestValidate_NoRegion(t *testing.T) {
@@ -85,8 +137,10 @@ func TestValidate_NoRegion(t *testing.T) {
 	result, err := validator.Validate(ctx)
 
 	assert.Error(t, err)
-	assert.False(t, result.Valid)
-	assert.Contains(t, result.ErrorMessage, "AWS region is not configured")
+	assert.Equal(t, CheckFailed, result.Checks.Status())
+	assert.Contains(t, failure(t, result.Checks).Detail, "AWS region is not configured")
+	assert.Equal(t, RemediationAWSRegionMissing, failure(t, result.Checks).Remediation)
+	assert.Equal(t, []string{CheckAWSCredentials, CheckAWSRegion}, checkNames(result.Checks))
 }
 
 func TestValidate_UnsupportedRegion(t *testing.T) {
@@ -106,8 +160,9 @@ func TestValidate_UnsupportedRegion(t *testing.T) {
 	result, err := validator.Validate(ctx)
 
 	assert.Error(t, err)
-	assert.False(t, result.Valid)
-	assert.Contains(t, result.ErrorMessage, "not supported")
+	assert.Equal(t, CheckFailed, result.Checks.Status())
+	assert.Contains(t, failure(t, result.Checks).Detail, "not supported")
+	assert.Equal(t, RemediationAWSRegionSupport, failure(t, result.Checks).Remediation)
 }
 
 func TestIsSupportedRegion(t *testing.T) {
@@ -119,6 +174,11 @@ func TestIsSupportedRegion(t *testing.T) {
 		{"us-west-2", true},
 		{"eu-west-1", true},
 		{"ap-southeast-1", true},
+		{"eu-south-1", true},
+		{"me-central-1", true},
+		{"ap-east-1", true},
+		{"af-south-1", true},
+		{"il-central-1", false},
 		{"unsupported-region", false},
 		{"us-east-3", false},
 		{"", false},
@@ -131,3 +191,164 @@ func TestIsSupportedRegion(t *testing.T) {
 		})
 	}
 }
+
+func TestValidate_RootAccount(t *testing.T) {
+	ctx := context.Background()
+	rootARN := "arn:aws:iam::123456789012:root"
+
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput,
+			optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String(rootARN),
+			}, nil
+		},
+	}
+
+	t.Run("denied by default", func(t *testing.T) {
+		validator := NewAWSValidator(mockSTS, "us-east-1")
+		result, err := validator.Validate(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, CheckFailed, result.Checks.Status())
+		assert.True(t, result.IsRoot)
+		assert.Contains(t, failure(t, result.Checks).Detail, "root account credentials detected")
+	})
+
+	t.Run("warns when allowed", func(t *testing.T) {
+		validator := NewAWSValidator(mockSTS, "us-east-1", WithAllowRoot(true))
+		result, err := validator.Validate(ctx)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, CheckFailed, result.Checks.Status())
+		assert.True(t, result.IsRoot)
+		assert.Len(t, result.Checks.Warnings(), 1)
+	})
+}
+
+func TestIsRootARN(t *testing.T) {
+	tests := []struct {
+		arn      string
+		expected bool
+	}{
+		{"arn:aws:iam::123456789012:root", true},
+		{"arn:aws-us-gov:iam::123456789012:root", true},
+		{"arn:aws:iam::123456789012:user/test-user", false},
+		{"arn:aws:sts::123456789012:assumed-role/admin/session", false},
+		{"not-an-arn", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arn, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isRootARN(tt.arn))
+		})
+	}
+}
+
+func TestValidate_Organization(t *testing.T) {
+	ctx := context.Background()
+	accountID := "123456789012"
+
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput,
+			optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{
+				Account: aws.String(accountID),
+				Arn:     aws.String("arn:aws:iam::123456789012:user/test-user"),
+			}, nil
+		},
+	}
+
+	describeOrg := func(managementAccountID string) func(ctx context.Context, params *organizations.DescribeOrganizationInput,
+		optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error) {
+		return func(ctx context.Context, params *organizations.DescribeOrganizationInput,
+			optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error) {
+			return &organizations.DescribeOrganizationOutput{
+				Organization: &orgTypes.Organization{
+					Id:              aws.String("o-abc123"),
+					MasterAccountId: aws.String(managementAccountID),
+				},
+			}, nil
+		}
+	}
+
+	t.Run("member account", func(t *testing.T) {
+		mockOrg := &mockOrganizationsClient{
+			describeOrganizationFunc: describeOrg("999999999999"),
+			describeAccountFunc: func(ctx context.Context, params *organizations.DescribeAccountInput,
+				optFns ...func(*organizations.Options)) (*organizations.DescribeAccountOutput, error) {
+				return nil, &orgTypes.AccessDeniedException{}
+			},
+		}
+
+		validator := NewAWSValidator(mockSTS, "us-east-1", WithOrganizationsClient(mockOrg))
+		result, err := validator.Validate(ctx)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, CheckFailed, result.Checks.Status())
+		assert.Equal(t, "o-abc123", result.OrganizationID)
+		assert.False(t, result.IsManagementAccount)
+		assert.Empty(t, result.AccountState)
+		assert.Empty(t, result.Checks.Warnings())
+	})
+
+	t.Run("management account", func(t *testing.T) {
+		mockOrg := &mockOrganizationsClient{
+			describeOrganizationFunc: describeOrg(accountID),
+			describeAccountFunc: func(ctx context.Context, params *organizations.DescribeAccountInput,
+				optFns ...func(*organizations.Options)) (*organizations.DescribeAccountOutput, error) {
+				assert.Equal(t, accountID, *params.AccountId)
+				return &organizations.DescribeAccountOutput{
+					Account: &orgTypes.Account{State: orgTypes.AccountStateActive},
+				}, nil
+			},
+		}
+
+		validator := NewAWSValidator(mockSTS, "us-east-1", WithOrganizationsClient(mockOrg))
+		result, err := validator.Validate(ctx)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, CheckFailed, result.Checks.Status())
+		assert.True(t, result.IsManagementAccount)
+		assert.Equal(t, "ACTIVE", result.AccountState)
+		assert.Len(t, result.Checks.Warnings(), 1)
+	})
+
+	t.Run("suspended account", func(t *testing.T) {
+		mockOrg := &mockOrganizationsClient{
+			describeOrganizationFunc: describeOrg(accountID),
+			describeAccountFunc: func(ctx context.Context, params *organizations.DescribeAccountInput,
+				optFns ...func(*organizations.Options)) (*organizations.DescribeAccountOutput, error) {
+				return &organizations.DescribeAccountOutput{
+					Account: &orgTypes.Account{State: orgTypes.AccountStateSuspended},
+				}, nil
+			},
+		}
+
+		validator := NewAWSValidator(mockSTS, "us-east-1", WithOrganizationsClient(mockOrg))
+		result, err := validator.Validate(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, CheckFailed, result.Checks.Status())
+		assert.Equal(t, "SUSPENDED", result.AccountState)
+		assert.Contains(t, failure(t, result.Checks).Detail, "SUSPENDED")
+	})
+
+	t.Run("standalone account", func(t *testing.T) {
+		mockOrg := &mockOrganizationsClient{
+			describeOrganizationFunc: func(ctx context.Context, params *organizations.DescribeOrganizationInput,
+				optFns ...func(*organizations.Options)) (*organizations.DescribeOrganizationOutput, error) {
+				return nil, &orgTypes.AWSOrganizationsNotInUseException{}
+			},
+		}
+
+		validator := NewAWSValidator(mockSTS, "us-east-1", WithOrganizationsClient(mockOrg))
+		result, err := validator.Validate(ctx)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, CheckFailed, result.Checks.Status())
+		assert.Empty(t, result.OrganizationID)
+		assert.Empty(t, result.Checks.Warnings())
+	})
+}
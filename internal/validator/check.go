@@ -0,0 +1,223 @@
+package validator
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// CheckStatus is the outcome of a single preflight check
+type CheckStatus string
+
+const (
+	CheckPassed  CheckStatus = "pass"
+	CheckWarning CheckStatus = "warn"
+	CheckFailed  CheckStatus = "fail"
+	CheckSkipped CheckStatus = "skip"
+)
+
+// Check names reported by the validators. Plugin checks are named "plugin:<name>" and
+// validation hook checks "hook:<name>".
+const (
+	CheckAWSCredentials = "aws-credentials"
+	CheckAWSRegion      = "aws-region"
+	CheckAWSRootUser    = "aws-root-user"
+	CheckAWSAccount     = "aws-account"
+	CheckPlatformAPI    = "platform-api"
+	CheckPlatformAccess = "platform-access"
+	CheckProxy          = "proxy"
+	checkPluginPrefix   = "plugin:"
+	checkHookPrefix     = "hook:"
+)
+
+// Remediation keys identify how to fix a failed or warning check. They are stable
+// identifiers that onboarding automation can map to runbooks.
+const (
+	RemediationAWSCredentials      = "configure-aws-credentials"
+	RemediationAWSRegionMissing    = "set-aws-region"
+	RemediationAWSRegionSupport    = "use-supported-region"
+	RemediationAWSRegionOptIn      = "enable-opt-in-region"
+	RemediationAWSRootUser         = "use-iam-principal"
+	RemediationAWSAccountInactive  = "reactivate-aws-account"
+	RemediationAWSManagement       = "use-member-account"
+	RemediationAWSOrganizations    = "grant-organizations-read"
+	RemediationPlatformURL         = "set-platform-api-url"
+	RemediationPlatformUnreachable = "check-platform-api-connectivity"
+	RemediationPlatformAuth        = "check-platform-api-access"
+	RemediationPlatformStatus      = "check-platform-api-status"
+	RemediationPlatformRegion      = "use-regional-platform-api-url"
+	RemediationPlatformVPCEndpoint = "check-vpc-endpoint-policy"
+	RemediationPlugin              = "fix-validator-plugin"
+	RemediationHook                = "resolve-validation-hook"
+	RemediationProxy               = "check-proxy-connectivity"
+)
+
+// CheckResult is the outcome of one preflight check. Every validator reports its
+// results as checks so they can be rendered, serialized, and aggregated the same way.
+type CheckResult struct {
+	Name        string
+	Status      CheckStatus
+	Detail      string
+	Remediation string
+	Latency     time.Duration
+}
+
+// checkResultJSON is the wire form of CheckResult; latency is reported in milliseconds
+type checkResultJSON struct {
+	Name        string      `json:"name"`
+	Status      CheckStatus `json:"status"`
+	LatencyMS   int64       `json:"latency_ms"`
+	Details     string      `json:"details,omitempty"`
+	Remediation string      `json:"remediation,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler
+func (c CheckResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(checkResultJSON{
+		Name:        c.Name,
+		Status:      c.Status,
+		LatencyMS:   c.Latency.Milliseconds(),
+		Details:     c.Detail,
+		Remediation: c.Remediation,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (c *CheckResult) UnmarshalJSON(data []byte) error {
+	var wire checkResultJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*c = CheckResult{
+		Name:        wire.Name,
+		Status:      wire.Status,
+		Detail:      wire.Details,
+		Remediation: wire.Remediation,
+		Latency:     time.Duration(wire.LatencyMS) * time.Millisecond,
+	}
+	return nil
+}
+
+// Title is the check's human-readable name
+func (c CheckResult) Title() string {
+	if plugin, ok := strings.CutPrefix(c.Name, checkPluginPrefix); ok {
+		return "Plugin " + plugin
+	}
+	if hook, ok := strings.CutPrefix(c.Name, checkHookPrefix); ok {
+		return "Hook " + hook
+	}
+	if title, ok := checkTitles[c.Name]; ok {
+		return title
+	}
+	return c.Name
+}
+
+// checkTitles are the human-readable names of the built-in checks
+var checkTitles = map[string]string{
+	CheckProxy:          "Proxy",
+	CheckAWSCredentials: "AWS credentials",
+	CheckAWSRegion:      "AWS region",
+	CheckAWSRootUser:    "AWS principal",
+	CheckAWSAccount:     "AWS account",
+	CheckPlatformAPI:    "Platform API",
+	CheckPlatformAccess: "Platform API access",
+}
+
+// Checks is an ordered list of check results, from one validator or a whole run
+type Checks []CheckResult
+
+// Status is fail when any check failed, warn when any check warned, and pass otherwise
+func (c Checks) Status() CheckStatus {
+	status := CheckPassed
+	for _, check := range c {
+		switch check.Status {
+		case CheckFailed:
+			return CheckFailed
+		case CheckWarning:
+			status = CheckWarning
+		}
+	}
+	return status
+}
+
+// Failure returns the first failed check
+func (c Checks) Failure() (CheckResult, bool) {
+	for _, check := range c {
+		if check.Status == CheckFailed {
+			return check, true
+		}
+	}
+	return CheckResult{}, false
+}
+
+// Warnings returns the checks that warned
+func (c Checks) Warnings() Checks {
+	var warnings Checks
+	for _, check := range c {
+		if check.Status == CheckWarning {
+			warnings = append(warnings, check)
+		}
+	}
+	return warnings
+}
+
+// WithoutPlugins returns the checks that did not come from validator plugins
+func (c Checks) WithoutPlugins() Checks {
+	var checks Checks
+	for _, check := range c {
+		if !strings.HasPrefix(check.Name, checkPluginPrefix) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// PluginCheckName returns the check name for a validator plugin
+func PluginCheckName(plugin string) string {
+	return checkPluginPrefix + plugin
+}
+
+// HookCheckName returns the check name for a validation hook
+func HookCheckName(hook string) string {
+	return checkHookPrefix + hook
+}
+
+// newCheck builds a check result, measuring latency from start
+func newCheck(name string, status CheckStatus, start time.Time, detail, remediation string) CheckResult {
+	return CheckResult{
+		Name:        name,
+		Status:      status,
+		Detail:      detail,
+		Remediation: remediation,
+		Latency:     time.Since(start),
+	}
+}
+
+// Check converts a plugin result into a check
+func (r PluginResult) Check() CheckResult {
+	check := CheckResult{
+		Name:    PluginCheckName(r.Name),
+		Status:  CheckPassed,
+		Detail:  r.Message,
+		Latency: r.Latency,
+	}
+	switch {
+	case !r.Valid:
+		check.Status = CheckFailed
+		check.Remediation = RemediationPlugin
+	case len(r.Warnings) > 0:
+		check.Status = CheckWarning
+		check.Detail = strings.Join(r.Warnings, "; ")
+	}
+	return check
+}
+
+// hookCheck converts a validation hook's result into a check
+func (r PluginResult) hookCheck() CheckResult {
+	check := r.Check()
+	check.Name = HookCheckName(r.Name)
+	if check.Status == CheckFailed {
+		check.Remediation = RemediationHook
+	}
+	return check
+}
@@ -0,0 +1,102 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckResult_JSON(t *testing.T) {
+	check := CheckResult{
+		Name:        CheckPlatformAPI,
+		Status:      CheckFailed,
+		Detail:      "Platform API returned status 403",
+		Remediation: RemediationPlatformAuth,
+		Latency:     143 * time.Millisecond,
+	}
+
+	data, err := json.Marshal(check)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "platform-api", "status": "fail", "latency_ms": 143,
+		"details": "Platform API returned status 403", "remediation": "check-platform-api-access"}`, string(data))
+
+	var decoded CheckResult
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, check, decoded)
+
+	data, err = json.Marshal(CheckResult{Name: CheckAWSAccount, Status: CheckSkipped})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "aws-account", "status": "skip", "latency_ms": 0}`, string(data))
+}
+
+func TestCheckResult_Title(t *testing.T) {
+	assert.Equal(t, "AWS credentials", CheckResult{Name: CheckAWSCredentials}.Title())
+	assert.Equal(t, "Plugin quota", CheckResult{Name: PluginCheckName("quota")}.Title())
+	assert.Equal(t, "custom", CheckResult{Name: "custom"}.Title())
+}
+
+func TestChecks(t *testing.T) {
+	passed := CheckResult{Name: CheckAWSCredentials, Status: CheckPassed}
+	skipped := CheckResult{Name: CheckAWSAccount, Status: CheckSkipped}
+	warned := CheckResult{Name: CheckAWSRootUser, Status: CheckWarning, Detail: "root"}
+	failed := CheckResult{Name: CheckAWSRegion, Status: CheckFailed, Detail: "unsupported"}
+
+	assert.Equal(t, CheckPassed, Checks{}.Status())
+	assert.Equal(t, CheckPassed, Checks{passed, skipped}.Status())
+	assert.Equal(t, CheckWarning, Checks{passed, warned, skipped}.Status())
+	assert.Equal(t, CheckFailed, Checks{warned, failed}.Status())
+
+	_, ok := Checks{passed, warned}.Failure()
+	assert.False(t, ok)
+	first, ok := Checks{passed, failed, CheckResult{Name: CheckPlatformAPI, Status: CheckFailed}}.Failure()
+	require.True(t, ok)
+	assert.Equal(t, failed, first)
+
+	assert.Equal(t, Checks{warned}, Checks{passed, warned, failed}.Warnings())
+
+	plugin := CheckResult{Name: PluginCheckName("quota"), Status: CheckPassed}
+	assert.Equal(t, Checks{passed, warned}, Checks{passed, plugin, warned}.WithoutPlugins())
+}
+
+func TestPluginResult_Check(t *testing.T) {
+	tests := []struct {
+		name        string
+		result      PluginResult
+		status      CheckStatus
+		details     string
+		remediation string
+	}{
+		{
+			name:    "pass",
+			result:  PluginResult{Name: "quota", Valid: true, Message: "ok"},
+			status:  CheckPassed,
+			details: "ok",
+		},
+		{
+			name:    "warn",
+			result:  PluginResult{Name: "quota", Valid: true, Message: "ok", Warnings: []string{"low quota", "old runtime"}},
+			status:  CheckWarning,
+			details: "low quota; old runtime",
+		},
+		{
+			name:        "fail",
+			result:      PluginResult{Name: "quota", Message: "quota exceeded"},
+			status:      CheckFailed,
+			details:     "quota exceeded",
+			remediation: RemediationPlugin,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := tt.result.Check()
+			assert.Equal(t, "plugin:quota", check.Name)
+			assert.Equal(t, tt.status, check.Status)
+			assert.Equal(t, tt.details, check.Detail)
+			assert.Equal(t, tt.remediation, check.Remediation)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Hook stages: pre hooks run before any check, post hooks after the whole suite
+const (
+	HookPre  = "pre"
+	HookPost = "post"
+)
+
+// maxHookResponseSize bounds how much of a webhook response is read
+const maxHookResponseSize = 1 << 20
+
+// Hook is an organization-provided step configured under validation_hooks that runs
+// before or after the validation suite, so onboarding can be gated on internal approval
+// systems. It is either a command, run with the hook input as JSON on stdin, or a
+// webhook URL the input is POSTed to. Either answers with a PluginResult document; an
+// empty answer from a command that exits zero or a 2xx webhook response passes.
+type Hook struct {
+	Name    string   `yaml:"name" json:"name"`
+	Stage   string   `yaml:"stage" json:"stage"`
+	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
+	URL     string   `yaml:"url,omitempty" json:"url,omitempty"`
+
+	// Headers are sent with webhook requests; ${VAR} references are expanded from the
+	// environment so tokens need not be written to the config file
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+
+	// Timeout bounds the hook's run; zero uses the validator plugin default of 30s
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// hookView is how a Hook is printed, with its timeout as written in the config file
+// rather than in nanoseconds
+type hookView struct {
+	Name    string            `yaml:"name" json:"name"`
+	Stage   string            `yaml:"stage" json:"stage"`
+	Command []string          `yaml:"command,omitempty" json:"command,omitempty"`
+	URL     string            `yaml:"url,omitempty" json:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Timeout string            `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+func (h Hook) view() hookView {
+	v := hookView{Name: h.Name, Stage: h.Stage, Command: h.Command, URL: h.URL, Headers: h.Headers}
+	if h.Timeout != 0 {
+		v.Timeout = h.Timeout.String()
+	}
+	return v
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (h Hook) MarshalYAML() (interface{}, error) {
+	return h.view(), nil
+}
+
+// MarshalJSON implements json.Marshaler
+func (h Hook) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.view())
+}
+
+// Validate checks that the hook is named, has a known stage, and runs exactly one
+// command or webhook
+func (h Hook) Validate() error {
+	if h.Name == "" {
+		return fmt.Errorf("hook name is required")
+	}
+	if h.Stage != HookPre && h.Stage != HookPost {
+		return fmt.Errorf("hook %s: unknown stage %q (expected %s or %s)", h.Name, h.Stage, HookPre, HookPost)
+	}
+	switch {
+	case len(h.Command) > 0 && h.URL != "":
+		return fmt.Errorf("hook %s: set either command or url, not both", h.Name)
+	case len(h.Command) == 0 && h.URL == "":
+		return fmt.Errorf("hook %s: command or url is required", h.Name)
+	case h.URL != "":
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("hook %s: url must be an http or https URL", h.Name)
+		}
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("hook %s: timeout must not be negative", h.Name)
+	}
+	return nil
+}
+
+// ValidateHooks checks every hook and that hook names are unique
+func ValidateHooks(hooks []Hook) error {
+	names := make(map[string]bool, len(hooks))
+	for _, hook := range hooks {
+		if err := hook.Validate(); err != nil {
+			return err
+		}
+		if names[hook.Name] {
+			return fmt.Errorf("hook %s is defined more than once", hook.Name)
+		}
+		names[hook.Name] = true
+	}
+	return nil
+}
+
+// HookInput is the JSON document passed to hooks. Pre hooks receive the validation
+// context; post hooks also receive the aggregated results of every check.
+type HookInput struct {
+	PluginInput
+	Stage string `json:"stage"`
+
+	Status CheckStatus `json:"status,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Checks Checks      `json:"checks,omitempty"`
+}
+
+// HookRunner runs the configured hooks of a stage
+type HookRunner struct {
+	hooks      []Hook
+	httpClient *http.Client
+	getenv     func(string) string
+}
+
+// HookRunnerOption configures a HookRunner
+type HookRunnerOption func(*HookRunner)
+
+// WithHookHTTPClient sets the client webhooks are called with, such as one that
+// honors the configured proxy
+func WithHookHTTPClient(client *http.Client) HookRunnerOption {
+	return func(r *HookRunner) {
+		r.httpClient = client
+	}
+}
+
+// NewHookRunner creates a runner for hooks, which must be valid
+func NewHookRunner(hooks []Hook, opts ...HookRunnerOption) (*HookRunner, error) {
+	if err := ValidateHooks(hooks); err != nil {
+		return nil, err
+	}
+
+	r := &HookRunner{
+		hooks:      hooks,
+		httpClient: http.DefaultClient,
+		getenv:     os.Getenv,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r, nil
+}
+
+// Run runs each hook of the stage, in configuration order, and returns one check per
+// hook. A hook that fails to run or answers malformed output fails its check.
+func (r *HookRunner) Run(ctx context.Context, stage string, input HookInput) (Checks, error) {
+	input.Stage = stage
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hook input: %w", err)
+	}
+
+	var checks Checks
+	for _, hook := range r.hooks {
+		if hook.Stage != stage {
+			continue
+		}
+
+		timeout := hook.Timeout
+		if timeout == 0 {
+			timeout = defaultPluginTimeout
+		}
+		hookCtx, cancel := context.WithTimeout(ctx, timeout)
+
+		start := time.Now()
+		var result PluginResult
+		if hook.URL != "" {
+			result = r.callWebhook(hookCtx, hook, payload)
+		} else {
+			result = runHookCommand(hookCtx, hook, payload)
+		}
+		cancel()
+
+		result.Name = hook.Name
+		result.Latency = time.Since(start)
+		checks = append(checks, result.hookCheck())
+	}
+	return checks, nil
+}
+
+// runHookCommand executes a command hook with the JSON payload on stdin
+func runHookCommand(ctx context.Context, hook Hook, payload []byte) PluginResult {
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		message := fmt.Sprintf("hook failed: %v", err)
+		if result, parseErr := parseHookResult(stdout.Bytes()); parseErr == nil && result.Message != "" {
+			message = result.Message
+		} else if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			message += ", stderr: " + detail
+		}
+		return PluginResult{Valid: false, Message: message}
+	}
+
+	result, err := parseHookResult(stdout.Bytes())
+	if err != nil {
+		return PluginResult{Valid: false, Message: fmt.Sprintf("hook returned invalid JSON: %v", err)}
+	}
+	return result
+}
+
+// callWebhook POSTs the JSON payload to a webhook hook
+func (r *HookRunner) callWebhook(ctx context.Context, hook Hook, payload []byte) PluginResult {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return PluginResult{Valid: false, Message: fmt.Sprintf("failed to create webhook request: %v", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range hook.Headers {
+		req.Header.Set(name, os.Expand(value, r.getenv))
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return PluginResult{Valid: false, Message: fmt.Sprintf("webhook request failed: %v", err)}
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHookResponseSize))
+	if err != nil {
+		return PluginResult{Valid: false, Message: fmt.Sprintf("failed to read webhook response: %v", err)}
+	}
+
+	result, parseErr := parseHookResult(body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		message := fmt.Sprintf("webhook returned %s", resp.Status)
+		if parseErr == nil && result.Message != "" {
+			message = result.Message
+		}
+		return PluginResult{Valid: false, Message: message}
+	}
+	if parseErr != nil {
+		return PluginResult{Valid: false, Message: fmt.Sprintf("webhook returned invalid JSON: %v", parseErr)}
+	}
+	return result
+}
+
+// parseHookResult decodes a hook's answer; an empty answer passes
+func parseHookResult(data []byte) (PluginResult, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return PluginResult{Valid: true}, nil
+	}
+	var result PluginResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return PluginResult{}, err
+	}
+	return result, nil
+}
@@ -0,0 +1,172 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateHooks(t *testing.T) {
+	tests := []struct {
+		name    string
+		hooks   []Hook
+		wantErr string
+	}{
+		{name: "command", hooks: []Hook{{Name: "audit", Stage: HookPost, Command: []string{"audit"}}}},
+		{name: "webhook", hooks: []Hook{{Name: "approvals", Stage: HookPre, URL: "https://approvals.example.com"}}},
+		{name: "unnamed", hooks: []Hook{{Stage: HookPre, Command: []string{"audit"}}}, wantErr: "name is required"},
+		{name: "unknown stage", hooks: []Hook{{Name: "audit", Stage: "during", Command: []string{"audit"}}}, wantErr: "unknown stage"},
+		{name: "neither", hooks: []Hook{{Name: "audit", Stage: HookPre}}, wantErr: "command or url is required"},
+		{
+			name:    "both",
+			hooks:   []Hook{{Name: "audit", Stage: HookPre, Command: []string{"audit"}, URL: "https://approvals.example.com"}},
+			wantErr: "not both",
+		},
+		{name: "bad url", hooks: []Hook{{Name: "approvals", Stage: HookPre, URL: "approvals.example.com"}}, wantErr: "http or https URL"},
+		{
+			name:    "negative timeout",
+			hooks:   []Hook{{Name: "audit", Stage: HookPre, Command: []string{"audit"}, Timeout: -time.Second}},
+			wantErr: "must not be negative",
+		},
+		{
+			name: "duplicate",
+			hooks: []Hook{
+				{Name: "audit", Stage: HookPre, Command: []string{"audit"}},
+				{Name: "audit", Stage: HookPost, Command: []string{"audit"}},
+			},
+			wantErr: "more than once",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateHooks(tt.hooks)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestHookRunner_Command(t *testing.T) {
+	dir := t.TempDir()
+	// Approve only post-stage input carrying passing results
+	writePlugin(t, dir, "gate", `read input
+case "$input" in
+  *'"stage":"post"'*'"status":"pass"'*) echo '{"valid":true,"message":"approved"}' ;;
+  *) echo '{"valid":false,"message":"not approved"}' ;;
+esac`)
+	writePlugin(t, dir, "silent", "cat >/dev/null")
+	writePlugin(t, dir, "exit-error", "echo denied by policy >&2; exit 2")
+	writePlugin(t, dir, "warn", `echo '{"valid":true,"warnings":["approval expires soon"]}'`)
+
+	runner, err := NewHookRunner([]Hook{
+		{Name: "gate", Stage: HookPost, Command: []string{filepath.Join(dir, "gate")}},
+		{Name: "silent", Stage: HookPost, Command: []string{filepath.Join(dir, "silent")}},
+		{Name: "exit-error", Stage: HookPost, Command: []string{filepath.Join(dir, "exit-error")}},
+		{Name: "warn", Stage: HookPost, Command: []string{filepath.Join(dir, "warn")}},
+		{Name: "pre-only", Stage: HookPre, Command: []string{filepath.Join(dir, "exit-error")}},
+	})
+	require.NoError(t, err)
+
+	checks, err := runner.Run(context.Background(), HookPost, HookInput{
+		PluginInput: PluginInput{AccountID: "123456789012", Command: "init"},
+		Status:      CheckPassed,
+		Checks:      Checks{{Name: CheckAWSCredentials, Status: CheckPassed}},
+	})
+	require.NoError(t, err)
+	require.Len(t, checks, 4, "only hooks of the stage run")
+
+	assert.Equal(t, "hook:gate", checks[0].Name)
+	assert.Equal(t, CheckPassed, checks[0].Status)
+	assert.Equal(t, "approved", checks[0].Detail)
+
+	assert.Equal(t, CheckPassed, checks[1].Status, "an empty answer passes")
+
+	assert.Equal(t, CheckFailed, checks[2].Status)
+	assert.Contains(t, checks[2].Detail, "denied by policy")
+	assert.Equal(t, RemediationHook, checks[2].Remediation)
+
+	assert.Equal(t, CheckWarning, checks[3].Status)
+	assert.Equal(t, "approval expires soon", checks[3].Detail)
+}
+
+func TestHookRunner_Webhook(t *testing.T) {
+	var received HookInput
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/approve":
+			authorization = r.Header.Get("Authorization")
+			body, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(body, &received)
+			w.WriteHeader(http.StatusNoContent)
+		case "/deny":
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"valid":false,"message":"account 123456789012 is not approved for onboarding"}`))
+		default:
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	runner, err := NewHookRunner([]Hook{
+		{Name: "approve", Stage: HookPre, URL: server.URL + "/approve", Headers: map[string]string{"Authorization": "Bearer ${TOKEN}"}},
+		{Name: "deny", Stage: HookPre, URL: server.URL + "/deny"},
+		{Name: "error", Stage: HookPre, URL: server.URL + "/error"},
+	}, WithHookHTTPClient(server.Client()))
+	require.NoError(t, err)
+	runner.getenv = func(key string) string {
+		if key == "TOKEN" {
+			return "s3cret"
+		}
+		return ""
+	}
+
+	checks, err := runner.Run(context.Background(), HookPre, HookInput{
+		PluginInput: PluginInput{AccountID: "123456789012", Region: "us-east-1", Command: "init"},
+	})
+	require.NoError(t, err)
+	require.Len(t, checks, 3)
+
+	assert.Equal(t, CheckPassed, checks[0].Status)
+	assert.Equal(t, "Bearer s3cret", authorization, "header references are expanded from the environment")
+	assert.Equal(t, HookPre, received.Stage)
+	assert.Equal(t, "us-east-1", received.Region)
+
+	assert.Equal(t, CheckFailed, checks[1].Status)
+	assert.Equal(t, "account 123456789012 is not approved for onboarding", checks[1].Detail)
+
+	assert.Equal(t, CheckFailed, checks[2].Status)
+	assert.Contains(t, checks[2].Detail, "500")
+}
+
+func TestHookRunner_Timeout(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "slow", "exec sleep 5")
+
+	runner, err := NewHookRunner([]Hook{
+		{Name: "slow", Stage: HookPre, Command: []string{filepath.Join(dir, "slow")}, Timeout: 100 * time.Millisecond},
+	})
+	require.NoError(t, err)
+
+	checks, err := runner.Run(context.Background(), HookPre, HookInput{})
+	require.NoError(t, err)
+	require.Len(t, checks, 1)
+	assert.Equal(t, CheckFailed, checks[0].Status)
+}
+
+func TestCheckResult_HookTitle(t *testing.T) {
+	assert.Equal(t, "Hook approvals", CheckResult{Name: HookCheckName("approvals")}.Title())
+}
@@ -0,0 +1,136 @@
+package validator
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// accessCheckPath is the resource requested to confirm access. Unlike the live
+// endpoint it is a resource commands act on, so it is covered by the same
+// execute-api:Invoke permissions and VPC endpoint policy statements they need.
+const accessCheckPath = "/prod/v0/clusters"
+
+// maxAccessErrorBody bounds how much of an error response is read
+const maxAccessErrorBody = 4096
+
+// ValidateAccess checks that the caller can SigV4-invoke the Platform API in the
+// target region from the current network. Some organizations only allow
+// execute-api through a VPC endpoint, so a failure is reported as a network failure,
+// when the API cannot be reached, or an authorization failure, when it rejects the
+// caller, naming a VPC endpoint policy when one denied the request. When the
+// configured API URL is in another region, access in the target region cannot be
+// confirmed and the check warns.
+func (v *PlatformValidator) ValidateAccess(ctx context.Context, region string) (CheckResult, error) {
+	start := time.Now()
+	fail := func(detail, remediation string, err error) (CheckResult, error) {
+		return newCheck(CheckPlatformAccess, CheckFailed, start, detail, remediation), err
+	}
+
+	if v.apiURL == "" {
+		return fail("Platform API URL is not configured", RemediationPlatformURL, fmt.Errorf("API URL not configured"))
+	}
+
+	apiRegion := extractRegionFromURL(v.apiURL)
+	if apiRegion == "" {
+		apiRegion = v.awsConfig.Region
+	}
+	if region == "" {
+		region = apiRegion
+	}
+	if apiRegion != region {
+		return newCheck(CheckPlatformAccess, CheckWarning, start,
+			fmt.Sprintf("Platform API URL is in %s, not the target region %s; access to the Platform API in %s was not verified", apiRegion, region, region),
+			RemediationPlatformRegion), nil
+	}
+
+	accessURL := v.apiURL + accessCheckPath
+	resp, failure := v.signedGet(ctx, accessURL, apiRegion)
+	if failure != nil {
+		if failure.remediation != RemediationPlatformUnreachable {
+			return fail(failure.detail, failure.remediation, failure.err)
+		}
+		detail := fmt.Sprintf("Network failure: %s", describeNetworkError(failure.err))
+		return fail(detail, RemediationPlatformUnreachable,
+			fmt.Errorf("network failure reaching the Platform API in %s: %w", region, failure.err))
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return newCheck(CheckPlatformAccess, CheckPassed, start,
+			fmt.Sprintf("Signed GET %s succeeded in %s", accessCheckPath, region), ""), nil
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		message := errorMessage(resp.Body)
+		remediation := RemediationPlatformAuth
+		reason := "the Platform API rejected the caller"
+		if isVPCEndpointDenial(message) {
+			remediation = RemediationPlatformVPCEndpoint
+			reason = "a VPC endpoint policy denied the request"
+		}
+		return fail(fmt.Sprintf("Authorization failure: %s (status %d: %s)", reason, resp.StatusCode, message), remediation,
+			fmt.Errorf("authorization failure invoking the Platform API in %s: %s", region, reason))
+	default:
+		message := errorMessage(resp.Body)
+		return fail(fmt.Sprintf("GET %s returned status: %d, body: %s", accessURL, resp.StatusCode, message),
+			RemediationPlatformStatus, fmt.Errorf("GET %s returned status code: %d", accessURL, resp.StatusCode))
+	}
+}
+
+// describeNetworkError explains why a request did not reach the Platform API
+func describeNetworkError(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var netErr net.Error
+	var opErr *net.OpError
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("cannot resolve %s from this network; a private API is only resolvable inside a VPC with an execute-api endpoint", dnsErr.Name)
+	case errors.As(err, &certErr), errors.As(err, &unknownAuthorityErr):
+		return fmt.Sprintf("TLS verification failed, often caused by an intercepting proxy: %v", err)
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timed out connecting; a firewall or security group may be dropping traffic to the endpoint"
+	case errors.As(err, &opErr):
+		return fmt.Sprintf("cannot connect: %v", opErr.Err)
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err.Error()
+	}
+	return err.Error()
+}
+
+// errorMessage reads the message of an API Gateway error response, or the raw body
+func errorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, maxAccessErrorBody))
+	var response struct {
+		Message      string `json:"message"`
+		UpperMessage string `json:"Message"`
+	}
+	if err := json.Unmarshal(data, &response); err == nil {
+		if response.Message != "" {
+			return response.Message
+		}
+		if response.UpperMessage != "" {
+			return response.UpperMessage
+		}
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// isVPCEndpointDenial reports whether an API Gateway authorization message names a
+// VPC endpoint policy, as in "... with an explicit deny in a VPC endpoint policy"
+func isVPCEndpointDenial(message string) bool {
+	return strings.Contains(strings.ToLower(message), "vpc endpoint policy")
+}
@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/openshift-online/regional-cli/internal/platform/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlatformValidator_ValidateAccess(t *testing.T) {
+	server := fake.NewServer(fake.WithCredentials(testCredentials))
+	defer server.Close()
+
+	result, err := NewPlatformValidator(server.URL, createTestAWSConfig()).ValidateAccess(context.Background(), "us-east-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, CheckPlatformAccess, result.Name)
+	assert.Equal(t, CheckPassed, result.Status)
+
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	assert.Equal(t, fake.EndpointListClusters, requests[0].Endpoint)
+	assert.Equal(t, testCredentials.AccessKeyID, requests[0].AccessKeyID)
+}
+
+func TestPlatformValidator_ValidateAccess_VPCEndpointDenial(t *testing.T) {
+	server := fake.NewServer(fake.WithFailure(fake.EndpointListClusters, fake.Failure{
+		StatusCode: http.StatusForbidden,
+		Body: `{"Message":"User: arn:aws:sts::123456789012:assumed-role/admin/me is not authorized to perform: ` +
+			`execute-api:Invoke on resource: arn:aws:execute-api:us-east-1:********2345:abc123/prod/GET/v0/clusters ` +
+			`with an explicit deny in a VPC endpoint policy"}`,
+	}))
+	defer server.Close()
+
+	result, err := NewPlatformValidator(server.URL, createTestAWSConfig()).ValidateAccess(context.Background(), "us-east-1")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authorization failure")
+	assert.Equal(t, CheckFailed, result.Status)
+	assert.Contains(t, result.Detail, "Authorization failure: a VPC endpoint policy denied the request")
+	assert.Contains(t, result.Detail, "explicit deny in a VPC endpoint policy")
+	assert.Equal(t, RemediationPlatformVPCEndpoint, result.Remediation)
+}
+
+func TestPlatformValidator_ValidateAccess_Unauthorized(t *testing.T) {
+	// The API knows a different secret for the access key, so the signature is rejected
+	server := fake.NewServer(fake.WithCredentials(aws.Credentials{
+		AccessKeyID:     testCredentials.AccessKeyID,
+		SecretAccessKey: "rotated",
+	}))
+	defer server.Close()
+
+	result, err := NewPlatformValidator(server.URL, createTestAWSConfig()).ValidateAccess(context.Background(), "us-east-1")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "authorization failure")
+	assert.Contains(t, result.Detail, "Authorization failure: the Platform API rejected the caller")
+	assert.Equal(t, RemediationPlatformAuth, result.Remediation)
+}
+
+func TestPlatformValidator_ValidateAccess_NetworkFailure(t *testing.T) {
+	server := fake.NewServer()
+	apiURL := server.URL
+	server.Close()
+
+	result, err := NewPlatformValidator(apiURL, createTestAWSConfig()).ValidateAccess(context.Background(), "us-east-1")
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "network failure")
+	assert.Contains(t, result.Detail, "Network failure: cannot connect")
+	assert.Equal(t, RemediationPlatformUnreachable, result.Remediation)
+}
+
+func TestPlatformValidator_ValidateAccess_OtherRegion(t *testing.T) {
+	validator := NewPlatformValidator("https://abc123.execute-api.us-west-2.amazonaws.com", createTestAWSConfig())
+	result, err := validator.ValidateAccess(context.Background(), "us-east-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, CheckWarning, result.Status)
+	assert.Contains(t, result.Detail, "access to the Platform API in us-east-1 was not verified")
+	assert.Equal(t, RemediationPlatformRegion, result.Remediation)
+}
+
+func TestDescribeNetworkError(t *testing.T) {
+	dnsErr := &url.Error{Op: "Get", URL: "https://abc123.execute-api.us-east-1.amazonaws.com",
+		Err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "abc123.execute-api.us-east-1.amazonaws.com", IsNotFound: true}}}
+	assert.Contains(t, describeNetworkError(dnsErr), "cannot resolve abc123.execute-api.us-east-1.amazonaws.com")
+
+	assert.Contains(t, describeNetworkError(&url.Error{Op: "Get", Err: context.DeadlineExceeded}), "timed out")
+	assert.Equal(t, "boom", describeNetworkError(&url.Error{Op: "Get", Err: errors.New("boom")}))
+}
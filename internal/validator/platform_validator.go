@@ -11,6 +11,8 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/openshift-online/regional-cli/pkg/dualstack"
+	"github.com/openshift-online/regional-cli/pkg/proxy"
 )
 
 // PlatformValidator validates Platform API connectivity
@@ -20,28 +22,48 @@ type PlatformValidator struct {
 	httpClient *http.Client
 }
 
+// PlatformValidatorOption configures optional PlatformValidator behavior
+type PlatformValidatorOption func(*PlatformValidator)
+
+// WithDualStack connects to the Platform API's dual-stack endpoint, dialing IPv6
+// first, so validation works from IPv6-only networks
+func WithDualStack(enabled bool) PlatformValidatorOption {
+	return func(v *PlatformValidator) {
+		if enabled {
+			v.apiURL = dualstack.EndpointURL(v.apiURL)
+			transport := dualstack.Transport()
+			transport.Proxy = proxy.FromConfig(v.awsConfig)
+			v.httpClient.Transport = transport
+		}
+	}
+}
+
 // NewPlatformValidator creates a new Platform API validator
-func NewPlatformValidator(apiURL string, awsConfig aws.Config) *PlatformValidator {
-	return &PlatformValidator{
+func NewPlatformValidator(apiURL string, awsConfig aws.Config, opts ...PlatformValidatorOption) *PlatformValidator {
+	v := &PlatformValidator{
 		apiURL:    apiURL,
 		awsConfig: awsConfig,
 		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout:   10 * time.Second,
+			Transport: proxy.Transport(awsConfig),
 		},
 	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	return v
 }
 
-// PlatformValidationResult holds the result of Platform API validation
-type PlatformValidationResult struct {
-	Valid        bool
-	APIVersion   string
-	ErrorMessage string
+// APIURL returns the Platform API URL requests are sent to
+func (v *PlatformValidator) APIURL() string {
+	return v.apiURL
 }
 
 // extractRegionFromURL extracts the AWS region from an API Gateway URL
 func extractRegionFromURL(url string) string {
-	// Match pattern like: https://xxx.execute-api.REGION.amazonaws.com
-	re := regexp.MustCompile(`execute-api\.([a-z0-9-]+)\.amazonaws\.com`)
+	// Match pattern like: https://xxx.execute-api.REGION.amazonaws.com, or the
+	// dual-stack https://xxx.execute-api.REGION.api.aws
+	re := regexp.MustCompile(`execute-api\.([a-z0-9-]+)\.(?:amazonaws\.com|api\.aws|api\.amazonwebservices\.com\.cn)`)
 	matches := re.FindStringSubmatch(url)
 	if len(matches) > 1 {
 		return matches[1]
@@ -49,13 +71,16 @@ func extractRegionFromURL(url string) string {
 	return ""
 }
 
-// Validate validates Platform API connectivity
-func (v *PlatformValidator) Validate(ctx context.Context) (*PlatformValidationResult, error) {
+// Validate checks that the Platform API's live endpoint accepts the caller's signed
+// requests. The check's detail is the endpoint's response on success.
+func (v *PlatformValidator) Validate(ctx context.Context) (CheckResult, error) {
+	start := time.Now()
+	fail := func(detail, remediation string, err error) (CheckResult, error) {
+		return newCheck(CheckPlatformAPI, CheckFailed, start, detail, remediation), err
+	}
+
 	if v.apiURL == "" {
-		return &PlatformValidationResult{
-			Valid:        false,
-			ErrorMessage: "Platform API URL is not configured",
-		}, fmt.Errorf("API URL not configured")
+		return fail("Platform API URL is not configured", RemediationPlatformURL, fmt.Errorf("API URL not configured"))
 	}
 
 	// Extract region from API URL for SigV4 signing
@@ -68,43 +93,9 @@ func (v *PlatformValidator) Validate(ctx context.Context) (*PlatformValidationRe
 	// Use the correct live endpoint
 	liveURL := v.apiURL + "/prod/v0/live"
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "GET", liveURL, nil)
-	if err != nil {
-		return &PlatformValidationResult{
-			Valid:        false,
-			ErrorMessage: fmt.Sprintf("Failed to create request to %s: %v", liveURL, err),
-		}, err
-	}
-
-	// Sign request with AWS SigV4 using the API's region
-	credentials, err := v.awsConfig.Credentials.Retrieve(ctx)
-	if err != nil {
-		return &PlatformValidationResult{
-			Valid:        false,
-			ErrorMessage: fmt.Sprintf("Failed to retrieve AWS credentials for signing: %v", err),
-		}, err
-	}
-
-	// Calculate payload hash for empty body (GET request)
-	payloadHash := fmt.Sprintf("%x", sha256.Sum256([]byte{}))
-
-	signer := v4.NewSigner()
-	err = signer.SignHTTP(ctx, credentials, req, payloadHash, "execute-api", apiRegion, time.Now())
-	if err != nil {
-		return &PlatformValidationResult{
-			Valid:        false,
-			ErrorMessage: fmt.Sprintf("Failed to sign request: %v", err),
-		}, err
-	}
-
-	// Execute request
-	resp, err := v.httpClient.Do(req)
-	if err != nil {
-		return &PlatformValidationResult{
-			Valid:        false,
-			ErrorMessage: fmt.Sprintf("Failed to connect to %s: %v", liveURL, err),
-		}, err
+	resp, failure := v.signedGet(ctx, liveURL, apiRegion)
+	if failure != nil {
+		return fail(failure.detail, failure.remediation, failure.err)
 	}
 	defer resp.Body.Close()
 
@@ -112,25 +103,55 @@ func (v *PlatformValidator) Validate(ctx context.Context) (*PlatformValidationRe
 	if resp.StatusCode != http.StatusOK {
 		// Read response body for more details
 		body, _ := io.ReadAll(resp.Body)
-		return &PlatformValidationResult{
-			Valid:        false,
-			ErrorMessage: fmt.Sprintf("GET %s returned status: %d, body: %s", liveURL, resp.StatusCode, string(body)),
-		}, fmt.Errorf("GET %s returned status code: %d", liveURL, resp.StatusCode)
+		remediation := RemediationPlatformStatus
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			remediation = RemediationPlatformAuth
+		}
+		return fail(fmt.Sprintf("GET %s returned status: %d, body: %s", liveURL, resp.StatusCode, string(body)),
+			remediation, fmt.Errorf("GET %s returned status code: %d", liveURL, resp.StatusCode))
 	}
 
 	// Read and parse response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return &PlatformValidationResult{
-			Valid:        false,
-			ErrorMessage: fmt.Sprintf("Failed to read response: %v", err),
-		}, err
+		return fail(fmt.Sprintf("Failed to read response: %v", err), RemediationPlatformUnreachable, err)
 	}
 
 	// For now, just validate we got a response
 	// In a real implementation, you would parse JSON for version info
-	return &PlatformValidationResult{
-		Valid:      true,
-		APIVersion: string(body), // Contains {"status":"ok"}
-	}, nil
+	return newCheck(CheckPlatformAPI, CheckPassed, start, string(body), ""), nil // Contains {"status":"ok"}
+}
+
+// requestFailure is why a signed request could not be sent or completed
+type requestFailure struct {
+	detail      string
+	remediation string
+	err         error
+}
+
+// signedGet sends a GET request to url signed with SigV4 for execute-api in apiRegion
+func (v *PlatformValidator) signedGet(ctx context.Context, url, apiRegion string) (*http.Response, *requestFailure) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, &requestFailure{fmt.Sprintf("Failed to create request to %s: %v", url, err), RemediationPlatformURL, err}
+	}
+
+	credentials, err := v.awsConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, &requestFailure{fmt.Sprintf("Failed to retrieve AWS credentials for signing: %v", err), RemediationAWSCredentials, err}
+	}
+
+	// Calculate payload hash for empty body (GET request)
+	payloadHash := fmt.Sprintf("%x", sha256.Sum256([]byte{}))
+
+	signer := v4.NewSigner()
+	if err := signer.SignHTTP(ctx, credentials, req, payloadHash, "execute-api", apiRegion, time.Now()); err != nil {
+		return nil, &requestFailure{fmt.Sprintf("Failed to sign request: %v", err), RemediationAWSCredentials, err}
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, &requestFailure{fmt.Sprintf("Failed to connect to %s: %v", url, err), RemediationPlatformUnreachable, err}
+	}
+	return resp, nil
 }
@@ -0,0 +1,141 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPluginTimeout = 30 * time.Second
+)
+
+// PluginInput is the context passed to external validator plugins as JSON on stdin
+type PluginInput struct {
+	AccountID      string `json:"account_id"`
+	UserARN        string `json:"user_arn"`
+	Region         string `json:"region"`
+	PlatformAPIURL string `json:"platform_api_url,omitempty"`
+	Command        string `json:"command"`
+}
+
+// PluginResult is the JSON document an external validator plugin writes to stdout
+type PluginResult struct {
+	Name     string   `json:"name"`
+	Valid    bool     `json:"valid"`
+	Message  string   `json:"message,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+
+	// Latency is how long the plugin took to run; it is measured, not read from the plugin
+	Latency time.Duration `json:"-"`
+}
+
+// PluginValidator runs organization-provided executables as additional preflight checks
+type PluginValidator struct {
+	dir     string
+	timeout time.Duration
+}
+
+// NewPluginValidator creates a validator that runs every executable found in dir
+func NewPluginValidator(dir string) *PluginValidator {
+	return &PluginValidator{
+		dir:     dir,
+		timeout: defaultPluginTimeout,
+	}
+}
+
+// Discover returns the executable plugins in the plugin directory, sorted by name
+func (v *PluginValidator) Discover() ([]string, error) {
+	entries, err := os.ReadDir(v.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory %s: %w", v.dir, err)
+	}
+
+	var plugins []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		plugins = append(plugins, filepath.Join(v.dir, entry.Name()))
+	}
+
+	sort.Strings(plugins)
+	return plugins, nil
+}
+
+// Validate runs each plugin and returns one result per plugin. A plugin that
+// fails to run or returns malformed output is reported as an invalid result.
+func (v *PluginValidator) Validate(ctx context.Context, input PluginInput) ([]PluginResult, error) {
+	plugins, err := v.Discover()
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin input: %w", err)
+	}
+
+	results := make([]PluginResult, 0, len(plugins))
+	for _, plugin := range plugins {
+		start := time.Now()
+		result := v.runPlugin(ctx, plugin, payload)
+		result.Latency = time.Since(start)
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// runPlugin executes a single plugin with the JSON payload on stdin
+func (v *PluginValidator) runPlugin(ctx context.Context, pluginPath string, payload []byte) PluginResult {
+	name := filepath.Base(pluginPath)
+
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, pluginPath)
+	cmd.Stdin = bytes.NewReader(payload)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+
+	var result PluginResult
+	if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+		message := fmt.Sprintf("plugin returned invalid JSON: %v", err)
+		if runErr != nil {
+			message = fmt.Sprintf("plugin failed: %v, stderr: %s", runErr, strings.TrimSpace(stderr.String()))
+		}
+		return PluginResult{Name: name, Valid: false, Message: message}
+	}
+
+	if result.Name == "" {
+		result.Name = name
+	}
+
+	// A non-zero exit always fails the check, even if the plugin claimed success
+	if runErr != nil && result.Valid {
+		result.Valid = false
+		result.Message = fmt.Sprintf("plugin exited with error: %v", runErr)
+	}
+
+	return result
+}
@@ -0,0 +1,72 @@
+package validator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePlugin creates an executable shell script plugin in dir
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755)
+	require.NoError(t, err)
+}
+
+func TestPluginValidator_Discover(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "20-second", "exit 0")
+	writePlugin(t, dir, "10-first", "exit 0")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not executable"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdir"), 0755))
+
+	plugins, err := NewPluginValidator(dir).Discover()
+	require.NoError(t, err)
+	require.Len(t, plugins, 2)
+	assert.Equal(t, "10-first", filepath.Base(plugins[0]))
+	assert.Equal(t, "20-second", filepath.Base(plugins[1]))
+}
+
+func TestPluginValidator_MissingDirectory(t *testing.T) {
+	results, err := NewPluginValidator(filepath.Join(t.TempDir(), "missing")).Validate(context.Background(), PluginInput{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestPluginValidator_Validate(t *testing.T) {
+	dir := t.TempDir()
+	// Echo the region from stdin back to prove the input payload is delivered
+	writePlugin(t, dir, "region-check", `read input
+case "$input" in
+  *'"region":"us-east-1"'*) echo '{"name":"region-policy","valid":true,"message":"region approved"}' ;;
+  *) echo '{"valid":false,"message":"region not approved"}' ;;
+esac`)
+	writePlugin(t, dir, "broken", "echo not-json")
+	writePlugin(t, dir, "exit-error", `echo '{"valid":true}'; exit 3`)
+
+	results, err := NewPluginValidator(dir).Validate(context.Background(), PluginInput{
+		AccountID: "123456789012",
+		Region:    "us-east-1",
+		Command:   "init",
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	byName := map[string]PluginResult{}
+	for _, r := range results {
+		byName[r.Name] = r
+	}
+
+	assert.True(t, byName["region-policy"].Valid)
+	assert.Equal(t, "region approved", byName["region-policy"].Message)
+
+	assert.False(t, byName["broken"].Valid)
+	assert.Contains(t, byName["broken"].Message, "invalid JSON")
+
+	assert.False(t, byName["exit-error"].Valid)
+	assert.Contains(t, byName["exit-error"].Message, "exited with error")
+}
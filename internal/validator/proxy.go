@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openshift-online/regional-cli/pkg/proxy"
+)
+
+// proxyDialTimeout bounds the connection attempt to each proxy
+const proxyDialTimeout = 5 * time.Second
+
+// defaultProxyPorts are the ports proxies listen on when their URL has none
+var defaultProxyPorts = map[string]string{"http": "80", "https": "443", "socks5": "1080"}
+
+// ProxyValidator checks that the proxies rosactl's requests go through accept
+// connections, so an unreachable proxy is reported as such rather than as failed
+// AWS or Platform API calls
+type ProxyValidator struct {
+	proxyFunc proxy.Func
+	dialer    *net.Dialer
+}
+
+// NewProxyValidator creates a validator for the proxies proxyFunc selects
+func NewProxyValidator(proxyFunc proxy.Func) *ProxyValidator {
+	return &ProxyValidator{
+		proxyFunc: proxyFunc,
+		dialer:    &net.Dialer{Timeout: proxyDialTimeout},
+	}
+}
+
+// Validate connects to the proxy of each target URL. The check is skipped when
+// every target is reached directly.
+func (v *ProxyValidator) Validate(ctx context.Context, targets ...string) CheckResult {
+	start := time.Now()
+
+	// Group targets by the proxy that serves them
+	hosts := make(map[string][]string)
+	proxies := make(map[string]*url.URL)
+	for _, target := range targets {
+		proxyURL, err := proxy.ForURL(v.proxyFunc, target)
+		if err != nil {
+			return newCheck(CheckProxy, CheckFailed, start, fmt.Sprintf("Failed to select a proxy for %s: %v", target, err), RemediationProxy)
+		}
+		if proxyURL == nil {
+			continue
+		}
+		key := proxyURL.Redacted()
+		proxies[key] = proxyURL
+		hosts[key] = append(hosts[key], targetHost(target))
+	}
+	if len(proxies) == 0 {
+		return newCheck(CheckProxy, CheckSkipped, start, "no proxy configured", "")
+	}
+
+	keys := make([]string, 0, len(proxies))
+	for key := range proxies {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var details []string
+	for _, key := range keys {
+		address := proxyAddress(proxies[key])
+		conn, err := v.dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return newCheck(CheckProxy, CheckFailed, start,
+				fmt.Sprintf("Proxy %s for %s is unreachable: %v", key, strings.Join(hosts[key], ", "), err),
+				RemediationProxy)
+		}
+		conn.Close()
+		details = append(details, fmt.Sprintf("%s via %s", strings.Join(hosts[key], ", "), key))
+	}
+	return newCheck(CheckProxy, CheckPassed, start, strings.Join(details, "; "), "")
+}
+
+// proxyAddress returns the host:port to connect to for a proxy
+func proxyAddress(proxyURL *url.URL) string {
+	port := proxyURL.Port()
+	if port == "" {
+		port = defaultProxyPorts[proxyURL.Scheme]
+	}
+	return net.JoinHostPort(proxyURL.Hostname(), port)
+}
+
+// targetHost returns the host of a target URL for messages
+func targetHost(target string) string {
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return target
+}
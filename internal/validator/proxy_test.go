@@ -0,0 +1,68 @@
+package validator
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proxyFor returns a proxy function sending every request to proxyURL
+func proxyFor(proxyURL *url.URL) func(*http.Request) (*url.URL, error) {
+	return func(*http.Request) (*url.URL, error) { return proxyURL, nil }
+}
+
+func TestProxyValidator_NoProxy(t *testing.T) {
+	direct := func(*http.Request) (*url.URL, error) { return nil, nil }
+
+	check := NewProxyValidator(direct).Validate(context.Background(), "https://sts.us-east-1.amazonaws.com")
+	assert.Equal(t, CheckProxy, check.Name)
+	assert.Equal(t, CheckSkipped, check.Status)
+}
+
+func TestProxyValidator_Reachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	proxyURL := &url.URL{Scheme: "http", User: url.UserPassword("user", "s3cret"), Host: listener.Addr().String()}
+	check := NewProxyValidator(proxyFor(proxyURL)).Validate(context.Background(),
+		"https://sts.us-east-1.amazonaws.com", "https://abc123.execute-api.us-east-1.amazonaws.com")
+
+	assert.Equal(t, CheckPassed, check.Status)
+	assert.Contains(t, check.Detail, "sts.us-east-1.amazonaws.com, abc123.execute-api.us-east-1.amazonaws.com via http://user:xxxxx@"+listener.Addr().String())
+	assert.NotContains(t, check.Detail, "s3cret")
+}
+
+func TestProxyValidator_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	proxyURL := &url.URL{Scheme: "http", Host: address}
+	check := NewProxyValidator(proxyFor(proxyURL)).Validate(context.Background(), "https://sts.us-east-1.amazonaws.com")
+
+	assert.Equal(t, CheckFailed, check.Status)
+	assert.Contains(t, check.Detail, "Proxy http://"+address+" for sts.us-east-1.amazonaws.com is unreachable")
+	assert.Equal(t, RemediationProxy, check.Remediation)
+}
+
+func TestProxyAddress(t *testing.T) {
+	assert.Equal(t, "proxy.corp:80", proxyAddress(&url.URL{Scheme: "http", Host: "proxy.corp"}))
+	assert.Equal(t, "proxy.corp:1080", proxyAddress(&url.URL{Scheme: "socks5", Host: "proxy.corp"}))
+	assert.Equal(t, "proxy.corp:3128", proxyAddress(&url.URL{Scheme: "http", Host: "proxy.corp:3128"}))
+}
@@ -0,0 +1,203 @@
+package validator
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/openshift-online/regional-cli/pkg/proxy"
+)
+
+// Region opt-in statuses as reported by EC2 DescribeRegions
+const (
+	RegionOptInNotRequired = "opt-in-not-required"
+	RegionOptedIn          = "opted-in"
+	RegionNotOptedIn       = "not-opted-in"
+)
+
+// supportedRegions lists the regions ROSA regional HCP is available in
+var supportedRegions = []string{
+	"us-east-1",
+	"us-east-2",
+	"us-west-1",
+	"us-west-2",
+	"eu-west-1",
+	"eu-west-2",
+	"eu-west-3",
+	"eu-central-1",
+	"eu-north-1",
+	"eu-south-1",
+	"eu-south-2",
+	"ap-southeast-1",
+	"ap-southeast-2",
+	"ap-northeast-1",
+	"ap-northeast-2",
+	"ap-south-1",
+	"ap-east-1",
+	"me-central-1",
+	"af-south-1",
+	"sa-east-1",
+	"ca-central-1",
+}
+
+// optInRegions lists AWS regions that are disabled by default and must be enabled
+// per account before they can be used
+var optInRegions = map[string]bool{
+	"af-south-1":     true,
+	"ap-east-1":      true,
+	"ap-east-2":      true,
+	"ap-south-2":     true,
+	"ap-southeast-3": true,
+	"ap-southeast-4": true,
+	"ap-southeast-5": true,
+	"ap-southeast-7": true,
+	"ca-west-1":      true,
+	"eu-central-2":   true,
+	"eu-south-1":     true,
+	"eu-south-2":     true,
+	"il-central-1":   true,
+	"me-central-1":   true,
+	"me-south-1":     true,
+	"mx-central-1":   true,
+}
+
+// SupportedRegions returns the regions ROSA regional HCP is available in
+func SupportedRegions() []string {
+	return slices.Clone(supportedRegions)
+}
+
+// isSupportedRegion checks if the region is in the supported list
+func isSupportedRegion(region string) bool {
+	for _, supported := range supportedRegions {
+		if region == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// isOptInRegion reports whether the region must be enabled before an account can use it
+func isOptInRegion(region string) bool {
+	return optInRegions[region]
+}
+
+// RegionOptInAPI reports whether a region is enabled for the calling account
+type RegionOptInAPI interface {
+	RegionOptInStatus(ctx context.Context, region string) (string, error)
+}
+
+// RegionOptInChecker looks up region opt-in status with a SigV4-signed EC2
+// DescribeRegions call against us-east-1, which is always enabled
+type RegionOptInChecker struct {
+	endpoint   string
+	awsConfig  aws.Config
+	httpClient *http.Client
+}
+
+// NewRegionOptInChecker creates a region opt-in checker
+func NewRegionOptInChecker(awsConfig aws.Config) *RegionOptInChecker {
+	return &RegionOptInChecker{
+		endpoint:  "https://ec2.us-east-1.amazonaws.com/",
+		awsConfig: awsConfig,
+		httpClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: proxy.Transport(awsConfig),
+		},
+	}
+}
+
+// describeRegionsResponse is the subset of the DescribeRegions response rosactl reads
+type describeRegionsResponse struct {
+	Regions []struct {
+		Name        string `xml:"regionName"`
+		OptInStatus string `xml:"optInStatus"`
+	} `xml:"regionInfo>item"`
+}
+
+// RegionOptInStatus returns the opt-in status of region for the calling account
+func (c *RegionOptInChecker) RegionOptInStatus(ctx context.Context, region string) (string, error) {
+	query := url.Values{}
+	query.Set("AllRegions", "true")
+	query.Set("RegionName.1", region)
+
+	parsed, err := c.describeRegions(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range parsed.Regions {
+		if r.Name == region {
+			return r.OptInStatus, nil
+		}
+	}
+	return "", fmt.Errorf("region %s not found", region)
+}
+
+// EnabledSupportedRegions returns the supported regions enabled for the calling
+// account, in the order of SupportedRegions
+func (c *RegionOptInChecker) EnabledSupportedRegions(ctx context.Context) ([]string, error) {
+	// Without AllRegions, only regions enabled for the account are returned
+	parsed, err := c.describeRegions(ctx, url.Values{})
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[string]bool, len(parsed.Regions))
+	for _, r := range parsed.Regions {
+		enabled[r.Name] = true
+	}
+
+	var regions []string
+	for _, region := range supportedRegions {
+		if enabled[region] {
+			regions = append(regions, region)
+		}
+	}
+	return regions, nil
+}
+
+// describeRegions makes a signed DescribeRegions call with query's parameters
+func (c *RegionOptInChecker) describeRegions(ctx context.Context, query url.Values) (*describeRegionsResponse, error) {
+	query.Set("Action", "DescribeRegions")
+	query.Set("Version", "2016-11-15")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.endpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DescribeRegions request: %w", err)
+	}
+
+	credentials, err := c.awsConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials for signing: %w", err)
+	}
+	payloadHash := fmt.Sprintf("%x", sha256.Sum256([]byte{}))
+	if err := v4.NewSigner().SignHTTP(ctx, credentials, req, payloadHash, "ec2", "us-east-1", time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign DescribeRegions request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("DescribeRegions failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read DescribeRegions response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("DescribeRegions returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var parsed describeRegionsResponse
+	if err := xml.Unmarshal(body, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse DescribeRegions response: %w", err)
+	}
+	return &parsed, nil
+}
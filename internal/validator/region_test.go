@@ -0,0 +1,159 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRegionOptInClient struct {
+	status string
+	err    error
+}
+
+func (m *mockRegionOptInClient) RegionOptInStatus(ctx context.Context, region string) (string, error) {
+	return m.status, m.err
+}
+
+func validCallerSTS() *mockSTSClient {
+	return &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput,
+			optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String("arn:aws:iam::123456789012:user/test-user"),
+			}, nil
+		},
+	}
+}
+
+func TestValidate_OptInRegion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("enabled", func(t *testing.T) {
+		validator := NewAWSValidator(validCallerSTS(), "af-south-1",
+			WithRegionOptInChecker(&mockRegionOptInClient{status: RegionOptedIn}))
+		result, err := validator.Validate(ctx)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, CheckFailed, result.Checks.Status())
+		assert.Equal(t, CheckPassed, result.Checks[1].Status)
+		assert.Equal(t, "af-south-1 (opted-in)", result.Checks[1].Detail)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		validator := NewAWSValidator(validCallerSTS(), "me-central-1",
+			WithRegionOptInChecker(&mockRegionOptInClient{status: RegionNotOptedIn}))
+		result, err := validator.Validate(ctx)
+
+		assert.Error(t, err)
+		assert.Equal(t, CheckFailed, result.Checks.Status())
+		assert.Contains(t, failure(t, result.Checks).Detail, "opt-in region that is not enabled")
+		assert.Equal(t, RemediationAWSRegionOptIn, failure(t, result.Checks).Remediation)
+		assert.Equal(t, CheckFailed, result.Checks[1].Status)
+	})
+
+	t.Run("lookup fails", func(t *testing.T) {
+		validator := NewAWSValidator(validCallerSTS(), "eu-south-1",
+			WithRegionOptInChecker(&mockRegionOptInClient{err: errors.New("access denied")}))
+		result, err := validator.Validate(ctx)
+
+		require.NoError(t, err)
+		assert.NotEqual(t, CheckFailed, result.Checks.Status())
+		assert.Equal(t, CheckWarning, result.Checks[1].Status)
+		require.Len(t, result.Checks.Warnings(), 1)
+		assert.Contains(t, result.Checks.Warnings()[0].Detail, "access denied")
+	})
+
+	t.Run("unsupported opt-in region", func(t *testing.T) {
+		validator := NewAWSValidator(validCallerSTS(), "il-central-1",
+			WithRegionOptInChecker(&mockRegionOptInClient{status: RegionOptedIn}))
+		result, err := validator.Validate(ctx)
+
+		assert.Error(t, err)
+		assert.Contains(t, failure(t, result.Checks).Detail, "not supported by ROSA regional HCP")
+		assert.Equal(t, RemediationAWSRegionSupport, failure(t, result.Checks).Remediation)
+	})
+}
+
+func TestValidate_CredentialsRejectedInDisabledRegion(t *testing.T) {
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput,
+			optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return nil, errors.New("InvalidClientTokenId: The security token included in the request is invalid")
+		},
+	}
+
+	validator := NewAWSValidator(mockSTS, "ap-east-1",
+		WithRegionOptInChecker(&mockRegionOptInClient{status: RegionNotOptedIn}))
+	result, err := validator.Validate(context.Background())
+
+	assert.Error(t, err)
+	assert.Equal(t, RemediationAWSRegionOptIn, failure(t, result.Checks).Remediation)
+	assert.Contains(t, failure(t, result.Checks).Detail, "aws account enable-region --region-name ap-east-1")
+}
+
+func TestRegionOptInChecker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DescribeRegions", r.URL.Query().Get("Action"))
+		assert.Equal(t, "true", r.URL.Query().Get("AllRegions"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-1/ec2/")
+
+		if r.URL.Query().Get("RegionName.1") == "missing-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`<Response><Errors><Error><Code>InvalidParameterValue</Code></Error></Errors></Response>`))
+			return
+		}
+		w.Write([]byte(`<DescribeRegionsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <requestId>59dbff89-35bd-4eac-99ed-be587EXAMPLE</requestId>
+  <regionInfo>
+    <item>
+      <regionName>af-south-1</regionName>
+      <regionEndpoint>ec2.af-south-1.amazonaws.com</regionEndpoint>
+      <optInStatus>not-opted-in</optInStatus>
+    </item>
+  </regionInfo>
+</DescribeRegionsResponse>`))
+	}))
+	defer server.Close()
+
+	checker := NewRegionOptInChecker(createTestAWSConfig())
+	checker.endpoint = server.URL + "/"
+
+	status, err := checker.RegionOptInStatus(context.Background(), "af-south-1")
+	require.NoError(t, err)
+	assert.Equal(t, RegionNotOptedIn, status)
+
+	_, err = checker.RegionOptInStatus(context.Background(), "missing-1")
+	assert.ErrorContains(t, err, "status 400")
+}
+
+func TestRegionOptInChecker_EnabledSupportedRegions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DescribeRegions", r.URL.Query().Get("Action"))
+		assert.Empty(t, r.URL.Query().Get("AllRegions"), "only enabled regions are listed")
+
+		w.Write([]byte(`<DescribeRegionsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/">
+  <regionInfo>
+    <item><regionName>us-west-2</regionName><optInStatus>opt-in-not-required</optInStatus></item>
+    <item><regionName>il-central-1</regionName><optInStatus>opted-in</optInStatus></item>
+    <item><regionName>us-east-1</regionName><optInStatus>opt-in-not-required</optInStatus></item>
+  </regionInfo>
+</DescribeRegionsResponse>`))
+	}))
+	defer server.Close()
+
+	checker := NewRegionOptInChecker(createTestAWSConfig())
+	checker.endpoint = server.URL + "/"
+
+	regions, err := checker.EnabledSupportedRegions(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"us-east-1", "us-west-2"}, regions)
+}
@@ -0,0 +1,300 @@
+// Package deploy is a small resource engine. Each cloud resource a deployment owns
+// implements Resource, and an Engine applies, plans, checks for drift, and tears
+// down an ordered set of them uniformly.
+package deploy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the engine's spans
+const tracerName = "github.com/openshift-online/regional-cli/pkg/deploy"
+
+// Attributes recorded on resource spans
+const (
+	ResourceTypeKey = attribute.Key("rosactl.resource.type")
+	ResourceIDKey   = attribute.Key("rosactl.resource.id")
+)
+
+// Actions reported by Resource.Ensure
+const (
+	ActionCreated   = "created"
+	ActionUpdated   = "updated"
+	ActionAdopted   = "adopted"
+	ActionUnchanged = "unchanged"
+)
+
+// Ref identifies a resource
+type Ref struct {
+	Type string
+	ID   string
+}
+
+func (r Ref) String() string {
+	return fmt.Sprintf("%s %s", r.Type, r.ID)
+}
+
+// Change is one field that differs between the desired and actual state
+type Change struct {
+	Field   string
+	Current string
+	Desired string
+}
+
+// Diff compares a resource's desired state with what exists in the account
+type Diff struct {
+	Ref     Ref
+	Exists  bool
+	Managed bool // Whether the existing resource carries the ownership marker
+	Changes []Change
+}
+
+// InSync reports whether the resource exists and matches its desired state
+func (d *Diff) InSync() bool {
+	return d.Exists && len(d.Changes) == 0
+}
+
+// Resource is a single cloud resource managed by a deployment
+type Resource interface {
+	// Ref identifies the resource
+	Ref() Ref
+	// Ensure creates the resource or converges it to its desired state and reports what it did
+	Ensure(ctx context.Context) (string, error)
+	// Diff compares the desired state with the account without changing anything
+	Diff(ctx context.Context) (*Diff, error)
+	// Delete removes the resource. Deleting a resource that does not exist is not an error.
+	Delete(ctx context.Context) error
+}
+
+// Applied records the action Ensure took on a resource
+type Applied struct {
+	Ref    Ref
+	Action string
+}
+
+// ApplyError is returned when Apply fails. Resources created before the failure
+// are rolled back unless rollback is disabled; anything left is listed in Remaining.
+type ApplyError struct {
+	Ref            Ref // Resource that failed
+	Err            error
+	RolledBack     []Ref
+	Remaining      []Ref
+	RollbackErrors []error
+}
+
+func (e *ApplyError) Error() string {
+	msg := fmt.Sprintf("failed to ensure %s: %v", e.Ref, e.Err)
+	if len(e.Remaining) == 0 {
+		return msg
+	}
+
+	remaining := make([]string, 0, len(e.Remaining))
+	for _, r := range e.Remaining {
+		remaining = append(remaining, r.String())
+	}
+	return fmt.Sprintf("%s (created resources remain: %s)", msg, strings.Join(remaining, ", "))
+}
+
+func (e *ApplyError) Unwrap() error {
+	return e.Err
+}
+
+// UnmanagedError is returned when teardown finds resources it does not own
+type UnmanagedError struct {
+	Refs []Ref
+}
+
+func (e *UnmanagedError) Error() string {
+	refs := make([]string, 0, len(e.Refs))
+	for _, r := range e.Refs {
+		refs = append(refs, r.String())
+	}
+	return fmt.Sprintf("refusing to delete resources not managed by rosactl: %s", strings.Join(refs, ", "))
+}
+
+// Engine applies an ordered set of resources. Resources are ensured in order and
+// deleted in reverse order, so dependencies must come before their dependents.
+type Engine struct {
+	resources  []Resource
+	noRollback bool
+	tracer     trace.Tracer
+}
+
+// EngineOption configures optional Engine behavior
+type EngineOption func(*Engine)
+
+// WithNoRollback leaves resources created by a failed Apply in place
+func WithNoRollback() EngineOption {
+	return func(e *Engine) {
+		e.noRollback = true
+	}
+}
+
+// WithTracerProvider records a span for each resource the engine diffs, ensures, or
+// deletes; by default spans go to the global OpenTelemetry tracer provider
+func WithTracerProvider(tp trace.TracerProvider) EngineOption {
+	return func(e *Engine) {
+		e.tracer = tp.Tracer(tracerName)
+	}
+}
+
+// NewEngine creates an engine for resources, in dependency order
+func NewEngine(resources []Resource, opts ...EngineOption) *Engine {
+	e := &Engine{resources: resources, tracer: otel.Tracer(tracerName)}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Apply ensures every resource in order. When a resource fails, resources created
+// by this call are deleted newest first and a *ApplyError is returned.
+func (e *Engine) Apply(ctx context.Context) ([]Applied, error) {
+	var applied []Applied
+	var created []Resource
+
+	for _, r := range e.resources {
+		if err := ctx.Err(); err != nil {
+			return applied, e.rollback(ctx, created, r.Ref(), err)
+		}
+
+		var action string
+		err := e.traced(ctx, "ensure", r, func(ctx context.Context) error {
+			var err error
+			action, err = r.Ensure(ctx)
+			return err
+		})
+		if err != nil {
+			return applied, e.rollback(ctx, created, r.Ref(), err)
+		}
+
+		applied = append(applied, Applied{Ref: r.Ref(), Action: action})
+		if action == ActionCreated {
+			created = append(created, r)
+		}
+	}
+
+	return applied, nil
+}
+
+// rollback deletes created resources newest first and describes what remains
+func (e *Engine) rollback(ctx context.Context, created []Resource, failed Ref, cause error) error {
+	applyErr := &ApplyError{Ref: failed, Err: cause}
+
+	// Roll back even if the apply was interrupted
+	ctx = context.WithoutCancel(ctx)
+
+	for i := len(created) - 1; i >= 0; i-- {
+		r := created[i]
+		if e.noRollback {
+			applyErr.Remaining = append(applyErr.Remaining, r.Ref())
+			continue
+		}
+		if err := e.traced(ctx, "delete", r, r.Delete); err != nil {
+			applyErr.Remaining = append(applyErr.Remaining, r.Ref())
+			applyErr.RollbackErrors = append(applyErr.RollbackErrors, fmt.Errorf("failed to delete %s: %w", r.Ref(), err))
+			continue
+		}
+		applyErr.RolledBack = append(applyErr.RolledBack, r.Ref())
+	}
+
+	return applyErr
+}
+
+// Plan diffs every resource without changing anything, for dry runs
+func (e *Engine) Plan(ctx context.Context) ([]Diff, error) {
+	diffs := make([]Diff, 0, len(e.resources))
+	for _, r := range e.resources {
+		var diff *Diff
+		err := e.traced(ctx, "diff", r, func(ctx context.Context) error {
+			var err error
+			diff, err = r.Diff(ctx)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", r.Ref(), err)
+		}
+		diffs = append(diffs, *diff)
+	}
+	return diffs, nil
+}
+
+// Drift returns the diffs of resources that are missing or differ from their desired state
+func (e *Engine) Drift(ctx context.Context) ([]Diff, error) {
+	diffs, err := e.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []Diff
+	for _, diff := range diffs {
+		if !diff.InSync() {
+			drifted = append(drifted, diff)
+		}
+	}
+	return drifted, nil
+}
+
+// Teardown deletes existing resources in reverse order and returns the ones it deleted.
+// Nothing is deleted if any existing resource is not managed; a *UnmanagedError lists them.
+// Deletion continues past failures, which are joined into the returned error.
+func (e *Engine) Teardown(ctx context.Context) ([]Ref, error) {
+	diffs, err := e.Plan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var unmanaged []Ref
+	for _, diff := range diffs {
+		if diff.Exists && !diff.Managed {
+			unmanaged = append(unmanaged, diff.Ref)
+		}
+	}
+	if len(unmanaged) > 0 {
+		return nil, &UnmanagedError{Refs: unmanaged}
+	}
+
+	var deleted []Ref
+	var errs []error
+	for i := len(e.resources) - 1; i >= 0; i-- {
+		if !diffs[i].Exists {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		r := e.resources[i]
+		if err := e.traced(ctx, "delete", r, r.Delete); err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s: %w", r.Ref(), err))
+			continue
+		}
+		deleted = append(deleted, r.Ref())
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+// traced runs fn, an operation on r, in a span named for the operation and resource type
+func (e *Engine) traced(ctx context.Context, operation string, r Resource, fn func(context.Context) error) error {
+	ref := r.Ref()
+	ctx, span := e.tracer.Start(ctx, operation+" "+ref.Type,
+		trace.WithAttributes(ResourceTypeKey.String(ref.Type), ResourceIDKey.String(ref.ID)))
+	defer span.End()
+
+	err := fn(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package deploy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// fakeResource records the calls made to it; log is shared so ordering across resources can be checked
+type fakeResource struct {
+	ref       Ref
+	action    string
+	ensureErr error
+	deleteErr error
+	diff      Diff
+	log       *[]string
+}
+
+func (f *fakeResource) Ref() Ref {
+	return f.ref
+}
+
+func (f *fakeResource) Ensure(ctx context.Context) (string, error) {
+	*f.log = append(*f.log, "ensure "+f.ref.ID)
+	return f.action, f.ensureErr
+}
+
+func (f *fakeResource) Diff(ctx context.Context) (*Diff, error) {
+	diff := f.diff
+	diff.Ref = f.ref
+	return &diff, nil
+}
+
+func (f *fakeResource) Delete(ctx context.Context) error {
+	*f.log = append(*f.log, "delete "+f.ref.ID)
+	return f.deleteErr
+}
+
+func newFake(log *[]string, id, action string) *fakeResource {
+	return &fakeResource{
+		ref:    Ref{Type: "fake", ID: id},
+		action: action,
+		diff:   Diff{Exists: true, Managed: true},
+		log:    log,
+	}
+}
+
+func TestApply(t *testing.T) {
+	var log []string
+	engine := NewEngine([]Resource{
+		newFake(&log, "role", ActionCreated),
+		newFake(&log, "function", ActionUpdated),
+	})
+
+	applied, err := engine.Apply(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Applied{
+		{Ref: Ref{Type: "fake", ID: "role"}, Action: ActionCreated},
+		{Ref: Ref{Type: "fake", ID: "function"}, Action: ActionUpdated},
+	}, applied)
+	assert.Equal(t, []string{"ensure role", "ensure function"}, log)
+}
+
+func TestApply_RollsBackCreatedResources(t *testing.T) {
+	var log []string
+	failing := newFake(&log, "log-group", "")
+	failing.ensureErr = errors.New("boom")
+
+	engine := NewEngine([]Resource{
+		newFake(&log, "role", ActionCreated),
+		newFake(&log, "existing", ActionUnchanged),
+		newFake(&log, "function", ActionCreated),
+		failing,
+	})
+
+	_, err := engine.Apply(context.Background())
+	var applyErr *ApplyError
+	require.ErrorAs(t, err, &applyErr)
+	assert.Equal(t, "log-group", applyErr.Ref.ID)
+	assert.Equal(t, []Ref{{Type: "fake", ID: "function"}, {Type: "fake", ID: "role"}}, applyErr.RolledBack)
+	assert.Empty(t, applyErr.Remaining)
+	assert.Equal(t, []string{"ensure role", "ensure existing", "ensure function", "ensure log-group", "delete function", "delete role"}, log)
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestApply_NoRollback(t *testing.T) {
+	var log []string
+	failing := newFake(&log, "function", "")
+	failing.ensureErr = errors.New("boom")
+
+	engine := NewEngine([]Resource{newFake(&log, "role", ActionCreated), failing}, WithNoRollback())
+
+	_, err := engine.Apply(context.Background())
+	var applyErr *ApplyError
+	require.ErrorAs(t, err, &applyErr)
+	assert.Equal(t, []Ref{{Type: "fake", ID: "role"}}, applyErr.Remaining)
+	assert.NotContains(t, log, "delete role")
+	assert.Contains(t, err.Error(), "created resources remain: fake role")
+}
+
+func TestApply_Cancelled(t *testing.T) {
+	var log []string
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewEngine([]Resource{newFake(&log, "role", ActionCreated)}).Apply(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, log)
+}
+
+func TestApply_Traced(t *testing.T) {
+	var log []string
+	failing := newFake(&log, "function", "")
+	failing.ensureErr = errors.New("boom")
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	engine := NewEngine([]Resource{newFake(&log, "role", ActionCreated), failing}, WithTracerProvider(tp))
+
+	_, err := engine.Apply(context.Background())
+	require.Error(t, err)
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"ensure fake", "ensure fake", "delete fake"}, names)
+
+	failed := recorder.Ended()[1]
+	assert.Equal(t, codes.Error, failed.Status().Code)
+	assert.Contains(t, failed.Attributes(), ResourceIDKey.String("function"))
+}
+
+func TestPlanAndDrift(t *testing.T) {
+	var log []string
+	missing := newFake(&log, "function", "")
+	missing.diff = Diff{}
+	changed := newFake(&log, "log-group", "")
+	changed.diff = Diff{Exists: true, Managed: true, Changes: []Change{{Field: "retention days", Current: "7", Desired: "90"}}}
+
+	engine := NewEngine([]Resource{newFake(&log, "role", ""), missing, changed})
+
+	diffs, err := engine.Plan(context.Background())
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	assert.True(t, diffs[0].InSync())
+	assert.False(t, diffs[1].Exists)
+
+	drifted, err := engine.Drift(context.Background())
+	require.NoError(t, err)
+	require.Len(t, drifted, 2)
+	assert.Equal(t, "function", drifted[0].Ref.ID)
+	assert.Equal(t, "log-group", drifted[1].Ref.ID)
+	assert.Empty(t, log, "planning must not change anything")
+}
+
+func TestTeardown(t *testing.T) {
+	var log []string
+	missing := newFake(&log, "policy", "")
+	missing.diff = Diff{}
+
+	engine := NewEngine([]Resource{
+		newFake(&log, "role", ""),
+		newFake(&log, "function", ""),
+		missing,
+		newFake(&log, "log-group", ""),
+	})
+
+	deleted, err := engine.Teardown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []Ref{{Type: "fake", ID: "log-group"}, {Type: "fake", ID: "function"}, {Type: "fake", ID: "role"}}, deleted)
+	assert.Equal(t, []string{"delete log-group", "delete function", "delete role"}, log)
+}
+
+func TestTeardown_RefusesUnmanaged(t *testing.T) {
+	var log []string
+	unmanaged := newFake(&log, "role", "")
+	unmanaged.diff = Diff{Exists: true}
+
+	_, err := NewEngine([]Resource{unmanaged, newFake(&log, "function", "")}).Teardown(context.Background())
+	var unmanagedErr *UnmanagedError
+	require.ErrorAs(t, err, &unmanagedErr)
+	assert.Equal(t, []Ref{{Type: "fake", ID: "role"}}, unmanagedErr.Refs)
+	assert.Empty(t, log)
+}
+
+func TestTeardown_ContinuesPastFailures(t *testing.T) {
+	var log []string
+	failing := newFake(&log, "function", "")
+	failing.deleteErr = errors.New("access denied")
+
+	deleted, err := NewEngine([]Resource{newFake(&log, "role", ""), failing}).Teardown(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete fake function: access denied")
+	assert.Equal(t, []Ref{{Type: "fake", ID: "role"}}, deleted)
+}
@@ -0,0 +1,422 @@
+// Package deployer deploys the ROSA OIDC provisioner Lambda into an AWS account. It is
+// the supported way for other tools to run the deployment rosactl setup-account
+// performs: it builds its AWS clients from an aws.Config and is configured with
+// functional options, so it can be embedded without the rosactl CLI.
+//
+// This package, pkg/oidc, and pkg/platform are the stable Go API of this module and
+// follow semantic versioning; see the README's "Go SDK" section. Other packages under
+// pkg/ are implementation details and may change in any release.
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go/middleware"
+	lambdadeployer "github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/openshift-online/regional-cli/pkg/telemetry"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Defaults applied when the corresponding option is not given
+const (
+	DefaultFunctionName      = lambdadeployer.DefaultFunctionName
+	DefaultExecutionRoleName = lambdadeployer.DefaultExecutionRoleName
+	DefaultMemorySize        = lambdadeployer.DefaultMemorySize // MB
+	DefaultTimeout           = lambdadeployer.DefaultTimeout * time.Second
+)
+
+// DefaultLedgerTableName is the ledger table rosactl oidc history reads by default
+const DefaultLedgerTableName = ledger.DefaultTableName
+
+// Errors returned by Deploy may wrap these; use errors.As to inspect them
+type (
+	// PartialFailureError describes the resources a failed deployment created, and
+	// which of them were rolled back
+	PartialFailureError = lambdadeployer.PartialFailureError
+
+	// CancelledError is returned when the context is cancelled mid-deployment
+	CancelledError = lambdadeployer.CancelledError
+
+	// NameValidationError lists every function, role, or log group name AWS would
+	// reject; New returns it before anything is deployed
+	NameValidationError = lambdadeployer.NameValidationError
+
+	// UnmanagedResourceError is returned when a resource exists but was not created
+	// by rosactl; use WithAdopt to take ownership of it
+	UnmanagedResourceError = lambdadeployer.UnmanagedResourceError
+
+	// TagPolicyAPIError is returned when AWS rejects a tag under the account's tag
+	// policy; use WithTagKeyFormat when it names one of rosactl's own keys
+	TagPolicyAPIError = lambdadeployer.TagPolicyAPIError
+)
+
+// Failure categories errors returned by Deploy may wrap; use errors.Is to branch on them
+var (
+	// ErrRoleCreationDenied is wrapped when IAM denies creating the execution role
+	ErrRoleCreationDenied = lambdadeployer.ErrRoleCreationDenied
+
+	// ErrPackageTooLarge is wrapped when the package exceeds Lambda's size limits
+	ErrPackageTooLarge = lambdadeployer.ErrPackageTooLarge
+
+	// ErrFunctionConflict is wrapped when the function already exists or another
+	// update to it is in progress
+	ErrFunctionConflict = lambdadeployer.ErrFunctionConflict
+
+	// ErrIAMPropagationTimeout is wrapped when a new execution role does not become
+	// assumable by Lambda in time
+	ErrIAMPropagationTimeout = lambdadeployer.ErrIAMPropagationTimeout
+
+	// ErrCodeSigning is wrapped when Lambda code signing rejects the deployment, such
+	// as when the account requires functions to use a code signing config
+	ErrCodeSigning = lambdadeployer.ErrCodeSigning
+)
+
+// Result describes a completed deployment
+type Result struct {
+	FunctionARN      string
+	FunctionName     string
+	ExecutionRoleARN string
+	LogGroupName     string
+	Status           string // "created", "updated", or "already_exists"
+	Version          string // Published version, empty unless WithPublishVersion is used
+	PackageChecksum  string // SHA-256 of the deployed package
+	DeployedAt       time.Time
+
+	// The account deployed into. AccountAlias is empty when the account has no alias
+	// or the caller may not call iam:ListAccountAliases.
+	AccountID    string
+	AccountAlias string
+	Region       string
+	Partition    string // Such as aws, aws-cn, or aws-us-gov
+
+	// PrimeLatency is the round trip of the priming invocation, zero unless WithPrime
+	// is used and the invocation succeeded
+	PrimeLatency time.Duration
+}
+
+// Option configures a Deployer
+type Option func(*options)
+
+type options struct {
+	config         lambdadeployer.DeploymentConfig
+	runtime        string
+	timeout        time.Duration
+	warningOutput  io.Writer
+	tracerProvider trace.TracerProvider
+}
+
+// WithFunctionName sets the Lambda function name (default DefaultFunctionName)
+func WithFunctionName(name string) Option {
+	return func(o *options) {
+		o.config.FunctionName = name
+	}
+}
+
+// WithExecutionRoleName sets the function's IAM role name (default
+// DefaultExecutionRoleName)
+func WithExecutionRoleName(name string) Option {
+	return func(o *options) {
+		o.config.ExecutionRoleName = name
+	}
+}
+
+// WithLogGroupName sets the log group the function writes to, for organizations with
+// log naming conventions (default /aws/lambda/<function name>)
+func WithLogGroupName(name string) Option {
+	return func(o *options) {
+		o.config.LogGroupName = name
+	}
+}
+
+// WithCLMServiceRoleARN allows the CLM service role to invoke the function.
+// sourceAccountID restricts invocations to that account; if empty, the deploying
+// account is used.
+func WithCLMServiceRoleARN(roleARN, sourceAccountID string) Option {
+	return func(o *options) {
+		o.config.CLMServiceRoleARN = roleARN
+		o.config.SourceAccountID = sourceAccountID
+	}
+}
+
+// WithSourceDir builds the function from dir instead of the provisioner source
+// shipped with this module
+func WithSourceDir(dir string) Option {
+	return func(o *options) {
+		o.config.SourceDir = dir
+	}
+}
+
+// WithPackageCacheDir reuses the package built from the same source by an earlier
+// deployment, kept in dir, instead of compiling it again, so deploying to many regions
+// or accounts compiles once. Packages built are added to dir.
+func WithPackageCacheDir(dir string) Option {
+	return func(o *options) {
+		o.config.PackageCacheDir = dir
+	}
+}
+
+// WithLedgerTable records every provisioning request the function serves in the
+// DynamoDB table named name, such as DefaultLedgerTableName, creating it when missing
+// and granting the execution role dynamodb:PutItem on it
+func WithLedgerTable(name string) Option {
+	return func(o *options) {
+		o.config.LedgerTableName = name
+	}
+}
+
+// WithRuntime sets the Lambda runtime, such as "provided.al2023"; by default the
+// newest runtime available in the config's region is used
+func WithRuntime(name string) Option {
+	return func(o *options) {
+		o.runtime = name
+	}
+}
+
+// WithMemorySize sets the function's memory in MB (default DefaultMemorySize)
+func WithMemorySize(mb int32) Option {
+	return func(o *options) {
+		o.config.MemorySize = mb
+	}
+}
+
+// WithTimeout sets the function's timeout, in whole seconds (default DefaultTimeout)
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithTags adds tags to every deployed resource
+func WithTags(tags map[string]string) Option {
+	return func(o *options) {
+		if o.config.Tags == nil {
+			o.config.Tags = make(map[string]string, len(tags))
+		}
+		for key, value := range tags {
+			o.config.Tags[key] = value
+		}
+	}
+}
+
+// WithTagKeyFormat writes the keys of the tags rosactl sets, such as rosa:managed, in
+// format, for tag policies that require a different prefix or case
+func WithTagKeyFormat(format tagkey.Format) Option {
+	return func(o *options) {
+		o.config.TagKeyFormat = format
+	}
+}
+
+// WithPublishVersion publishes an immutable version after each deployment and keeps
+// the newest keep versions; 0 keeps them all
+func WithPublishVersion(keep int) Option {
+	return func(o *options) {
+		o.config.PublishVersion = true
+		o.config.KeepVersions = keep
+	}
+}
+
+// WithAdopt takes ownership of a pre-existing function, role, and log group that
+// were not created by rosactl instead of failing with an UnmanagedResourceError
+func WithAdopt() Option {
+	return func(o *options) {
+		o.config.Adopt = true
+	}
+}
+
+// WithPrime invokes the function once after deploying, so the first provisioning
+// request does not wait for a cold start. A failed invocation is reported as a
+// warning and does not fail the deployment.
+func WithPrime() Option {
+	return func(o *options) {
+		o.config.Prime = true
+	}
+}
+
+// WithNoRollback leaves resources created by a failed deployment in place instead
+// of deleting them
+func WithNoRollback() Option {
+	return func(o *options) {
+		o.config.NoRollback = true
+	}
+}
+
+// WithWarningOutput sends non-fatal deployment warnings to w (default os.Stderr)
+func WithWarningOutput(w io.Writer) Option {
+	return func(o *options) {
+		o.warningOutput = w
+	}
+}
+
+// WithTracerProvider records OpenTelemetry spans with tp for each deployment, its
+// steps, and each AWS API call it makes. Without it, deployment and step spans go to
+// the global tracer provider and AWS calls are not traced, so instrumentation already
+// on cfg is not duplicated.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(o *options) {
+		o.tracerProvider = tp
+	}
+}
+
+// Deployer deploys the OIDC provisioner. Create one with New.
+type Deployer struct {
+	deployer *lambdadeployer.Deployer
+}
+
+// New creates a Deployer using cfg's credentials and region. Options and the source
+// directory are validated here; nothing is sent to AWS until Deploy.
+func New(cfg aws.Config, opts ...Option) (*Deployer, error) {
+	o, err := resolveOptions(cfg.Region, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployerOpts []lambdadeployer.DeployerOption
+	if o.warningOutput != nil {
+		deployerOpts = append(deployerOpts, lambdadeployer.WithWarningOutput(o.warningOutput))
+	}
+	if o.tracerProvider != nil {
+		// Copy the options so the caller's config is left untouched
+		cfg.APIOptions = append(append([]func(*middleware.Stack) error(nil), cfg.APIOptions...), telemetry.AWSMiddleware(o.tracerProvider))
+		deployerOpts = append(deployerOpts, lambdadeployer.WithTracerProvider(o.tracerProvider))
+	}
+	deployerOpts = append(deployerOpts, lambdadeployer.WithSTSClient(sts.NewFromConfig(cfg)))
+	if o.config.LedgerTableName != "" {
+		deployerOpts = append(deployerOpts, lambdadeployer.WithLedgerTableClient(dynamodb.NewFromConfig(cfg)))
+	}
+
+	d := &Deployer{
+		deployer: lambdadeployer.NewDeployer(lambda.NewFromConfig(cfg), iam.NewFromConfig(cfg),
+			cloudwatchlogs.NewFromConfig(cfg), o.config, deployerOpts...),
+	}
+	if err := d.deployer.ValidateTags(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// resolveOptions applies opts over the defaults for region and validates the result
+func resolveOptions(region string, opts []Option) (options, error) {
+	if region == "" {
+		return options{}, errors.New("AWS region is required")
+	}
+
+	o := options{
+		config: lambdadeployer.DeploymentConfig{
+			FunctionName:      DefaultFunctionName,
+			ExecutionRoleName: DefaultExecutionRoleName,
+			MemorySize:        DefaultMemorySize,
+			Region:            region,
+			Architecture:      lambdaTypes.ArchitectureX8664,
+		},
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.timeout%time.Second != 0 {
+		return options{}, fmt.Errorf("timeout must be a whole number of seconds, got %s", o.timeout)
+	}
+	o.config.Timeout = int32(o.timeout / time.Second)
+	if err := lambdadeployer.ValidateNames(o.config.FunctionName, o.config.ExecutionRoleName); err != nil {
+		return options{}, err
+	}
+	if o.config.LogGroupName != "" {
+		if err := lambdadeployer.ValidateLogGroupName(o.config.LogGroupName); err != nil {
+			return options{}, err
+		}
+	}
+	if err := lambdadeployer.ValidateFunctionLimits(o.config.MemorySize, o.config.Timeout); err != nil {
+		return options{}, err
+	}
+	if o.config.LedgerTableName != "" {
+		if err := ledger.ValidateTableName(o.config.LedgerTableName); err != nil {
+			return options{}, err
+		}
+	}
+
+	if o.runtime == "" {
+		o.config.Runtime = lambdadeployer.DefaultRuntime(region)
+	} else {
+		parsed, err := lambdadeployer.ParseRuntime(o.runtime)
+		if err != nil {
+			return options{}, err
+		}
+		if err := lambdadeployer.ValidateRuntimeAvailability(parsed, region); err != nil {
+			return options{}, err
+		}
+		o.config.Runtime = parsed
+	}
+
+	if o.config.SourceDir == "" {
+		o.config.SourceDir = bundledSourceDir()
+	}
+	sourceDir, err := lambdadeployer.ResolveSourceDir(o.config.SourceDir)
+	if err != nil {
+		return options{}, err
+	}
+	if err := lambdadeployer.ValidateSourceDir(sourceDir); err != nil {
+		return options{}, err
+	}
+	o.config.SourceDir = sourceDir
+	return o, nil
+}
+
+// Deploy builds the provisioner and creates or updates its function, execution role,
+// and log group. It needs the Go toolchain to compile the function. If it fails after
+// creating resources they are rolled back, unless WithNoRollback is used, and the
+// error wraps a *PartialFailureError.
+func (d *Deployer) Deploy(ctx context.Context) (*Result, error) {
+	result, err := d.deployer.Deploy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		FunctionARN:      result.FunctionARN,
+		FunctionName:     result.FunctionName,
+		ExecutionRoleARN: result.ExecutionRole,
+		LogGroupName:     result.LogGroupName,
+		Status:           result.Status,
+		Version:          result.Version,
+		PackageChecksum:  result.PackageChecksum,
+		DeployedAt:       result.DeployedAt,
+		AccountID:        result.AccountID,
+		AccountAlias:     result.AccountAlias,
+		Region:           result.Region,
+		Partition:        result.Partition,
+		PrimeLatency:     result.PrimeLatency,
+	}, nil
+}
+
+// PruneVersions deletes published versions older than the newest keep, except
+// versions an alias points to, and returns the deleted versions
+func (d *Deployer) PruneVersions(ctx context.Context, keep int) ([]string, error) {
+	return d.deployer.PruneVersions(ctx, keep)
+}
+
+// bundledSourceDir returns the provisioner source shipped with this module, which is
+// present in the module cache of programs importing it. It falls back to the path
+// relative to a regional-cli checkout when the source path is unknown, for example
+// in binaries built with -trimpath.
+func bundledSourceDir() string {
+	_, file, _, ok := runtime.Caller(0)
+	if !ok || !filepath.IsAbs(file) {
+		return lambdadeployer.DefaultSourceDir
+	}
+	return filepath.Join(filepath.Dir(file), "..", "..", lambdadeployer.DefaultSourceDir)
+}
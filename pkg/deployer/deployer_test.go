@@ -0,0 +1,114 @@
+package deployer
+
+import (
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveOptions_Defaults(t *testing.T) {
+	o, err := resolveOptions("us-east-1", nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, DefaultFunctionName, o.config.FunctionName)
+	assert.Equal(t, DefaultExecutionRoleName, o.config.ExecutionRoleName)
+	assert.Equal(t, int32(DefaultMemorySize), o.config.MemorySize)
+	assert.Equal(t, int32(60), o.config.Timeout)
+	assert.Equal(t, lambdaTypes.RuntimeProvidedal2023, o.config.Runtime)
+	assert.Equal(t, "us-east-1", o.config.Region)
+
+	// The provisioner source shipped with the module is found without a checkout-relative path
+	assert.True(t, filepath.IsAbs(o.config.SourceDir))
+	assert.FileExists(t, filepath.Join(o.config.SourceDir, "main.go"))
+}
+
+func TestResolveOptions(t *testing.T) {
+	o, err := resolveOptions("us-east-1", []Option{
+		WithFunctionName("custom"),
+		WithLogGroupName("/org/lambda/custom"),
+		WithCLMServiceRoleARN("arn:aws:iam::123456789012:role/clm", "123456789012"),
+		WithMemorySize(256),
+		WithTimeout(2 * time.Minute),
+		WithTags(map[string]string{"team": "sre"}),
+		WithTags(map[string]string{"env": "prod"}),
+		WithPublishVersion(3),
+		WithAdopt(),
+		WithPrime(),
+		WithPackageCacheDir("/tmp/packages"),
+		WithLedgerTable(DefaultLedgerTableName),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "custom", o.config.FunctionName)
+	assert.Equal(t, "/org/lambda/custom", o.config.LogGroupName)
+	assert.Equal(t, "123456789012", o.config.SourceAccountID)
+	assert.Equal(t, int32(256), o.config.MemorySize)
+	assert.Equal(t, int32(120), o.config.Timeout)
+	assert.Equal(t, map[string]string{"team": "sre", "env": "prod"}, o.config.Tags)
+	assert.True(t, o.config.PublishVersion)
+	assert.Equal(t, 3, o.config.KeepVersions)
+	assert.True(t, o.config.Adopt)
+	assert.True(t, o.config.Prime)
+	assert.Equal(t, "/tmp/packages", o.config.PackageCacheDir)
+	assert.Equal(t, "rosa-oidc-provisioner-ledger", o.config.LedgerTableName)
+	assert.False(t, o.config.NoRollback)
+}
+
+func TestResolveOptions_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+		opts   []Option
+		errMsg string
+	}{
+		{"no region", "", nil, "region is required"},
+		{"fractional timeout", "us-east-1", []Option{WithTimeout(1500 * time.Millisecond)}, "whole number of seconds"},
+		{"memory too small", "us-east-1", []Option{WithMemorySize(64)}, "memory"},
+		{"unknown runtime", "us-east-1", []Option{WithRuntime("nodejs20.x")}, "unsupported runtime"},
+		{"missing source", "us-east-1", []Option{WithSourceDir("/nonexistent/provisioner")}, "nonexistent"},
+		{"invalid names", "us-east-1", []Option{WithFunctionName("rosa.oidc"), WithExecutionRoleName("")}, "invalid resource names"},
+		{"invalid log group", "us-east-1", []Option{WithLogGroupName("aws/lambda/custom")}, "reserved for AWS services"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveOptions(tt.region, tt.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	d, err := New(aws.Config{Region: "us-west-2"}, WithTags(map[string]string{"team": "sre"}))
+	require.NoError(t, err)
+	assert.NotNil(t, d)
+
+	_, err = New(aws.Config{Region: "us-west-2"}, WithTags(map[string]string{"rosa:managed": "false"}))
+	assert.Error(t, err, "reserved tags are rejected before anything is deployed")
+}
+
+// TestStableAPIDependencies keeps the stable packages importable without the CLI
+func TestStableAPIDependencies(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go list")
+	}
+	out, err := exec.Command("go", "list", "-deps",
+		"github.com/openshift-online/regional-cli/pkg/deployer",
+		"github.com/openshift-online/regional-cli/pkg/oidc",
+		"github.com/openshift-online/regional-cli/pkg/platform",
+	).Output()
+	require.NoError(t, err)
+
+	for _, pkg := range strings.Fields(string(out)) {
+		assert.NotContains(t, pkg, "github.com/spf13/")
+		assert.NotContains(t, pkg, "github.com/openshift-online/regional-cli/internal/")
+	}
+}
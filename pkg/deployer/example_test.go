@@ -0,0 +1,40 @@
+package deployer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/openshift-online/regional-cli/pkg/deployer"
+)
+
+func ExampleNew() {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	d, err := deployer.New(cfg,
+		deployer.WithCLMServiceRoleARN("arn:aws:iam::123456789012:role/clm-service", ""),
+		deployer.WithTimeout(2*time.Minute),
+		deployer.WithTags(map[string]string{"team": "sre"}),
+		deployer.WithPublishVersion(5),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := d.Deploy(ctx)
+	var partial *deployer.PartialFailureError
+	if errors.As(err, &partial) {
+		log.Fatalf("deployment failed; %d resources remain: %v", len(partial.Remaining), err)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(result.FunctionARN, result.Version)
+}
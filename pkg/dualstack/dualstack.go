@@ -0,0 +1,154 @@
+// Package dualstack resolves dual-stack endpoints and dials them IPv6-first, so the
+// CLI keeps working on IPv6-only networks where IPv4 endpoints are unreachable.
+package dualstack
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+)
+
+const (
+	// DefaultFallbackDelay is how long an IPv6 connection attempt runs before IPv4 is
+	// tried in parallel (RFC 8305 recommends 250ms)
+	DefaultFallbackDelay = 300 * time.Millisecond
+
+	// DefaultDialTimeout bounds a single connection attempt
+	DefaultDialTimeout = 10 * time.Second
+)
+
+// executeAPIHost matches IPv4-only API Gateway hosts, capturing the host up to the
+// region and the partition DNS suffix
+var executeAPIHost = regexp.MustCompile(`^(.+\.execute-api\.[a-z0-9-]+)\.(amazonaws\.com|amazonaws\.com\.cn)$`)
+
+// dualStackSuffixes maps a partition's IPv4-only DNS suffix to its dual-stack suffix
+var dualStackSuffixes = map[string]string{
+	"amazonaws.com":    "api.aws",
+	"amazonaws.com.cn": "api.amazonwebservices.com.cn",
+}
+
+// EndpointURL rewrites an API Gateway URL to its dual-stack form, for example
+// https://abc123.execute-api.us-east-1.amazonaws.com to
+// https://abc123.execute-api.us-east-1.api.aws. Custom domains and URLs that are
+// already dual-stack are returned unchanged.
+func EndpointURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+
+	matches := executeAPIHost.FindStringSubmatch(u.Hostname())
+	if matches == nil {
+		return rawURL
+	}
+
+	host := matches[1] + "." + dualStackSuffixes[matches[2]]
+	if port := u.Port(); port != "" {
+		host = net.JoinHostPort(host, port)
+	}
+	u.Host = host
+	return u.String()
+}
+
+// Dialer connects over IPv6 first and falls back to IPv4 after FallbackDelay, racing
+// the two ("happy eyeballs"). Unlike net.Dialer, which prefers whichever family the
+// resolver lists first, the preference is explicit, so hosts on IPv6-only networks do
+// not wait on IPv4 attempts that can never succeed.
+type Dialer struct {
+	net.Dialer
+
+	// FallbackDelay is how long the IPv6 attempt runs before IPv4 is tried; an IPv6
+	// failure starts the IPv4 attempt immediately
+	FallbackDelay time.Duration
+}
+
+// NewDialer returns a Dialer with the default timeouts
+func NewDialer() *Dialer {
+	return &Dialer{
+		Dialer: net.Dialer{
+			Timeout:   DefaultDialTimeout,
+			KeepAlive: 30 * time.Second,
+		},
+		FallbackDelay: DefaultFallbackDelay,
+	}
+}
+
+// dialResult is the outcome of one address family's connection attempt
+type dialResult struct {
+	conn    net.Conn
+	err     error
+	primary bool
+}
+
+// DialContext connects to address. Networks other than "tcp" are dialed as requested.
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if network != "tcp" {
+		return d.Dialer.DialContext(ctx, network, address)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, 2)
+	dial := func(network string, primary bool) {
+		conn, err := d.Dialer.DialContext(ctx, network, address)
+		results <- dialResult{conn: conn, err: err, primary: primary}
+	}
+
+	go dial("tcp6", true)
+	pending := 1
+	fallback := time.NewTimer(d.FallbackDelay)
+	defer fallback.Stop()
+	fallbackStarted := false
+	startFallback := func() {
+		if !fallbackStarted {
+			fallbackStarted = true
+			pending++
+			go dial("tcp4", false)
+		}
+	}
+
+	var primaryErr, fallbackErr error
+	for {
+		select {
+		case <-fallback.C:
+			startFallback()
+
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				// Close a connection the losing attempt may still establish
+				go func(n int) {
+					for ; n > 0; n-- {
+						if late := <-results; late.conn != nil {
+							late.conn.Close()
+						}
+					}
+				}(pending)
+				return result.conn, nil
+			}
+
+			if result.primary {
+				primaryErr = result.err
+			} else {
+				fallbackErr = result.err
+			}
+			startFallback()
+			if pending == 0 {
+				return nil, errors.Join(primaryErr, fallbackErr)
+			}
+		}
+	}
+}
+
+// Transport returns an HTTP transport with the default transport's settings that
+// dials with NewDialer
+func Transport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = NewDialer().DialContext
+	return transport
+}
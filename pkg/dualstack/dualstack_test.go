@@ -0,0 +1,85 @@
+package dualstack
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{"commercial", "https://abc123.execute-api.us-east-1.amazonaws.com", "https://abc123.execute-api.us-east-1.api.aws"},
+		{"keeps path and port", "https://abc123.execute-api.eu-west-1.amazonaws.com:8443/prod", "https://abc123.execute-api.eu-west-1.api.aws:8443/prod"},
+		{"china", "https://abc123.execute-api.cn-north-1.amazonaws.com.cn", "https://abc123.execute-api.cn-north-1.api.amazonwebservices.com.cn"},
+		{"already dual-stack", "https://abc123.execute-api.us-east-1.api.aws", "https://abc123.execute-api.us-east-1.api.aws"},
+		{"custom domain", "https://api.example.com", "https://api.example.com"},
+		{"not a URL", "::", "::"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EndpointURL(tt.url))
+		})
+	}
+}
+
+// listen accepts connections on a loopback address of the given network until the test ends
+func listen(t *testing.T, network, address string) string {
+	t.Helper()
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		t.Skipf("%s loopback unavailable: %v", network, err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func TestDialer_FallsBackToIPv4(t *testing.T) {
+	address := listen(t, "tcp4", "127.0.0.1:0")
+
+	d := NewDialer()
+	d.FallbackDelay = time.Hour // The IPv6 failure must start IPv4 without waiting
+	conn, err := d.DialContext(context.Background(), "tcp", address)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, address, conn.RemoteAddr().String())
+}
+
+func TestDialer_PrefersIPv6(t *testing.T) {
+	address := listen(t, "tcp6", "[::1]:0")
+
+	conn, err := NewDialer().DialContext(context.Background(), "tcp", address)
+	require.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, address, conn.RemoteAddr().String())
+}
+
+func TestDialer_BothFamiliesFail(t *testing.T) {
+	// Reserve a port and close it so connections are refused
+	listener, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = NewDialer().DialContext(context.Background(), "tcp", address)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tcp4")
+	assert.Contains(t, err.Error(), "tcp6")
+}
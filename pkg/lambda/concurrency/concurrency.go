@@ -0,0 +1,198 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+)
+
+const (
+	// MinimumUnreserved is the concurrency Lambda keeps unreserved for functions
+	// without a reservation; no reservation may reduce the pool below it
+	MinimumUnreserved = 100
+
+	// DefaultHeadroom is added to the estimated concurrency so retries and uneven
+	// arrival do not throttle the run
+	DefaultHeadroom = 0.2
+)
+
+// LambdaAPI defines the Lambda operations needed to check and reserve concurrency
+type LambdaAPI interface {
+	GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error)
+	GetFunctionConcurrency(ctx context.Context, params *lambda.GetFunctionConcurrencyInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetFunctionConcurrencyOutput, error)
+	PutFunctionConcurrency(ctx context.Context, params *lambda.PutFunctionConcurrencyInput,
+		optFns ...func(*lambda.Options)) (*lambda.PutFunctionConcurrencyOutput, error)
+}
+
+// Burst describes an expected run of provisioning requests
+type Burst struct {
+	Invocations int           // Requests expected during the run
+	Window      time.Duration // How long the run takes; zero means all requests arrive at once
+	Duration    time.Duration // Expected duration of a single invocation
+}
+
+// Required estimates the concurrency a burst needs: the requests in flight at once
+// (invocations x duration / window) plus DefaultHeadroom, at least one and at most
+// the number of invocations
+func (b Burst) Required() int32 {
+	if b.Invocations <= 0 {
+		return 0
+	}
+	inFlight := float64(b.Invocations)
+	if b.Window > 0 && b.Duration > 0 && b.Duration < b.Window {
+		inFlight = float64(b.Invocations) * b.Duration.Seconds() / b.Window.Seconds()
+	}
+	required := math.Ceil(inFlight * (1 + DefaultHeadroom))
+	if required > float64(b.Invocations) {
+		required = float64(b.Invocations)
+	}
+	if required < 1 {
+		required = 1
+	}
+	return int32(required)
+}
+
+// Check is a single evaluated preflight condition
+type Check struct {
+	Name    string
+	Value   string
+	Healthy bool
+	Detail  string // What to do about it, set when the check is unhealthy
+}
+
+// Preflight is the concurrency available to a function for a burst
+type Preflight struct {
+	AccountLimit int32  // Account concurrent executions quota
+	Unreserved   int32  // Account concurrency not reserved by any function
+	Reserved     *int32 // The function's reservation, nil if it has none
+	Required     int32  // Concurrency the burst needs
+	Available    int32  // Highest reservation the function could be given
+	Throttles    float64
+	Checks       []Check
+}
+
+// Recommended returns the reservation to apply, or zero if the current one suffices
+// or the account cannot provide the required concurrency
+func (p *Preflight) Recommended() int32 {
+	if p.Reserved != nil && *p.Reserved >= p.Required {
+		return 0
+	}
+	if p.Required > p.Available {
+		return 0
+	}
+	return p.Required
+}
+
+// Healthy reports whether every check passed
+func (p *Preflight) Healthy() bool {
+	for _, check := range p.Checks {
+		if !check.Healthy {
+			return false
+		}
+	}
+	return true
+}
+
+// Run checks whether the function can absorb the burst. throttles is the number of
+// throttled invocations recently observed for the function.
+func Run(ctx context.Context, client LambdaAPI, functionName string, burst Burst, throttles float64) (*Preflight, error) {
+	if burst.Invocations <= 0 {
+		return nil, errors.New("expected invocations must be positive")
+	}
+
+	settings, err := client.GetAccountSettings(ctx, &lambda.GetAccountSettingsInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read account settings: %w", err)
+	}
+	if settings.AccountLimit == nil {
+		return nil, errors.New("account settings did not include concurrency limits")
+	}
+
+	function, err := client.GetFunctionConcurrency(ctx, &lambda.GetFunctionConcurrencyInput{
+		FunctionName: aws.String(functionName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read concurrency of %s: %w", functionName, err)
+	}
+
+	p := &Preflight{
+		AccountLimit: settings.AccountLimit.ConcurrentExecutions,
+		Unreserved:   aws.ToInt32(settings.AccountLimit.UnreservedConcurrentExecutions),
+		Reserved:     function.ReservedConcurrentExecutions,
+		Required:     burst.Required(),
+		Throttles:    throttles,
+	}
+
+	// The function's own reservation is returned to the pool when it is replaced
+	p.Available = p.Unreserved - MinimumUnreserved
+	if p.Reserved != nil {
+		p.Available += *p.Reserved
+	}
+	if p.Available < 0 {
+		p.Available = 0
+	}
+
+	p.Checks = p.evaluate()
+	return p, nil
+}
+
+// evaluate builds the preflight checks
+func (p *Preflight) evaluate() []Check {
+	checks := []Check{{
+		Name:    "Account concurrency",
+		Value:   fmt.Sprintf("%d of %d unreserved", p.Unreserved, p.AccountLimit),
+		Healthy: p.Required <= p.Available,
+		Detail: fmt.Sprintf("the burst needs %d but at most %d can be reserved while keeping %d unreserved; "+
+			"request a Lambda concurrent executions quota increase in Service Quotas", p.Required, p.Available, MinimumUnreserved),
+	}}
+
+	reservation := Check{Name: "Reserved concurrency", Healthy: true}
+	switch {
+	case p.Reserved == nil:
+		reservation.Value = "none (shares the unreserved pool)"
+		reservation.Healthy = false
+		reservation.Detail = fmt.Sprintf("other functions can use up the unreserved pool; reserve %d for the function", p.Required)
+	case *p.Reserved == 0:
+		reservation.Value = "0 (function is disabled)"
+		reservation.Healthy = false
+		reservation.Detail = "every invocation is throttled; reserve concurrency for the function"
+	default:
+		reservation.Value = fmt.Sprintf("%d", *p.Reserved)
+		reservation.Healthy = *p.Reserved >= p.Required
+		reservation.Detail = fmt.Sprintf("the burst needs %d", p.Required)
+	}
+	checks = append(checks, reservation)
+
+	checks = append(checks, Check{
+		Name:    "Recent throttles",
+		Value:   fmt.Sprintf("%.0f", p.Throttles),
+		Healthy: p.Throttles == 0,
+		Detail:  "the function is already being throttled",
+	})
+
+	for i := range checks {
+		if checks[i].Healthy {
+			checks[i].Detail = ""
+		}
+	}
+	return checks
+}
+
+// Apply reserves concurrency for the function
+func Apply(ctx context.Context, client LambdaAPI, functionName string, reserved int32) error {
+	_, err := client.PutFunctionConcurrency(ctx, &lambda.PutFunctionConcurrencyInput{
+		FunctionName:                 aws.String(functionName),
+		ReservedConcurrentExecutions: aws.Int32(reserved),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reserve concurrency for %s: %w", functionName, err)
+	}
+	return nil
+}
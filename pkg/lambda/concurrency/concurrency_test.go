@@ -0,0 +1,121 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLambdaClient struct {
+	limit      int32
+	unreserved int32
+	reserved   *int32
+	put        *lambda.PutFunctionConcurrencyInput
+	err        error
+}
+
+func (m *mockLambdaClient) GetAccountSettings(ctx context.Context, params *lambda.GetAccountSettingsInput, optFns ...func(*lambda.Options)) (*lambda.GetAccountSettingsOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &lambda.GetAccountSettingsOutput{AccountLimit: &types.AccountLimit{
+		ConcurrentExecutions:           m.limit,
+		UnreservedConcurrentExecutions: aws.Int32(m.unreserved),
+	}}, nil
+}
+
+func (m *mockLambdaClient) GetFunctionConcurrency(ctx context.Context, params *lambda.GetFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionConcurrencyOutput, error) {
+	return &lambda.GetFunctionConcurrencyOutput{ReservedConcurrentExecutions: m.reserved}, nil
+}
+
+func (m *mockLambdaClient) PutFunctionConcurrency(ctx context.Context, params *lambda.PutFunctionConcurrencyInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionConcurrencyOutput, error) {
+	m.put = params
+	return &lambda.PutFunctionConcurrencyOutput{}, nil
+}
+
+func checkByName(t *testing.T, p *Preflight, name string) Check {
+	t.Helper()
+	for _, check := range p.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("check %q not found", name)
+	return Check{}
+}
+
+func TestBurst_Required(t *testing.T) {
+	tests := []struct {
+		name     string
+		burst    Burst
+		expected int32
+	}{
+		{"all at once", Burst{Invocations: 50}, 50},
+		{"spread over a window", Burst{Invocations: 600, Window: 10 * time.Minute, Duration: 2 * time.Second}, 3},
+		{"headroom is added", Burst{Invocations: 1000, Window: 100 * time.Second, Duration: 10 * time.Second}, 120},
+		{"capped at invocations", Burst{Invocations: 10, Window: time.Minute, Duration: 55 * time.Second}, 10},
+		{"at least one", Burst{Invocations: 1, Window: time.Hour, Duration: time.Millisecond}, 1},
+		{"none", Burst{}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.burst.Required())
+		})
+	}
+}
+
+func TestRun_RecommendsReservation(t *testing.T) {
+	client := &mockLambdaClient{limit: 1000, unreserved: 900}
+
+	p, err := Run(context.Background(), client, "fn", Burst{Invocations: 50}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, int32(50), p.Required)
+	assert.Equal(t, int32(800), p.Available)
+	assert.Equal(t, int32(50), p.Recommended())
+	assert.False(t, p.Healthy())
+	assert.True(t, checkByName(t, p, "Account concurrency").Healthy)
+	assert.Contains(t, checkByName(t, p, "Reserved concurrency").Detail, "reserve 50")
+
+	require.NoError(t, Apply(context.Background(), client, "fn", p.Recommended()))
+	assert.Equal(t, int32(50), aws.ToInt32(client.put.ReservedConcurrentExecutions))
+}
+
+func TestRun_ExistingReservationSuffices(t *testing.T) {
+	client := &mockLambdaClient{limit: 1000, unreserved: 900, reserved: aws.Int32(100)}
+
+	p, err := Run(context.Background(), client, "fn", Burst{Invocations: 50}, 0)
+	require.NoError(t, err)
+	assert.True(t, p.Healthy())
+	assert.Equal(t, int32(0), p.Recommended())
+}
+
+func TestRun_AccountTooSmall(t *testing.T) {
+	// The function's current reservation counts toward what it can be given
+	client := &mockLambdaClient{limit: 200, unreserved: 130, reserved: aws.Int32(20)}
+
+	p, err := Run(context.Background(), client, "fn", Burst{Invocations: 80}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, int32(50), p.Available)
+	assert.Equal(t, int32(0), p.Recommended())
+
+	account := checkByName(t, p, "Account concurrency")
+	assert.False(t, account.Healthy)
+	assert.Contains(t, account.Detail, "quota increase")
+	assert.False(t, checkByName(t, p, "Recent throttles").Healthy)
+}
+
+func TestRun_Errors(t *testing.T) {
+	_, err := Run(context.Background(), &mockLambdaClient{}, "fn", Burst{}, 0)
+	assert.ErrorContains(t, err, "must be positive")
+
+	_, err = Run(context.Background(), &mockLambdaClient{err: errors.New("AccessDenied")}, "fn", Burst{Invocations: 1}, 0)
+	assert.ErrorContains(t, err, "failed to read account settings: AccessDenied")
+}
@@ -0,0 +1,235 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/lambda/metrics"
+)
+
+const (
+	// DefaultCanaryAlias is the alias callers invoke when canary deploys are used
+	DefaultCanaryAlias = "live"
+
+	// DefaultCanaryInterval is how long a canary bakes before it is promoted
+	DefaultCanaryInterval = 5 * time.Minute
+
+	// defaultCanaryCheckInterval is how often canary metrics are checked during the bake
+	defaultCanaryCheckInterval = time.Minute
+)
+
+// Canary outcomes recorded in CanaryResult.Outcome
+const (
+	CanaryPromoted     = "promoted"
+	CanaryRolledBack   = "rolled-back"
+	CanaryAliasCreated = "alias-created"
+	CanaryUnchanged    = "unchanged"
+)
+
+// CanaryConfig controls a weighted rollout of a published version behind an alias
+type CanaryConfig struct {
+	Alias              string        // Alias callers invoke; created at the new version if missing
+	Percent            int           // Share of alias traffic sent to the new version while baking, 1-99
+	Interval           time.Duration // Bake window before the new version is promoted
+	ErrorRateThreshold float64       // Highest healthy canary error rate (fraction of invocations)
+	CheckInterval      time.Duration // How often metrics are checked while baking; defaults to one minute
+
+	// OnCheck, if set, is called with the canary metrics after every check
+	OnCheck func(elapsed time.Duration, summary *metrics.Summary)
+}
+
+// Validate checks the canary settings
+func (c CanaryConfig) Validate() error {
+	if c.Alias == "" {
+		return errors.New("canary alias is required")
+	}
+	if c.Percent < 1 || c.Percent > 99 {
+		return fmt.Errorf("canary percent must be between 1 and 99, got %d", c.Percent)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("canary interval must be positive, got %s", c.Interval)
+	}
+	if c.ErrorRateThreshold < 0 || c.ErrorRateThreshold >= 1 {
+		return fmt.Errorf("canary error rate threshold must be at least 0 and below 1, got %g", c.ErrorRateThreshold)
+	}
+	return nil
+}
+
+// CanaryMetrics reads metrics for the new version's share of alias invocations;
+// metrics.NewAliasVersionCollector satisfies it
+type CanaryMetrics interface {
+	Summarize(ctx context.Context, start, end time.Time) (*metrics.Summary, error)
+}
+
+// CanaryResult describes the outcome of a canary rollout
+type CanaryResult struct {
+	Alias         string
+	StableVersion string // Version the alias pointed to before the rollout; empty if it was created
+	CanaryVersion string
+	Outcome       string
+	Summary       *metrics.Summary // Canary metrics at the last check
+	Reason        string           // Why the canary was rolled back
+}
+
+// CanaryRollbackError is returned when a canary is rolled back
+type CanaryRollbackError struct {
+	Result *CanaryResult
+}
+
+func (e *CanaryRollbackError) Error() string {
+	return fmt.Sprintf("canary version %s rolled back, alias %s restored to version %s: %s",
+		e.Result.CanaryVersion, e.Result.Alias, e.Result.StableVersion, e.Result.Reason)
+}
+
+// Canary rolls version out behind the alias. The alias keeps pointing at its current
+// version while cfg.Percent of its traffic is routed to the new one; if the new
+// version's error rate stays within the threshold for the bake window the alias is
+// moved to it, otherwise the routing is removed and a *CanaryRollbackError returned.
+// A missing alias is created at the new version, since there is nothing to compare
+// against. Interrupting the bake also rolls back.
+func (d *Deployer) Canary(ctx context.Context, version string, cfg CanaryConfig, collector CanaryMetrics) (*CanaryResult, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	if version == "" {
+		return nil, errors.New("canary deploys need a published version")
+	}
+	checkInterval := cfg.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = defaultCanaryCheckInterval
+	}
+
+	result := &CanaryResult{Alias: cfg.Alias, CanaryVersion: version}
+
+	alias, err := d.lambdaClient.GetAlias(ctx, &lambda.GetAliasInput{
+		FunctionName: aws.String(d.config.FunctionName),
+		Name:         aws.String(cfg.Alias),
+	})
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if !errors.As(err, &notFoundErr) {
+			return nil, fmt.Errorf("failed to read alias %s: %w", cfg.Alias, err)
+		}
+		_, err := d.lambdaClient.CreateAlias(ctx, &lambda.CreateAliasInput{
+			FunctionName:    aws.String(d.config.FunctionName),
+			Name:            aws.String(cfg.Alias),
+			FunctionVersion: aws.String(version),
+			Description:     aws.String("Version of the OIDC provisioner callers invoke, managed by rosactl"),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create alias %s: %w", cfg.Alias, err)
+		}
+		if err := d.addAliasResourcePolicy(ctx, cfg.Alias); err != nil {
+			fmt.Fprintf(d.warnings, "Warning: failed to add resource policy to alias %s: %v\n", cfg.Alias, err)
+		}
+		result.Outcome = CanaryAliasCreated
+		return result, nil
+	}
+
+	result.StableVersion = aws.ToString(alias.FunctionVersion)
+	if result.StableVersion == version {
+		result.Outcome = CanaryUnchanged
+		return result, nil
+	}
+
+	if err := d.routeAlias(ctx, cfg.Alias, result.StableVersion, map[string]float64{
+		version: float64(cfg.Percent) / 100,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to route %d%% of alias %s to version %s: %w", cfg.Percent, cfg.Alias, version, err)
+	}
+
+	reason, err := d.bakeCanary(ctx, cfg, checkInterval, collector, result)
+	if err != nil || reason != "" {
+		if err != nil {
+			reason = err.Error()
+		}
+		// Restore the alias even if the bake was interrupted
+		if rollbackErr := d.routeAlias(context.WithoutCancel(ctx), cfg.Alias, result.StableVersion, nil); rollbackErr != nil {
+			return result, fmt.Errorf("canary failed (%s) and restoring alias %s to version %s failed: %w",
+				reason, cfg.Alias, result.StableVersion, rollbackErr)
+		}
+		result.Outcome = CanaryRolledBack
+		result.Reason = reason
+		return result, &CanaryRollbackError{Result: result}
+	}
+
+	if err := d.routeAlias(ctx, cfg.Alias, version, nil); err != nil {
+		return result, fmt.Errorf("failed to promote version %s on alias %s: %w", version, cfg.Alias, err)
+	}
+	result.Outcome = CanaryPromoted
+	return result, nil
+}
+
+// bakeCanary checks canary metrics until the interval has passed. It returns a reason
+// to roll back if the canary is unhealthy, or an error if the bake was interrupted.
+func (d *Deployer) bakeCanary(ctx context.Context, cfg CanaryConfig, checkInterval time.Duration, collector CanaryMetrics, result *CanaryResult) (string, error) {
+	start := d.now()
+	for {
+		wait := checkInterval
+		if remaining := cfg.Interval - d.now().Sub(start); remaining < wait {
+			wait = remaining
+		}
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return "", fmt.Errorf("canary interrupted: %w", ctx.Err())
+			case <-timer.C:
+			}
+		}
+
+		now := d.now()
+		summary, err := collector.Summarize(ctx, start, now)
+		if err != nil {
+			return fmt.Sprintf("failed to read canary metrics: %v", err), nil
+		}
+		result.Summary = summary
+		if cfg.OnCheck != nil {
+			cfg.OnCheck(now.Sub(start), summary)
+		}
+
+		if summary.ErrorRate() > cfg.ErrorRateThreshold {
+			return fmt.Sprintf("error rate %.1f%% (%.0f of %.0f invocations) exceeds %.1f%%",
+				summary.ErrorRate()*100, summary.Errors, summary.Invocations, cfg.ErrorRateThreshold*100), nil
+		}
+		if now.Sub(start) >= cfg.Interval {
+			return "", nil
+		}
+	}
+}
+
+// routeAlias points the alias at version with the given additional version weights;
+// nil weights remove weighted routing
+func (d *Deployer) routeAlias(ctx context.Context, alias, version string, weights map[string]float64) error {
+	if weights == nil {
+		weights = map[string]float64{}
+	}
+	_, err := d.lambdaClient.UpdateAlias(ctx, &lambda.UpdateAliasInput{
+		FunctionName:    aws.String(d.config.FunctionName),
+		Name:            aws.String(alias),
+		FunctionVersion: aws.String(version),
+		RoutingConfig:   &lambdaTypes.AliasRoutingConfiguration{AdditionalVersionWeights: weights},
+	})
+	return err
+}
+
+// addAliasResourcePolicy allows CLM to invoke the alias; the function's own policy
+// statement only covers unqualified invocations
+func (d *Deployer) addAliasResourcePolicy(ctx context.Context, alias string) error {
+	if d.config.CLMServiceRoleARN == "" || d.sourceAccountID() == "" {
+		return nil
+	}
+	// The qualified statement could not be added before the alias existed
+	if alias == d.config.ResourcePolicyQualifier {
+		_, err := d.addResourcePolicy(ctx)
+		return err
+	}
+	_, err := d.addPermission(ctx, alias)
+	return err
+}
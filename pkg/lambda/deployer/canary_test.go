@@ -0,0 +1,175 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/openshift-online/regional-cli/pkg/lambda/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCanaryMetrics struct {
+	summaries []*metrics.Summary
+	err       error
+	calls     int
+}
+
+func (m *mockCanaryMetrics) Summarize(ctx context.Context, start, end time.Time) (*metrics.Summary, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	summary := m.summaries[0]
+	if len(m.summaries) > 1 {
+		m.summaries = m.summaries[1:]
+	}
+	return summary, nil
+}
+
+func testCanaryConfig() CanaryConfig {
+	return CanaryConfig{
+		Alias:              DefaultCanaryAlias,
+		Percent:            10,
+		Interval:           30 * time.Millisecond,
+		CheckInterval:      10 * time.Millisecond,
+		ErrorRateThreshold: 0.05,
+	}
+}
+
+// aliasClient returns a Lambda mock whose alias points at stable and records alias updates
+func aliasClient(stable string, updates *[]*lambda.UpdateAliasInput) *mockLambdaClient {
+	return &mockLambdaClient{
+		getAliasFunc: func(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error) {
+			return &lambda.GetAliasOutput{FunctionVersion: aws.String(stable)}, nil
+		},
+		updateAliasFunc: func(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+			*updates = append(*updates, params)
+			return &lambda.UpdateAliasOutput{}, nil
+		},
+	}
+}
+
+func TestCanary_Promotes(t *testing.T) {
+	var updates []*lambda.UpdateAliasInput
+	d := NewDeployer(aliasClient("4", &updates), nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	collector := &mockCanaryMetrics{summaries: []*metrics.Summary{{Invocations: 40, Errors: 1}}}
+
+	cfg := testCanaryConfig()
+	checks := 0
+	cfg.OnCheck = func(elapsed time.Duration, summary *metrics.Summary) { checks++ }
+
+	result, err := d.Canary(context.Background(), "5", cfg, collector)
+	require.NoError(t, err)
+	assert.Equal(t, CanaryPromoted, result.Outcome)
+	assert.Equal(t, "4", result.StableVersion)
+	assert.GreaterOrEqual(t, checks, 3)
+	assert.Equal(t, collector.calls, checks)
+
+	require.Len(t, updates, 2)
+	assert.Equal(t, "4", aws.ToString(updates[0].FunctionVersion))
+	assert.Equal(t, map[string]float64{"5": 0.1}, updates[0].RoutingConfig.AdditionalVersionWeights)
+	assert.Equal(t, "5", aws.ToString(updates[1].FunctionVersion))
+	assert.Empty(t, updates[1].RoutingConfig.AdditionalVersionWeights)
+}
+
+func TestCanary_RollsBackOnErrors(t *testing.T) {
+	var updates []*lambda.UpdateAliasInput
+	d := NewDeployer(aliasClient("4", &updates), nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	collector := &mockCanaryMetrics{summaries: []*metrics.Summary{{Invocations: 10}, {Invocations: 20, Errors: 3}}}
+
+	result, err := d.Canary(context.Background(), "5", testCanaryConfig(), collector)
+	var rollbackErr *CanaryRollbackError
+	require.ErrorAs(t, err, &rollbackErr)
+	assert.Contains(t, err.Error(), "error rate 15.0% (3 of 20 invocations) exceeds 5.0%")
+	assert.Equal(t, CanaryRolledBack, result.Outcome)
+	assert.Equal(t, 2, collector.calls)
+
+	require.Len(t, updates, 2)
+	assert.Equal(t, "4", aws.ToString(updates[1].FunctionVersion))
+	assert.Empty(t, updates[1].RoutingConfig.AdditionalVersionWeights)
+}
+
+func TestCanary_RollsBackWhenMetricsFail(t *testing.T) {
+	var updates []*lambda.UpdateAliasInput
+	d := NewDeployer(aliasClient("4", &updates), nil, nil, DeploymentConfig{FunctionName: "test-function"})
+
+	_, err := d.Canary(context.Background(), "5", testCanaryConfig(), &mockCanaryMetrics{err: errors.New("AccessDenied")})
+	assert.ErrorContains(t, err, "failed to read canary metrics: AccessDenied")
+	require.Len(t, updates, 2)
+	assert.Equal(t, "4", aws.ToString(updates[1].FunctionVersion))
+}
+
+func TestCanary_RollsBackWhenInterrupted(t *testing.T) {
+	var updates []*lambda.UpdateAliasInput
+	d := NewDeployer(aliasClient("4", &updates), nil, nil, DeploymentConfig{FunctionName: "test-function"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg := testCanaryConfig()
+	cfg.Interval = time.Hour
+
+	result, err := d.Canary(ctx, "5", cfg, &mockCanaryMetrics{})
+	assert.ErrorContains(t, err, "canary interrupted")
+	assert.Equal(t, CanaryRolledBack, result.Outcome)
+	require.Len(t, updates, 2)
+	assert.Equal(t, "4", aws.ToString(updates[1].FunctionVersion))
+}
+
+func TestCanary_CreatesMissingAlias(t *testing.T) {
+	var created *lambda.CreateAliasInput
+	var permission *lambda.AddPermissionInput
+	client := &mockLambdaClient{
+		createAliasFunc: func(ctx context.Context, params *lambda.CreateAliasInput, optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error) {
+			created = params
+			return &lambda.CreateAliasOutput{}, nil
+		},
+		addPermissionFunc: func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+			permission = params
+			return &lambda.AddPermissionOutput{}, nil
+		},
+	}
+	d := NewDeployer(client, nil, nil, DeploymentConfig{
+		FunctionName:      "test-function",
+		CLMServiceRoleARN: "arn:aws:iam::987654321098:role/clm",
+		SourceAccountID:   "987654321098",
+	})
+
+	result, err := d.Canary(context.Background(), "1", testCanaryConfig(), &mockCanaryMetrics{})
+	require.NoError(t, err)
+	assert.Equal(t, CanaryAliasCreated, result.Outcome)
+	require.NotNil(t, created)
+	assert.Equal(t, "1", aws.ToString(created.FunctionVersion))
+	require.NotNil(t, permission)
+	assert.Equal(t, DefaultCanaryAlias, aws.ToString(permission.Qualifier))
+}
+
+func TestCanary_Unchanged(t *testing.T) {
+	var updates []*lambda.UpdateAliasInput
+	d := NewDeployer(aliasClient("5", &updates), nil, nil, DeploymentConfig{FunctionName: "test-function"})
+
+	result, err := d.Canary(context.Background(), "5", testCanaryConfig(), &mockCanaryMetrics{})
+	require.NoError(t, err)
+	assert.Equal(t, CanaryUnchanged, result.Outcome)
+	assert.Empty(t, updates)
+}
+
+func TestCanaryConfig_Validate(t *testing.T) {
+	assert.NoError(t, testCanaryConfig().Validate())
+
+	cfg := testCanaryConfig()
+	cfg.Percent = 100
+	assert.ErrorContains(t, cfg.Validate(), "between 1 and 99")
+
+	cfg = testCanaryConfig()
+	cfg.Interval = 0
+	assert.ErrorContains(t, cfg.Validate(), "interval must be positive")
+
+	cfg = testCanaryConfig()
+	cfg.ErrorRateThreshold = 1
+	assert.ErrorContains(t, cfg.Validate(), "threshold")
+}
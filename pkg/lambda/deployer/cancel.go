@@ -0,0 +1,79 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Deployment steps, in the order Deploy runs them
+const (
+	StepValidate       = "validate"
+	StepExecutionRole  = "execution-role"
+	StepLedgerTable    = "ledger-table"
+	StepPackage        = "package"
+	StepFunction       = "function"
+	StepResourcePolicy = "resource-policy"
+	StepLogGroup       = "log-group"
+	StepTagFunction    = "tag-function"
+	StepVerify         = "verify"
+	StepPublishVersion = "publish-version"
+	StepPrime          = "prime"
+)
+
+// CancelledError is returned when the deployment context is cancelled. The deployment
+// stops before starting the next step; Completed lists the steps that finished.
+type CancelledError struct {
+	Step      string   // Step that was running or about to start
+	Completed []string // Steps that finished before cancellation
+	Err       error
+}
+
+func (e *CancelledError) Error() string {
+	if len(e.Completed) == 0 {
+		return fmt.Sprintf("deployment cancelled during %s: %v", e.Step, e.Err)
+	}
+	return fmt.Sprintf("deployment cancelled during %s after completing %s: %v",
+		e.Step, strings.Join(e.Completed, ", "), e.Err)
+}
+
+func (e *CancelledError) Unwrap() error {
+	return e.Err
+}
+
+// beginStep marks step as running and starts its span, returning the context the
+// step's calls should use, or returns a *CancelledError if ctx is already done
+func (d *Deployer) beginStep(ctx context.Context, step string) (context.Context, error) {
+	d.currentStep = step
+	ctx, d.stepSpan = d.tracer.Start(ctx, step, trace.WithAttributes(StepKey.String(step)))
+	if err := ctx.Err(); err != nil {
+		return ctx, d.cancelled(err)
+	}
+	return ctx, nil
+}
+
+// completeStep records that the running step finished
+func (d *Deployer) completeStep() {
+	d.completedSteps = append(d.completedSteps, d.currentStep)
+	d.endStep(nil)
+}
+
+// endStep ends the running step's span, recording err if the step failed
+func (d *Deployer) endStep(err error) {
+	if d.stepSpan == nil {
+		return
+	}
+	endSpan(d.stepSpan, err)
+	d.stepSpan = nil
+}
+
+// cancelled describes a cancellation during the running step
+func (d *Deployer) cancelled(err error) *CancelledError {
+	return &CancelledError{
+		Step:      d.currentStep,
+		Completed: append([]string(nil), d.completedSteps...),
+		Err:       err,
+	}
+}
@@ -0,0 +1,91 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeploy_CancelledBeforeStart(t *testing.T) {
+	called := false
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			called = true
+			return nil, nil
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	deployer := NewDeployer(mockLambda, &mockIAMClient{}, &mockCloudWatchLogsClient{}, rollbackConfig())
+	_, err := deployer.Deploy(ctx)
+
+	var cancelled *CancelledError
+	require.ErrorAs(t, err, &cancelled)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, StepValidate, cancelled.Step)
+	assert.Empty(t, cancelled.Completed)
+	assert.False(t, called)
+}
+
+func TestDeploy_CancelledBetweenSteps(t *testing.T) {
+	var deleted []string
+	mockLambda, mockIAM := failingRoleSetup(&deleted)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel once the role is fully set up, so the deployment stops before packaging
+	mockIAM.putRolePolicyFunc = func(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error) {
+		cancel()
+		return &iam.PutRolePolicyOutput{}, nil
+	}
+	mockIAM.deleteRolePolicyFunc = func(ctx context.Context, params *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error) {
+		require.NoError(t, ctx.Err(), "rollback must not use the cancelled context")
+		return &iam.DeleteRolePolicyOutput{}, nil
+	}
+
+	deployer := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, rollbackConfig())
+	_, err := deployer.Deploy(ctx)
+
+	var cancelled *CancelledError
+	require.ErrorAs(t, err, &cancelled)
+	assert.Equal(t, StepPackage, cancelled.Step)
+	assert.Equal(t, []string{StepValidate, StepExecutionRole}, cancelled.Completed)
+	assert.Contains(t, err.Error(), "deployment cancelled during package after completing validate, execution-role")
+
+	var failure *PartialFailureError
+	require.ErrorAs(t, err, &failure)
+	assert.Equal(t, []string{"test-role"}, deleted)
+	assert.Equal(t, []ResourceRecord{{Type: ResourceTypeExecutionRole, Identifier: rollbackRoleARN, Action: ResourceActionCreated}}, failure.RolledBack)
+}
+
+func TestDeploy_CancelledDuringStep(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mockLambda, mockIAM := failingRoleSetup(new([]string))
+	mockIAM.getRoleFunc = func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+		cancel()
+		return nil, ctx.Err()
+	}
+	mockIAM.createRoleFunc = func(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
+		return &iam.CreateRoleOutput{Role: &iamTypes.Role{Arn: aws.String(rollbackRoleARN)}}, nil
+	}
+
+	deployer := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, rollbackConfig())
+	_, err := deployer.Deploy(ctx)
+
+	var cancelled *CancelledError
+	require.ErrorAs(t, err, &cancelled)
+	assert.Equal(t, StepExecutionRole, cancelled.Step)
+	assert.Equal(t, []string{StepValidate}, cancelled.Completed)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,153 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	ctTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+)
+
+const (
+	// cloudTrailLookbackSkew widens the lookup window to tolerate clock skew with AWS
+	cloudTrailLookbackSkew = time.Minute
+
+	// cloudTrailMaxPages bounds how many LookupEvents pages are read per region;
+	// LookupEvents is limited to two requests per second per account and region
+	cloudTrailMaxPages = 20
+)
+
+// CloudTrailAPI defines the CloudTrail operations needed to verify a deployment
+type CloudTrailAPI interface {
+	LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput,
+		optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error)
+}
+
+// Denial is an API call by the deployment principal that AWS rejected
+type Denial struct {
+	EventID      string
+	Time         time.Time
+	EventSource  string
+	EventName    string
+	ErrorCode    string
+	ErrorMessage string
+}
+
+// cloudTrailRecord is the subset of a CloudTrail event record rosactl reads
+type cloudTrailRecord struct {
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+	UserIdentity struct {
+		ARN string `json:"arn"`
+	} `json:"userIdentity"`
+}
+
+// VerifyCloudTrail looks up CloudTrail events recorded between the start of the
+// deployment and end, and returns the calls by the deployment principal that were
+// denied, oldest first. Global services such as IAM record their events in a single
+// region, so callers pass one client per region to search. Events are delivered
+// with a delay of several minutes, so recent denials may not be found yet.
+func VerifyCloudTrail(ctx context.Context, clients []CloudTrailAPI, result *DeploymentResult, end time.Time) ([]Denial, error) {
+	if result.DeployedBy == "" {
+		return nil, errors.New("the deployment principal is unknown")
+	}
+	username, err := cloudTrailUsername(result.DeployedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var denials []Denial
+	for _, client := range clients {
+		input := &cloudtrail.LookupEventsInput{
+			LookupAttributes: []ctTypes.LookupAttribute{{
+				AttributeKey:   ctTypes.LookupAttributeKeyUsername,
+				AttributeValue: aws.String(username),
+			}},
+			StartTime:  aws.Time(result.DeployedAt.Add(-cloudTrailLookbackSkew)),
+			EndTime:    aws.Time(end),
+			MaxResults: aws.Int32(50),
+		}
+
+		for page := 0; page < cloudTrailMaxPages; page++ {
+			output, err := client.LookupEvents(ctx, input)
+			if err != nil {
+				return denials, fmt.Errorf("failed to look up CloudTrail events: %w", err)
+			}
+			for _, event := range output.Events {
+				denial, ok := deniedEvent(event, result.DeployedBy)
+				if ok && !seen[denial.EventID] {
+					seen[denial.EventID] = true
+					denials = append(denials, denial)
+				}
+			}
+			if aws.ToString(output.NextToken) == "" {
+				break
+			}
+			input.NextToken = output.NextToken
+		}
+	}
+
+	sort.SliceStable(denials, func(i, j int) bool {
+		return denials[i].Time.Before(denials[j].Time)
+	})
+	return denials, nil
+}
+
+// deniedEvent returns the event as a denial if principalARN made it and it failed with an authorization error
+func deniedEvent(event ctTypes.Event, principalARN string) (Denial, bool) {
+	var record cloudTrailRecord
+	if err := json.Unmarshal([]byte(aws.ToString(event.CloudTrailEvent)), &record); err != nil {
+		return Denial{}, false
+	}
+	if record.UserIdentity.ARN != principalARN || !isAccessDenied(record.ErrorCode) {
+		return Denial{}, false
+	}
+	return Denial{
+		EventID:      aws.ToString(event.EventId),
+		Time:         aws.ToTime(event.EventTime),
+		EventSource:  aws.ToString(event.EventSource),
+		EventName:    aws.ToString(event.EventName),
+		ErrorCode:    record.ErrorCode,
+		ErrorMessage: record.ErrorMessage,
+	}, true
+}
+
+// isAccessDenied reports whether a CloudTrail error code is an authorization failure
+func isAccessDenied(code string) bool {
+	return strings.Contains(code, "AccessDenied") || strings.Contains(code, "UnauthorizedOperation")
+}
+
+// cloudTrailUsername returns the CloudTrail Username for a principal: the session
+// name of an assumed role, or the name of an IAM user
+func cloudTrailUsername(principalARN string) (string, error) {
+	parsed, err := arn.Parse(principalARN)
+	if err != nil {
+		return "", fmt.Errorf("invalid principal ARN %q: %w", principalARN, err)
+	}
+	parts := strings.Split(parsed.Resource, "/")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("cannot look up CloudTrail events for %s", principalARN)
+	}
+	return parts[len(parts)-1], nil
+}
+
+// GlobalEventsRegion returns the region where CloudTrail records events for global
+// services such as IAM in region's partition
+func GlobalEventsRegion(region string) string {
+	switch {
+	case strings.HasPrefix(region, "us-gov-"):
+		return "us-gov-west-1"
+	case strings.HasPrefix(region, "cn-"):
+		return "cn-north-1"
+	default:
+		return "us-east-1"
+	}
+}
@@ -0,0 +1,105 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	ctTypes "github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCloudTrailClient struct {
+	pages  []*cloudtrail.LookupEventsOutput
+	inputs []cloudtrail.LookupEventsInput
+	err    error
+}
+
+func (m *mockCloudTrailClient) LookupEvents(ctx context.Context, params *cloudtrail.LookupEventsInput,
+	optFns ...func(*cloudtrail.Options)) (*cloudtrail.LookupEventsOutput, error) {
+	m.inputs = append(m.inputs, *params)
+	if m.err != nil {
+		return nil, m.err
+	}
+	page := m.pages[0]
+	m.pages = m.pages[1:]
+	return page, nil
+}
+
+func trailEvent(id, source, name string, at time.Time, record string) ctTypes.Event {
+	return ctTypes.Event{
+		EventId:         aws.String(id),
+		EventSource:     aws.String(source),
+		EventName:       aws.String(name),
+		EventTime:       aws.Time(at),
+		CloudTrailEvent: aws.String(record),
+	}
+}
+
+func TestVerifyCloudTrail(t *testing.T) {
+	principal := "arn:aws:sts::123456789012:assumed-role/Admin/dev"
+	deployedAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	end := deployedAt.Add(3 * time.Minute)
+
+	denied := `{"errorCode":"AccessDenied","errorMessage":"not authorized to perform iam:TagRole","userIdentity":{"arn":"` + principal + `"}}`
+	other := `{"errorCode":"AccessDenied","userIdentity":{"arn":"arn:aws:sts::123456789012:assumed-role/Other/dev"}}`
+	ok := `{"userIdentity":{"arn":"` + principal + `"}}`
+
+	regional := &mockCloudTrailClient{pages: []*cloudtrail.LookupEventsOutput{
+		{
+			Events: []ctTypes.Event{
+				trailEvent("e-3", "logs.amazonaws.com", "TagLogGroup", deployedAt.Add(2*time.Minute),
+					`{"errorCode":"AccessDeniedException","userIdentity":{"arn":"`+principal+`"}}`),
+				trailEvent("e-4", "lambda.amazonaws.com", "CreateFunction20150331", deployedAt.Add(time.Minute), ok),
+			},
+			NextToken: aws.String("next"),
+		},
+		{Events: []ctTypes.Event{trailEvent("e-5", "iam.amazonaws.com", "TagRole", deployedAt, other)}},
+	}}
+	global := &mockCloudTrailClient{pages: []*cloudtrail.LookupEventsOutput{
+		{Events: []ctTypes.Event{trailEvent("e-1", "iam.amazonaws.com", "TagRole", deployedAt.Add(30*time.Second), denied)}},
+	}}
+
+	denials, err := VerifyCloudTrail(context.Background(), []CloudTrailAPI{regional, global},
+		&DeploymentResult{DeployedBy: principal, DeployedAt: deployedAt}, end)
+	require.NoError(t, err)
+
+	require.Len(t, denials, 2)
+	assert.Equal(t, Denial{
+		EventID:      "e-1",
+		Time:         deployedAt.Add(30 * time.Second),
+		EventSource:  "iam.amazonaws.com",
+		EventName:    "TagRole",
+		ErrorCode:    "AccessDenied",
+		ErrorMessage: "not authorized to perform iam:TagRole",
+	}, denials[0])
+	assert.Equal(t, "TagLogGroup", denials[1].EventName)
+
+	require.Len(t, regional.inputs, 2)
+	assert.Equal(t, "next", aws.ToString(regional.inputs[1].NextToken))
+	assert.Equal(t, []ctTypes.LookupAttribute{{AttributeKey: ctTypes.LookupAttributeKeyUsername, AttributeValue: aws.String("dev")}},
+		regional.inputs[0].LookupAttributes)
+	assert.Equal(t, deployedAt.Add(-time.Minute), aws.ToTime(regional.inputs[0].StartTime))
+	assert.Equal(t, end, aws.ToTime(regional.inputs[0].EndTime))
+}
+
+func TestVerifyCloudTrail_Errors(t *testing.T) {
+	_, err := VerifyCloudTrail(context.Background(), nil, &DeploymentResult{}, time.Now())
+	assert.ErrorContains(t, err, "principal is unknown")
+
+	client := &mockCloudTrailClient{err: errors.New("AccessDenied")}
+	_, err = VerifyCloudTrail(context.Background(), []CloudTrailAPI{client},
+		&DeploymentResult{DeployedBy: "arn:aws:iam::123456789012:user/ci/deployer"}, time.Now())
+	assert.ErrorContains(t, err, "failed to look up CloudTrail events: AccessDenied")
+	assert.Equal(t, "deployer", aws.ToString(client.inputs[0].LookupAttributes[0].AttributeValue))
+}
+
+func TestGlobalEventsRegion(t *testing.T) {
+	assert.Equal(t, "us-east-1", GlobalEventsRegion("eu-west-1"))
+	assert.Equal(t, "us-gov-west-1", GlobalEventsRegion("us-gov-east-1"))
+	assert.Equal(t, "cn-north-1", GlobalEventsRegion("cn-northwest-1"))
+}
@@ -0,0 +1,180 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+// codeSigningConfigsMaxPages bounds how many ListCodeSigningConfigs pages are read when
+// suggesting a config after a denied CreateFunction
+const codeSigningConfigsMaxPages = 10
+
+// CodeSigningAPI defines the Lambda operations needed to attach a code signing config
+// to the function and to find the account's configs when a policy requires one
+type CodeSigningAPI interface {
+	GetCodeSigningConfig(ctx context.Context, params *lambda.GetCodeSigningConfigInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetCodeSigningConfigOutput, error)
+	ListCodeSigningConfigs(ctx context.Context, params *lambda.ListCodeSigningConfigsInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListCodeSigningConfigsOutput, error)
+	GetFunctionCodeSigningConfig(ctx context.Context, params *lambda.GetFunctionCodeSigningConfigInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetFunctionCodeSigningConfigOutput, error)
+	PutFunctionCodeSigningConfig(ctx context.Context, params *lambda.PutFunctionCodeSigningConfigInput,
+		optFns ...func(*lambda.Options)) (*lambda.PutFunctionCodeSigningConfigOutput, error)
+}
+
+// WithCodeSigningClient lets the deployer check and attach DeploymentConfig's
+// CodeSigningConfigARN on existing functions, and list the account's code signing
+// configs when a policy denies creating a function without one
+func WithCodeSigningClient(client CodeSigningAPI) DeployerOption {
+	return func(d *Deployer) {
+		d.codeSigningClient = client
+	}
+}
+
+// ValidateCodeSigningConfigARN checks that configARN is the ARN of a Lambda code
+// signing config
+func ValidateCodeSigningConfigARN(configARN string) error {
+	parsed, err := arn.Parse(configARN)
+	if err != nil || parsed.Service != "lambda" || !strings.HasPrefix(parsed.Resource, "code-signing-config:csc-") {
+		return fmt.Errorf("code signing config ARN %q is not a Lambda code signing config ARN, such as arn:aws:lambda:us-east-1:123456789012:code-signing-config:csc-0123456789abcdef0", configARN)
+	}
+	return nil
+}
+
+// checkCodeSigningConfig reads the configured code signing config before anything is
+// deployed. rosactl uploads the package it builds unsigned, so a config that enforces
+// signed code would reject every deployment; it is refused with the remediation
+// instead. Accounts that deny lambda:GetCodeSigningConfig are only warned.
+func (d *Deployer) checkCodeSigningConfig(ctx context.Context) error {
+	if d.config.CodeSigningConfigARN == "" || d.codeSigningClient == nil {
+		return nil
+	}
+
+	output, err := d.codeSigningClient.GetCodeSigningConfig(ctx, &lambda.GetCodeSigningConfigInput{
+		CodeSigningConfigArn: aws.String(d.config.CodeSigningConfigARN),
+	})
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		var apiErr smithy.APIError
+		switch {
+		case errors.As(err, &notFoundErr):
+			return withCategory(ErrCodeSigning, fmt.Errorf("code signing config %s does not exist: %w", d.config.CodeSigningConfigARN, err))
+		case errors.As(err, &apiErr) && isAccessDenied(apiErr.ErrorCode()):
+			fmt.Fprintf(d.warnings, "Warning: code signing config %s not checked: %v\n", d.config.CodeSigningConfigARN, err)
+			return nil
+		}
+		return fmt.Errorf("failed to read code signing config %s: %w", d.config.CodeSigningConfigARN, err)
+	}
+
+	if enforcesSignedCode(output.CodeSigningConfig) {
+		return withCategory(ErrCodeSigning, fmt.Errorf(
+			"code signing config %s enforces signed code, but the package rosactl builds is uploaded unsigned; "+
+				"set the config's untrusted artifact policy to Warn, or use a config that does not enforce it",
+			d.config.CodeSigningConfigARN))
+	}
+	return nil
+}
+
+// ensureFunctionCodeSigning attaches the configured code signing config to the
+// existing function, before its code is updated, when it has another one or none
+func (d *Deployer) ensureFunctionCodeSigning(ctx context.Context) error {
+	if d.config.CodeSigningConfigARN == "" {
+		return nil
+	}
+	if d.codeSigningClient == nil {
+		return fmt.Errorf("code signing config %s cannot be attached to existing function %s without a code signing client",
+			d.config.CodeSigningConfigARN, d.config.FunctionName)
+	}
+
+	current, err := d.codeSigningClient.GetFunctionCodeSigningConfig(ctx, &lambda.GetFunctionCodeSigningConfigInput{
+		FunctionName: aws.String(d.config.FunctionName),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read the code signing config of function %s: %w", d.config.FunctionName, err)
+	}
+	if aws.ToString(current.CodeSigningConfigArn) == d.config.CodeSigningConfigARN {
+		return nil
+	}
+
+	_, err = d.codeSigningClient.PutFunctionCodeSigningConfig(ctx, &lambda.PutFunctionCodeSigningConfigInput{
+		FunctionName:         aws.String(d.config.FunctionName),
+		CodeSigningConfigArn: aws.String(d.config.CodeSigningConfigARN),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach code signing config %s: %w", d.config.CodeSigningConfigARN, categorizeFunctionError(err))
+	}
+	return nil
+}
+
+// codeSigningDeniedError explains a CreateFunction an explicit deny refused while no
+// code signing config was configured. Organizations that require code signing deny
+// creating functions without one in a service control policy, which Lambda reports
+// as a plain AccessDeniedException, so when the account has code signing configs
+// the error is categorized as ErrCodeSigning and names them. Otherwise err is
+// returned categorized as usual.
+func (d *Deployer) codeSigningDeniedError(ctx context.Context, err error) error {
+	if d.config.CodeSigningConfigARN != "" || d.codeSigningClient == nil || !isExplicitDeny(err) {
+		return categorizeFunctionError(err)
+	}
+
+	configs, listErr := d.listCodeSigningConfigs(ctx)
+	if listErr != nil || len(configs) == 0 {
+		return categorizeFunctionError(err)
+	}
+
+	var usable, enforcing []string
+	for _, config := range configs {
+		if enforcesSignedCode(&config) {
+			enforcing = append(enforcing, aws.ToString(config.CodeSigningConfigArn))
+		} else {
+			usable = append(usable, aws.ToString(config.CodeSigningConfigArn))
+		}
+	}
+	remediation := "deploy with one of the account's code signing configs: " + strings.Join(usable, ", ")
+	if len(usable) == 0 {
+		remediation = "the account's code signing configs all enforce signed code, which the package rosactl builds is not (" +
+			strings.Join(enforcing, ", ") + "); create one whose untrusted artifact policy is Warn and deploy with it"
+	}
+	return withCategory(ErrCodeSigning, fmt.Errorf(
+		"%w; the account appears to require Lambda functions to use a code signing config: %s", err, remediation))
+}
+
+// listCodeSigningConfigs returns the code signing configs in the deployment's region
+func (d *Deployer) listCodeSigningConfigs(ctx context.Context) ([]lambdaTypes.CodeSigningConfig, error) {
+	var configs []lambdaTypes.CodeSigningConfig
+	input := &lambda.ListCodeSigningConfigsInput{}
+	for page := 0; page < codeSigningConfigsMaxPages; page++ {
+		output, err := d.codeSigningClient.ListCodeSigningConfigs(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, output.CodeSigningConfigs...)
+		if output.NextMarker == nil {
+			break
+		}
+		input.Marker = output.NextMarker
+	}
+	return configs, nil
+}
+
+// enforcesSignedCode reports whether config rejects code that fails its signature checks
+func enforcesSignedCode(config *lambdaTypes.CodeSigningConfig) bool {
+	return config != nil && config.CodeSigningPolicies != nil &&
+		config.CodeSigningPolicies.UntrustedArtifactOnDeployment == lambdaTypes.CodeSigningPolicyEnforce
+}
+
+// isExplicitDeny reports whether err is an authorization failure caused by an explicit
+// deny, such as a service control policy, rather than a missing allow
+func isExplicitDeny(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && isAccessDenied(apiErr.ErrorCode()) &&
+		strings.Contains(strings.ToLower(apiErr.ErrorMessage()), "explicit deny")
+}
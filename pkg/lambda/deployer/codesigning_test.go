@@ -0,0 +1,218 @@
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCodeSigningConfigARN  = "arn:aws:lambda:us-east-1:123456789012:code-signing-config:csc-0123456789abcdef0"
+	otherCodeSigningConfigARN = "arn:aws:lambda:us-east-1:123456789012:code-signing-config:csc-0fedcba9876543210"
+)
+
+type mockCodeSigningClient struct {
+	getCodeSigningConfigFunc         func(ctx context.Context, params *lambda.GetCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetCodeSigningConfigOutput, error)
+	listCodeSigningConfigsFunc       func(ctx context.Context, params *lambda.ListCodeSigningConfigsInput, optFns ...func(*lambda.Options)) (*lambda.ListCodeSigningConfigsOutput, error)
+	getFunctionCodeSigningConfigFunc func(ctx context.Context, params *lambda.GetFunctionCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionCodeSigningConfigOutput, error)
+	putFunctionCodeSigningConfigFunc func(ctx context.Context, params *lambda.PutFunctionCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionCodeSigningConfigOutput, error)
+}
+
+func (m *mockCodeSigningClient) GetCodeSigningConfig(ctx context.Context, params *lambda.GetCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetCodeSigningConfigOutput, error) {
+	if m.getCodeSigningConfigFunc != nil {
+		return m.getCodeSigningConfigFunc(ctx, params, optFns...)
+	}
+	return &lambda.GetCodeSigningConfigOutput{CodeSigningConfig: codeSigningConfig(aws.ToString(params.CodeSigningConfigArn), lambdaTypes.CodeSigningPolicyWarn)}, nil
+}
+
+func (m *mockCodeSigningClient) ListCodeSigningConfigs(ctx context.Context, params *lambda.ListCodeSigningConfigsInput, optFns ...func(*lambda.Options)) (*lambda.ListCodeSigningConfigsOutput, error) {
+	if m.listCodeSigningConfigsFunc != nil {
+		return m.listCodeSigningConfigsFunc(ctx, params, optFns...)
+	}
+	return &lambda.ListCodeSigningConfigsOutput{}, nil
+}
+
+func (m *mockCodeSigningClient) GetFunctionCodeSigningConfig(ctx context.Context, params *lambda.GetFunctionCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionCodeSigningConfigOutput, error) {
+	if m.getFunctionCodeSigningConfigFunc != nil {
+		return m.getFunctionCodeSigningConfigFunc(ctx, params, optFns...)
+	}
+	return &lambda.GetFunctionCodeSigningConfigOutput{}, nil
+}
+
+func (m *mockCodeSigningClient) PutFunctionCodeSigningConfig(ctx context.Context, params *lambda.PutFunctionCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionCodeSigningConfigOutput, error) {
+	if m.putFunctionCodeSigningConfigFunc != nil {
+		return m.putFunctionCodeSigningConfigFunc(ctx, params, optFns...)
+	}
+	return &lambda.PutFunctionCodeSigningConfigOutput{}, nil
+}
+
+func codeSigningConfig(configARN string, policy lambdaTypes.CodeSigningPolicy) *lambdaTypes.CodeSigningConfig {
+	return &lambdaTypes.CodeSigningConfig{
+		CodeSigningConfigArn: aws.String(configARN),
+		CodeSigningPolicies:  &lambdaTypes.CodeSigningPolicies{UntrustedArtifactOnDeployment: policy},
+	}
+}
+
+// explicitDeny is the error Lambda returns when a service control policy denies a request
+var explicitDeny = &smithy.GenericAPIError{
+	Code:    "AccessDeniedException",
+	Message: "User: arn:aws:sts::123456789012:assumed-role/deployer/session is not authorized to perform: lambda:CreateFunction with an explicit deny in a service control policy",
+}
+
+func TestValidateCodeSigningConfigARN(t *testing.T) {
+	assert.NoError(t, ValidateCodeSigningConfigARN(testCodeSigningConfigARN))
+	assert.ErrorContains(t, ValidateCodeSigningConfigARN("csc-0123456789abcdef0"), "is not a Lambda code signing config ARN")
+	assert.ErrorContains(t, ValidateCodeSigningConfigARN("arn:aws:lambda:us-east-1:123456789012:function:test-function"),
+		"is not a Lambda code signing config ARN")
+}
+
+func TestCreateFunction_CodeSigningConfig(t *testing.T) {
+	var input *lambda.CreateFunctionInput
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			input = params
+			return &lambda.CreateFunctionOutput{FunctionArn: aws.String("arn")}, nil
+		},
+	}
+
+	_, err := NewDeployer(mockLambda, nil, nil, rollbackConfig()).createFunction(context.Background(), []byte("zip"), "role")
+	require.NoError(t, err)
+	assert.Nil(t, input.CodeSigningConfigArn)
+
+	config := rollbackConfig()
+	config.CodeSigningConfigARN = testCodeSigningConfigARN
+	_, err = NewDeployer(mockLambda, nil, nil, config).createFunction(context.Background(), []byte("zip"), "role")
+	require.NoError(t, err)
+	assert.Equal(t, testCodeSigningConfigARN, aws.ToString(input.CodeSigningConfigArn))
+}
+
+func TestCreateFunction_CodeSigningRequiredByPolicy(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			return nil, explicitDeny
+		},
+	}
+	codeSigning := &mockCodeSigningClient{
+		listCodeSigningConfigsFunc: func(ctx context.Context, params *lambda.ListCodeSigningConfigsInput, optFns ...func(*lambda.Options)) (*lambda.ListCodeSigningConfigsOutput, error) {
+			return &lambda.ListCodeSigningConfigsOutput{CodeSigningConfigs: []lambdaTypes.CodeSigningConfig{
+				*codeSigningConfig(testCodeSigningConfigARN, lambdaTypes.CodeSigningPolicyWarn),
+				*codeSigningConfig(otherCodeSigningConfigARN, lambdaTypes.CodeSigningPolicyEnforce),
+			}}, nil
+		},
+	}
+
+	_, err := NewDeployer(mockLambda, nil, nil, rollbackConfig(), WithCodeSigningClient(codeSigning)).
+		createFunction(context.Background(), []byte("zip"), "role")
+	assert.ErrorIs(t, err, ErrCodeSigning)
+	assert.ErrorContains(t, err, "explicit deny")
+	assert.ErrorContains(t, err, "deploy with one of the account's code signing configs: "+testCodeSigningConfigARN)
+	assert.NotContains(t, err.Error(), otherCodeSigningConfigARN, "a config enforcing signed code cannot be used")
+
+	// Without code signing configs in the account, the denial is not attributed to code signing
+	codeSigning.listCodeSigningConfigsFunc = nil
+	_, err = NewDeployer(mockLambda, nil, nil, rollbackConfig(), WithCodeSigningClient(codeSigning)).
+		createFunction(context.Background(), []byte("zip"), "role")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrCodeSigning)
+}
+
+func TestUpdateFunction_AttachesCodeSigningConfig(t *testing.T) {
+	var calls []string
+	mockLambda := &mockLambdaClient{
+		updateFunctionCodeFunc: func(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
+			calls = append(calls, "UpdateFunctionCode")
+			return &lambda.UpdateFunctionCodeOutput{}, nil
+		},
+	}
+	codeSigning := &mockCodeSigningClient{
+		getFunctionCodeSigningConfigFunc: func(ctx context.Context, params *lambda.GetFunctionCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionCodeSigningConfigOutput, error) {
+			return &lambda.GetFunctionCodeSigningConfigOutput{CodeSigningConfigArn: aws.String(otherCodeSigningConfigARN)}, nil
+		},
+		putFunctionCodeSigningConfigFunc: func(ctx context.Context, params *lambda.PutFunctionCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.PutFunctionCodeSigningConfigOutput, error) {
+			assert.Equal(t, "test-function", aws.ToString(params.FunctionName))
+			assert.Equal(t, testCodeSigningConfigARN, aws.ToString(params.CodeSigningConfigArn))
+			calls = append(calls, "PutFunctionCodeSigningConfig")
+			return &lambda.PutFunctionCodeSigningConfigOutput{}, nil
+		},
+	}
+
+	config := rollbackConfig()
+	config.CodeSigningConfigARN = testCodeSigningConfigARN
+	d := NewDeployer(mockLambda, nil, nil, config, WithCodeSigningClient(codeSigning), WithWarningOutput(&bytes.Buffer{}))
+	require.NoError(t, d.updateFunction(context.Background(), []byte("zip"), "role", &lambdaTypes.FunctionConfiguration{}))
+	assert.Equal(t, []string{"PutFunctionCodeSigningConfig", "UpdateFunctionCode"}, calls, "the config is attached before the code is updated")
+
+	// An attached config is left alone
+	calls = nil
+	codeSigning.getFunctionCodeSigningConfigFunc = func(ctx context.Context, params *lambda.GetFunctionCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionCodeSigningConfigOutput, error) {
+		return &lambda.GetFunctionCodeSigningConfigOutput{CodeSigningConfigArn: aws.String(testCodeSigningConfigARN)}, nil
+	}
+	require.NoError(t, d.updateFunction(context.Background(), []byte("zip"), "role", &lambdaTypes.FunctionConfiguration{}))
+	assert.Equal(t, []string{"UpdateFunctionCode"}, calls)
+}
+
+func TestUpdateFunction_CodeVerificationFailed(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		updateFunctionCodeFunc: func(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
+			return nil, &lambdaTypes.CodeVerificationFailedException{Message: aws.String("the code signature failed one or more validation checks")}
+		},
+	}
+
+	err := NewDeployer(mockLambda, nil, nil, rollbackConfig()).
+		updateFunction(context.Background(), []byte("zip"), "role", &lambdaTypes.FunctionConfiguration{})
+	assert.ErrorIs(t, err, ErrCodeSigning)
+	assert.ErrorContains(t, err, "set the config's untrusted artifact policy to Warn")
+}
+
+func TestDeploy_RefusesEnforcingCodeSigningConfig(t *testing.T) {
+	config := rollbackConfig()
+	config.ExecutionRoleARN = testPreCreatedRoleARN
+	config.CodeSigningConfigARN = testCodeSigningConfigARN
+	codeSigning := &mockCodeSigningClient{
+		getCodeSigningConfigFunc: func(ctx context.Context, params *lambda.GetCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetCodeSigningConfigOutput, error) {
+			return &lambda.GetCodeSigningConfigOutput{CodeSigningConfig: codeSigningConfig(testCodeSigningConfigARN, lambdaTypes.CodeSigningPolicyEnforce)}, nil
+		},
+	}
+	mockLambda := missingFunctionLambda(t)
+	mockLambda.createFunctionFunc = func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+		t.Error("the function must not be created")
+		return &lambda.CreateFunctionOutput{}, nil
+	}
+
+	_, err := NewDeployer(mockLambda, preCreatedRoleIAM(t, lambdaTrustPolicy(t)), &mockCloudWatchLogsClient{}, config,
+		WithCodeSigningClient(codeSigning)).Deploy(context.Background())
+	assert.ErrorIs(t, err, ErrCodeSigning)
+	assert.ErrorContains(t, err, "enforces signed code")
+
+	config.CodeSigningConfigARN = "csc-0123456789abcdef0"
+	_, err = NewDeployer(mockLambda, preCreatedRoleIAM(t, lambdaTrustPolicy(t)), &mockCloudWatchLogsClient{}, config,
+		WithCodeSigningClient(codeSigning)).Deploy(context.Background())
+	assert.ErrorContains(t, err, "is not a Lambda code signing config ARN")
+}
+
+func TestCheckCodeSigningConfig_Unreadable(t *testing.T) {
+	config := rollbackConfig()
+	config.CodeSigningConfigARN = testCodeSigningConfigARN
+	codeSigning := &mockCodeSigningClient{
+		getCodeSigningConfigFunc: func(ctx context.Context, params *lambda.GetCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetCodeSigningConfigOutput, error) {
+			return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized to perform: lambda:GetCodeSigningConfig"}
+		},
+	}
+
+	var warnings bytes.Buffer
+	d := NewDeployer(nil, nil, nil, config, WithCodeSigningClient(codeSigning), WithWarningOutput(&warnings))
+	require.NoError(t, d.checkCodeSigningConfig(context.Background()))
+	assert.Contains(t, warnings.String(), "code signing config "+testCodeSigningConfigARN+" not checked")
+
+	codeSigning.getCodeSigningConfigFunc = func(ctx context.Context, params *lambda.GetCodeSigningConfigInput, optFns ...func(*lambda.Options)) (*lambda.GetCodeSigningConfigOutput, error) {
+		return nil, &lambdaTypes.ResourceNotFoundException{}
+	}
+	assert.ErrorIs(t, d.checkCodeSigningConfig(context.Background()), ErrCodeSigning)
+}
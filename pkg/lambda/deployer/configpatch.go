@@ -0,0 +1,189 @@
+package deployer
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+// functionHandler is the executable name custom runtimes start
+const functionHandler = "bootstrap"
+
+// managedEnvVars are the environment variables rosactl sets on the function; any
+// other variable was added out-of-band and is preserved on update
+var managedEnvVars = []string{ProviderTagsEnvVar, ProviderMetadataEnvVar, tagkey.EnvVar, BindableRolePathEnvVar, ledger.EnvVar}
+
+// UnmanagedSetting is a function setting rosactl does not manage, found on an
+// existing function. Updates leave it unchanged.
+type UnmanagedSetting struct {
+	Field string // e.g. "VPC config" or "environment variable LOG_LEVEL"
+	Value string
+}
+
+func (s UnmanagedSetting) String() string {
+	if s.Value == "" {
+		return s.Field
+	}
+	return fmt.Sprintf("%s (%s)", s.Field, s.Value)
+}
+
+// functionConfigPatch returns the UpdateFunctionConfiguration input that moves current
+// to the desired configuration, setting only the fields that differ so settings
+// applied out-of-band are preserved. It returns nil if nothing differs.
+func (d *Deployer) functionConfigPatch(current *lambdaTypes.FunctionConfiguration, roleARN string) (*lambda.UpdateFunctionConfigurationInput, error) {
+	if current == nil {
+		current = &lambdaTypes.FunctionConfiguration{}
+	}
+	patch := &lambda.UpdateFunctionConfigurationInput{FunctionName: aws.String(d.config.FunctionName)}
+	changed := false
+
+	if d.config.Runtime != "" && current.Runtime != d.config.Runtime {
+		patch.Runtime = d.config.Runtime
+		changed = true
+	}
+	if roleARN != "" && aws.ToString(current.Role) != roleARN {
+		patch.Role = aws.String(roleARN)
+		changed = true
+	}
+	if aws.ToString(current.Handler) != functionHandler {
+		patch.Handler = aws.String(functionHandler)
+		changed = true
+	}
+	if d.config.MemorySize > 0 && aws.ToInt32(current.MemorySize) != d.config.MemorySize {
+		patch.MemorySize = aws.Int32(d.config.MemorySize)
+		changed = true
+	}
+	if d.config.Timeout > 0 && aws.ToInt32(current.Timeout) != d.config.Timeout {
+		patch.Timeout = aws.Int32(d.config.Timeout)
+		changed = true
+	}
+	if description := FunctionDescription(d.stamp()); aws.ToString(current.Description) != description {
+		patch.Description = aws.String(description)
+		changed = true
+	}
+	if storage := d.ephemeralStorage(); storage != nil {
+		if current.EphemeralStorage == nil || aws.ToInt32(current.EphemeralStorage.Size) != aws.ToInt32(storage.Size) {
+			patch.EphemeralStorage = storage
+			changed = true
+		}
+	}
+	if snapStart := d.snapStart(); snapStart != nil {
+		if current.SnapStart == nil || current.SnapStart.ApplyOn != snapStart.ApplyOn {
+			patch.SnapStart = snapStart
+			changed = true
+		}
+	}
+
+	if tracing := d.tracingConfig(); tracing != nil {
+		if current.TracingConfig == nil || current.TracingConfig.Mode != tracing.Mode {
+			patch.TracingConfig = tracing
+			changed = true
+		}
+	}
+
+	if FunctionLogGroupName(current, d.config.FunctionName) != d.logGroupName() {
+		patch.LoggingConfig = d.loggingConfig(current.LoggingConfig)
+		changed = true
+	}
+
+	// The environment is replaced as a whole, so merge rosactl's variables into the
+	// existing ones rather than sending only its own
+	environment, err := d.functionEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	var existing map[string]string
+	if current.Environment != nil {
+		existing = current.Environment.Variables
+	}
+	merged := mergeEnvironment(existing, environment.Variables)
+	if !maps.Equal(existing, merged) {
+		patch.Environment = &lambdaTypes.Environment{Variables: merged}
+		changed = true
+	}
+
+	if !changed {
+		return nil, nil
+	}
+	return patch, nil
+}
+
+// mergeEnvironment returns the existing variables with the managed ones replaced by
+// desired; managed variables missing from desired are removed
+func mergeEnvironment(existing, desired map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(desired))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for _, key := range managedEnvVars {
+		delete(merged, key)
+	}
+	for key, value := range desired {
+		merged[key] = value
+	}
+	return merged
+}
+
+// unmanagedSettings lists the settings on an existing function that rosactl does
+// not manage, sorted by field
+func (d *Deployer) unmanagedSettings(current *lambdaTypes.FunctionConfiguration) []UnmanagedSetting {
+	if current == nil {
+		return nil
+	}
+
+	var settings []UnmanagedSetting
+	if vpc := current.VpcConfig; vpc != nil && aws.ToString(vpc.VpcId) != "" {
+		settings = append(settings, UnmanagedSetting{Field: "VPC config", Value: aws.ToString(vpc.VpcId)})
+	}
+	for _, layer := range current.Layers {
+		settings = append(settings, UnmanagedSetting{Field: "layer", Value: aws.ToString(layer.Arn)})
+	}
+	if dlq := current.DeadLetterConfig; dlq != nil && aws.ToString(dlq.TargetArn) != "" {
+		settings = append(settings, UnmanagedSetting{Field: "dead-letter queue", Value: aws.ToString(dlq.TargetArn)})
+	}
+	if key := aws.ToString(current.KMSKeyArn); key != "" {
+		settings = append(settings, UnmanagedSetting{Field: "KMS key", Value: key})
+	}
+	for _, fs := range current.FileSystemConfigs {
+		settings = append(settings, UnmanagedSetting{Field: "file system", Value: aws.ToString(fs.LocalMountPath)})
+	}
+	if tracing := current.TracingConfig; tracing != nil && tracing.Mode == lambdaTypes.TracingModeActive && !d.config.Tracing {
+		settings = append(settings, UnmanagedSetting{Field: "active tracing"})
+	}
+	if current.Environment != nil {
+		for key := range current.Environment.Variables {
+			if _, configured := d.config.Environment[key]; !configured && !isManagedEnvVar(key) {
+				settings = append(settings, UnmanagedSetting{Field: "environment variable " + key})
+			}
+		}
+	}
+
+	sort.SliceStable(settings, func(i, j int) bool { return settings[i].String() < settings[j].String() })
+	return settings
+}
+
+// isManagedEnvVar reports whether rosactl sets the environment variable
+func isManagedEnvVar(key string) bool {
+	for _, managed := range managedEnvVars {
+		if key == managed {
+			return true
+		}
+	}
+	return false
+}
+
+// unmanagedSettingsList renders settings for warnings
+func unmanagedSettingsList(settings []UnmanagedSetting) string {
+	parts := make([]string, len(settings))
+	for i, setting := range settings {
+		parts[i] = setting.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,171 @@
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchConfig() DeploymentConfig {
+	return DeploymentConfig{
+		FunctionName: "test-function",
+		Runtime:      lambdaTypes.RuntimeProvidedal2023,
+		MemorySize:   128,
+		Timeout:      60,
+		Tags:         map[string]string{"team": "oidc"},
+		CLIVersion:   "1.2.3",
+	}
+}
+
+// deployedConfig is the configuration rosactl would have deployed with patchConfig
+func deployedConfig(t *testing.T, d *Deployer) *lambdaTypes.FunctionConfiguration {
+	t.Helper()
+	environment, err := d.functionEnvironment()
+	require.NoError(t, err)
+	return &lambdaTypes.FunctionConfiguration{
+		Runtime:     lambdaTypes.RuntimeProvidedal2023,
+		Role:        aws.String("role"),
+		Handler:     aws.String(functionHandler),
+		MemorySize:  aws.Int32(128),
+		Timeout:     aws.Int32(60),
+		Description: aws.String(FunctionDescription(d.stamp())),
+		Environment: &lambdaTypes.EnvironmentResponse{Variables: environment.Variables},
+	}
+}
+
+func TestFunctionConfigPatch_Unchanged(t *testing.T) {
+	d := NewDeployer(nil, nil, nil, patchConfig())
+
+	patch, err := d.functionConfigPatch(deployedConfig(t, d), "role")
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+}
+
+func TestFunctionConfigPatch_OnlyChangedFields(t *testing.T) {
+	d := NewDeployer(nil, nil, nil, patchConfig())
+	current := deployedConfig(t, d)
+	current.MemorySize = aws.Int32(256)
+	current.VpcConfig = &lambdaTypes.VpcConfigResponse{VpcId: aws.String("vpc-1"), SubnetIds: []string{"subnet-1"}}
+
+	patch, err := d.functionConfigPatch(current, "role")
+	require.NoError(t, err)
+	require.NotNil(t, patch)
+	assert.Equal(t, int32(128), aws.ToInt32(patch.MemorySize))
+	assert.Nil(t, patch.Timeout)
+	assert.Nil(t, patch.Role)
+	assert.Nil(t, patch.Environment)
+	assert.Nil(t, patch.VpcConfig)
+}
+
+func TestFunctionConfigPatch_LogGroup(t *testing.T) {
+	config := patchConfig()
+	config.LogGroupName = "/org/rosa/oidc-provisioner"
+	d := NewDeployer(nil, nil, nil, config)
+
+	current := deployedConfig(t, NewDeployer(nil, nil, nil, patchConfig()))
+	current.LoggingConfig = &lambdaTypes.LoggingConfig{
+		LogFormat: lambdaTypes.LogFormatJson,
+		LogGroup:  aws.String("/aws/lambda/test-function"),
+	}
+
+	patch, err := d.functionConfigPatch(current, "role")
+	require.NoError(t, err)
+	require.NotNil(t, patch)
+	require.NotNil(t, patch.LoggingConfig)
+	assert.Equal(t, "/org/rosa/oidc-provisioner", aws.ToString(patch.LoggingConfig.LogGroup))
+	assert.Equal(t, lambdaTypes.LogFormatJson, patch.LoggingConfig.LogFormat, "other logging settings are preserved")
+
+	// Without a configured name the function is moved back to Lambda's default group
+	current.LoggingConfig.LogGroup = aws.String("/org/rosa/oidc-provisioner")
+	patch, err = NewDeployer(nil, nil, nil, patchConfig()).functionConfigPatch(current, "role")
+	require.NoError(t, err)
+	require.NotNil(t, patch)
+	assert.Equal(t, "/aws/lambda/test-function", aws.ToString(patch.LoggingConfig.LogGroup))
+}
+
+func TestFunctionConfigPatch_MergesEnvironment(t *testing.T) {
+	config := patchConfig()
+	config.Tags = map[string]string{"team": "identity"}
+	d := NewDeployer(nil, nil, nil, config)
+
+	current := deployedConfig(t, NewDeployer(nil, nil, nil, patchConfig()))
+	current.Environment.Variables["LOG_LEVEL"] = "debug"
+
+	patch, err := d.functionConfigPatch(current, "role")
+	require.NoError(t, err)
+	require.NotNil(t, patch.Environment)
+	assert.Equal(t, "debug", patch.Environment.Variables["LOG_LEVEL"])
+	assert.Contains(t, patch.Environment.Variables[ProviderTagsEnvVar], "identity")
+
+	// Removing every tag clears the managed variable but keeps the others
+	config.Tags = nil
+	d = NewDeployer(nil, nil, nil, config)
+	patch, err = d.functionConfigPatch(current, "role")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"LOG_LEVEL":            "debug",
+		ProviderMetadataEnvVar: `{"created_by":"test-function","rosactl_version":"1.2.3"}`,
+	}, patch.Environment.Variables)
+}
+
+func TestFunctionConfigPatch_EnvironmentAndTracing(t *testing.T) {
+	config := patchConfig()
+	config.Environment = map[string]string{"LOG_LEVEL": "info"}
+	config.Tracing = true
+	d := NewDeployer(nil, nil, nil, config)
+
+	current := deployedConfig(t, NewDeployer(nil, nil, nil, patchConfig()))
+	current.Environment.Variables["LOG_LEVEL"] = "debug"
+	current.Environment.Variables["OTHER"] = "kept"
+
+	patch, err := d.functionConfigPatch(current, "role")
+	require.NoError(t, err)
+	require.NotNil(t, patch)
+	require.NotNil(t, patch.TracingConfig)
+	assert.Equal(t, lambdaTypes.TracingModeActive, patch.TracingConfig.Mode)
+	assert.Equal(t, "info", patch.Environment.Variables["LOG_LEVEL"])
+	assert.Equal(t, "kept", patch.Environment.Variables["OTHER"])
+
+	// Configured settings are managed, not reported as preserved
+	current.TracingConfig = &lambdaTypes.TracingConfigResponse{Mode: lambdaTypes.TracingModeActive}
+	assert.Equal(t, []UnmanagedSetting{{Field: "environment variable OTHER"}}, d.unmanagedSettings(current))
+
+	// Without Tracing an existing mode is left alone
+	d = NewDeployer(nil, nil, nil, patchConfig())
+	patch, err = d.functionConfigPatch(current, "role")
+	require.NoError(t, err)
+	assert.Nil(t, patch)
+}
+
+func TestUpdateFunction_WarnsAboutUnmanagedSettings(t *testing.T) {
+	var patch *lambda.UpdateFunctionConfigurationInput
+	client := &mockLambdaClient{
+		updateFunctionConfigFunc: func(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error) {
+			patch = params
+			return &lambda.UpdateFunctionConfigurationOutput{}, nil
+		},
+	}
+	var warnings bytes.Buffer
+	d := NewDeployer(client, nil, nil, patchConfig(), WithWarningOutput(&warnings))
+
+	current := deployedConfig(t, d)
+	current.VpcConfig = &lambdaTypes.VpcConfigResponse{VpcId: aws.String("vpc-1")}
+	current.Layers = []lambdaTypes.Layer{{Arn: aws.String("arn:aws:lambda:us-east-1:123456789012:layer:ext:3")}}
+	current.Environment.Variables["LOG_LEVEL"] = "debug"
+
+	require.NoError(t, d.updateFunction(context.Background(), []byte("zip"), "role", current))
+	assert.Nil(t, patch, "no managed field differs")
+	assert.Equal(t, []UnmanagedSetting{
+		{Field: "VPC config", Value: "vpc-1"},
+		{Field: "environment variable LOG_LEVEL"},
+		{Field: "layer", Value: "arn:aws:lambda:us-east-1:123456789012:layer:ext:3"},
+	}, d.preserved)
+	assert.Contains(t, warnings.String(),
+		"Warning: function test-function has settings rosactl does not manage; they are left unchanged: VPC config (vpc-1), environment variable LOG_LEVEL, layer")
+}
@@ -0,0 +1,360 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	configTypes "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// configRulesMaxPages bounds how many DescribeConfigRules pages are read; a region
+// holds at most 1000 rules, returned 25 to a page
+const configRulesMaxPages = 50
+
+// AWS Config resource types of the deployed resources
+const (
+	ConfigResourceTypeFunction = "AWS::Lambda::Function"
+	ConfigResourceTypeRole     = "AWS::IAM::Role"
+	ConfigResourceTypeLogGroup = "AWS::Logs::LogGroup"
+)
+
+// Defaults AWS Config applies to managed rule parameters that are not set
+const (
+	defaultMinRetentionTime     = 365
+	defaultSettingsCheckTimeout = 3
+	defaultSettingsCheckMemory  = 128
+)
+
+// ConfigRulesAPI defines the AWS Config operations needed to check a deployment
+// against the account's Config rules
+type ConfigRulesAPI interface {
+	DescribeConfigRules(ctx context.Context, params *configservice.DescribeConfigRulesInput,
+		optFns ...func(*configservice.Options)) (*configservice.DescribeConfigRulesOutput, error)
+}
+
+// ConfigRuleFinding is an AWS Config rule expected to mark a deployed resource noncompliant
+type ConfigRuleFinding struct {
+	RuleName     string // Name of the rule in the account
+	Identifier   string // Managed rule identifier, such as LAMBDA_INSIDE_VPC
+	ResourceType string // Config resource type, such as AWS::Lambda::Function
+	Resource     string // Name of the resource the rule would flag
+	Detail       string // Why the resource would be noncompliant
+	Suggestion   string // How to deploy a compliant resource, or avoid the finding
+}
+
+// configResource is a deployed resource as AWS Config rules see it
+type configResource struct {
+	Type string
+	Name string
+	Tags map[string]string
+}
+
+// configRuleCheck evaluates a managed rule against the deployment's resources
+type configRuleCheck func(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding
+
+// configRuleChecks are the AWS managed rules the deployment is checked against, by identifier
+var configRuleChecks = map[string]configRuleCheck{
+	"LAMBDA_INSIDE_VPC":                  checkLambdaInsideVPC,
+	"LAMBDA_DLQ_CHECK":                   checkLambdaDLQ,
+	"LAMBDA_FUNCTION_SETTINGS_CHECK":     checkLambdaFunctionSettings,
+	"CW_LOGGROUP_RETENTION_PERIOD_CHECK": checkLogGroupRetention,
+	"CLOUDWATCH_LOG_GROUP_ENCRYPTED":     checkLogGroupEncrypted,
+	"IAM_NO_INLINE_POLICY_CHECK":         checkNoInlinePolicy,
+	"REQUIRED_TAGS":                      checkRequiredTags,
+}
+
+// CheckConfigRules reads the account's AWS Config rules in the deployment's region and
+// returns the findings the active AWS managed rules are expected to report for the
+// resources a deployment creates, sorted by rule and resource. Custom rules are not
+// evaluated. Findings are predictions from the deployment's configuration; settings
+// made outside rosactl, such as a VPC config on an existing function, are not seen.
+func (d *Deployer) CheckConfigRules(ctx context.Context, client ConfigRulesAPI) ([]ConfigRuleFinding, error) {
+	var findings []ConfigRuleFinding
+	input := &configservice.DescribeConfigRulesInput{}
+	for page := 0; page < configRulesMaxPages; page++ {
+		output, err := client.DescribeConfigRules(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe AWS Config rules: %w", err)
+		}
+		for _, rule := range output.ConfigRules {
+			if rule.Source == nil || rule.Source.Owner != configTypes.OwnerAws {
+				continue
+			}
+			if rule.ConfigRuleState != configTypes.ConfigRuleStateActive && rule.ConfigRuleState != configTypes.ConfigRuleStateEvaluating {
+				continue
+			}
+			check, ok := configRuleChecks[aws.ToString(rule.Source.SourceIdentifier)]
+			if !ok {
+				continue
+			}
+			params, err := ruleParameters(aws.ToString(rule.InputParameters))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse parameters of AWS Config rule %s: %w", aws.ToString(rule.ConfigRuleName), err)
+			}
+			findings = append(findings, check(d, rule, params)...)
+		}
+		if aws.ToString(output.NextToken) == "" {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].RuleName != findings[j].RuleName {
+			return findings[i].RuleName < findings[j].RuleName
+		}
+		return findings[i].Resource < findings[j].Resource
+	})
+	return findings, nil
+}
+
+// ruleParameters decodes a rule's input parameters. Config stores them as a JSON
+// object whose values are usually strings, but numbers and booleans are accepted.
+func ruleParameters(raw string) (map[string]string, error) {
+	params := make(map[string]string)
+	if strings.TrimSpace(raw) == "" {
+		return params, nil
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &values); err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		if s, ok := value.(string); ok {
+			params[key] = strings.TrimSpace(s)
+		} else if value != nil {
+			params[key] = fmt.Sprint(value)
+		}
+	}
+	return params, nil
+}
+
+// configResources returns the function, execution role, and log group a deployment manages
+func (d *Deployer) configResources() (function, role, logGroup configResource) {
+	tags := d.resourceTags()
+	return configResource{Type: ConfigResourceTypeFunction, Name: d.config.FunctionName, Tags: tags},
+		configResource{Type: ConfigResourceTypeRole, Name: d.config.ExecutionRoleName, Tags: tags},
+		configResource{Type: ConfigResourceTypeLogGroup, Name: d.logGroupName(), Tags: tags}
+}
+
+// inScope reports whether a rule's scope covers resource. A rule without a scope
+// covers every resource type it supports.
+func inScope(rule configTypes.ConfigRule, resource configResource) bool {
+	scope := rule.Scope
+	if scope == nil {
+		return true
+	}
+	if len(scope.ComplianceResourceTypes) > 0 && !slices.Contains(scope.ComplianceResourceTypes, resource.Type) {
+		return false
+	}
+	if id := aws.ToString(scope.ComplianceResourceId); id != "" && id != resource.Name {
+		return false
+	}
+	if tagKey := aws.ToString(scope.TagKey); tagKey != "" {
+		value, ok := resource.Tags[tagKey]
+		if tagValue := aws.ToString(scope.TagValue); !ok || (tagValue != "" && value != tagValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// finding returns a finding of rule for resource
+func finding(rule configTypes.ConfigRule, resource configResource, detail, suggestion string) ConfigRuleFinding {
+	return ConfigRuleFinding{
+		RuleName:     aws.ToString(rule.ConfigRuleName),
+		Identifier:   aws.ToString(rule.Source.SourceIdentifier),
+		ResourceType: resource.Type,
+		Resource:     resource.Name,
+		Detail:       detail,
+		Suggestion:   suggestion,
+	}
+}
+
+func checkLambdaInsideVPC(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding {
+	function, _, _ := d.configResources()
+	if !inScope(rule, function) {
+		return nil
+	}
+	return []ConfigRuleFinding{finding(rule, function,
+		"the function is not attached to a VPC",
+		"attach the function to private subnets with a NAT gateway after deploying, since it calls IAM and the cluster's "+
+			"OIDC issuer; later deployments preserve the VPC config. Otherwise exclude the function from the rule's scope")}
+}
+
+func checkLambdaDLQ(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding {
+	function, _, _ := d.configResources()
+	if !inScope(rule, function) {
+		return nil
+	}
+	return []ConfigRuleFinding{finding(rule, function,
+		"the function has no dead-letter queue",
+		"CLM invokes the function synchronously, so a dead-letter queue would receive nothing; exclude the function "+
+			"from the rule's scope, or configure a queue after deploying, which later deployments preserve")}
+}
+
+func checkLambdaFunctionSettings(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding {
+	function, _, _ := d.configResources()
+	if !inScope(rule, function) {
+		return nil
+	}
+
+	var findings []ConfigRuleFinding
+	if runtimes := splitList(params["runtime"]); len(runtimes) > 0 && d.config.Runtime != "" {
+		if !slices.Contains(runtimes, string(d.config.Runtime)) {
+			suggestion := fmt.Sprintf("the rule expects %s, which rosactl cannot deploy (it supports %s); exclude the function from the rule's scope",
+				strings.Join(runtimes, ", "), supportedRuntimeList())
+			for _, runtime := range runtimes {
+				if isSupportedRuntime(lambdaTypes.Runtime(runtime)) {
+					suggestion = fmt.Sprintf("re-run with --runtime %s", runtime)
+					break
+				}
+			}
+			findings = append(findings, finding(rule, function,
+				fmt.Sprintf("runtime is %s; the rule expects %s", d.config.Runtime, strings.Join(runtimes, ", ")), suggestion))
+		}
+	}
+
+	if expected, ok := intParameter(params, "timeout", defaultSettingsCheckTimeout); ok && d.config.Timeout != int32(expected) {
+		findings = append(findings, finding(rule, function,
+			fmt.Sprintf("timeout is %d seconds; the rule expects %d", d.config.Timeout, expected),
+			fmt.Sprintf("re-run with --timeout %d, if provisioning completes within it, or change the rule's timeout parameter", expected)))
+	}
+	if expected, ok := intParameter(params, "memorySize", defaultSettingsCheckMemory); ok && d.config.MemorySize != int32(expected) {
+		findings = append(findings, finding(rule, function,
+			fmt.Sprintf("memory size is %d MB; the rule expects %d", d.config.MemorySize, expected),
+			fmt.Sprintf("re-run with --memory %d", expected)))
+	}
+
+	if roleARN := params["role"]; roleARN != "" {
+		parsed, err := arn.Parse(roleARN)
+		if err == nil && strings.HasPrefix(parsed.Resource, "role/") {
+			name := parsed.Resource[strings.LastIndex(parsed.Resource, "/")+1:]
+			if name != d.config.ExecutionRoleName {
+				findings = append(findings, finding(rule, function,
+					fmt.Sprintf("execution role is %s; the rule expects %s", d.config.ExecutionRoleName, roleARN),
+					fmt.Sprintf("re-run with --execution-role-name %s", name)))
+			}
+		}
+	}
+	return findings
+}
+
+func checkLogGroupRetention(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding {
+	_, _, logGroup := d.configResources()
+	if !inScope(rule, logGroup) {
+		return nil
+	}
+	minimum, ok := intParameter(params, "MinRetentionTime", defaultMinRetentionTime)
+	if !ok || int(d.logRetentionDays()) >= minimum {
+		return nil
+	}
+
+	suggestion := "the rule requires a longer retention than CloudWatch Logs supports; exclude the log group from the rule's scope"
+	for _, period := range logRetentionPeriods {
+		if int(period) >= minimum {
+			suggestion = fmt.Sprintf("re-run with --log-retention-days %d", period)
+			break
+		}
+	}
+	return []ConfigRuleFinding{finding(rule, logGroup,
+		fmt.Sprintf("logs are retained for %d days; the rule requires at least %d", d.logRetentionDays(), minimum), suggestion)}
+}
+
+func checkLogGroupEncrypted(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding {
+	_, _, logGroup := d.configResources()
+	if !inScope(rule, logGroup) {
+		return nil
+	}
+	return []ConfigRuleFinding{finding(rule, logGroup,
+		"the log group is not encrypted with a KMS key",
+		fmt.Sprintf("associate a KMS key after deploying with aws logs associate-kms-key --log-group-name %s --kms-key-id <key-arn>; "+
+			"later deployments leave it in place", logGroup.Name))}
+}
+
+func checkNoInlinePolicy(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding {
+	_, role, _ := d.configResources()
+	// A pre-created execution role is granted its permissions by its owner
+	if d.config.ExecutionRoleARN != "" || !inScope(rule, role) {
+		return nil
+	}
+	return []ConfigRuleFinding{finding(rule, role,
+		fmt.Sprintf("the execution role's permissions are granted by the inline policy %s", permissionsPolicyName),
+		"rosactl scopes the policy to the deployment and maintains it inline; exclude the execution role from the rule's scope")}
+}
+
+// requiredTagsMax is the number of tag key and value pairs REQUIRED_TAGS accepts
+const requiredTagsMax = 6
+
+func checkRequiredTags(d *Deployer, rule configTypes.ConfigRule, params map[string]string) []ConfigRuleFinding {
+	function, role, logGroup := d.configResources()
+	resources := []configResource{function, role, logGroup}
+	if d.config.ExecutionRoleARN != "" {
+		// rosactl does not tag a pre-created execution role
+		resources = []configResource{function, logGroup}
+	}
+
+	var findings []ConfigRuleFinding
+	for _, resource := range resources {
+		if !inScope(rule, resource) {
+			continue
+		}
+		for i := 1; i <= requiredTagsMax; i++ {
+			key := params[fmt.Sprintf("tag%dKey", i)]
+			if key == "" {
+				continue
+			}
+			allowed := splitList(params[fmt.Sprintf("tag%dValue", i)])
+			value, ok := resource.Tags[key]
+			switch {
+			case !ok:
+				example := "<value>"
+				if len(allowed) > 0 {
+					example = allowed[0]
+				}
+				findings = append(findings, finding(rule, resource,
+					fmt.Sprintf("tag %s is missing", key),
+					fmt.Sprintf("re-run with --tag %s=%s", key, example)))
+			case len(allowed) > 0 && !slices.Contains(allowed, value):
+				findings = append(findings, finding(rule, resource,
+					fmt.Sprintf("tag %s is %q; the rule expects %s", key, value, strings.Join(allowed, ", ")),
+					fmt.Sprintf("re-run with --tag %s=%s", key, allowed[0])))
+			}
+		}
+	}
+	return findings
+}
+
+// intParameter returns a rule's integer parameter, or def when it is not set. It
+// reports false when the parameter is not an integer, so the rule is not evaluated.
+func intParameter(params map[string]string, key string, def int) (int, bool) {
+	raw, ok := params[key]
+	if !ok || raw == "" {
+		return def, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// splitList splits a comma-separated rule parameter
+func splitList(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,168 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/configservice"
+	configTypes "github.com/aws/aws-sdk-go-v2/service/configservice/types"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configRulesClient returns pages of rules, one per DescribeConfigRules call
+type configRulesClient struct {
+	pages  [][]configTypes.ConfigRule
+	tokens []string
+	err    error
+}
+
+func (c *configRulesClient) DescribeConfigRules(ctx context.Context, params *configservice.DescribeConfigRulesInput,
+	optFns ...func(*configservice.Options)) (*configservice.DescribeConfigRulesOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	page := len(c.tokens)
+	c.tokens = append(c.tokens, aws.ToString(params.NextToken))
+	output := &configservice.DescribeConfigRulesOutput{ConfigRules: c.pages[page]}
+	if page+1 < len(c.pages) {
+		output.NextToken = aws.String("next")
+	}
+	return output, nil
+}
+
+func managedRule(name, identifier, params string) configTypes.ConfigRule {
+	return configTypes.ConfigRule{
+		ConfigRuleName:  aws.String(name),
+		Source:          &configTypes.Source{Owner: configTypes.OwnerAws, SourceIdentifier: aws.String(identifier)},
+		ConfigRuleState: configTypes.ConfigRuleStateActive,
+		InputParameters: aws.String(params),
+	}
+}
+
+func configRulesDeployer(config DeploymentConfig) *Deployer {
+	config.FunctionName = "test-function"
+	config.ExecutionRoleName = "test-role"
+	config.Runtime = lambdaTypes.RuntimeProvidedal2023
+	config.MemorySize = 128
+	config.Timeout = 60
+	return NewDeployer(&mockLambdaClient{}, &mockIAMClient{}, &mockCloudWatchLogsClient{}, config)
+}
+
+func TestCheckConfigRules(t *testing.T) {
+	custom := managedRule("custom-vpc", "LAMBDA_INSIDE_VPC", "")
+	custom.Source.Owner = configTypes.OwnerCustomLambda
+	deleting := managedRule("deleting-vpc", "LAMBDA_INSIDE_VPC", "")
+	deleting.ConfigRuleState = configTypes.ConfigRuleStateDeleting
+
+	client := &configRulesClient{pages: [][]configTypes.ConfigRule{
+		{
+			managedRule("lambda-vpc", "LAMBDA_INSIDE_VPC", ""),
+			managedRule("log-retention", "CW_LOGGROUP_RETENTION_PERIOD_CHECK", `{"MinRetentionTime":"180"}`),
+			custom,
+		},
+		{
+			deleting,
+			managedRule("s3-versioning", "S3_BUCKET_VERSIONING_ENABLED", ""),
+			managedRule("inline-policies", "IAM_NO_INLINE_POLICY_CHECK", ""),
+		},
+	}}
+
+	findings, err := configRulesDeployer(DeploymentConfig{}).CheckConfigRules(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "next"}, client.tokens)
+
+	require.Len(t, findings, 3)
+	assert.Equal(t, "inline-policies", findings[0].RuleName)
+	assert.Equal(t, ConfigResourceTypeRole, findings[0].ResourceType)
+	assert.Equal(t, "test-role", findings[0].Resource)
+
+	assert.Equal(t, "lambda-vpc", findings[1].RuleName)
+	assert.Equal(t, "LAMBDA_INSIDE_VPC", findings[1].Identifier)
+	assert.Equal(t, "test-function", findings[1].Resource)
+
+	assert.Equal(t, "log-retention", findings[2].RuleName)
+	assert.Equal(t, "/aws/lambda/test-function", findings[2].Resource)
+	assert.Equal(t, "logs are retained for 90 days; the rule requires at least 180", findings[2].Detail)
+	assert.Equal(t, "re-run with --log-retention-days 180", findings[2].Suggestion)
+}
+
+func TestCheckConfigRules_Retention(t *testing.T) {
+	rules := []configTypes.ConfigRule{managedRule("log-retention", "CW_LOGGROUP_RETENTION_PERIOD_CHECK", "")}
+
+	findings, err := configRulesDeployer(DeploymentConfig{}).CheckConfigRules(context.Background(),
+		&configRulesClient{pages: [][]configTypes.ConfigRule{rules}})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "re-run with --log-retention-days 365", findings[0].Suggestion, "MinRetentionTime defaults to 365")
+
+	findings, err = configRulesDeployer(DeploymentConfig{LogRetentionDays: 400}).CheckConfigRules(context.Background(),
+		&configRulesClient{pages: [][]configTypes.ConfigRule{rules}})
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestCheckConfigRules_FunctionSettings(t *testing.T) {
+	rules := []configTypes.ConfigRule{managedRule("lambda-settings", "LAMBDA_FUNCTION_SETTINGS_CHECK",
+		`{"runtime":"python3.12, provided.al2","timeout":60,"memorySize":"256","role":"arn:aws:iam::123456789012:role/approved-role"}`)}
+
+	findings, err := configRulesDeployer(DeploymentConfig{}).CheckConfigRules(context.Background(),
+		&configRulesClient{pages: [][]configTypes.ConfigRule{rules}})
+	require.NoError(t, err)
+
+	var suggestions []string
+	for _, finding := range findings {
+		suggestions = append(suggestions, finding.Suggestion)
+	}
+	assert.Equal(t, []string{
+		"re-run with --runtime provided.al2",
+		"re-run with --memory 256",
+		"re-run with --execution-role-name approved-role",
+	}, suggestions, "the timeout matches")
+}
+
+func TestCheckConfigRules_Scope(t *testing.T) {
+	taggedOnly := managedRule("lambda-vpc", "LAMBDA_INSIDE_VPC", "")
+	taggedOnly.Scope = &configTypes.Scope{TagKey: aws.String("env"), TagValue: aws.String("prod")}
+	otherType := managedRule("tags", "REQUIRED_TAGS", `{"tag1Key":"cost-center"}`)
+	otherType.Scope = &configTypes.Scope{ComplianceResourceTypes: []string{"AWS::EC2::Instance"}}
+	rules := []configTypes.ConfigRule{taggedOnly, otherType}
+
+	findings, err := configRulesDeployer(DeploymentConfig{Tags: map[string]string{"env": "dev"}}).CheckConfigRules(context.Background(),
+		&configRulesClient{pages: [][]configTypes.ConfigRule{rules}})
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	findings, err = configRulesDeployer(DeploymentConfig{Tags: map[string]string{"env": "prod"}}).CheckConfigRules(context.Background(),
+		&configRulesClient{pages: [][]configTypes.ConfigRule{rules}})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "lambda-vpc", findings[0].RuleName)
+}
+
+func TestCheckConfigRules_RequiredTags(t *testing.T) {
+	rule := managedRule("tags", "REQUIRED_TAGS", `{"tag1Key":"cost-center","tag2Key":"env","tag2Value":"prod,staging"}`)
+	rule.Scope = &configTypes.Scope{ComplianceResourceTypes: []string{ConfigResourceTypeFunction}}
+
+	findings, err := configRulesDeployer(DeploymentConfig{Tags: map[string]string{"env": "dev"}}).CheckConfigRules(context.Background(),
+		&configRulesClient{pages: [][]configTypes.ConfigRule{{rule}}})
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, "tag cost-center is missing", findings[0].Detail)
+	assert.Equal(t, "re-run with --tag cost-center=<value>", findings[0].Suggestion)
+	assert.Equal(t, `tag env is "dev"; the rule expects prod, staging`, findings[1].Detail)
+	assert.Equal(t, "re-run with --tag env=prod", findings[1].Suggestion)
+}
+
+func TestCheckConfigRules_Error(t *testing.T) {
+	_, err := configRulesDeployer(DeploymentConfig{}).CheckConfigRules(context.Background(),
+		&configRulesClient{err: errors.New("access denied")})
+	assert.ErrorContains(t, err, "failed to describe AWS Config rules: access denied")
+
+	_, err = configRulesDeployer(DeploymentConfig{}).CheckConfigRules(context.Background(),
+		&configRulesClient{pages: [][]configTypes.ConfigRule{{managedRule("lambda-vpc", "LAMBDA_INSIDE_VPC", "{")}}})
+	assert.ErrorContains(t, err, "failed to parse parameters of AWS Config rule lambda-vpc")
+}
@@ -0,0 +1,122 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// Custom data identifiers masked by the default log data protection policy
+const (
+	accountIDDataIdentifier = "AwsAccountId"
+	arnDataIdentifier       = "AwsArn"
+
+	accountIDPattern = `\b\d{12}\b`
+	arnPattern       = `arn:aws[a-z-]*:[a-z0-9-]+:[a-z0-9-]*:\d{0,12}:[A-Za-z0-9_+=,.@:/*-]+`
+)
+
+// dataProtectionPolicyDocument mirrors the CloudWatch Logs data protection policy syntax
+type dataProtectionPolicyDocument struct {
+	Name          string                    `json:"Name"`
+	Description   string                    `json:"Description,omitempty"`
+	Version       string                    `json:"Version"`
+	Configuration *dataProtectionConfig     `json:"Configuration,omitempty"`
+	Statement     []dataProtectionStatement `json:"Statement"`
+}
+
+type dataProtectionConfig struct {
+	CustomDataIdentifier []customDataIdentifier `json:"CustomDataIdentifier"`
+}
+
+type customDataIdentifier struct {
+	Name  string `json:"Name"`
+	Regex string `json:"Regex"`
+}
+
+type dataProtectionStatement struct {
+	Sid            string                 `json:"Sid"`
+	DataIdentifier []string               `json:"DataIdentifier"`
+	Operation      map[string]interface{} `json:"Operation"`
+}
+
+// GenerateLogDataProtectionPolicy generates a CloudWatch Logs data protection policy that
+// audits and masks AWS account IDs and ARNs written by the OIDC provisioner
+func GenerateLogDataProtectionPolicy() (string, error) {
+	identifiers := []string{accountIDDataIdentifier, arnDataIdentifier}
+
+	policy := dataProtectionPolicyDocument{
+		Name:        "rosa-oidc-provisioner-data-protection",
+		Description: "Masks AWS account IDs and ARNs in OIDC provisioner logs",
+		Version:     "2021-06-01",
+		Configuration: &dataProtectionConfig{
+			CustomDataIdentifier: []customDataIdentifier{
+				{Name: accountIDDataIdentifier, Regex: accountIDPattern},
+				{Name: arnDataIdentifier, Regex: arnPattern},
+			},
+		},
+		Statement: []dataProtectionStatement{
+			{
+				// CloudWatch Logs requires an audit statement alongside the de-identify statement
+				Sid:            "audit",
+				DataIdentifier: identifiers,
+				Operation: map[string]interface{}{
+					"Audit": map[string]interface{}{"FindingsDestination": map[string]interface{}{}},
+				},
+			},
+			{
+				Sid:            "redact",
+				DataIdentifier: identifiers,
+				Operation: map[string]interface{}{
+					"Deidentify": map[string]interface{}{"MaskConfig": map[string]interface{}{}},
+				},
+			},
+		},
+	}
+
+	policyJSON, err := json.Marshal(policy)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal data protection policy: %w", err)
+	}
+
+	return string(policyJSON), nil
+}
+
+// ValidateLogDataProtectionPolicy checks that a custom data protection policy is well formed
+// enough to submit: valid JSON with a Version and at least one statement
+func ValidateLogDataProtectionPolicy(document string) error {
+	var doc dataProtectionPolicyDocument
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return fmt.Errorf("failed to parse data protection policy: %w", err)
+	}
+	if doc.Version == "" {
+		return fmt.Errorf("invalid data protection policy: Version is required")
+	}
+	if len(doc.Statement) == 0 {
+		return fmt.Errorf("invalid data protection policy: at least one Statement is required")
+	}
+	return nil
+}
+
+// applyLogDataProtection attaches the data protection policy to the log group
+func (d *Deployer) applyLogDataProtection(ctx context.Context, logGroupName string) error {
+	policy := d.config.LogDataProtectionPolicy
+	if policy == "" {
+		var err error
+		if policy, err = GenerateLogDataProtectionPolicy(); err != nil {
+			return err
+		}
+	}
+
+	_, err := d.cwLogsClient.PutDataProtectionPolicy(ctx, &cloudwatchlogs.PutDataProtectionPolicyInput{
+		LogGroupIdentifier: aws.String(logGroupName),
+		PolicyDocument:     aws.String(policy),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put data protection policy: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateLogDataProtectionPolicy(t *testing.T) {
+	policyJSON, err := GenerateLogDataProtectionPolicy()
+	require.NoError(t, err)
+	require.NoError(t, ValidateLogDataProtectionPolicy(policyJSON))
+
+	var policy dataProtectionPolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(policyJSON), &policy))
+
+	assert.Equal(t, "2021-06-01", policy.Version)
+	require.Len(t, policy.Statement, 2)
+	assert.Contains(t, policy.Statement[0].Operation, "Audit")
+	assert.Contains(t, policy.Statement[1].Operation, "Deidentify")
+	for _, statement := range policy.Statement {
+		assert.ElementsMatch(t, []string{accountIDDataIdentifier, arnDataIdentifier}, statement.DataIdentifier)
+	}
+
+	require.NotNil(t, policy.Configuration)
+	for _, identifier := range policy.Configuration.CustomDataIdentifier {
+		// Custom data identifier patterns are limited to 200 characters
+		assert.LessOrEqual(t, len(identifier.Regex), 200)
+		_, err := regexp.Compile(identifier.Regex)
+		assert.NoError(t, err)
+	}
+}
+
+func TestDataIdentifierPatterns(t *testing.T) {
+	arn := regexp.MustCompile(arnPattern)
+	assert.True(t, arn.MatchString("created arn:aws:iam::123456789012:oidc-provider/oidc.example.com/abc"))
+	assert.True(t, arn.MatchString("arn:aws-us-gov:lambda:us-gov-west-1:123456789012:function:test"))
+	assert.False(t, arn.MatchString("no identifiers here"))
+
+	accountID := regexp.MustCompile(accountIDPattern)
+	assert.True(t, accountID.MatchString("account 123456789012 ready"))
+	assert.False(t, accountID.MatchString("1234567890123"))
+}
+
+func TestValidateLogDataProtectionPolicy(t *testing.T) {
+	assert.ErrorContains(t, ValidateLogDataProtectionPolicy(`{`), "failed to parse")
+	assert.ErrorContains(t, ValidateLogDataProtectionPolicy(`{"Statement":[{"Sid":"a"}]}`), "Version is required")
+	assert.ErrorContains(t, ValidateLogDataProtectionPolicy(`{"Version":"2021-06-01","Statement":[]}`), "at least one Statement")
+}
+
+func TestApplyLogDataProtection(t *testing.T) {
+	var applied *cloudwatchlogs.PutDataProtectionPolicyInput
+	mockCWLogs := &mockCloudWatchLogsClient{
+		putDataProtectionFunc: func(ctx context.Context, params *cloudwatchlogs.PutDataProtectionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutDataProtectionPolicyOutput, error) {
+			applied = params
+			return &cloudwatchlogs.PutDataProtectionPolicyOutput{}, nil
+		},
+	}
+
+	deployer := NewDeployer(nil, nil, mockCWLogs, DeploymentConfig{LogDataProtection: true})
+	require.NoError(t, deployer.applyLogDataProtection(context.Background(), "/aws/lambda/test-function"))
+
+	require.NotNil(t, applied)
+	assert.Equal(t, "/aws/lambda/test-function", *applied.LogGroupIdentifier)
+	defaultPolicy, err := GenerateLogDataProtectionPolicy()
+	require.NoError(t, err)
+	assert.Equal(t, defaultPolicy, *applied.PolicyDocument)
+
+	custom := `{"Name":"custom","Version":"2021-06-01","Statement":[{"Sid":"audit","DataIdentifier":["arn:aws:dataprotection::aws:data-identifier/AwsSecretKey"],"Operation":{"Audit":{"FindingsDestination":{}}}}]}`
+	deployer = NewDeployer(nil, nil, mockCWLogs, DeploymentConfig{LogDataProtection: true, LogDataProtectionPolicy: custom})
+	require.NoError(t, deployer.applyLogDataProtection(context.Background(), "/aws/lambda/test-function"))
+	assert.Equal(t, custom, *applied.PolicyDocument)
+}
+
+func TestApplyLogDataProtection_Error(t *testing.T) {
+	mockCWLogs := &mockCloudWatchLogsClient{
+		putDataProtectionFunc: func(ctx context.Context, params *cloudwatchlogs.PutDataProtectionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutDataProtectionPolicyOutput, error) {
+			return nil, errors.New("access denied")
+		},
+	}
+
+	deployer := NewDeployer(nil, nil, mockCWLogs, DeploymentConfig{LogDataProtection: true})
+	err := deployer.applyLogDataProtection(context.Background(), "/aws/lambda/test-function")
+	assert.ErrorContains(t, err, "failed to put data protection policy")
+}
@@ -5,14 +5,29 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
 	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // AWS service interfaces (defined in internal/aws/interfaces.go, but redefined here for package independence)
@@ -27,8 +42,28 @@ type LambdaAPI interface {
 		optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
 	AddPermission(ctx context.Context, params *lambda.AddPermissionInput,
 		optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error)
+	RemovePermission(ctx context.Context, params *lambda.RemovePermissionInput,
+		optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error)
+	GetPolicy(ctx context.Context, params *lambda.GetPolicyInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error)
 	TagResource(ctx context.Context, params *lambda.TagResourceInput,
 		optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error)
+	PublishVersion(ctx context.Context, params *lambda.PublishVersionInput,
+		optFns ...func(*lambda.Options)) (*lambda.PublishVersionOutput, error)
+	ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error)
+	ListAliases(ctx context.Context, params *lambda.ListAliasesInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error)
+	DeleteFunction(ctx context.Context, params *lambda.DeleteFunctionInput,
+		optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error)
+	GetAlias(ctx context.Context, params *lambda.GetAliasInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error)
+	CreateAlias(ctx context.Context, params *lambda.CreateAliasInput,
+		optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error)
+	UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput,
+		optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error)
+	Invoke(ctx context.Context, params *lambda.InvokeInput,
+		optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
 }
 
 type IAMAPI interface {
@@ -38,6 +73,18 @@ type IAMAPI interface {
 		optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
 	PutRolePolicy(ctx context.Context, params *iam.PutRolePolicyInput,
 		optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error)
+	UpdateAssumeRolePolicy(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error)
+	TagRole(ctx context.Context, params *iam.TagRoleInput,
+		optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error)
+	ListRoleTags(ctx context.Context, params *iam.ListRoleTagsInput,
+		optFns ...func(*iam.Options)) (*iam.ListRoleTagsOutput, error)
+	DeleteRolePolicy(ctx context.Context, params *iam.DeleteRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error)
+	DeleteRole(ctx context.Context, params *iam.DeleteRoleInput,
+		optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error)
+	ListAccountAliases(ctx context.Context, params *iam.ListAccountAliasesInput,
+		optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error)
 }
 
 type CloudWatchLogsAPI interface {
@@ -49,122 +96,788 @@ type CloudWatchLogsAPI interface {
 		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
 	TagLogGroup(ctx context.Context, params *cloudwatchlogs.TagLogGroupInput,
 		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagLogGroupOutput, error)
+	PutDataProtectionPolicy(ctx context.Context, params *cloudwatchlogs.PutDataProtectionPolicyInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutDataProtectionPolicyOutput, error)
+	DeleteLogGroup(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error)
+	ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+}
+
+type STSAPI interface {
+	GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput,
+		optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
 }
 
 // DeploymentConfig holds configuration for Lambda deployment
 type DeploymentConfig struct {
 	FunctionName      string
 	ExecutionRoleName string
+
+	// ExecutionRoleARN names a pre-created execution role, such as one provisioned by
+	// the customer's own IaC, used instead of creating one. It is never modified, so
+	// deploying needs no IAM writes; its trust policy is read to check Lambda can assume
+	// it. ExecutionRoleName is taken from it.
+	ExecutionRoleARN string
+
 	SourceDir         string
 	CLMServiceRoleARN string // Optional: for resource-based policy
-	SourceAccountID   string // Optional: for resource-based policy
+	SourceAccountID   string // Optional: for resource-based policy (defaults to the caller's account when an STS client is set)
+
+	// ResourcePolicyQualifier scopes the CLM statement to an alias or version, such as
+	// live, so CLM cannot invoke unpublished $LATEST code during a rollout. The
+	// statement on the unqualified function is removed. Empty allows unqualified invocations.
+	ResourcePolicyQualifier string
+
+	Region            string // Optional: region used to render scoped ARNs
 	Runtime           lambdaTypes.Runtime
 	MemorySize        int32
 	Timeout           int32
 	Architecture      lambdaTypes.Architecture
+	EphemeralStorage  int32 // Optional: /tmp size in MB; zero keeps the Lambda default of 512
+	SnapStart         bool  // Snapshot published versions to cut cold starts; requires PublishVersion
 	Tags              map[string]string
+	PublishVersion    bool       // Publish an immutable version after each deploy
+	KeepVersions      int        // Optional: prune published versions beyond the newest N (0 disables)
+	TagPolicy         *TagPolicy // Optional: tag requirements validated before any resource is created
+	Adopt             bool       // Take ownership of pre-existing resources not managed by rosactl
+	BuildArtifactsDir string     // Optional: keep the compiled binary, package, and build log here
+	PackageCacheDir   string     // Optional: reuse packages built from the same source from this PackageCache directory
+	NoRollback        bool       // Leave resources created by a failed deploy in place instead of deleting them
+	Prime             bool       // Invoke the function once after deploying so the first request skips the cold start
+
+	// TagKeyFormat rewrites the prefix and case of rosactl's own tag keys, such as
+	// rosa:managed, for tag policies that require a different spelling. When it is the
+	// default and TagPolicy names rosa: keys, the policy's spelling is used.
+	TagKeyFormat tagkey.Format
+
+	// TrustPolicyOverride replaces the default execution role trust policy, for example to add
+	// aws:SourceArn or aws:SourceAccount conditions. It must allow lambda.amazonaws.com to assume the role.
+	TrustPolicyOverride string
+
+	// LogGroupName is the log group the function writes to, for organizations with log
+	// group naming conventions; empty uses Lambda's default of /aws/lambda/<function>.
+	// A pre-created group is used as is, or reconciled when Adopt is set.
+	LogGroupName string
+
+	// CodeSigningConfigARN is the Lambda code signing config attached to the function,
+	// for accounts that require one. The package is uploaded unsigned, so the config's
+	// untrusted artifact policy must be Warn; a config that enforces signed code is refused.
+	CodeSigningConfigARN string
+
+	// Naming is applied by NewDeployer to FunctionName, ExecutionRoleName, and the last
+	// segment of LogGroupName, for accounts with resource naming conventions or several
+	// deployment environments; resources are tagged with its environment. The name of a
+	// pre-created ExecutionRoleARN is used as is.
+	Naming Naming
+
+	// LogRetentionDays is how long the log group keeps the function's logs, one of the
+	// periods CloudWatch Logs accepts; zero keeps DefaultLogRetentionDays
+	LogRetentionDays int32
+
+	// Environment holds environment variables set on the function alongside rosactl's own.
+	// Variables set out-of-band are left in place.
+	Environment map[string]string
+
+	// Tracing enables active X-Ray tracing and grants the execution role the X-Ray
+	// writes it needs; when false an existing function's tracing mode is left unchanged
+	Tracing bool
+
+	// LogDataProtection attaches a data protection policy masking account IDs and ARNs to the
+	// log group. LogDataProtectionPolicy optionally replaces the default policy document.
+	LogDataProtection       bool
+	LogDataProtectionPolicy string
+
+	// CLIVersion is the rosactl release stamped on the function with the package checksum
+	CLIVersion string
+
+	// ProviderDescription and PlatformEnvironment are tagged on the OIDC providers the
+	// provisioner creates, with the function name and CLIVersion, since IAM OIDC
+	// providers have no description of their own
+	ProviderDescription string
+	PlatformEnvironment string
+
+	// BindableRolePath is the IAM path, such as /rosa-operators/, of the roles whose trust
+	// policies bind-roles requests may federate with the OIDC provider. The execution
+	// role is granted GetRole and UpdateAssumeRolePolicy on them only. Empty refuses
+	// bind-roles.
+	BindableRolePath string
+
+	// LedgerTableName is the DynamoDB table the provisioner records each provisioning
+	// and bind-roles request in, as rosactl oidc history reads it. The table is created
+	// when missing, which needs a client set with WithLedgerTableClient, and the
+	// execution role is granted dynamodb:PutItem on it. Empty disables the ledger.
+	LedgerTableName string
+
+	// Steps limits the deployment to some of its steps; empty runs every step
+	Steps StepSelection
+
+	// Timeouts bounds compiling, uploading, IAM propagation, and post-deploy verification
+	Timeouts StepTimeouts
+}
+
+// Defaults for the provisioner's function, execution role, and sizing
+const (
+	DefaultFunctionName      = "rosa-oidc-provisioner"
+	DefaultExecutionRoleName = "rosa-oidc-provisioner-execution"
+	DefaultMemorySize        = 128 // MB
+	DefaultTimeout           = 60  // seconds
+)
+
+// Lambda function configuration limits
+const (
+	MinMemorySize = 128   // MB
+	MaxMemorySize = 10240 // MB
+	MinTimeout    = 1     // seconds
+	MaxTimeout    = 900   // seconds
+
+	MinEphemeralStorage = 512   // MB
+	MaxEphemeralStorage = 10240 // MB
+)
+
+const (
+	// ManagedTagKey marks resources owned by rosactl
+	ManagedTagKey   = "rosa:managed"
+	ManagedTagValue = "true"
+
+	// FunctionARNTagKey links a log group to the function writing to it
+	FunctionARNTagKey = "rosa:function-arn"
+
+	// DeployedByTagKey and DeployedAtTagKey record who last deployed the function and when
+	DeployedByTagKey = "rosa:deployed-by"
+	DeployedAtTagKey = "rosa:deployed-at"
+)
+
+// permissionsPolicyName is the inline policy attached to the execution role
+const permissionsPolicyName = "OIDCProvisionerPermissions"
+
+// Resource types recorded in DeploymentResult.Resources
+const (
+	ResourceTypeExecutionRole = "iam-role"
+	ResourceTypeFunction      = "lambda-function"
+	ResourceTypeLogGroup      = "log-group"
+)
+
+// Resource actions recorded in DeploymentResult.Resources
+const (
+	ResourceActionCreated   = deploy.ActionCreated
+	ResourceActionUpdated   = deploy.ActionUpdated
+	ResourceActionAdopted   = deploy.ActionAdopted
+	ResourceActionUnchanged = deploy.ActionUnchanged
+)
+
+// ResourceRecord describes what a deployment did to a single resource
+type ResourceRecord struct {
+	Type       string
+	Identifier string
+	Action     string
+}
+
+// Policy types recorded in DeploymentResult.Policies
+const (
+	PolicyTypeTrust          = "trust"
+	PolicyTypeInline         = "inline"
+	PolicyTypeResource       = "resource"
+	PolicyTypeDataProtection = "data-protection"
+)
+
+// resourcePolicyStatementID identifies the statement allowing CLM to invoke the function
+const resourcePolicyStatementID = "AllowCLMInvoke"
+
+// AttachedPolicy describes a policy applied to a deployed resource
+type AttachedPolicy struct {
+	Type        string
+	Name        string
+	AttachedTo  string // ARN or name of the resource the policy is attached to
+	Description string
+}
+
+// UnmanagedResourceError is returned when a resource exists but is not owned by rosactl
+type UnmanagedResourceError struct {
+	Type       string
+	Identifier string
+}
+
+func (e *UnmanagedResourceError) Error() string {
+	return fmt.Sprintf("%s %s already exists but is not managed by rosactl (missing %s=%s tag); "+
+		"re-run with adopt enabled to take ownership of it", e.Type, e.Identifier, ManagedTagKey, ManagedTagValue)
 }
 
 // Deployer orchestrates Lambda deployment
 type Deployer struct {
-	lambdaClient LambdaAPI
-	iamClient    IAMAPI
-	cwLogsClient CloudWatchLogsAPI
-	config       DeploymentConfig
+	lambdaClient      LambdaAPI
+	iamClient         IAMAPI
+	cwLogsClient      CloudWatchLogsAPI
+	stsClient         STSAPI
+	codeSigningClient CodeSigningAPI
+	ledgerClient      LedgerTableAPI
+	config            DeploymentConfig
+	keys              tagkey.Format // Format of rosactl's own tag keys
+	scope             ARNScope
+	resources         []ResourceRecord
+	preserved         []UnmanagedSetting // Settings on the existing function rosactl left unchanged
+	currentStep       string             // Step the current deployment is running
+	completedSteps    []string           // Steps the current deployment finished
+	callerARN         string             // Identity running the deployment, when an STS client is set
+	accountAlias      string             // Alias of the target account, when it has one and the caller may read it
+	deployedAt        time.Time          // Start of the current deployment
+	checksum          string             // Checksum of the package built by the current deployment
+	packageCached     bool               // Whether that package came from the package cache
+	keptStamp         *Stamp             // Stamp of the deployed code when the function-code step is skipped
+	functionRoleARN   string             // Execution role of the existing function, when rosactl manages it
+	warnings          io.Writer          // Receives non-fatal deployment warnings
+	tracerProvider    trace.TracerProvider
+	tracer            trace.Tracer
+	stepSpan          trace.Span // Span of the running step
+	pollInterval      time.Duration
+	now               func() time.Time
+}
+
+// DeployerOption configures optional Deployer behavior
+type DeployerOption func(*Deployer)
+
+// WithSTSClient lets the deployer resolve the caller's account ID so policies and
+// tags use account- and region-scoped ARNs instead of wildcards
+func WithSTSClient(stsClient STSAPI) DeployerOption {
+	return func(d *Deployer) {
+		d.stsClient = stsClient
+	}
+}
+
+// WithWarningOutput sends non-fatal deployment warnings to w instead of stderr
+func WithWarningOutput(w io.Writer) DeployerOption {
+	return func(d *Deployer) {
+		d.warnings = w
+	}
+}
+
+// WithTracerProvider records deployments, their steps, and the resources they touch
+// as spans; by default spans go to the global OpenTelemetry tracer provider
+func WithTracerProvider(tp trace.TracerProvider) DeployerOption {
+	return func(d *Deployer) {
+		d.tracerProvider = tp
+	}
 }
 
 // NewDeployer creates a new Lambda deployer
-func NewDeployer(lambdaClient LambdaAPI, iamClient IAMAPI, cwLogsClient CloudWatchLogsAPI, config DeploymentConfig) *Deployer {
-	return &Deployer{
+func NewDeployer(lambdaClient LambdaAPI, iamClient IAMAPI, cwLogsClient CloudWatchLogsAPI, config DeploymentConfig, opts ...DeployerOption) *Deployer {
+	d := &Deployer{
 		lambdaClient: lambdaClient,
 		iamClient:    iamClient,
 		cwLogsClient: cwLogsClient,
 		config:       config,
+		keys:         config.TagKeyFormat,
+		scope:        ARNScope{Region: config.Region},
+		warnings:     os.Stderr,
+		pollInterval: defaultPollInterval,
+		now:          time.Now,
+	}
+	if d.keys.IsDefault() && config.TagPolicy != nil {
+		d.keys = config.TagPolicy.KeyFormat()
+	}
+	d.config.FunctionName = config.Naming.Apply(config.FunctionName)
+	d.config.ExecutionRoleName = config.Naming.Apply(config.ExecutionRoleName)
+	d.config.LogGroupName = config.Naming.ApplyLogGroup(config.LogGroupName)
+	if config.ExecutionRoleARN != "" {
+		d.config.ExecutionRoleName = roleNameFromARN(config.ExecutionRoleARN)
 	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	if d.tracerProvider == nil {
+		d.tracerProvider = otel.GetTracerProvider()
+	}
+	d.tracer = d.tracerProvider.Tracer(tracerName)
+	return d
 }
 
 // DeploymentResult holds the result of a deployment
 type DeploymentResult struct {
-	FunctionARN     string
-	FunctionName    string
-	ExecutionRole   string
-	LogGroupName    string
-	Status          string // "created", "updated", "already_exists"
-	PackageSize     int
-	PackageChecksum string
+	FunctionARN       string
+	FunctionName      string
+	ExecutionRole     string
+	LogGroupName      string
+	Status            string // "created", "updated", "already_exists"
+	PackageSize       int
+	PackageChecksum   string
+	PackageCached     bool             // Whether the package was reused from PackageCacheDir instead of compiled
+	Version           string           // Published version, empty unless PublishVersion is set
+	PrunedVersions    []string         // Versions deleted by the retention policy
+	Resources         []ResourceRecord // Per-resource actions taken by the deployment
+	LogDataProtection bool             // Whether a data protection policy was attached to the log group
+	LogRetentionDays  int32            // Retention configured for the log group
+	DeployedBy        string           // Caller ARN, empty when the caller is unknown
+	AccountID         string           // Target account, empty when the caller is unknown
+	AccountAlias      string           // Target account's alias, empty when it has none or it could not be read
+	Region            string
+	Partition         string // Such as aws, aws-cn, or aws-us-gov; empty when the caller is unknown
+	DeployedAt        time.Time
+	Policies          []AttachedPolicy // Policies attached to the deployed resources
+	PrimeLatency      time.Duration    // Round trip of the priming invocation, zero unless Prime is set and it succeeded
+
+	// UnmanagedSettings lists settings found on an existing function that rosactl does
+	// not manage, such as a VPC config or extra environment variables; they were preserved
+	UnmanagedSettings []UnmanagedSetting
+	SkippedSteps      []string // Steps left out by DeploymentConfig.Steps
+	Naming            Naming   // Naming convention applied to the resource names
 }
 
-// Deploy orchestrates the full Lambda deployment
+// Deploy orchestrates the full Lambda deployment. If it fails after creating
+// resources, they are rolled back unless NoRollback is set, and a
+// *PartialFailureError describes what was created and what remains. When ctx is
+// cancelled the deployment stops between steps and the error wraps a *CancelledError.
 func (d *Deployer) Deploy(ctx context.Context) (*DeploymentResult, error) {
-	// Step 1: Ensure IAM execution role exists
-	roleARN, err := d.ensureExecutionRole(ctx)
+	d.resources = nil
+	d.preserved = nil
+	d.currentStep = ""
+	d.completedSteps = nil
+	d.deployedAt = d.now().UTC()
+	d.checksum = ""
+	d.keptStamp = nil
+	d.stepSpan = nil
+
+	ctx, span := d.startSpan(ctx, "Deploy")
+	result, err := d.deploy(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to ensure execution role: %w", err)
+		var cancelledErr *CancelledError
+		if ctx.Err() != nil && !errors.As(err, &cancelledErr) {
+			err = d.cancelled(err)
+		}
+		d.endStep(err)
+		err = d.handleFailure(ctx, d.tagPolicyAPIError(err))
+	}
+	if d.scope.AccountID != "" {
+		span.SetAttributes(semconv.CloudAccountID(d.scope.AccountID))
 	}
+	endSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
 
-	// Step 2: Build Lambda package
-	packageBuilder := NewPackageBuilder(d.config.SourceDir)
-	zipData, checksum, err := packageBuilder.Build()
+// deploy runs the deployment steps; each runs with its step span's context, derived
+// from deployCtx
+func (d *Deployer) deploy(deployCtx context.Context) (*DeploymentResult, error) {
+	// Step 0: Fail fast on invalid names, tags, tag policy violations, or settings before creating anything
+	ctx, err := d.beginStep(deployCtx, StepValidate)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build Lambda package: %w", err)
+		return nil, err
+	}
+	if d.config.ExecutionRoleARN != "" {
+		if _, err := ValidateExecutionRoleARN(d.config.ExecutionRoleARN); err != nil {
+			return nil, err
+		}
+		if d.config.TrustPolicyOverride != "" {
+			return nil, errors.New("a trust policy override cannot be applied to a pre-created execution role")
+		}
+	}
+	if err := d.config.Naming.Validate(); err != nil {
+		return nil, err
+	}
+	if err := ValidateNames(d.config.FunctionName, d.config.ExecutionRoleName); err != nil {
+		return nil, err
+	}
+	if d.config.LogGroupName != "" {
+		if err := ValidateLogGroupName(d.config.LogGroupName); err != nil {
+			return nil, err
+		}
+	}
+	if d.config.CodeSigningConfigARN != "" {
+		if err := ValidateCodeSigningConfigARN(d.config.CodeSigningConfigARN); err != nil {
+			return nil, err
+		}
+	}
+	if d.config.ResourcePolicyQualifier != "" {
+		if err := ValidateQualifier(d.config.ResourcePolicyQualifier); err != nil {
+			return nil, err
+		}
+	}
+	if err := ValidateEnvironment(d.config.Environment); err != nil {
+		return nil, err
+	}
+	if err := d.ValidateTags(); err != nil {
+		return nil, err
+	}
+	if err := ValidateFunctionLimits(d.config.MemorySize, d.config.Timeout); err != nil {
+		return nil, err
+	}
+	if d.config.Runtime != "" && !isSupportedRuntime(d.config.Runtime) {
+		return nil, fmt.Errorf("unsupported runtime %q (expected %s)", d.config.Runtime, supportedRuntimeList())
+	}
+	if err := ValidateEphemeralStorage(d.config.EphemeralStorage); err != nil {
+		return nil, err
+	}
+	if err := ValidateLogRetention(d.config.LogRetentionDays); err != nil {
+		return nil, err
+	}
+	if err := ValidateBindableRolePath(d.config.BindableRolePath); err != nil {
+		return nil, err
+	}
+	if err := d.validateLedger(); err != nil {
+		return nil, err
+	}
+	if err := d.config.Steps.Validate(); err != nil {
+		return nil, err
+	}
+	if d.config.SnapStart {
+		if err := ValidateSnapStart(d.config.Runtime, d.config.EphemeralStorage, d.config.PublishVersion); err != nil {
+			return nil, err
+		}
+	}
+	if d.config.TrustPolicyOverride != "" {
+		if err := ValidateTrustPolicy(d.config.TrustPolicyOverride); err != nil {
+			return nil, err
+		}
+	}
+	if d.config.LogDataProtectionPolicy != "" {
+		if err := ValidateLogDataProtectionPolicy(d.config.LogDataProtectionPolicy); err != nil {
+			return nil, err
+		}
 	}
 
-	// Step 3: Check if Lambda function exists
+	// Resolve the target account so policies can be scoped to it
+	if err := d.resolveScope(ctx); err != nil {
+		return nil, err
+	}
+	d.resolveAccountAlias(ctx)
+	if err := d.checkCodeSigningConfig(ctx); err != nil {
+		return nil, err
+	}
+
+	// Check whether the function exists before touching anything, so an unmanaged
+	// function is refused before its role or log group are modified
 	exists, existingFunc, err := d.checkFunctionExists(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check if function exists: %w", err)
 	}
 
-	var functionARN string
-	var status string
+	functionAction := ResourceActionUpdated
+	if exists && !d.isManaged(existingFunc.Tags) {
+		if !d.config.Adopt {
+			return nil, &UnmanagedResourceError{Type: ResourceTypeFunction, Identifier: d.config.FunctionName}
+		}
+		functionAction = ResourceActionAdopted
+	}
+	if err := d.checkStepSelection(exists); err != nil {
+		return nil, err
+	}
+	if exists && d.isManaged(existingFunc.Tags) && existingFunc.Configuration != nil {
+		d.functionRoleARN = aws.ToString(existingFunc.Configuration.Role)
+	}
+	if exists && !d.runs(StepFunctionCode) {
+		// The code is left in place, so keep the stamp describing it
+		stamp := ReadStamp(existingFunc, d.keys)
+		d.keptStamp = &stamp
+	}
+	d.completeStep()
+
+	// Step 1: Ensure IAM execution role exists
+	var roleARN string
+	var policies []AttachedPolicy
+	if d.config.ExecutionRoleARN != "" {
+		if d.runs(StepExecutionRole) {
+			if ctx, err = d.beginStep(deployCtx, StepExecutionRole); err != nil {
+				return nil, err
+			}
+			if err := d.checkPreCreatedRole(ctx); err != nil {
+				return nil, err
+			}
+			d.completeStep()
+		}
+		roleARN = d.config.ExecutionRoleARN
+	} else if d.runs(StepExecutionRole) {
+		if ctx, err = d.beginStep(deployCtx, StepExecutionRole); err != nil {
+			return nil, err
+		}
+		role := d.executionRoleResource(d.config.ExecutionRoleName)
+		if _, err := role.Ensure(ctx); err != nil {
+			return nil, fmt.Errorf("failed to ensure execution role: %w", err)
+		}
+		roleARN = role.arn
+		d.completeStep()
 
-	if exists {
-		// Update existing function
-		functionARN = *existingFunc.Configuration.FunctionArn
-		if err := d.updateFunction(ctx, zipData, roleARN); err != nil {
-			return nil, fmt.Errorf("failed to update function: %w", err)
+		trustDescription := "Allows lambda.amazonaws.com to assume the role"
+		if d.config.TrustPolicyOverride != "" {
+			trustDescription = "Custom trust policy supplied with --trust-policy"
 		}
-		status = "updated"
-	} else {
-		// Create new function
-		functionARN, err = d.createFunction(ctx, zipData, roleARN)
+		policies = append(policies,
+			AttachedPolicy{Type: PolicyTypeTrust, Name: "AssumeRolePolicy", AttachedTo: roleARN, Description: trustDescription},
+			AttachedPolicy{Type: PolicyTypeInline, Name: permissionsPolicyName, AttachedTo: roleARN,
+				Description: "Manages OIDC providers in the account and writes the function's logs"},
+		)
+	} else if roleARN, err = d.skippedRoleARN(existingFunc); err != nil {
+		return nil, err
+	}
+
+	// Step 1b: Ensure the ledger table exists before the function is pointed at it
+	if d.config.LedgerTableName != "" && d.runs(StepLedgerTable) {
+		if ctx, err = d.beginStep(deployCtx, StepLedgerTable); err != nil {
+			return nil, err
+		}
+		if _, err := d.ledgerTableResource(d.config.LedgerTableName).Ensure(ctx); err != nil {
+			return nil, err
+		}
+		if d.config.ExecutionRoleARN != "" {
+			fmt.Fprintf(d.warnings, "Warning: execution role %s must allow dynamodb:PutItem on %s for requests to be recorded in the ledger\n",
+				d.config.ExecutionRoleARN, d.scope.DynamoDBTableARN(d.config.LedgerTableName))
+		}
+		d.completeStep()
+	}
+
+	// Step 2: Build Lambda package
+	var zipData []byte
+	var checksum string
+	if d.runs(StepFunctionCode) {
+		if ctx, err = d.beginStep(deployCtx, StepPackage); err != nil {
+			return nil, err
+		}
+		var builderOpts []PackageBuilderOption
+		if d.config.BuildArtifactsDir != "" {
+			builderOpts = append(builderOpts, WithArtifactsDir(d.config.BuildArtifactsDir))
+		}
+		if d.config.Architecture != "" {
+			builderOpts = append(builderOpts, WithTargetArchitecture(d.config.Architecture))
+		}
+		if d.config.PackageCacheDir != "" {
+			builderOpts = append(builderOpts, WithPackageCache(NewPackageCache(d.config.PackageCacheDir)))
+		}
+		packageBuilder := NewPackageBuilder(d.config.SourceDir, builderOpts...)
+		err = d.withStepTimeout(ctx, TimedCompile, func(ctx context.Context) error {
+			var err error
+			zipData, checksum, err = packageBuilder.BuildContext(ctx)
+			return err
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to create function: %w", err)
+			return nil, fmt.Errorf("failed to build Lambda package: %w", err)
+		}
+		d.checksum = checksum
+		d.packageCached = packageBuilder.Cached()
+		d.completeStep()
+	}
+
+	// Step 3: Create or update the Lambda function
+	var functionARN string
+	status := "already_exists"
+	if d.runs(StepFunctionCode) || d.runs(StepFunctionConfig) {
+		if ctx, err = d.beginStep(deployCtx, StepFunction); err != nil {
+			return nil, err
 		}
+		function := d.functionResource(d.config.FunctionName)
+		function.zipData = zipData
+		function.roleARN = roleARN
+		if exists {
+			function.existing = existingFunc
+			function.action = functionAction
+		}
+		if _, err := function.Ensure(ctx); err != nil {
+			return nil, err
+		}
+		functionARN = function.arn
 		status = "created"
+		if exists {
+			status = "updated"
+		}
+		d.completeStep()
+	} else {
+		functionARN = aws.ToString(existingFunc.Configuration.FunctionArn)
 	}
 
 	// Step 4: Add resource-based policy (if CLM service role ARN is provided)
-	if d.config.CLMServiceRoleARN != "" && d.config.SourceAccountID != "" {
-		if err := d.addResourcePolicy(ctx); err != nil {
+	if d.config.CLMServiceRoleARN != "" && d.sourceAccountID() != "" && d.runs(StepResourcePolicy) {
+		if ctx, err = d.beginStep(deployCtx, StepResourcePolicy); err != nil {
+			return nil, err
+		}
+		if _, err := (&resourcePolicyResource{d: d}).Ensure(ctx); err != nil {
 			// Don't fail deployment if policy already exists
-			fmt.Printf("Warning: failed to add resource policy: %v\n", err)
+			fmt.Fprintf(d.warnings, "Warning: failed to add resource policy: %v\n", err)
+		} else {
+			policies = append(policies, AttachedPolicy{
+				Type:        PolicyTypeResource,
+				Name:        resourcePolicyStatementID,
+				AttachedTo:  functionARN,
+				Description: fmt.Sprintf("Allows %s to invoke the function", d.config.CLMServiceRoleARN),
+			})
 		}
+		d.completeStep()
 	}
 
 	// Step 5: Ensure CloudWatch Log Group exists
-	logGroupName := fmt.Sprintf("/aws/lambda/%s", d.config.FunctionName)
-	if err := d.ensureLogGroup(ctx, logGroupName); err != nil {
-		// Don't fail deployment if log group creation fails
-		fmt.Printf("Warning: failed to ensure log group: %v\n", err)
+	logGroupName := d.logGroupName()
+	if d.runs(StepLogGroup) {
+		if ctx, err = d.beginStep(deployCtx, StepLogGroup); err != nil {
+			return nil, err
+		}
+		if _, err := d.logGroupResource(logGroupName).Ensure(ctx); err != nil {
+			// Don't fail deployment if log group creation fails
+			fmt.Fprintf(d.warnings, "Warning: failed to ensure log group: %v\n", err)
+		}
+
+		// Logs must not be written unmasked when data protection is required
+		if d.config.LogDataProtection {
+			if err := d.applyLogDataProtection(ctx, logGroupName); err != nil {
+				return nil, err
+			}
+			description := "Masks AWS account IDs and ARNs in log events"
+			if d.config.LogDataProtectionPolicy != "" {
+				description = "Custom data protection policy supplied with --log-data-protection-policy"
+			}
+			policies = append(policies, AttachedPolicy{
+				Type:        PolicyTypeDataProtection,
+				Name:        "DataProtectionPolicy",
+				AttachedTo:  logGroupName,
+				Description: description,
+			})
+		}
+		d.completeStep()
 	}
 
 	// Step 6: Tag Lambda function
-	if len(d.config.Tags) > 0 {
+	if d.runs(StepTagFunction) {
+		if ctx, err = d.beginStep(deployCtx, StepTagFunction); err != nil {
+			return nil, err
+		}
 		if err := d.tagFunction(ctx, functionARN); err != nil {
-			fmt.Printf("Warning: failed to tag function: %v\n", err)
+			fmt.Fprintf(d.warnings, "Warning: failed to tag function: %v\n", err)
 		}
+		d.completeStep()
+	}
+
+	// Step 7: Verify the function is active before reporting success or publishing
+	if ctx, err = d.beginStep(deployCtx, StepVerify); err != nil {
+		return nil, err
+	}
+	if err := d.waitForFunctionActive(ctx); err != nil {
+		return nil, fmt.Errorf("failed to verify function: %w", err)
+	}
+	d.completeStep()
+
+	result := &DeploymentResult{
+		FunctionARN:       functionARN,
+		FunctionName:      d.config.FunctionName,
+		Naming:            d.config.Naming,
+		ExecutionRole:     roleARN,
+		LogGroupName:      logGroupName,
+		Status:            status,
+		PackageSize:       len(zipData),
+		PackageChecksum:   d.stamp().PackageChecksum,
+		PackageCached:     d.packageCached,
+		Resources:         d.resources,
+		LogDataProtection: d.config.LogDataProtection && d.runs(StepLogGroup),
+		LogRetentionDays:  d.logRetentionDays(),
+		DeployedBy:        d.callerARN,
+		AccountID:         d.scope.AccountID,
+		AccountAlias:      d.accountAlias,
+		Region:            d.config.Region,
+		Partition:         d.scope.Partition,
+		DeployedAt:        d.deployedAt,
+		Policies:          policies,
+		UnmanagedSettings: d.preserved,
+		SkippedSteps:      d.config.Steps.Skipped(),
+	}
+
+	// Step 8: Publish an immutable version and apply the retention policy
+	if d.config.PublishVersion && d.runs(StepPublishVersion) {
+		if ctx, err = d.beginStep(deployCtx, StepPublishVersion); err != nil {
+			return nil, err
+		}
+		version, err := d.publishVersion(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to publish version: %w", err)
+		}
+		result.Version = version
+
+		if d.config.KeepVersions > 0 {
+			pruned, err := d.PruneVersions(ctx, d.config.KeepVersions)
+			if err != nil {
+				fmt.Fprintf(d.warnings, "Warning: failed to prune old versions: %v\n", err)
+			}
+			result.PrunedVersions = pruned
+		}
+		d.completeStep()
+	}
+
+	// Step 9: Warm an execution environment; a failure only costs the first request a cold start
+	if d.config.Prime && d.runs(StepPrime) {
+		if ctx, err = d.beginStep(deployCtx, StepPrime); err != nil {
+			return nil, err
+		}
+		latency, err := d.prime(ctx, functionARN, result.Version)
+		if err != nil {
+			fmt.Fprintf(d.warnings, "Warning: failed to prime function: %v\n", err)
+		} else {
+			result.PrimeLatency = latency
+		}
+		d.completeStep()
+	}
+
+	return result, nil
+}
+
+// ValidateFunctionLimits checks memory size (MB) and timeout (seconds) against Lambda limits
+func ValidateFunctionLimits(memorySize, timeout int32) error {
+	if memorySize < MinMemorySize || memorySize > MaxMemorySize {
+		return fmt.Errorf("memory size %d MB is outside the Lambda limits of %d-%d MB", memorySize, MinMemorySize, MaxMemorySize)
+	}
+	if timeout < MinTimeout || timeout > MaxTimeout {
+		return fmt.Errorf("timeout %d seconds is outside the Lambda limits of %d-%d seconds", timeout, MinTimeout, MaxTimeout)
+	}
+	return nil
+}
+
+// ValidateEphemeralStorage checks the /tmp size (MB) against Lambda limits; zero means the default
+func ValidateEphemeralStorage(size int32) error {
+	if size != 0 && (size < MinEphemeralStorage || size > MaxEphemeralStorage) {
+		return fmt.Errorf("ephemeral storage %d MB is outside the Lambda limits of %d-%d MB", size, MinEphemeralStorage, MaxEphemeralStorage)
+	}
+	return nil
+}
+
+// ValidateLogRetention checks the log retention in days against the periods CloudWatch
+// Logs accepts; zero means the default
+func ValidateLogRetention(days int32) error {
+	if days == 0 || slices.Contains(logRetentionPeriods, days) {
+		return nil
+	}
+	periods := make([]string, len(logRetentionPeriods))
+	for i, period := range logRetentionPeriods {
+		periods[i] = strconv.Itoa(int(period))
 	}
+	return fmt.Errorf("log retention of %d days is not supported by CloudWatch Logs (expected one of %s)", days, strings.Join(periods, ", "))
+}
 
-	return &DeploymentResult{
-		FunctionARN:     functionARN,
-		FunctionName:    d.config.FunctionName,
-		ExecutionRole:   roleARN,
-		LogGroupName:    logGroupName,
-		Status:          status,
-		PackageSize:     len(zipData),
-		PackageChecksum: checksum,
-	}, nil
+// logRetentionDays returns the retention applied to the log group
+func (d *Deployer) logRetentionDays() int32 {
+	if d.config.LogRetentionDays != 0 {
+		return d.config.LogRetentionDays
+	}
+	return DefaultLogRetentionDays
+}
+
+// ValidateTags checks the configured tags against AWS tagging constraints and the tag policy, if one is set
+func (d *Deployer) ValidateTags() error {
+	if err := d.keys.Validate(); err != nil {
+		return err
+	}
+	if value, ok := d.keys.Lookup(d.config.Tags, ManagedTagKey); ok && value != ManagedTagValue {
+		return fmt.Errorf("tag %s is reserved for rosactl and must be %q", d.keys.Key(ManagedTagKey), ManagedTagValue)
+	}
+
+	if err := ValidateTagConstraints(d.resourceTags()); err != nil {
+		return err
+	}
+
+	if d.config.TagPolicy == nil {
+		return nil
+	}
+
+	// rosactl's own keys are checked too, so a policy requiring another spelling of
+	// them fails here rather than on the first tagging call
+	if violations := d.config.TagPolicy.Validate(d.resourceTags()); len(violations) > 0 {
+		return &TagPolicyError{Violations: violations}
+	}
+
+	return nil
 }
 
 // ensureExecutionRole creates or gets the Lambda execution role
@@ -176,7 +889,37 @@ func (d *Deployer) ensureExecutionRole(ctx context.Context) (string, error) {
 
 	if err == nil {
 		// Role exists
-		return *getOutput.Role.Arn, nil
+		roleARN := *getOutput.Role.Arn
+		// Roles created before rosactl tagged them carry no tags. The one the managed
+		// function runs as is such a role, and is tagged below like any managed role.
+		createdUntagged := roleARN == d.functionRoleARN
+		if !d.isManagedIAM(getOutput.Role.Tags) && !createdUntagged {
+			// Like an unmanaged function, a role rosactl does not own is only used once adopted
+			if !d.config.Adopt {
+				return "", &UnmanagedResourceError{Type: ResourceTypeExecutionRole, Identifier: d.config.ExecutionRoleName}
+			}
+			if err := d.adoptExecutionRole(ctx); err != nil {
+				return "", fmt.Errorf("failed to adopt role: %w", err)
+			}
+			d.record(ResourceTypeExecutionRole, roleARN, ResourceActionAdopted)
+			return roleARN, nil
+		}
+		action := ResourceActionUnchanged
+		if d.config.TrustPolicyOverride != "" {
+			if err := d.updateTrustPolicy(ctx); err != nil {
+				return "", err
+			}
+			action = ResourceActionUpdated
+		}
+		tagged, err := d.reconcileRoleTags(ctx)
+		if err != nil {
+			// Don't fail if tagging fails
+			fmt.Fprintf(d.warnings, "Warning: failed to tag execution role: %v\n", err)
+		} else if tagged {
+			action = ResourceActionUpdated
+		}
+		d.record(ResourceTypeExecutionRole, roleARN, action)
+		return roleARN, nil
 	}
 
 	// Check if error is "not found"
@@ -186,7 +929,7 @@ func (d *Deployer) ensureExecutionRole(ctx context.Context) (string, error) {
 	}
 
 	// Role doesn't exist, create it
-	trustPolicy, err := GenerateLambdaExecutionRoleTrustPolicy()
+	trustPolicy, err := d.executionRoleTrustPolicy()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate trust policy: %w", err)
 	}
@@ -195,31 +938,148 @@ func (d *Deployer) ensureExecutionRole(ctx context.Context) (string, error) {
 		RoleName:                 aws.String(d.config.ExecutionRoleName),
 		AssumeRolePolicyDocument: aws.String(trustPolicy),
 		Description:              aws.String("Execution role for ROSA OIDC provisioner Lambda"),
+		Tags:                     d.roleTags(),
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create role: %w", err)
+		return "", fmt.Errorf("failed to create role: %w", categorizeRoleError(err))
 	}
 
 	roleARN := *createOutput.Role.Arn
+	d.record(ResourceTypeExecutionRole, roleARN, ResourceActionCreated)
 
 	// Attach inline permissions policy
-	permissionsPolicy, err := GenerateOIDCProvisionerPermissionsPolicy()
+	permissionsPolicy, err := d.permissionsPolicy()
 	if err != nil {
 		return "", fmt.Errorf("failed to generate permissions policy: %w", err)
 	}
 
 	_, err = d.iamClient.PutRolePolicy(ctx, &iam.PutRolePolicyInput{
 		RoleName:       aws.String(d.config.ExecutionRoleName),
-		PolicyName:     aws.String("OIDCProvisionerPermissions"),
+		PolicyName:     aws.String(permissionsPolicyName),
 		PolicyDocument: aws.String(permissionsPolicy),
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to attach permissions policy: %w", err)
+		return "", fmt.Errorf("failed to attach permissions policy: %w", categorizeRoleError(err))
 	}
 
 	return roleARN, nil
 }
 
+// staleRoleTags returns the desired execution role tags that are missing or have a different value
+func (d *Deployer) staleRoleTags(ctx context.Context) ([]iamTypes.Tag, map[string]string, error) {
+	current := make(map[string]string)
+	input := &iam.ListRoleTagsInput{RoleName: aws.String(d.config.ExecutionRoleName)}
+	for {
+		output, err := d.iamClient.ListRoleTags(ctx, input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list role tags: %w", err)
+		}
+		for _, tag := range output.Tags {
+			current[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+		if !output.IsTruncated {
+			break
+		}
+		input.Marker = output.Marker
+	}
+
+	var stale []iamTypes.Tag
+	for _, tag := range d.roleTags() {
+		if value, ok := current[aws.ToString(tag.Key)]; !ok || value != aws.ToString(tag.Value) {
+			stale = append(stale, tag)
+		}
+	}
+	return stale, current, nil
+}
+
+// reconcileRoleTags applies the configured tags to the execution role, reporting whether any changed.
+// Like function and log group tagging, tags are only added or updated, never removed.
+func (d *Deployer) reconcileRoleTags(ctx context.Context) (bool, error) {
+	stale, _, err := d.staleRoleTags(ctx)
+	if err != nil || len(stale) == 0 {
+		return false, err
+	}
+
+	_, err = d.iamClient.TagRole(ctx, &iam.TagRoleInput{
+		RoleName: aws.String(d.config.ExecutionRoleName),
+		Tags:     stale,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// updateTrustPolicy replaces the execution role's trust policy with the desired one
+func (d *Deployer) updateTrustPolicy(ctx context.Context) error {
+	trustPolicy, err := d.executionRoleTrustPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to generate trust policy: %w", err)
+	}
+
+	_, err = d.iamClient.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{
+		RoleName:       aws.String(d.config.ExecutionRoleName),
+		PolicyDocument: aws.String(trustPolicy),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update trust policy: %w", err)
+	}
+
+	return nil
+}
+
+// permissionsPolicy returns the execution role's inline policy, scoped to the target
+// account and the function's log group when both are known
+func (d *Deployer) permissionsPolicy() (string, error) {
+	logGroupName := ""
+	if d.config.FunctionName != "" || d.config.LogGroupName != "" {
+		logGroupName = d.logGroupName()
+	}
+	policy := oidcProvisionerPermissions(d.scope, logGroupName)
+	if d.config.Tracing {
+		policy.Statement = append(policy.Statement, tracingStatement)
+	}
+	if d.config.BindableRolePath != "" {
+		policy.Statement = append(policy.Statement, bindRolesStatement(d.scope, d.config.BindableRolePath))
+	}
+	if d.config.LedgerTableName != "" {
+		policy.Statement = append(policy.Statement, ledgerStatement(d.scope, d.config.LedgerTableName))
+	}
+	return marshalPermissionsPolicy(policy)
+}
+
+// adoptExecutionRole reconciles a pre-existing role to the desired trust and permissions
+// policies and tags it as managed by rosactl
+func (d *Deployer) adoptExecutionRole(ctx context.Context) error {
+	if err := d.updateTrustPolicy(ctx); err != nil {
+		return err
+	}
+
+	permissionsPolicy, err := d.permissionsPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to generate permissions policy: %w", err)
+	}
+
+	_, err = d.iamClient.PutRolePolicy(ctx, &iam.PutRolePolicyInput{
+		RoleName:       aws.String(d.config.ExecutionRoleName),
+		PolicyName:     aws.String(permissionsPolicyName),
+		PolicyDocument: aws.String(permissionsPolicy),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to attach permissions policy: %w", err)
+	}
+
+	_, err = d.iamClient.TagRole(ctx, &iam.TagRoleInput{
+		RoleName: aws.String(d.config.ExecutionRoleName),
+		Tags:     d.roleTags(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tag role: %w", err)
+	}
+
+	return nil
+}
+
 // checkFunctionExists checks if the Lambda function already exists
 func (d *Deployer) checkFunctionExists(ctx context.Context) (bool, *lambda.GetFunctionOutput, error) {
 	output, err := d.lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
@@ -239,82 +1099,216 @@ func (d *Deployer) checkFunctionExists(ctx context.Context) (bool, *lambda.GetFu
 
 // createFunction creates a new Lambda function
 func (d *Deployer) createFunction(ctx context.Context, zipData []byte, roleARN string) (string, error) {
+	environment, err := d.functionEnvironment()
+	if err != nil {
+		return "", err
+	}
+
 	output, err := d.lambdaClient.CreateFunction(ctx, &lambda.CreateFunctionInput{
 		FunctionName: aws.String(d.config.FunctionName),
 		Runtime:      d.config.Runtime,
 		Role:         aws.String(roleARN),
-		Handler:      aws.String("bootstrap"), // Required for custom runtime
+		Handler:      aws.String(functionHandler), // Required for custom runtime
 		Code: &lambdaTypes.FunctionCode{
 			ZipFile: zipData,
 		},
 		MemorySize:   aws.Int32(d.config.MemorySize),
 		Timeout:      aws.Int32(d.config.Timeout),
 		Architectures: []lambdaTypes.Architecture{d.config.Architecture},
-		Description:  aws.String("ROSA OIDC provider provisioner"),
+		Description:  aws.String(FunctionDescription(d.stamp())),
+		Environment:  environment,
+		Tags:         d.functionTags(),
+		EphemeralStorage: d.ephemeralStorage(),
+		SnapStart:        d.snapStart(),
+		LoggingConfig:    d.loggingConfig(nil),
+		TracingConfig:    d.tracingConfig(),
+		CodeSigningConfigArn: d.codeSigningConfigARN(),
 	})
 
 	if err != nil {
-		return "", err
+		return "", d.codeSigningDeniedError(ctx, err)
 	}
 
 	return *output.FunctionArn, nil
 }
 
-// updateFunction updates an existing Lambda function
-func (d *Deployer) updateFunction(ctx context.Context, zipData []byte, roleARN string) error {
+// updateFunction updates an existing Lambda function. Only configuration that differs
+// from current is sent, so settings rosactl does not manage are preserved; they are
+// reported as a warning and in the deployment result.
+func (d *Deployer) updateFunction(ctx context.Context, zipData []byte, roleARN string, current *lambdaTypes.FunctionConfiguration) error {
+	// Attach the code signing config first, so the new code is checked against it
+	if d.runs(StepFunctionConfig) {
+		if err := d.ensureFunctionCodeSigning(ctx); err != nil {
+			return err
+		}
+	}
+
 	// Update code
-	_, err := d.lambdaClient.UpdateFunctionCode(ctx, &lambda.UpdateFunctionCodeInput{
-		FunctionName: aws.String(d.config.FunctionName),
-		ZipFile:      zipData,
-	})
-	if err != nil {
-		return fmt.Errorf("failed to update function code: %w", err)
+	if d.runs(StepFunctionCode) {
+		_, err := d.lambdaClient.UpdateFunctionCode(ctx, &lambda.UpdateFunctionCodeInput{
+			FunctionName: aws.String(d.config.FunctionName),
+			ZipFile:      zipData,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update function code: %w", categorizeFunctionError(err))
+		}
+	}
+	if !d.runs(StepFunctionConfig) {
+		return nil
+	}
+
+	d.preserved = d.unmanagedSettings(current)
+	if len(d.preserved) > 0 {
+		fmt.Fprintf(d.warnings, "Warning: function %s has settings rosactl does not manage; they are left unchanged: %s\n",
+			d.config.FunctionName, unmanagedSettingsList(d.preserved))
 	}
 
 	// Update configuration
-	_, err = d.lambdaClient.UpdateFunctionConfiguration(ctx, &lambda.UpdateFunctionConfigurationInput{
-		FunctionName: aws.String(d.config.FunctionName),
-		Runtime:      d.config.Runtime,
-		Role:         aws.String(roleARN),
-		Handler:      aws.String("bootstrap"),
-		MemorySize:   aws.Int32(d.config.MemorySize),
-		Timeout:      aws.Int32(d.config.Timeout),
-	})
+	patch, err := d.functionConfigPatch(current, roleARN)
+	if err != nil {
+		return err
+	}
+	if patch == nil {
+		return nil
+	}
+
+	_, err = d.lambdaClient.UpdateFunctionConfiguration(ctx, patch)
 	if err != nil {
-		return fmt.Errorf("failed to update function configuration: %w", err)
+		return fmt.Errorf("failed to update function configuration: %w", categorizeFunctionError(err))
 	}
 
 	return nil
 }
 
+// codeSigningConfigARN returns the configured code signing config, or nil to attach none
+func (d *Deployer) codeSigningConfigARN() *string {
+	if d.config.CodeSigningConfigARN == "" {
+		return nil
+	}
+	return aws.String(d.config.CodeSigningConfigARN)
+}
+
+// ephemeralStorage returns the configured /tmp size, or nil to leave it unchanged
+func (d *Deployer) ephemeralStorage() *lambdaTypes.EphemeralStorage {
+	if d.config.EphemeralStorage == 0 {
+		return nil
+	}
+	return &lambdaTypes.EphemeralStorage{Size: aws.Int32(d.config.EphemeralStorage)}
+}
+
+// loggingConfig returns current with its log group set to the configured one, or nil
+// when neither sets a log group so Lambda's default is kept
+func (d *Deployer) loggingConfig(current *lambdaTypes.LoggingConfig) *lambdaTypes.LoggingConfig {
+	if d.config.LogGroupName == "" && current == nil {
+		return nil
+	}
+	config := lambdaTypes.LoggingConfig{}
+	if current != nil {
+		config = *current
+	}
+	config.LogGroup = aws.String(d.logGroupName())
+	return &config
+}
+
+// tracingConfig returns active tracing when enabled, or nil to leave the mode unchanged
+func (d *Deployer) tracingConfig() *lambdaTypes.TracingConfig {
+	if !d.config.Tracing {
+		return nil
+	}
+	return &lambdaTypes.TracingConfig{Mode: lambdaTypes.TracingModeActive}
+}
+
+// snapStart returns the SnapStart setting, or nil when it is not enabled
+func (d *Deployer) snapStart() *lambdaTypes.SnapStart {
+	if !d.config.SnapStart {
+		return nil
+	}
+	return &lambdaTypes.SnapStart{ApplyOn: lambdaTypes.SnapStartApplyOnPublishedVersions}
+}
+
 // addResourcePolicy adds a resource-based policy to allow CLM to invoke the Lambda
-func (d *Deployer) addResourcePolicy(ctx context.Context) error {
-	policy, err := GenerateLambdaResourcePolicy(d.config.CLMServiceRoleARN, d.config.SourceAccountID)
+// and reports whether the statement was created, updated, or unchanged
+func (d *Deployer) addResourcePolicy(ctx context.Context) (string, error) {
+	sourceAccountID := d.sourceAccountID()
+	policy, err := GenerateLambdaResourcePolicy(d.config.CLMServiceRoleARN, sourceAccountID)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	// Add permission (idempotent - will return error if already exists, which we ignore)
-	_, err = d.lambdaClient.AddPermission(ctx, &lambda.AddPermissionInput{
+	_ = policy // Policy string generated but not directly used (AddPermission handles it)
+
+	qualifier := d.config.ResourcePolicyQualifier
+	action, err := d.addPermission(ctx, qualifier)
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if qualifier != "" && errors.As(err, &notFoundErr) {
+			return "", fmt.Errorf("alias or version %s of %s does not exist yet: %w", qualifier, d.config.FunctionName, err)
+		}
+		return "", err
+	}
+	if qualifier == "" {
+		return action, nil
+	}
+
+	// Once CLM can invoke the qualifier, stop it invoking $LATEST
+	statements, err := d.functionPolicy(ctx, "")
+	if err != nil {
+		return "", err
+	}
+	if findStatement(statements, resourcePolicyStatementID) != nil {
+		if err := d.removePermission(ctx, ""); err != nil {
+			return "", fmt.Errorf("failed to remove unqualified statement %s: %w", resourcePolicyStatementID, err)
+		}
+		if action == ResourceActionUnchanged {
+			action = ResourceActionUpdated
+		}
+	}
+	return action, nil
+}
+
+// addPermission allows CLM to invoke the function, or the alias or version named by
+// qualifier. An existing statement that differs, such as one naming a retired CLM
+// role, is removed and added again, since statements cannot be modified in place.
+func (d *Deployer) addPermission(ctx context.Context, qualifier string) (string, error) {
+	desired := d.desiredPermission()
+
+	statements, err := d.functionPolicy(ctx, qualifier)
+	if err != nil {
+		return "", err
+	}
+	action := ResourceActionCreated
+	if current := findStatement(statements, desired.Sid); current != nil {
+		if len(diffPermission(*current, desired)) == 0 {
+			return ResourceActionUnchanged, nil
+		}
+		if err := d.removePermission(ctx, qualifier); err != nil {
+			return "", fmt.Errorf("failed to remove outdated statement %s: %w", desired.Sid, err)
+		}
+		action = ResourceActionUpdated
+	}
+
+	input := &lambda.AddPermissionInput{
 		FunctionName: aws.String(d.config.FunctionName),
-		StatementId:  aws.String("AllowCLMInvoke"),
-		Action:       aws.String("lambda:InvokeFunction"),
-		Principal:    aws.String("arn:aws:iam::" + d.config.SourceAccountID + ":root"),
-		SourceArn:    aws.String(d.config.CLMServiceRoleARN),
-	})
+		StatementId:  aws.String(desired.Sid),
+		Action:       aws.String(desired.Action),
+		Principal:    aws.String(desired.Principal),
+		SourceArn:    aws.String(desired.SourceArn),
+	}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
 
+	_, err = d.lambdaClient.AddPermission(ctx, input)
 	if err != nil {
-		// Check if permission already exists
+		// A statement added since the policy was read is not an error
 		var resourceConflictErr *lambdaTypes.ResourceConflictException
 		if errors.As(err, &resourceConflictErr) {
-			// Permission already exists, not an error
-			return nil
+			return ResourceActionUnchanged, nil
 		}
-		return err
+		return "", err
 	}
 
-	_ = policy // Policy string generated but not directly used (AddPermission handles it)
-	return nil
+	return action, nil
 }
 
 // ensureLogGroup ensures the CloudWatch Log Group exists with retention
@@ -328,6 +1322,11 @@ func (d *Deployer) ensureLogGroup(ctx context.Context, logGroupName string) erro
 		// Log group already exists
 		for _, lg := range describeOutput.LogGroups {
 			if *lg.LogGroupName == logGroupName {
+				if d.config.Adopt {
+					// Reconcile retention and tags on the adopted group
+					return d.reconcileLogGroup(ctx, logGroupName, ResourceActionAdopted)
+				}
+				d.record(ResourceTypeLogGroup, logGroupName, ResourceActionUnchanged)
 				return nil // Already exists
 			}
 		}
@@ -338,39 +1337,46 @@ func (d *Deployer) ensureLogGroup(ctx context.Context, logGroupName string) erro
 		LogGroupName: aws.String(logGroupName),
 	})
 
+	action := ResourceActionCreated
 	if err != nil {
 		var alreadyExistsErr *types.ResourceAlreadyExistsException
 		if !errors.As(err, &alreadyExistsErr) {
 			return fmt.Errorf("failed to create log group: %w", err)
 		}
+		action = ResourceActionUpdated
 	}
 
-	// Set retention policy (90 days)
-	_, err = d.cwLogsClient.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
+	return d.reconcileLogGroup(ctx, logGroupName, action)
+}
+
+// reconcileLogGroup applies the retention policy and tags to a log group
+func (d *Deployer) reconcileLogGroup(ctx context.Context, logGroupName, action string) error {
+	d.record(ResourceTypeLogGroup, logGroupName, action)
+
+	// Set retention policy
+	_, err := d.cwLogsClient.PutRetentionPolicy(ctx, &cloudwatchlogs.PutRetentionPolicyInput{
 		LogGroupName:    aws.String(logGroupName),
-		RetentionInDays: aws.Int32(90),
+		RetentionInDays: aws.Int32(d.logRetentionDays()),
 	})
 
 	if err != nil {
 		return fmt.Errorf("failed to set retention policy: %w", err)
 	}
 
-	// Tag log group
-	if len(d.config.Tags) > 0 {
-		tags := make(map[string]string)
-		for k, v := range d.config.Tags {
-			tags[k] = v
-		}
+	// Tag log group, linking it to the function when the account is known
+	tags := d.resourceTags()
+	if d.scope.IsScoped() && d.config.FunctionName != "" {
+		tags[d.keys.Key(FunctionARNTagKey)] = d.scope.FunctionARN(d.config.FunctionName)
+	}
 
-		_, err = d.cwLogsClient.TagLogGroup(ctx, &cloudwatchlogs.TagLogGroupInput{
-			LogGroupName: aws.String(logGroupName),
-			Tags:         tags,
-		})
+	_, err = d.cwLogsClient.TagLogGroup(ctx, &cloudwatchlogs.TagLogGroupInput{
+		LogGroupName: aws.String(logGroupName),
+		Tags:         tags,
+	})
 
-		if err != nil {
-			// Don't fail if tagging fails
-			fmt.Printf("Warning: failed to tag log group: %v\n", err)
-		}
+	if err != nil {
+		// Don't fail if tagging fails
+		fmt.Fprintf(d.warnings, "Warning: failed to tag log group: %v\n", err)
 	}
 
 	return nil
@@ -380,11 +1386,293 @@ func (d *Deployer) ensureLogGroup(ctx context.Context, logGroupName string) erro
 func (d *Deployer) tagFunction(ctx context.Context, functionARN string) error {
 	_, err := d.lambdaClient.TagResource(ctx, &lambda.TagResourceInput{
 		Resource: aws.String(functionARN),
-		Tags:     d.config.Tags,
+		Tags:     d.functionTags(),
 	})
 	return err
 }
 
+// resolveScope looks up the caller's account and partition when an STS client is configured
+func (d *Deployer) resolveScope(ctx context.Context) error {
+	if d.stsClient == nil {
+		return nil
+	}
+
+	identity, err := d.stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	d.scope.AccountID = aws.ToString(identity.Account)
+	d.callerARN = aws.ToString(identity.Arn)
+	if parsed, err := arn.Parse(aws.ToString(identity.Arn)); err == nil {
+		d.scope.Partition = parsed.Partition
+	}
+
+	return nil
+}
+
+// resolveAccountAlias records the target account's alias, so deployments can be told
+// apart by account name. Reading it is optional: without iam:ListAccountAliases the
+// alias is left empty.
+func (d *Deployer) resolveAccountAlias(ctx context.Context) {
+	d.accountAlias = ""
+	if d.iamClient == nil || d.scope.AccountID == "" {
+		return
+	}
+	output, err := d.iamClient.ListAccountAliases(ctx, &iam.ListAccountAliasesInput{})
+	if err != nil {
+		fmt.Fprintf(d.warnings, "Warning: failed to read the account alias: %v\n", err)
+		return
+	}
+	// An account has at most one alias
+	if len(output.AccountAliases) > 0 {
+		d.accountAlias = output.AccountAliases[0]
+	}
+}
+
+// sourceAccountID returns the account allowed to invoke the function, defaulting to the caller's account
+func (d *Deployer) sourceAccountID() string {
+	if d.config.SourceAccountID != "" {
+		return d.config.SourceAccountID
+	}
+	return d.scope.AccountID
+}
+
+// resourceTags returns the configured tags plus the rosactl ownership marker and the
+// deployment environment, with rosa: keys written in the tag key format
+func (d *Deployer) resourceTags() map[string]string {
+	tags := make(map[string]string, len(d.config.Tags)+1)
+	for k, v := range d.config.Tags {
+		tags[d.keys.Key(k)] = v
+	}
+	tags[d.keys.Key(ManagedTagKey)] = ManagedTagValue
+	if environment := d.config.Naming.Environment; environment != "" {
+		tags[d.keys.Key(EnvironmentTagKey)] = environment
+	}
+	return tags
+}
+
+// functionTags returns resourceTags plus who deployed the function, when, and with which release and package
+func (d *Deployer) functionTags() map[string]string {
+	tags := d.resourceTags()
+	if d.callerARN != "" {
+		tags[d.keys.Key(DeployedByTagKey)] = d.callerARN
+	}
+	if !d.deployedAt.IsZero() {
+		tags[d.keys.Key(DeployedAtTagKey)] = d.deployedAt.Format(time.RFC3339)
+	}
+	stamp := d.stamp()
+	if stamp.CLIVersion != "" {
+		tags[d.keys.Key(CLIVersionTagKey)] = stamp.CLIVersion
+	}
+	if stamp.PackageChecksum != "" {
+		tags[d.keys.Key(PackageChecksumTagKey)] = stamp.PackageChecksum
+	}
+	return tags
+}
+
+// stamp returns the release and package checksum of the current deployment
+func (d *Deployer) stamp() Stamp {
+	if d.keptStamp != nil {
+		return *d.keptStamp
+	}
+	return Stamp{CLIVersion: d.config.CLIVersion, PackageChecksum: d.checksum}
+}
+
+// roleTags returns resourceTags in IAM form, sorted by key
+func (d *Deployer) roleTags() []iamTypes.Tag {
+	tags := d.resourceTags()
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	iamTags := make([]iamTypes.Tag, 0, len(keys))
+	for _, k := range keys {
+		iamTags = append(iamTags, iamTypes.Tag{Key: aws.String(k), Value: aws.String(tags[k])})
+	}
+	return iamTags
+}
+
+// functionEnvironment returns the provisioner's environment, forwarding user tags for OIDC providers
+func (d *Deployer) functionEnvironment() (*lambdaTypes.Environment, error) {
+	providerTags, err := providerTagsJSON(d.config.Tags, d.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// Always send the variable map so removed tags are cleared on update
+	variables := make(map[string]string, len(d.config.Environment)+3)
+	for key, value := range d.config.Environment {
+		variables[key] = value
+	}
+	if providerTags != "" {
+		variables[ProviderTagsEnvVar] = providerTags
+	}
+	providerMetadata, err := d.providerMetadataJSON()
+	if err != nil {
+		return nil, err
+	}
+	if providerMetadata != "" {
+		variables[ProviderMetadataEnvVar] = providerMetadata
+	}
+	if !d.keys.IsDefault() {
+		variables[tagkey.EnvVar] = d.keys.String()
+	}
+	if d.config.BindableRolePath != "" {
+		variables[BindableRolePathEnvVar] = d.config.BindableRolePath
+	}
+	if d.config.LedgerTableName != "" {
+		variables[ledger.EnvVar] = d.config.LedgerTableName
+	}
+
+	return &lambdaTypes.Environment{Variables: variables}, nil
+}
+
+// record tracks an action taken against a resource during Deploy
+func (d *Deployer) record(resourceType, identifier, action string) {
+	d.resources = append(d.resources, ResourceRecord{
+		Type:       resourceType,
+		Identifier: identifier,
+		Action:     action,
+	})
+}
+
+// isManaged reports whether a resource's tags mark it as owned by rosactl
+func (d *Deployer) isManaged(tags map[string]string) bool {
+	value, _ := d.keys.Lookup(tags, ManagedTagKey)
+	return value == ManagedTagValue
+}
+
+// isManagedIAM reports whether IAM tags mark a resource as owned by rosactl
+func (d *Deployer) isManagedIAM(tags []iamTypes.Tag) bool {
+	for _, tag := range tags {
+		if d.keys.Matches(aws.ToString(tag.Key), ManagedTagKey) && aws.ToString(tag.Value) == ManagedTagValue {
+			return true
+		}
+	}
+	return false
+}
+
+// publishVersion publishes the current function code and configuration as a new version
+func (d *Deployer) publishVersion(ctx context.Context) (string, error) {
+	output, err := d.lambdaClient.PublishVersion(ctx, &lambda.PublishVersionInput{
+		FunctionName: aws.String(d.config.FunctionName),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return aws.ToString(output.Version), nil
+}
+
+// PruneVersions deletes published versions older than the newest keep versions.
+// $LATEST and any version referenced by an alias (including weighted routing) are never deleted.
+// It returns the versions that were deleted.
+func (d *Deployer) PruneVersions(ctx context.Context, keep int) ([]string, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("keep must be at least 1, got %d", keep)
+	}
+
+	versions, err := d.listPublishedVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	aliased, err := d.listAliasedVersions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aliases: %w", err)
+	}
+
+	// Newest versions first
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	var pruned []string
+	for i, version := range versions {
+		versionStr := strconv.Itoa(version)
+		if i < keep || aliased[versionStr] {
+			continue
+		}
+
+		_, err := d.lambdaClient.DeleteFunction(ctx, &lambda.DeleteFunctionInput{
+			FunctionName: aws.String(d.config.FunctionName),
+			Qualifier:    aws.String(versionStr),
+		})
+		if err != nil {
+			return pruned, fmt.Errorf("failed to delete version %s: %w", versionStr, err)
+		}
+		pruned = append(pruned, versionStr)
+	}
+
+	return pruned, nil
+}
+
+// listPublishedVersions returns all numeric versions of the function
+func (d *Deployer) listPublishedVersions(ctx context.Context) ([]int, error) {
+	var versions []int
+	var marker *string
+
+	for {
+		output, err := d.lambdaClient.ListVersionsByFunction(ctx, &lambda.ListVersionsByFunctionInput{
+			FunctionName: aws.String(d.config.FunctionName),
+			Marker:       marker,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, fn := range output.Versions {
+			version, err := strconv.Atoi(aws.ToString(fn.Version))
+			if err != nil {
+				// Skip $LATEST
+				continue
+			}
+			versions = append(versions, version)
+		}
+
+		if output.NextMarker == nil {
+			break
+		}
+		marker = output.NextMarker
+	}
+
+	return versions, nil
+}
+
+// listAliasedVersions returns the set of versions referenced by any alias
+func (d *Deployer) listAliasedVersions(ctx context.Context) (map[string]bool, error) {
+	aliased := make(map[string]bool)
+	var marker *string
+
+	for {
+		output, err := d.lambdaClient.ListAliases(ctx, &lambda.ListAliasesInput{
+			FunctionName: aws.String(d.config.FunctionName),
+			Marker:       marker,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, alias := range output.Aliases {
+			aliased[aws.ToString(alias.FunctionVersion)] = true
+			if alias.RoutingConfig != nil {
+				for version := range alias.RoutingConfig.AdditionalVersionWeights {
+					aliased[version] = true
+				}
+			}
+		}
+
+		if output.NextMarker == nil {
+			break
+		}
+		marker = output.NextMarker
+	}
+
+	return aliased, nil
+}
+
 // EncodeBase64 encodes data to base64 (utility for testing)
 func EncodeBase64(data []byte) string {
 	return base64.StdEncoding.EncodeToString(data)
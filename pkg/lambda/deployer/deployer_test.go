@@ -1,9 +1,11 @@
 package deployer
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
@@ -12,6 +14,7 @@ import (
 	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
 	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -22,8 +25,19 @@ type mockLambdaClient struct {
 	updateFunctionCodeFunc    func(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error)
 	updateFunctionConfigFunc  func(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error)
 	getFunctionFunc           func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
+	listFunctionsFunc         func(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error)
 	addPermissionFunc         func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error)
+	removePermissionFunc      func(ctx context.Context, params *lambda.RemovePermissionInput, optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error)
+	getPolicyFunc             func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error)
 	tagResourceFunc           func(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error)
+	publishVersionFunc        func(ctx context.Context, params *lambda.PublishVersionInput, optFns ...func(*lambda.Options)) (*lambda.PublishVersionOutput, error)
+	listVersionsFunc          func(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error)
+	listAliasesFunc           func(ctx context.Context, params *lambda.ListAliasesInput, optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error)
+	deleteFunctionFunc        func(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error)
+	getAliasFunc              func(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error)
+	createAliasFunc           func(ctx context.Context, params *lambda.CreateAliasInput, optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error)
+	updateAliasFunc           func(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error)
+	invokeFunc                func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
 }
 
 func (m *mockLambdaClient) CreateFunction(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
@@ -54,6 +68,13 @@ func (m *mockLambdaClient) GetFunction(ctx context.Context, params *lambda.GetFu
 	return &lambda.GetFunctionOutput{}, nil
 }
 
+func (m *mockLambdaClient) ListFunctions(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error) {
+	if m.listFunctionsFunc != nil {
+		return m.listFunctionsFunc(ctx, params, optFns...)
+	}
+	return &lambda.ListFunctionsOutput{}, nil
+}
+
 func (m *mockLambdaClient) AddPermission(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
 	if m.addPermissionFunc != nil {
 		return m.addPermissionFunc(ctx, params, optFns...)
@@ -61,6 +82,21 @@ func (m *mockLambdaClient) AddPermission(ctx context.Context, params *lambda.Add
 	return &lambda.AddPermissionOutput{}, nil
 }
 
+func (m *mockLambdaClient) RemovePermission(ctx context.Context, params *lambda.RemovePermissionInput, optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error) {
+	if m.removePermissionFunc != nil {
+		return m.removePermissionFunc(ctx, params, optFns...)
+	}
+	return &lambda.RemovePermissionOutput{}, nil
+}
+
+// GetPolicy defaults to a function without a policy
+func (m *mockLambdaClient) GetPolicy(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+	if m.getPolicyFunc != nil {
+		return m.getPolicyFunc(ctx, params, optFns...)
+	}
+	return nil, &lambdaTypes.ResourceNotFoundException{}
+}
+
 func (m *mockLambdaClient) TagResource(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error) {
 	if m.tagResourceFunc != nil {
 		return m.tagResourceFunc(ctx, params, optFns...)
@@ -68,10 +104,73 @@ func (m *mockLambdaClient) TagResource(ctx context.Context, params *lambda.TagRe
 	return &lambda.TagResourceOutput{}, nil
 }
 
+func (m *mockLambdaClient) PublishVersion(ctx context.Context, params *lambda.PublishVersionInput, optFns ...func(*lambda.Options)) (*lambda.PublishVersionOutput, error) {
+	if m.publishVersionFunc != nil {
+		return m.publishVersionFunc(ctx, params, optFns...)
+	}
+	return &lambda.PublishVersionOutput{}, nil
+}
+
+func (m *mockLambdaClient) ListVersionsByFunction(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error) {
+	if m.listVersionsFunc != nil {
+		return m.listVersionsFunc(ctx, params, optFns...)
+	}
+	return &lambda.ListVersionsByFunctionOutput{}, nil
+}
+
+func (m *mockLambdaClient) ListAliases(ctx context.Context, params *lambda.ListAliasesInput, optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error) {
+	if m.listAliasesFunc != nil {
+		return m.listAliasesFunc(ctx, params, optFns...)
+	}
+	return &lambda.ListAliasesOutput{}, nil
+}
+
+func (m *mockLambdaClient) DeleteFunction(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error) {
+	if m.deleteFunctionFunc != nil {
+		return m.deleteFunctionFunc(ctx, params, optFns...)
+	}
+	return &lambda.DeleteFunctionOutput{}, nil
+}
+
+func (m *mockLambdaClient) GetAlias(ctx context.Context, params *lambda.GetAliasInput, optFns ...func(*lambda.Options)) (*lambda.GetAliasOutput, error) {
+	if m.getAliasFunc != nil {
+		return m.getAliasFunc(ctx, params, optFns...)
+	}
+	return nil, &lambdaTypes.ResourceNotFoundException{}
+}
+
+func (m *mockLambdaClient) CreateAlias(ctx context.Context, params *lambda.CreateAliasInput, optFns ...func(*lambda.Options)) (*lambda.CreateAliasOutput, error) {
+	if m.createAliasFunc != nil {
+		return m.createAliasFunc(ctx, params, optFns...)
+	}
+	return &lambda.CreateAliasOutput{}, nil
+}
+
+func (m *mockLambdaClient) UpdateAlias(ctx context.Context, params *lambda.UpdateAliasInput, optFns ...func(*lambda.Options)) (*lambda.UpdateAliasOutput, error) {
+	if m.updateAliasFunc != nil {
+		return m.updateAliasFunc(ctx, params, optFns...)
+	}
+	return &lambda.UpdateAliasOutput{}, nil
+}
+
+func (m *mockLambdaClient) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	if m.invokeFunc != nil {
+		return m.invokeFunc(ctx, params, optFns...)
+	}
+	return &lambda.InvokeOutput{Payload: []byte(`{"status":"healthy"}`)}, nil
+}
+
 type mockIAMClient struct {
-	createRoleFunc    func(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error)
-	getRoleFunc       func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
-	putRolePolicyFunc func(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error)
+	createRoleFunc         func(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error)
+	getRoleFunc            func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	putRolePolicyFunc      func(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error)
+	updateAssumeFunc       func(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput, optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error)
+	tagRoleFunc            func(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error)
+	listRoleTagsFunc       func(ctx context.Context, params *iam.ListRoleTagsInput, optFns ...func(*iam.Options)) (*iam.ListRoleTagsOutput, error)
+	getRolePolicyFunc      func(ctx context.Context, params *iam.GetRolePolicyInput, optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+	deleteRolePolicyFunc   func(ctx context.Context, params *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error)
+	deleteRoleFunc         func(ctx context.Context, params *iam.DeleteRoleInput, optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error)
+	listAccountAliasesFunc func(ctx context.Context, params *iam.ListAccountAliasesInput, optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error)
 }
 
 func (m *mockIAMClient) CreateRole(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
@@ -95,11 +194,80 @@ func (m *mockIAMClient) PutRolePolicy(ctx context.Context, params *iam.PutRolePo
 	return &iam.PutRolePolicyOutput{}, nil
 }
 
+func (m *mockIAMClient) UpdateAssumeRolePolicy(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput, optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error) {
+	if m.updateAssumeFunc != nil {
+		return m.updateAssumeFunc(ctx, params, optFns...)
+	}
+	return &iam.UpdateAssumeRolePolicyOutput{}, nil
+}
+
+func (m *mockIAMClient) TagRole(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+	if m.tagRoleFunc != nil {
+		return m.tagRoleFunc(ctx, params, optFns...)
+	}
+	return &iam.TagRoleOutput{}, nil
+}
+
+// ListRoleTags defaults to the tags of the role returned by GetRole
+func (m *mockIAMClient) ListRoleTags(ctx context.Context, params *iam.ListRoleTagsInput, optFns ...func(*iam.Options)) (*iam.ListRoleTagsOutput, error) {
+	if m.listRoleTagsFunc != nil {
+		return m.listRoleTagsFunc(ctx, params, optFns...)
+	}
+	output, err := m.GetRole(ctx, &iam.GetRoleInput{RoleName: params.RoleName})
+	if err != nil || output.Role == nil {
+		return &iam.ListRoleTagsOutput{}, err
+	}
+	return &iam.ListRoleTagsOutput{Tags: output.Role.Tags}, nil
+}
+
+// GetRolePolicy defaults to a role without the inline policy
+func (m *mockIAMClient) GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput, optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error) {
+	if m.getRolePolicyFunc != nil {
+		return m.getRolePolicyFunc(ctx, params, optFns...)
+	}
+	return nil, &iamTypes.NoSuchEntityException{}
+}
+
+func (m *mockIAMClient) DeleteRolePolicy(ctx context.Context, params *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error) {
+	if m.deleteRolePolicyFunc != nil {
+		return m.deleteRolePolicyFunc(ctx, params, optFns...)
+	}
+	return &iam.DeleteRolePolicyOutput{}, nil
+}
+
+func (m *mockIAMClient) DeleteRole(ctx context.Context, params *iam.DeleteRoleInput, optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error) {
+	if m.deleteRoleFunc != nil {
+		return m.deleteRoleFunc(ctx, params, optFns...)
+	}
+	return &iam.DeleteRoleOutput{}, nil
+}
+
+func (m *mockIAMClient) ListAccountAliases(ctx context.Context, params *iam.ListAccountAliasesInput, optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error) {
+	if m.listAccountAliasesFunc != nil {
+		return m.listAccountAliasesFunc(ctx, params, optFns...)
+	}
+	return &iam.ListAccountAliasesOutput{}, nil
+}
+
+type mockSTSClient struct {
+	getCallerIdentityFunc func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+func (m *mockSTSClient) GetCallerIdentity(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	if m.getCallerIdentityFunc != nil {
+		return m.getCallerIdentityFunc(ctx, params, optFns...)
+	}
+	return &sts.GetCallerIdentityOutput{}, nil
+}
+
 type mockCloudWatchLogsClient struct {
-	createLogGroupFunc      func(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
-	describeLogGroupsFunc   func(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
-	putRetentionPolicyFunc  func(ctx context.Context, params *cloudwatchlogs.PutRetentionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
-	tagLogGroupFunc         func(ctx context.Context, params *cloudwatchlogs.TagLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagLogGroupOutput, error)
+	createLogGroupFunc     func(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error)
+	describeLogGroupsFunc  func(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+	putRetentionPolicyFunc func(ctx context.Context, params *cloudwatchlogs.PutRetentionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error)
+	tagLogGroupFunc        func(ctx context.Context, params *cloudwatchlogs.TagLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagLogGroupOutput, error)
+	putDataProtectionFunc  func(ctx context.Context, params *cloudwatchlogs.PutDataProtectionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutDataProtectionPolicyOutput, error)
+	deleteLogGroupFunc     func(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error)
+	listTagsFunc           func(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error)
 }
 
 func (m *mockCloudWatchLogsClient) CreateLogGroup(ctx context.Context, params *cloudwatchlogs.CreateLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogGroupOutput, error) {
@@ -130,13 +298,40 @@ func (m *mockCloudWatchLogsClient) TagLogGroup(ctx context.Context, params *clou
 	return &cloudwatchlogs.TagLogGroupOutput{}, nil
 }
 
+func (m *mockCloudWatchLogsClient) PutDataProtectionPolicy(ctx context.Context, params *cloudwatchlogs.PutDataProtectionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutDataProtectionPolicyOutput, error) {
+	if m.putDataProtectionFunc != nil {
+		return m.putDataProtectionFunc(ctx, params, optFns...)
+	}
+	return &cloudwatchlogs.PutDataProtectionPolicyOutput{}, nil
+}
+
+func (m *mockCloudWatchLogsClient) DeleteLogGroup(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+	if m.deleteLogGroupFunc != nil {
+		return m.deleteLogGroupFunc(ctx, params, optFns...)
+	}
+	return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+}
+
+func (m *mockCloudWatchLogsClient) ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+	if m.listTagsFunc != nil {
+		return m.listTagsFunc(ctx, params, optFns...)
+	}
+	return &cloudwatchlogs.ListTagsForResourceOutput{}, nil
+}
+
 func TestDeploy_CreateNewFunction(t *testing.T) {
 	ctx := context.Background()
 	roleARN := "arn:aws:iam::123456789012:role/test-role"
 	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
 
+	created := false
 	mockLambda := &mockLambdaClient{
 		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			if created {
+				return &lambda.GetFunctionOutput{
+					Configuration: &lambdaTypes.FunctionConfiguration{State: lambdaTypes.StateActive},
+				}, nil
+			}
 			// Function doesn't exist
 			return nil, &lambdaTypes.ResourceNotFoundException{}
 		},
@@ -144,6 +339,7 @@ func TestDeploy_CreateNewFunction(t *testing.T) {
 			assert.Equal(t, "test-function", *params.FunctionName)
 			assert.Equal(t, roleARN, *params.Role)
 			assert.NotEmpty(t, params.Code.ZipFile)
+			created = true
 			return &lambda.CreateFunctionOutput{
 				FunctionArn: aws.String(functionARN),
 			}, nil
@@ -157,7 +353,8 @@ func TestDeploy_CreateNewFunction(t *testing.T) {
 		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
 			return &iam.GetRoleOutput{
 				Role: &iamTypes.Role{
-					Arn: aws.String(roleARN),
+					Arn:  aws.String(roleARN),
+					Tags: []iamTypes.Tag{{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)}},
 				},
 			}, nil
 		},
@@ -200,20 +397,33 @@ func TestDeploy_CreateNewFunction(t *testing.T) {
 	assert.Equal(t, "created", result.Status)
 	assert.Greater(t, result.PackageSize, 0)
 	assert.NotEmpty(t, result.PackageChecksum)
+
+	require.Len(t, result.Policies, 2)
+	assert.Equal(t, PolicyTypeTrust, result.Policies[0].Type)
+	assert.Equal(t, roleARN, result.Policies[0].AttachedTo)
+	assert.Equal(t, AttachedPolicy{
+		Type:        PolicyTypeInline,
+		Name:        "OIDCProvisionerPermissions",
+		AttachedTo:  roleARN,
+		Description: "Manages OIDC providers in the account and writes the function's logs",
+	}, result.Policies[1])
 }
 
 func TestDeploy_UpdateExistingFunction(t *testing.T) {
 	ctx := context.Background()
 	roleARN := "arn:aws:iam::123456789012:role/test-role"
 	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
+	var description string
+	var functionTags map[string]string
 
 	mockLambda := &mockLambdaClient{
 		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
-			// Function exists
+			// Function exists and is managed by rosactl
 			return &lambda.GetFunctionOutput{
 				Configuration: &lambdaTypes.FunctionConfiguration{
 					FunctionArn: aws.String(functionARN),
 				},
+				Tags: map[string]string{ManagedTagKey: ManagedTagValue},
 			}, nil
 		},
 		updateFunctionCodeFunc: func(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
@@ -222,9 +432,11 @@ func TestDeploy_UpdateExistingFunction(t *testing.T) {
 		},
 		updateFunctionConfigFunc: func(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error) {
 			assert.Equal(t, "test-function", *params.FunctionName)
+			description = aws.ToString(params.Description)
 			return &lambda.UpdateFunctionConfigurationOutput{}, nil
 		},
 		tagResourceFunc: func(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error) {
+			functionTags = params.Tags
 			return &lambda.TagResourceOutput{}, nil
 		},
 	}
@@ -233,7 +445,8 @@ func TestDeploy_UpdateExistingFunction(t *testing.T) {
 		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
 			return &iam.GetRoleOutput{
 				Role: &iamTypes.Role{
-					Arn: aws.String(roleARN),
+					Arn:  aws.String(roleARN),
+					Tags: []iamTypes.Tag{{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)}},
 				},
 			}, nil
 		},
@@ -260,6 +473,7 @@ func TestDeploy_UpdateExistingFunction(t *testing.T) {
 		Tags: map[string]string{
 			"Environment": "test",
 		},
+		CLIVersion: "1.2.3",
 	}
 
 	deployer := NewDeployer(mockLambda, mockIAM, mockCWLogs, config)
@@ -268,6 +482,189 @@ func TestDeploy_UpdateExistingFunction(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, functionARN, result.FunctionARN)
 	assert.Equal(t, "updated", result.Status)
+
+	// The release and package are stamped on the function
+	assert.Equal(t, "1.2.3", functionTags[CLIVersionTagKey])
+	assert.Equal(t, result.PackageChecksum, functionTags[PackageChecksumTagKey])
+	assert.Equal(t, FunctionDescription(Stamp{CLIVersion: "1.2.3", PackageChecksum: result.PackageChecksum}), description)
+}
+
+func TestDeploy_RefusesUnmanagedFunction(t *testing.T) {
+	ctx := context.Background()
+
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{
+					FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:test-function"),
+				},
+			}, nil
+		},
+	}
+
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			t.Fatal("role should not be touched when the function is unmanaged")
+			return nil, nil
+		},
+	}
+
+	config := DeploymentConfig{
+		FunctionName:      "test-function",
+		ExecutionRoleName: "test-role",
+		MemorySize:        128,
+		Timeout:           60,
+	}
+
+	deployer := NewDeployer(mockLambda, mockIAM, nil, config)
+	_, err := deployer.Deploy(ctx)
+
+	var unmanagedErr *UnmanagedResourceError
+	require.ErrorAs(t, err, &unmanagedErr)
+	assert.Equal(t, ResourceTypeFunction, unmanagedErr.Type)
+	assert.Equal(t, "test-function", unmanagedErr.Identifier)
+}
+
+func TestValidateEphemeralStorage(t *testing.T) {
+	assert.NoError(t, ValidateEphemeralStorage(0))
+	assert.NoError(t, ValidateEphemeralStorage(512))
+	assert.NoError(t, ValidateEphemeralStorage(10240))
+	assert.ErrorContains(t, ValidateEphemeralStorage(256), "ephemeral storage 256 MB")
+	assert.ErrorContains(t, ValidateEphemeralStorage(10241), "ephemeral storage 10241 MB")
+}
+
+func TestValidateLogRetention(t *testing.T) {
+	assert.NoError(t, ValidateLogRetention(0))
+	assert.NoError(t, ValidateLogRetention(365))
+	assert.NoError(t, ValidateLogRetention(3653))
+	assert.ErrorContains(t, ValidateLogRetention(100), "log retention of 100 days is not supported")
+}
+
+func TestReconcileLogGroup_Retention(t *testing.T) {
+	var retention int32
+	mockCWLogs := &mockCloudWatchLogsClient{
+		putRetentionPolicyFunc: func(ctx context.Context, params *cloudwatchlogs.PutRetentionPolicyInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutRetentionPolicyOutput, error) {
+			retention = aws.ToInt32(params.RetentionInDays)
+			return &cloudwatchlogs.PutRetentionPolicyOutput{}, nil
+		},
+	}
+
+	d := NewDeployer(&mockLambdaClient{}, &mockIAMClient{}, mockCWLogs, DeploymentConfig{FunctionName: "test-function", LogRetentionDays: 365})
+	require.NoError(t, d.reconcileLogGroup(context.Background(), "/aws/lambda/test-function", ResourceActionCreated))
+	assert.Equal(t, int32(365), retention)
+}
+
+func TestCreateFunction_EphemeralStorageAndSnapStart(t *testing.T) {
+	var input *lambda.CreateFunctionInput
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			input = params
+			return &lambda.CreateFunctionOutput{FunctionArn: aws.String("arn")}, nil
+		},
+	}
+
+	d := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	_, err := d.createFunction(context.Background(), []byte("zip"), "role")
+	require.NoError(t, err)
+	assert.Nil(t, input.EphemeralStorage)
+	assert.Nil(t, input.SnapStart)
+
+	d = NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function", EphemeralStorage: 2048, SnapStart: true})
+	_, err = d.createFunction(context.Background(), []byte("zip"), "role")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2048), aws.ToInt32(input.EphemeralStorage.Size))
+	assert.Equal(t, lambdaTypes.SnapStartApplyOnPublishedVersions, input.SnapStart.ApplyOn)
+}
+
+func TestValidateFunctionLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		memorySize  int32
+		timeout     int32
+		expectError string
+	}{
+		{name: "defaults", memorySize: 128, timeout: 60},
+		{name: "maximums", memorySize: 10240, timeout: 900},
+		{name: "memory too small", memorySize: 64, timeout: 60, expectError: "memory size 64 MB"},
+		{name: "memory too large", memorySize: 10241, timeout: 60, expectError: "memory size 10241 MB"},
+		{name: "timeout zero", memorySize: 128, timeout: 0, expectError: "timeout 0 seconds"},
+		{name: "timeout too long", memorySize: 128, timeout: 901, expectError: "timeout 901 seconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateFunctionLimits(tt.memorySize, tt.timeout)
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+func TestDeploy_InvalidLimits(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		FunctionName:      "test-function",
+		ExecutionRoleName: "test-role",
+		MemorySize:        128,
+		Timeout:           1000,
+	})
+	_, err := deployer.Deploy(context.Background())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timeout 1000 seconds")
+}
+
+func TestEnsureExecutionRole_AdoptUnmanagedRole(t *testing.T) {
+	ctx := context.Background()
+	roleName := "test-role"
+	roleARN := "arn:aws:iam::123456789012:role/test-role"
+
+	var trustUpdated, policyPut bool
+	var roleTags []iamTypes.Tag
+
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			return &iam.GetRoleOutput{
+				Role: &iamTypes.Role{
+					Arn: aws.String(roleARN),
+				},
+			}, nil
+		},
+		updateAssumeFunc: func(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput, optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error) {
+			assert.Equal(t, roleName, *params.RoleName)
+			trustUpdated = true
+			return &iam.UpdateAssumeRolePolicyOutput{}, nil
+		},
+		putRolePolicyFunc: func(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error) {
+			policyPut = true
+			return &iam.PutRolePolicyOutput{}, nil
+		},
+		tagRoleFunc: func(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+			roleTags = params.Tags
+			return &iam.TagRoleOutput{}, nil
+		},
+	}
+
+	config := DeploymentConfig{
+		ExecutionRoleName: roleName,
+		Adopt:             true,
+		Tags:              map[string]string{"Environment": "test"},
+	}
+
+	deployer := NewDeployer(nil, mockIAM, nil, config)
+	arn, err := deployer.ensureExecutionRole(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, roleARN, arn)
+	assert.True(t, trustUpdated)
+	assert.True(t, policyPut)
+	assert.True(t, deployer.isManagedIAM(roleTags))
+	assert.Len(t, roleTags, 2)
+	require.Len(t, deployer.resources, 1)
+	assert.Equal(t, ResourceActionAdopted, deployer.resources[0].Action)
 }
 
 func TestEnsureExecutionRole_CreateNewRole(t *testing.T) {
@@ -316,7 +713,8 @@ func TestEnsureExecutionRole_UseExistingRole(t *testing.T) {
 		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
 			return &iam.GetRoleOutput{
 				Role: &iamTypes.Role{
-					Arn: aws.String(roleARN),
+					Arn:  aws.String(roleARN),
+					Tags: []iamTypes.Tag{{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)}},
 				},
 			}, nil
 		},
@@ -333,6 +731,130 @@ func TestEnsureExecutionRole_UseExistingRole(t *testing.T) {
 	assert.Equal(t, roleARN, arn)
 }
 
+func TestEnsureExecutionRole_ReconcilesTags(t *testing.T) {
+	ctx := context.Background()
+	roleARN := "arn:aws:iam::123456789012:role/test-role"
+	config := DeploymentConfig{
+		ExecutionRoleName: "test-role",
+		Tags:              map[string]string{"team": "platform", "cost-center": "1234"},
+	}
+
+	t.Run("managed role", func(t *testing.T) {
+		var tagged []iamTypes.Tag
+		mockIAM := &mockIAMClient{
+			getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+				return &iam.GetRoleOutput{
+					Role: &iamTypes.Role{
+						Arn: aws.String(roleARN),
+						Tags: []iamTypes.Tag{
+							{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)},
+							{Key: aws.String("team"), Value: aws.String("old")},
+							{Key: aws.String("owner"), Value: aws.String("someone")},
+						},
+					},
+				}, nil
+			},
+			tagRoleFunc: func(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+				tagged = params.Tags
+				return &iam.TagRoleOutput{}, nil
+			},
+		}
+
+		deployer := NewDeployer(nil, mockIAM, nil, config)
+		_, err := deployer.ensureExecutionRole(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []iamTypes.Tag{
+			{Key: aws.String("cost-center"), Value: aws.String("1234")},
+			{Key: aws.String("team"), Value: aws.String("platform")},
+		}, tagged, "only missing or changed tags are applied")
+		assert.Equal(t, ResourceActionUpdated, deployer.resources[0].Action)
+
+		// A second deploy finds the tags in place
+		tagged = nil
+		mockIAM.listRoleTagsFunc = func(ctx context.Context, params *iam.ListRoleTagsInput, optFns ...func(*iam.Options)) (*iam.ListRoleTagsOutput, error) {
+			return &iam.ListRoleTagsOutput{Tags: deployer.roleTags()}, nil
+		}
+		deployer = NewDeployer(nil, mockIAM, nil, config)
+		_, err = deployer.ensureExecutionRole(ctx)
+		require.NoError(t, err)
+		assert.Nil(t, tagged)
+		assert.Equal(t, ResourceActionUnchanged, deployer.resources[0].Action)
+	})
+
+	t.Run("unmanaged role is refused", func(t *testing.T) {
+		mockIAM := &mockIAMClient{
+			getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+				return &iam.GetRoleOutput{Role: &iamTypes.Role{Arn: aws.String(roleARN)}}, nil
+			},
+			tagRoleFunc: func(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+				t.Fatal("unmanaged role must not be tagged without --adopt")
+				return nil, nil
+			},
+		}
+
+		deployer := NewDeployer(nil, mockIAM, nil, config)
+		_, err := deployer.ensureExecutionRole(ctx)
+		var unmanagedErr *UnmanagedResourceError
+		require.ErrorAs(t, err, &unmanagedErr)
+		assert.Equal(t, ResourceTypeExecutionRole, unmanagedErr.Type)
+		assert.Equal(t, "test-role", unmanagedErr.Identifier)
+		assert.Empty(t, deployer.resources)
+	})
+
+	t.Run("untagged role of the managed function", func(t *testing.T) {
+		var tagged []iamTypes.Tag
+		mockIAM := &mockIAMClient{
+			getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+				return &iam.GetRoleOutput{Role: &iamTypes.Role{Arn: aws.String(roleARN)}}, nil
+			},
+			tagRoleFunc: func(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+				assert.Equal(t, "test-role", aws.ToString(params.RoleName))
+				tagged = params.Tags
+				return &iam.TagRoleOutput{}, nil
+			},
+		}
+
+		// A role created before roles were tagged, which the managed function runs as
+		deployer := NewDeployer(nil, mockIAM, nil, config)
+		deployer.functionRoleARN = roleARN
+		arn, err := deployer.ensureExecutionRole(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, roleARN, arn)
+		assert.True(t, deployer.isManagedIAM(tagged), "the role is tagged as managed")
+		assert.Len(t, tagged, 3)
+		assert.Equal(t, ResourceActionUpdated, deployer.resources[0].Action)
+
+		// The same role is refused when the managed function runs as another role
+		deployer = NewDeployer(nil, mockIAM, nil, config)
+		deployer.functionRoleARN = "arn:aws:iam::123456789012:role/other-role"
+		_, err = deployer.ensureExecutionRole(ctx)
+		var unmanagedErr *UnmanagedResourceError
+		assert.ErrorAs(t, err, &unmanagedErr)
+	})
+
+	t.Run("tagging failure warns", func(t *testing.T) {
+		var warnings bytes.Buffer
+		mockIAM := &mockIAMClient{
+			getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+				return &iam.GetRoleOutput{
+					Role: &iamTypes.Role{
+						Arn:  aws.String(roleARN),
+						Tags: []iamTypes.Tag{{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)}},
+					},
+				}, nil
+			},
+			tagRoleFunc: func(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+				return nil, errors.New("AccessDenied")
+			},
+		}
+
+		_, err := NewDeployer(nil, mockIAM, nil, config, WithWarningOutput(&warnings)).ensureExecutionRole(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, warnings.String(), "failed to tag execution role: AccessDenied")
+	})
+}
+
 func TestEnsureExecutionRole_Error(t *testing.T) {
 	ctx := context.Background()
 
@@ -433,7 +955,7 @@ func TestAddResourcePolicy(t *testing.T) {
 			}
 
 			deployer := NewDeployer(mockLambda, nil, nil, config)
-			err := deployer.addResourcePolicy(ctx)
+			_, err := deployer.addResourcePolicy(ctx)
 
 			if tt.expectError {
 				assert.Error(t, err)
@@ -444,6 +966,125 @@ func TestAddResourcePolicy(t *testing.T) {
 	}
 }
 
+func TestAddResourcePolicy_DefaultsToCallerAccount(t *testing.T) {
+	ctx := context.Background()
+
+	mockLambda := &mockLambdaClient{
+		addPermissionFunc: func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+			assert.Equal(t, "arn:aws:iam::123456789012:root", *params.Principal)
+			return &lambda.AddPermissionOutput{}, nil
+		},
+	}
+
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String("arn:aws:iam::123456789012:user/test"),
+			}, nil
+		},
+	}
+
+	config := DeploymentConfig{
+		FunctionName:      "test-function",
+		CLMServiceRoleARN: "arn:aws:iam::210987654321:role/clm-role",
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, config, WithSTSClient(mockSTS))
+	require.NoError(t, deployer.resolveScope(ctx))
+	assert.Equal(t, "123456789012", deployer.sourceAccountID())
+	_, err := deployer.addResourcePolicy(ctx)
+	assert.NoError(t, err)
+}
+
+func TestEnsureLogGroup_ScopedTags(t *testing.T) {
+	ctx := context.Background()
+
+	var tags map[string]string
+	mockCWLogs := &mockCloudWatchLogsClient{
+		tagLogGroupFunc: func(ctx context.Context, params *cloudwatchlogs.TagLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagLogGroupOutput, error) {
+			tags = params.Tags
+			return &cloudwatchlogs.TagLogGroupOutput{}, nil
+		},
+	}
+
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String("arn:aws-cn:iam::123456789012:user/test"),
+			}, nil
+		},
+	}
+
+	config := DeploymentConfig{
+		FunctionName: "test-function",
+		Region:       "cn-north-1",
+	}
+
+	deployer := NewDeployer(nil, nil, mockCWLogs, config, WithSTSClient(mockSTS))
+	require.NoError(t, deployer.resolveScope(ctx))
+	require.NoError(t, deployer.ensureLogGroup(ctx, "/aws/lambda/test-function"))
+
+	assert.Equal(t, "arn:aws-cn:lambda:cn-north-1:123456789012:function:test-function", tags[FunctionARNTagKey])
+	assert.Equal(t, ManagedTagValue, tags[ManagedTagKey])
+}
+
+func TestEnsureLogGroup_WarningOutput(t *testing.T) {
+	mockCWLogs := &mockCloudWatchLogsClient{
+		tagLogGroupFunc: func(ctx context.Context, params *cloudwatchlogs.TagLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.TagLogGroupOutput, error) {
+			return nil, errors.New("access denied")
+		},
+	}
+
+	var warnings bytes.Buffer
+	deployer := NewDeployer(nil, nil, mockCWLogs, DeploymentConfig{}, WithWarningOutput(&warnings))
+	require.NoError(t, deployer.ensureLogGroup(context.Background(), "/aws/lambda/test-function"))
+
+	assert.Equal(t, "Warning: failed to tag log group: access denied\n", warnings.String())
+}
+
+func TestResolveScope_Error(t *testing.T) {
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return nil, errors.New("expired token")
+		},
+	}
+
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{}, WithSTSClient(mockSTS))
+	err := deployer.resolveScope(context.Background())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get caller identity")
+}
+
+func TestFunctionTags_DeployAnnotations(t *testing.T) {
+	callerARN := "arn:aws:sts::123456789012:assumed-role/Admin/alice"
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String(callerARN),
+			}, nil
+		},
+	}
+
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{Tags: map[string]string{"team": "platform"}}, WithSTSClient(mockSTS))
+	require.NoError(t, deployer.resolveScope(context.Background()))
+	deployer.deployedAt = time.Date(2026, 3, 10, 14, 30, 0, 0, time.UTC)
+
+	tags := deployer.functionTags()
+	assert.Equal(t, callerARN, tags[DeployedByTagKey])
+	assert.Equal(t, "2026-03-10T14:30:00Z", tags[DeployedAtTagKey])
+	assert.Equal(t, "platform", tags["team"])
+	assert.Equal(t, ManagedTagValue, tags[ManagedTagKey])
+	assert.NoError(t, ValidateTagConstraints(tags))
+
+	// Roles and log groups are not annotated, so they are not retagged on every deploy
+	assert.NotContains(t, deployer.resourceTags(), DeployedByTagKey)
+	assert.NotContains(t, deployer.resourceTags(), DeployedAtTagKey)
+}
+
 func TestCheckFunctionExists(t *testing.T) {
 	ctx := context.Background()
 	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
@@ -484,3 +1125,139 @@ func TestCheckFunctionExists(t *testing.T) {
 		assert.Nil(t, output)
 	})
 }
+
+func TestPruneVersions(t *testing.T) {
+	ctx := context.Background()
+
+	var deleted []string
+	mockLambda := &mockLambdaClient{
+		listVersionsFunc: func(ctx context.Context, params *lambda.ListVersionsByFunctionInput, optFns ...func(*lambda.Options)) (*lambda.ListVersionsByFunctionOutput, error) {
+			// Two pages: $LATEST plus versions 1-3, then versions 4-6
+			if params.Marker == nil {
+				return &lambda.ListVersionsByFunctionOutput{
+					Versions: []lambdaTypes.FunctionConfiguration{
+						{Version: aws.String("$LATEST")},
+						{Version: aws.String("1")},
+						{Version: aws.String("2")},
+						{Version: aws.String("3")},
+					},
+					NextMarker: aws.String("page-2"),
+				}, nil
+			}
+			return &lambda.ListVersionsByFunctionOutput{
+				Versions: []lambdaTypes.FunctionConfiguration{
+					{Version: aws.String("4")},
+					{Version: aws.String("5")},
+					{Version: aws.String("6")},
+				},
+			}, nil
+		},
+		listAliasesFunc: func(ctx context.Context, params *lambda.ListAliasesInput, optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error) {
+			return &lambda.ListAliasesOutput{
+				Aliases: []lambdaTypes.AliasConfiguration{
+					{
+						Name:            aws.String("live"),
+						FunctionVersion: aws.String("2"),
+						RoutingConfig: &lambdaTypes.AliasRoutingConfiguration{
+							AdditionalVersionWeights: map[string]float64{"3": 0.1},
+						},
+					},
+				},
+			}, nil
+		},
+		deleteFunctionFunc: func(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error) {
+			assert.Equal(t, "test-function", *params.FunctionName)
+			deleted = append(deleted, *params.Qualifier)
+			return &lambda.DeleteFunctionOutput{}, nil
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	pruned, err := deployer.PruneVersions(ctx, 2)
+
+	require.NoError(t, err)
+	// Keeps 6 and 5 (newest), 2 and 3 (aliased); deletes 4 and 1
+	assert.Equal(t, []string{"4", "1"}, pruned)
+	assert.Equal(t, []string{"4", "1"}, deleted)
+}
+
+func TestPruneVersions_InvalidKeep(t *testing.T) {
+	deployer := NewDeployer(&mockLambdaClient{}, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	_, err := deployer.PruneVersions(context.Background(), 0)
+
+	assert.Error(t, err)
+}
+
+func TestPublishVersion(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		publishVersionFunc: func(ctx context.Context, params *lambda.PublishVersionInput, optFns ...func(*lambda.Options)) (*lambda.PublishVersionOutput, error) {
+			assert.Equal(t, "test-function", *params.FunctionName)
+			return &lambda.PublishVersionOutput{Version: aws.String("7")}, nil
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	version, err := deployer.publishVersion(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "7", version)
+}
+
+func TestResolveAccountAlias(t *testing.T) {
+	mockSTS := &mockSTSClient{
+		getCallerIdentityFunc: func(ctx context.Context, params *sts.GetCallerIdentityInput, optFns ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+			return &sts.GetCallerIdentityOutput{
+				Account: aws.String("123456789012"),
+				Arn:     aws.String("arn:aws-us-gov:iam::123456789012:user/alice"),
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		aliases []string
+		err     error
+		alias   string
+		warning string
+	}{
+		{"alias", []string{"acme-prod"}, nil, "acme-prod", ""},
+		{"no alias", nil, nil, "", ""},
+		{"denied", nil, errors.New("access denied"), "", "Warning: failed to read the account alias: access denied\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockIAM := &mockIAMClient{
+				listAccountAliasesFunc: func(ctx context.Context, params *iam.ListAccountAliasesInput, optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error) {
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					return &iam.ListAccountAliasesOutput{AccountAliases: tt.aliases}, nil
+				},
+			}
+
+			var warnings bytes.Buffer
+			deployer := NewDeployer(nil, mockIAM, nil, DeploymentConfig{}, WithSTSClient(mockSTS), WithWarningOutput(&warnings))
+			require.NoError(t, deployer.resolveScope(context.Background()))
+			deployer.resolveAccountAlias(context.Background())
+
+			assert.Equal(t, "123456789012", deployer.scope.AccountID)
+			assert.Equal(t, "aws-us-gov", deployer.scope.Partition)
+			assert.Equal(t, tt.alias, deployer.accountAlias)
+			assert.Equal(t, tt.warning, warnings.String())
+		})
+	}
+}
+
+func TestResolveAccountAlias_UnknownAccount(t *testing.T) {
+	mockIAM := &mockIAMClient{
+		listAccountAliasesFunc: func(ctx context.Context, params *iam.ListAccountAliasesInput, optFns ...func(*iam.Options)) (*iam.ListAccountAliasesOutput, error) {
+			t.Fatal("the alias is only read once the account is known")
+			return nil, nil
+		},
+	}
+
+	deployer := NewDeployer(nil, mockIAM, nil, DeploymentConfig{})
+	deployer.resolveAccountAlias(context.Background())
+	assert.Empty(t, deployer.accountAlias)
+}
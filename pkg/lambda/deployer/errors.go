@@ -0,0 +1,95 @@
+package deployer
+
+import (
+	"errors"
+	"fmt"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+)
+
+// Failure categories of a deployment. Errors returned by Deploy wrap them with
+// context, keeping the underlying AWS error, so callers can branch on them with
+// errors.Is instead of matching messages.
+var (
+	// ErrRoleCreationDenied is wrapped when IAM denies creating the execution role or
+	// attaching its permissions policy
+	ErrRoleCreationDenied = errors.New("execution role creation denied")
+
+	// ErrPackageTooLarge is wrapped when the deployment package exceeds the Lambda
+	// package size limit or the account's code storage
+	ErrPackageTooLarge = errors.New("deployment package too large")
+
+	// ErrFunctionConflict is wrapped when Lambda rejects a change because the
+	// function already exists or another update to it is in progress
+	ErrFunctionConflict = errors.New("function conflict")
+
+	// ErrIAMPropagationTimeout is wrapped when a new execution role does not become
+	// assumable by Lambda within the IAM propagation timeout
+	ErrIAMPropagationTimeout = errors.New("IAM propagation timed out")
+
+	// ErrCodeSigning is wrapped when Lambda code signing rejects the deployment: the
+	// code signing config enforces signed code, does not exist, or the account
+	// requires functions to use one
+	ErrCodeSigning = errors.New("code signing rejected the deployment")
+)
+
+// categorizedError attaches a failure category to an error without changing its message
+type categorizedError struct {
+	category error
+	err      error
+}
+
+func (e *categorizedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *categorizedError) Unwrap() []error {
+	return []error{e.err, e.category}
+}
+
+// withCategory returns err wrapped with category, or nil if err is nil
+func withCategory(category, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &categorizedError{category: category, err: err}
+}
+
+// categorizeRoleError wraps an IAM error from creating the execution role with
+// ErrRoleCreationDenied when IAM denied the request
+func categorizeRoleError(err error) error {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDenied", "AccessDeniedException", "UnauthorizedOperation":
+			return withCategory(ErrRoleCreationDenied, err)
+		}
+	}
+	return err
+}
+
+// categorizeFunctionError wraps a Lambda error from creating or updating the
+// function with the failure category it belongs to, if any. Code signing
+// verification failures also say how to fix them.
+func categorizeFunctionError(err error) error {
+	var tooLargeErr *lambdaTypes.RequestTooLargeException
+	var storageErr *lambdaTypes.CodeStorageExceededException
+	var conflictErr *lambdaTypes.ResourceConflictException
+	var inUseErr *lambdaTypes.ResourceInUseException
+	var verificationErr *lambdaTypes.CodeVerificationFailedException
+	var signatureErr *lambdaTypes.InvalidCodeSignatureException
+	var signingConfigErr *lambdaTypes.CodeSigningConfigNotFoundException
+	switch {
+	case errors.As(err, &tooLargeErr), errors.As(err, &storageErr):
+		return withCategory(ErrPackageTooLarge, err)
+	case errors.As(err, &conflictErr), errors.As(err, &inUseErr):
+		return withCategory(ErrFunctionConflict, err)
+	case errors.As(err, &verificationErr), errors.As(err, &signatureErr):
+		return withCategory(ErrCodeSigning, fmt.Errorf("%w; the function's code signing config enforces signed code, "+
+			"but the package rosactl builds is uploaded unsigned: set the config's untrusted artifact policy to Warn", err))
+	case errors.As(err, &signingConfigErr):
+		return withCategory(ErrCodeSigning, err)
+	}
+	return err
+}
@@ -0,0 +1,71 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureExecutionRole_CreationDenied(t *testing.T) {
+	denied := &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized to perform: iam:CreateRole"}
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			return nil, &iamTypes.NoSuchEntityException{}
+		},
+		createRoleFunc: func(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
+			return nil, denied
+		},
+	}
+
+	deployer := NewDeployer(nil, mockIAM, nil, DeploymentConfig{ExecutionRoleName: "test-role"})
+	_, err := deployer.ensureExecutionRole(context.Background())
+
+	require.ErrorIs(t, err, ErrRoleCreationDenied)
+	assert.ErrorIs(t, err, denied, "the AWS error is kept")
+	assert.Equal(t, "failed to create role: "+denied.Error(), err.Error(), "the message is unchanged")
+}
+
+func TestCreateFunction_Conflict(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			return nil, &lambdaTypes.ResourceConflictException{Message: aws.String("Function already exist: test-function")}
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	_, err := deployer.createFunction(context.Background(), []byte("zip"), "arn:aws:iam::123456789012:role/test-role")
+
+	require.ErrorIs(t, err, ErrFunctionConflict)
+	var conflictErr *lambdaTypes.ResourceConflictException
+	assert.ErrorAs(t, err, &conflictErr)
+}
+
+func TestUpdateFunction_PackageTooLarge(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		updateFunctionCodeFunc: func(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
+			return nil, &lambdaTypes.RequestTooLargeException{Message: aws.String("Request must be smaller than 70167211 bytes")}
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	err := deployer.updateFunction(context.Background(), []byte("zip"), "", &lambdaTypes.FunctionConfiguration{})
+
+	require.ErrorIs(t, err, ErrPackageTooLarge)
+	assert.NotErrorIs(t, err, ErrFunctionConflict)
+}
+
+func TestCategorize_Uncategorized(t *testing.T) {
+	err := errors.New("throttled")
+	assert.Same(t, err, categorizeFunctionError(err))
+	assert.Same(t, err, categorizeRoleError(err))
+	assert.Nil(t, withCategory(ErrFunctionConflict, nil))
+}
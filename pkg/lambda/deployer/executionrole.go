@@ -0,0 +1,58 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go"
+)
+
+// ValidateExecutionRoleARN checks that roleARN is the ARN of an IAM role and returns
+// the role's name
+func ValidateExecutionRoleARN(roleARN string) (string, error) {
+	parsed, err := arn.Parse(roleARN)
+	if err != nil || parsed.Service != "iam" || !strings.HasPrefix(parsed.Resource, "role/") {
+		return "", fmt.Errorf("execution role ARN %q is not an IAM role ARN, such as arn:aws:iam::123456789012:role/rosa-oidc-provisioner", roleARN)
+	}
+	name := roleNameFromARN(roleARN)
+	if problem := nameProblem("execution role name", name, maxRoleNameLength, roleNamePattern, "letters, numbers, and +=,.@_-"); problem != "" {
+		return "", errors.New(problem)
+	}
+	return name, nil
+}
+
+// checkPreCreatedRole reads the trust policy of the role set with ExecutionRoleARN
+// and checks that Lambda can assume it. The role is otherwise used as is: it is not
+// created, updated, tagged, or deleted. Accounts that deny iam:GetRole too are only
+// warned, since the role is then checked when the function is created.
+func (d *Deployer) checkPreCreatedRole(ctx context.Context) error {
+	roleName := roleNameFromARN(d.config.ExecutionRoleARN)
+	output, err := d.iamClient.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		var apiErr smithy.APIError
+		if errors.As(err, &apiErr) && isAccessDenied(apiErr.ErrorCode()) {
+			fmt.Fprintf(d.warnings, "Warning: trust policy of execution role %s not checked: %v\n", d.config.ExecutionRoleARN, err)
+			return nil
+		}
+		return fmt.Errorf("failed to read execution role %s: %w", d.config.ExecutionRoleARN, err)
+	}
+
+	if actual := aws.ToString(output.Role.Arn); actual != d.config.ExecutionRoleARN {
+		return fmt.Errorf("execution role %s has ARN %s, not %s", roleName, actual, d.config.ExecutionRoleARN)
+	}
+	// IAM returns policy documents URL-encoded
+	document, err := url.QueryUnescape(aws.ToString(output.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		return fmt.Errorf("failed to decode trust policy of execution role %s: %w", d.config.ExecutionRoleARN, err)
+	}
+	if err := ValidateTrustPolicy(document); err != nil {
+		return fmt.Errorf("execution role %s cannot be used: %w", d.config.ExecutionRoleARN, err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPreCreatedRoleARN = "arn:aws:iam::123456789012:role/customer/precreated"
+
+func TestValidateExecutionRoleARN(t *testing.T) {
+	name, err := ValidateExecutionRoleARN(testPreCreatedRoleARN)
+	require.NoError(t, err)
+	assert.Equal(t, "precreated", name)
+
+	_, err = ValidateExecutionRoleARN("arn:aws:iam::123456789012:user/someone")
+	assert.ErrorContains(t, err, "is not an IAM role ARN")
+	_, err = ValidateExecutionRoleARN("precreated")
+	assert.ErrorContains(t, err, "is not an IAM role ARN")
+	_, err = ValidateExecutionRoleARN("arn:aws:iam::123456789012:role/bad name")
+	assert.ErrorContains(t, err, "execution role name")
+}
+
+// preCreatedRoleIAM returns an IAM client that serves the pre-created role with
+// trustPolicy and fails the test on any IAM write
+func preCreatedRoleIAM(t *testing.T, trustPolicy string) *mockIAMClient {
+	return &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			assert.Equal(t, "precreated", aws.ToString(params.RoleName))
+			return &iam.GetRoleOutput{Role: &iamTypes.Role{
+				Arn:                      aws.String(testPreCreatedRoleARN),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(trustPolicy)),
+			}}, nil
+		},
+		createRoleFunc: func(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
+			t.Error("the role must not be created")
+			return &iam.CreateRoleOutput{}, nil
+		},
+		putRolePolicyFunc: func(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error) {
+			t.Error("the role's policies must not be changed")
+			return &iam.PutRolePolicyOutput{}, nil
+		},
+		tagRoleFunc: func(ctx context.Context, params *iam.TagRoleInput, optFns ...func(*iam.Options)) (*iam.TagRoleOutput, error) {
+			t.Error("the role must not be tagged")
+			return &iam.TagRoleOutput{}, nil
+		},
+	}
+}
+
+// missingFunctionLambda returns a Lambda client on which the function does not exist
+// yet and is created with the pre-created role
+func missingFunctionLambda(t *testing.T) *mockLambdaClient {
+	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
+	created := false
+	return &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			if !created {
+				return nil, &lambdaTypes.ResourceNotFoundException{}
+			}
+			return &lambda.GetFunctionOutput{Configuration: &lambdaTypes.FunctionConfiguration{
+				FunctionArn: aws.String(functionARN),
+				Role:        aws.String(testPreCreatedRoleARN),
+				State:       lambdaTypes.StateActive,
+			}}, nil
+		},
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			assert.Equal(t, testPreCreatedRoleARN, aws.ToString(params.Role))
+			created = true
+			return &lambda.CreateFunctionOutput{FunctionArn: aws.String(functionARN)}, nil
+		},
+	}
+}
+
+func lambdaTrustPolicy(t *testing.T) string {
+	document, err := GenerateLambdaExecutionRoleTrustPolicy()
+	require.NoError(t, err)
+	return document
+}
+
+func TestDeploy_PreCreatedExecutionRole(t *testing.T) {
+	config := rollbackConfig()
+	config.ExecutionRoleARN = testPreCreatedRoleARN
+	deployer := NewDeployer(missingFunctionLambda(t), preCreatedRoleIAM(t, lambdaTrustPolicy(t)), &mockCloudWatchLogsClient{}, config)
+
+	result, err := deployer.Deploy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testPreCreatedRoleARN, result.ExecutionRole)
+	assert.Empty(t, result.Policies)
+
+	for _, resource := range deployer.Resources() {
+		assert.NotEqual(t, ResourceTypeExecutionRole, resource.Ref().Type, "a pre-created role is not managed")
+	}
+}
+
+func TestDeploy_PreCreatedExecutionRoleUntrusted(t *testing.T) {
+	config := rollbackConfig()
+	config.ExecutionRoleARN = testPreCreatedRoleARN
+	untrusted := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+
+	_, err := NewDeployer(missingFunctionLambda(t), preCreatedRoleIAM(t, untrusted), &mockCloudWatchLogsClient{}, config).Deploy(context.Background())
+	assert.ErrorContains(t, err, "execution role "+testPreCreatedRoleARN+" cannot be used")
+}
+
+func TestDeploy_PreCreatedExecutionRoleUnreadable(t *testing.T) {
+	config := rollbackConfig()
+	config.ExecutionRoleARN = testPreCreatedRoleARN
+	mockIAM := preCreatedRoleIAM(t, "")
+	mockIAM.getRoleFunc = func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+		return nil, &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized to perform: iam:GetRole"}
+	}
+
+	var warnings bytes.Buffer
+	result, err := NewDeployer(missingFunctionLambda(t), mockIAM, &mockCloudWatchLogsClient{}, config, WithWarningOutput(&warnings)).Deploy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, testPreCreatedRoleARN, result.ExecutionRole)
+	assert.Contains(t, warnings.String(), "trust policy of execution role "+testPreCreatedRoleARN+" not checked")
+}
+
+func TestDeploy_PreCreatedExecutionRoleRefusesTrustPolicy(t *testing.T) {
+	config := rollbackConfig()
+	config.ExecutionRoleARN = testPreCreatedRoleARN
+	config.TrustPolicyOverride = lambdaTrustPolicy(t)
+
+	_, err := NewDeployer(missingFunctionLambda(t), &mockIAMClient{}, &mockCloudWatchLogsClient{}, config).Deploy(context.Background())
+	assert.ErrorContains(t, err, "cannot be applied to a pre-created execution role")
+}
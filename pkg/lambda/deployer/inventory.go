@@ -0,0 +1,255 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+// Resource types reported by FindManagedResources in addition to the deployment's own
+const (
+	ResourceTypeRolePolicy = "iam-role-policy"
+	ResourceTypeAlias      = "lambda-alias"
+)
+
+// InventoryLambdaAPI is the subset of the Lambda API used to find managed resources
+type InventoryLambdaAPI interface {
+	ListFunctions(ctx context.Context, params *lambda.ListFunctionsInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error)
+	GetFunction(ctx context.Context, params *lambda.GetFunctionInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
+	GetPolicy(ctx context.Context, params *lambda.GetPolicyInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error)
+	ListAliases(ctx context.Context, params *lambda.ListAliasesInput,
+		optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error)
+}
+
+// InventoryIAMAPI is the subset of the IAM API used to find managed resources
+type InventoryIAMAPI interface {
+	GetRole(ctx context.Context, params *iam.GetRoleInput,
+		optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+}
+
+// InventoryLogsAPI is the subset of the CloudWatch Logs API used to find managed resources
+type InventoryLogsAPI interface {
+	DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+	ListTagsForResource(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error)
+}
+
+// ManagedResource is a resource rosactl created for a provisioner deployment
+type ManagedResource struct {
+	Type     string // One of the ResourceType constants
+	Name     string // Role, function, alias, log group, inline policy, or policy statement ID
+	Function string // Function of the deployment the resource belongs to
+
+	// Role is the role an inline policy is attached to, and Qualifier the alias a
+	// policy statement is attached to, if any
+	Role      string
+	Qualifier string
+}
+
+// FindManagedResources lists the rosa-managed provisioner deployments in the
+// region: each function tagged rosa:managed=true whose description identifies it as
+// a provisioner, its aliases and CLM policy statements, and its execution role,
+// inline policy, and log group when they are tagged rosa:managed=true too.
+// Resources are grouped by function, sorted by function name. keys is the format
+// the tags were written in.
+func FindManagedResources(ctx context.Context, lambdaClient InventoryLambdaAPI, iamClient InventoryIAMAPI,
+	logsClient InventoryLogsAPI, keys tagkey.Format) ([]ManagedResource, error) {
+	var functionNames []string
+	paginator := lambda.NewListFunctionsPaginator(lambdaClient, &lambda.ListFunctionsInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list functions: %w", err)
+		}
+		for _, function := range page.Functions {
+			if IsProvisionerDescription(aws.ToString(function.Description)) {
+				functionNames = append(functionNames, aws.ToString(function.FunctionName))
+			}
+		}
+	}
+	sort.Strings(functionNames)
+
+	var resources []ManagedResource
+	for _, functionName := range functionNames {
+		found, err := findFunctionResources(ctx, lambdaClient, iamClient, logsClient, functionName, keys)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, found...)
+	}
+	return resources, nil
+}
+
+// findFunctionResources returns the managed resources of one provisioner function,
+// or none if the function is not tagged rosa:managed=true
+func findFunctionResources(ctx context.Context, lambdaClient InventoryLambdaAPI, iamClient InventoryIAMAPI,
+	logsClient InventoryLogsAPI, functionName string, keys tagkey.Format) ([]ManagedResource, error) {
+	output, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{FunctionName: aws.String(functionName)})
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get function %s: %w", functionName, err)
+	}
+	if value, _ := keys.Lookup(output.Tags, ManagedTagKey); value != ManagedTagValue || output.Configuration == nil {
+		return nil, nil
+	}
+	cfg := output.Configuration
+	resources := []ManagedResource{{Type: ResourceTypeFunction, Name: functionName, Function: functionName}}
+
+	var aliases []string
+	aliasPaginator := lambda.NewListAliasesPaginator(lambdaClient, &lambda.ListAliasesInput{FunctionName: aws.String(functionName)})
+	for aliasPaginator.HasMorePages() {
+		page, err := aliasPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list aliases of %s: %w", functionName, err)
+		}
+		for _, alias := range page.Aliases {
+			aliases = append(aliases, aws.ToString(alias.Name))
+		}
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		resources = append(resources, ManagedResource{Type: ResourceTypeAlias, Name: alias, Function: functionName})
+	}
+
+	for _, qualifier := range append([]string{""}, aliases...) {
+		found, err := hasCLMStatement(ctx, lambdaClient, functionName, qualifier)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			resources = append(resources, ManagedResource{
+				Type: ResourceTypeResourcePolicy, Name: resourcePolicyStatementID, Function: functionName, Qualifier: qualifier,
+			})
+		}
+	}
+
+	roleResources, err := findRoleResources(ctx, iamClient, roleNameFromARN(aws.ToString(cfg.Role)), functionName, keys)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, roleResources...)
+
+	logGroupName := FunctionLogGroupName(cfg, functionName)
+	managed, err := isManagedLogGroup(ctx, logsClient, logGroupName, keys)
+	if err != nil {
+		return nil, err
+	}
+	if managed {
+		resources = append(resources, ManagedResource{Type: ResourceTypeLogGroup, Name: logGroupName, Function: functionName})
+	}
+	return resources, nil
+}
+
+// hasCLMStatement reports whether the policy of the function, or of its alias or
+// version qualifier, has the statement allowing CLM to invoke it
+func hasCLMStatement(ctx context.Context, client InventoryLambdaAPI, functionName, qualifier string) (bool, error) {
+	input := &lambda.GetPolicyInput{FunctionName: aws.String(functionName)}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+	output, err := client.GetPolicy(ctx, input)
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get function policy of %s: %w", qualifiedName(functionName, qualifier), err)
+	}
+	statements, err := parseFunctionPolicy(aws.ToString(output.Policy))
+	if err != nil {
+		return false, err
+	}
+	return findStatement(statements, resourcePolicyStatementID) != nil, nil
+}
+
+// findRoleResources returns the execution role and its inline permissions policy if
+// the role is tagged rosa:managed=true
+func findRoleResources(ctx context.Context, client InventoryIAMAPI, roleName, functionName string, keys tagkey.Format) ([]ManagedResource, error) {
+	if roleName == "" {
+		return nil, nil
+	}
+	var notFoundErr *iamTypes.NoSuchEntityException
+	role, err := client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get role %s: %w", roleName, err)
+	}
+	managed := false
+	for _, tag := range role.Role.Tags {
+		if keys.Matches(aws.ToString(tag.Key), ManagedTagKey) && aws.ToString(tag.Value) == ManagedTagValue {
+			managed = true
+		}
+	}
+	if !managed {
+		return nil, nil
+	}
+	resources := []ManagedResource{{Type: ResourceTypeExecutionRole, Name: roleName, Function: functionName}}
+
+	_, err = client.GetRolePolicy(ctx, &iam.GetRolePolicyInput{
+		RoleName:   aws.String(roleName),
+		PolicyName: aws.String(permissionsPolicyName),
+	})
+	if err != nil {
+		if errors.As(err, &notFoundErr) {
+			return resources, nil
+		}
+		return nil, fmt.Errorf("failed to get role policy %s: %w", permissionsPolicyName, err)
+	}
+	return append(resources, ManagedResource{
+		Type: ResourceTypeRolePolicy, Name: permissionsPolicyName, Function: functionName, Role: roleName,
+	}), nil
+}
+
+// isManagedLogGroup reports whether the log group exists and is tagged rosa:managed=true
+func isManagedLogGroup(ctx context.Context, client InventoryLogsAPI, logGroupName string, keys tagkey.Format) (bool, error) {
+	output, err := client.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(logGroupName),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to describe log group %s: %w", logGroupName, err)
+	}
+	for _, group := range output.LogGroups {
+		if aws.ToString(group.LogGroupName) != logGroupName {
+			continue
+		}
+		// DescribeLogGroups reports the ARN with a trailing ":*"
+		tags, err := client.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{
+			ResourceArn: aws.String(strings.TrimSuffix(aws.ToString(group.Arn), ":*")),
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to list tags of log group %s: %w", logGroupName, err)
+		}
+		value, _ := keys.Lookup(tags.Tags, ManagedTagKey)
+		return value == ManagedTagValue, nil
+	}
+	return false, nil
+}
+
+// qualifiedName returns functionName:qualifier, or functionName without a qualifier
+func qualifiedName(functionName, qualifier string) string {
+	if qualifier == "" {
+		return functionName
+	}
+	return functionName + ":" + qualifier
+}
@@ -0,0 +1,76 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindManagedResources(t *testing.T) {
+	lambdaClient, iamClient, logsClient := snapshotClients("us-east-1", "123456789012", 128)
+	lambdaClient.listFunctionsFunc = func(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error) {
+		return &lambda.ListFunctionsOutput{Functions: []lambdaTypes.FunctionConfiguration{
+			{FunctionName: aws.String("test-function"), Description: aws.String(FunctionDescription(Stamp{CLIVersion: "0.1.0"}))},
+			{FunctionName: aws.String("unrelated-function"), Description: aws.String("Something else")},
+		}}, nil
+	}
+	lambdaClient.listAliasesFunc = func(ctx context.Context, params *lambda.ListAliasesInput, optFns ...func(*lambda.Options)) (*lambda.ListAliasesOutput, error) {
+		return &lambda.ListAliasesOutput{Aliases: []lambdaTypes.AliasConfiguration{{Name: aws.String("live")}}}, nil
+	}
+	lambdaClient.getPolicyFunc = func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+		if aws.ToString(params.Qualifier) != "live" {
+			return nil, &lambdaTypes.ResourceNotFoundException{}
+		}
+		return &lambda.GetPolicyOutput{Policy: aws.String(functionPolicyDocument(testCLMRole))}, nil
+	}
+	logsClient.describeLogGroupsFunc = func(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+		return &cloudwatchlogs.DescribeLogGroupsOutput{LogGroups: []cwTypes.LogGroup{{
+			LogGroupName: aws.String("/aws/lambda/test-function"),
+			Arn:          aws.String("arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/test-function:*"),
+		}}}, nil
+	}
+	logsClient.listTagsFunc = func(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+		assert.Equal(t, "arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/test-function", aws.ToString(params.ResourceArn))
+		return &cloudwatchlogs.ListTagsForResourceOutput{Tags: map[string]string{ManagedTagKey: ManagedTagValue}}, nil
+	}
+
+	resources, err := FindManagedResources(context.Background(), lambdaClient, iamClient, logsClient, tagkey.Format{})
+	require.NoError(t, err)
+	assert.Equal(t, []ManagedResource{
+		{Type: ResourceTypeFunction, Name: "test-function", Function: "test-function"},
+		{Type: ResourceTypeAlias, Name: "live", Function: "test-function"},
+		{Type: ResourceTypeResourcePolicy, Name: resourcePolicyStatementID, Function: "test-function", Qualifier: "live"},
+		{Type: ResourceTypeExecutionRole, Name: "test-role", Function: "test-function"},
+		{Type: ResourceTypeRolePolicy, Name: permissionsPolicyName, Function: "test-function", Role: "test-role"},
+		{Type: ResourceTypeLogGroup, Name: "/aws/lambda/test-function", Function: "test-function"},
+	}, resources)
+}
+
+func TestFindManagedResources_Unmanaged(t *testing.T) {
+	lambdaClient, iamClient, logsClient := snapshotClients("us-east-1", "123456789012", 128)
+	lambdaClient.listFunctionsFunc = func(ctx context.Context, params *lambda.ListFunctionsInput, optFns ...func(*lambda.Options)) (*lambda.ListFunctionsOutput, error) {
+		return &lambda.ListFunctionsOutput{Functions: []lambdaTypes.FunctionConfiguration{
+			{FunctionName: aws.String("test-function"), Description: aws.String(FunctionDescription(Stamp{}))},
+		}}, nil
+	}
+	iamClient.getRoleFunc = func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+		return &iam.GetRoleOutput{Role: &iamTypes.Role{Arn: aws.String("arn:aws:iam::123456789012:role/test-role")}}, nil
+	}
+
+	resources, err := FindManagedResources(context.Background(), lambdaClient, iamClient, logsClient, tagkey.Format{})
+	require.NoError(t, err)
+	assert.Equal(t, []ManagedResource{
+		{Type: ResourceTypeFunction, Name: "test-function", Function: "test-function"},
+		{Type: ResourceTypeResourcePolicy, Name: resourcePolicyStatementID, Function: "test-function"},
+	}, resources, "an untagged role and log group are left to their owners")
+}
@@ -0,0 +1,100 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+)
+
+// InvocableResult reports how long CLM's permission to invoke the function took to
+// take effect after deploying
+type InvocableResult struct {
+	// Target is the function ARN, qualified by the resource policy qualifier if any
+	Target string
+
+	// PolicyWait is how long until the function policy read back with the CLM statement
+	PolicyWait time.Duration
+
+	// Invoked reports whether a test invocation with the CLM service role's credentials
+	// was made and allowed
+	Invoked bool
+
+	// InvokeWait is how long until the test invocation was allowed
+	InvokeWait time.Duration
+}
+
+// WaitForInvocable confirms that CLM can invoke the deployed function. Permission
+// changes take time to be enforced everywhere, so CLM invocations can be denied for
+// minutes after a deployment reports success. The function policy is read back until
+// it holds the CLM statement as deployed. When invokeClient is set, with the CLM
+// service role's credentials, the function is then pinged through it until Lambda
+// stops denying the invocation; an invocation that reaches the function counts even
+// if the function fails. Both waits are bounded by the invocable step timeout.
+func (d *Deployer) WaitForInvocable(ctx context.Context, functionARN string, invokeClient invoker.LambdaInvokeAPI) (*InvocableResult, error) {
+	if d.config.CLMServiceRoleARN == "" {
+		return nil, errors.New("CLM service role ARN is required")
+	}
+
+	qualifier := d.config.ResourcePolicyQualifier
+	result := &InvocableResult{Target: functionARN}
+	if qualifier != "" {
+		result.Target = functionARN + ":" + qualifier
+	}
+
+	start := time.Now()
+	err := d.withStepTimeout(ctx, TimedInvocable, func(ctx context.Context) error {
+		desired := d.desiredPermission()
+		for {
+			statements, err := d.functionPolicy(ctx, qualifier)
+			if err != nil {
+				return err
+			}
+			if current := findStatement(statements, desired.Sid); current != nil && len(diffPermission(*current, desired)) == 0 {
+				break
+			}
+
+			if err := d.sleep(ctx); err != nil {
+				return fmt.Errorf("policy of %s does not yet allow CLM to invoke it: %w", result.Target, err)
+			}
+		}
+		result.PolicyWait = time.Since(start)
+
+		if invokeClient == nil {
+			return nil
+		}
+
+		ping := invoker.NewInvoker(invokeClient, result.Target)
+		for {
+			resp, _, err := ping.Ping(ctx)
+			var funcErr *invoker.FunctionError
+			if err == nil || resp != nil || errors.As(err, &funcErr) {
+				result.Invoked = true
+				result.InvokeWait = time.Since(start)
+				return nil
+			}
+			if !isInvokeDenied(err) {
+				return fmt.Errorf("test invocation of %s as %s failed: %w", result.Target, d.config.CLMServiceRoleARN, err)
+			}
+
+			if sleepErr := d.sleep(ctx); sleepErr != nil {
+				return fmt.Errorf("invocations of %s as %s are still denied (%v): %w", result.Target, d.config.CLMServiceRoleARN, err, sleepErr)
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// isInvokeDenied reports whether Lambda denied an invocation, as it does until a new
+// permission has propagated
+func isInvokeDenied(err error) bool {
+	var apiErr smithy.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == "AccessDeniedException"
+}
@@ -0,0 +1,126 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInvocableFunctionARN = "arn:aws:lambda:us-east-1:987654321098:function:test-function"
+
+// invocableDeployer returns a deployer whose function policy reads back the CLM
+// statement from the given GetPolicy call on
+func invocableDeployer(config DeploymentConfig, visibleFrom int) (*Deployer, *[]string) {
+	var qualifiers []string
+	mockLambda := &mockLambdaClient{
+		getPolicyFunc: func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+			qualifiers = append(qualifiers, aws.ToString(params.Qualifier))
+			if len(qualifiers) < visibleFrom {
+				return nil, &lambdaTypes.ResourceNotFoundException{Message: aws.String("The resource you requested does not exist.")}
+			}
+			return &lambda.GetPolicyOutput{Policy: aws.String(functionPolicyDocument(testCLMRole))}, nil
+		},
+	}
+
+	config.FunctionName = "test-function"
+	config.CLMServiceRoleARN = testCLMRole
+	config.SourceAccountID = "987654321098"
+	deployer := NewDeployer(mockLambda, nil, nil, config)
+	deployer.pollInterval = time.Millisecond
+	return deployer, &qualifiers
+}
+
+// accessDeniedError returns the error Lambda reports for an invocation its policy does not allow
+func accessDeniedError() error {
+	return &lambdaTypes.ServiceException{Message: aws.String("denied"), ErrorCodeOverride: aws.String("AccessDeniedException")}
+}
+
+func TestDeployer_WaitForInvocable_PolicyReadBack(t *testing.T) {
+	deployer, qualifiers := invocableDeployer(DeploymentConfig{ResourcePolicyQualifier: "live"}, 3)
+
+	result, err := deployer.WaitForInvocable(context.Background(), testInvocableFunctionARN, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"live", "live", "live"}, *qualifiers)
+	assert.Equal(t, testInvocableFunctionARN+":live", result.Target)
+	assert.False(t, result.Invoked)
+}
+
+func TestDeployer_WaitForInvocable_TestInvoke(t *testing.T) {
+	deployer, _ := invocableDeployer(DeploymentConfig{}, 1)
+
+	attempts := 0
+	clmLambda := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			attempts++
+			assert.Equal(t, testInvocableFunctionARN, aws.ToString(params.FunctionName))
+			if attempts < 3 {
+				return nil, accessDeniedError()
+			}
+			return &lambda.InvokeOutput{Payload: []byte(`{"status":"healthy"}`)}, nil
+		},
+	}
+
+	result, err := deployer.WaitForInvocable(context.Background(), testInvocableFunctionARN, clmLambda)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, attempts)
+	assert.True(t, result.Invoked)
+	assert.GreaterOrEqual(t, result.InvokeWait, result.PolicyWait)
+}
+
+func TestDeployer_WaitForInvocable_FunctionErrorIsInvocable(t *testing.T) {
+	deployer, _ := invocableDeployer(DeploymentConfig{}, 1)
+	clmLambda := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			return &lambda.InvokeOutput{FunctionError: aws.String("Unhandled"), Payload: []byte(`{"errorMessage":"boom"}`)}, nil
+		},
+	}
+
+	result, err := deployer.WaitForInvocable(context.Background(), testInvocableFunctionARN, clmLambda)
+	require.NoError(t, err)
+	assert.True(t, result.Invoked)
+}
+
+func TestDeployer_WaitForInvocable_InvokeError(t *testing.T) {
+	deployer, _ := invocableDeployer(DeploymentConfig{}, 1)
+	clmLambda := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			return nil, errors.New("connection reset")
+		},
+	}
+
+	_, err := deployer.WaitForInvocable(context.Background(), testInvocableFunctionARN, clmLambda)
+	assert.ErrorContains(t, err, "test invocation of "+testInvocableFunctionARN+" as "+testCLMRole+" failed")
+}
+
+func TestDeployer_WaitForInvocable_Timeout(t *testing.T) {
+	deployer, _ := invocableDeployer(DeploymentConfig{Timeouts: StepTimeouts{Invocable: 20 * time.Millisecond}}, 1)
+	clmLambda := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			return nil, accessDeniedError()
+		},
+	}
+
+	_, err := deployer.WaitForInvocable(context.Background(), testInvocableFunctionARN, clmLambda)
+
+	var timeoutErr *StepTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, TimedInvocable, timeoutErr.Step)
+	assert.Contains(t, err.Error(), "are still denied")
+	assert.Contains(t, err.Error(), "--invocable-timeout")
+}
+
+func TestDeployer_WaitForInvocable_RequiresCLMRole(t *testing.T) {
+	deployer := NewDeployer(&mockLambdaClient{}, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+
+	_, err := deployer.WaitForInvocable(context.Background(), testInvocableFunctionARN, nil)
+	assert.ErrorContains(t, err, "CLM service role ARN is required")
+}
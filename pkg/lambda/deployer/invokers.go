@@ -0,0 +1,212 @@
+package deployer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// invokerStatementPrefix starts the IDs of statements added by AllowInvoker
+const invokerStatementPrefix = "AllowInvoker-"
+
+// accountIDRegexp matches an AWS account ID
+var accountIDRegexp = regexp.MustCompile(`^[0-9]{12}$`)
+
+// FunctionPolicyAPI defines the Lambda operations needed to read and edit a
+// function's resource-based policy
+type FunctionPolicyAPI interface {
+	GetPolicy(ctx context.Context, params *lambda.GetPolicyInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error)
+	AddPermission(ctx context.Context, params *lambda.AddPermissionInput,
+		optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error)
+	RemovePermission(ctx context.Context, params *lambda.RemovePermissionInput,
+		optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error)
+}
+
+// PolicyStatement is a statement of a function's resource-based policy
+type PolicyStatement struct {
+	Sid        string   `json:"sid"`
+	Effect     string   `json:"effect"`
+	Principal  string   `json:"principal"`
+	Action     string   `json:"action"`
+	Conditions []string `json:"conditions,omitempty"` // Each as "<operator> <key>=<value>", sorted
+
+	// Managed reports whether this is the statement setup-account maintains for CLM
+	Managed bool `json:"managed"`
+}
+
+// ReadFunctionPolicy returns the statements of a function's resource-based policy, or
+// of the alias or version named by qualifier. A function without a policy has none.
+func ReadFunctionPolicy(ctx context.Context, client FunctionPolicyAPI, functionName, qualifier string) ([]PolicyStatement, error) {
+	input := &lambda.GetPolicyInput{FunctionName: aws.String(functionName)}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+
+	output, err := client.GetPolicy(ctx, input)
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get function policy: %w", err)
+	}
+
+	var policy rawPolicy
+	if err := json.Unmarshal([]byte(aws.ToString(output.Policy)), &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse function policy: %w", err)
+	}
+	statements := make([]PolicyStatement, 0, len(policy.Statement))
+	for _, raw := range policy.Statement {
+		statement := PolicyStatement{
+			Sid:       raw.Sid,
+			Effect:    raw.Effect,
+			Principal: principalValue(raw.Principal),
+			Action:    stringValue(raw.Action),
+			Managed:   raw.Sid == resourcePolicyStatementID,
+		}
+		for operator, values := range raw.Condition {
+			for key, value := range values {
+				statement.Conditions = append(statement.Conditions, fmt.Sprintf("%s %s=%s", operator, key, stringValue(value)))
+			}
+		}
+		sort.Strings(statement.Conditions)
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// InvokerStatementID returns the ID of the statement AllowInvoker adds for principal.
+// It is derived from the principal so adding the same invoker twice is detected.
+func InvokerStatementID(principal string) string {
+	sum := sha256.Sum256([]byte(principal))
+	return invokerStatementPrefix + hex.EncodeToString(sum[:8])
+}
+
+// ValidateInvoker checks that principal is an IAM role, user, or account root ARN, or
+// an account ID
+func ValidateInvoker(principal string) error {
+	if accountIDRegexp.MatchString(principal) {
+		return nil
+	}
+	parsed, err := arn.Parse(principal)
+	if err != nil || parsed.Service != "iam" || parsed.AccountID == "" {
+		return fmt.Errorf("invoker %q must be an IAM role, user, or account ARN, or an account ID", principal)
+	}
+	if parsed.Resource != "root" && !strings.HasPrefix(parsed.Resource, "role/") && !strings.HasPrefix(parsed.Resource, "user/") {
+		return fmt.Errorf("invoker %q must be an IAM role, user, or account ARN, or an account ID", principal)
+	}
+	return nil
+}
+
+// AllowInvoker adds a statement allowing principal to invoke the function, or the
+// alias or version named by qualifier, and reports whether it was added. A principal
+// already allowed by a statement AllowInvoker added is left alone.
+func AllowInvoker(ctx context.Context, client FunctionPolicyAPI, functionName, qualifier, principal string) (bool, error) {
+	if err := ValidateInvoker(principal); err != nil {
+		return false, err
+	}
+	if qualifier != "" {
+		if err := ValidateQualifier(qualifier); err != nil {
+			return false, err
+		}
+	}
+
+	sid := InvokerStatementID(principal)
+	statements, err := ReadFunctionPolicy(ctx, client, functionName, qualifier)
+	if err != nil {
+		return false, err
+	}
+	for _, statement := range statements {
+		if statement.Sid == sid {
+			return false, nil
+		}
+	}
+
+	input := &lambda.AddPermissionInput{
+		FunctionName: aws.String(functionName),
+		StatementId:  aws.String(sid),
+		Action:       aws.String(invokeAction),
+		Principal:    aws.String(principal),
+	}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+	if _, err := client.AddPermission(ctx, input); err != nil {
+		// Another caller added the same statement since the policy was read
+		var conflictErr *lambdaTypes.ResourceConflictException
+		if errors.As(err, &conflictErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to allow %s to invoke %s: %w", principal, functionName, err)
+	}
+	return true, nil
+}
+
+// RevokeInvoker removes the statements allowing ref to invoke the function, or the
+// alias or version named by qualifier, and returns their IDs. ref is a statement ID,
+// or the principal of the statements: an ARN, or an account ID matching its root ARN.
+// The statement setup-account maintains for CLM is refused, since removing it stops
+// CLM from provisioning; teardown removes it.
+func RevokeInvoker(ctx context.Context, client FunctionPolicyAPI, functionName, qualifier, ref string) ([]string, error) {
+	statements, err := ReadFunctionPolicy(ctx, client, functionName, qualifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []PolicyStatement
+	for _, statement := range statements {
+		if !statementMatches(statement, ref) {
+			continue
+		}
+		if statement.Managed {
+			return nil, fmt.Errorf("statement %s allows CLM to invoke %s and is managed by setup-account; run teardown to remove it", statement.Sid, functionName)
+		}
+		matched = append(matched, statement)
+	}
+	if len(matched) == 0 {
+		return nil, fmt.Errorf("no statement in the policy of %s has the ID or principal %s", functionName, ref)
+	}
+
+	var removed []string
+	for _, statement := range matched {
+		input := &lambda.RemovePermissionInput{
+			FunctionName: aws.String(functionName),
+			StatementId:  aws.String(statement.Sid),
+		}
+		if qualifier != "" {
+			input.Qualifier = aws.String(qualifier)
+		}
+		if _, err := client.RemovePermission(ctx, input); err != nil {
+			var notFoundErr *lambdaTypes.ResourceNotFoundException
+			if !errors.As(err, &notFoundErr) {
+				return removed, fmt.Errorf("failed to remove statement %s: %w", statement.Sid, err)
+			}
+		}
+		removed = append(removed, statement.Sid)
+	}
+	return removed, nil
+}
+
+// statementMatches reports whether ref is the statement's ID or principal
+func statementMatches(statement PolicyStatement, ref string) bool {
+	if statement.Sid == ref || statement.Principal == ref {
+		return true
+	}
+	if accountIDRegexp.MatchString(ref) {
+		parsed, err := arn.Parse(statement.Principal)
+		return err == nil && parsed.AccountID == ref && parsed.Resource == "root"
+	}
+	return false
+}
@@ -0,0 +1,142 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testInvoker = "arn:aws:iam::111122223333:role/ci-backfill"
+
+func TestReadFunctionPolicy(t *testing.T) {
+	var calls []string
+	statements, err := ReadFunctionPolicy(context.Background(), policyClient(testCLMRole, &calls), "test-function", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []PolicyStatement{
+		{
+			Sid:        "AllowCLMInvoke",
+			Effect:     "Allow",
+			Principal:  "arn:aws:iam::987654321098:root",
+			Action:     "lambda:InvokeFunction",
+			Conditions: []string{"ArnLike AWS:SourceArn=" + testCLMRole},
+			Managed:    true,
+		},
+		{
+			Sid:       "AllowEvents",
+			Effect:    "Allow",
+			Principal: "events.amazonaws.com",
+			Action:    "lambda:InvokeFunction",
+		},
+	}, statements)
+}
+
+func TestReadFunctionPolicy_NoPolicy(t *testing.T) {
+	client := &mockLambdaClient{
+		getPolicyFunc: func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+			assert.Equal(t, "live", aws.ToString(params.Qualifier))
+			return nil, &lambdaTypes.ResourceNotFoundException{Message: aws.String("The resource you requested does not exist.")}
+		},
+	}
+
+	statements, err := ReadFunctionPolicy(context.Background(), client, "test-function", "live")
+	require.NoError(t, err)
+	assert.Empty(t, statements)
+}
+
+func TestValidateInvoker(t *testing.T) {
+	for _, valid := range []string{testInvoker, "arn:aws:iam::111122223333:root", "arn:aws:iam::111122223333:user/ops", "111122223333"} {
+		assert.NoError(t, ValidateInvoker(valid), valid)
+	}
+	for _, invalid := range []string{"", "ci-backfill", "arn:aws:sts::111122223333:assumed-role/ci/session", "arn:aws:iam::111122223333:policy/p", "1234"} {
+		assert.Error(t, ValidateInvoker(invalid), invalid)
+	}
+}
+
+func TestAllowInvoker(t *testing.T) {
+	var added *lambda.AddPermissionInput
+	client := &mockLambdaClient{
+		getPolicyFunc: func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+			return &lambda.GetPolicyOutput{Policy: aws.String(functionPolicyDocument(testCLMRole))}, nil
+		},
+		addPermissionFunc: func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+			added = params
+			return &lambda.AddPermissionOutput{}, nil
+		},
+	}
+
+	ok, err := AllowInvoker(context.Background(), client, "test-function", "live", testInvoker)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NotNil(t, added)
+	assert.Equal(t, InvokerStatementID(testInvoker), aws.ToString(added.StatementId))
+	assert.Equal(t, testInvoker, aws.ToString(added.Principal))
+	assert.Equal(t, invokeAction, aws.ToString(added.Action))
+	assert.Equal(t, "live", aws.ToString(added.Qualifier))
+}
+
+func TestAllowInvoker_AlreadyAllowed(t *testing.T) {
+	policy := `{"Statement":[{"Sid":"` + InvokerStatementID(testInvoker) + `","Effect":"Allow",` +
+		`"Principal":{"AWS":"` + testInvoker + `"},"Action":"lambda:InvokeFunction"}]}`
+	client := &mockLambdaClient{
+		getPolicyFunc: func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+			return &lambda.GetPolicyOutput{Policy: aws.String(policy)}, nil
+		},
+		addPermissionFunc: func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+			t.Fatal("the statement already exists")
+			return nil, nil
+		},
+	}
+
+	ok, err := AllowInvoker(context.Background(), client, "test-function", "", testInvoker)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestAllowInvoker_Invalid(t *testing.T) {
+	_, err := AllowInvoker(context.Background(), &mockLambdaClient{}, "test-function", "", "ci-backfill")
+	assert.ErrorContains(t, err, "must be an IAM role, user, or account ARN")
+
+	_, err = AllowInvoker(context.Background(), &mockLambdaClient{}, "test-function", "$LATEST", testInvoker)
+	assert.ErrorContains(t, err, "cannot be $LATEST")
+}
+
+func TestRevokeInvoker(t *testing.T) {
+	policy := `{"Statement":[
+		{"Sid":"AllowCLMInvoke","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::987654321098:root"},"Action":"lambda:InvokeFunction"},
+		{"Sid":"` + InvokerStatementID(testInvoker) + `","Effect":"Allow","Principal":{"AWS":"` + testInvoker + `"},"Action":"lambda:InvokeFunction"},
+		{"Sid":"ops","Effect":"Allow","Principal":{"AWS":"arn:aws:iam::111122223333:root"},"Action":"lambda:InvokeFunction"}
+	]}`
+	var removed []string
+	client := &mockLambdaClient{
+		getPolicyFunc: func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+			return &lambda.GetPolicyOutput{Policy: aws.String(policy)}, nil
+		},
+		removePermissionFunc: func(ctx context.Context, params *lambda.RemovePermissionInput, optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error) {
+			removed = append(removed, aws.ToString(params.StatementId))
+			return &lambda.RemovePermissionOutput{}, nil
+		},
+	}
+
+	sids, err := RevokeInvoker(context.Background(), client, "test-function", "", testInvoker)
+	require.NoError(t, err)
+	assert.Equal(t, []string{InvokerStatementID(testInvoker)}, sids)
+
+	sids, err = RevokeInvoker(context.Background(), client, "test-function", "", "111122223333")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ops"}, sids, "an account ID matches its root principal")
+
+	_, err = RevokeInvoker(context.Background(), client, "test-function", "", "AllowCLMInvoke")
+	assert.ErrorContains(t, err, "managed by setup-account")
+
+	_, err = RevokeInvoker(context.Background(), client, "test-function", "", "arn:aws:iam::444455556666:role/other")
+	assert.ErrorContains(t, err, "no statement in the policy of test-function")
+
+	assert.Equal(t, []string{InvokerStatementID(testInvoker), "ops"}, removed)
+}
@@ -0,0 +1,231 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+)
+
+// ResourceTypeLedgerTable identifies the DynamoDB table the provisioner records its
+// requests in
+const ResourceTypeLedgerTable = "dynamodb-table"
+
+// ledgerTableActiveTimeout bounds waiting for a new ledger table to become active
+const ledgerTableActiveTimeout = 2 * time.Minute
+
+// LedgerTableAPI defines the DynamoDB operations needed to manage the ledger table
+type LedgerTableAPI interface {
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+	DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error)
+	ListTagsOfResource(ctx context.Context, params *dynamodb.ListTagsOfResourceInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error)
+	TagResource(ctx context.Context, params *dynamodb.TagResourceInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.TagResourceOutput, error)
+}
+
+// WithLedgerTableClient lets the deployer create and reconcile DeploymentConfig's
+// LedgerTableName
+func WithLedgerTableClient(client LedgerTableAPI) DeployerOption {
+	return func(d *Deployer) {
+		d.ledgerClient = client
+	}
+}
+
+// validateLedger checks the ledger table name and that the table can be managed
+func (d *Deployer) validateLedger() error {
+	if d.config.LedgerTableName == "" {
+		return nil
+	}
+	if err := ledger.ValidateTableName(d.config.LedgerTableName); err != nil {
+		return err
+	}
+	if d.ledgerClient == nil && d.runs(StepLedgerTable) {
+		return errors.New("a ledger table requires a DynamoDB client")
+	}
+	return nil
+}
+
+// ledgerTableResource is the DynamoDB table the provisioner records its requests in.
+// A table with another key schema cannot hold the ledger and is refused.
+type ledgerTableResource struct {
+	d    *Deployer
+	name string
+}
+
+func (d *Deployer) ledgerTableResource(name string) *ledgerTableResource {
+	return &ledgerTableResource{d: d, name: name}
+}
+
+func (r *ledgerTableResource) Ref() deploy.Ref {
+	return deploy.Ref{Type: ResourceTypeLedgerTable, ID: r.name}
+}
+
+func (r *ledgerTableResource) Ensure(ctx context.Context) (string, error) {
+	table, err := r.describe(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	action := ResourceActionUnchanged
+	if table == nil {
+		input := ledger.TableDefinition(r.name)
+		input.Tags = r.tags()
+		if _, err := r.d.ledgerClient.CreateTable(ctx, input); err != nil {
+			return "", fmt.Errorf("failed to create ledger table %s: %w", r.name, err)
+		}
+		action = ResourceActionCreated
+	} else {
+		if !ledger.HasKeySchema(table.KeySchema) {
+			return "", fmt.Errorf("table %s exists but does not have the ledger's key schema (%s, %s); name another ledger table",
+				r.name, ledger.AttributeIssuerURL, ledger.AttributeRequestID)
+		}
+		managed, err := r.managed(ctx, table)
+		if err != nil {
+			return "", err
+		}
+		if !managed {
+			if !r.d.config.Adopt {
+				return "", &UnmanagedResourceError{Type: ResourceTypeLedgerTable, Identifier: r.name}
+			}
+			_, err := r.d.ledgerClient.TagResource(ctx, &dynamodb.TagResourceInput{
+				ResourceArn: table.TableArn,
+				Tags:        r.tags(),
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to tag ledger table %s: %w", r.name, err)
+			}
+			action = ResourceActionAdopted
+		}
+	}
+	r.d.record(ResourceTypeLedgerTable, r.name, action)
+
+	if err := r.waitForActive(ctx); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+func (r *ledgerTableResource) Diff(ctx context.Context) (*deploy.Diff, error) {
+	diff := &deploy.Diff{Ref: r.Ref()}
+
+	table, err := r.describe(ctx)
+	if err != nil || table == nil {
+		return diff, err
+	}
+	diff.Exists = true
+	if diff.Managed, err = r.managed(ctx, table); err != nil {
+		return nil, err
+	}
+	if !diff.Managed {
+		diff.Changes = append(diff.Changes, r.d.managedTagChange())
+	}
+	if !ledger.HasKeySchema(table.KeySchema) {
+		diff.Changes = append(diff.Changes, deploy.Change{
+			Field:   "key schema",
+			Current: keySchemaString(table.KeySchema),
+			Desired: keySchemaString(ledger.KeySchema()),
+		})
+	}
+	return diff, nil
+}
+
+func (r *ledgerTableResource) Delete(ctx context.Context) error {
+	_, err := r.d.ledgerClient.DeleteTable(ctx, &dynamodb.DeleteTableInput{
+		TableName: aws.String(r.name),
+	})
+	var notFoundErr *dynamodbTypes.ResourceNotFoundException
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
+
+// describe returns the table, or nil if it does not exist
+func (r *ledgerTableResource) describe(ctx context.Context) (*dynamodbTypes.TableDescription, error) {
+	if r.d.ledgerClient == nil {
+		return nil, errors.New("a ledger table requires a DynamoDB client")
+	}
+	output, err := r.d.ledgerClient.DescribeTable(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(r.name),
+	})
+	if err != nil {
+		var notFoundErr *dynamodbTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to describe ledger table %s: %w", r.name, err)
+	}
+	return output.Table, nil
+}
+
+// managed reports whether the table carries rosactl's ownership tag
+func (r *ledgerTableResource) managed(ctx context.Context, table *dynamodbTypes.TableDescription) (bool, error) {
+	output, err := r.d.ledgerClient.ListTagsOfResource(ctx, &dynamodb.ListTagsOfResourceInput{
+		ResourceArn: table.TableArn,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list ledger table tags: %w", err)
+	}
+	tags := make(map[string]string, len(output.Tags))
+	for _, tag := range output.Tags {
+		tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return r.d.isManaged(tags), nil
+}
+
+// tags returns resourceTags in DynamoDB form, sorted by key
+func (r *ledgerTableResource) tags() []dynamodbTypes.Tag {
+	tags := r.d.resourceTags()
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	dynamodbTags := make([]dynamodbTypes.Tag, 0, len(keys))
+	for _, key := range keys {
+		dynamodbTags = append(dynamodbTags, dynamodbTypes.Tag{Key: aws.String(key), Value: aws.String(tags[key])})
+	}
+	return dynamodbTags
+}
+
+// waitForActive waits until the table accepts writes, so the provisioner's first
+// request after deploying is recorded
+func (r *ledgerTableResource) waitForActive(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, ledgerTableActiveTimeout)
+	defer cancel()
+	for {
+		table, err := r.describe(ctx)
+		if err != nil {
+			return err
+		}
+		if table != nil && table.TableStatus == dynamodbTypes.TableStatusActive {
+			return nil
+		}
+		if err := r.d.sleep(ctx); err != nil {
+			return fmt.Errorf("ledger table %s is not active: %w", r.name, err)
+		}
+	}
+}
+
+// keySchemaString describes a key schema as name (type) pairs
+func keySchemaString(schema []dynamodbTypes.KeySchemaElement) string {
+	elements := make([]string, 0, len(schema))
+	for _, element := range schema {
+		elements = append(elements, fmt.Sprintf("%s (%s)", aws.ToString(element.AttributeName), element.KeyType))
+	}
+	return strings.Join(elements, ", ")
+}
@@ -0,0 +1,195 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLedgerTableARN = "arn:aws:dynamodb:us-east-1:123456789012:table/rosa-oidc-provisioner-ledger"
+
+// mockDynamoDBClient keeps a single table, which becomes active after creatingPolls
+// further describes
+type mockDynamoDBClient struct {
+	table         *dynamodbTypes.TableDescription
+	tags          []dynamodbTypes.Tag
+	creatingPolls int
+	created       *dynamodb.CreateTableInput
+	deleted       bool
+}
+
+func (m *mockDynamoDBClient) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	m.created = params
+	m.table = &dynamodbTypes.TableDescription{
+		TableName:   params.TableName,
+		TableArn:    aws.String(testLedgerTableARN),
+		TableStatus: dynamodbTypes.TableStatusCreating,
+		KeySchema:   params.KeySchema,
+	}
+	m.tags = params.Tags
+	return &dynamodb.CreateTableOutput{TableDescription: m.table}, nil
+}
+
+func (m *mockDynamoDBClient) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	if m.table == nil {
+		return nil, &dynamodbTypes.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	}
+	if m.table.TableStatus == dynamodbTypes.TableStatusCreating {
+		if m.creatingPolls == 0 {
+			m.table.TableStatus = dynamodbTypes.TableStatusActive
+		} else {
+			m.creatingPolls--
+		}
+	}
+	table := *m.table
+	return &dynamodb.DescribeTableOutput{Table: &table}, nil
+}
+
+func (m *mockDynamoDBClient) DeleteTable(ctx context.Context, params *dynamodb.DeleteTableInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteTableOutput, error) {
+	if m.table == nil {
+		return nil, &dynamodbTypes.ResourceNotFoundException{Message: aws.String("Requested resource not found")}
+	}
+	m.table = nil
+	m.deleted = true
+	return &dynamodb.DeleteTableOutput{}, nil
+}
+
+func (m *mockDynamoDBClient) ListTagsOfResource(ctx context.Context, params *dynamodb.ListTagsOfResourceInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.ListTagsOfResourceOutput, error) {
+	return &dynamodb.ListTagsOfResourceOutput{Tags: m.tags}, nil
+}
+
+func (m *mockDynamoDBClient) TagResource(ctx context.Context, params *dynamodb.TagResourceInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.TagResourceOutput, error) {
+	m.tags = append(m.tags, params.Tags...)
+	return &dynamodb.TagResourceOutput{}, nil
+}
+
+// existingLedgerTable returns a client with an active table of the ledger's key schema
+func existingLedgerTable(tags ...dynamodbTypes.Tag) *mockDynamoDBClient {
+	return &mockDynamoDBClient{
+		table: &dynamodbTypes.TableDescription{
+			TableName:   aws.String(ledger.DefaultTableName),
+			TableArn:    aws.String(testLedgerTableARN),
+			TableStatus: dynamodbTypes.TableStatusActive,
+			KeySchema:   ledger.KeySchema(),
+		},
+		tags: tags,
+	}
+}
+
+func ledgerDeployer(client *mockDynamoDBClient, adopt bool) *Deployer {
+	d := NewDeployer(nil, nil, nil, DeploymentConfig{
+		FunctionName:    "rosa-oidc-provisioner",
+		LedgerTableName: ledger.DefaultTableName,
+		Adopt:           adopt,
+	}, WithLedgerTableClient(client))
+	d.pollInterval = time.Millisecond
+	return d
+}
+
+func TestLedgerTable_Create(t *testing.T) {
+	client := &mockDynamoDBClient{creatingPolls: 2}
+	d := ledgerDeployer(client, false)
+
+	action, err := d.ledgerTableResource(ledger.DefaultTableName).Ensure(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionCreated, action)
+	require.NotNil(t, client.created)
+	assert.Equal(t, dynamodbTypes.BillingModePayPerRequest, client.created.BillingMode)
+	assert.Contains(t, client.created.Tags, dynamodbTypes.Tag{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)})
+	assert.Equal(t, dynamodbTypes.TableStatusActive, client.table.TableStatus, "Ensure waits for the table to become active")
+	assert.Equal(t, []ResourceRecord{{Type: ResourceTypeLedgerTable, Identifier: ledger.DefaultTableName, Action: ResourceActionCreated}}, d.resources)
+
+	action, err = d.ledgerTableResource(ledger.DefaultTableName).Ensure(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionUnchanged, action)
+}
+
+func TestLedgerTable_Unmanaged(t *testing.T) {
+	client := existingLedgerTable()
+
+	_, err := ledgerDeployer(client, false).ledgerTableResource(ledger.DefaultTableName).Ensure(context.Background())
+	var unmanagedErr *UnmanagedResourceError
+	require.ErrorAs(t, err, &unmanagedErr)
+	assert.Equal(t, ResourceTypeLedgerTable, unmanagedErr.Type)
+
+	action, err := ledgerDeployer(client, true).ledgerTableResource(ledger.DefaultTableName).Ensure(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionAdopted, action)
+	assert.Contains(t, client.tags, dynamodbTypes.Tag{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)})
+}
+
+func TestLedgerTable_RefusesOtherKeySchema(t *testing.T) {
+	client := existingLedgerTable(dynamodbTypes.Tag{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)})
+	client.table.KeySchema = []dynamodbTypes.KeySchemaElement{{AttributeName: aws.String("id"), KeyType: dynamodbTypes.KeyTypeHash}}
+	d := ledgerDeployer(client, true)
+	resource := d.ledgerTableResource(ledger.DefaultTableName)
+
+	_, err := resource.Ensure(context.Background())
+	assert.ErrorContains(t, err, "does not have the ledger's key schema")
+
+	diff, err := resource.Diff(context.Background())
+	require.NoError(t, err)
+	assert.True(t, diff.Exists)
+	assert.True(t, diff.Managed)
+	require.Len(t, diff.Changes, 1)
+	assert.Equal(t, "id (HASH)", diff.Changes[0].Current)
+	assert.Equal(t, "issuer_url (HASH), request_id (RANGE)", diff.Changes[0].Desired)
+}
+
+func TestLedgerTable_Delete(t *testing.T) {
+	client := existingLedgerTable()
+	resource := ledgerDeployer(client, false).ledgerTableResource(ledger.DefaultTableName)
+
+	require.NoError(t, resource.Delete(context.Background()))
+	assert.True(t, client.deleted)
+	assert.NoError(t, resource.Delete(context.Background()), "a missing table is already deleted")
+}
+
+func TestValidateLedger(t *testing.T) {
+	d := NewDeployer(nil, nil, nil, DeploymentConfig{LedgerTableName: ledger.DefaultTableName})
+	assert.ErrorContains(t, d.validateLedger(), "requires a DynamoDB client")
+
+	d = NewDeployer(nil, nil, nil, DeploymentConfig{
+		LedgerTableName: ledger.DefaultTableName,
+		Steps:           StepSelection{Skip: []string{StepLedgerTable}},
+	})
+	assert.NoError(t, d.validateLedger(), "the client is only needed to manage the table")
+
+	d = NewDeployer(nil, nil, nil, DeploymentConfig{LedgerTableName: "ledger table"}, WithLedgerTableClient(&mockDynamoDBClient{}))
+	assert.Error(t, d.validateLedger())
+}
+
+func TestDeployer_LedgerTable(t *testing.T) {
+	d := NewDeployer(nil, nil, nil, DeploymentConfig{FunctionName: "rosa-oidc-provisioner", LedgerTableName: ledger.DefaultTableName})
+	d.scope = ARNScope{AccountID: "123456789012", Region: "us-east-1"}
+
+	policyStr, err := d.permissionsPolicy()
+	require.NoError(t, err)
+	var policy PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(policyStr), &policy))
+	last := policy.Statement[len(policy.Statement)-1]
+	assert.Equal(t, "dynamodb:PutItem", last.Action)
+	assert.Equal(t, testLedgerTableARN, last.Resource)
+
+	env, err := d.functionEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, ledger.DefaultTableName, env.Variables[ledger.EnvVar])
+
+	d = NewDeployer(nil, nil, nil, DeploymentConfig{FunctionName: "rosa-oidc-provisioner"})
+	env, err = d.functionEnvironment()
+	require.NoError(t, err)
+	assert.NotContains(t, env.Variables, ledger.EnvVar)
+}
@@ -0,0 +1,101 @@
+package deployer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AWS naming limits for the deployed resources
+const (
+	maxFunctionNameLength = 64
+	maxRoleNameLength     = 64
+	maxLogGroupNameLength = 512
+	maxRolePathLength     = 512
+)
+
+var (
+	functionNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	roleNamePattern     = regexp.MustCompile(`^[\w+=,.@-]+$`)
+	logGroupNamePattern = regexp.MustCompile(`^[\w.\-/#]+$`)
+	rolePathPattern     = regexp.MustCompile(`^/([\w+=,.@-]+/)*$`)
+)
+
+// NameValidationError lists every resource name AWS would reject
+type NameValidationError struct {
+	Problems []string
+}
+
+func (e *NameValidationError) Error() string {
+	return fmt.Sprintf("invalid resource names: %s", strings.Join(e.Problems, "; "))
+}
+
+// ValidateNames checks the function, execution role, and log group names against AWS
+// naming rules, so an invalid name is reported before anything is created rather than
+// by whichever service call first rejects it
+func ValidateNames(functionName, executionRoleName string) error {
+	var problems []string
+	check := func(kind, name string, maxLength int, pattern *regexp.Regexp, allowed string) bool {
+		if problem := nameProblem(kind, name, maxLength, pattern, allowed); problem != "" {
+			problems = append(problems, problem)
+			return false
+		}
+		return true
+	}
+
+	functionNameValid := check("function name", functionName, maxFunctionNameLength, functionNamePattern,
+		"letters, numbers, hyphens, and underscores")
+	check("execution role name", executionRoleName, maxRoleNameLength, roleNamePattern,
+		"letters, numbers, and +=,.@_-")
+	// The log group name is derived from the function name; an invalid function name is
+	// reported once rather than again as an invalid log group
+	if functionNameValid {
+		check("log group name", defaultLogGroupName(functionName), maxLogGroupNameLength, logGroupNamePattern,
+			"letters, numbers, and ._-/#")
+	}
+
+	if len(problems) > 0 {
+		return &NameValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidateLogGroupName checks a custom log group name against CloudWatch Logs naming rules
+func ValidateLogGroupName(name string) error {
+	problem := nameProblem("log group name", name, maxLogGroupNameLength, logGroupNamePattern, "letters, numbers, and ._-/#")
+	if problem == "" && strings.HasPrefix(name, "aws/") {
+		problem = fmt.Sprintf("log group name %q must not start with aws/, which is reserved for AWS services", name)
+	}
+	if problem != "" {
+		return &NameValidationError{Problems: []string{problem}}
+	}
+	return nil
+}
+
+// ValidateBindableRolePath checks that path is an IAM path, such as /rosa-operators/,
+// naming a subset of the account's roles; empty disables bind-roles. The root path
+// would let callers rewrite the trust policy of any role, so it is refused.
+func ValidateBindableRolePath(path string) error {
+	switch {
+	case path == "":
+		return nil
+	case path == "/":
+		return fmt.Errorf("bindable role path must not be /, which covers every role in the account")
+	case len(path) > maxRolePathLength || !rolePathPattern.MatchString(path):
+		return fmt.Errorf("bindable role path %q must be an IAM path that begins and ends with /, such as /rosa-operators/", path)
+	}
+	return nil
+}
+
+// nameProblem describes why name breaks a naming rule, or returns "" if it is valid
+func nameProblem(kind, name string, maxLength int, pattern *regexp.Regexp, allowed string) string {
+	switch {
+	case name == "":
+		return fmt.Sprintf("%s must not be empty", kind)
+	case len(name) > maxLength:
+		return fmt.Sprintf("%s %q is %d characters, more than the maximum of %d", kind, name, len(name), maxLength)
+	case !pattern.MatchString(name):
+		return fmt.Sprintf("%s %q may only contain %s", kind, name, allowed)
+	}
+	return ""
+}
@@ -0,0 +1,109 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNames(t *testing.T) {
+	tests := []struct {
+		name         string
+		functionName string
+		roleName     string
+		problems     []string
+	}{
+		{name: "defaults", functionName: DefaultFunctionName, roleName: DefaultExecutionRoleName},
+		{name: "role punctuation", functionName: "fn_1", roleName: "role+=,.@_-1"},
+		{name: "longest names", functionName: strings.Repeat("f", 64), roleName: strings.Repeat("r", 64)},
+		{
+			name:         "empty",
+			functionName: "",
+			roleName:     "",
+			problems:     []string{"function name must not be empty", "execution role name must not be empty"},
+		},
+		{
+			name:         "too long",
+			functionName: strings.Repeat("f", 65),
+			roleName:     strings.Repeat("r", 65),
+			problems: []string{
+				"function name \"" + strings.Repeat("f", 65) + "\" is 65 characters, more than the maximum of 64",
+				"execution role name \"" + strings.Repeat("r", 65) + "\" is 65 characters, more than the maximum of 64",
+			},
+		},
+		{
+			name:         "invalid characters",
+			functionName: "rosa.oidc",
+			roleName:     "rosa role",
+			problems: []string{
+				`function name "rosa.oidc" may only contain letters, numbers, hyphens, and underscores`,
+				`execution role name "rosa role" may only contain letters, numbers, and +=,.@_-`,
+			},
+		},
+		{
+			name:         "only role invalid",
+			functionName: DefaultFunctionName,
+			roleName:     "rosa/role",
+			problems:     []string{`execution role name "rosa/role" may only contain letters, numbers, and +=,.@_-`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNames(tt.functionName, tt.roleName)
+			if tt.problems == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var nameErr *NameValidationError
+			require.True(t, errors.As(err, &nameErr))
+			assert.Equal(t, tt.problems, nameErr.Problems)
+		})
+	}
+}
+
+func TestValidateLogGroupName(t *testing.T) {
+	assert.NoError(t, ValidateLogGroupName("/org/platform/rosa-oidc#1"))
+
+	tests := map[string]string{
+		"":                             "log group name must not be empty",
+		"/org/rosa oidc":               `log group name "/org/rosa oidc" may only contain letters, numbers, and ._-/#`,
+		"aws/rosa":                     `log group name "aws/rosa" must not start with aws/, which is reserved for AWS services`,
+		"/" + strings.Repeat("g", 512): "log group name \"/" + strings.Repeat("g", 512) + "\" is 513 characters, more than the maximum of 512",
+	}
+	for name, problem := range tests {
+		var nameErr *NameValidationError
+		require.ErrorAs(t, ValidateLogGroupName(name), &nameErr)
+		assert.Equal(t, []string{problem}, nameErr.Problems)
+	}
+}
+
+func TestValidateBindableRolePath(t *testing.T) {
+	assert.NoError(t, ValidateBindableRolePath(""))
+	assert.NoError(t, ValidateBindableRolePath("/rosa-operators/"))
+	assert.NoError(t, ValidateBindableRolePath("/org/rosa/operators/"))
+
+	assert.ErrorContains(t, ValidateBindableRolePath("/"), "must not be /")
+	for _, path := range []string{"rosa-operators/", "/rosa-operators", "/rosa-*/", "//"} {
+		assert.ErrorContains(t, ValidateBindableRolePath(path), "must be an IAM path", path)
+	}
+}
+
+func TestDeploy_InvalidNames(t *testing.T) {
+	// No clients: the deployment must fail before any API call
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		FunctionName:      "rosa oidc",
+		ExecutionRoleName: "rosa:role",
+		MemorySize:        128,
+		Timeout:           60,
+	})
+	_, err := deployer.Deploy(context.Background())
+
+	var nameErr *NameValidationError
+	require.True(t, errors.As(err, &nameErr))
+	assert.Len(t, nameErr.Problems, 2)
+}
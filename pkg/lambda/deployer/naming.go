@@ -0,0 +1,108 @@
+package deployer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxNameAffixLength bounds a naming prefix or suffix, leaving room in the 64-character
+// function and role name limits for the names they are applied to
+const maxNameAffixLength = 32
+
+// maxEnvironmentLength bounds a deployment environment name, such as dev or stage
+const maxEnvironmentLength = 16
+
+// EnvironmentTagKey records the deployment environment of the function, execution role,
+// and log group when one is set
+const EnvironmentTagKey = "rosa:environment"
+
+var (
+	// nameAffixPattern allows only characters every named resource accepts
+	nameAffixPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]*$`)
+
+	environmentPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+)
+
+// Naming is an account's resource naming convention: a prefix and a suffix applied to
+// the function, execution role, and log group names, such as acme- and -prod turning
+// rosa-oidc-provisioner into acme-rosa-oidc-provisioner-prod. Environment, when set,
+// is appended as -<environment> before the suffix, so deployments of several
+// environments, such as dev and stage, can coexist in one account. The zero value
+// leaves names unchanged.
+type Naming struct {
+	Prefix      string
+	Suffix      string
+	Environment string
+}
+
+// IsEmpty reports whether the naming leaves names unchanged
+func (n Naming) IsEmpty() bool {
+	return n.Prefix == "" && n.Suffix == "" && n.Environment == ""
+}
+
+// Validate checks that the prefix and suffix only use characters the function, role,
+// and log group names all accept, and that the environment is a valid environment name
+func (n Naming) Validate() error {
+	for _, affix := range []struct{ kind, value string }{{"prefix", n.Prefix}, {"suffix", n.Suffix}} {
+		if len(affix.value) > maxNameAffixLength || !nameAffixPattern.MatchString(affix.value) {
+			return fmt.Errorf("name %s %q must be up to %d letters, numbers, hyphens, and underscores",
+				affix.kind, affix.value, maxNameAffixLength)
+		}
+	}
+	return ValidateDeploymentEnvironment(n.Environment)
+}
+
+// ValidateDeploymentEnvironment checks that environment, when set, is up to 16
+// lowercase letters, numbers, and inner hyphens, such as dev or stage-eu
+func ValidateDeploymentEnvironment(environment string) error {
+	if environment == "" {
+		return nil
+	}
+	if len(environment) > maxEnvironmentLength || !environmentPattern.MatchString(environment) {
+		return fmt.Errorf("environment %q must be up to %d lowercase letters, numbers, and hyphens, starting and ending with a letter or number",
+			environment, maxEnvironmentLength)
+	}
+	return nil
+}
+
+// Apply returns name with the prefix, environment, and suffix
+func (n Naming) Apply(name string) string {
+	if name == "" {
+		return ""
+	}
+	return n.Prefix + name + n.environmentAffix() + n.Suffix
+}
+
+// Base returns the name Apply turned into name, and false when name does not carry
+// the prefix, environment, and suffix
+func (n Naming) Base(name string) (string, bool) {
+	suffix := n.environmentAffix() + n.Suffix
+	if len(name) <= len(n.Prefix)+len(suffix) || !strings.HasPrefix(name, n.Prefix) || !strings.HasSuffix(name, suffix) {
+		return "", false
+	}
+	return name[len(n.Prefix) : len(name)-len(suffix)], true
+}
+
+// ApplyLogGroup applies the naming to the last segment of a log group name, so the
+// path a log naming convention requires, such as /org/lambda/, is kept
+func (n Naming) ApplyLogGroup(name string) string {
+	if name == "" {
+		return ""
+	}
+	i := strings.LastIndex(name, "/")
+	return name[:i+1] + n.Apply(name[i+1:])
+}
+
+// String describes the naming as a pattern, such as acme-<name>-dev-prod
+func (n Naming) String() string {
+	return n.Apply("<name>")
+}
+
+// environmentAffix returns the part of a name that identifies the environment
+func (n Naming) environmentAffix() string {
+	if n.Environment == "" {
+		return ""
+	}
+	return "-" + n.Environment
+}
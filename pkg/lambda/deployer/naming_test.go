@@ -0,0 +1,69 @@
+package deployer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaming(t *testing.T) {
+	naming := Naming{Prefix: "acme-", Suffix: "-prod"}
+	assert.Equal(t, "acme-rosa-oidc-provisioner-prod", naming.Apply(DefaultFunctionName))
+	assert.Equal(t, "", naming.Apply(""))
+	assert.Equal(t, "/org/lambda/acme-oidc-prod", naming.ApplyLogGroup("/org/lambda/oidc"))
+	assert.Equal(t, "acme-<name>-prod", naming.String())
+	assert.Equal(t, DefaultFunctionName, Naming{}.Apply(DefaultFunctionName))
+
+	base, ok := naming.Base("acme-rosa-oidc-provisioner-prod")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultFunctionName, base)
+	_, ok = naming.Base(DefaultFunctionName)
+	assert.False(t, ok)
+	_, ok = naming.Base("acme--prod")
+	assert.False(t, ok)
+
+	assert.NoError(t, naming.Validate())
+	assert.ErrorContains(t, Naming{Prefix: "acme."}.Validate(), `name prefix "acme."`)
+	assert.ErrorContains(t, Naming{Suffix: strings.Repeat("s", 33)}.Validate(), "up to 32")
+}
+
+func TestNaming_Environment(t *testing.T) {
+	naming := Naming{Prefix: "acme-", Environment: "stage"}
+	assert.Equal(t, "acme-rosa-oidc-provisioner-stage", naming.Apply(DefaultFunctionName))
+	assert.Equal(t, "acme-<name>-stage", naming.String())
+	base, ok := naming.Base("acme-rosa-oidc-provisioner-stage")
+	assert.True(t, ok)
+	assert.Equal(t, DefaultFunctionName, base)
+	_, ok = naming.Base("acme-rosa-oidc-provisioner-dev")
+	assert.False(t, ok, "another environment's deployment")
+
+	assert.NoError(t, naming.Validate())
+	assert.NoError(t, ValidateDeploymentEnvironment("stage-eu"))
+	assert.Error(t, ValidateDeploymentEnvironment("Stage"))
+	assert.Error(t, ValidateDeploymentEnvironment("dev-"))
+	assert.Error(t, ValidateDeploymentEnvironment(strings.Repeat("e", 17)))
+
+	config := rollbackConfig()
+	config.Naming = naming
+	d := NewDeployer(nil, nil, nil, config)
+	assert.Equal(t, "stage", d.resourceTags()[EnvironmentTagKey])
+	assert.NotContains(t, NewDeployer(nil, nil, nil, rollbackConfig()).resourceTags(), EnvironmentTagKey)
+}
+
+func TestNewDeployer_AppliesNaming(t *testing.T) {
+	config := rollbackConfig()
+	config.LogGroupName = "/org/lambda/oidc"
+	config.Naming = Naming{Prefix: "acme-", Suffix: "-prod"}
+
+	resources := NewDeployer(nil, nil, nil, config).Resources()
+	var identifiers []string
+	for _, resource := range resources {
+		identifiers = append(identifiers, resource.Ref().ID)
+	}
+	assert.Equal(t, []string{"acme-test-role-prod", "acme-test-function-prod", "/org/lambda/acme-oidc-prod"}, identifiers)
+
+	config.ExecutionRoleARN = "arn:aws:iam::123456789012:role/precreated"
+	assert.Equal(t, "acme-test-function-prod", NewDeployer(nil, nil, nil, config).Resources()[0].Ref().ID,
+		"a pre-created role is not renamed, or managed")
+}
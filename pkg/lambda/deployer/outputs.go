@@ -0,0 +1,77 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// DefaultOutputsPrefix is the SSM path deployment outputs are published under
+const DefaultOutputsPrefix = "/rosa/oidc-provisioner"
+
+// latestVersion is published as the version when no immutable version was published
+const latestVersion = "$LATEST"
+
+// SSMAPI defines the SSM operations needed to publish deployment outputs
+type SSMAPI interface {
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput,
+		optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+// DeploymentOutputs returns the parameters PublishOutputs writes, keyed by name under prefix
+func DeploymentOutputs(prefix string, result *DeploymentResult) map[string]string {
+	version := result.Version
+	if version == "" {
+		version = latestVersion
+	}
+
+	outputs := map[string]string{
+		"function-arn":       result.FunctionARN,
+		"function-name":      result.FunctionName,
+		"execution-role-arn": result.ExecutionRole,
+		"log-group-name":     result.LogGroupName,
+		"version":            version,
+		"package-checksum":   result.PackageChecksum,
+	}
+
+	parameters := make(map[string]string, len(outputs))
+	for name, value := range outputs {
+		parameters[path.Join("/", strings.TrimSuffix(prefix, "/"), name)] = value
+	}
+	return parameters
+}
+
+// PublishOutputs writes the deployment outputs to SSM parameters under prefix so other
+// automation in the account can discover them. It returns the parameter names written.
+func PublishOutputs(ctx context.Context, client SSMAPI, prefix string, result *DeploymentResult) ([]string, error) {
+	parameters := DeploymentOutputs(prefix, result)
+
+	names := make([]string, 0, len(parameters))
+	for name := range parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var written []string
+	for _, name := range names {
+		_, err := client.PutParameter(ctx, &ssm.PutParameterInput{
+			Name:        aws.String(name),
+			Value:       aws.String(parameters[name]),
+			Type:        ssmTypes.ParameterTypeString,
+			Description: aws.String(fmt.Sprintf("OIDC provisioner deployment output for %s", result.FunctionName)),
+			Overwrite:   aws.Bool(true),
+		})
+		if err != nil {
+			return written, fmt.Errorf("failed to write parameter %s: %w", name, err)
+		}
+		written = append(written, name)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,84 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSSMClient struct {
+	putParameterFunc func(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+func (m *mockSSMClient) PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	if m.putParameterFunc != nil {
+		return m.putParameterFunc(ctx, params, optFns...)
+	}
+	return &ssm.PutParameterOutput{}, nil
+}
+
+func testDeploymentResult() *DeploymentResult {
+	return &DeploymentResult{
+		FunctionARN:     "arn:aws:lambda:us-east-1:123456789012:function:test-function",
+		FunctionName:    "test-function",
+		ExecutionRole:   "arn:aws:iam::123456789012:role/test-role",
+		LogGroupName:    "/aws/lambda/test-function",
+		PackageChecksum: "abc123",
+	}
+}
+
+func TestDeploymentOutputs(t *testing.T) {
+	outputs := DeploymentOutputs(DefaultOutputsPrefix, testDeploymentResult())
+
+	assert.Equal(t, map[string]string{
+		"/rosa/oidc-provisioner/function-arn":       "arn:aws:lambda:us-east-1:123456789012:function:test-function",
+		"/rosa/oidc-provisioner/function-name":      "test-function",
+		"/rosa/oidc-provisioner/execution-role-arn": "arn:aws:iam::123456789012:role/test-role",
+		"/rosa/oidc-provisioner/log-group-name":     "/aws/lambda/test-function",
+		"/rosa/oidc-provisioner/version":            "$LATEST",
+		"/rosa/oidc-provisioner/package-checksum":   "abc123",
+	}, outputs)
+
+	result := testDeploymentResult()
+	result.Version = "7"
+	outputs = DeploymentOutputs("team/rosa/", result)
+	assert.Equal(t, "7", outputs["/team/rosa/version"])
+}
+
+func TestPublishOutputs(t *testing.T) {
+	written := make(map[string]string)
+	client := &mockSSMClient{
+		putParameterFunc: func(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+			assert.True(t, *params.Overwrite)
+			written[*params.Name] = *params.Value
+			return &ssm.PutParameterOutput{}, nil
+		},
+	}
+
+	names, err := PublishOutputs(context.Background(), client, DefaultOutputsPrefix, testDeploymentResult())
+	require.NoError(t, err)
+	assert.Len(t, names, 6)
+	assert.IsIncreasing(t, names)
+	assert.Equal(t, DeploymentOutputs(DefaultOutputsPrefix, testDeploymentResult()), written)
+}
+
+func TestPublishOutputs_Error(t *testing.T) {
+	calls := 0
+	client := &mockSSMClient{
+		putParameterFunc: func(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+			calls++
+			if calls == 2 {
+				return nil, errors.New("access denied")
+			}
+			return &ssm.PutParameterOutput{}, nil
+		},
+	}
+
+	names, err := PublishOutputs(context.Background(), client, DefaultOutputsPrefix, testDeploymentResult())
+	assert.ErrorContains(t, err, "failed to write parameter")
+	assert.Len(t, names, 1)
+}
@@ -0,0 +1,94 @@
+package deployer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// environmentVariablePattern is the form Lambda requires of environment variable names
+var environmentVariablePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`)
+
+// OverrideKeys lists the keys FunctionSettings.Set accepts
+var OverrideKeys = []string{"memory", "timeout", "ephemeral-storage", "tracing", "env.<NAME>"}
+
+// FunctionSettings are the function configuration values `--set key=value` overrides
+// apply to, on top of the defaults from the config file and flags, so per-environment
+// variations can be scripted without a config file for each
+type FunctionSettings struct {
+	MemorySize       int32 // MB
+	Timeout          int32 // seconds
+	EphemeralStorage int32 // MB, zero keeps the Lambda default
+	Tracing          bool
+	Environment      map[string]string
+}
+
+// Set applies one key=value override. Keys are dotted paths: memory, timeout,
+// ephemeral-storage, and tracing name a setting, and env.NAME names the environment
+// variable NAME. A later override of the same key wins.
+func (s *FunctionSettings) Set(override string) error {
+	key, value, ok := strings.Cut(override, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value")
+	}
+
+	if name, ok := strings.CutPrefix(key, "env."); ok {
+		if err := validateEnvironmentVariableName(name); err != nil {
+			return err
+		}
+		if s.Environment == nil {
+			s.Environment = make(map[string]string)
+		}
+		s.Environment[name] = value
+		return nil
+	}
+
+	switch key {
+	case "memory":
+		return setInt32(&s.MemorySize, key, value)
+	case "timeout":
+		return setInt32(&s.Timeout, key, value)
+	case "ephemeral-storage":
+		return setInt32(&s.EphemeralStorage, key, value)
+	case "tracing":
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("tracing must be true or false, got %q", value)
+		}
+		s.Tracing = enabled
+		return nil
+	default:
+		return fmt.Errorf("unknown key %q (expected one of %s)", key, strings.Join(OverrideKeys, ", "))
+	}
+}
+
+func setInt32(field *int32, key, value string) error {
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%s must be a whole number, got %q", key, value)
+	}
+	*field = int32(n)
+	return nil
+}
+
+// ValidateEnvironment checks that environment variables set on the function have names
+// Lambda accepts and do not replace the ones rosactl manages
+func ValidateEnvironment(variables map[string]string) error {
+	for name := range variables {
+		if err := validateEnvironmentVariableName(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateEnvironmentVariableName(name string) error {
+	if !environmentVariablePattern.MatchString(name) {
+		return fmt.Errorf("environment variable name %q must start with a letter and contain only letters, numbers, and underscores", name)
+	}
+	if isManagedEnvVar(name) {
+		return fmt.Errorf("environment variable %s is set by rosactl and cannot be overridden", name)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package deployer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctionSettings_Set(t *testing.T) {
+	settings := FunctionSettings{MemorySize: 128, Timeout: 60}
+	for _, override := range []string{
+		"memory=512",
+		"timeout=120",
+		"ephemeral-storage=1024",
+		"tracing=true",
+		"env.LOG_LEVEL=debug",
+		"env.EMPTY=",
+		"env.URL=https://example.com/?a=b",
+		"memory=256",
+	} {
+		require.NoError(t, settings.Set(override), override)
+	}
+
+	assert.Equal(t, FunctionSettings{
+		MemorySize:       256,
+		Timeout:          120,
+		EphemeralStorage: 1024,
+		Tracing:          true,
+		Environment:      map[string]string{"LOG_LEVEL": "debug", "EMPTY": "", "URL": "https://example.com/?a=b"},
+	}, settings)
+}
+
+func TestFunctionSettings_SetInvalid(t *testing.T) {
+	tests := []struct {
+		override string
+		errMsg   string
+	}{
+		{"memory", "expected key=value"},
+		{"=512", "expected key=value"},
+		{"memory=lots", "whole number"},
+		{"tracing=sometimes", "true or false"},
+		{"runtime=provided.al2", "unknown key"},
+		{"memory.max=512", "unknown key"},
+		{"env.1ST=x", "must start with a letter"},
+		{"env.A.B=x", "must start with a letter"},
+		{"env." + ProviderTagsEnvVar + "={}", "set by rosactl"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.override, func(t *testing.T) {
+			var settings FunctionSettings
+			err := settings.Set(tt.override)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
@@ -3,12 +3,18 @@ package deployer
 import (
 	"archive/zip"
 	"bytes"
+	"context"
 	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
 )
 
 const (
@@ -17,18 +23,99 @@ const (
 
 // PackageBuilder builds Lambda deployment packages
 type PackageBuilder struct {
-	sourceDir string
+	sourceDir    string
+	artifactsDir string
+	goarch       string
+	cache        *PackageCache
+	cached       bool
+	buildLog     bytes.Buffer
+}
+
+// PackageBuilderOption configures optional PackageBuilder behavior
+type PackageBuilderOption func(*PackageBuilder)
+
+// WithArtifactsDir keeps the compiled binary, the ZIP package, and the go build
+// command and output in dir after every build, successful or not
+func WithArtifactsDir(dir string) PackageBuilderOption {
+	return func(pb *PackageBuilder) {
+		pb.artifactsDir = dir
+	}
+}
+
+// WithTargetArchitecture builds the package for a Lambda architecture instead of x86_64
+func WithTargetArchitecture(arch lambdaTypes.Architecture) PackageBuilderOption {
+	return func(pb *PackageBuilder) {
+		pb.goarch = GOARCH(arch)
+	}
+}
+
+// WithPackageCache reuses the package a cache holds for the same source instead of
+// compiling, and caches the packages it builds. Builds that keep artifacts always compile.
+func WithPackageCache(cache *PackageCache) PackageBuilderOption {
+	return func(pb *PackageBuilder) {
+		pb.cache = cache
+	}
+}
+
+// GOARCH returns the Go architecture for a Lambda architecture
+func GOARCH(arch lambdaTypes.Architecture) string {
+	if arch == lambdaTypes.ArchitectureArm64 {
+		return "arm64"
+	}
+	return "amd64"
 }
 
 // NewPackageBuilder creates a new package builder
-func NewPackageBuilder(sourceDir string) *PackageBuilder {
-	return &PackageBuilder{
+func NewPackageBuilder(sourceDir string, opts ...PackageBuilderOption) *PackageBuilder {
+	pb := &PackageBuilder{
 		sourceDir: sourceDir,
+		goarch:    "amd64",
 	}
+	for _, opt := range opts {
+		opt(pb)
+	}
+	return pb
 }
 
 // Build compiles the Go binary and packages it into a ZIP file
 func (pb *PackageBuilder) Build() ([]byte, string, error) {
+	return pb.BuildContext(context.Background())
+}
+
+// BuildContext is Build with a context; cancelling ctx kills the go build process
+func (pb *PackageBuilder) BuildContext(ctx context.Context) ([]byte, string, error) {
+	pb.cached = false
+	// The cache is only consulted when no artifacts are kept, since they come from
+	// compiling. A cache that cannot be read or written is passed over.
+	if pb.cache == nil || pb.artifactsDir != "" {
+		return pb.build(ctx)
+	}
+
+	key, err := SourceKey(ctx, pb.sourceDir, pb.goarch)
+	if err != nil {
+		return pb.build(ctx)
+	}
+	if zipData, checksum, ok := pb.cache.Lookup(key); ok {
+		pb.cached = true
+		return zipData, checksum, nil
+	}
+
+	zipData, checksum, err := pb.build(ctx)
+	if err == nil {
+		_ = pb.cache.Store(key, zipData, checksum)
+	}
+	return zipData, checksum, err
+}
+
+// Cached reports whether the last build reused a cached package instead of compiling
+func (pb *PackageBuilder) Cached() bool {
+	return pb.cached
+}
+
+// build compiles the Go binary and packages it into a ZIP file
+func (pb *PackageBuilder) build(ctx context.Context) (_ []byte, _ string, err error) {
+	pb.buildLog.Reset()
+
 	// Create temporary directory for build
 	tmpDir, err := os.MkdirTemp("", "lambda-build-*")
 	if err != nil {
@@ -36,21 +123,39 @@ func (pb *PackageBuilder) Build() ([]byte, string, error) {
 	}
 	defer os.RemoveAll(tmpDir)
 
-	// Cross-compile for Linux/AMD64
 	binaryPath := filepath.Join(tmpDir, "bootstrap")
-	if err := pb.compileBinary(binaryPath); err != nil {
+	var zipData []byte
+
+	// Copy artifacts out of the temp directory before it is removed
+	if pb.artifactsDir != "" {
+		defer func() {
+			if saveErr := pb.saveArtifacts(binaryPath, zipData, err); saveErr != nil {
+				if err == nil {
+					err = saveErr
+				} else {
+					err = fmt.Errorf("%w (also failed to save build artifacts: %v)", err, saveErr)
+				}
+			} else if err != nil {
+				err = fmt.Errorf("%w (build artifacts saved to %s)", err, pb.artifactsDir)
+			}
+		}()
+	}
+
+	// Cross-compile for Linux on the target architecture
+	if err := pb.compileBinary(ctx, binaryPath); err != nil {
 		return nil, "", fmt.Errorf("failed to compile binary: %w", err)
 	}
 
 	// Create ZIP package
-	zipData, err := pb.createZipPackage(binaryPath)
+	zipData, err = pb.createZipPackage(binaryPath)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create zip package: %w", err)
 	}
 
 	// Validate package size
 	if len(zipData) > maxPackageSize {
-		return nil, "", fmt.Errorf("package size %d bytes exceeds maximum %d bytes", len(zipData), maxPackageSize)
+		return nil, "", withCategory(ErrPackageTooLarge,
+			fmt.Errorf("package size %d bytes exceeds maximum %d bytes", len(zipData), maxPackageSize))
 	}
 
 	// Calculate SHA256 hash
@@ -60,36 +165,236 @@ func (pb *PackageBuilder) Build() ([]byte, string, error) {
 	return zipData, hashStr, nil
 }
 
-// compileBinary cross-compiles the Go binary for Linux/AMD64
-func (pb *PackageBuilder) compileBinary(outputPath string) error {
-	cmd := exec.Command("go", "build", "-ldflags", "-s -w", "-o", outputPath, pb.sourceDir)
-	cmd.Env = append(os.Environ(),
-		"GOOS=linux",
-		"GOARCH=amd64",
-		"CGO_ENABLED=0",
-		"GOTOOLCHAIN=auto",
-	)
+// saveArtifacts writes whatever the build produced, plus the build log, to the artifacts directory
+func (pb *PackageBuilder) saveArtifacts(binaryPath string, zipData []byte, buildErr error) error {
+	if err := os.MkdirAll(pb.artifactsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	if binary, err := os.ReadFile(binaryPath); err == nil {
+		if err := os.WriteFile(filepath.Join(pb.artifactsDir, "bootstrap"), binary, 0755); err != nil {
+			return fmt.Errorf("failed to save binary: %w", err)
+		}
+	}
+
+	if zipData != nil {
+		if err := os.WriteFile(filepath.Join(pb.artifactsDir, "bootstrap.zip"), zipData, 0644); err != nil {
+			return fmt.Errorf("failed to save package: %w", err)
+		}
+	}
+
+	log := pb.buildLog.String()
+	if buildErr != nil {
+		log += fmt.Sprintf("result: %v\n", buildErr)
+	} else {
+		log += "result: success\n"
+	}
+
+	if err := os.WriteFile(filepath.Join(pb.artifactsDir, "build.log"), []byte(log), 0644); err != nil {
+		return fmt.Errorf("failed to save build log: %w", err)
+	}
+
+	return nil
+}
+
+// compileBinary cross-compiles the Go binary for Linux on the target architecture
+func (pb *PackageBuilder) compileBinary(ctx context.Context, outputPath string) error {
+	// Build from inside the source directory so the path is never mistaken for an
+	// import path and host path separators don't matter
+	sourceDir, err := filepath.Abs(pb.sourceDir)
+	if err != nil {
+		return fmt.Errorf("compilation failed: invalid source directory %s: %w", pb.sourceDir, err)
+	}
+	if err := ValidateSourceDir(sourceDir); err != nil {
+		return fmt.Errorf("compilation failed: %w", err)
+	}
+
+	env := buildEnv(os.Environ(), runtime.GOOS, pb.goarch)
+	dir, pkg, modeArgs := buildTarget(sourceDir)
+	args := append(append([]string{"build"}, modeArgs...), "-ldflags", "-s -w", "-o", outputPath, pkg)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = env
 
-	var stderr bytes.Buffer
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	if err := cmd.Run(); err != nil {
+	err = cmd.Run()
+	pb.logBuild(cmd, stdout.String(), stderr.String())
+
+	if err != nil {
+		var notes []string
+		if hint := moduleHint(sourceDir); hint != "" {
+			notes = append(notes, hint)
+		}
+		if diagnostics := goEnvDiagnostics(ctx, dir, env); diagnostics != "" {
+			fmt.Fprintf(&pb.buildLog, "go env: %s\n", diagnostics)
+			notes = append(notes, "go env: "+diagnostics)
+		}
+		if len(notes) > 0 {
+			return fmt.Errorf("compilation failed: %w, stderr: %s (%s)", err, stderr.String(), strings.Join(notes, "; "))
+		}
 		return fmt.Errorf("compilation failed: %w, stderr: %s", err, stderr.String())
 	}
 
-	// Verify binary was created
+	// Verify binary was created. Executable permissions are set in the ZIP header
+	// rather than on disk, since hosts like Windows have no executable bit.
 	if _, err := os.Stat(outputPath); err != nil {
 		return fmt.Errorf("binary not found after compilation: %w", err)
 	}
 
-	// Set executable permissions (important for Lambda)
-	if err := os.Chmod(outputPath, 0755); err != nil {
-		return fmt.Errorf("failed to set binary permissions: %w", err)
+	return nil
+}
+
+// buildTarget returns the directory to run go commands for sourceDir in, the package
+// to name, and the module mode flags. The package is built from its module root in
+// explicit module mode, so a go.work file or GOFLAGS=-mod=vendor in a CI checkout
+// cannot swap in other modules.
+func buildTarget(sourceDir string) (dir, pkg string, modeArgs []string) {
+	dir, pkg = sourceDir, "."
+	if root := moduleRoot(sourceDir); root != "" {
+		if rel, err := filepath.Rel(root, sourceDir); err == nil && rel != "." {
+			dir, pkg = root, "./"+filepath.ToSlash(rel)
+		}
+		modeArgs = []string{"-mod=" + moduleMode(root)}
 	}
+	return dir, pkg, modeArgs
+}
 
-	return nil
+// logBuild records the go build invocation and its output for saved artifacts
+func (pb *PackageBuilder) logBuild(cmd *exec.Cmd, stdout, stderr string) {
+	fmt.Fprintf(&pb.buildLog, "host: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&pb.buildLog, "dir: %s\n", cmd.Dir)
+	fmt.Fprintf(&pb.buildLog, "command: %s\n", strings.Join(cmd.Args, " "))
+	fmt.Fprintf(&pb.buildLog, "env: %s\n", strings.Join(targetEnv(pb.goarch), " "))
+	fmt.Fprintf(&pb.buildLog, "stdout:\n%s\n", stdout)
+	fmt.Fprintf(&pb.buildLog, "stderr:\n%s\n", stderr)
+}
+
+// buildEnv returns the host environment with the Lambda target settings applied.
+// Inherited values for the overridden variables are dropped so a host GOOS, GOARCH,
+// or GOEXE cannot leak into the build; Windows environment keys are case-insensitive.
+func buildEnv(environ []string, hostOS, goarch string) []string {
+	target := targetEnv(goarch)
+	overridden := make(map[string]bool, len(target))
+	for _, kv := range target {
+		overridden[envKey(kv, hostOS)] = true
+	}
+
+	env := make([]string, 0, len(environ)+len(target))
+	for _, kv := range environ {
+		if !overridden[envKey(kv, hostOS)] {
+			env = append(env, kv)
+		}
+	}
+
+	return append(env, target...)
+}
+
+// targetEnv returns the environment settings for the Lambda build target. Module
+// settings are isolated from the caller's: workspaces are ignored, and GOFLAGS is
+// cleared because the module mode is passed to go build explicitly.
+func targetEnv(goarch string) []string {
+	return []string{
+		"GOOS=linux",
+		"GOARCH=" + goarch,
+		"CGO_ENABLED=0",
+		"GOEXE=",
+		"GOTOOLCHAIN=auto",
+		"GO111MODULE=on",
+		"GOWORK=off",
+		"GOFLAGS=",
+	}
+}
+
+// moduleMode returns the -mod setting for building the module at root: its vendor
+// directory when it has one, and otherwise the module cache without changing go.mod
+func moduleMode(root string) string {
+	if _, err := os.Stat(filepath.Join(root, "vendor", "modules.txt")); err == nil {
+		return "vendor"
+	}
+	return "readonly"
+}
+
+// goEnvVars are reported when a build fails, to explain which toolchain, module,
+// workspace, and proxy settings it ran with
+var goEnvVars = []string{"GOVERSION", "GOMOD", "GOWORK", "GOFLAGS", "GOPROXY", "GOTOOLCHAIN"}
+
+// goEnvDiagnostics returns goEnvVars as the build in dir saw them, as KEY=value pairs,
+// or an empty string when go env fails
+func goEnvDiagnostics(ctx context.Context, dir string, env []string) string {
+	cmd := exec.CommandContext(context.WithoutCancel(ctx), "go", append([]string{"env"}, goEnvVars...)...)
+	cmd.Dir = dir
+	cmd.Env = env
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	values := strings.Split(strings.ReplaceAll(string(output), "\r\n", "\n"), "\n")
+	pairs := make([]string, 0, len(goEnvVars))
+	for i, name := range goEnvVars {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs = append(pairs, name+"="+value)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// envKey returns the key of a KEY=value entry, normalized for the host's case sensitivity
+func envKey(kv, hostOS string) string {
+	key, _, _ := strings.Cut(kv, "=")
+	if hostOS == "windows" {
+		return strings.ToUpper(key)
+	}
+	return key
+}
+
+// moduleHint explains a build failure caused by the source directory not being
+// inside a Go module, or returns an empty string
+func moduleHint(sourceDir string) string {
+	if _, err := os.Stat(sourceDir); err != nil {
+		return fmt.Sprintf("source directory %s does not exist", sourceDir)
+	}
+
+	cmd := exec.Command("go", "env", "GOMOD")
+	cmd.Dir = sourceDir
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	if !hasModule(parseGoEnv(output), runtime.GOOS) {
+		return fmt.Sprintf("source directory %s is not inside a Go module", sourceDir)
+	}
+	return ""
+}
+
+// parseGoEnv returns a single `go env` value, tolerating CRLF line endings
+func parseGoEnv(output []byte) string {
+	return strings.TrimRight(string(output), "\r\n")
+}
+
+// hasModule reports whether a GOMOD value refers to a real go.mod file. Outside a
+// module, go env reports the null device, which is NUL on Windows.
+func hasModule(gomod, hostOS string) bool {
+	if gomod == "" {
+		return false
+	}
+	if hostOS == "windows" {
+		return !strings.EqualFold(gomod, "NUL")
+	}
+	return gomod != "/dev/null"
 }
 
+// zipModTime is the modification time recorded for the packaged binary: the
+// earliest time a ZIP archive can hold
+var zipModTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
 // createZipPackage creates a ZIP archive containing the binary
 func (pb *PackageBuilder) createZipPackage(binaryPath string) ([]byte, error) {
 	buf := new(bytes.Buffer)
@@ -102,23 +407,17 @@ func (pb *PackageBuilder) createZipPackage(binaryPath string) ([]byte, error) {
 	}
 	defer file.Close()
 
-	// Get file info
-	fileInfo, err := file.Stat()
-	if err != nil {
-		return nil, fmt.Errorf("failed to stat binary: %w", err)
-	}
-
-	// Create ZIP file header with executable permissions
-	header, err := zip.FileInfoHeader(fileInfo)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create zip header: %w", err)
+	// Build the header from scratch rather than from host file info, so the
+	// archive is identical whatever filesystem the binary was written to and
+	// whenever it was built, and an unchanged binary keeps its CodeSha256.
+	// Name is "bootstrap" (required for custom runtime).
+	header := &zip.FileHeader{
+		Name:     "bootstrap",
+		Method:   zip.Deflate,
+		Modified: zipModTime,
 	}
 
-	// Set name to "bootstrap" (required for custom runtime)
-	header.Name = "bootstrap"
-	header.Method = zip.Deflate
-
-	// Preserve executable permissions in ZIP
+	// Mark as a Unix executable regardless of host permissions
 	header.SetMode(0755)
 
 	// Create file entry in ZIP
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -101,13 +102,32 @@ func TestPackageBuilder_HashConsistency(t *testing.T) {
 	zipData2, hash2, err := pb.Build()
 	require.NoError(t, err)
 
-	// Hashes might differ due to build timestamps in the Go binary
-	// but we can verify the hash matches the actual content
 	actualHash1 := fmt.Sprintf("%x", sha256.Sum256(zipData1))
 	actualHash2 := fmt.Sprintf("%x", sha256.Sum256(zipData2))
 
 	assert.Equal(t, hash1, actualHash1)
 	assert.Equal(t, hash2, actualHash2)
+
+	// Rebuilding unchanged source gives the same package, so an unchanged
+	// function keeps its CodeSha256
+	assert.True(t, bytes.Equal(zipData1, zipData2), "rebuilt package differs")
+	assert.Equal(t, hash1, hash2)
+}
+
+func TestCreateZipPackage_IgnoresModTime(t *testing.T) {
+	binaryPath := filepath.Join(t.TempDir(), "bootstrap")
+	require.NoError(t, os.WriteFile(binaryPath, []byte("test"), 0755))
+
+	pb := NewPackageBuilder("")
+	zipData1, err := pb.createZipPackage(binaryPath)
+	require.NoError(t, err)
+
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(binaryPath, later, later))
+	zipData2, err := pb.createZipPackage(binaryPath)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.Equal(zipData1, zipData2), "package depends on the binary's modification time")
 }
 
 func TestPackageBuilder_BinaryPermissions(t *testing.T) {
@@ -133,3 +153,217 @@ func TestPackageBuilder_BinaryPermissions(t *testing.T) {
 	mode := zipReader.File[0].Mode()
 	assert.True(t, mode&0111 != 0, "bootstrap should have executable permissions in ZIP")
 }
+
+func TestBuildEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostOS   string
+		goarch   string
+		environ  []string
+		expected []string
+		dropped  []string
+	}{
+		{
+			name:     "linux host keeps unrelated variables",
+			hostOS:   "linux",
+			environ:  []string{"HOME=/home/user", "GOOS=linux", "GOARCH=arm64"},
+			expected: []string{"HOME=/home/user", "GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0"},
+			dropped:  []string{"GOARCH=arm64"},
+		},
+		{
+			name:     "darwin host overrides native target",
+			hostOS:   "darwin",
+			environ:  []string{"GOOS=darwin", "GOARCH=arm64", "CGO_ENABLED=1"},
+			expected: []string{"GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0"},
+			dropped:  []string{"GOOS=darwin", "GOARCH=arm64", "CGO_ENABLED=1"},
+		},
+		{
+			name:     "windows host matches keys case-insensitively",
+			hostOS:   "windows",
+			environ:  []string{"Path=C:\\Go\\bin", "goos=windows", "GoExe=.exe"},
+			expected: []string{"Path=C:\\Go\\bin", "GOOS=linux", "GOEXE="},
+			dropped:  []string{"goos=windows", "GoExe=.exe"},
+		},
+		{
+			name:     "arm64 target overrides host architecture",
+			hostOS:   "linux",
+			goarch:   "arm64",
+			environ:  []string{"GOARCH=amd64"},
+			expected: []string{"GOOS=linux", "GOARCH=arm64"},
+			dropped:  []string{"GOARCH=amd64"},
+		},
+		{
+			name:     "case differences are distinct variables on unix",
+			hostOS:   "linux",
+			environ:  []string{"goos=windows"},
+			expected: []string{"goos=windows", "GOOS=linux"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			goarch := tt.goarch
+			if goarch == "" {
+				goarch = "amd64"
+			}
+			env := buildEnv(tt.environ, tt.hostOS, goarch)
+
+			for _, kv := range tt.expected {
+				assert.Contains(t, env, kv)
+			}
+			for _, kv := range tt.dropped {
+				assert.NotContains(t, env, kv)
+			}
+		})
+	}
+}
+
+func TestParseGoEnv(t *testing.T) {
+	assert.Equal(t, "/src/go.mod", parseGoEnv([]byte("/src/go.mod\n")))
+	assert.Equal(t, `C:\src\go.mod`, parseGoEnv([]byte("C:\\src\\go.mod\r\n")))
+	assert.Equal(t, "", parseGoEnv([]byte("\r\n")))
+}
+
+func TestHasModule(t *testing.T) {
+	tests := []struct {
+		gomod    string
+		hostOS   string
+		expected bool
+	}{
+		{"/src/go.mod", "linux", true},
+		{"/dev/null", "linux", false},
+		{"/dev/null", "darwin", false},
+		{"", "darwin", false},
+		{`C:\src\go.mod`, "windows", true},
+		{"NUL", "windows", false},
+		{"nul", "windows", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.hostOS+"/"+tt.gomod, func(t *testing.T) {
+			assert.Equal(t, tt.expected, hasModule(tt.gomod, tt.hostOS))
+		})
+	}
+}
+
+func TestCreateZipPackage_UnixModeFromNonExecutableHost(t *testing.T) {
+	// Simulate a host filesystem without executable bits
+	binaryPath := filepath.Join(t.TempDir(), "bootstrap")
+	require.NoError(t, os.WriteFile(binaryPath, []byte("test"), 0600))
+
+	pb := NewPackageBuilder("")
+	zipData, err := pb.createZipPackage(binaryPath)
+	require.NoError(t, err)
+
+	zipReader, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	require.NoError(t, err)
+
+	file := zipReader.File[0]
+	assert.Equal(t, uint16(3), file.CreatorVersion>>8, "entry should be recorded as created on Unix")
+	assert.Equal(t, os.FileMode(0755), file.Mode().Perm())
+}
+
+func TestPackageBuilder_MissingSourceDirHint(t *testing.T) {
+	pb := NewPackageBuilder(filepath.Join(t.TempDir(), "missing"))
+	_, _, err := pb.Build()
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "compilation failed")
+	assert.Contains(t, err.Error(), "does not exist")
+}
+
+func TestPackageBuilder_OutsideModuleHint(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+
+	hint := moduleHint(sourceDir)
+	assert.Contains(t, hint, "not inside a Go module")
+}
+
+func TestPackageBuilder_KeepArtifacts(t *testing.T) {
+	artifactsDir := filepath.Join(t.TempDir(), "artifacts")
+
+	pb := NewPackageBuilder("../functions/oidc-provisioner", WithArtifactsDir(artifactsDir))
+	zipData, _, err := pb.Build()
+	require.NoError(t, err)
+
+	savedZip, err := os.ReadFile(filepath.Join(artifactsDir, "bootstrap.zip"))
+	require.NoError(t, err)
+	assert.Equal(t, zipData, savedZip)
+
+	_, err = os.Stat(filepath.Join(artifactsDir, "bootstrap"))
+	assert.NoError(t, err)
+
+	log, err := os.ReadFile(filepath.Join(artifactsDir, "build.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "command: go build")
+	assert.Contains(t, string(log), "GOOS=linux")
+	assert.Contains(t, string(log), "result: success")
+}
+
+func TestPackageBuilder_KeepArtifactsOnFailure(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "go.mod"), []byte("module broken\n\ngo 1.23\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\nfunc main() { undefined() }\n"), 0644))
+
+	artifactsDir := filepath.Join(t.TempDir(), "artifacts")
+
+	pb := NewPackageBuilder(sourceDir, WithArtifactsDir(artifactsDir))
+	_, _, err := pb.Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "build artifacts saved to "+artifactsDir)
+
+	log, err := os.ReadFile(filepath.Join(artifactsDir, "build.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "undefined: undefined")
+	assert.Contains(t, string(log), "result: failed to compile binary")
+
+	_, err = os.Stat(filepath.Join(artifactsDir, "bootstrap.zip"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestPackageBuilder_IgnoresWorkspaceAndGOFLAGS(t *testing.T) {
+	// A CI checkout whose go.work names a missing module and whose environment forces
+	// vendoring, with the function in a subdirectory of its module
+	root := t.TempDir()
+	moduleDir := filepath.Join(root, "module")
+	sourceDir := filepath.Join(moduleDir, "cmd", "function")
+	require.NoError(t, os.MkdirAll(sourceDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.work"), []byte("go 1.23\n\nuse ./missing\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(moduleDir, "go.mod"), []byte("module example.com/function\n\ngo 1.23\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	t.Setenv("GOFLAGS", "-mod=vendor")
+	t.Setenv("GOWORK", filepath.Join(root, "go.work"))
+
+	artifactsDir := filepath.Join(t.TempDir(), "artifacts")
+	_, _, err := NewPackageBuilder(sourceDir, WithArtifactsDir(artifactsDir)).Build()
+	require.NoError(t, err)
+
+	log, err := os.ReadFile(filepath.Join(artifactsDir, "build.log"))
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "dir: "+moduleDir)
+	assert.Contains(t, string(log), "-mod=readonly")
+	assert.Contains(t, string(log), "./cmd/function")
+	assert.Contains(t, string(log), "GOWORK=off", "the workspace is disabled")
+}
+
+func TestModuleMode(t *testing.T) {
+	root := t.TempDir()
+	assert.Equal(t, "readonly", moduleMode(root))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "vendor"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "vendor", "modules.txt"), nil, 0644))
+	assert.Equal(t, "vendor", moduleMode(root))
+}
+
+func TestPackageBuilder_GoEnvDiagnostics(t *testing.T) {
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "go.mod"), []byte("module broken\n\ngo 1.23\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\nfunc main() { undefined() }\n"), 0644))
+
+	_, _, err := NewPackageBuilder(sourceDir).Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "go env: GOVERSION=go")
+	assert.Contains(t, err.Error(), "GOMOD="+filepath.Join(sourceDir, "go.mod"))
+	assert.Contains(t, err.Error(), "GOWORK=off", "the workspace is disabled")
+}
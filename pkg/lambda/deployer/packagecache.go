@@ -0,0 +1,187 @@
+package deployer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// packageCacheFormat is mixed into every source key, so keys change whenever the way
+// packages are built does
+const packageCacheFormat = "rosactl-package-v1"
+
+// checksumPattern matches the hex-encoded SHA256 package checksums the cache is keyed by
+var checksumPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// PackageCache keeps built deployment packages on disk, so deploying the same source
+// to several regions or accounts, or deploying again later, compiles it only once.
+// Packages are stored by checksum as <checksum>.zip; the source they were built from
+// is indexed by a key covering the Go toolchain, target architecture, and the
+// contents of every non-standard-library file compiled into the binary.
+type PackageCache struct {
+	dir string
+}
+
+// NewPackageCache creates a package cache rooted at dir, such as ~/.rosactl/cache/packages
+func NewPackageCache(dir string) *PackageCache {
+	return &PackageCache{dir: dir}
+}
+
+// Dir returns the directory the cache is rooted at
+func (c *PackageCache) Dir() string {
+	return c.dir
+}
+
+// Lookup returns the package last stored for key and its checksum. A missing or
+// corrupt package is a miss.
+func (c *PackageCache) Lookup(key string) ([]byte, string, bool) {
+	index, err := os.ReadFile(c.indexPath(key))
+	if err != nil {
+		return nil, "", false
+	}
+	checksum := strings.TrimSpace(string(index))
+	if !checksumPattern.MatchString(checksum) {
+		return nil, "", false
+	}
+
+	zipData, err := os.ReadFile(c.packagePath(checksum))
+	if err != nil || fmt.Sprintf("%x", sha256.Sum256(zipData)) != checksum {
+		return nil, "", false
+	}
+	return zipData, checksum, true
+}
+
+// Store saves a package with its checksum and indexes it under key
+func (c *PackageCache) Store(key string, zipData []byte, checksum string) error {
+	if !checksumPattern.MatchString(checksum) {
+		return fmt.Errorf("invalid package checksum %q", checksum)
+	}
+	if err := writeFileAtomic(c.packagePath(checksum), zipData); err != nil {
+		return fmt.Errorf("failed to cache package: %w", err)
+	}
+	if err := writeFileAtomic(c.indexPath(key), []byte(checksum+"\n")); err != nil {
+		return fmt.Errorf("failed to index cached package: %w", err)
+	}
+	return nil
+}
+
+func (c *PackageCache) packagePath(checksum string) string {
+	return filepath.Join(c.dir, checksum+".zip")
+}
+
+func (c *PackageCache) indexPath(key string) string {
+	return filepath.Join(c.dir, "index", key)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it into
+// place, so concurrent deploys never read a partial file
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// sourceListFormat is the go list template describing each package compiled into the
+// binary: standard library packages are covered by the toolchain version, other
+// packages by their directory and files
+const sourceListFormat = `{{if not .Standard}}{{.Dir}}{{range .GoFiles}}	{{.}}{{end}}{{range .EmbedFiles}}	{{.}}{{end}}{{end}}`
+
+// SourceKey returns the cache key of the package sourceDir builds for goarch. It hashes
+// the Go toolchain version, goarch, and every non-standard-library source and embedded
+// file of the binary's packages, as go list reports them for the Lambda target.
+func SourceKey(ctx context.Context, sourceDir, goarch string) (string, error) {
+	sourceDir, err := filepath.Abs(sourceDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid source directory %s: %w", sourceDir, err)
+	}
+	dir, pkg, modeArgs := buildTarget(sourceDir)
+	env := buildEnv(os.Environ(), runtime.GOOS, goarch)
+
+	goVersion, err := runGo(ctx, dir, env, "env", "GOVERSION")
+	if err != nil {
+		return "", err
+	}
+	listing, err := runGo(ctx, dir, env, append(append([]string{"list", "-deps"}, modeArgs...), "-f", sourceListFormat, pkg)...)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\ngoarch=%s\n%s\n", packageCacheFormat, goarch, strings.TrimSpace(string(goVersion)))
+	scanner := bufio.NewScanner(bytes.NewReader(listing))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		for _, name := range fields[1:] {
+			if err := hashFile(hash, filepath.Join(fields[0], name)); err != nil {
+				return "", err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read go list output: %w", err)
+	}
+	if root := moduleRoot(sourceDir); root != "" {
+		for _, name := range []string{"go.mod", "go.sum"} {
+			if err := hashFile(hash, filepath.Join(root, name)); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return "", err
+			}
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// hashFile adds a file's path and contents to hash
+func hashFile(hash io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to hash source file: %w", err)
+	}
+	defer f.Close()
+	fmt.Fprintf(hash, "file %s\n", filepath.ToSlash(path))
+	if _, err := io.Copy(hash, f); err != nil {
+		return fmt.Errorf("failed to hash source file %s: %w", path, err)
+	}
+	return nil
+}
+
+// runGo runs a go command in dir and returns its standard output
+func runGo(ctx context.Context, dir string, env []string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go %s failed: %w, stderr: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
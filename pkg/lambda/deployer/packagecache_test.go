@@ -0,0 +1,91 @@
+package deployer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestModule writes a module with one main package and returns its directory
+func writeTestModule(t *testing.T) string {
+	t.Helper()
+	sourceDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "go.mod"), []byte("module example.com/function\n\ngo 1.23\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	return sourceDir
+}
+
+func TestPackageCache(t *testing.T) {
+	cache := NewPackageCache(t.TempDir())
+	zipData := []byte("package")
+	checksum := fmt.Sprintf("%x", sha256.Sum256(zipData))
+
+	_, _, ok := cache.Lookup("key")
+	assert.False(t, ok)
+
+	require.NoError(t, cache.Store("key", zipData, checksum))
+	cached, cachedChecksum, ok := cache.Lookup("key")
+	require.True(t, ok)
+	assert.Equal(t, zipData, cached)
+	assert.Equal(t, checksum, cachedChecksum)
+
+	// A package that no longer matches its checksum is a miss
+	require.NoError(t, os.WriteFile(filepath.Join(cache.Dir(), checksum+".zip"), []byte("tampered"), 0600))
+	_, _, ok = cache.Lookup("key")
+	assert.False(t, ok)
+
+	assert.ErrorContains(t, cache.Store("key", zipData, "../escape"), "invalid package checksum")
+}
+
+func TestSourceKey(t *testing.T) {
+	ctx := context.Background()
+	sourceDir := writeTestModule(t)
+
+	key, err := SourceKey(ctx, sourceDir, "amd64")
+	require.NoError(t, err)
+	again, err := SourceKey(ctx, sourceDir, "amd64")
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	arm, err := SourceKey(ctx, sourceDir, "arm64")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, arm, "the architecture is part of the key")
+
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\nfunc main() { println() }\n"), 0644))
+	changed, err := SourceKey(ctx, sourceDir, "amd64")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, changed, "source changes are part of the key")
+}
+
+func TestPackageBuilder_PackageCache(t *testing.T) {
+	sourceDir := writeTestModule(t)
+	cache := NewPackageCache(filepath.Join(t.TempDir(), "packages"))
+
+	pb := NewPackageBuilder(sourceDir, WithPackageCache(cache))
+	zipData, checksum, err := pb.Build()
+	require.NoError(t, err)
+	assert.False(t, pb.Cached())
+
+	// Another deployment of the same source reuses the package, checksum included
+	second := NewPackageBuilder(sourceDir, WithPackageCache(cache))
+	cachedZip, cachedChecksum, err := second.Build()
+	require.NoError(t, err)
+	assert.True(t, second.Cached())
+	assert.Equal(t, zipData, cachedZip)
+	assert.Equal(t, checksum, cachedChecksum)
+
+	_, err = os.Stat(filepath.Join(cache.Dir(), checksum+".zip"))
+	assert.NoError(t, err)
+
+	// Keeping artifacts always compiles
+	withArtifacts := NewPackageBuilder(sourceDir, WithPackageCache(cache), WithArtifactsDir(t.TempDir()))
+	_, _, err = withArtifacts.Build()
+	require.NoError(t, err)
+	assert.False(t, withArtifacts.Cached())
+}
@@ -43,33 +43,174 @@ func GenerateLambdaExecutionRoleTrustPolicy() (string, error) {
 	return string(policyJSON), nil
 }
 
+// ARNScope identifies the partition, region, and account resources are deployed into.
+// Empty fields render as wildcards.
+type ARNScope struct {
+	Partition string
+	Region    string
+	AccountID string
+}
+
+// IsScoped reports whether the account ID is known
+func (s ARNScope) IsScoped() bool {
+	return s.AccountID != ""
+}
+
+// LogGroupARN returns the ARN of the named log group, including its streams
+func (s ARNScope) LogGroupARN(logGroupName string) string {
+	return fmt.Sprintf("arn:%s:logs:%s:%s:log-group:%s:*", s.partition(), wildcard(s.Region), wildcard(s.AccountID), logGroupName)
+}
+
+// FunctionARN returns the ARN of the named Lambda function
+func (s ARNScope) FunctionARN(functionName string) string {
+	return fmt.Sprintf("arn:%s:lambda:%s:%s:function:%s", s.partition(), wildcard(s.Region), wildcard(s.AccountID), functionName)
+}
+
+// OIDCProviderARN returns an ARN pattern matching every OIDC provider in the account
+func (s ARNScope) OIDCProviderARN() string {
+	return fmt.Sprintf("arn:%s:iam::%s:oidc-provider/*", s.partition(), wildcard(s.AccountID))
+}
+
+// RoleARN returns an ARN pattern matching every role under the IAM path, such as
+// /rosa-operators/
+func (s ARNScope) RoleARN(path string) string {
+	return fmt.Sprintf("arn:%s:iam::%s:role%s*", s.partition(), wildcard(s.AccountID), path)
+}
+
+// DynamoDBTableARN returns the ARN of the named DynamoDB table
+func (s ARNScope) DynamoDBTableARN(tableName string) string {
+	return fmt.Sprintf("arn:%s:dynamodb:%s:%s:table/%s", s.partition(), wildcard(s.Region), wildcard(s.AccountID), tableName)
+}
+
+func (s ARNScope) partition() string {
+	if s.Partition == "" {
+		return "aws"
+	}
+	return s.Partition
+}
+
+func wildcard(value string) string {
+	if value == "" {
+		return "*"
+	}
+	return value
+}
+
 // GenerateOIDCProvisionerPermissionsPolicy generates the permissions policy for OIDC provisioner Lambda
 func GenerateOIDCProvisionerPermissionsPolicy() (string, error) {
-	policy := PolicyDocument{
-		Version: "2012-10-17",
-		Statement: []Statement{
-			{
-				Effect: "Allow",
-				Action: []string{
-					"iam:CreateOpenIDConnectProvider",
-					"iam:GetOpenIDConnectProvider",
-					"iam:ListOpenIDConnectProviders",
-					"iam:TagOpenIDConnectProvider",
+	return GenerateScopedOIDCProvisionerPermissionsPolicy(ARNScope{}, "")
+}
+
+// GenerateScopedOIDCProvisionerPermissionsPolicy generates the permissions policy for the OIDC provisioner
+// Lambda with OIDC provider and log group resources restricted to the given scope. Without an account ID
+// or function name it falls back to the wildcard policy.
+func GenerateScopedOIDCProvisionerPermissionsPolicy(scope ARNScope, functionName string) (string, error) {
+	logGroupName := ""
+	if functionName != "" {
+		logGroupName = defaultLogGroupName(functionName)
+	}
+	return GenerateScopedOIDCProvisionerPermissionsPolicyForLogGroup(scope, logGroupName)
+}
+
+// GenerateScopedOIDCProvisionerPermissionsPolicyForLogGroup is like
+// GenerateScopedOIDCProvisionerPermissionsPolicy for a function writing to the named
+// log group rather than Lambda's default one
+func GenerateScopedOIDCProvisionerPermissionsPolicyForLogGroup(scope ARNScope, logGroupName string) (string, error) {
+	return marshalPermissionsPolicy(oidcProvisionerPermissions(scope, logGroupName))
+}
+
+// tracingStatement grants the X-Ray writes Lambda makes for a function with active tracing
+var tracingStatement = Statement{
+	Effect:   "Allow",
+	Action:   []string{"xray:PutTraceSegments", "xray:PutTelemetryRecords"},
+	Resource: "*",
+}
+
+// bindRolesStatement grants the role reads and trust policy updates a bind-roles
+// request makes, limited to roles under the bindable path
+func bindRolesStatement(scope ARNScope, path string) Statement {
+	return Statement{
+		Effect:   "Allow",
+		Action:   []string{"iam:GetRole", "iam:UpdateAssumeRolePolicy"},
+		Resource: scope.RoleARN(path),
+	}
+}
+
+// ledgerStatement grants the conditional writes the provisioner records its requests
+// with, limited to the ledger table
+func ledgerStatement(scope ARNScope, tableName string) Statement {
+	return Statement{
+		Effect:   "Allow",
+		Action:   "dynamodb:PutItem",
+		Resource: scope.DynamoDBTableARN(tableName),
+	}
+}
+
+// oidcProvisionerPermissions returns the OIDC provisioner's permissions policy, scoped
+// when the account ID and log group are known
+func oidcProvisionerPermissions(scope ARNScope, logGroupName string) PolicyDocument {
+	var policy PolicyDocument
+
+	if !scope.IsScoped() || logGroupName == "" {
+		policy = PolicyDocument{
+			Version: "2012-10-17",
+			Statement: []Statement{
+				{
+					Effect: "Allow",
+					Action: []string{
+						"iam:CreateOpenIDConnectProvider",
+						"iam:GetOpenIDConnectProvider",
+						"iam:ListOpenIDConnectProviders",
+						"iam:TagOpenIDConnectProvider",
+					},
+					Resource: "*",
+				},
+				{
+					Effect: "Allow",
+					Action: []string{
+						"logs:CreateLogGroup",
+						"logs:CreateLogStream",
+						"logs:PutLogEvents",
+					},
+					Resource: "arn:aws:logs:*:*:*",
 				},
-				Resource: "*",
 			},
-			{
-				Effect: "Allow",
-				Action: []string{
-					"logs:CreateLogGroup",
-					"logs:CreateLogStream",
-					"logs:PutLogEvents",
+		}
+	} else {
+		policy = PolicyDocument{
+			Version: "2012-10-17",
+			Statement: []Statement{
+				{
+					Effect: "Allow",
+					Action: []string{
+						"iam:CreateOpenIDConnectProvider",
+						"iam:GetOpenIDConnectProvider",
+						"iam:TagOpenIDConnectProvider",
+					},
+					Resource: scope.OIDCProviderARN(),
+				},
+				{
+					Effect: "Allow",
+					Action: []string{
+						"logs:CreateLogGroup",
+						"logs:CreateLogStream",
+						"logs:PutLogEvents",
+					},
+					Resource: scope.LogGroupARN(logGroupName),
+				},
+				{
+					// ListOpenIDConnectProviders does not support resource-level permissions
+					Effect:   "Allow",
+					Action:   "iam:ListOpenIDConnectProviders",
+					Resource: "*",
 				},
-				Resource: "arn:aws:logs:*:*:*",
 			},
-		},
+		}
 	}
+	return policy
+}
 
+func marshalPermissionsPolicy(policy PolicyDocument) (string, error) {
 	policyJSON, err := json.Marshal(policy)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal permissions policy: %w", err)
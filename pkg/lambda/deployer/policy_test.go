@@ -140,6 +140,82 @@ func TestGenerateLambdaResourcePolicy(t *testing.T) {
 	}
 }
 
+func TestGenerateScopedOIDCProvisionerPermissionsPolicy(t *testing.T) {
+	scope := ARNScope{Partition: "aws-us-gov", Region: "us-gov-west-1", AccountID: "123456789012"}
+
+	policyStr, err := GenerateScopedOIDCProvisionerPermissionsPolicy(scope, "rosa-oidc-provisioner")
+	require.NoError(t, err)
+
+	var policy PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(policyStr), &policy))
+	require.Len(t, policy.Statement, 3)
+
+	assert.Equal(t, "arn:aws-us-gov:iam::123456789012:oidc-provider/*", policy.Statement[0].Resource)
+	assert.NotContains(t, toString(policy.Statement[0].Action.([]interface{})), "iam:ListOpenIDConnectProviders")
+	assert.Equal(t, "arn:aws-us-gov:logs:us-gov-west-1:123456789012:log-group:/aws/lambda/rosa-oidc-provisioner:*",
+		policy.Statement[1].Resource)
+	assert.Equal(t, "iam:ListOpenIDConnectProviders", policy.Statement[2].Action)
+	assert.Equal(t, "*", policy.Statement[2].Resource)
+}
+
+func TestGenerateScopedOIDCProvisionerPermissionsPolicyForLogGroup(t *testing.T) {
+	scope := ARNScope{Region: "us-east-1", AccountID: "123456789012"}
+
+	policyStr, err := GenerateScopedOIDCProvisionerPermissionsPolicyForLogGroup(scope, "/org/rosa/oidc-provisioner")
+	require.NoError(t, err)
+
+	var policy PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(policyStr), &policy))
+	require.Len(t, policy.Statement, 3)
+	assert.Equal(t, "arn:aws:logs:us-east-1:123456789012:log-group:/org/rosa/oidc-provisioner:*", policy.Statement[1].Resource)
+}
+
+func TestPermissionsPolicy_Tracing(t *testing.T) {
+	d := NewDeployer(nil, nil, nil, DeploymentConfig{FunctionName: "rosa-oidc-provisioner", Tracing: true})
+
+	policyStr, err := d.permissionsPolicy()
+	require.NoError(t, err)
+
+	var policy PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(policyStr), &policy))
+	require.Len(t, policy.Statement, 3)
+	assert.Equal(t, []interface{}{"xray:PutTraceSegments", "xray:PutTelemetryRecords"}, policy.Statement[2].Action)
+}
+
+func TestPermissionsPolicy_BindableRolePath(t *testing.T) {
+	d := NewDeployer(nil, nil, nil, DeploymentConfig{FunctionName: "rosa-oidc-provisioner", BindableRolePath: "/rosa-operators/"})
+	d.scope = ARNScope{AccountID: "123456789012"}
+
+	policyStr, err := d.permissionsPolicy()
+	require.NoError(t, err)
+
+	var policy PolicyDocument
+	require.NoError(t, json.Unmarshal([]byte(policyStr), &policy))
+	require.Len(t, policy.Statement, 4)
+	assert.Equal(t, []interface{}{"iam:GetRole", "iam:UpdateAssumeRolePolicy"}, policy.Statement[3].Action)
+	assert.Equal(t, "arn:aws:iam::123456789012:role/rosa-operators/*", policy.Statement[3].Resource)
+}
+
+func TestGenerateScopedOIDCProvisionerPermissionsPolicy_Unscoped(t *testing.T) {
+	scoped, err := GenerateScopedOIDCProvisionerPermissionsPolicy(ARNScope{Region: "us-east-1"}, "rosa-oidc-provisioner")
+	require.NoError(t, err)
+
+	wildcard, err := GenerateOIDCProvisionerPermissionsPolicy()
+	require.NoError(t, err)
+
+	assert.Equal(t, wildcard, scoped)
+}
+
+func TestARNScope(t *testing.T) {
+	scope := ARNScope{AccountID: "123456789012"}
+
+	assert.Equal(t, "arn:aws:lambda:*:123456789012:function:test", scope.FunctionARN("test"))
+	assert.Equal(t, "arn:aws:logs:*:123456789012:log-group:/aws/lambda/test:*", scope.LogGroupARN("/aws/lambda/test"))
+
+	scope.Region = "eu-west-1"
+	assert.Equal(t, "arn:aws:lambda:eu-west-1:123456789012:function:test", scope.FunctionARN("test"))
+}
+
 func TestPolicyJSONFormat(t *testing.T) {
 	// Verify all policies produce valid, well-formed JSON
 	policies := []struct {
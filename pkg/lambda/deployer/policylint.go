@@ -0,0 +1,249 @@
+package deployer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Severities of a LintFinding
+const (
+	LintError   = "error"   // AWS would reject the policy, or it lets anyone assume the role
+	LintWarning = "warning" // The policy works but is broader than it needs to be
+)
+
+// LintFinding is one problem found in a policy. Statement is the 1-based index of the
+// statement it concerns, or 0 for the document as a whole.
+type LintFinding struct {
+	Severity  string `json:"severity"`
+	Code      string `json:"code"`
+	Statement int    `json:"statement,omitempty"`
+	Message   string `json:"message"`
+}
+
+func (f LintFinding) String() string {
+	if f.Statement == 0 {
+		return fmt.Sprintf("%s [%s] %s", f.Severity, f.Code, f.Message)
+	}
+	return fmt.Sprintf("%s [%s] statement %d: %s", f.Severity, f.Code, f.Statement, f.Message)
+}
+
+// PolicyLintError is returned when a policy has errors, or warnings in strict mode
+type PolicyLintError struct {
+	Findings []LintFinding
+}
+
+func (e *PolicyLintError) Error() string {
+	messages := make([]string, 0, len(e.Findings))
+	for _, finding := range e.Findings {
+		messages = append(messages, finding.String())
+	}
+	return fmt.Sprintf("trust policy failed linting: %s", strings.Join(messages, "; "))
+}
+
+// CheckLintFindings returns a *PolicyLintError listing the findings that fail the
+// policy: errors, and warnings too when strict is set
+func CheckLintFindings(findings []LintFinding, strict bool) error {
+	var failed []LintFinding
+	for _, finding := range findings {
+		if finding.Severity == LintError || strict {
+			failed = append(failed, finding)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &PolicyLintError{Findings: failed}
+}
+
+// validPolicyVersions are the policy language versions IAM accepts
+var validPolicyVersions = []string{"2012-10-17", "2008-10-17"}
+
+// confusedDeputyKeys are the condition keys that bind a service principal to the
+// account or resource acting through it
+var confusedDeputyKeys = []string{"aws:sourceaccount", "aws:sourcearn", "aws:sourceorgid", "aws:sourceorgpaths"}
+
+// crossAccountKeys are the condition keys that bind an AWS principal to a known caller
+var crossAccountKeys = []string{"sts:externalid", "aws:principalorgid", "aws:principalorgpaths", "aws:principalarn"}
+
+type lintStatement struct {
+	Effect       string                                `json:"Effect"`
+	Principal    json.RawMessage                       `json:"Principal"`
+	NotPrincipal json.RawMessage                       `json:"NotPrincipal"`
+	Action       json.RawMessage                       `json:"Action"`
+	NotAction    json.RawMessage                       `json:"NotAction"`
+	Condition    map[string]map[string]json.RawMessage `json:"Condition"`
+}
+
+// LintTrustPolicy checks a customer-provided execution role trust policy for syntax
+// errors, a missing lambda.amazonaws.com principal, overly broad wildcards, and
+// statements missing the conditions that prevent confused-deputy use of the role.
+// Findings are returned in document order; a nil result means the policy is clean.
+func LintTrustPolicy(document string) []LintFinding {
+	var findings []LintFinding
+	add := func(severity, code string, statement int, format string, args ...interface{}) {
+		findings = append(findings, LintFinding{
+			Severity:  severity,
+			Code:      code,
+			Statement: statement,
+			Message:   fmt.Sprintf(format, args...),
+		})
+	}
+
+	var doc trustPolicyDocument
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		add(LintError, "invalid-json", 0, "policy is not valid JSON: %v", err)
+		return findings
+	}
+
+	switch {
+	case doc.Version == "":
+		add(LintError, "missing-version", 0, "Version is required; use %q", validPolicyVersions[0])
+	case !containsAny([]string{doc.Version}, validPolicyVersions...):
+		add(LintError, "invalid-version", 0, "Version %q is not a policy language version; use %q", doc.Version, validPolicyVersions[0])
+	case doc.Version != validPolicyVersions[0]:
+		add(LintWarning, "old-version", 0, "Version %q does not support policy variables; use %q", doc.Version, validPolicyVersions[0])
+	}
+
+	var statements []lintStatement
+	if err := json.Unmarshal(doc.Statement, &statements); err != nil {
+		var single lintStatement
+		if err := json.Unmarshal(doc.Statement, &single); err != nil {
+			add(LintError, "invalid-statement", 0, "Statement must be an object or a list of objects")
+			return findings
+		}
+		statements = []lintStatement{single}
+	}
+	if len(statements) == 0 {
+		add(LintError, "invalid-statement", 0, "Statement must contain at least one statement")
+		return findings
+	}
+
+	allowsLambda := false
+	for i, statement := range statements {
+		n := i + 1
+
+		if statement.Effect != "Allow" && statement.Effect != "Deny" {
+			add(LintError, "invalid-effect", n, "Effect must be Allow or Deny, not %q", statement.Effect)
+			continue
+		}
+
+		actions, err := policyStrings(statement.Action)
+		if err != nil {
+			add(LintError, "invalid-action", n, "Action must be a string or a list of strings")
+			continue
+		}
+		if len(actions) == 0 && len(statement.NotAction) == 0 {
+			add(LintError, "missing-action", n, "Action is required")
+		}
+
+		principal, err := parsePrincipal(statement.Principal)
+		if err != nil {
+			add(LintError, "invalid-principal", n, "%v", err)
+			continue
+		}
+		if principal == nil && len(statement.NotPrincipal) == 0 {
+			add(LintError, "missing-principal", n, "trust policy statements require a Principal")
+			continue
+		}
+
+		if statement.Effect != "Allow" {
+			continue
+		}
+
+		if len(statement.NotPrincipal) > 0 {
+			add(LintWarning, "not-principal", n, "Allow with NotPrincipal trusts every principal except those listed; name the principals instead")
+		}
+		if len(statement.NotAction) > 0 {
+			add(LintWarning, "not-action", n, "Allow with NotAction grants every action except those listed; name the actions instead")
+		}
+		if containsAny(actions, "*", "sts:*") {
+			add(LintWarning, "wildcard-action", n, "Action %q also allows web identity, SAML, and session tagging; use \"sts:AssumeRole\"", wildcardAction(actions))
+		}
+
+		conditionKeys := conditionKeys(statement.Condition)
+		if principal["*"] != nil || containsAny(principal["AWS"], "*") {
+			if len(conditionKeys) == 0 {
+				add(LintError, "wildcard-principal", n, "Principal \"*\" lets any AWS account assume the role")
+			} else {
+				add(LintWarning, "wildcard-principal", n, "Principal \"*\" relies on conditions alone to restrict who can assume the role")
+			}
+		}
+
+		if services := principal["Service"]; len(services) > 0 {
+			if containsAny(services, lambdaServicePrincipal) && containsAny(actions, "sts:AssumeRole", "sts:*", "*") {
+				allowsLambda = true
+			}
+			if !containsAny(conditionKeys, confusedDeputyKeys...) {
+				add(LintWarning, "missing-confused-deputy-condition", n,
+					"service principal %s is not restricted with aws:SourceAccount or aws:SourceArn", strings.Join(services, ", "))
+			}
+		}
+
+		if accounts := principal["AWS"]; len(accounts) > 0 && !containsAny(accounts, "*") {
+			if !containsAny(conditionKeys, crossAccountKeys...) {
+				add(LintWarning, "missing-external-id", n,
+					"AWS principal %s is not restricted with sts:ExternalId or aws:PrincipalOrgID, which a principal in another account needs", strings.Join(accounts, ", "))
+			}
+		}
+	}
+
+	if !allowsLambda {
+		add(LintError, "missing-lambda-principal", 0, "no statement allows %s to sts:AssumeRole", lambdaServicePrincipal)
+	}
+
+	return findings
+}
+
+// parsePrincipal returns a statement's principals by type, such as AWS or Service. The
+// anonymous principal "*" is returned under the "*" key; a missing Principal is nil.
+func parsePrincipal(raw json.RawMessage) (map[string][]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var anonymous string
+	if err := json.Unmarshal(raw, &anonymous); err == nil {
+		if anonymous != "*" {
+			return nil, fmt.Errorf("Principal must be \"*\" or an object, not %q", anonymous)
+		}
+		return map[string][]string{"*": {"*"}}, nil
+	}
+
+	var byType map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &byType); err != nil {
+		return nil, fmt.Errorf("Principal must be \"*\" or an object")
+	}
+	principal := make(map[string][]string, len(byType))
+	for principalType, value := range byType {
+		var values []string
+		if err := json.Unmarshal(value, &values); err != nil {
+			var single string
+			if err := json.Unmarshal(value, &single); err != nil {
+				return nil, fmt.Errorf("Principal %s must be a string or a list of strings", principalType)
+			}
+			values = []string{single}
+		}
+		principal[principalType] = values
+	}
+	return principal, nil
+}
+
+// conditionKeys returns the lowercased condition keys of a statement, across operators
+func conditionKeys(condition map[string]map[string]json.RawMessage) []string {
+	var keys []string
+	for _, values := range condition {
+		for key := range values {
+			keys = append(keys, strings.ToLower(key))
+		}
+	}
+	return keys
+}
+
+// wildcardAction returns the broadest wildcard among actions
+func wildcardAction(actions []string) string {
+	if containsAny(actions, "*") {
+		return "*"
+	}
+	return "sts:*"
+}
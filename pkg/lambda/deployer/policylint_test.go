@@ -0,0 +1,141 @@
+package deployer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findingCodes returns the severity and code of each finding, for compact assertions
+func findingCodes(findings []LintFinding) []string {
+	var codes []string
+	for _, finding := range findings {
+		codes = append(codes, finding.Severity+" "+finding.Code)
+	}
+	return codes
+}
+
+func TestLintTrustPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		expected []string
+	}{
+		{
+			name:     "scoped lambda principal",
+			document: sourceArnTrustPolicy,
+		},
+		{
+			name:     "default policy lacks confused-deputy conditions",
+			document: `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}}`,
+			expected: []string{"warning missing-confused-deputy-condition"},
+		},
+		{
+			name:     "invalid JSON",
+			document: `{"Version":`,
+			expected: []string{"error invalid-json"},
+		},
+		{
+			name:     "missing version and statement",
+			document: `{}`,
+			expected: []string{"error missing-version", "error invalid-statement"},
+		},
+		{
+			name:     "old version",
+			document: `{"Version":"2008-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:SourceAccount":"123456789012"}}}]}`,
+			expected: []string{"warning old-version"},
+		},
+		{
+			name:     "unknown version and effect",
+			document: `{"Version":"2024-01-01","Statement":[{"Effect":"allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+			expected: []string{"error invalid-version", "error invalid-effect", "error missing-lambda-principal"},
+		},
+		{
+			name:     "missing principal",
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"sts:AssumeRole"}]}`,
+			expected: []string{"error missing-principal", "error missing-lambda-principal"},
+		},
+		{
+			name:     "wrong service principal",
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:SourceAccount":"123456789012"}}}]}`,
+			expected: []string{"error missing-lambda-principal"},
+		},
+		{
+			name:     "wildcard action",
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:*","Condition":{"StringEquals":{"AWS:SourceAccount":"123456789012"}}}]}`,
+			expected: []string{"warning wildcard-action"},
+		},
+		{
+			name: "anonymous principal",
+			document: `{"Version":"2012-10-17","Statement":[
+				{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:SourceAccount":"123456789012"}}},
+				{"Effect":"Allow","Principal":"*","Action":"sts:AssumeRole"},
+				{"Effect":"Allow","Principal":{"AWS":"*"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:PrincipalOrgID":"o-abc123"}}}
+			]}`,
+			expected: []string{"error wildcard-principal", "warning wildcard-principal"},
+		},
+		{
+			name: "cross-account principal",
+			document: `{"Version":"2012-10-17","Statement":[
+				{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole","Condition":{"ArnLike":{"aws:SourceArn":"arn:aws:lambda:*:123456789012:function:*"}}},
+				{"Effect":"Allow","Principal":{"AWS":["arn:aws:iam::210987654321:root"]},"Action":"sts:AssumeRole"},
+				{"Effect":"Allow","Principal":{"AWS":"arn:aws:iam::210987654321:root"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"sts:ExternalId":"rosa"}}}
+			]}`,
+			expected: []string{"warning missing-external-id"},
+		},
+		{
+			name: "not principal and not action",
+			document: `{"Version":"2012-10-17","Statement":[
+				{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:SourceAccount":"123456789012"}}},
+				{"Effect":"Allow","NotPrincipal":{"AWS":"arn:aws:iam::123456789012:root"},"NotAction":"sts:TagSession"}
+			]}`,
+			expected: []string{"warning not-principal", "warning not-action"},
+		},
+		{
+			name:     "deny statements are not linted for breadth",
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:SourceAccount":"123456789012"}}},{"Effect":"Deny","Principal":"*","Action":"*"}]}`,
+		},
+		{
+			name:     "malformed principal",
+			document: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"lambda.amazonaws.com","Action":"sts:AssumeRole"}]}`,
+			expected: []string{"error invalid-principal", "error missing-lambda-principal"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, findingCodes(LintTrustPolicy(tt.document)))
+		})
+	}
+}
+
+func TestLintTrustPolicy_StatementNumbers(t *testing.T) {
+	findings := LintTrustPolicy(`{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole","Condition":{"StringEquals":{"aws:SourceAccount":"123456789012"}}},
+		{"Effect":"Allow","Principal":"*","Action":"sts:AssumeRole"}
+	]}`)
+
+	require.Len(t, findings, 1)
+	assert.Equal(t, 2, findings[0].Statement)
+	assert.Equal(t, `error [wildcard-principal] statement 2: Principal "*" lets any AWS account assume the role`, findings[0].String())
+}
+
+func TestCheckLintFindings(t *testing.T) {
+	warning := LintFinding{Severity: LintWarning, Code: "wildcard-action", Statement: 1, Message: "too broad"}
+	failure := LintFinding{Severity: LintError, Code: "missing-version", Message: "Version is required"}
+
+	assert.NoError(t, CheckLintFindings(nil, true))
+	assert.NoError(t, CheckLintFindings([]LintFinding{warning}, false))
+
+	err := CheckLintFindings([]LintFinding{warning}, true)
+	var lintErr *PolicyLintError
+	require.True(t, errors.As(err, &lintErr))
+	assert.Equal(t, []LintFinding{warning}, lintErr.Findings)
+
+	err = CheckLintFindings([]LintFinding{warning, failure}, false)
+	require.True(t, errors.As(err, &lintErr))
+	assert.Equal(t, []LintFinding{failure}, lintErr.Findings)
+	assert.EqualError(t, err, "trust policy failed linting: error [missing-version] Version is required")
+}
@@ -0,0 +1,27 @@
+package deployer
+
+import (
+	"context"
+	"time"
+
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+)
+
+// primeTimeout bounds the priming invocation, which includes the function's cold start
+const primeTimeout = 30 * time.Second
+
+// prime invokes the deployed function once with a ping, so Lambda initializes an
+// execution environment before the first provisioning request arrives. The published
+// version is primed when there is one, since callers invoking it do not share
+// environments with $LATEST. It returns the invocation's round-trip latency.
+func (d *Deployer) prime(ctx context.Context, functionARN, version string) (time.Duration, error) {
+	target := functionARN
+	if version != "" {
+		target = functionARN + ":" + version
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, primeTimeout)
+	defer cancel()
+	_, latency, err := invoker.NewInvoker(d.lambdaClient, target).Ping(ctx)
+	return latency, err
+}
@@ -0,0 +1,46 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployer_Prime(t *testing.T) {
+	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
+
+	var invoked []string
+	mockLambda := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			invoked = append(invoked, aws.ToString(params.FunctionName))
+			assert.JSONEq(t, `{"action":"ping"}`, string(params.Payload))
+			return &lambda.InvokeOutput{Payload: []byte(`{"status":"healthy"}`)}, nil
+		},
+	}
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{})
+
+	_, err := deployer.prime(context.Background(), functionARN, "")
+	require.NoError(t, err)
+	_, err = deployer.prime(context.Background(), functionARN, "7")
+	require.NoError(t, err)
+
+	// A published version runs in its own execution environments, so it is primed instead of $LATEST
+	assert.Equal(t, []string{functionARN, functionARN + ":7"}, invoked)
+}
+
+func TestDeployer_PrimeError(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			return nil, errors.New("throttled")
+		},
+	}
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{})
+
+	_, err := deployer.prime(context.Background(), "arn:aws:lambda:us-east-1:123456789012:function:test-function", "")
+	assert.ErrorContains(t, err, "throttled")
+}
@@ -0,0 +1,159 @@
+package deployer
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	// ReleaseIndexFile is written under the release prefix after every package is uploaded
+	ReleaseIndexFile = "index.json"
+
+	// ReleaseChecksumMetadataKey is the S3 object metadata key holding a package's hex SHA256
+	ReleaseChecksumMetadataKey = "sha256"
+)
+
+// ErrReleaseExists is returned by PublishRelease when the prefix already holds a release
+var ErrReleaseExists = errors.New("release already published")
+
+// S3API defines the S3 operations needed to publish release packages
+type S3API interface {
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput,
+		optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput,
+		optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// PublishedArtifact is a release package uploaded to S3
+type PublishedArtifact struct {
+	ReleaseArtifact
+	Key string `json:"key"`
+}
+
+// ReleaseIndex describes a published release. Deployments that install a pinned
+// package read it to find the object for their architecture and its checksum.
+type ReleaseIndex struct {
+	CLIVersion  string              `json:"cli_version,omitempty"`
+	BuiltAt     time.Time           `json:"built_at"`
+	PublishedAt time.Time           `json:"published_at"`
+	Bucket      string              `json:"bucket"`
+	Prefix      string              `json:"prefix"`
+	Artifacts   []PublishedArtifact `json:"artifacts"`
+}
+
+// ReleaseKey returns the S3 key of a release file under prefix
+func ReleaseKey(prefix, file string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return file
+	}
+	return path.Join(prefix, file)
+}
+
+// PublishRelease uploads the packages built by BuildRelease in dir to bucket under
+// prefix, then SHA256SUMS and index.json. Each package's checksum is verified against
+// the manifest before anything is uploaded, and stored as object metadata and as the
+// S3 SHA256 checksum so S3 rejects a corrupted upload. An existing index.json means
+// the release was already published; it is only replaced when overwrite is set, so a
+// vetted release is not changed by accident.
+func PublishRelease(ctx context.Context, client S3API, dir, bucket, prefix string, overwrite bool) (*ReleaseIndex, error) {
+	manifest, err := ReadReleaseManifest(filepath.Join(dir, ReleaseManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Artifacts) == 0 {
+		return nil, fmt.Errorf("release manifest in %s lists no packages", dir)
+	}
+
+	packages := make([][]byte, len(manifest.Artifacts))
+	for i, artifact := range manifest.Artifacts {
+		data, err := os.ReadFile(filepath.Join(dir, artifact.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package: %w", err)
+		}
+		if checksum := fmt.Sprintf("%x", sha256.Sum256(data)); checksum != artifact.SHA256 {
+			return nil, fmt.Errorf("package %s has checksum %s, but the manifest records %s", artifact.File, checksum, artifact.SHA256)
+		}
+		packages[i] = data
+	}
+
+	indexKey := ReleaseKey(prefix, ReleaseIndexFile)
+	if !overwrite {
+		_, err := client.HeadObject(ctx, &s3.HeadObjectInput{Bucket: aws.String(bucket), Key: aws.String(indexKey)})
+		if err == nil {
+			return nil, fmt.Errorf("%w at s3://%s/%s", ErrReleaseExists, bucket, indexKey)
+		}
+		var notFoundErr *s3Types.NotFound
+		if !errors.As(err, &notFoundErr) {
+			return nil, fmt.Errorf("failed to check for an existing release: %w", err)
+		}
+	}
+
+	index := &ReleaseIndex{
+		CLIVersion:  manifest.CLIVersion,
+		BuiltAt:     manifest.BuiltAt,
+		PublishedAt: time.Now().UTC(),
+		Bucket:      bucket,
+		Prefix:      strings.Trim(prefix, "/"),
+	}
+	for i, artifact := range manifest.Artifacts {
+		key := ReleaseKey(prefix, artifact.File)
+		if err := putReleaseObject(ctx, client, bucket, key, packages[i], "application/zip", artifact.SHA256); err != nil {
+			return nil, err
+		}
+		index.Artifacts = append(index.Artifacts, PublishedArtifact{ReleaseArtifact: artifact, Key: key})
+	}
+
+	sums, err := os.ReadFile(filepath.Join(dir, ReleaseChecksumsFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ReleaseChecksumsFile, err)
+	}
+	if err := putReleaseObject(ctx, client, bucket, ReleaseKey(prefix, ReleaseChecksumsFile), sums, "text/plain", ""); err != nil {
+		return nil, err
+	}
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode release index: %w", err)
+	}
+	if err := putReleaseObject(ctx, client, bucket, indexKey, append(data, '\n'), "application/json", ""); err != nil {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+// putReleaseObject uploads one release file. Packages carry their hex checksum as
+// metadata; every object is sent with its SHA256 so S3 verifies the upload.
+func putReleaseObject(ctx context.Context, client S3API, bucket, key string, data []byte, contentType, checksum string) error {
+	sum := sha256.Sum256(data)
+	input := &s3.PutObjectInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		Body:              bytes.NewReader(data),
+		ContentType:       aws.String(contentType),
+		ChecksumAlgorithm: s3Types.ChecksumAlgorithmSha256,
+		ChecksumSHA256:    aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+	}
+	if checksum != "" {
+		input.Metadata = map[string]string{ReleaseChecksumMetadataKey: checksum}
+	}
+	if _, err := client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to upload s3://%s/%s: %w", bucket, key, err)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package deployer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockS3Client struct {
+	headObjectFunc func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+	putObjectFunc  func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+func (m *mockS3Client) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if m.headObjectFunc != nil {
+		return m.headObjectFunc(ctx, params, optFns...)
+	}
+	return nil, &s3Types.NotFound{}
+}
+
+func (m *mockS3Client) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if m.putObjectFunc != nil {
+		return m.putObjectFunc(ctx, params, optFns...)
+	}
+	return &s3.PutObjectOutput{}, nil
+}
+
+// writeRelease writes a fake release directory with one package per architecture
+func writeRelease(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	manifest := ReleaseManifest{CLIVersion: "1.2.3"}
+	var sums string
+	for _, arch := range ReleaseArchitectures {
+		data := []byte("package-" + string(arch))
+		file := ReleaseFileName(arch)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), data, 0644))
+		checksum := fmt.Sprintf("%x", sha256.Sum256(data))
+		manifest.Artifacts = append(manifest.Artifacts, ReleaseArtifact{
+			Architecture: arch, GOARCH: GOARCH(arch), File: file, SHA256: checksum, Size: int64(len(data)),
+		})
+		sums += fmt.Sprintf("%s  %s\n", checksum, file)
+	}
+	data, err := json.Marshal(manifest)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ReleaseManifestFile), data, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ReleaseChecksumsFile), []byte(sums), 0644))
+	return dir
+}
+
+func TestPublishRelease(t *testing.T) {
+	dir := writeRelease(t)
+
+	uploaded := map[string]*s3.PutObjectInput{}
+	bodies := map[string][]byte{}
+	var order []string
+	client := &mockS3Client{
+		putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			key := aws.ToString(params.Key)
+			body, err := io.ReadAll(params.Body)
+			require.NoError(t, err)
+			uploaded[key] = params
+			bodies[key] = body
+			order = append(order, key)
+			return &s3.PutObjectOutput{}, nil
+		},
+	}
+
+	index, err := PublishRelease(context.Background(), client, dir, "releases-bucket", "/releases/v1.2.3/", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"releases/v1.2.3/oidc-provisioner-x86_64.zip",
+		"releases/v1.2.3/oidc-provisioner-arm64.zip",
+		"releases/v1.2.3/SHA256SUMS",
+		"releases/v1.2.3/index.json",
+	}, order)
+
+	arm := uploaded["releases/v1.2.3/oidc-provisioner-arm64.zip"]
+	sum := sha256.Sum256([]byte("package-arm64"))
+	assert.Equal(t, fmt.Sprintf("%x", sum), arm.Metadata[ReleaseChecksumMetadataKey])
+	assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), aws.ToString(arm.ChecksumSHA256))
+	assert.Equal(t, "releases-bucket", aws.ToString(arm.Bucket))
+
+	assert.Equal(t, "releases/v1.2.3", index.Prefix)
+	require.Len(t, index.Artifacts, 2)
+	assert.Equal(t, lambdaTypes.ArchitectureArm64, index.Artifacts[1].Architecture)
+	assert.Equal(t, "releases/v1.2.3/oidc-provisioner-arm64.zip", index.Artifacts[1].Key)
+
+	var published map[string]interface{}
+	require.NoError(t, json.Unmarshal(bodies["releases/v1.2.3/index.json"], &published))
+	artifact := published["artifacts"].([]interface{})[1].(map[string]interface{})
+	assert.Equal(t, "arm64", artifact["architecture"])
+	assert.Equal(t, fmt.Sprintf("%x", sum), artifact["sha256"])
+	assert.Equal(t, "releases/v1.2.3/oidc-provisioner-arm64.zip", artifact["key"])
+	assert.Equal(t, "1.2.3", published["cli_version"])
+}
+
+func TestPublishRelease_AlreadyPublished(t *testing.T) {
+	dir := writeRelease(t)
+	uploads := 0
+	client := &mockS3Client{
+		headObjectFunc: func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+			assert.Equal(t, "v1/index.json", aws.ToString(params.Key))
+			return &s3.HeadObjectOutput{}, nil
+		},
+		putObjectFunc: func(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+			uploads++
+			return &s3.PutObjectOutput{}, nil
+		},
+	}
+
+	_, err := PublishRelease(context.Background(), client, dir, "bucket", "v1", false)
+	assert.ErrorIs(t, err, ErrReleaseExists)
+	assert.ErrorContains(t, err, "already published at s3://bucket/v1/index.json")
+	assert.Equal(t, 0, uploads)
+
+	_, err = PublishRelease(context.Background(), client, dir, "bucket", "v1", true)
+	require.NoError(t, err)
+	assert.Equal(t, 4, uploads)
+
+	client.headObjectFunc = func(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+		return nil, errors.New("AccessDenied")
+	}
+	_, err = PublishRelease(context.Background(), client, dir, "bucket", "v1", false)
+	assert.ErrorContains(t, err, "failed to check for an existing release: AccessDenied")
+}
+
+func TestPublishRelease_ChecksumMismatch(t *testing.T) {
+	dir := writeRelease(t)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ReleaseFileName(lambdaTypes.ArchitectureArm64)), []byte("tampered"), 0644))
+
+	_, err := PublishRelease(context.Background(), &mockS3Client{}, dir, "bucket", "v1", false)
+	assert.ErrorContains(t, err, "oidc-provisioner-arm64.zip has checksum")
+}
+
+func TestReleaseKey(t *testing.T) {
+	assert.Equal(t, "index.json", ReleaseKey("", "index.json"))
+	assert.Equal(t, "releases/v1/index.json", ReleaseKey("/releases/v1/", "index.json"))
+}
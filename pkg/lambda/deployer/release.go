@@ -0,0 +1,147 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const (
+	// ReleaseManifestFile lists the artifacts of a release build
+	ReleaseManifestFile = "manifest.json"
+
+	// ReleaseChecksumsFile holds the artifact checksums in sha256sum format
+	ReleaseChecksumsFile = "SHA256SUMS"
+)
+
+// ReleaseArchitectures are the Lambda architectures built by --all-arch
+var ReleaseArchitectures = []lambdaTypes.Architecture{
+	lambdaTypes.ArchitectureX8664,
+	lambdaTypes.ArchitectureArm64,
+}
+
+// ReleaseArtifact is one architecture's package in a release build
+type ReleaseArtifact struct {
+	Architecture lambdaTypes.Architecture `json:"architecture"`
+	GOARCH       string                   `json:"goarch"`
+	File         string                   `json:"file"`
+	SHA256       string                   `json:"sha256"`
+	Size         int64                    `json:"size"`
+}
+
+// ReleaseManifest describes the packages written by BuildRelease. Package sources
+// that install prebuilt packages select the artifact for the function's architecture
+// and verify its checksum before uploading it.
+type ReleaseManifest struct {
+	CLIVersion string            `json:"cli_version,omitempty"`
+	BuiltAt    time.Time         `json:"built_at"`
+	Artifacts  []ReleaseArtifact `json:"artifacts"`
+}
+
+// Artifact returns the artifact built for arch
+func (m *ReleaseManifest) Artifact(arch lambdaTypes.Architecture) (ReleaseArtifact, bool) {
+	for _, artifact := range m.Artifacts {
+		if artifact.Architecture == arch {
+			return artifact, true
+		}
+	}
+	return ReleaseArtifact{}, false
+}
+
+// ReleaseFileName returns the package file name for an architecture
+func ReleaseFileName(arch lambdaTypes.Architecture) string {
+	return fmt.Sprintf("oidc-provisioner-%s.zip", arch)
+}
+
+// BuildRelease builds the Lambda package in sourceDir for each architecture in
+// parallel and writes the packages, SHA256SUMS, and manifest.json to outDir. The
+// manifest is only written if every build succeeds.
+func BuildRelease(ctx context.Context, sourceDir, outDir string, archs []lambdaTypes.Architecture, cliVersion string) (*ReleaseManifest, error) {
+	if len(archs) == 0 {
+		return nil, errors.New("no architectures to build")
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	artifacts := make([]ReleaseArtifact, len(archs))
+	errs := make([]error, len(archs))
+	var wg sync.WaitGroup
+	for i, arch := range archs {
+		wg.Add(1)
+		go func(i int, arch lambdaTypes.Architecture) {
+			defer wg.Done()
+			artifacts[i], errs[i] = buildReleaseArtifact(ctx, sourceDir, outDir, arch)
+		}(i, arch)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	var sums strings.Builder
+	for _, artifact := range artifacts {
+		fmt.Fprintf(&sums, "%s  %s\n", artifact.SHA256, artifact.File)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, ReleaseChecksumsFile), []byte(sums.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ReleaseChecksumsFile, err)
+	}
+
+	manifest := &ReleaseManifest{
+		CLIVersion: cliVersion,
+		BuiltAt:    time.Now().UTC(),
+		Artifacts:  artifacts,
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode release manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outDir, ReleaseManifestFile), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", ReleaseManifestFile, err)
+	}
+
+	return manifest, nil
+}
+
+// buildReleaseArtifact builds and writes the package for one architecture
+func buildReleaseArtifact(ctx context.Context, sourceDir, outDir string, arch lambdaTypes.Architecture) (ReleaseArtifact, error) {
+	zipData, checksum, err := NewPackageBuilder(sourceDir, WithTargetArchitecture(arch)).BuildContext(ctx)
+	if err != nil {
+		return ReleaseArtifact{}, fmt.Errorf("failed to build %s package: %w", arch, err)
+	}
+
+	file := ReleaseFileName(arch)
+	if err := os.WriteFile(filepath.Join(outDir, file), zipData, 0644); err != nil {
+		return ReleaseArtifact{}, fmt.Errorf("failed to write %s: %w", file, err)
+	}
+
+	return ReleaseArtifact{
+		Architecture: arch,
+		GOARCH:       GOARCH(arch),
+		File:         file,
+		SHA256:       checksum,
+		Size:         int64(len(zipData)),
+	}, nil
+}
+
+// ReadReleaseManifest reads the manifest written by BuildRelease
+func ReadReleaseManifest(path string) (*ReleaseManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read release manifest: %w", err)
+	}
+	var manifest ReleaseManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse release manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
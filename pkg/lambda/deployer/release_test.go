@@ -0,0 +1,87 @@
+package deployer
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"debug/elf"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bootstrapMachine returns the ELF machine of the bootstrap binary in a package
+func bootstrapMachine(t *testing.T, zipData []byte) elf.Machine {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
+	require.NoError(t, err)
+	require.Len(t, reader.File, 1)
+
+	file, err := reader.File[0].Open()
+	require.NoError(t, err)
+	defer file.Close()
+	binary, err := io.ReadAll(file)
+	require.NoError(t, err)
+
+	parsed, err := elf.NewFile(bytes.NewReader(binary))
+	require.NoError(t, err)
+	return parsed.Machine
+}
+
+func TestBuildRelease(t *testing.T) {
+	outDir := filepath.Join(t.TempDir(), "dist")
+
+	manifest, err := BuildRelease(context.Background(), "../functions/oidc-provisioner", outDir, ReleaseArchitectures, "1.2.3")
+	require.NoError(t, err)
+	require.Len(t, manifest.Artifacts, 2)
+	assert.Equal(t, "1.2.3", manifest.CLIVersion)
+
+	machines := map[lambdaTypes.Architecture]elf.Machine{
+		lambdaTypes.ArchitectureX8664: elf.EM_X86_64,
+		lambdaTypes.ArchitectureArm64: elf.EM_AARCH64,
+	}
+	var sums string
+	for _, arch := range ReleaseArchitectures {
+		artifact, ok := manifest.Artifact(arch)
+		require.True(t, ok, arch)
+		assert.Equal(t, ReleaseFileName(arch), artifact.File)
+
+		zipData, err := os.ReadFile(filepath.Join(outDir, artifact.File))
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("%x", sha256.Sum256(zipData)), artifact.SHA256)
+		assert.Equal(t, int64(len(zipData)), artifact.Size)
+		assert.Equal(t, machines[arch], bootstrapMachine(t, zipData))
+
+		sums += fmt.Sprintf("%s  %s\n", artifact.SHA256, artifact.File)
+	}
+
+	checksums, err := os.ReadFile(filepath.Join(outDir, ReleaseChecksumsFile))
+	require.NoError(t, err)
+	assert.Equal(t, sums, string(checksums))
+
+	read, err := ReadReleaseManifest(filepath.Join(outDir, ReleaseManifestFile))
+	require.NoError(t, err)
+	assert.Equal(t, manifest.Artifacts, read.Artifacts)
+}
+
+func TestBuildRelease_Failure(t *testing.T) {
+	outDir := t.TempDir()
+
+	_, err := BuildRelease(context.Background(), filepath.Join(t.TempDir(), "missing"), outDir, ReleaseArchitectures, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to build x86_64 package")
+	assert.Contains(t, err.Error(), "failed to build arm64 package")
+
+	_, err = os.Stat(filepath.Join(outDir, ReleaseManifestFile))
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = BuildRelease(context.Background(), "../functions/oidc-provisioner", outDir, nil, "")
+	assert.ErrorContains(t, err, "no architectures")
+}
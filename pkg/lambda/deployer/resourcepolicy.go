@@ -0,0 +1,199 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+)
+
+// invokeAction is the action the CLM statement allows
+const invokeAction = "lambda:InvokeFunction"
+
+// qualifierPattern is the form Lambda requires of alias names and version numbers
+var qualifierPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,128}$`)
+
+// ValidateQualifier checks that a resource policy qualifier names an alias or a
+// published version. $LATEST is refused since it would allow invoking unpublished code.
+func ValidateQualifier(qualifier string) error {
+	if qualifier == "$LATEST" {
+		return errors.New("resource policy qualifier cannot be $LATEST; name an alias or a published version")
+	}
+	if !qualifierPattern.MatchString(qualifier) {
+		return fmt.Errorf("resource policy qualifier %q must be an alias name or version number of at most 128 letters, numbers, hyphens, and underscores", qualifier)
+	}
+	return nil
+}
+
+// permissionStatement is a function policy statement, reduced to the fields
+// AddPermission sets
+type permissionStatement struct {
+	Sid       string
+	Effect    string
+	Principal string
+	Action    string
+	SourceArn string
+}
+
+// rawPolicy is a function policy as returned by GetPolicy
+type rawPolicy struct {
+	Statement []struct {
+		Sid       string
+		Effect    string
+		Principal json.RawMessage
+		Action    json.RawMessage
+		Condition map[string]map[string]json.RawMessage
+	}
+}
+
+// parseFunctionPolicy returns the statements of a function policy document
+func parseFunctionPolicy(document string) ([]permissionStatement, error) {
+	var policy rawPolicy
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse function policy: %w", err)
+	}
+
+	statements := make([]permissionStatement, 0, len(policy.Statement))
+	for _, raw := range policy.Statement {
+		statement := permissionStatement{
+			Sid:       raw.Sid,
+			Effect:    raw.Effect,
+			Principal: principalValue(raw.Principal),
+			Action:    stringValue(raw.Action),
+		}
+		// Condition keys are case-insensitive; Lambda writes AWS:SourceArn
+		for _, values := range raw.Condition {
+			for key, value := range values {
+				if strings.EqualFold(key, "aws:SourceArn") {
+					statement.SourceArn = stringValue(value)
+				}
+			}
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// principalValue returns a principal given as "*" or as {"AWS": "..."} or {"Service": "..."}
+func principalValue(raw json.RawMessage) string {
+	var byType map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &byType); err != nil {
+		return stringValue(raw)
+	}
+	for _, value := range byType {
+		return stringValue(value)
+	}
+	return ""
+}
+
+// stringValue returns a JSON string, or the elements of a string array joined by commas
+func stringValue(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return strings.Join(list, ",")
+	}
+	return ""
+}
+
+// ConfigureResourcePolicy allows the CLM service role to invoke an existing function
+// without redeploying it, and reports whether the statement was created, updated, or
+// unchanged
+func (d *Deployer) ConfigureResourcePolicy(ctx context.Context) (string, error) {
+	if d.config.CLMServiceRoleARN == "" {
+		return "", errors.New("CLM service role ARN is required")
+	}
+	if d.config.ResourcePolicyQualifier != "" {
+		if err := ValidateQualifier(d.config.ResourcePolicyQualifier); err != nil {
+			return "", err
+		}
+	}
+	if err := d.resolveScope(ctx); err != nil {
+		return "", err
+	}
+	return d.addResourcePolicy(ctx)
+}
+
+// desiredPermission is the statement allowing CLM to invoke the function
+func (d *Deployer) desiredPermission() permissionStatement {
+	return permissionStatement{
+		Sid:       resourcePolicyStatementID,
+		Effect:    "Allow",
+		Principal: fmt.Sprintf("arn:%s:iam::%s:root", d.scope.partition(), d.sourceAccountID()),
+		Action:    invokeAction,
+		SourceArn: d.config.CLMServiceRoleARN,
+	}
+}
+
+// functionPolicy returns the statements of the function's policy, or of the alias or
+// version named by qualifier. A function without a policy has no statements.
+func (d *Deployer) functionPolicy(ctx context.Context, qualifier string) ([]permissionStatement, error) {
+	input := &lambda.GetPolicyInput{FunctionName: aws.String(d.config.FunctionName)}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+
+	output, err := d.lambdaClient.GetPolicy(ctx, input)
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get function policy: %w", err)
+	}
+	return parseFunctionPolicy(aws.ToString(output.Policy))
+}
+
+// findStatement returns the statement with sid, or nil
+func findStatement(statements []permissionStatement, sid string) *permissionStatement {
+	for i := range statements {
+		if statements[i].Sid == sid {
+			return &statements[i]
+		}
+	}
+	return nil
+}
+
+// diffPermission lists the fields in which current differs from desired
+func diffPermission(current, desired permissionStatement) []deploy.Change {
+	var changes []deploy.Change
+	field := func(name, current, desired string) {
+		if current != desired {
+			changes = append(changes, deploy.Change{Field: name, Current: current, Desired: desired})
+		}
+	}
+	field("effect", current.Effect, desired.Effect)
+	field("principal", current.Principal, desired.Principal)
+	field("action", current.Action, desired.Action)
+	field("source ARN", current.SourceArn, desired.SourceArn)
+	return changes
+}
+
+// removePermission removes the CLM statement from the function, or from the alias or
+// version named by qualifier. Removing a statement that does not exist is not an error.
+func (d *Deployer) removePermission(ctx context.Context, qualifier string) error {
+	input := &lambda.RemovePermissionInput{
+		FunctionName: aws.String(d.config.FunctionName),
+		StatementId:  aws.String(resourcePolicyStatementID),
+	}
+	if qualifier != "" {
+		input.Qualifier = aws.String(qualifier)
+	}
+
+	_, err := d.lambdaClient.RemovePermission(ctx, input)
+	var notFoundErr *lambdaTypes.ResourceNotFoundException
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,259 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCLMRole        = "arn:aws:iam::987654321098:role/clm"
+	testRetiredCLMRole = "arn:aws:iam::987654321098:role/clm-old"
+)
+
+// functionPolicyDocument returns a policy as GetPolicy reports it, with the CLM
+// statement naming clmRole and a statement rosactl did not add
+func functionPolicyDocument(clmRole string) string {
+	return fmt.Sprintf(`{
+  "Version": "2012-10-17",
+  "Id": "default",
+  "Statement": [
+    {
+      "Sid": "AllowCLMInvoke",
+      "Effect": "Allow",
+      "Principal": {"AWS": "arn:aws:iam::987654321098:root"},
+      "Action": "lambda:InvokeFunction",
+      "Resource": "arn:aws:lambda:us-east-1:987654321098:function:test-function",
+      "Condition": {"ArnLike": {"AWS:SourceArn": %q}}
+    },
+    {
+      "Sid": "AllowEvents",
+      "Effect": "Allow",
+      "Principal": {"Service": "events.amazonaws.com"},
+      "Action": "lambda:InvokeFunction",
+      "Resource": "arn:aws:lambda:us-east-1:987654321098:function:test-function"
+    }
+  ]
+}`, clmRole)
+}
+
+// policyClient returns a Lambda client whose function policy names clmRole and
+// that records permission changes
+func policyClient(clmRole string, calls *[]string) *mockLambdaClient {
+	return &mockLambdaClient{
+		getPolicyFunc: func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+			return &lambda.GetPolicyOutput{Policy: aws.String(functionPolicyDocument(clmRole))}, nil
+		},
+		removePermissionFunc: func(ctx context.Context, params *lambda.RemovePermissionInput, optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error) {
+			*calls = append(*calls, "remove "+aws.ToString(params.StatementId))
+			return &lambda.RemovePermissionOutput{}, nil
+		},
+		addPermissionFunc: func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+			*calls = append(*calls, "add "+aws.ToString(params.SourceArn))
+			return &lambda.AddPermissionOutput{}, nil
+		},
+	}
+}
+
+func policyTestConfig() DeploymentConfig {
+	return DeploymentConfig{
+		FunctionName:      "test-function",
+		CLMServiceRoleARN: testCLMRole,
+		SourceAccountID:   "987654321098",
+	}
+}
+
+func TestParseFunctionPolicy(t *testing.T) {
+	statements, err := parseFunctionPolicy(functionPolicyDocument(testCLMRole))
+	require.NoError(t, err)
+	assert.Equal(t, []permissionStatement{
+		{
+			Sid:       "AllowCLMInvoke",
+			Effect:    "Allow",
+			Principal: "arn:aws:iam::987654321098:root",
+			Action:    "lambda:InvokeFunction",
+			SourceArn: testCLMRole,
+		},
+		{
+			Sid:       "AllowEvents",
+			Effect:    "Allow",
+			Principal: "events.amazonaws.com",
+			Action:    "lambda:InvokeFunction",
+		},
+	}, statements)
+
+	_, err = parseFunctionPolicy("not json")
+	assert.Error(t, err)
+}
+
+func TestAddPermission_Unchanged(t *testing.T) {
+	var calls []string
+	d := NewDeployer(policyClient(testCLMRole, &calls), nil, nil, policyTestConfig())
+
+	action, err := d.addResourcePolicy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionUnchanged, action)
+	assert.Empty(t, calls)
+}
+
+func TestAddPermission_Missing(t *testing.T) {
+	var calls []string
+	client := policyClient(testCLMRole, &calls)
+	client.getPolicyFunc = nil
+	d := NewDeployer(client, nil, nil, policyTestConfig())
+
+	action, err := d.addResourcePolicy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionCreated, action)
+	assert.Equal(t, []string{"add " + testCLMRole}, calls)
+}
+
+func TestAddPermission_ReplacesRetiredRole(t *testing.T) {
+	var calls []string
+	d := NewDeployer(policyClient(testRetiredCLMRole, &calls), nil, nil, policyTestConfig())
+
+	action, err := d.addResourcePolicy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionUpdated, action)
+	assert.Equal(t, []string{"remove AllowCLMInvoke", "add " + testCLMRole}, calls)
+}
+
+func TestAddPermission_RemoveFails(t *testing.T) {
+	var calls []string
+	client := policyClient(testRetiredCLMRole, &calls)
+	client.removePermissionFunc = func(ctx context.Context, params *lambda.RemovePermissionInput, optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error) {
+		return nil, errors.New("access denied")
+	}
+	d := NewDeployer(client, nil, nil, policyTestConfig())
+
+	_, err := d.addResourcePolicy(context.Background())
+	assert.ErrorContains(t, err, "failed to remove outdated statement AllowCLMInvoke")
+	assert.Empty(t, calls, "the new statement is not added while the old one remains")
+}
+
+func TestConfigureResourcePolicy(t *testing.T) {
+	var calls []string
+	d := NewDeployer(policyClient(testRetiredCLMRole, &calls), nil, nil, policyTestConfig())
+
+	action, err := d.ConfigureResourcePolicy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionUpdated, action)
+	assert.Equal(t, []string{"remove AllowCLMInvoke", "add " + testCLMRole}, calls)
+
+	config := policyTestConfig()
+	config.CLMServiceRoleARN = ""
+	_, err = NewDeployer(policyClient(testCLMRole, &calls), nil, nil, config).ConfigureResourcePolicy(context.Background())
+	assert.ErrorContains(t, err, "CLM service role ARN is required")
+}
+
+func TestResourcePolicyDiff(t *testing.T) {
+	var calls []string
+	d := NewDeployer(policyClient(testRetiredCLMRole, &calls), nil, nil, policyTestConfig())
+
+	diff, err := (&resourcePolicyResource{d: d}).Diff(context.Background())
+	require.NoError(t, err)
+	assert.True(t, diff.Exists)
+	assert.True(t, diff.Managed)
+	assert.Equal(t, []deploy.Change{{Field: "source ARN", Current: testRetiredCLMRole, Desired: testCLMRole}}, diff.Changes)
+
+	// Without a CLM service role the statement is reported but not compared
+	config := policyTestConfig()
+	config.CLMServiceRoleARN = ""
+	diff, err = (&resourcePolicyResource{d: NewDeployer(d.lambdaClient, nil, nil, config)}).Diff(context.Background())
+	require.NoError(t, err)
+	assert.True(t, diff.InSync())
+}
+
+func TestTeardown_RemovesResourcePolicy(t *testing.T) {
+	var calls []string
+	client := policyClient(testRetiredCLMRole, &calls)
+	client.getFunctionFunc = func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+		return &lambda.GetFunctionOutput{Tags: map[string]string{ManagedTagKey: ManagedTagValue}}, nil
+	}
+	client.deleteFunctionFunc = func(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error) {
+		calls = append(calls, "delete function")
+		return &lambda.DeleteFunctionOutput{}, nil
+	}
+
+	// Teardown is not given the CLM service role
+	refs, err := NewDeployer(client, managedRoleClient(t), logGroupClient(90, map[string]string{ManagedTagKey: ManagedTagValue}),
+		resourcesTestConfig()).Teardown(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, refs, deploy.Ref{Type: ResourceTypeResourcePolicy, ID: resourcePolicyStatementID})
+	assert.Equal(t, []string{"remove AllowCLMInvoke", "delete function"}, calls)
+}
+
+func TestValidateQualifier(t *testing.T) {
+	assert.NoError(t, ValidateQualifier("live"))
+	assert.NoError(t, ValidateQualifier("7"))
+	assert.ErrorContains(t, ValidateQualifier("$LATEST"), "cannot be $LATEST")
+	assert.ErrorContains(t, ValidateQualifier("live:1"), "must be an alias name or version number")
+}
+
+// qualifiedPolicyClient returns a Lambda client whose unqualified function policy has the
+// CLM statement when unqualified is set, and whose live alias has none
+func qualifiedPolicyClient(unqualified bool, calls *[]string) *mockLambdaClient {
+	client := policyClient(testCLMRole, calls)
+	client.getPolicyFunc = func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+		if params.Qualifier != nil || !unqualified {
+			return nil, &lambdaTypes.ResourceNotFoundException{}
+		}
+		return &lambda.GetPolicyOutput{Policy: aws.String(functionPolicyDocument(testCLMRole))}, nil
+	}
+	client.removePermissionFunc = func(ctx context.Context, params *lambda.RemovePermissionInput, optFns ...func(*lambda.Options)) (*lambda.RemovePermissionOutput, error) {
+		*calls = append(*calls, "remove "+aws.ToString(params.StatementId)+" "+aws.ToString(params.Qualifier))
+		return &lambda.RemovePermissionOutput{}, nil
+	}
+	client.addPermissionFunc = func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+		*calls = append(*calls, "add "+aws.ToString(params.StatementId)+" "+aws.ToString(params.Qualifier))
+		return &lambda.AddPermissionOutput{}, nil
+	}
+	return client
+}
+
+func TestAddPermission_Qualified(t *testing.T) {
+	config := policyTestConfig()
+	config.ResourcePolicyQualifier = "live"
+
+	var calls []string
+	action, err := NewDeployer(qualifiedPolicyClient(true, &calls), nil, nil, config).addResourcePolicy(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, ResourceActionCreated, action)
+	assert.Equal(t, []string{"add AllowCLMInvoke live", "remove AllowCLMInvoke "}, calls,
+		"the unqualified statement is removed once the alias can be invoked")
+
+	calls = nil
+	client := qualifiedPolicyClient(true, &calls)
+	client.addPermissionFunc = func(ctx context.Context, params *lambda.AddPermissionInput, optFns ...func(*lambda.Options)) (*lambda.AddPermissionOutput, error) {
+		return nil, &lambdaTypes.ResourceNotFoundException{}
+	}
+	_, err = NewDeployer(client, nil, nil, config).addResourcePolicy(context.Background())
+	assert.ErrorContains(t, err, "alias or version live of test-function does not exist yet")
+	assert.Empty(t, calls, "the unqualified statement is kept while the alias cannot be invoked")
+}
+
+func TestResourcePolicyDiff_Qualified(t *testing.T) {
+	config := policyTestConfig()
+	config.ResourcePolicyQualifier = "live"
+
+	var calls []string
+	diff, err := (&resourcePolicyResource{d: NewDeployer(qualifiedPolicyClient(true, &calls), nil, nil, config)}).Diff(context.Background())
+	require.NoError(t, err)
+	assert.True(t, diff.Exists)
+	assert.Equal(t, []deploy.Change{{Field: "qualifier", Current: "", Desired: "live"}}, diff.Changes)
+
+	diff, err = (&resourcePolicyResource{d: NewDeployer(qualifiedPolicyClient(false, &calls), nil, nil, config)}).Diff(context.Background())
+	require.NoError(t, err)
+	assert.False(t, diff.Exists)
+
+	require.NoError(t, (&resourcePolicyResource{d: NewDeployer(qualifiedPolicyClient(false, &calls), nil, nil, config)}).Delete(context.Background()))
+	assert.Equal(t, []string{"remove AllowCLMInvoke live", "remove AllowCLMInvoke "}, calls)
+}
@@ -0,0 +1,564 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+)
+
+// ResourceTypeResourcePolicy identifies the statement allowing CLM to invoke the function
+const ResourceTypeResourcePolicy = "resource-policy"
+
+// DefaultLogRetentionDays is the retention applied to the function's log group when
+// DeploymentConfig.LogRetentionDays is not set
+const DefaultLogRetentionDays = 90
+
+// logRetentionPeriods are the retention periods, in days, CloudWatch Logs accepts
+var logRetentionPeriods = []int32{1, 3, 5, 7, 14, 30, 60, 90, 120, 150, 180, 365, 400, 545, 731, 1096, 1827, 2192, 2557, 2922, 3288, 3653}
+
+// Resources returns the resources a deployment manages, in dependency order: the
+// execution role unless ExecutionRoleARN names a pre-created one, the ledger table
+// when one is configured, the function, its resource policy when a CLM service role
+// is configured, and the log group. The function resource can diff and delete but
+// only Deploy, which builds the package, can ensure it.
+func (d *Deployer) Resources() []deploy.Resource {
+	return d.managedResources(d.config.CLMServiceRoleARN != "", d.config.LedgerTableName != "")
+}
+
+// managedResources returns the managed resources, including the resource policy
+// statement if withPolicy is set and the ledger table if withLedger is set
+func (d *Deployer) managedResources(withPolicy, withLedger bool) []deploy.Resource {
+	var resources []deploy.Resource
+	// A pre-created execution role belongs to the customer, not the deployment
+	if d.config.ExecutionRoleARN == "" {
+		resources = append(resources, d.executionRoleResource(d.config.ExecutionRoleName))
+	}
+	if withLedger {
+		resources = append(resources, d.ledgerTableResource(d.config.LedgerTableName))
+	}
+	resources = append(resources, d.functionResource(d.config.FunctionName))
+	if withPolicy {
+		resources = append(resources, &resourcePolicyResource{d: d})
+	}
+	resources = append(resources, d.logGroupResource(d.logGroupName()))
+	return resources
+}
+
+// Plan resolves the target account and diffs every managed resource a deployment with
+// the configured steps would touch, without changing anything
+func (d *Deployer) Plan(ctx context.Context) (diffs []deploy.Diff, err error) {
+	ctx, span := d.startSpan(ctx, "Plan")
+	defer func() { endSpan(span, err) }()
+
+	if err := d.resolveScope(ctx); err != nil {
+		return nil, err
+	}
+	return d.engine(d.selectedResources(d.Resources())).Plan(ctx)
+}
+
+// PlanTeardown diffs the resources Teardown would consider, without changing anything
+func (d *Deployer) PlanTeardown(ctx context.Context) (diffs []deploy.Diff, err error) {
+	ctx, span := d.startSpan(ctx, "PlanTeardown")
+	defer func() { endSpan(span, err) }()
+
+	if err := d.resolveScope(ctx); err != nil {
+		return nil, err
+	}
+	if err := d.resolveLogGroupName(ctx); err != nil {
+		return nil, err
+	}
+	return d.engine(d.managedResources(true, false)).Plan(ctx)
+}
+
+// Teardown deletes every managed resource in reverse dependency order. Nothing is
+// deleted if any of them exists but is not managed by rosactl. Without a configured
+// log group name, the log group deleted is the one the function writes to. The CLM resource
+// policy statement is removed whether or not a CLM service role is configured, so a
+// statement naming a retired role does not outlive the deployment. The ledger table
+// is kept, so the provisioning history outlives the function.
+func (d *Deployer) Teardown(ctx context.Context) (deleted []deploy.Ref, err error) {
+	ctx, span := d.startSpan(ctx, "Teardown")
+	defer func() { endSpan(span, err) }()
+
+	if err := d.resolveScope(ctx); err != nil {
+		return nil, err
+	}
+	if err := d.resolveLogGroupName(ctx); err != nil {
+		return nil, err
+	}
+	return d.engine(d.managedResources(true, false)).Teardown(ctx)
+}
+
+// engine returns an engine for resources that traces with the deployer's provider
+func (d *Deployer) engine(resources []deploy.Resource) *deploy.Engine {
+	return deploy.NewEngine(resources, deploy.WithTracerProvider(d.tracerProvider))
+}
+
+// defaultLogGroupName returns the log group Lambda writes a function's logs to unless
+// its logging config names another
+func defaultLogGroupName(functionName string) string {
+	return fmt.Sprintf("/aws/lambda/%s", functionName)
+}
+
+// logGroupName returns the log group the function writes to: the configured one, or
+// Lambda's default for the function
+func (d *Deployer) logGroupName() string {
+	if d.config.LogGroupName != "" {
+		return d.config.LogGroupName
+	}
+	return defaultLogGroupName(d.config.FunctionName)
+}
+
+// FunctionLogGroupName returns the log group a function with configuration cfg writes to
+func FunctionLogGroupName(cfg *lambdaTypes.FunctionConfiguration, functionName string) string {
+	if cfg.LoggingConfig != nil && aws.ToString(cfg.LoggingConfig.LogGroup) != "" {
+		return aws.ToString(cfg.LoggingConfig.LogGroup)
+	}
+	return defaultLogGroupName(functionName)
+}
+
+// resolveLogGroupName makes teardown find the log group an existing function writes to
+// when none is configured, rather than assuming Lambda's default
+func (d *Deployer) resolveLogGroupName(ctx context.Context) error {
+	if d.config.LogGroupName != "" {
+		return nil
+	}
+	output, err := d.lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(d.config.FunctionName),
+	})
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to get function: %w", err)
+	}
+	if output.Configuration != nil && output.Configuration.LoggingConfig != nil {
+		d.config.LogGroupName = aws.ToString(output.Configuration.LoggingConfig.LogGroup)
+	}
+	return nil
+}
+
+// actionSince returns the action recorded after the first n records, or unchanged if none was
+func (d *Deployer) actionSince(n int) string {
+	if len(d.resources) > n {
+		return d.resources[len(d.resources)-1].Action
+	}
+	return ResourceActionUnchanged
+}
+
+// executionRoleResource is the IAM role the function runs as, with its inline permissions policy
+type executionRoleResource struct {
+	d    *Deployer
+	name string
+	arn  string // Set by Ensure
+}
+
+func (d *Deployer) executionRoleResource(name string) *executionRoleResource {
+	return &executionRoleResource{d: d, name: name}
+}
+
+func (r *executionRoleResource) Ref() deploy.Ref {
+	return deploy.Ref{Type: ResourceTypeExecutionRole, ID: r.name}
+}
+
+func (r *executionRoleResource) Ensure(ctx context.Context) (string, error) {
+	recorded := len(r.d.resources)
+	roleARN, err := r.d.ensureExecutionRole(ctx)
+	if err != nil {
+		return "", err
+	}
+	r.arn = roleARN
+	return r.d.actionSince(recorded), nil
+}
+
+func (r *executionRoleResource) Diff(ctx context.Context) (*deploy.Diff, error) {
+	diff := &deploy.Diff{Ref: r.Ref()}
+
+	output, err := r.d.iamClient.GetRole(ctx, &iam.GetRoleInput{
+		RoleName: aws.String(r.name),
+	})
+	if err != nil {
+		var notFoundErr *iamTypes.NoSuchEntityException
+		if errors.As(err, &notFoundErr) {
+			return diff, nil
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	diff.Exists = true
+	diff.Managed = r.d.isManagedIAM(output.Role.Tags)
+	if !diff.Managed {
+		diff.Changes = append(diff.Changes, r.d.managedTagChange())
+	}
+
+	desired, err := r.d.executionRoleTrustPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate trust policy: %w", err)
+	}
+	// IAM returns policy documents URL-encoded
+	current, err := url.QueryUnescape(aws.ToString(output.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		current = aws.ToString(output.Role.AssumeRolePolicyDocument)
+	}
+	if !equalJSON(current, desired) {
+		diff.Changes = append(diff.Changes, deploy.Change{Field: "trust policy", Current: current, Desired: desired})
+	}
+
+	if diff.Managed {
+		stale, currentTags, err := r.d.staleRoleTags(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range stale {
+			key := aws.ToString(tag.Key)
+			diff.Changes = append(diff.Changes, deploy.Change{
+				Field:   "tag " + key,
+				Current: currentTags[key],
+				Desired: aws.ToString(tag.Value),
+			})
+		}
+	}
+
+	return diff, nil
+}
+
+func (r *executionRoleResource) Delete(ctx context.Context) error {
+	var notFoundErr *iamTypes.NoSuchEntityException
+	_, err := r.d.iamClient.DeleteRolePolicy(ctx, &iam.DeleteRolePolicyInput{
+		RoleName:   aws.String(r.name),
+		PolicyName: aws.String(permissionsPolicyName),
+	})
+	if err != nil && !errors.As(err, &notFoundErr) {
+		return err
+	}
+
+	_, err = r.d.iamClient.DeleteRole(ctx, &iam.DeleteRoleInput{
+		RoleName: aws.String(r.name),
+	})
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
+
+// functionResource is the provisioner Lambda function. Ensure needs the package
+// built by Deploy and the result of the existence check made before any change.
+type functionResource struct {
+	d        *Deployer
+	name     string
+	zipData  []byte
+	roleARN  string
+	existing *lambda.GetFunctionOutput
+	action   string // Action recorded when updating an existing function
+	arn      string // Set by Ensure
+}
+
+func (d *Deployer) functionResource(name string) *functionResource {
+	return &functionResource{d: d, name: name}
+}
+
+func (r *functionResource) Ref() deploy.Ref {
+	return deploy.Ref{Type: ResourceTypeFunction, ID: r.name}
+}
+
+func (r *functionResource) Ensure(ctx context.Context) (string, error) {
+	if r.zipData == nil && (r.existing == nil || r.d.runs(StepFunctionCode)) {
+		return "", fmt.Errorf("function %s cannot be deployed before its package is built", r.name)
+	}
+
+	if r.existing != nil {
+		// Update existing function, reconciling it to the desired configuration
+		r.arn = aws.ToString(r.existing.Configuration.FunctionArn)
+		err := r.d.withStepTimeout(ctx, TimedUpload, func(ctx context.Context) error {
+			return r.d.updateFunction(ctx, r.zipData, r.roleARN, r.existing.Configuration)
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to update function: %w", err)
+		}
+		action := r.action
+		if action == "" {
+			action = ResourceActionUpdated
+		}
+		r.d.record(ResourceTypeFunction, r.arn, action)
+		return action, nil
+	}
+
+	functionARN, err := r.d.createFunctionWhenRoleAssumable(ctx, r.zipData, r.roleARN)
+	if err != nil {
+		return "", fmt.Errorf("failed to create function: %w", err)
+	}
+	r.arn = functionARN
+	r.d.record(ResourceTypeFunction, functionARN, ResourceActionCreated)
+	return ResourceActionCreated, nil
+}
+
+func (r *functionResource) Diff(ctx context.Context) (*deploy.Diff, error) {
+	diff := &deploy.Diff{Ref: r.Ref()}
+
+	output, err := r.d.lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(r.name),
+	})
+	if err != nil {
+		var notFoundErr *lambdaTypes.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return diff, nil
+		}
+		return nil, fmt.Errorf("failed to get function: %w", err)
+	}
+
+	diff.Exists = true
+	diff.Managed = r.d.isManaged(output.Tags)
+	if !diff.Managed {
+		diff.Changes = append(diff.Changes, r.d.managedTagChange())
+	}
+
+	cfg := output.Configuration
+	if cfg == nil {
+		return diff, nil
+	}
+
+	cfgField := func(field, current, desired string) {
+		if desired != "" && current != desired {
+			diff.Changes = append(diff.Changes, deploy.Change{Field: field, Current: current, Desired: desired})
+		}
+	}
+	cfgField("runtime", string(cfg.Runtime), string(r.d.config.Runtime))
+	cfgField("memory", int32String(cfg.MemorySize), positiveInt32String(r.d.config.MemorySize))
+	cfgField("timeout", int32String(cfg.Timeout), positiveInt32String(r.d.config.Timeout))
+	var ephemeralStorage *int32
+	if cfg.EphemeralStorage != nil {
+		ephemeralStorage = cfg.EphemeralStorage.Size
+	}
+	cfgField("ephemeral storage", int32String(ephemeralStorage), positiveInt32String(r.d.config.EphemeralStorage))
+	if r.d.config.SnapStart {
+		current := string(lambdaTypes.SnapStartApplyOnNone)
+		if cfg.SnapStart != nil {
+			current = string(cfg.SnapStart.ApplyOn)
+		}
+		cfgField("snapstart", current, string(lambdaTypes.SnapStartApplyOnPublishedVersions))
+	}
+	if r.d.config.Tracing {
+		current := string(lambdaTypes.TracingModePassThrough)
+		if cfg.TracingConfig != nil {
+			current = string(cfg.TracingConfig.Mode)
+		}
+		cfgField("tracing", current, string(lambdaTypes.TracingModeActive))
+	}
+	var environment map[string]string
+	if cfg.Environment != nil {
+		environment = cfg.Environment.Variables
+	}
+	for _, key := range slices.Sorted(maps.Keys(r.d.config.Environment)) {
+		cfgField("environment variable "+key, environment[key], r.d.config.Environment[key])
+	}
+	cfgField("execution role", roleNameFromARN(aws.ToString(cfg.Role)), r.d.config.ExecutionRoleName)
+	cfgField("log group", FunctionLogGroupName(cfg, r.name), r.d.logGroupName())
+
+	stamp := r.d.stamp()
+	for _, drift := range CompareStamp(stamp, ReadStamp(output, r.d.keys), aws.ToString(cfg.CodeSha256)) {
+		diff.Changes = append(diff.Changes, deploy.Change{Field: drift.Field, Current: drift.Actual, Desired: drift.Expected})
+	}
+
+	return diff, nil
+}
+
+func (r *functionResource) Delete(ctx context.Context) error {
+	_, err := r.d.lambdaClient.DeleteFunction(ctx, &lambda.DeleteFunctionInput{
+		FunctionName: aws.String(r.name),
+	})
+	var notFoundErr *lambdaTypes.ResourceNotFoundException
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
+
+// resourcePolicyResource is the function policy statement allowing CLM to invoke it.
+// The statement is read back with GetPolicy and replaced when it differs, so changing
+// the CLM service role revokes the old one. A statement rosactl added is identified
+// by its statement ID; other statements in the policy are left alone. With a
+// ResourcePolicyQualifier the statement lives on the alias or version instead, and a
+// leftover statement on the unqualified function is reported as a change.
+type resourcePolicyResource struct {
+	d *Deployer
+}
+
+func (r *resourcePolicyResource) Ref() deploy.Ref {
+	return deploy.Ref{Type: ResourceTypeResourcePolicy, ID: resourcePolicyStatementID}
+}
+
+func (r *resourcePolicyResource) Ensure(ctx context.Context) (string, error) {
+	return r.d.addResourcePolicy(ctx)
+}
+
+func (r *resourcePolicyResource) Diff(ctx context.Context) (*deploy.Diff, error) {
+	diff := &deploy.Diff{Ref: r.Ref()}
+	qualifier := r.d.config.ResourcePolicyQualifier
+
+	statements, err := r.d.functionPolicy(ctx, qualifier)
+	if err != nil {
+		return nil, err
+	}
+	current := findStatement(statements, resourcePolicyStatementID)
+
+	if qualifier != "" {
+		unqualified, err := r.d.functionPolicy(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		if findStatement(unqualified, resourcePolicyStatementID) != nil {
+			diff.Exists = true
+			diff.Managed = true
+			diff.Changes = append(diff.Changes, deploy.Change{Field: "qualifier", Current: "", Desired: qualifier})
+		}
+	}
+	if current == nil {
+		return diff, nil
+	}
+
+	diff.Exists = true
+	diff.Managed = true
+	// Without a CLM service role the deployment does not manage the statement, but
+	// teardown still removes it
+	if r.d.config.CLMServiceRoleARN != "" {
+		diff.Changes = append(diff.Changes, diffPermission(*current, r.d.desiredPermission())...)
+	}
+	return diff, nil
+}
+
+func (r *resourcePolicyResource) Delete(ctx context.Context) error {
+	if qualifier := r.d.config.ResourcePolicyQualifier; qualifier != "" {
+		if err := r.d.removePermission(ctx, qualifier); err != nil {
+			return err
+		}
+	}
+	return r.d.removePermission(ctx, "")
+}
+
+// logGroupResource is the CloudWatch Logs log group the function writes to
+type logGroupResource struct {
+	d    *Deployer
+	name string
+}
+
+func (d *Deployer) logGroupResource(name string) *logGroupResource {
+	return &logGroupResource{d: d, name: name}
+}
+
+func (r *logGroupResource) Ref() deploy.Ref {
+	return deploy.Ref{Type: ResourceTypeLogGroup, ID: r.name}
+}
+
+func (r *logGroupResource) Ensure(ctx context.Context) (string, error) {
+	recorded := len(r.d.resources)
+	if err := r.d.ensureLogGroup(ctx, r.name); err != nil {
+		return "", err
+	}
+	return r.d.actionSince(recorded), nil
+}
+
+func (r *logGroupResource) Diff(ctx context.Context) (*deploy.Diff, error) {
+	diff := &deploy.Diff{Ref: r.Ref()}
+
+	output, err := r.d.cwLogsClient.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(r.name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe log groups: %w", err)
+	}
+
+	var group *types.LogGroup
+	for i := range output.LogGroups {
+		if aws.ToString(output.LogGroups[i].LogGroupName) == r.name {
+			group = &output.LogGroups[i]
+			break
+		}
+	}
+	if group == nil {
+		return diff, nil
+	}
+	diff.Exists = true
+
+	// DescribeLogGroups reports the ARN with a trailing ":*"
+	tags, err := r.d.cwLogsClient.ListTagsForResource(ctx, &cloudwatchlogs.ListTagsForResourceInput{
+		ResourceArn: aws.String(strings.TrimSuffix(aws.ToString(group.Arn), ":*")),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log group tags: %w", err)
+	}
+	diff.Managed = r.d.isManaged(tags.Tags)
+	if !diff.Managed {
+		diff.Changes = append(diff.Changes, r.d.managedTagChange())
+	}
+
+	if retention := r.d.logRetentionDays(); aws.ToInt32(group.RetentionInDays) != retention {
+		diff.Changes = append(diff.Changes, deploy.Change{
+			Field:   "retention days",
+			Current: int32String(group.RetentionInDays),
+			Desired: strconv.Itoa(int(retention)),
+		})
+	}
+
+	return diff, nil
+}
+
+func (r *logGroupResource) Delete(ctx context.Context) error {
+	_, err := r.d.cwLogsClient.DeleteLogGroup(ctx, &cloudwatchlogs.DeleteLogGroupInput{
+		LogGroupName: aws.String(r.name),
+	})
+	var notFoundErr *types.ResourceNotFoundException
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+	return err
+}
+
+// managedTagChange reports a missing rosactl ownership tag
+func (d *Deployer) managedTagChange() deploy.Change {
+	return deploy.Change{Field: "tag " + d.keys.Key(ManagedTagKey), Desired: ManagedTagValue}
+}
+
+// int32String formats an optional number, returning "" when it is unset
+func int32String(v *int32) string {
+	if v == nil {
+		return ""
+	}
+	return strconv.Itoa(int(*v))
+}
+
+// positiveInt32String formats a configured number, returning "" when it is not set
+func positiveInt32String(v int32) string {
+	if v <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(v))
+}
+
+// equalJSON reports whether two JSON documents are semantically equal
+func equalJSON(a, b string) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
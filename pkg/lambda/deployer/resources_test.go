@@ -0,0 +1,274 @@
+package deployer
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLogGroupARN = "arn:aws:logs:us-east-1:123456789012:log-group:/aws/lambda/test-function"
+
+func resourcesTestConfig() DeploymentConfig {
+	return DeploymentConfig{
+		FunctionName:      "test-function",
+		ExecutionRoleName: "test-role",
+		Runtime:           lambdaTypes.RuntimeProvidedal2023,
+		MemorySize:        128,
+		Timeout:           60,
+		CLIVersion:        "0.2.0",
+	}
+}
+
+// managedRoleClient returns an IAM client whose role matches the default trust policy
+func managedRoleClient(t *testing.T) *mockIAMClient {
+	trustPolicy, err := GenerateLambdaExecutionRoleTrustPolicy()
+	require.NoError(t, err)
+
+	return &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			return &iam.GetRoleOutput{
+				Role: &iamTypes.Role{
+					Arn:                      aws.String("arn:aws:iam::123456789012:role/test-role"),
+					AssumeRolePolicyDocument: aws.String(url.QueryEscape(trustPolicy)),
+					Tags:                     []iamTypes.Tag{{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)}},
+				},
+			}, nil
+		},
+	}
+}
+
+// logGroupClient returns a CloudWatch Logs client with the function's log group
+func logGroupClient(retention int32, tags map[string]string) *mockCloudWatchLogsClient {
+	return &mockCloudWatchLogsClient{
+		describeLogGroupsFunc: func(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+			return &cloudwatchlogs.DescribeLogGroupsOutput{
+				LogGroups: []cwTypes.LogGroup{{
+					LogGroupName:    aws.String("/aws/lambda/test-function"),
+					Arn:             aws.String(testLogGroupARN + ":*"),
+					RetentionInDays: aws.Int32(retention),
+				}},
+			}, nil
+		},
+		listTagsFunc: func(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+			if aws.ToString(params.ResourceArn) != testLogGroupARN {
+				return nil, &cwTypes.ResourceNotFoundException{Message: aws.String("wrong ARN")}
+			}
+			return &cloudwatchlogs.ListTagsForResourceOutput{Tags: tags}, nil
+		},
+	}
+}
+
+func TestResources_Order(t *testing.T) {
+	config := resourcesTestConfig()
+	assert.Len(t, NewDeployer(nil, nil, nil, config).Resources(), 3)
+
+	config.CLMServiceRoleARN = "arn:aws:iam::987654321098:role/clm"
+	var types []string
+	for _, r := range NewDeployer(nil, nil, nil, config).Resources() {
+		types = append(types, r.Ref().Type)
+	}
+	assert.Equal(t, []string{ResourceTypeExecutionRole, ResourceTypeFunction, ResourceTypeResourcePolicy, ResourceTypeLogGroup}, types)
+}
+
+func TestPlan(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{
+					FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:test-function"),
+					Runtime:     lambdaTypes.RuntimeProvidedal2023,
+					MemorySize:  aws.Int32(256),
+					Timeout:     aws.Int32(60),
+					Role:        aws.String("arn:aws:iam::123456789012:role/test-role"),
+				},
+				Tags: map[string]string{ManagedTagKey: ManagedTagValue, CLIVersionTagKey: "0.1.0"},
+			}, nil
+		},
+	}
+	mockCWLogs := logGroupClient(7, map[string]string{ManagedTagKey: ManagedTagValue})
+
+	diffs, err := NewDeployer(mockLambda, managedRoleClient(t), mockCWLogs, resourcesTestConfig()).Plan(context.Background())
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+
+	assert.True(t, diffs[0].InSync(), "role matches: %+v", diffs[0].Changes)
+
+	assert.True(t, diffs[1].Exists)
+	assert.Equal(t, []deploy.Change{
+		{Field: "memory", Current: "256", Desired: "128"},
+		{Field: DriftFieldCLIVersion, Current: "0.1.0", Desired: "0.2.0"},
+	}, diffs[1].Changes)
+
+	assert.Equal(t, []deploy.Change{{Field: "retention days", Current: "7", Desired: "90"}}, diffs[2].Changes)
+}
+
+func TestPlan_RoleTags(t *testing.T) {
+	mockIAM := managedRoleClient(t)
+	config := resourcesTestConfig()
+	config.Tags = map[string]string{"team": "platform"}
+
+	diff, err := NewDeployer(nil, mockIAM, nil, config).executionRoleResource("test-role").Diff(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []deploy.Change{{Field: "tag team", Current: "", Desired: "platform"}}, diff.Changes)
+}
+
+func TestPlan_NothingDeployed(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return nil, &lambdaTypes.ResourceNotFoundException{Message: aws.String("not found")}
+		},
+	}
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			return nil, &iamTypes.NoSuchEntityException{Message: aws.String("not found")}
+		},
+	}
+
+	config := resourcesTestConfig()
+	config.CLMServiceRoleARN = "arn:aws:iam::987654321098:role/clm"
+	diffs, err := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, config).Plan(context.Background())
+	require.NoError(t, err)
+	require.Len(t, diffs, 4)
+	for _, diff := range diffs {
+		assert.False(t, diff.Exists, diff.Ref.String())
+	}
+}
+
+func TestTeardown(t *testing.T) {
+	var deleted []string
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{Tags: map[string]string{ManagedTagKey: ManagedTagValue}}, nil
+		},
+		deleteFunctionFunc: func(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error) {
+			deleted = append(deleted, "function "+aws.ToString(params.FunctionName))
+			return &lambda.DeleteFunctionOutput{}, nil
+		},
+	}
+	mockIAM := managedRoleClient(t)
+	mockIAM.deleteRolePolicyFunc = func(ctx context.Context, params *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error) {
+		deleted = append(deleted, "policy "+aws.ToString(params.PolicyName))
+		return &iam.DeleteRolePolicyOutput{}, nil
+	}
+	mockIAM.deleteRoleFunc = func(ctx context.Context, params *iam.DeleteRoleInput, optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error) {
+		deleted = append(deleted, "role "+aws.ToString(params.RoleName))
+		return &iam.DeleteRoleOutput{}, nil
+	}
+	mockCWLogs := logGroupClient(90, map[string]string{ManagedTagKey: ManagedTagValue})
+	mockCWLogs.deleteLogGroupFunc = func(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+		deleted = append(deleted, "log group "+aws.ToString(params.LogGroupName))
+		return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+	}
+
+	refs, err := NewDeployer(mockLambda, mockIAM, mockCWLogs, resourcesTestConfig()).Teardown(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, refs, 3)
+	assert.Equal(t, []string{
+		"log group /aws/lambda/test-function",
+		"function test-function",
+		"policy " + permissionsPolicyName,
+		"role test-role",
+	}, deleted)
+}
+
+func TestTeardown_RefusesUnmanagedLogGroup(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{Tags: map[string]string{ManagedTagKey: ManagedTagValue}}, nil
+		},
+		deleteFunctionFunc: func(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error) {
+			t.Fatal("nothing should be deleted")
+			return nil, nil
+		},
+	}
+
+	_, err := NewDeployer(mockLambda, managedRoleClient(t), logGroupClient(90, nil), resourcesTestConfig()).Teardown(context.Background())
+	var unmanagedErr *deploy.UnmanagedError
+	require.ErrorAs(t, err, &unmanagedErr)
+	assert.Equal(t, []deploy.Ref{{Type: ResourceTypeLogGroup, ID: "/aws/lambda/test-function"}}, unmanagedErr.Refs)
+}
+
+func TestTeardown_CustomLogGroup(t *testing.T) {
+	const customGroup = "/org/rosa/oidc-provisioner"
+	var deletedGroup string
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{
+					LoggingConfig: &lambdaTypes.LoggingConfig{LogGroup: aws.String(customGroup)},
+				},
+				Tags: map[string]string{ManagedTagKey: ManagedTagValue},
+			}, nil
+		},
+	}
+	mockCWLogs := &mockCloudWatchLogsClient{
+		describeLogGroupsFunc: func(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+			return &cloudwatchlogs.DescribeLogGroupsOutput{
+				LogGroups: []cwTypes.LogGroup{{LogGroupName: aws.String(customGroup), RetentionInDays: aws.Int32(DefaultLogRetentionDays)}},
+			}, nil
+		},
+		listTagsFunc: func(ctx context.Context, params *cloudwatchlogs.ListTagsForResourceInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.ListTagsForResourceOutput, error) {
+			return &cloudwatchlogs.ListTagsForResourceOutput{Tags: map[string]string{ManagedTagKey: ManagedTagValue}}, nil
+		},
+		deleteLogGroupFunc: func(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+			deletedGroup = aws.ToString(params.LogGroupName)
+			return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+		},
+	}
+
+	// Without a configured name, the group the function writes to is torn down
+	_, err := NewDeployer(mockLambda, managedRoleClient(t), mockCWLogs, resourcesTestConfig()).Teardown(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, customGroup, deletedGroup)
+}
+
+func TestPlan_LogGroupDrift(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{
+					LoggingConfig: &lambdaTypes.LoggingConfig{LogGroup: aws.String("/aws/lambda/test-function")},
+				},
+				Tags: map[string]string{ManagedTagKey: ManagedTagValue},
+			}, nil
+		},
+	}
+	config := resourcesTestConfig()
+	config.LogGroupName = "/org/rosa/oidc-provisioner"
+
+	diff, err := NewDeployer(mockLambda, nil, nil, config).functionResource("test-function").Diff(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, diff.Changes, deploy.Change{Field: "log group", Current: "/aws/lambda/test-function", Desired: "/org/rosa/oidc-provisioner"})
+}
+
+func TestCreateFunction_LoggingConfig(t *testing.T) {
+	var input *lambda.CreateFunctionInput
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			input = params
+			return &lambda.CreateFunctionOutput{FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:test-function")}, nil
+		},
+	}
+
+	_, err := NewDeployer(mockLambda, nil, nil, resourcesTestConfig()).createFunction(context.Background(), []byte("zip"), "role")
+	require.NoError(t, err)
+	assert.Nil(t, input.LoggingConfig, "Lambda's default log group is kept")
+
+	config := resourcesTestConfig()
+	config.LogGroupName = "/org/rosa/oidc-provisioner"
+	_, err = NewDeployer(mockLambda, nil, nil, config).createFunction(context.Background(), []byte("zip"), "role")
+	require.NoError(t, err)
+	require.NotNil(t, input.LoggingConfig)
+	assert.Equal(t, "/org/rosa/oidc-provisioner", aws.ToString(input.LoggingConfig.LogGroup))
+}
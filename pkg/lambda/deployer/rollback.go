@@ -0,0 +1,131 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// PartialFailureError is returned when a deployment fails after creating resources.
+// Resources that were rolled back are listed in RolledBack; anything still present
+// in the account is listed in Remaining.
+type PartialFailureError struct {
+	Err            error
+	RolledBack     []ResourceRecord
+	Remaining      []ResourceRecord
+	RollbackErrors []error
+	region         string
+}
+
+func (e *PartialFailureError) Error() string {
+	if len(e.Remaining) == 0 {
+		return fmt.Sprintf("%v (rolled back %d created resource(s))", e.Err, len(e.RolledBack))
+	}
+
+	remaining := make([]string, 0, len(e.Remaining))
+	for _, r := range e.Remaining {
+		remaining = append(remaining, fmt.Sprintf("%s %s", r.Type, r.Identifier))
+	}
+	return fmt.Sprintf("%v (created resources remain: %s)", e.Err, strings.Join(remaining, ", "))
+}
+
+func (e *PartialFailureError) Unwrap() error {
+	return e.Err
+}
+
+// CleanupPlan returns AWS CLI commands that delete the remaining resources, in order
+func (e *PartialFailureError) CleanupPlan() []string {
+	regionFlag := ""
+	if e.region != "" {
+		regionFlag = " --region " + e.region
+	}
+
+	var commands []string
+	for _, r := range e.Remaining {
+		switch r.Type {
+		case ResourceTypeLogGroup:
+			commands = append(commands, fmt.Sprintf("aws logs delete-log-group --log-group-name %s%s", r.Identifier, regionFlag))
+		case ResourceTypeFunction:
+			commands = append(commands, fmt.Sprintf("aws lambda delete-function --function-name %s%s", r.Identifier, regionFlag))
+		case ResourceTypeLedgerTable:
+			commands = append(commands, fmt.Sprintf("aws dynamodb delete-table --table-name %s%s", r.Identifier, regionFlag))
+		case ResourceTypeExecutionRole:
+			roleName := roleNameFromARN(r.Identifier)
+			commands = append(commands,
+				fmt.Sprintf("aws iam delete-role-policy --role-name %s --policy-name %s", roleName, permissionsPolicyName),
+				fmt.Sprintf("aws iam delete-role --role-name %s", roleName))
+		}
+	}
+	return commands
+}
+
+// handleFailure rolls back resources created by a failed deployment, unless
+// NoRollback is set, and describes what remains
+func (d *Deployer) handleFailure(ctx context.Context, deployErr error) error {
+	// Newest first, so the function is removed before the role it uses
+	var created []ResourceRecord
+	for i := len(d.resources) - 1; i >= 0; i-- {
+		if d.resources[i].Action == ResourceActionCreated {
+			created = append(created, d.resources[i])
+		}
+	}
+
+	if len(created) == 0 {
+		return deployErr
+	}
+
+	failure := &PartialFailureError{
+		Err:    deployErr,
+		region: d.config.Region,
+	}
+
+	if d.config.NoRollback {
+		failure.Remaining = created
+		return failure
+	}
+
+	// Roll back even if the deployment was interrupted
+	rollbackCtx := context.WithoutCancel(ctx)
+
+	for _, r := range created {
+		if err := d.deleteResource(rollbackCtx, r); err != nil {
+			failure.Remaining = append(failure.Remaining, r)
+			failure.RollbackErrors = append(failure.RollbackErrors, fmt.Errorf("failed to delete %s %s: %w", r.Type, r.Identifier, err))
+			continue
+		}
+		failure.RolledBack = append(failure.RolledBack, r)
+	}
+
+	return failure
+}
+
+// deleteResource removes a resource created during this deployment
+func (d *Deployer) deleteResource(ctx context.Context, r ResourceRecord) error {
+	switch r.Type {
+	case ResourceTypeLogGroup:
+		return d.logGroupResource(r.Identifier).Delete(ctx)
+	case ResourceTypeFunction:
+		return d.functionResource(r.Identifier).Delete(ctx)
+	case ResourceTypeLedgerTable:
+		return d.ledgerTableResource(r.Identifier).Delete(ctx)
+	case ResourceTypeExecutionRole:
+		return d.executionRoleResource(roleNameFromARN(r.Identifier)).Delete(ctx)
+	default:
+		return fmt.Errorf("unknown resource type %s", r.Type)
+	}
+}
+
+// roleNameFromARN returns the role name from an IAM role ARN, ignoring any path
+func roleNameFromARN(roleARN string) string {
+	parsed, err := arn.Parse(roleARN)
+	if err != nil {
+		return roleARN
+	}
+	resource := strings.TrimPrefix(parsed.Resource, "role/")
+	if i := strings.LastIndex(resource, "/"); i >= 0 {
+		return resource[i+1:]
+	}
+	return resource
+}
@@ -0,0 +1,171 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rollbackRoleARN = "arn:aws:iam::123456789012:role/test-role"
+
+// failingRoleSetup creates the role and then fails to attach its permissions policy
+func failingRoleSetup(deleted *[]string) (*mockLambdaClient, *mockIAMClient) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return nil, &lambdaTypes.ResourceNotFoundException{}
+		},
+	}
+
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			return nil, &iamTypes.NoSuchEntityException{}
+		},
+		createRoleFunc: func(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
+			return &iam.CreateRoleOutput{Role: &iamTypes.Role{Arn: aws.String(rollbackRoleARN)}}, nil
+		},
+		putRolePolicyFunc: func(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error) {
+			return nil, errors.New("access denied")
+		},
+		deleteRolePolicyFunc: func(ctx context.Context, params *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error) {
+			return nil, &iamTypes.NoSuchEntityException{}
+		},
+		deleteRoleFunc: func(ctx context.Context, params *iam.DeleteRoleInput, optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error) {
+			*deleted = append(*deleted, *params.RoleName)
+			return &iam.DeleteRoleOutput{}, nil
+		},
+	}
+
+	return mockLambda, mockIAM
+}
+
+func rollbackConfig() DeploymentConfig {
+	return DeploymentConfig{
+		FunctionName:      "test-function",
+		ExecutionRoleName: "test-role",
+		SourceDir:         "../functions/oidc-provisioner",
+		Region:            "us-east-1",
+		MemorySize:        128,
+		Timeout:           60,
+	}
+}
+
+func TestDeploy_RollsBackCreatedRole(t *testing.T) {
+	var deleted []string
+	mockLambda, mockIAM := failingRoleSetup(&deleted)
+
+	deployer := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, rollbackConfig())
+	_, err := deployer.Deploy(context.Background())
+
+	var failure *PartialFailureError
+	require.ErrorAs(t, err, &failure)
+	assert.Contains(t, err.Error(), "access denied")
+	assert.Equal(t, []string{"test-role"}, deleted)
+	assert.Equal(t, []ResourceRecord{{Type: ResourceTypeExecutionRole, Identifier: rollbackRoleARN, Action: ResourceActionCreated}}, failure.RolledBack)
+	assert.Empty(t, failure.Remaining)
+	assert.Empty(t, failure.CleanupPlan())
+}
+
+func TestDeploy_NoRollbackLeavesCleanupPlan(t *testing.T) {
+	var deleted []string
+	mockLambda, mockIAM := failingRoleSetup(&deleted)
+
+	config := rollbackConfig()
+	config.NoRollback = true
+
+	deployer := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, config)
+	_, err := deployer.Deploy(context.Background())
+
+	var failure *PartialFailureError
+	require.ErrorAs(t, err, &failure)
+	assert.Empty(t, deleted)
+	assert.Empty(t, failure.RolledBack)
+	require.Len(t, failure.Remaining, 1)
+	assert.Contains(t, err.Error(), "iam-role "+rollbackRoleARN)
+	assert.Equal(t, []string{
+		"aws iam delete-role-policy --role-name test-role --policy-name OIDCProvisionerPermissions",
+		"aws iam delete-role --role-name test-role",
+	}, failure.CleanupPlan())
+}
+
+func TestDeploy_FailureWithoutCreatedResources(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return nil, &lambdaTypes.ResourceNotFoundException{}
+		},
+	}
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			return nil, errors.New("access denied")
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, rollbackConfig())
+	_, err := deployer.Deploy(context.Background())
+
+	require.Error(t, err)
+	var failure *PartialFailureError
+	assert.False(t, errors.As(err, &failure))
+}
+
+func TestHandleFailure_RollbackOrderAndErrors(t *testing.T) {
+	var calls []string
+
+	mockLambda := &mockLambdaClient{
+		deleteFunctionFunc: func(ctx context.Context, params *lambda.DeleteFunctionInput, optFns ...func(*lambda.Options)) (*lambda.DeleteFunctionOutput, error) {
+			calls = append(calls, "function")
+			return nil, errors.New("throttled")
+		},
+	}
+	mockIAM := &mockIAMClient{
+		deleteRoleFunc: func(ctx context.Context, params *iam.DeleteRoleInput, optFns ...func(*iam.Options)) (*iam.DeleteRoleOutput, error) {
+			calls = append(calls, "role")
+			return &iam.DeleteRoleOutput{}, nil
+		},
+	}
+	mockCWLogs := &mockCloudWatchLogsClient{
+		deleteLogGroupFunc: func(ctx context.Context, params *cloudwatchlogs.DeleteLogGroupInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DeleteLogGroupOutput, error) {
+			calls = append(calls, "log-group")
+			return &cloudwatchlogs.DeleteLogGroupOutput{}, nil
+		},
+	}
+
+	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
+	deployer := NewDeployer(mockLambda, mockIAM, mockCWLogs, rollbackConfig())
+	deployer.resources = []ResourceRecord{
+		{Type: ResourceTypeExecutionRole, Identifier: rollbackRoleARN, Action: ResourceActionCreated},
+		{Type: ResourceTypeFunction, Identifier: functionARN, Action: ResourceActionCreated},
+		{Type: ResourceTypeLogGroup, Identifier: "/aws/lambda/test-function", Action: ResourceActionUpdated},
+	}
+
+	// Cancelled deployments are still rolled back
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := deployer.handleFailure(ctx, errors.New("publish failed"))
+
+	var failure *PartialFailureError
+	require.ErrorAs(t, err, &failure)
+	assert.Equal(t, []string{"function", "role"}, calls, "only created resources are deleted, newest first")
+	require.Len(t, failure.Remaining, 1)
+	assert.Equal(t, functionARN, failure.Remaining[0].Identifier)
+	require.Len(t, failure.RollbackErrors, 1)
+	assert.Contains(t, failure.RollbackErrors[0].Error(), "throttled")
+	assert.Equal(t, []string{
+		"aws lambda delete-function --function-name " + functionARN + " --region us-east-1",
+	}, failure.CleanupPlan())
+}
+
+func TestRoleNameFromARN(t *testing.T) {
+	assert.Equal(t, "test-role", roleNameFromARN("arn:aws:iam::123456789012:role/test-role"))
+	assert.Equal(t, "test-role", roleNameFromARN("arn:aws:iam::123456789012:role/service/test-role"))
+	assert.Equal(t, "not-an-arn", roleNameFromARN("not-an-arn"))
+}
@@ -0,0 +1,116 @@
+package deployer
+
+import (
+	"fmt"
+	"strings"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// SupportedRuntimes lists the custom runtimes the provisioner can run on, newest first
+var SupportedRuntimes = []lambdaTypes.Runtime{
+	lambdaTypes.RuntimeProvidedal2023,
+	lambdaTypes.RuntimeProvidedal2,
+}
+
+// regionRuntimes lists the runtimes available in regions that lag behind the commercial
+// regions. Regions not listed offer every supported runtime.
+var regionRuntimes = map[string][]lambdaTypes.Runtime{
+	"us-gov-east-1":  {lambdaTypes.RuntimeProvidedal2},
+	"us-gov-west-1":  {lambdaTypes.RuntimeProvidedal2},
+	"cn-north-1":     {lambdaTypes.RuntimeProvidedal2},
+	"cn-northwest-1": {lambdaTypes.RuntimeProvidedal2},
+}
+
+// ParseRuntime parses a supported runtime identifier such as provided.al2023
+func ParseRuntime(value string) (lambdaTypes.Runtime, error) {
+	for _, runtime := range SupportedRuntimes {
+		if string(runtime) == value {
+			return runtime, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported runtime %q (expected %s)", value, supportedRuntimeList())
+}
+
+// DefaultRuntime returns the newest supported runtime available in region
+func DefaultRuntime(region string) lambdaTypes.Runtime {
+	return AvailableRuntimes(region)[0]
+}
+
+// AvailableRuntimes returns the supported runtimes available in region, newest first
+func AvailableRuntimes(region string) []lambdaTypes.Runtime {
+	if runtimes, ok := regionRuntimes[region]; ok {
+		return runtimes
+	}
+	return SupportedRuntimes
+}
+
+// ValidateRuntimeAvailability checks that runtime is available in region
+func ValidateRuntimeAvailability(runtime lambdaTypes.Runtime, region string) error {
+	for _, available := range AvailableRuntimes(region) {
+		if available == runtime {
+			return nil
+		}
+	}
+	return fmt.Errorf("runtime %s is not available in %s (available: %s)", runtime, region, runtimeList(AvailableRuntimes(region)))
+}
+
+// isSupportedRuntime reports whether runtime is one of SupportedRuntimes
+func isSupportedRuntime(runtime lambdaTypes.Runtime) bool {
+	_, err := ParseRuntime(string(runtime))
+	return err == nil
+}
+
+// supportedRuntimeList renders SupportedRuntimes for error messages
+func supportedRuntimeList() string {
+	return runtimeList(SupportedRuntimes)
+}
+
+func runtimeList(runtimes []lambdaTypes.Runtime) string {
+	names := make([]string, 0, len(runtimes))
+	for _, runtime := range runtimes {
+		names = append(names, string(runtime))
+	}
+	return strings.Join(names, ", ")
+}
+
+// snapStartRuntimes lists the runtimes Lambda SnapStart supports. Custom runtimes,
+// including every runtime in SupportedRuntimes, are not among them.
+var snapStartRuntimes = []lambdaTypes.Runtime{
+	lambdaTypes.RuntimeJava11,
+	lambdaTypes.RuntimeJava17,
+	lambdaTypes.RuntimeJava21,
+	lambdaTypes.RuntimePython312,
+	// Newer than the SDK's Runtime constants
+	"python3.13",
+	"dotnet8",
+}
+
+// SupportsSnapStart reports whether Lambda SnapStart is available for runtime
+func SupportsSnapStart(runtime lambdaTypes.Runtime) bool {
+	for _, supported := range snapStartRuntimes {
+		if runtime == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateSnapStart checks that SnapStart can be enabled with the given runtime,
+// ephemeral storage (MB, zero for the default), and version publishing
+func ValidateSnapStart(runtime lambdaTypes.Runtime, ephemeralStorage int32, publishVersion bool) error {
+	if !SupportsSnapStart(runtime) {
+		name := string(runtime)
+		if name == "" {
+			name = "(unset)"
+		}
+		return fmt.Errorf("SnapStart is not supported by runtime %s (supported: %s)", name, runtimeList(snapStartRuntimes))
+	}
+	if ephemeralStorage > MinEphemeralStorage {
+		return fmt.Errorf("SnapStart does not support ephemeral storage above %d MB, got %d MB", MinEphemeralStorage, ephemeralStorage)
+	}
+	if !publishVersion {
+		return fmt.Errorf("SnapStart only applies to published versions; enable version publishing")
+	}
+	return nil
+}
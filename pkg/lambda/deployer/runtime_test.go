@@ -0,0 +1,67 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRuntime(t *testing.T) {
+	runtime, err := ParseRuntime("provided.al2")
+	require.NoError(t, err)
+	assert.Equal(t, lambdaTypes.RuntimeProvidedal2, runtime)
+
+	_, err = ParseRuntime("go1.x")
+	assert.EqualError(t, err, `unsupported runtime "go1.x" (expected provided.al2023, provided.al2)`)
+}
+
+func TestDefaultRuntime(t *testing.T) {
+	assert.Equal(t, lambdaTypes.RuntimeProvidedal2023, DefaultRuntime("us-east-1"))
+	assert.Equal(t, lambdaTypes.RuntimeProvidedal2, DefaultRuntime("us-gov-west-1"))
+	assert.Equal(t, lambdaTypes.RuntimeProvidedal2, DefaultRuntime("cn-north-1"))
+}
+
+func TestValidateRuntimeAvailability(t *testing.T) {
+	assert.NoError(t, ValidateRuntimeAvailability(lambdaTypes.RuntimeProvidedal2023, "eu-west-1"))
+	assert.NoError(t, ValidateRuntimeAvailability(lambdaTypes.RuntimeProvidedal2, "eu-west-1"))
+	assert.NoError(t, ValidateRuntimeAvailability(lambdaTypes.RuntimeProvidedal2, "us-gov-east-1"))
+
+	err := ValidateRuntimeAvailability(lambdaTypes.RuntimeProvidedal2023, "us-gov-east-1")
+	assert.EqualError(t, err, "runtime provided.al2023 is not available in us-gov-east-1 (available: provided.al2)")
+}
+
+func TestDeploy_UnsupportedRuntime(t *testing.T) {
+	config := rollbackConfig()
+	config.Runtime = lambdaTypes.RuntimeGo1x
+
+	deployer := NewDeployer(&mockLambdaClient{}, &mockIAMClient{}, &mockCloudWatchLogsClient{}, config)
+	_, err := deployer.Deploy(context.Background())
+
+	assert.ErrorContains(t, err, `unsupported runtime "go1.x"`)
+}
+
+func TestValidateSnapStart(t *testing.T) {
+	assert.NoError(t, ValidateSnapStart(lambdaTypes.RuntimeJava21, 0, true))
+	assert.NoError(t, ValidateSnapStart(lambdaTypes.RuntimePython312, 512, true))
+
+	err := ValidateSnapStart(lambdaTypes.RuntimeProvidedal2023, 0, true)
+	assert.ErrorContains(t, err, "SnapStart is not supported by runtime provided.al2023")
+	assert.ErrorContains(t, ValidateSnapStart("", 0, true), "runtime (unset)")
+	assert.ErrorContains(t, ValidateSnapStart(lambdaTypes.RuntimeJava21, 1024, true), "ephemeral storage above 512 MB")
+	assert.ErrorContains(t, ValidateSnapStart(lambdaTypes.RuntimeJava21, 0, false), "published versions")
+}
+
+func TestDeploy_SnapStartUnsupportedRuntime(t *testing.T) {
+	config := rollbackConfig()
+	config.Runtime = lambdaTypes.RuntimeProvidedal2023
+	config.PublishVersion = true
+	config.SnapStart = true
+
+	deployer := NewDeployer(&mockLambdaClient{}, &mockIAMClient{}, &mockCloudWatchLogsClient{}, config)
+	_, err := deployer.Deploy(context.Background())
+
+	assert.ErrorContains(t, err, "SnapStart is not supported by runtime provided.al2023")
+}
@@ -0,0 +1,309 @@
+package deployer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+// Settings recorded in a Snapshot. Environment variables and tags are recorded
+// under a prefix followed by their name, e.g. function.tag.team.
+const (
+	SettingFunctionARN       = "function.arn"
+	SettingRuntime           = "function.runtime"
+	SettingMemory            = "function.memory"
+	SettingTimeout           = "function.timeout"
+	SettingArchitecture      = "function.architecture"
+	SettingEphemeralStorage  = "function.ephemeral-storage"
+	SettingTracing           = "function.tracing"
+	SettingExecutionRole     = "function.execution-role"
+	SettingLogGroup          = "function.log-group"
+	SettingCodeSha256        = "function.code-sha256"
+	SettingPackageChecksum   = "function.package-checksum"
+	SettingCLIVersion        = "function.cli-version"
+	SettingEnvironmentPrefix = "function.environment."
+	SettingFunctionTagPrefix = "function.tag."
+	SettingResourcePolicy    = "function.resource-policy"
+	SettingTrustPolicy       = "role.trust-policy"
+	SettingPermissionsPolicy = "role.permissions-policy"
+	SettingRoleTagPrefix     = "role.tag."
+	SettingLogRetention      = "log-group.retention"
+)
+
+// Placeholders replacing a snapshot's account ID and region when snapshots are compared
+const (
+	accountIDPlaceholder = "${AccountId}"
+	regionPlaceholder    = "${Region}"
+)
+
+// SnapshotLambdaAPI is the subset of the Lambda API used to snapshot a deployment
+type SnapshotLambdaAPI interface {
+	GetFunction(ctx context.Context, params *lambda.GetFunctionInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
+	GetPolicy(ctx context.Context, params *lambda.GetPolicyInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error)
+}
+
+// SnapshotIAMAPI is the subset of the IAM API used to snapshot a deployment
+type SnapshotIAMAPI interface {
+	GetRole(ctx context.Context, params *iam.GetRoleInput,
+		optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	GetRolePolicy(ctx context.Context, params *iam.GetRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error)
+}
+
+// SnapshotLogsAPI is the subset of the CloudWatch Logs API used to snapshot a deployment
+type SnapshotLogsAPI interface {
+	DescribeLogGroups(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error)
+}
+
+// Snapshot is the configuration of a deployment as named settings, so two
+// deployments, or a deployment and its manifest, can be compared
+type Snapshot struct {
+	FunctionName string            `json:"function_name"`
+	Region       string            `json:"region"`
+	AccountID    string            `json:"account_id,omitempty"`
+	Settings     map[string]string `json:"settings"`
+
+	// Partial is set for snapshots that only record some settings, such as one built
+	// from a deployment manifest; settings they do not record are not compared
+	Partial bool `json:"partial,omitempty"`
+}
+
+// Difference is a setting whose value differs between two snapshots. A value is
+// empty when the setting is not present in that snapshot.
+type Difference struct {
+	Setting  string `json:"setting"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+// TakeSnapshot reads the live configuration of the deployed function, its resource
+// policy, execution role, and log group. Tags rosactl rewrites on every deploy, such
+// as rosa:deployed-at, are left out. keys is the format the function's tags were written in.
+func TakeSnapshot(ctx context.Context, lambdaClient SnapshotLambdaAPI, iamClient SnapshotIAMAPI, logsClient SnapshotLogsAPI,
+	functionName string, keys tagkey.Format) (*Snapshot, error) {
+	output, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function %s: %w", functionName, err)
+	}
+	cfg := output.Configuration
+	if cfg == nil {
+		return nil, fmt.Errorf("function %s has no configuration", functionName)
+	}
+
+	snapshot := &Snapshot{FunctionName: functionName, Settings: make(map[string]string)}
+	functionARN := aws.ToString(cfg.FunctionArn)
+	if parsed, err := arn.Parse(functionARN); err == nil {
+		snapshot.Region = parsed.Region
+		snapshot.AccountID = parsed.AccountID
+	}
+	set := func(setting, value string) {
+		if value != "" {
+			snapshot.Settings[setting] = value
+		}
+	}
+
+	set(SettingFunctionARN, functionARN)
+	set(SettingRuntime, string(cfg.Runtime))
+	set(SettingMemory, int32String(cfg.MemorySize))
+	set(SettingTimeout, int32String(cfg.Timeout))
+	architectures := make([]string, 0, len(cfg.Architectures))
+	for _, architecture := range cfg.Architectures {
+		architectures = append(architectures, string(architecture))
+	}
+	set(SettingArchitecture, strings.Join(architectures, ","))
+	if cfg.EphemeralStorage != nil {
+		set(SettingEphemeralStorage, int32String(cfg.EphemeralStorage.Size))
+	}
+	if cfg.TracingConfig != nil {
+		set(SettingTracing, string(cfg.TracingConfig.Mode))
+	}
+	set(SettingExecutionRole, aws.ToString(cfg.Role))
+	set(SettingLogGroup, FunctionLogGroupName(cfg, functionName))
+	set(SettingCodeSha256, aws.ToString(cfg.CodeSha256))
+	if cfg.Environment != nil {
+		for name, value := range cfg.Environment.Variables {
+			set(SettingEnvironmentPrefix+name, value)
+		}
+	}
+
+	stamp := ReadStamp(output, keys)
+	set(SettingPackageChecksum, stamp.PackageChecksum)
+	set(SettingCLIVersion, stamp.CLIVersion)
+	for key, value := range output.Tags {
+		if isVolatileTag(key, keys) {
+			continue
+		}
+		set(SettingFunctionTagPrefix+key, value)
+	}
+
+	policyOutput, err := lambdaClient.GetPolicy(ctx, &lambda.GetPolicyInput{FunctionName: aws.String(functionName)})
+	var notFoundErr *lambdaTypes.ResourceNotFoundException
+	if err != nil && !errors.As(err, &notFoundErr) {
+		return nil, fmt.Errorf("failed to get function policy: %w", err)
+	}
+	if err == nil {
+		statements, err := parseFunctionPolicy(aws.ToString(policyOutput.Policy))
+		if err != nil {
+			return nil, err
+		}
+		if statement := findStatement(statements, resourcePolicyStatementID); statement != nil {
+			set(SettingResourcePolicy, fmt.Sprintf("%s %s to %s from %s",
+				statement.Effect, statement.Action, statement.Principal, valueOrNone(statement.SourceArn)))
+		}
+	}
+
+	if err := snapshotRole(ctx, iamClient, roleNameFromARN(aws.ToString(cfg.Role)), keys, set); err != nil {
+		return nil, err
+	}
+
+	logGroupName := FunctionLogGroupName(cfg, functionName)
+	logGroups, err := logsClient.DescribeLogGroups(ctx, &cloudwatchlogs.DescribeLogGroupsInput{
+		LogGroupNamePrefix: aws.String(logGroupName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe log group %s: %w", logGroupName, err)
+	}
+	for _, group := range logGroups.LogGroups {
+		if aws.ToString(group.LogGroupName) != logGroupName {
+			continue
+		}
+		retention := "never expire"
+		if group.RetentionInDays != nil {
+			retention = fmt.Sprintf("%d days", *group.RetentionInDays)
+		}
+		set(SettingLogRetention, retention)
+	}
+
+	return snapshot, nil
+}
+
+// snapshotRole records the execution role's trust policy, permissions policy, and tags
+func snapshotRole(ctx context.Context, client SnapshotIAMAPI, roleName string, keys tagkey.Format, set func(setting, value string)) error {
+	var notFoundErr *iamTypes.NoSuchEntityException
+	role, err := client.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+	if err != nil {
+		if errors.As(err, &notFoundErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to get role %s: %w", roleName, err)
+	}
+	set(SettingTrustPolicy, compactPolicy(aws.ToString(role.Role.AssumeRolePolicyDocument)))
+	for _, tag := range role.Role.Tags {
+		if isVolatileTag(aws.ToString(tag.Key), keys) {
+			continue
+		}
+		set(SettingRoleTagPrefix+aws.ToString(tag.Key), aws.ToString(tag.Value))
+	}
+
+	policy, err := client.GetRolePolicy(ctx, &iam.GetRolePolicyInput{
+		RoleName:   aws.String(roleName),
+		PolicyName: aws.String(permissionsPolicyName),
+	})
+	if err != nil {
+		if errors.As(err, &notFoundErr) {
+			return nil
+		}
+		return fmt.Errorf("failed to get role policy %s: %w", permissionsPolicyName, err)
+	}
+	set(SettingPermissionsPolicy, compactPolicy(aws.ToString(policy.PolicyDocument)))
+	return nil
+}
+
+// isVolatileTag reports whether a tag changes on every deploy, or is recorded in a
+// setting of its own, and so is left out of snapshots
+func isVolatileTag(key string, keys tagkey.Format) bool {
+	for _, volatile := range []string{DeployedAtTagKey, DeployedByTagKey, CLIVersionTagKey, PackageChecksumTagKey} {
+		if keys.Matches(key, volatile) {
+			return true
+		}
+	}
+	return false
+}
+
+// compactPolicy decodes an IAM policy document, which IAM returns URL-encoded, and
+// re-encodes it compactly with sorted keys so equal policies compare equal
+func compactPolicy(document string) string {
+	if decoded, err := url.QueryUnescape(document); err == nil {
+		document = decoded
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(document), &value); err != nil {
+		return document
+	}
+	compact, err := json.Marshal(value)
+	if err != nil {
+		return document
+	}
+	return string(compact)
+}
+
+// valueOrNone returns "none" for an unset value in a rendered setting
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// normalized returns the snapshot's settings with its account ID and region replaced
+// by placeholders, so deployments in different accounts or regions compare equal
+func (s *Snapshot) normalized() map[string]string {
+	replacements := make([]string, 0, 4)
+	if s.AccountID != "" {
+		replacements = append(replacements, s.AccountID, accountIDPlaceholder)
+	}
+	if s.Region != "" {
+		replacements = append(replacements, s.Region, regionPlaceholder)
+	}
+	replacer := strings.NewReplacer(replacements...)
+
+	settings := make(map[string]string, len(s.Settings))
+	for setting, value := range s.Settings {
+		settings[setting] = replacer.Replace(value)
+	}
+	return settings
+}
+
+// CompareSnapshots lists the settings whose values differ between expected and
+// actual, sorted by setting. Each snapshot's account ID and region are replaced by
+// placeholders first, so two regions configured identically have no differences.
+// Settings a partial snapshot does not record are not compared.
+func CompareSnapshots(expected, actual *Snapshot) []Difference {
+	expectedSettings := expected.normalized()
+	actualSettings := actual.normalized()
+
+	settings := slices.AppendSeq(slices.Collect(maps.Keys(expectedSettings)), maps.Keys(actualSettings))
+	slices.Sort(settings)
+
+	var differences []Difference
+	for _, setting := range slices.Compact(settings) {
+		expectedValue, inExpected := expectedSettings[setting]
+		actualValue, inActual := actualSettings[setting]
+		if (expected.Partial && !inExpected) || (actual.Partial && !inActual) {
+			continue
+		}
+		if expectedValue != actualValue {
+			differences = append(differences, Difference{Setting: setting, Expected: expectedValue, Actual: actualValue})
+		}
+	}
+	return differences
+}
@@ -0,0 +1,149 @@
+package deployer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// snapshotClients returns clients for a deployment of test-function in region and
+// account with the given memory size
+func snapshotClients(region, accountID string, memory int32) (*mockLambdaClient, *mockIAMClient, *mockCloudWatchLogsClient) {
+	functionARN := fmt.Sprintf("arn:aws:lambda:%s:%s:function:test-function", region, accountID)
+	roleARN := fmt.Sprintf("arn:aws:iam::%s:role/test-role", accountID)
+
+	lambdaClient := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{
+					FunctionArn:   aws.String(functionARN),
+					Runtime:       lambdaTypes.RuntimeProvidedal2023,
+					MemorySize:    aws.Int32(memory),
+					Timeout:       aws.Int32(60),
+					Architectures: []lambdaTypes.Architecture{lambdaTypes.ArchitectureX8664},
+					Role:          aws.String(roleARN),
+					CodeSha256:    aws.String("q1w2e3"),
+					Environment: &lambdaTypes.EnvironmentResponse{
+						Variables: map[string]string{"ROSA_FUNCTION_ARN": functionARN},
+					},
+				},
+				Tags: map[string]string{
+					ManagedTagKey:         ManagedTagValue,
+					PackageChecksumTagKey: "abc123",
+					DeployedAtTagKey:      "2026-03-10T14:30:00Z-" + region,
+					"team":                "rosa",
+				},
+			}, nil
+		},
+		getPolicyFunc: func(ctx context.Context, params *lambda.GetPolicyInput, optFns ...func(*lambda.Options)) (*lambda.GetPolicyOutput, error) {
+			return &lambda.GetPolicyOutput{Policy: aws.String(functionPolicyDocument(testCLMRole))}, nil
+		},
+	}
+
+	iamClient := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			if aws.ToString(params.RoleName) != "test-role" {
+				return nil, &iamTypes.NoSuchEntityException{}
+			}
+			return &iam.GetRoleOutput{Role: &iamTypes.Role{
+				Arn:                      aws.String(roleARN),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(`{"Version": "2012-10-17", "Statement": []}`)),
+				Tags:                     []iamTypes.Tag{{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)}},
+			}}, nil
+		},
+		getRolePolicyFunc: func(ctx context.Context, params *iam.GetRolePolicyInput, optFns ...func(*iam.Options)) (*iam.GetRolePolicyOutput, error) {
+			return &iam.GetRolePolicyOutput{PolicyDocument: aws.String(url.QueryEscape(fmt.Sprintf(
+				`{"Statement": [{"Effect": "Allow", "Action": "logs:PutLogEvents", "Resource": "arn:aws:logs:%s:%s:*"}]}`, region, accountID)))}, nil
+		},
+	}
+
+	logsClient := &mockCloudWatchLogsClient{
+		describeLogGroupsFunc: func(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+			return &cloudwatchlogs.DescribeLogGroupsOutput{LogGroups: []cwTypes.LogGroup{
+				{LogGroupName: aws.String("/aws/lambda/test-function"), RetentionInDays: aws.Int32(90)},
+				{LogGroupName: aws.String("/aws/lambda/test-function-canary")},
+			}}, nil
+		},
+	}
+
+	return lambdaClient, iamClient, logsClient
+}
+
+func takeTestSnapshot(t *testing.T, region, accountID string, memory int32) *Snapshot {
+	lambdaClient, iamClient, logsClient := snapshotClients(region, accountID, memory)
+	snapshot, err := TakeSnapshot(context.Background(), lambdaClient, iamClient, logsClient, "test-function", tagkey.Format{})
+	require.NoError(t, err)
+	return snapshot
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	snapshot := takeTestSnapshot(t, "us-east-1", "123456789012", 128)
+
+	assert.Equal(t, "us-east-1", snapshot.Region)
+	assert.Equal(t, "123456789012", snapshot.AccountID)
+	assert.Equal(t, "128", snapshot.Settings[SettingMemory])
+	assert.Equal(t, "x86_64", snapshot.Settings[SettingArchitecture])
+	assert.Equal(t, "/aws/lambda/test-function", snapshot.Settings[SettingLogGroup])
+	assert.Equal(t, "abc123", snapshot.Settings[SettingPackageChecksum])
+	assert.Equal(t, "rosa", snapshot.Settings[SettingFunctionTagPrefix+"team"])
+	assert.Equal(t, "90 days", snapshot.Settings[SettingLogRetention])
+	assert.Equal(t, `{"Statement":[],"Version":"2012-10-17"}`, snapshot.Settings[SettingTrustPolicy], "policies are decoded and compacted")
+	assert.Equal(t, "Allow lambda:InvokeFunction to arn:aws:iam::987654321098:root from "+testCLMRole, snapshot.Settings[SettingResourcePolicy])
+
+	assert.NotContains(t, snapshot.Settings, SettingFunctionTagPrefix+DeployedAtTagKey, "tags rewritten on every deploy are left out")
+	assert.NotContains(t, snapshot.Settings, SettingFunctionTagPrefix+PackageChecksumTagKey, "the stamp is recorded in its own settings")
+	assert.NotContains(t, snapshot.Settings, SettingTracing)
+}
+
+func TestTakeSnapshot_MissingRolePolicy(t *testing.T) {
+	lambdaClient, iamClient, logsClient := snapshotClients("us-east-1", "123456789012", 128)
+	lambdaClient.getPolicyFunc = nil
+	iamClient.getRolePolicyFunc = nil
+
+	snapshot, err := TakeSnapshot(context.Background(), lambdaClient, iamClient, logsClient, "test-function", tagkey.Format{})
+	require.NoError(t, err)
+	assert.NotContains(t, snapshot.Settings, SettingPermissionsPolicy)
+	assert.NotContains(t, snapshot.Settings, SettingResourcePolicy)
+}
+
+func TestCompareSnapshots_Regions(t *testing.T) {
+	east := takeTestSnapshot(t, "us-east-1", "123456789012", 128)
+
+	assert.Empty(t, CompareSnapshots(east, takeTestSnapshot(t, "us-west-2", "210987654321", 128)),
+		"identically configured regions in different accounts have no differences")
+
+	assert.Equal(t, []Difference{{Setting: SettingMemory, Expected: "128", Actual: "256"}},
+		CompareSnapshots(east, takeTestSnapshot(t, "us-west-2", "123456789012", 256)))
+}
+
+func TestCompareSnapshots_Partial(t *testing.T) {
+	live := takeTestSnapshot(t, "us-east-1", "123456789012", 128)
+
+	recorded := &Snapshot{
+		FunctionName: "test-function",
+		Region:       "us-east-1",
+		AccountID:    "123456789012",
+		Partial:      true,
+		Settings: map[string]string{
+			SettingFunctionARN:     "arn:aws:lambda:us-east-1:123456789012:function:test-function",
+			SettingPackageChecksum: "def456",
+		},
+	}
+	assert.Equal(t, []Difference{{Setting: SettingPackageChecksum, Expected: "def456", Actual: "abc123"}},
+		CompareSnapshots(recorded, live), "settings the partial snapshot does not record are not compared")
+
+	delete(live.Settings, SettingPackageChecksum)
+	assert.Equal(t, []Difference{{Setting: SettingPackageChecksum, Expected: "def456"}}, CompareSnapshots(recorded, live))
+}
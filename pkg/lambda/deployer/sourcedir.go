@@ -0,0 +1,133 @@
+package deployer
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultSourceDir is the OIDC provisioner source, relative to the rosactl module root
+var DefaultSourceDir = filepath.Join("pkg", "lambda", "functions", "oidc-provisioner")
+
+// ResolveSourceDir returns the absolute path of a Lambda source directory. Absolute
+// paths are used as given. Relative paths are tried against the working directory,
+// then against the root of the Go module containing the working directory, then
+// against the module root containing the rosactl executable, so the default works
+// from any directory of a checkout and from a binary built inside one.
+func ResolveSourceDir(dir string) (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		executable = ""
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine working directory: %w", err)
+	}
+	return resolveSourceDir(dir, workDir, executable)
+}
+
+func resolveSourceDir(dir, workDir, executable string) (string, error) {
+	if filepath.IsAbs(dir) {
+		return filepath.Clean(dir), nil
+	}
+
+	bases := []string{workDir}
+	if root := moduleRoot(workDir); root != "" {
+		bases = append(bases, root)
+	}
+	if executable != "" {
+		if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+			executable = resolved
+		}
+		if root := moduleRoot(filepath.Dir(executable)); root != "" {
+			bases = append(bases, root)
+		}
+	}
+
+	var tried []string
+	for _, base := range bases {
+		candidate := filepath.Join(base, dir)
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+		tried = append(tried, candidate)
+	}
+
+	return "", fmt.Errorf("source directory %s not found (tried %s); run rosactl from the regional-cli repository or pass --source-dir",
+		dir, strings.Join(dedupe(tried), ", "))
+}
+
+// moduleRoot returns the nearest directory at or above dir that contains a go.mod
+// file, or an empty string
+func moduleRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// dedupe removes repeated entries, keeping the first occurrence
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+// ValidateSourceDir checks that dir exists and holds a main package, so a wrong
+// --source-dir fails with an explanation instead of a go build error
+func ValidateSourceDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return fmt.Errorf("source directory %s does not exist", dir)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("source directory %s is not a directory", dir)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return fmt.Errorf("failed to list Go files in %s: %w", dir, err)
+	}
+
+	packages := make(map[string]bool)
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), file, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		packages[parsed.Name.Name] = true
+	}
+
+	if packages["main"] {
+		return nil
+	}
+	if len(packages) == 0 {
+		return fmt.Errorf("source directory %s contains no Go source files", dir)
+	}
+	names := make([]string, 0, len(packages))
+	for name := range packages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("source directory %s contains package %s, not a main package; point --source-dir at the Lambda function's main package",
+		dir, strings.Join(names, ", "))
+}
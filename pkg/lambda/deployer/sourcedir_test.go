@@ -0,0 +1,68 @@
+package deployer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeModule(t *testing.T, root string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example\n\ngo 1.23\n"), 0644))
+	sourceDir := filepath.Join(root, DefaultSourceDir)
+	require.NoError(t, os.MkdirAll(sourceDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sourceDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644))
+	return sourceDir
+}
+
+func TestResolveSourceDir(t *testing.T) {
+	root := t.TempDir()
+	sourceDir := writeModule(t, root)
+	nested := filepath.Join(root, "internal", "cli")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+
+	// From the module root
+	resolved, err := resolveSourceDir(DefaultSourceDir, root, "")
+	require.NoError(t, err)
+	assert.Equal(t, sourceDir, resolved)
+
+	// From a subdirectory of the module
+	resolved, err = resolveSourceDir(DefaultSourceDir, nested, "")
+	require.NoError(t, err)
+	assert.Equal(t, sourceDir, resolved)
+
+	// From outside the module, via the executable's location
+	resolved, err = resolveSourceDir(DefaultSourceDir, t.TempDir(), filepath.Join(root, "bin", "rosactl"))
+	require.NoError(t, err)
+	assert.Equal(t, sourceDir, resolved)
+
+	// Absolute paths are used as given
+	resolved, err = resolveSourceDir(sourceDir+string(filepath.Separator), t.TempDir(), "")
+	require.NoError(t, err)
+	assert.Equal(t, sourceDir, resolved)
+}
+
+func TestResolveSourceDir_NotFound(t *testing.T) {
+	workDir := t.TempDir()
+
+	_, err := resolveSourceDir(DefaultSourceDir, workDir, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(workDir, DefaultSourceDir))
+	assert.Contains(t, err.Error(), "--source-dir")
+}
+
+func TestValidateSourceDir(t *testing.T) {
+	assert.NoError(t, ValidateSourceDir("../functions/oidc-provisioner"))
+
+	err := ValidateSourceDir(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorContains(t, err, "does not exist")
+
+	empty := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(empty, "main_test.go"), []byte("package main\n"), 0644))
+	assert.ErrorContains(t, ValidateSourceDir(empty), "contains no Go source files")
+
+	assert.ErrorContains(t, ValidateSourceDir("."), "contains package deployer, not a main package")
+}
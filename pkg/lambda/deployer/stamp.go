@@ -0,0 +1,181 @@
+package deployer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+const (
+	// CLIVersionTagKey and PackageChecksumTagKey record which rosactl release deployed
+	// the function and the SHA256 of the package it uploaded
+	CLIVersionTagKey      = "rosa:cli-version"
+	PackageChecksumTagKey = "rosa:package-checksum"
+
+	// functionDescriptionBase is the function description before the stamp is appended
+	functionDescriptionBase = "ROSA OIDC provider provisioner"
+
+	// stampChecksumLength is how much of the checksum is shown in the function description
+	stampChecksumLength = 12
+)
+
+// Stamp identifies the rosactl release and package behind a deployment
+type Stamp struct {
+	CLIVersion      string
+	PackageChecksum string // Hex-encoded SHA256 of the deployment package
+}
+
+// FunctionDescription renders the Lambda function description for s, so the
+// deploying release is visible in the console without looking at tags
+func FunctionDescription(s Stamp) string {
+	switch {
+	case s.CLIVersion != "" && s.PackageChecksum != "":
+		return fmt.Sprintf("%s (rosactl %s, sha256 %s)", functionDescriptionBase, s.CLIVersion, shortChecksum(s.PackageChecksum))
+	case s.CLIVersion != "":
+		return fmt.Sprintf("%s (rosactl %s)", functionDescriptionBase, s.CLIVersion)
+	case s.PackageChecksum != "":
+		return fmt.Sprintf("%s (sha256 %s)", functionDescriptionBase, shortChecksum(s.PackageChecksum))
+	}
+	return functionDescriptionBase
+}
+
+// IsProvisionerDescription reports whether a function description was rendered by
+// FunctionDescription, identifying provisioner functions in a function listing
+func IsProvisionerDescription(description string) bool {
+	return strings.HasPrefix(description, functionDescriptionBase)
+}
+
+// shortChecksum abbreviates a checksum for display
+func shortChecksum(checksum string) string {
+	if len(checksum) > stampChecksumLength {
+		return checksum[:stampChecksumLength]
+	}
+	return checksum
+}
+
+// ReadStamp returns the stamp recorded in a function's tags, written in the keys format
+func ReadStamp(function *lambda.GetFunctionOutput, keys tagkey.Format) Stamp {
+	if function == nil {
+		return Stamp{}
+	}
+	cliVersion, _ := keys.Lookup(function.Tags, CLIVersionTagKey)
+	checksum, _ := keys.Lookup(function.Tags, PackageChecksumTagKey)
+	return Stamp{CLIVersion: cliVersion, PackageChecksum: checksum}
+}
+
+// Drift fields reported by DetectDrift
+const (
+	DriftFieldCLIVersion      = "cli-version"
+	DriftFieldPackageChecksum = "package-checksum"
+	DriftFieldCode            = "code"
+)
+
+// Drift describes one difference between the expected and deployed function
+type Drift struct {
+	Field    string
+	Expected string
+	Actual   string
+	Message  string
+}
+
+// DriftReport is the result of comparing a deployed function against an expected stamp
+type DriftReport struct {
+	FunctionARN string
+	Deployed    Stamp  // Stamp read from the function's tags
+	CodeSha256  string // Base64-encoded SHA256 Lambda reports for the deployed code
+	Drifts      []Drift
+}
+
+// HasDrift reports whether any differences were found
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Drifts) > 0
+}
+
+// FunctionGetter is the subset of the Lambda API used to read a function
+type FunctionGetter interface {
+	GetFunction(ctx context.Context, params *lambda.GetFunctionInput,
+		optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error)
+}
+
+// DetectDrift compares the deployed function against expected. Empty fields of
+// expected are not compared. Independently of expected, the code Lambda is running
+// is checked against the stamped package checksum to catch code uploaded outside rosactl.
+// keys is the format the function's tags were written in.
+func DetectDrift(ctx context.Context, client FunctionGetter, functionName string, expected Stamp, keys tagkey.Format) (*DriftReport, error) {
+	output, err := client.GetFunction(ctx, &lambda.GetFunctionInput{
+		FunctionName: aws.String(functionName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function %s: %w", functionName, err)
+	}
+
+	report := &DriftReport{Deployed: ReadStamp(output, keys)}
+	if output.Configuration != nil {
+		report.FunctionARN = aws.ToString(output.Configuration.FunctionArn)
+		report.CodeSha256 = aws.ToString(output.Configuration.CodeSha256)
+	}
+	report.Drifts = CompareStamp(expected, report.Deployed, report.CodeSha256)
+
+	return report, nil
+}
+
+// CompareStamp lists the differences between an expected stamp, the stamp a function
+// carries, and the base64-encoded SHA256 of the code Lambda reports for it
+func CompareStamp(expected, deployed Stamp, codeSha256 string) []Drift {
+	var drifts []Drift
+
+	if expected.CLIVersion != "" && deployed.CLIVersion != expected.CLIVersion {
+		drifts = append(drifts, Drift{
+			Field:    DriftFieldCLIVersion,
+			Expected: expected.CLIVersion,
+			Actual:   deployed.CLIVersion,
+			Message:  stampMismatchMessage("rosactl version", expected.CLIVersion, deployed.CLIVersion),
+		})
+	}
+
+	if expected.PackageChecksum != "" && deployed.PackageChecksum != expected.PackageChecksum {
+		drifts = append(drifts, Drift{
+			Field:    DriftFieldPackageChecksum,
+			Expected: expected.PackageChecksum,
+			Actual:   deployed.PackageChecksum,
+			Message:  stampMismatchMessage("package checksum", expected.PackageChecksum, deployed.PackageChecksum),
+		})
+	}
+
+	if deployed.PackageChecksum != "" && codeSha256 != "" {
+		stamped, err := lambdaCodeSha256(deployed.PackageChecksum)
+		if err != nil || stamped != codeSha256 {
+			drifts = append(drifts, Drift{
+				Field:    DriftFieldCode,
+				Expected: stamped,
+				Actual:   codeSha256,
+				Message:  "function code does not match the package rosactl deployed; it was changed outside rosactl",
+			})
+		}
+	}
+
+	return drifts
+}
+
+// stampMismatchMessage describes a stamp field that differs, including when it is missing
+func stampMismatchMessage(field, expected, actual string) string {
+	if actual == "" {
+		return fmt.Sprintf("%s is not recorded on the function (expected %s)", field, expected)
+	}
+	return fmt.Sprintf("%s is %s, expected %s", field, actual, expected)
+}
+
+// lambdaCodeSha256 converts a hex-encoded SHA256 into the base64 form Lambda reports as CodeSha256
+func lambdaCodeSha256(checksum string) (string, error) {
+	raw, err := hex.DecodeString(checksum)
+	if err != nil {
+		return "", fmt.Errorf("invalid package checksum %q: %w", checksum, err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
@@ -0,0 +1,132 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testChecksum is the hex SHA256 of an empty package; testCodeSha256 is the same digest as Lambda reports it
+const (
+	testChecksum   = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	testCodeSha256 = "47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+)
+
+func TestFunctionDescription(t *testing.T) {
+	tests := []struct {
+		name  string
+		stamp Stamp
+		want  string
+	}{
+		{"unstamped", Stamp{}, "ROSA OIDC provider provisioner"},
+		{"version only", Stamp{CLIVersion: "0.1.0"}, "ROSA OIDC provider provisioner (rosactl 0.1.0)"},
+		{"checksum only", Stamp{PackageChecksum: testChecksum}, "ROSA OIDC provider provisioner (sha256 e3b0c44298fc)"},
+		{"version and checksum", Stamp{CLIVersion: "0.1.0", PackageChecksum: testChecksum},
+			"ROSA OIDC provider provisioner (rosactl 0.1.0, sha256 e3b0c44298fc)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FunctionDescription(tt.stamp)
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, len(got), 256, "Lambda limits descriptions to 256 characters")
+			assert.True(t, IsProvisionerDescription(got))
+		})
+	}
+
+	assert.False(t, IsProvisionerDescription("Image resizer"))
+}
+
+func TestCompareStamp(t *testing.T) {
+	deployed := Stamp{CLIVersion: "0.1.0", PackageChecksum: testChecksum}
+
+	t.Run("matching", func(t *testing.T) {
+		assert.Empty(t, CompareStamp(deployed, deployed, testCodeSha256))
+	})
+
+	t.Run("empty expectations are not compared", func(t *testing.T) {
+		assert.Empty(t, CompareStamp(Stamp{}, deployed, testCodeSha256))
+	})
+
+	t.Run("different release", func(t *testing.T) {
+		drifts := CompareStamp(Stamp{CLIVersion: "0.2.0"}, deployed, testCodeSha256)
+		require.Len(t, drifts, 1)
+		assert.Equal(t, DriftFieldCLIVersion, drifts[0].Field)
+		assert.Equal(t, "0.2.0", drifts[0].Expected)
+		assert.Equal(t, "0.1.0", drifts[0].Actual)
+		assert.Contains(t, drifts[0].Message, "rosactl version is 0.1.0, expected 0.2.0")
+	})
+
+	t.Run("unstamped function", func(t *testing.T) {
+		drifts := CompareStamp(deployed, Stamp{}, testCodeSha256)
+		require.Len(t, drifts, 2)
+		assert.Equal(t, DriftFieldCLIVersion, drifts[0].Field)
+		assert.Contains(t, drifts[0].Message, "not recorded")
+		assert.Equal(t, DriftFieldPackageChecksum, drifts[1].Field)
+	})
+
+	t.Run("code changed outside rosactl", func(t *testing.T) {
+		drifts := CompareStamp(Stamp{}, deployed, "bm90LXRoZS1zYW1lLWNvZGU=")
+		require.Len(t, drifts, 1)
+		assert.Equal(t, DriftFieldCode, drifts[0].Field)
+		assert.Equal(t, testCodeSha256, drifts[0].Expected)
+	})
+}
+
+func TestDetectDrift(t *testing.T) {
+	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			assert.Equal(t, "test-function", aws.ToString(params.FunctionName))
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{
+					FunctionArn: aws.String(functionARN),
+					CodeSha256:  aws.String(testCodeSha256),
+				},
+				Tags: map[string]string{
+					CLIVersionTagKey:      "0.1.0",
+					PackageChecksumTagKey: testChecksum,
+				},
+			}, nil
+		},
+	}
+
+	report, err := DetectDrift(context.Background(), mockLambda, "test-function", Stamp{CLIVersion: "0.2.0"}, tagkey.Format{})
+	require.NoError(t, err)
+	assert.Equal(t, functionARN, report.FunctionARN)
+	assert.Equal(t, Stamp{CLIVersion: "0.1.0", PackageChecksum: testChecksum}, report.Deployed)
+	assert.True(t, report.HasDrift())
+	require.Len(t, report.Drifts, 1)
+	assert.Equal(t, DriftFieldCLIVersion, report.Drifts[0].Field)
+}
+
+func TestDetectDrift_GetFunctionError(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return nil, errors.New("access denied")
+		},
+	}
+
+	_, err := DetectDrift(context.Background(), mockLambda, "test-function", Stamp{}, tagkey.Format{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access denied")
+}
+
+func TestReadStamp_TagKeyFormat(t *testing.T) {
+	function := &lambda.GetFunctionOutput{Tags: map[string]string{
+		"ROSA:CLI-VERSION":      "0.1.0",
+		"ROSA:PACKAGE-CHECKSUM": testChecksum,
+	}}
+
+	expected := Stamp{CLIVersion: "0.1.0", PackageChecksum: testChecksum}
+	assert.Equal(t, expected, ReadStamp(function, tagkey.Format{Case: tagkey.CaseUpper}))
+	// A case-only difference is found without the format
+	assert.Equal(t, expected, ReadStamp(function, tagkey.Format{}))
+}
@@ -0,0 +1,151 @@
+package deployer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/openshift-online/regional-cli/pkg/deploy"
+)
+
+// The function step's two halves, which can be selected separately so code can be
+// refreshed without reconciling the function's configuration or the reverse
+const (
+	StepFunctionCode   = "function-code"
+	StepFunctionConfig = "function-config"
+)
+
+// SelectableSteps are the steps StepSelection can name, in the order Deploy runs them.
+// Validation, building the package, and verifying the function always run when needed.
+var SelectableSteps = []string{
+	StepExecutionRole,
+	StepLedgerTable,
+	StepFunction,
+	StepFunctionCode,
+	StepFunctionConfig,
+	StepResourcePolicy,
+	StepLogGroup,
+	StepTagFunction,
+	StepPublishVersion,
+	StepPrime,
+}
+
+// StepSelection limits a deployment to some of its steps, for example to refresh the
+// function code in an account where the operator cannot change IAM. Only and Skip are
+// mutually exclusive; when both are empty every step runs. Naming StepFunction selects
+// both StepFunctionCode and StepFunctionConfig.
+type StepSelection struct {
+	Only []string
+	Skip []string
+}
+
+// Validate checks that the selection names known steps and does not set both Only and Skip
+func (s StepSelection) Validate() error {
+	if len(s.Only) > 0 && len(s.Skip) > 0 {
+		return fmt.Errorf("only one of the steps to run and the steps to skip may be set")
+	}
+	for _, step := range slices.Concat(s.Only, s.Skip) {
+		if !slices.Contains(SelectableSteps, step) {
+			return fmt.Errorf("unknown deployment step %q (expected one of %s)", step, strings.Join(SelectableSteps, ", "))
+		}
+	}
+	return nil
+}
+
+// IsEmpty reports whether every step runs
+func (s StepSelection) IsEmpty() bool {
+	return len(s.Only) == 0 && len(s.Skip) == 0
+}
+
+// Runs reports whether the selection includes step
+func (s StepSelection) Runs(step string) bool {
+	if s.IsEmpty() {
+		return true
+	}
+	names := []string{step}
+	if step == StepFunctionCode || step == StepFunctionConfig {
+		names = append(names, StepFunction)
+	}
+	named := false
+	for _, name := range names {
+		if slices.Contains(s.Only, name) || slices.Contains(s.Skip, name) {
+			named = true
+		}
+	}
+	return named == (len(s.Only) > 0)
+}
+
+// Skipped returns the selectable steps the selection leaves out, other than StepFunction,
+// whose halves are listed instead
+func (s StepSelection) Skipped() []string {
+	var skipped []string
+	for _, step := range SelectableSteps {
+		if step != StepFunction && !s.Runs(step) {
+			skipped = append(skipped, step)
+		}
+	}
+	return skipped
+}
+
+// runs reports whether the deployment runs step
+func (d *Deployer) runs(step string) bool {
+	return d.config.Steps.Runs(step)
+}
+
+// checkStepSelection refuses a selection that skips creating resources later steps
+// need: a function that does not exist can only be created with its code and its
+// configuration
+func (d *Deployer) checkStepSelection(exists bool) error {
+	if exists || (d.runs(StepFunctionCode) && d.runs(StepFunctionConfig)) {
+		return nil
+	}
+	return fmt.Errorf("function %s does not exist; creating it requires the %s and %s steps",
+		d.config.FunctionName, StepFunctionCode, StepFunctionConfig)
+}
+
+// skippedRoleARN returns the execution role ARN to deploy with when the execution-role
+// step is skipped, without calling IAM: the existing function's role, or the ARN of
+// the configured role in the target account
+func (d *Deployer) skippedRoleARN(existing *lambda.GetFunctionOutput) (string, error) {
+	if existing != nil && existing.Configuration != nil && existing.Configuration.Role != nil {
+		return aws.ToString(existing.Configuration.Role), nil
+	}
+	if !d.scope.IsScoped() {
+		return "", fmt.Errorf("the account ID is needed to name execution role %s when the %s step is skipped",
+			d.config.ExecutionRoleName, StepExecutionRole)
+	}
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", d.scope.partition(), d.scope.AccountID, d.config.ExecutionRoleName), nil
+}
+
+// selectedResources drops the resources of skipped steps from a plan
+func (d *Deployer) selectedResources(resources []deploy.Resource) []deploy.Resource {
+	var selected []deploy.Resource
+	for _, resource := range resources {
+		switch resource.(type) {
+		case *executionRoleResource:
+			if !d.runs(StepExecutionRole) {
+				continue
+			}
+		case *ledgerTableResource:
+			if !d.runs(StepLedgerTable) {
+				continue
+			}
+		case *functionResource:
+			if !d.runs(StepFunctionCode) && !d.runs(StepFunctionConfig) {
+				continue
+			}
+		case *resourcePolicyResource:
+			if !d.runs(StepResourcePolicy) {
+				continue
+			}
+		case *logGroupResource:
+			if !d.runs(StepLogGroup) {
+				continue
+			}
+		}
+		selected = append(selected, resource)
+	}
+	return selected
+}
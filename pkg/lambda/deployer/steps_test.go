@@ -0,0 +1,139 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepSelection(t *testing.T) {
+	assert.True(t, StepSelection{}.Runs(StepExecutionRole))
+	assert.Empty(t, StepSelection{}.Skipped())
+
+	only := StepSelection{Only: []string{StepFunctionCode}}
+	assert.True(t, only.Runs(StepFunctionCode))
+	assert.False(t, only.Runs(StepFunctionConfig))
+	assert.False(t, only.Runs(StepExecutionRole))
+	assert.Equal(t, []string{StepExecutionRole, StepLedgerTable, StepFunctionConfig, StepResourcePolicy, StepLogGroup,
+		StepTagFunction, StepPublishVersion, StepPrime}, only.Skipped())
+
+	skip := StepSelection{Skip: []string{StepFunction, StepLogGroup}}
+	assert.False(t, skip.Runs(StepFunctionCode))
+	assert.False(t, skip.Runs(StepFunctionConfig))
+	assert.True(t, skip.Runs(StepResourcePolicy))
+	assert.Equal(t, []string{StepFunctionCode, StepFunctionConfig, StepLogGroup}, skip.Skipped())
+
+	assert.NoError(t, skip.Validate())
+	assert.ErrorContains(t, StepSelection{Only: []string{StepLogGroup}, Skip: []string{StepPrime}}.Validate(), "only one of")
+	assert.ErrorContains(t, StepSelection{Skip: []string{StepValidate}}.Validate(), `unknown deployment step "validate"`)
+}
+
+func TestDeploy_OnlyFunctionCode(t *testing.T) {
+	roleARN := "arn:aws:iam::123456789012:role/test-role"
+	functionARN := "arn:aws:lambda:us-east-1:123456789012:function:test-function"
+	codeUpdated := false
+
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{FunctionArn: aws.String(functionARN), Role: aws.String(roleARN)},
+				Tags:          map[string]string{ManagedTagKey: ManagedTagValue, PackageChecksumTagKey: "0ld"},
+			}, nil
+		},
+		updateFunctionCodeFunc: func(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
+			codeUpdated = true
+			return &lambda.UpdateFunctionCodeOutput{}, nil
+		},
+		updateFunctionConfigFunc: func(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error) {
+			t.Error("the function configuration must not be updated")
+			return &lambda.UpdateFunctionConfigurationOutput{}, nil
+		},
+		tagResourceFunc: func(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error) {
+			t.Error("the function must not be tagged")
+			return &lambda.TagResourceOutput{}, nil
+		},
+	}
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			t.Error("IAM must not be called")
+			return nil, nil
+		},
+	}
+	mockCWLogs := &mockCloudWatchLogsClient{
+		describeLogGroupsFunc: func(ctx context.Context, params *cloudwatchlogs.DescribeLogGroupsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.DescribeLogGroupsOutput, error) {
+			t.Error("the log group must not be touched")
+			return &cloudwatchlogs.DescribeLogGroupsOutput{}, nil
+		},
+	}
+
+	config := rollbackConfig()
+	config.Steps = StepSelection{Only: []string{StepFunctionCode}}
+	result, err := NewDeployer(mockLambda, mockIAM, mockCWLogs, config).Deploy(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, codeUpdated)
+	assert.Equal(t, functionARN, result.FunctionARN)
+	assert.Equal(t, roleARN, result.ExecutionRole)
+	assert.Empty(t, result.Policies)
+	assert.Contains(t, result.SkippedSteps, StepExecutionRole)
+	assert.NotEqual(t, "0ld", result.PackageChecksum, "the new package is reported")
+}
+
+func TestDeploy_SkipFunctionCodeKeepsStamp(t *testing.T) {
+	var description string
+	var functionTags map[string]string
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{
+				Configuration: &lambdaTypes.FunctionConfiguration{
+					FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:test-function"),
+					Role:        aws.String("arn:aws:iam::123456789012:role/test-role"),
+				},
+				Tags: map[string]string{ManagedTagKey: ManagedTagValue, CLIVersionTagKey: "1.0.0", PackageChecksumTagKey: "0ld"},
+			}, nil
+		},
+		updateFunctionCodeFunc: func(ctx context.Context, params *lambda.UpdateFunctionCodeInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionCodeOutput, error) {
+			t.Error("the function code must not be updated")
+			return &lambda.UpdateFunctionCodeOutput{}, nil
+		},
+		updateFunctionConfigFunc: func(ctx context.Context, params *lambda.UpdateFunctionConfigurationInput, optFns ...func(*lambda.Options)) (*lambda.UpdateFunctionConfigurationOutput, error) {
+			description = aws.ToString(params.Description)
+			return &lambda.UpdateFunctionConfigurationOutput{}, nil
+		},
+		tagResourceFunc: func(ctx context.Context, params *lambda.TagResourceInput, optFns ...func(*lambda.Options)) (*lambda.TagResourceOutput, error) {
+			functionTags = params.Tags
+			return &lambda.TagResourceOutput{}, nil
+		},
+	}
+
+	config := rollbackConfig()
+	config.CLIVersion = "2.0.0"
+	config.Steps = StepSelection{Skip: []string{StepExecutionRole, StepFunctionCode, StepLogGroup}}
+	result, err := NewDeployer(mockLambda, &mockIAMClient{}, &mockCloudWatchLogsClient{}, config).Deploy(context.Background())
+	require.NoError(t, err)
+
+	// The code was not replaced, so the stamp still describes the deployed package
+	assert.Equal(t, FunctionDescription(Stamp{CLIVersion: "1.0.0", PackageChecksum: "0ld"}), description)
+	assert.Equal(t, "1.0.0", functionTags[CLIVersionTagKey])
+	assert.Equal(t, "0ld", result.PackageChecksum)
+	assert.Zero(t, result.PackageSize)
+}
+
+func TestDeploy_SkipRefusesMissingFunction(t *testing.T) {
+	config := rollbackConfig()
+	config.Steps = StepSelection{Skip: []string{StepFunctionConfig}}
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return nil, &lambdaTypes.ResourceNotFoundException{}
+		},
+	}
+	_, err := NewDeployer(mockLambda, &mockIAMClient{}, &mockCloudWatchLogsClient{}, config).Deploy(context.Background())
+	assert.ErrorContains(t, err, "function test-function does not exist; creating it requires the function-code and function-config steps")
+}
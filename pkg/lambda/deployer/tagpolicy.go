@@ -0,0 +1,298 @@
+package deployer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/smithy-go"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+// Resource types (in tag policy notation) created by the deployer
+var deployedResourceTypes = []string{"lambda:function", "logs:log-group"}
+
+// TagPolicy describes the tag requirements a deployment must satisfy before any resource is created.
+// It is typically parsed from an AWS Organizations effective tag policy or a local file in the same format.
+type TagPolicy struct {
+	Rules []TagRule
+}
+
+// TagRule is a single tag key requirement
+type TagRule struct {
+	Key           string   // Required key spelling (tag policies are case-sensitive on the key)
+	AllowedValues []string // Allowed values; a trailing "*" matches any suffix. Empty allows any value
+	Required      bool     // Whether the key must be present
+	EnforcedFor   []string // Resource types the rule applies to; empty applies to all
+}
+
+// TagViolation describes a tag that does not satisfy the tag policy
+type TagViolation struct {
+	Key    string
+	Value  string
+	Reason string
+}
+
+// TagPolicyError is returned when configured tags violate the tag policy
+type TagPolicyError struct {
+	Violations []TagViolation
+}
+
+func (e *TagPolicyError) Error() string {
+	lines := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		lines = append(lines, fmt.Sprintf("  - %s: %s", v.Key, v.Reason))
+	}
+	return fmt.Sprintf("tags violate tag policy:\n%s", strings.Join(lines, "\n"))
+}
+
+// TagPolicyAPIError is returned when AWS rejects a call because the tags it sets violate
+// an AWS Organizations tag policy enforced on the account
+type TagPolicyAPIError struct {
+	Key      string // Offending tag key, when AWS names it
+	Reserved bool   // Whether Key is one of rosactl's own keys rather than a user tag
+	Err      error
+}
+
+func (e *TagPolicyAPIError) Error() string {
+	key := "a tag key"
+	if e.Key != "" {
+		key = fmt.Sprintf("tag key %q", e.Key)
+	}
+	hint := "change the tag to satisfy the policy"
+	if e.Reserved || e.Key == "" {
+		hint = "set the tag key case or prefix to the spelling the policy requires, " +
+			"or provide the policy so the spelling is detected"
+	}
+	return fmt.Sprintf("AWS rejected %s under the account's tag policy; %s: %v", key, hint, e.Err)
+}
+
+func (e *TagPolicyAPIError) Unwrap() error {
+	return e.Err
+}
+
+// tagPolicyErrorCodes are the API error codes AWS services use for tag policy violations
+var tagPolicyErrorCodes = []string{"TagPolicyViolation", "TagPolicyException"}
+
+// tagPolicyKeyPattern extracts the offending key from a tag policy error message
+var tagPolicyKeyPattern = regexp.MustCompile(`(?i)tag keys?:?\s*['"]?([^'",\s\]]+)`)
+
+// tagPolicyAPIError wraps err in a *TagPolicyAPIError when AWS rejected a call for
+// violating a tag policy, and returns it unchanged otherwise
+func (d *Deployer) tagPolicyAPIError(err error) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	message := apiErr.ErrorMessage()
+	isPolicy := strings.Contains(strings.ToLower(message), "tag polic")
+	for _, code := range tagPolicyErrorCodes {
+		isPolicy = isPolicy || apiErr.ErrorCode() == code
+	}
+	if !isPolicy {
+		return err
+	}
+	policyErr := &TagPolicyAPIError{Err: err}
+	if match := tagPolicyKeyPattern.FindStringSubmatch(message); match != nil {
+		policyErr.Key = match[1]
+		policyErr.Reserved = d.keys.HasPrefix(policyErr.Key)
+	}
+	return policyErr
+}
+
+// KeyFormat returns the format of rosactl's tag keys the policy requires, detected
+// from the spelling of any rosa: key it has a rule for. A key matching no case, such
+// as Rosa:cluster-id, keeps the policy's spelling of the prefix. Without a rosa: key,
+// the default format is returned.
+func (p *TagPolicy) KeyFormat() tagkey.Format {
+	for _, rule := range p.Rules {
+		if !(tagkey.Format{}).HasPrefix(rule.Key) {
+			continue
+		}
+		for _, c := range []tagkey.Case{tagkey.CaseAsIs, tagkey.CaseUpper, tagkey.CaseTitle} {
+			format := tagkey.Format{Case: c}
+			if format.Key(strings.ToLower(rule.Key)) == rule.Key {
+				return format
+			}
+		}
+		return tagkey.Format{Prefix: rule.Key[:len(tagkey.DefaultPrefix)]}
+	}
+	return tagkey.Format{}
+}
+
+// tagPolicyDocument mirrors the tag policy JSON syntax. Values may be plain (effective policies)
+// or wrapped in inheritance operators such as "@@assign" (policy source documents).
+type tagPolicyDocument struct {
+	Tags map[string]struct {
+		TagKey               json.RawMessage `json:"tag_key"`
+		TagValue             json.RawMessage `json:"tag_value"`
+		EnforcedFor          json.RawMessage `json:"enforced_for"`
+		ReportRequiredTagFor json.RawMessage `json:"report_required_tag_for"`
+		Required             bool            `json:"required"`
+	} `json:"tags"`
+}
+
+// ParseTagPolicy parses a tag policy document (as returned by organizations:DescribeEffectivePolicy)
+func ParseTagPolicy(content string) (*TagPolicy, error) {
+	var doc tagPolicyDocument
+	if err := json.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse tag policy: %w", err)
+	}
+
+	policy := &TagPolicy{}
+	for name, entry := range doc.Tags {
+		rule := TagRule{Key: name, Required: entry.Required}
+
+		if key, err := policyStrings(entry.TagKey); err != nil {
+			return nil, fmt.Errorf("invalid tag_key for %s: %w", name, err)
+		} else if len(key) > 0 {
+			rule.Key = key[0]
+		}
+
+		var err error
+		if rule.AllowedValues, err = policyStrings(entry.TagValue); err != nil {
+			return nil, fmt.Errorf("invalid tag_value for %s: %w", name, err)
+		}
+		if rule.EnforcedFor, err = policyStrings(entry.EnforcedFor); err != nil {
+			return nil, fmt.Errorf("invalid enforced_for for %s: %w", name, err)
+		}
+
+		requiredFor, err := policyStrings(entry.ReportRequiredTagFor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid report_required_tag_for for %s: %w", name, err)
+		}
+		if appliesToDeployedResources(requiredFor) {
+			rule.Required = true
+		}
+
+		policy.Rules = append(policy.Rules, rule)
+	}
+
+	sort.Slice(policy.Rules, func(i, j int) bool {
+		return policy.Rules[i].Key < policy.Rules[j].Key
+	})
+
+	return policy, nil
+}
+
+// LoadTagPolicyFile reads a local tag policy file in the AWS tag policy JSON format
+func LoadTagPolicyFile(path string) (*TagPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tag policy file: %w", err)
+	}
+	return ParseTagPolicy(string(data))
+}
+
+// Validate checks tags against the policy and returns every violation found
+func (p *TagPolicy) Validate(tags map[string]string) []TagViolation {
+	var violations []TagViolation
+
+	for _, rule := range p.Rules {
+		if len(rule.EnforcedFor) > 0 && !appliesToDeployedResources(rule.EnforcedFor) && !rule.Required {
+			continue
+		}
+
+		actualKey, value, found := lookupTagFold(tags, rule.Key)
+		if !found {
+			if rule.Required {
+				violations = append(violations, TagViolation{
+					Key:    rule.Key,
+					Reason: "required tag is missing",
+				})
+			}
+			continue
+		}
+
+		if actualKey != rule.Key {
+			violations = append(violations, TagViolation{
+				Key:    actualKey,
+				Value:  value,
+				Reason: fmt.Sprintf("tag key must be spelled %q", rule.Key),
+			})
+		}
+
+		if len(rule.AllowedValues) > 0 && !matchesAllowedValue(value, rule.AllowedValues) {
+			violations = append(violations, TagViolation{
+				Key:    actualKey,
+				Value:  value,
+				Reason: fmt.Sprintf("value %q is not allowed (allowed: %s)", value, strings.Join(rule.AllowedValues, ", ")),
+			})
+		}
+	}
+
+	return violations
+}
+
+// policyStrings decodes a tag policy field that may be a string, a list of strings,
+// or either of those wrapped in an inheritance operator object
+func policyStrings(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+
+	var operators map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &operators); err != nil {
+		return nil, fmt.Errorf("unexpected value %s", string(raw))
+	}
+	for _, op := range []string{"@@assign", "@@append"} {
+		if value, ok := operators[op]; ok {
+			return policyStrings(value)
+		}
+	}
+
+	return nil, nil
+}
+
+// appliesToDeployedResources reports whether any resource type matches a resource the deployer creates
+func appliesToDeployedResources(resourceTypes []string) bool {
+	for _, resourceType := range resourceTypes {
+		service := strings.SplitN(resourceType, ":", 2)[0]
+		for _, deployed := range deployedResourceTypes {
+			if resourceType == deployed || resourceType == service+":ALL_SUPPORTED" && strings.HasPrefix(deployed, service+":") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// lookupTagFold finds a tag by key, ignoring case
+func lookupTagFold(tags map[string]string, key string) (string, string, bool) {
+	if value, ok := tags[key]; ok {
+		return key, value, true
+	}
+	for k, v := range tags {
+		if strings.EqualFold(k, key) {
+			return k, v, true
+		}
+	}
+	return "", "", false
+}
+
+// matchesAllowedValue reports whether value matches an allowed value, honoring trailing wildcards
+func matchesAllowedValue(value string, allowed []string) bool {
+	for _, candidate := range allowed {
+		if candidate == "*" || candidate == value {
+			return true
+		}
+		if strings.HasSuffix(candidate, "*") && strings.HasPrefix(value, strings.TrimSuffix(candidate, "*")) {
+			return true
+		}
+	}
+	return false
+}
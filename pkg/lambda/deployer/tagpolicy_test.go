@@ -0,0 +1,188 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/smithy-go"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const effectiveTagPolicy = `{
+  "tags": {
+    "costcenter": {
+      "tag_key": "CostCenter",
+      "tag_value": ["100", "200*"],
+      "enforced_for": ["lambda:function"]
+    },
+    "owner": {
+      "tag_key": "Owner",
+      "report_required_tag_for": ["lambda:function"]
+    },
+    "project": {
+      "tag_key": "Project",
+      "enforced_for": ["ec2:instance"]
+    }
+  }
+}`
+
+func TestParseTagPolicy(t *testing.T) {
+	policy, err := ParseTagPolicy(effectiveTagPolicy)
+	require.NoError(t, err)
+	require.Len(t, policy.Rules, 3)
+
+	assert.Equal(t, "CostCenter", policy.Rules[0].Key)
+	assert.Equal(t, []string{"100", "200*"}, policy.Rules[0].AllowedValues)
+	assert.Equal(t, "Owner", policy.Rules[1].Key)
+	assert.True(t, policy.Rules[1].Required)
+	assert.Equal(t, "Project", policy.Rules[2].Key)
+	assert.False(t, policy.Rules[2].Required)
+}
+
+func TestParseTagPolicy_InheritanceOperators(t *testing.T) {
+	policy, err := ParseTagPolicy(`{"tags":{"env":{"tag_key":{"@@assign":"Env"},"tag_value":{"@@assign":["prod","dev"]}}}}`)
+	require.NoError(t, err)
+	require.Len(t, policy.Rules, 1)
+	assert.Equal(t, "Env", policy.Rules[0].Key)
+	assert.Equal(t, []string{"prod", "dev"}, policy.Rules[0].AllowedValues)
+}
+
+func TestParseTagPolicy_Invalid(t *testing.T) {
+	_, err := ParseTagPolicy("not json")
+	assert.Error(t, err)
+}
+
+func TestTagPolicy_Validate(t *testing.T) {
+	policy, err := ParseTagPolicy(effectiveTagPolicy)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		tags       map[string]string
+		violations []string
+	}{
+		{
+			name:       "compliant",
+			tags:       map[string]string{"CostCenter": "2001", "Owner": "platform"},
+			violations: nil,
+		},
+		{
+			name:       "missing required tag",
+			tags:       map[string]string{"CostCenter": "100"},
+			violations: []string{"Owner"},
+		},
+		{
+			name:       "wrong key casing and disallowed value",
+			tags:       map[string]string{"costcenter": "300", "Owner": "platform"},
+			violations: []string{"costcenter", "costcenter"},
+		},
+		{
+			name:       "rule enforced for other resource types is ignored",
+			tags:       map[string]string{"CostCenter": "100", "Owner": "platform", "project": "anything"},
+			violations: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := policy.Validate(tt.tags)
+
+			var keys []string
+			for _, v := range violations {
+				keys = append(keys, v.Key)
+			}
+			assert.Equal(t, tt.violations, keys)
+		})
+	}
+}
+
+func TestLoadTagPolicyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tag-policy.json")
+	require.NoError(t, os.WriteFile(path, []byte(effectiveTagPolicy), 0644))
+
+	policy, err := LoadTagPolicyFile(path)
+	require.NoError(t, err)
+	assert.Len(t, policy.Rules, 3)
+
+	_, err = LoadTagPolicyFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestDeploy_TagPolicyViolationCreatesNothing(t *testing.T) {
+	policy, err := ParseTagPolicy(effectiveTagPolicy)
+	require.NoError(t, err)
+
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			t.Fatal("no AWS calls expected when tags violate the tag policy")
+			return nil, nil
+		},
+	}
+
+	config := DeploymentConfig{
+		FunctionName:      "test-function",
+		ExecutionRoleName: "test-role",
+		Tags:              map[string]string{"rosa:managed": "true"},
+		TagPolicy:         policy,
+	}
+
+	deployer := NewDeployer(&mockLambdaClient{}, mockIAM, &mockCloudWatchLogsClient{}, config)
+	_, err = deployer.Deploy(context.Background())
+
+	var tagErr *TagPolicyError
+	require.True(t, errors.As(err, &tagErr))
+	require.Len(t, tagErr.Violations, 1)
+	assert.Equal(t, "Owner", tagErr.Violations[0].Key)
+	assert.Contains(t, err.Error(), "required tag is missing")
+}
+
+func TestTagPolicy_KeyFormat(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected tagkey.Format
+	}{
+		{"CostCenter", tagkey.Format{}},
+		{"rosa:managed", tagkey.Format{}},
+		{"ROSA:MANAGED", tagkey.Format{Case: tagkey.CaseUpper}},
+		{"Rosa:Cluster-Id", tagkey.Format{Case: tagkey.CaseTitle}},
+		{"Rosa:cluster-id", tagkey.Format{Prefix: "Rosa:"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			policy := &TagPolicy{Rules: []TagRule{{Key: "Owner"}, {Key: tt.key}}}
+			assert.Equal(t, tt.expected, policy.KeyFormat())
+		})
+	}
+}
+
+func TestDeployer_TagPolicyAPIError(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{})
+
+	rejected := fmt.Errorf("failed to create role: %w", &smithy.GenericAPIError{
+		Code:    "InvalidInput",
+		Message: "The tag policy does not allow the specified value for the following tag key: 'rosa:managed'.",
+	})
+	err := deployer.tagPolicyAPIError(rejected)
+	var policyErr *TagPolicyAPIError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, ManagedTagKey, policyErr.Key)
+	assert.True(t, policyErr.Reserved)
+	assert.Contains(t, err.Error(), `tag key "rosa:managed"`)
+
+	// Other API errors are returned unchanged
+	denied := &smithy.GenericAPIError{Code: "AccessDenied", Message: "not authorized"}
+	assert.Same(t, denied, deployer.tagPolicyAPIError(denied))
+
+	err = deployer.tagPolicyAPIError(&smithy.GenericAPIError{Code: "TagPolicyViolation", Message: "Tag key: CostCenter"})
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, "CostCenter", policyErr.Key)
+	assert.False(t, policyErr.Reserved)
+}
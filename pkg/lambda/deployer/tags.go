@@ -0,0 +1,155 @@
+package deployer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+const (
+	maxTagsPerResource = 50
+	maxTagKeyLength    = 128
+	maxTagValueLength  = 256
+
+	// reservedTagPrefix is reserved by AWS and cannot be used in user tags
+	reservedTagPrefix = "aws:"
+
+	// ProviderTagsEnvVar passes user tags to the provisioner, which applies them to the OIDC providers it creates
+	ProviderTagsEnvVar = "ROSA_PROVIDER_TAGS"
+
+	// ProviderMetadataEnvVar passes the metadata the provisioner tags OIDC providers with,
+	// as IAM OIDC providers have no description
+	ProviderMetadataEnvVar = "ROSA_PROVIDER_METADATA"
+
+	// BindableRolePathEnvVar passes the IAM path of the roles the provisioner's
+	// bind-roles action may update
+	BindableRolePathEnvVar = "ROSA_BINDABLE_ROLE_PATH"
+)
+
+// tagCharacters matches the characters AWS allows in tag keys and values
+var tagCharacters = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// TagConstraintError lists tags that violate AWS tagging constraints
+type TagConstraintError struct {
+	Problems []string
+}
+
+func (e *TagConstraintError) Error() string {
+	return fmt.Sprintf("invalid tags: %s", strings.Join(e.Problems, "; "))
+}
+
+// ValidateTagConstraints checks tags against the AWS limits shared by Lambda, IAM, and CloudWatch Logs
+func ValidateTagConstraints(tags map[string]string) error {
+	var problems []string
+
+	if len(tags) > maxTagsPerResource {
+		problems = append(problems, fmt.Sprintf("%d tags exceeds the maximum of %d per resource", len(tags), maxTagsPerResource))
+	}
+
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := tags[key]
+
+		switch {
+		case key == "":
+			problems = append(problems, "tag key must not be empty")
+			continue
+		case utf8.RuneCountInString(key) > maxTagKeyLength:
+			problems = append(problems, fmt.Sprintf("tag key %q exceeds %d characters", key, maxTagKeyLength))
+		case strings.HasPrefix(strings.ToLower(key), reservedTagPrefix):
+			problems = append(problems, fmt.Sprintf("tag key %q uses the reserved %q prefix", key, reservedTagPrefix))
+		case !tagCharacters.MatchString(key):
+			problems = append(problems, fmt.Sprintf("tag key %q contains characters AWS does not allow", key))
+		}
+
+		switch {
+		case utf8.RuneCountInString(value) > maxTagValueLength:
+			problems = append(problems, fmt.Sprintf("value of tag %q exceeds %d characters", key, maxTagValueLength))
+		case !tagCharacters.MatchString(value):
+			problems = append(problems, fmt.Sprintf("value of tag %q contains characters AWS does not allow", key))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &TagConstraintError{Problems: problems}
+	}
+	return nil
+}
+
+// providerTagsJSON encodes the user tags forwarded to OIDC providers. Tags in the
+// rosa: namespace, in any case or in the keys format, describe the deployed resources
+// themselves and are not forwarded.
+func providerTagsJSON(tags map[string]string, keys tagkey.Format) (string, error) {
+	forwarded := make(map[string]string)
+	for key, value := range tags {
+		if !keys.HasPrefix(key) {
+			forwarded[key] = value
+		}
+	}
+
+	if len(forwarded) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(forwarded)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode provider tags: %w", err)
+	}
+	return string(data), nil
+}
+
+// providerMetadata is the metadata the provisioner tags the OIDC providers it creates with
+type providerMetadata struct {
+	Description         string `json:"description,omitempty"`
+	CreatedBy           string `json:"created_by,omitempty"`
+	RosactlVersion      string `json:"rosactl_version,omitempty"`
+	PlatformEnvironment string `json:"platform_environment,omitempty"`
+}
+
+// ValidateProviderMetadata checks that an OIDC provider description and platform
+// environment can be written as tag values
+func ValidateProviderMetadata(description, environment string) error {
+	for _, field := range []struct{ name, value string }{
+		{"provider description", description},
+		{"platform environment", environment},
+	} {
+		switch {
+		case utf8.RuneCountInString(field.value) > maxTagValueLength:
+			return fmt.Errorf("%s exceeds %d characters", field.name, maxTagValueLength)
+		case !tagCharacters.MatchString(field.value):
+			return fmt.Errorf("%s %q contains characters AWS does not allow in tag values (letters, numbers, spaces, and _.:/=+-@)", field.name, field.value)
+		}
+	}
+	return nil
+}
+
+// providerMetadataJSON encodes the metadata forwarded to OIDC providers: the
+// configured description and platform environment, the function creating them, and
+// the rosactl release that deployed it
+func (d *Deployer) providerMetadataJSON() (string, error) {
+	metadata := providerMetadata{
+		Description:         d.config.ProviderDescription,
+		CreatedBy:           d.config.FunctionName,
+		RosactlVersion:      d.config.CLIVersion,
+		PlatformEnvironment: d.config.PlatformEnvironment,
+	}
+	if metadata == (providerMetadata{}) {
+		return "", nil
+	}
+
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode provider metadata: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,221 @@
+package deployer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTagConstraints(t *testing.T) {
+	tests := []struct {
+		name        string
+		tags        map[string]string
+		expectError string
+	}{
+		{
+			name: "valid tags",
+			tags: map[string]string{"team": "platform", "cost-center": "1234", "owner": "ops@example.com", "path": "a/b:c+d=e"},
+		},
+		{
+			name: "empty value allowed",
+			tags: map[string]string{"team": ""},
+		},
+		{
+			name:        "empty key",
+			tags:        map[string]string{"": "value"},
+			expectError: "tag key must not be empty",
+		},
+		{
+			name:        "reserved prefix",
+			tags:        map[string]string{"AWS:createdBy": "me"},
+			expectError: "reserved",
+		},
+		{
+			name:        "key too long",
+			tags:        map[string]string{strings.Repeat("k", 129): "v"},
+			expectError: "exceeds 128 characters",
+		},
+		{
+			name:        "value too long",
+			tags:        map[string]string{"team": strings.Repeat("v", 257)},
+			expectError: "exceeds 256 characters",
+		},
+		{
+			name:        "invalid key characters",
+			tags:        map[string]string{"team#1": "platform"},
+			expectError: "characters AWS does not allow",
+		},
+		{
+			name:        "invalid value characters",
+			tags:        map[string]string{"team": "platform!"},
+			expectError: "characters AWS does not allow",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTagConstraints(tt.tags)
+			if tt.expectError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var constraintErr *TagConstraintError
+			require.ErrorAs(t, err, &constraintErr)
+			assert.Contains(t, err.Error(), tt.expectError)
+		})
+	}
+}
+
+func TestValidateTagConstraints_TooMany(t *testing.T) {
+	tags := make(map[string]string)
+	for i := 0; i < maxTagsPerResource+1; i++ {
+		tags[strings.Repeat("k", i+1)] = "v"
+	}
+
+	err := ValidateTagConstraints(tags)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds the maximum of 50")
+}
+
+func TestProviderTagsJSON(t *testing.T) {
+	encoded, err := providerTagsJSON(map[string]string{
+		"rosa:component": "oidc-provisioner",
+		"rosa:managed":   "true",
+		"team":           "platform",
+	}, tagkey.Format{})
+	require.NoError(t, err)
+
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal([]byte(encoded), &decoded))
+	assert.Equal(t, map[string]string{"team": "platform"}, decoded)
+
+	encoded, err = providerTagsJSON(map[string]string{"rosa:managed": "true"}, tagkey.Format{})
+	require.NoError(t, err)
+	assert.Empty(t, encoded)
+}
+
+func TestDeployer_ValidateTagsReservedManagedTag(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		Tags: map[string]string{ManagedTagKey: "false"},
+	})
+
+	err := deployer.ValidateTags()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reserved for rosactl")
+}
+
+func TestDeployer_FunctionEnvironment(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		Tags: map[string]string{"rosa:component": "oidc-provisioner", "team": "platform"},
+	})
+
+	env, err := deployer.functionEnvironment()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"team":"platform"}`, env.Variables[ProviderTagsEnvVar])
+
+	// Without user tags the variable is cleared rather than left stale
+	deployer = NewDeployer(nil, nil, nil, DeploymentConfig{})
+	env, err = deployer.functionEnvironment()
+	require.NoError(t, err)
+	assert.NotNil(t, env.Variables)
+	assert.NotContains(t, env.Variables, ProviderTagsEnvVar)
+}
+
+func TestDeployer_ProviderMetadata(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		FunctionName:        "rosa-oidc-provisioner",
+		CLIVersion:          "v1.4.0",
+		ProviderDescription: "ROSA cluster OIDC provider",
+		PlatformEnvironment: "staging",
+	})
+
+	env, err := deployer.functionEnvironment()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"description":"ROSA cluster OIDC provider","created_by":"rosa-oidc-provisioner",`+
+		`"rosactl_version":"v1.4.0","platform_environment":"staging"}`, env.Variables[ProviderMetadataEnvVar])
+
+	deployer = NewDeployer(nil, nil, nil, DeploymentConfig{})
+	env, err = deployer.functionEnvironment()
+	require.NoError(t, err)
+	assert.NotContains(t, env.Variables, ProviderMetadataEnvVar)
+}
+
+func TestDeployer_BindableRolePath(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{BindableRolePath: "/rosa-operators/"})
+	env, err := deployer.functionEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, "/rosa-operators/", env.Variables[BindableRolePathEnvVar])
+
+	deployer = NewDeployer(nil, nil, nil, DeploymentConfig{})
+	env, err = deployer.functionEnvironment()
+	require.NoError(t, err)
+	assert.NotContains(t, env.Variables, BindableRolePathEnvVar)
+}
+
+func TestValidateProviderMetadata(t *testing.T) {
+	assert.NoError(t, ValidateProviderMetadata("ROSA HCP cluster provider: team-identity@example.com", "prod-us"))
+	assert.NoError(t, ValidateProviderMetadata("", ""))
+	assert.ErrorContains(t, ValidateProviderMetadata("clusters; do not delete!", ""), "provider description")
+	assert.ErrorContains(t, ValidateProviderMetadata("", strings.Repeat("e", 257)), "platform environment exceeds 256 characters")
+}
+
+func TestDeployer_RoleTagsSorted(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		Tags: map[string]string{"team": "platform", "env": "prod"},
+	})
+
+	tags := deployer.roleTags()
+	require.Len(t, tags, 3)
+	assert.Equal(t, "env", *tags[0].Key)
+	assert.Equal(t, ManagedTagKey, *tags[1].Key)
+	assert.Equal(t, "team", *tags[2].Key)
+}
+
+func TestDeployer_TagKeyFormat(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		Tags:         map[string]string{"rosa:component": "oidc-provisioner", "team": "platform"},
+		TagKeyFormat: tagkey.Format{Case: tagkey.CaseTitle},
+		CLIVersion:   "0.1.0",
+	})
+
+	assert.Equal(t, map[string]string{
+		"Rosa:Component": "oidc-provisioner",
+		"Rosa:Managed":   ManagedTagValue,
+		"team":           "platform",
+	}, deployer.resourceTags())
+	assert.Equal(t, "0.1.0", deployer.functionTags()["Rosa:Cli-Version"])
+
+	// Resources tagged in either spelling are recognized as managed
+	assert.True(t, deployer.isManaged(map[string]string{"Rosa:Managed": ManagedTagValue}))
+	assert.True(t, deployer.isManaged(map[string]string{ManagedTagKey: ManagedTagValue}))
+	assert.False(t, deployer.isManaged(map[string]string{"team": "platform"}))
+
+	// The provisioner is told the format, and the renamed rosa: tags are not forwarded
+	env, err := deployer.functionEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, "prefix=,case=title", env.Variables[tagkey.EnvVar])
+	assert.JSONEq(t, `{"team":"platform"}`, env.Variables[ProviderTagsEnvVar])
+}
+
+func TestDeployer_TagKeyFormatFromPolicy(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		TagPolicy: &TagPolicy{Rules: []TagRule{{Key: "ROSA:MANAGED", AllowedValues: []string{"true"}}}},
+	})
+
+	assert.Equal(t, map[string]string{"ROSA:MANAGED": ManagedTagValue}, deployer.resourceTags())
+	assert.NoError(t, deployer.ValidateTags())
+
+	// An explicit format wins; the policy then reports rosactl's key by name
+	deployer = NewDeployer(nil, nil, nil, DeploymentConfig{
+		TagPolicy:    &TagPolicy{Rules: []TagRule{{Key: "ROSA:MANAGED"}}},
+		TagKeyFormat: tagkey.Format{Case: tagkey.CaseLower},
+	})
+	err := deployer.ValidateTags()
+	var policyErr *TagPolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, ManagedTagKey, policyErr.Violations[0].Key)
+}
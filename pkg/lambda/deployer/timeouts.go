@@ -0,0 +1,205 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// Timed deployment operations
+const (
+	TimedCompile        = "compile"
+	TimedUpload         = "upload"
+	TimedIAMPropagation = "iam-propagation"
+	TimedVerify         = "verify"
+	TimedInvocable      = "invocable"
+)
+
+// Default step timeouts
+const (
+	DefaultCompileTimeout        = 5 * time.Minute
+	DefaultUploadTimeout         = 5 * time.Minute
+	DefaultIAMPropagationTimeout = 2 * time.Minute
+	DefaultVerifyTimeout         = 5 * time.Minute
+	DefaultInvocableTimeout      = 5 * time.Minute
+
+	// defaultPollInterval is how often role propagation and function state are rechecked
+	defaultPollInterval = 2 * time.Second
+)
+
+// StepTimeouts bounds individual deployment operations so a hung endpoint fails fast.
+// Zero values use the defaults.
+type StepTimeouts struct {
+	Compile        time.Duration // Building the Lambda package
+	Upload         time.Duration // Each CreateFunction or UpdateFunctionCode/Configuration call
+	IAMPropagation time.Duration // Waiting for a new execution role to become assumable by Lambda
+	Verify         time.Duration // Waiting for the function to become active after deploying
+	Invocable      time.Duration // Waiting for CLM's permission to invoke the function to take effect
+}
+
+// timeout returns the configured timeout for op, or its default
+func (t StepTimeouts) timeout(op string) time.Duration {
+	var configured, fallback time.Duration
+	switch op {
+	case TimedCompile:
+		configured, fallback = t.Compile, DefaultCompileTimeout
+	case TimedUpload:
+		configured, fallback = t.Upload, DefaultUploadTimeout
+	case TimedIAMPropagation:
+		configured, fallback = t.IAMPropagation, DefaultIAMPropagationTimeout
+	case TimedVerify:
+		configured, fallback = t.Verify, DefaultVerifyTimeout
+	case TimedInvocable:
+		configured, fallback = t.Invocable, DefaultInvocableTimeout
+	}
+	if configured > 0 {
+		return configured
+	}
+	return fallback
+}
+
+// StepTimeoutError is returned when a deployment operation exceeds its timeout
+type StepTimeoutError struct {
+	Step    string
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *StepTimeoutError) Error() string {
+	return fmt.Sprintf("step %s timed out after %s: %s (last error: %v)", e.Step, e.Timeout, timeoutHint(e.Step), e.Err)
+}
+
+func (e *StepTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrIAMPropagationTimeout when the timed-out step waited for IAM propagation
+func (e *StepTimeoutError) Is(target error) bool {
+	return target == ErrIAMPropagationTimeout && e.Step == TimedIAMPropagation
+}
+
+// timeoutHint suggests what to do about a timed-out operation
+func timeoutHint(step string) string {
+	switch step {
+	case TimedCompile:
+		return "check that the Go toolchain can download the function's dependencies, or raise --compile-timeout"
+	case TimedUpload:
+		return "check connectivity to the Lambda endpoint, or raise --upload-timeout"
+	case TimedIAMPropagation:
+		return "the execution role is not yet assumable by Lambda; re-run setup-account, or raise --iam-propagation-timeout"
+	case TimedVerify:
+		return "the function did not become active; check its state with 'aws lambda get-function', or raise --verify-timeout"
+	case TimedInvocable:
+		return "CLM's permission to invoke the function has not taken effect; check it with 'aws lambda get-policy', or raise --invocable-timeout"
+	default:
+		return "retry the deployment"
+	}
+}
+
+// withStepTimeout runs fn under a child context bounded by the timeout for op. Hitting
+// that deadline is reported as a *StepTimeoutError; cancellation of ctx is passed through.
+func (d *Deployer) withStepTimeout(ctx context.Context, op string, fn func(context.Context) error) error {
+	timeout := d.config.Timeouts.timeout(op)
+	stepCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	err := fn(stepCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		return &StepTimeoutError{Step: op, Timeout: timeout, Err: err}
+	}
+	return err
+}
+
+// createFunctionWhenRoleAssumable creates the function, retrying while a newly created
+// execution role has not yet propagated to Lambda
+func (d *Deployer) createFunctionWhenRoleAssumable(ctx context.Context, zipData []byte, roleARN string) (string, error) {
+	var functionARN string
+
+	err := d.withStepTimeout(ctx, TimedIAMPropagation, func(ctx context.Context) error {
+		for {
+			err := d.withStepTimeout(ctx, TimedUpload, func(ctx context.Context) error {
+				var err error
+				functionARN, err = d.createFunction(ctx, zipData, roleARN)
+				return err
+			})
+			if !isRolePropagationError(err) {
+				return err
+			}
+
+			if err := d.sleep(ctx); err != nil {
+				return fmt.Errorf("execution role %s is not assumable by Lambda: %w", roleARN, err)
+			}
+		}
+	})
+
+	return functionARN, err
+}
+
+// isRolePropagationError reports whether CreateFunction failed because Lambda cannot
+// assume the execution role yet, which happens for a few seconds after it is created
+func isRolePropagationError(err error) bool {
+	var invalidParam *lambdaTypes.InvalidParameterValueException
+	return errors.As(err, &invalidParam) && strings.Contains(invalidParam.ErrorMessage(), "cannot be assumed")
+}
+
+// waitForFunctionActive polls the function until it is active and its last update has finished
+func (d *Deployer) waitForFunctionActive(ctx context.Context) error {
+	return d.withStepTimeout(ctx, TimedVerify, func(ctx context.Context) error {
+		for {
+			output, err := d.lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{
+				FunctionName: aws.String(d.config.FunctionName),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to read function state: %w", err)
+			}
+
+			cfg := output.Configuration
+			if cfg == nil {
+				return nil
+			}
+			if cfg.State == lambdaTypes.StateFailed {
+				return fmt.Errorf("function entered state %s: %s", cfg.State, aws.ToString(cfg.StateReason))
+			}
+			if cfg.LastUpdateStatus == lambdaTypes.LastUpdateStatusFailed {
+				return fmt.Errorf("function update failed: %s", aws.ToString(cfg.LastUpdateStatusReason))
+			}
+
+			pending := cfg.State == lambdaTypes.StatePending ||
+				cfg.LastUpdateStatus == lambdaTypes.LastUpdateStatusInProgress
+			if !pending {
+				return nil
+			}
+
+			if err := d.sleep(ctx); err != nil {
+				return fmt.Errorf("function is still %s: %w", pendingState(cfg), err)
+			}
+		}
+	})
+}
+
+// pendingState describes why a function is not yet active
+func pendingState(cfg *lambdaTypes.FunctionConfiguration) string {
+	if cfg.State == lambdaTypes.StatePending {
+		return "pending"
+	}
+	return "updating"
+}
+
+// sleep waits for the poll interval, returning early with the context error if ctx is done
+func (d *Deployer) sleep(ctx context.Context) error {
+	timer := time.NewTimer(d.pollInterval)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,185 @@
+package deployer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepTimeouts_Defaults(t *testing.T) {
+	timeouts := StepTimeouts{Upload: time.Minute}
+
+	assert.Equal(t, DefaultCompileTimeout, timeouts.timeout(TimedCompile))
+	assert.Equal(t, time.Minute, timeouts.timeout(TimedUpload))
+	assert.Equal(t, DefaultIAMPropagationTimeout, timeouts.timeout(TimedIAMPropagation))
+	assert.Equal(t, DefaultVerifyTimeout, timeouts.timeout(TimedVerify))
+}
+
+func TestWithStepTimeout(t *testing.T) {
+	deployer := NewDeployer(nil, nil, nil, DeploymentConfig{
+		Timeouts: StepTimeouts{Upload: 10 * time.Millisecond},
+	})
+
+	err := deployer.withStepTimeout(context.Background(), TimedUpload, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	var timeoutErr *StepTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, TimedUpload, timeoutErr.Step)
+	assert.Contains(t, err.Error(), "step upload timed out after 10ms")
+	assert.Contains(t, err.Error(), "--upload-timeout")
+
+	// Cancelling the parent context is not a timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = deployer.withStepTimeout(ctx, TimedUpload, func(ctx context.Context) error {
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, errors.As(err, &timeoutErr))
+}
+
+func rolePropagationError() error {
+	return &lambdaTypes.InvalidParameterValueException{
+		Message: aws.String("The role defined for the function cannot be assumed by Lambda."),
+	}
+}
+
+func TestCreateFunctionWhenRoleAssumable_Retries(t *testing.T) {
+	attempts := 0
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, rolePropagationError()
+			}
+			return &lambda.CreateFunctionOutput{FunctionArn: aws.String("arn:aws:lambda:us-east-1:123456789012:function:test-function")}, nil
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	deployer.pollInterval = time.Millisecond
+
+	functionARN, err := deployer.createFunctionWhenRoleAssumable(context.Background(), []byte("zip"), "arn:aws:iam::123456789012:role/test-role")
+	require.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, "arn:aws:lambda:us-east-1:123456789012:function:test-function", functionARN)
+}
+
+func TestCreateFunctionWhenRoleAssumable_Timeout(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			return nil, rolePropagationError()
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{
+		FunctionName: "test-function",
+		Timeouts:     StepTimeouts{IAMPropagation: 20 * time.Millisecond},
+	})
+	deployer.pollInterval = time.Millisecond
+
+	_, err := deployer.createFunctionWhenRoleAssumable(context.Background(), []byte("zip"), "arn:aws:iam::123456789012:role/test-role")
+
+	var timeoutErr *StepTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, TimedIAMPropagation, timeoutErr.Step)
+	assert.Contains(t, err.Error(), "not assumable by Lambda")
+	assert.ErrorIs(t, err, ErrIAMPropagationTimeout)
+}
+
+func TestCreateFunctionWhenRoleAssumable_OtherError(t *testing.T) {
+	attempts := 0
+	mockLambda := &mockLambdaClient{
+		createFunctionFunc: func(ctx context.Context, params *lambda.CreateFunctionInput, optFns ...func(*lambda.Options)) (*lambda.CreateFunctionOutput, error) {
+			attempts++
+			return nil, errors.New("access denied")
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	_, err := deployer.createFunctionWhenRoleAssumable(context.Background(), []byte("zip"), "arn:aws:iam::123456789012:role/test-role")
+
+	assert.EqualError(t, err, "access denied")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestWaitForFunctionActive(t *testing.T) {
+	states := []lambdaTypes.FunctionConfiguration{
+		{State: lambdaTypes.StatePending},
+		{State: lambdaTypes.StateActive, LastUpdateStatus: lambdaTypes.LastUpdateStatusInProgress},
+		{State: lambdaTypes.StateActive, LastUpdateStatus: lambdaTypes.LastUpdateStatusSuccessful},
+	}
+	calls := 0
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			cfg := states[calls]
+			calls++
+			return &lambda.GetFunctionOutput{Configuration: &cfg}, nil
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	deployer.pollInterval = time.Millisecond
+
+	require.NoError(t, deployer.waitForFunctionActive(context.Background()))
+	assert.Equal(t, 3, calls)
+}
+
+func TestWaitForFunctionActive_Failed(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{Configuration: &lambdaTypes.FunctionConfiguration{
+				State:       lambdaTypes.StateFailed,
+				StateReason: aws.String("InvalidImage"),
+			}}, nil
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{FunctionName: "test-function"})
+	err := deployer.waitForFunctionActive(context.Background())
+
+	assert.EqualError(t, err, "function entered state Failed: InvalidImage")
+}
+
+func TestWaitForFunctionActive_Timeout(t *testing.T) {
+	mockLambda := &mockLambdaClient{
+		getFunctionFunc: func(ctx context.Context, params *lambda.GetFunctionInput, optFns ...func(*lambda.Options)) (*lambda.GetFunctionOutput, error) {
+			return &lambda.GetFunctionOutput{Configuration: &lambdaTypes.FunctionConfiguration{
+				State:            lambdaTypes.StateActive,
+				LastUpdateStatus: lambdaTypes.LastUpdateStatusInProgress,
+			}}, nil
+		},
+	}
+
+	deployer := NewDeployer(mockLambda, nil, nil, DeploymentConfig{
+		FunctionName: "test-function",
+		Timeouts:     StepTimeouts{Verify: 20 * time.Millisecond},
+	})
+	deployer.pollInterval = time.Millisecond
+
+	err := deployer.waitForFunctionActive(context.Background())
+
+	var timeoutErr *StepTimeoutError
+	require.ErrorAs(t, err, &timeoutErr)
+	assert.Equal(t, TimedVerify, timeoutErr.Step)
+	assert.Contains(t, err.Error(), "function is still updating")
+	assert.NotErrorIs(t, err, ErrIAMPropagationTimeout, "only the IAM propagation step matches")
+}
+
+func TestPackageBuilder_BuildContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := NewPackageBuilder("../functions/oidc-provisioner").BuildContext(ctx)
+	assert.Error(t, err)
+}
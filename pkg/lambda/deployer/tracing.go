@@ -0,0 +1,36 @@
+package deployer
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of the deployer's spans
+const tracerName = "github.com/openshift-online/regional-cli/pkg/lambda/deployer"
+
+// Attributes recorded on deployment spans
+const (
+	StepKey     = attribute.Key("rosactl.step")
+	FunctionKey = attribute.Key("rosactl.function")
+)
+
+// endSpan ends span, marking it failed with err when err is set
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startSpan starts the root span of a deployer operation
+func (d *Deployer) startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return d.tracer.Start(ctx, operation, trace.WithAttributes(
+		FunctionKey.String(d.config.FunctionName),
+		semconv.CloudRegion(d.config.Region),
+	))
+}
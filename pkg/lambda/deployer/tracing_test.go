@@ -0,0 +1,67 @@
+package deployer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestDeploy_StepSpans(t *testing.T) {
+	mockLambda, mockIAM := failingRoleSetup(new([]string))
+
+	// Cancel once the role is set up, so the deployment fails at the package step
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mockIAM.putRolePolicyFunc = func(ctx context.Context, params *iam.PutRolePolicyInput, optFns ...func(*iam.Options)) (*iam.PutRolePolicyOutput, error) {
+		cancel()
+		return &iam.PutRolePolicyOutput{}, nil
+	}
+	mockIAM.deleteRolePolicyFunc = func(ctx context.Context, params *iam.DeleteRolePolicyInput, optFns ...func(*iam.Options)) (*iam.DeleteRolePolicyOutput, error) {
+		return &iam.DeleteRolePolicyOutput{}, nil
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	deployer := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, rollbackConfig(), WithTracerProvider(tp))
+	_, err := deployer.Deploy(ctx)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	var names []string
+	for _, span := range spans {
+		names = append(names, span.Name())
+	}
+	require.Equal(t, []string{StepValidate, StepExecutionRole, StepPackage, "Deploy"}, names)
+
+	root := spans[3]
+	assert.Equal(t, codes.Error, root.Status().Code)
+	assert.Contains(t, root.Attributes(), FunctionKey.String(rollbackConfig().FunctionName))
+	for _, step := range spans[:3] {
+		assert.Equal(t, root.SpanContext().SpanID(), step.Parent().SpanID(), "%s is a child of Deploy", step.Name())
+	}
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, codes.Error, spans[2].Status().Code, "the interrupted step is marked failed")
+}
+
+func TestPlanTeardown_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	mockLambda, mockIAM := failingRoleSetup(new([]string))
+	deployer := NewDeployer(mockLambda, mockIAM, &mockCloudWatchLogsClient{}, rollbackConfig(), WithTracerProvider(tp))
+
+	_, _ = deployer.PlanTeardown(context.Background())
+
+	spans := recorder.Ended()
+	require.Greater(t, len(spans), 1, "resources are diffed in child spans")
+	root := spans[len(spans)-1]
+	assert.Equal(t, "PlanTeardown", root.Name())
+	for _, span := range spans[:len(spans)-1] {
+		assert.Equal(t, root.SpanContext().SpanID(), span.Parent().SpanID(), "%s is a child of PlanTeardown", span.Name())
+	}
+}
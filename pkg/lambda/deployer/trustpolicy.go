@@ -0,0 +1,122 @@
+package deployer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// lambdaServicePrincipal must be allowed to assume the execution role
+const lambdaServicePrincipal = "lambda.amazonaws.com"
+
+// trustPolicyDocument mirrors the IAM trust policy syntax, where Statement may be a single
+// object and Principal, Action, and Service may each be a string or a list
+type trustPolicyDocument struct {
+	Version   string          `json:"Version"`
+	Statement json.RawMessage `json:"Statement"`
+}
+
+type trustStatement struct {
+	Effect    string          `json:"Effect"`
+	Principal json.RawMessage `json:"Principal"`
+	Action    json.RawMessage `json:"Action"`
+}
+
+// LoadTrustPolicy returns a trust policy given either inline JSON or the path to a JSON file,
+// validated with ValidateTrustPolicy
+func LoadTrustPolicy(value string) (string, error) {
+	document, err := ReadTrustPolicy(value)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ValidateTrustPolicy(document); err != nil {
+		return "", err
+	}
+	return document, nil
+}
+
+// ReadTrustPolicy returns a trust policy given either inline JSON or the path to a JSON
+// file, without validating it
+func ReadTrustPolicy(value string) (string, error) {
+	document := strings.TrimSpace(value)
+	if !strings.HasPrefix(document, "{") {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to read trust policy file: %w", err)
+		}
+		document = strings.TrimSpace(string(data))
+	}
+	return document, nil
+}
+
+// ValidateTrustPolicy checks that a trust policy allows lambda.amazonaws.com to assume the role.
+// Additional statements and conditions, such as aws:SourceArn or aws:SourceAccount, are allowed.
+func ValidateTrustPolicy(document string) error {
+	var doc trustPolicyDocument
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		return fmt.Errorf("failed to parse trust policy: %w", err)
+	}
+
+	if doc.Version == "" {
+		return fmt.Errorf("invalid trust policy: Version is required")
+	}
+
+	var statements []trustStatement
+	if err := json.Unmarshal(doc.Statement, &statements); err != nil {
+		var single trustStatement
+		if err := json.Unmarshal(doc.Statement, &single); err != nil {
+			return fmt.Errorf("invalid trust policy: Statement must be an object or a list of objects")
+		}
+		statements = []trustStatement{single}
+	}
+
+	for _, statement := range statements {
+		if statement.Effect != "Allow" {
+			continue
+		}
+
+		actions, err := policyStrings(statement.Action)
+		if err != nil {
+			return fmt.Errorf("invalid trust policy Action: %w", err)
+		}
+		if !containsAny(actions, "sts:AssumeRole", "sts:*", "*") {
+			continue
+		}
+
+		var principal map[string]json.RawMessage
+		if err := json.Unmarshal(statement.Principal, &principal); err != nil {
+			continue
+		}
+		services, err := policyStrings(principal["Service"])
+		if err != nil {
+			return fmt.Errorf("invalid trust policy Principal: %w", err)
+		}
+		if containsAny(services, lambdaServicePrincipal) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid trust policy: no statement allows %s to sts:AssumeRole", lambdaServicePrincipal)
+}
+
+// containsAny reports whether values contains any of the candidates
+func containsAny(values []string, candidates ...string) bool {
+	for _, value := range values {
+		for _, candidate := range candidates {
+			if value == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// executionRoleTrustPolicy returns the configured trust policy override, or the default policy
+func (d *Deployer) executionRoleTrustPolicy() (string, error) {
+	if d.config.TrustPolicyOverride != "" {
+		return d.config.TrustPolicyOverride, nil
+	}
+	return GenerateLambdaExecutionRoleTrustPolicy()
+}
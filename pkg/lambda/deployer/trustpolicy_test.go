@@ -0,0 +1,171 @@
+package deployer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sourceArnTrustPolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {
+      "Effect": "Allow",
+      "Principal": {"Service": "lambda.amazonaws.com"},
+      "Action": "sts:AssumeRole",
+      "Condition": {
+        "StringEquals": {"aws:SourceAccount": "123456789012"},
+        "ArnLike": {"aws:SourceArn": "arn:aws:lambda:us-east-1:123456789012:function:test-function"}
+      }
+    }
+  ]
+}`
+
+func TestValidateTrustPolicy(t *testing.T) {
+	tests := []struct {
+		name        string
+		document    string
+		expectError string
+	}{
+		{
+			name:     "lambda principal with conditions",
+			document: sourceArnTrustPolicy,
+		},
+		{
+			name:     "single statement object with lists",
+			document: `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Principal":{"Service":["edgelambda.amazonaws.com","lambda.amazonaws.com"]},"Action":["sts:AssumeRole","sts:TagSession"]}}`,
+		},
+		{
+			name:        "invalid JSON",
+			document:    `{"Version":`,
+			expectError: "failed to parse trust policy",
+		},
+		{
+			name:        "missing version",
+			document:    `{"Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+			expectError: "Version is required",
+		},
+		{
+			name:        "wrong service principal",
+			document:    `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+			expectError: "no statement allows lambda.amazonaws.com",
+		},
+		{
+			name:        "deny only",
+			document:    `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+			expectError: "no statement allows lambda.amazonaws.com",
+		},
+		{
+			name:        "wrong action",
+			document:    `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"lambda.amazonaws.com"},"Action":"sts:TagSession"}]}`,
+			expectError: "no statement allows lambda.amazonaws.com",
+		},
+		{
+			name:        "wildcard principal",
+			document:    `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":"*","Action":"sts:AssumeRole"}]}`,
+			expectError: "no statement allows lambda.amazonaws.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTrustPolicy(tt.document)
+			if tt.expectError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLoadTrustPolicy(t *testing.T) {
+	inline, err := LoadTrustPolicy("  " + sourceArnTrustPolicy)
+	require.NoError(t, err)
+	assert.Equal(t, sourceArnTrustPolicy, inline)
+
+	path := filepath.Join(t.TempDir(), "trust.json")
+	require.NoError(t, os.WriteFile(path, []byte(sourceArnTrustPolicy+"\n"), 0600))
+
+	fromFile, err := LoadTrustPolicy(path)
+	require.NoError(t, err)
+	assert.Equal(t, sourceArnTrustPolicy, fromFile)
+
+	_, err = LoadTrustPolicy(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read trust policy file")
+}
+
+func TestEnsureExecutionRole_TrustPolicyOverride(t *testing.T) {
+	roleARN := "arn:aws:iam::123456789012:role/test-role"
+
+	t.Run("new role", func(t *testing.T) {
+		mockIAM := &mockIAMClient{
+			getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+				return nil, &iamTypes.NoSuchEntityException{}
+			},
+			createRoleFunc: func(ctx context.Context, params *iam.CreateRoleInput, optFns ...func(*iam.Options)) (*iam.CreateRoleOutput, error) {
+				assert.Equal(t, sourceArnTrustPolicy, *params.AssumeRolePolicyDocument)
+				return &iam.CreateRoleOutput{Role: &iamTypes.Role{Arn: aws.String(roleARN)}}, nil
+			},
+		}
+
+		deployer := NewDeployer(nil, mockIAM, nil, DeploymentConfig{
+			ExecutionRoleName:   "test-role",
+			TrustPolicyOverride: sourceArnTrustPolicy,
+		})
+		_, err := deployer.ensureExecutionRole(context.Background())
+		require.NoError(t, err)
+	})
+
+	t.Run("existing managed role", func(t *testing.T) {
+		var updated string
+		mockIAM := &mockIAMClient{
+			getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+				return &iam.GetRoleOutput{Role: &iamTypes.Role{
+					Arn:  aws.String(roleARN),
+					Tags: []iamTypes.Tag{{Key: aws.String(ManagedTagKey), Value: aws.String(ManagedTagValue)}},
+				}}, nil
+			},
+			updateAssumeFunc: func(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput, optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error) {
+				updated = *params.PolicyDocument
+				return &iam.UpdateAssumeRolePolicyOutput{}, nil
+			},
+		}
+
+		deployer := NewDeployer(nil, mockIAM, nil, DeploymentConfig{
+			ExecutionRoleName:   "test-role",
+			TrustPolicyOverride: sourceArnTrustPolicy,
+		})
+		_, err := deployer.ensureExecutionRole(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, sourceArnTrustPolicy, updated)
+		assert.Equal(t, []ResourceRecord{{Type: ResourceTypeExecutionRole, Identifier: roleARN, Action: ResourceActionUpdated}}, deployer.resources)
+	})
+}
+
+func TestDeploy_InvalidTrustPolicyCreatesNothing(t *testing.T) {
+	mockIAM := &mockIAMClient{
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput, optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			t.Fatal("no IAM calls expected")
+			return nil, nil
+		},
+	}
+
+	deployer := NewDeployer(&mockLambdaClient{}, mockIAM, &mockCloudWatchLogsClient{}, DeploymentConfig{
+		FunctionName:        "test-function",
+		ExecutionRoleName:   "test-role",
+		MemorySize:          128,
+		Timeout:             60,
+		TrustPolicyOverride: `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`,
+	})
+	_, err := deployer.Deploy(context.Background())
+	assert.ErrorContains(t, err, "no statement allows lambda.amazonaws.com")
+}
@@ -2,25 +2,74 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/openshift-online/regional-cli/pkg/lambda/functions/oidc-provisioner/provisioner"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
 )
 
+// credentialExpiryWindow refreshes the execution role's credentials this long before
+// they expire, so a refresh never races an in-flight IAM call
+const credentialExpiryWindow = 5 * time.Minute
+
+// ledgerMaxAttempts lets the SDK retry ledger writes, which the handler does not retry itself
+const ledgerMaxAttempts = 3
+
+// handler is built once per execution environment, during Lambda's init phase, and
+// reused by every invocation along with its IAM client and pooled connections
+var handler *provisioner.Handler
+
+func init() {
+	// init also runs under go test, where there is no Lambda environment
+	if os.Getenv("AWS_LAMBDA_FUNCTION_NAME") == "" {
+		return
+	}
+	handler = newHandler(context.Background())
+}
+
 func main() {
-	// Initialize AWS SDK
-	cfg, err := config.LoadDefaultConfig(context.Background())
+	if handler == nil {
+		handler = newHandler(context.Background())
+	}
+
+	// Start Lambda
+	lambda.Start(handler.Handle)
+}
+
+// newHandler creates the IAM client and handler from the environment
+func newHandler(ctx context.Context) *provisioner.Handler {
+	// Initialize AWS SDK. SDK retries are disabled because the handler retries
+	// throttled IAM calls itself within the invocation deadline. Credentials are
+	// cached and retrieved on first use, then refreshed ahead of expiry.
+	cfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRetryMaxAttempts(1),
+		config.WithCredentialsCacheOptions(func(o *aws.CredentialsCacheOptions) {
+			o.ExpiryWindow = credentialExpiryWindow
+		}),
+	)
 	if err != nil {
 		panic("failed to load AWS config: " + err.Error())
 	}
 
 	// Create IAM client
 	iamClient := iam.NewFromConfig(cfg)
+	if err := prewarm(ctx, cfg); err != nil {
+		fmt.Printf("Warning: failed to pre-warm IAM connection: %v\n", err)
+	}
 
-	// Create handler
-	handler := NewHandler(iamClient)
-
-	// Start Lambda
-	lambda.Start(handler.Handle)
+	opts := append(provisioner.OptionsFromEnv(), provisioner.WithThumbprintFetcher(provisioner.FetchThumbprint))
+	if table := os.Getenv(ledger.EnvVar); table != "" {
+		ledgerClient := dynamodb.NewFromConfig(cfg, func(o *dynamodb.Options) {
+			o.RetryMaxAttempts = ledgerMaxAttempts
+		})
+		opts = append(opts, provisioner.WithLedger(ledgerClient, table))
+	}
+	return provisioner.NewHandler(iamClient, opts...)
 }
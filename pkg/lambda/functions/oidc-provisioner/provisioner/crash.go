@@ -0,0 +1,158 @@
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	// Messages of the records written when an invocation crashes, or is about to be
+	// killed for running out of memory or time. Lambda logs only a panic's message, and
+	// nothing of the process it kills, so these are the last word on the invocation.
+	crashLogMessage          = "function crashed"
+	memoryPressureLogMessage = "memory pressure"
+	timeoutLogMessage        = "invocation timing out"
+
+	// memorySizeEnvVar holds the function's memory size in MB, set by Lambda
+	memorySizeEnvVar = "AWS_LAMBDA_FUNCTION_MEMORY_SIZE"
+
+	// memoryPressurePercent of the memory size obtained from the OS is logged as memory
+	// pressure, while the invocation can still write a record before it is killed
+	memoryPressurePercent = 90
+
+	// timeoutLogMargin is how long before the invocation deadline it is logged as timing out
+	timeoutLogMargin = 500 * time.Millisecond
+
+	// watchInterval is how often a running invocation's memory and deadline are checked
+	watchInterval = 250 * time.Millisecond
+)
+
+const bytesPerMB = 1 << 20
+
+// crashLogRecord is the structured log line written when an invocation crashes or is
+// about to be killed, with the execution environment's memory use at the time
+type crashLogRecord struct {
+	Msg           string       `json:"msg"`
+	CorrelationID string       `json:"correlation_id,omitempty"`
+	ClusterID     string       `json:"cluster_id,omitempty"`
+	Action        string       `json:"action,omitempty"`
+	Panic         string       `json:"panic,omitempty"`
+	Stack         string       `json:"stack,omitempty"`
+	RemainingMS   int64        `json:"remaining_ms,omitempty"`
+	Runtime       RuntimeStats `json:"runtime"`
+}
+
+// WithMemoryLimit sets the function's memory size in MB, against which memory pressure
+// is reported; zero disables the check
+func WithMemoryLimit(mb uint64) HandlerOption {
+	return func(h *Handler) {
+		h.memoryLimitMB = mb
+	}
+}
+
+// runtimeStats returns the execution environment's current memory use and uptime
+func (h *Handler) runtimeStats() RuntimeStats {
+	var mem runtime.MemStats
+	h.readMemStats(&mem)
+	return RuntimeStats{
+		HeapMB:        mem.HeapAlloc / bytesPerMB,
+		SysMB:         mem.Sys / bytesPerMB,
+		MemoryLimitMB: h.memoryLimitMB,
+		Goroutines:    runtime.NumGoroutine(),
+		GCCycles:      mem.NumGC,
+		UptimeSeconds: int64(h.now().Sub(h.startedAt).Seconds()),
+		Invocations:   h.invocations,
+	}
+}
+
+// reportPanic logs a panicking invocation with its stack and memory use, then
+// re-panics so Lambda still reports the failure and replaces the execution environment.
+// It must be deferred by Handle.
+func (h *Handler) reportPanic(req OIDCProvisionerRequest) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	h.logCrash(req, crashLogRecord{
+		Msg:   crashLogMessage,
+		Panic: fmt.Sprint(recovered),
+		Stack: string(debug.Stack()),
+	})
+	panic(recovered)
+}
+
+// watch checks the invocation's memory use and deadline until the returned function
+// is called, logging memory pressure and an imminent timeout once each, so an
+// invocation Lambda kills leaves a record of why
+func (h *Handler) watch(ctx context.Context, req OIDCProvisionerRequest) func() {
+	deadline, hasDeadline := ctx.Deadline()
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(h.watchInterval)
+		defer ticker.Stop()
+
+		pressureLogged, timeoutLogged := false, false
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			stats := h.runtimeStats()
+			if !pressureLogged && stats.MemoryLimitMB > 0 && stats.SysMB*100 >= stats.MemoryLimitMB*memoryPressurePercent {
+				h.writeCrashRecord(req, crashLogRecord{Msg: memoryPressureLogMessage, Runtime: stats})
+				pressureLogged = true
+			}
+			if remaining := deadline.Sub(h.now()); !timeoutLogged && hasDeadline && remaining <= timeoutLogMargin {
+				h.writeCrashRecord(req, crashLogRecord{Msg: timeoutLogMessage, RemainingMS: remaining.Milliseconds(), Runtime: stats})
+				timeoutLogged = true
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-done
+		})
+	}
+}
+
+// logCrash writes record with the execution environment's current memory use
+func (h *Handler) logCrash(req OIDCProvisionerRequest, record crashLogRecord) {
+	record.Runtime = h.runtimeStats()
+	h.writeCrashRecord(req, record)
+}
+
+// writeCrashRecord writes record for req as a single JSON line
+func (h *Handler) writeCrashRecord(req OIDCProvisionerRequest, record crashLogRecord) {
+	record.CorrelationID = req.CorrelationID
+	record.ClusterID = req.ClusterID
+	record.Action = req.Action
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(h.logOutput, string(line))
+}
+
+// setMemoryLimit sets the Go runtime's soft memory limit to the memory pressure
+// threshold of the function's memory size, so the garbage collector works harder
+// before Lambda kills an execution environment that runs out of memory
+func setMemoryLimit(mb uint64) {
+	if mb == 0 {
+		return
+	}
+	debug.SetMemoryLimit(int64(mb * bytesPerMB * memoryPressurePercent / 100))
+}
@@ -0,0 +1,132 @@
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// crashRecords returns the crash, memory pressure, and timeout records in out
+func crashRecords(t *testing.T, out *bytes.Buffer) []crashLogRecord {
+	var records []crashLogRecord
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var record crashLogRecord
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		if record.Msg != requestLogMessage {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// slowIAMClient takes delay to list OIDC providers, calling onList first
+func slowIAMClient(delay time.Duration, onList func()) *mockIAMClient {
+	return &mockIAMClient{
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return &iam.CreateOpenIDConnectProviderOutput{
+				OpenIDConnectProviderArn: aws.String("arn:aws:iam::123456789012:oidc-provider/example.com"),
+			}, nil
+		},
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			onList()
+			time.Sleep(delay)
+			return &iam.ListOpenIDConnectProvidersOutput{}, nil
+		},
+	}
+}
+
+var provisionRequest = OIDCProvisionerRequest{
+	IssuerURL:     "https://example.com",
+	Thumbprint:    "abc123",
+	ClusterID:     "test-cluster",
+	CorrelationID: "request-1",
+}
+
+func TestHandle_LogsPanic(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewHandler(slowIAMClient(0, func() { panic("provider list corrupted") }), WithMemoryLimit(128))
+	handler.logOutput = &out
+
+	assert.PanicsWithValue(t, "provider list corrupted", func() {
+		_, _ = handler.Handle(context.Background(), provisionRequest)
+	}, "the panic is still reported to Lambda")
+
+	records := crashRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, crashLogMessage, records[0].Msg)
+	assert.Equal(t, "request-1", records[0].CorrelationID)
+	assert.Equal(t, "test-cluster", records[0].ClusterID)
+	assert.Equal(t, "provider list corrupted", records[0].Panic)
+	assert.Contains(t, records[0].Stack, "checkProviderExists")
+	assert.Equal(t, uint64(128), records[0].Runtime.MemoryLimitMB)
+	assert.Equal(t, int64(1), records[0].Runtime.Invocations)
+}
+
+func TestHandle_LogsMemoryPressure(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewHandler(slowIAMClient(20*time.Millisecond, func() {}), WithMemoryLimit(128))
+	handler.logOutput = &out
+	handler.watchInterval = time.Millisecond
+	handler.readMemStats = func(mem *runtime.MemStats) {
+		mem.HeapAlloc = 100 << 20
+		mem.Sys = 120 << 20
+	}
+
+	_, err := handler.Handle(context.Background(), provisionRequest)
+	require.NoError(t, err)
+
+	records := crashRecords(t, &out)
+	require.Len(t, records, 1, "memory pressure is logged once per invocation")
+	assert.Equal(t, memoryPressureLogMessage, records[0].Msg)
+	assert.Equal(t, "request-1", records[0].CorrelationID)
+	assert.Equal(t, uint64(100), records[0].Runtime.HeapMB)
+	assert.Equal(t, uint64(120), records[0].Runtime.SysMB)
+}
+
+func TestHandle_LogsImminentTimeout(t *testing.T) {
+	var out bytes.Buffer
+	handler := NewHandler(slowIAMClient(20*time.Millisecond, func() {}))
+	handler.logOutput = &out
+	handler.watchInterval = time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeoutLogMargin)
+	defer cancel()
+	_, err := handler.Handle(ctx, provisionRequest)
+	require.NoError(t, err)
+
+	records := crashRecords(t, &out)
+	require.Len(t, records, 1)
+	assert.Equal(t, timeoutLogMessage, records[0].Msg)
+	assert.LessOrEqual(t, records[0].RemainingMS, timeoutLogMargin.Milliseconds())
+
+	// Invocations with time to spare log nothing
+	out.Reset()
+	_, err = handler.Handle(context.Background(), provisionRequest)
+	require.NoError(t, err)
+	assert.Empty(t, crashRecords(t, &out))
+}
+
+func TestHandle_PingReportsRuntimeStats(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	handler := NewHandler(&mockIAMClient{}, WithMemoryLimit(256), WithClock(func() time.Time { return now }))
+	now = now.Add(90 * time.Second)
+
+	resp, err := handler.Handle(context.Background(), OIDCProvisionerRequest{Action: actionPing})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Runtime)
+	assert.Equal(t, uint64(256), resp.Runtime.MemoryLimitMB)
+	assert.Equal(t, int64(90), resp.Runtime.UptimeSeconds)
+	assert.Equal(t, int64(1), resp.Runtime.Invocations)
+	assert.Positive(t, resp.Runtime.Goroutines)
+}
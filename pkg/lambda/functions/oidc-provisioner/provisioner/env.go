@@ -0,0 +1,92 @@
+package provisioner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/openshift-online/regional-cli/pkg/oidc"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+// thumbprintFetchTimeout bounds checking an issuer's live thumbprint, so an issuer the
+// function cannot reach does not use up the invocation
+const thumbprintFetchTimeout = 5 * time.Second
+
+// FetchThumbprint fetches an issuer's live thumbprint. validateRequest only refuses
+// issuers named by an internal address, so connections are also refused when the
+// issuer's name resolves to one.
+func FetchThumbprint(ctx context.Context, issuerURL string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, thumbprintFetchTimeout)
+	defer cancel()
+
+	dialer := &net.Dialer{Control: func(network, address string, c syscall.RawConn) error {
+		addrPort, err := netip.ParseAddrPort(address)
+		if err != nil || !publicIssuerHost(addrPort.Addr().String()) {
+			return errors.New("issuer resolves to a non-public address")
+		}
+		return nil
+	}}
+	return oidc.FetchThumbprint(ctx, issuerURL, oidc.WithDialer(dialer))
+}
+
+// OptionsFromEnv returns options applying any user tags, provider metadata, tag key
+// format, issuer allowlist, and bindable role path the deployer passed in the
+// environment, and the memory size Lambda set, which also bounds the Go runtime's memory
+func OptionsFromEnv() []HandlerOption {
+	var opts []HandlerOption
+	if raw := os.Getenv(providerTagsEnvVar); raw != "" {
+		var tags map[string]string
+		if err := json.Unmarshal([]byte(raw), &tags); err != nil {
+			fmt.Printf("Warning: ignoring invalid %s: %v\n", providerTagsEnvVar, err)
+		} else {
+			opts = append(opts, WithProviderTags(tags))
+		}
+	}
+	if raw := os.Getenv(providerMetadataEnvVar); raw != "" {
+		var metadata ProviderMetadata
+		if err := json.Unmarshal([]byte(raw), &metadata); err != nil {
+			fmt.Printf("Warning: ignoring invalid %s: %v\n", providerMetadataEnvVar, err)
+		} else {
+			opts = append(opts, WithProviderMetadata(metadata))
+		}
+	}
+	if raw := os.Getenv(tagkey.EnvVar); raw != "" {
+		format, err := tagkey.Parse(raw)
+		if err != nil {
+			fmt.Printf("Warning: ignoring invalid %s: %v\n", tagkey.EnvVar, err)
+		} else {
+			opts = append(opts, WithTagKeyFormat(format))
+		}
+	}
+	if raw := os.Getenv(allowedIssuerHostsEnvVar); raw != "" {
+		var hosts []string
+		for _, host := range strings.Split(raw, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				hosts = append(hosts, host)
+			}
+		}
+		opts = append(opts, WithIssuerAllowlist(hosts))
+	}
+	if path := os.Getenv(bindableRolePathEnvVar); path != "" {
+		opts = append(opts, WithBindableRolePath(path))
+	}
+	if raw := os.Getenv(memorySizeEnvVar); raw != "" {
+		mb, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			fmt.Printf("Warning: ignoring invalid %s: %v\n", memorySizeEnvVar, err)
+		} else {
+			setMemoryLimit(mb)
+			opts = append(opts, WithMemoryLimit(mb))
+		}
+	}
+	return opts
+}
@@ -0,0 +1,752 @@
+// Package provisioner implements the OIDC provisioner Lambda's handler. The function's
+// main package runs it on Lambda; rosactl dev invoke-local runs it in process.
+package provisioner
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	"github.com/openshift-online/regional-cli/pkg/oidc"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+const (
+	statusCreated       = "created"
+	statusAlreadyExists = "already_exists"
+	statusHealthy       = "healthy"
+	statusBound         = "bound"
+	statusAlreadyBound  = "already_bound"
+	actionPing          = "ping"
+	actionBindRoles     = "bind-roles"
+	tagComponentKey     = "rosa:component"
+	tagComponentValue   = "oidc-provider"
+	tagClusterKey       = "rosa:cluster-id"
+	tagCreatedAtKey     = "rosa:created-at"
+
+	// IAM OIDC providers have no description, so metadata about a provider is tagged
+	tagDescriptionKey = "rosa:description"
+	tagCreatedByKey   = "rosa:created-by"
+	tagVersionKey     = "rosa:rosactl-version"
+	tagEnvironmentKey = "rosa:platform-environment"
+
+	// providerTagsEnvVar holds JSON-encoded user tags set by the deployer
+	providerTagsEnvVar = "ROSA_PROVIDER_TAGS"
+
+	// providerMetadataEnvVar holds the JSON-encoded ProviderMetadata set by the deployer
+	providerMetadataEnvVar = "ROSA_PROVIDER_METADATA"
+
+	// allowedIssuerHostsEnvVar holds a comma-separated issuer host allowlist
+	allowedIssuerHostsEnvVar = "ROSA_ALLOWED_ISSUER_HOSTS"
+
+	// bindableRolePathEnvVar holds the IAM path of the roles bind-roles requests may
+	// update; bind-roles is refused when it is unset
+	bindableRolePathEnvVar = "ROSA_BINDABLE_ROLE_PATH"
+
+	// requestLogMessage identifies the per-request record queried by rosactl logs insights
+	requestLogMessage = "request completed"
+)
+
+// handlerTagKeys are the rosa: tags the handler sets itself; user tags cannot override them
+var handlerTagKeys = []string{tagComponentKey, tagClusterKey, tagCreatedAtKey,
+	tagDescriptionKey, tagCreatedByKey, tagVersionKey, tagEnvironmentKey}
+
+// ProviderMetadata describes the OIDC providers the handler creates and is written to
+// them as tags. Empty fields are not tagged.
+type ProviderMetadata struct {
+	Description         string `json:"description,omitempty"`          // rosa:description
+	CreatedBy           string `json:"created_by,omitempty"`           // rosa:created-by, set on creation only
+	RosactlVersion      string `json:"rosactl_version,omitempty"`      // rosa:rosactl-version, set on creation only
+	PlatformEnvironment string `json:"platform_environment,omitempty"` // rosa:platform-environment
+}
+
+// IAMAPI defines the IAM operations needed by the handler
+type IAMAPI interface {
+	CreateOpenIDConnectProvider(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error)
+	GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error)
+	ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+		optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error)
+	TagOpenIDConnectProvider(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error)
+	GetRole(ctx context.Context, params *iam.GetRoleInput,
+		optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	UpdateAssumeRolePolicy(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error)
+}
+
+// Clock returns the current time
+type Clock func() time.Time
+
+// IDGenerator returns a new unique identifier
+type IDGenerator func() string
+
+// ThumbprintFetcher returns the thumbprint of the certificate chain an issuer serves
+type ThumbprintFetcher func(ctx context.Context, issuerURL string) (string, error)
+
+// Handler handles OIDC provider creation requests
+type Handler struct {
+	iamClient          IAMAPI
+	retryer            *retryer
+	providerTags       map[string]string
+	metadata           ProviderMetadata
+	keys               tagkey.Format
+	allowedIssuerHosts []string
+	bindableRolePath   string
+	ledger             ledger.WriteAPI // Records provisioning requests when set
+	ledgerTable        string
+	now                Clock
+	newID              IDGenerator
+	thumbprints        ThumbprintFetcher
+	logOutput          io.Writer
+	memoryLimitMB      uint64    // Function memory size, for memory pressure reports
+	startedAt          time.Time // Start of the execution environment
+	invocations        int64     // Invocations the execution environment has served
+	readMemStats       func(*runtime.MemStats)
+	watchInterval      time.Duration
+}
+
+// HandlerOption configures optional Handler behavior
+type HandlerOption func(*Handler)
+
+// WithProviderTags adds tags to every OIDC provider the handler creates or reconciles.
+// The handler's own rosa: tags take precedence over these.
+func WithProviderTags(tags map[string]string) HandlerOption {
+	return func(h *Handler) {
+		h.providerTags = tags
+	}
+}
+
+// WithProviderMetadata tags every OIDC provider the handler creates with metadata.
+// The description and platform environment are also applied to existing providers it
+// reconciles; the creator and rosactl version describe creation and are not.
+func WithProviderMetadata(metadata ProviderMetadata) HandlerOption {
+	return func(h *Handler) {
+		h.metadata = metadata
+	}
+}
+
+// WithTagKeyFormat writes the handler's own rosa: tag keys in format, for tag policies
+// that require a different prefix or case
+func WithTagKeyFormat(format tagkey.Format) HandlerOption {
+	return func(h *Handler) {
+		h.keys = format
+	}
+}
+
+// WithIssuerAllowlist restricts issuer URLs to the given hosts and their subdomains.
+// An empty allowlist accepts any host.
+func WithIssuerAllowlist(hosts []string) HandlerOption {
+	return func(h *Handler) {
+		h.allowedIssuerHosts = hosts
+	}
+}
+
+// WithBindableRolePath allows bind-roles requests to update the trust policies of roles
+// under path, such as /rosa-operators/. Without it bind-roles is refused.
+func WithBindableRolePath(path string) HandlerOption {
+	return func(h *Handler) {
+		h.bindableRolePath = path
+	}
+}
+
+// WithClock sets the clock used for created-at tags
+func WithClock(now Clock) HandlerOption {
+	return func(h *Handler) {
+		h.now = now
+	}
+}
+
+// WithIDGenerator sets the generator for request correlation IDs
+func WithIDGenerator(newID IDGenerator) HandlerOption {
+	return func(h *Handler) {
+		h.newID = newID
+	}
+}
+
+// WithThumbprintFetcher checks the thumbprint of each provider the handler creates
+// against the one its issuer serves, fetched with fetch
+func WithThumbprintFetcher(fetch ThumbprintFetcher) HandlerOption {
+	return func(h *Handler) {
+		h.thumbprints = fetch
+	}
+}
+
+// WithLogOutput writes the handler's log records and warnings to w instead of stdout
+func WithLogOutput(w io.Writer) HandlerOption {
+	return func(h *Handler) {
+		h.logOutput = w
+		h.retryer.logOutput = w
+	}
+}
+
+// NewHandler creates a new OIDC provisioner handler
+func NewHandler(iamClient IAMAPI, opts ...HandlerOption) *Handler {
+	h := &Handler{
+		iamClient: iamClient,
+		retryer:   newRetryer(),
+		now:       time.Now,
+		newID:     newUUID,
+		logOutput: os.Stdout,
+
+		readMemStats:  runtime.ReadMemStats,
+		watchInterval: watchInterval,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.startedAt = h.now()
+	return h
+}
+
+// requestLogRecord is the structured log line written for every provisioning request.
+// CloudWatch Logs Insights discovers its fields automatically.
+type requestLogRecord struct {
+	Msg           string `json:"msg"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	ClusterID     string `json:"cluster_id,omitempty"`
+	IssuerURL     string `json:"issuer_url,omitempty"`
+	Status        string `json:"status,omitempty"`
+	ProviderARN   string `json:"provider_arn,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Handle processes the OIDC provisioner request, then logs its outcome and records it
+// in the ledger when one is configured. Requests without a correlation ID are
+// assigned one, which is returned and logged. A panic,
+// memory pressure, or an imminent timeout is also logged, with the execution
+// environment's memory use.
+func (h *Handler) Handle(ctx context.Context, req OIDCProvisionerRequest) (*OIDCProvisionerResponse, error) {
+	if req.CorrelationID == "" {
+		req.CorrelationID = h.newID()
+	}
+	h.invocations++
+	defer h.reportPanic(req)
+
+	stopWatch := h.watch(ctx, req)
+	defer stopWatch()
+	resp, err := h.handle(ctx, req)
+	stopWatch()
+
+	if resp != nil {
+		resp.CorrelationID = req.CorrelationID
+	}
+	if req.Action != actionPing {
+		h.logRequest(req, resp, err)
+		h.recordLedger(ctx, req, resp, err)
+	}
+	return resp, err
+}
+
+// logRequest writes the outcome of a provisioning request as a single JSON line
+func (h *Handler) logRequest(req OIDCProvisionerRequest, resp *OIDCProvisionerResponse, err error) {
+	record := requestLogRecord{
+		Msg:           requestLogMessage,
+		CorrelationID: req.CorrelationID,
+		ClusterID:     req.ClusterID,
+		IssuerURL:     req.IssuerURL,
+	}
+	if resp != nil {
+		record.Status = resp.Status
+		record.ProviderARN = resp.OIDCProviderARN
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	line, marshalErr := json.Marshal(record)
+	if marshalErr != nil {
+		return
+	}
+	fmt.Fprintln(h.logOutput, string(line))
+}
+
+func (h *Handler) handle(ctx context.Context, req OIDCProvisionerRequest) (*OIDCProvisionerResponse, error) {
+	// Health checks confirm invocability without touching IAM
+	if req.Action == actionPing {
+		stats := h.runtimeStats()
+		return &OIDCProvisionerResponse{
+			Status:  statusHealthy,
+			Message: "pong",
+			Runtime: &stats,
+		}, nil
+	}
+
+	// Validate request
+	if err := h.validateRequest(req); err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	if req.Action == actionBindRoles {
+		return h.bindRoles(ctx, req)
+	}
+
+	// Normalize issuer URL (remove trailing slash)
+	issuerURL := strings.TrimSuffix(req.IssuerURL, "/")
+
+	// Check if provider already exists
+	providerARN, exists, err := h.checkProviderExists(ctx, issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if provider exists: %w", err)
+	}
+
+	if exists {
+		// Provider already exists, ensure tags are set
+		if err := h.tagProvider(ctx, providerARN, req.ClusterID, ""); err != nil {
+			return nil, fmt.Errorf("failed to tag existing provider: %w", err)
+		}
+
+		return &OIDCProvisionerResponse{
+			OIDCProviderARN: providerARN,
+			Status:          statusAlreadyExists,
+			Message:         "OIDC provider already exists",
+		}, nil
+	}
+
+	// A provider with the wrong thumbprint is created but never validates a token
+	warnings, err := h.checkThumbprint(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	// Create new OIDC provider
+	providerARN, err = h.createProvider(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OIDC provider: %w", err)
+	}
+
+	// Tag the newly created provider
+	createdAt := h.now().UTC().Format(time.RFC3339)
+	if err := h.tagProvider(ctx, providerARN, req.ClusterID, createdAt); err != nil {
+		// Don't fail if tagging fails (provider is already created)
+		// Just log the error (Lambda logs will capture it)
+		fmt.Fprintf(h.logOutput, "Warning: failed to tag provider: %v\n", err)
+	}
+
+	return &OIDCProvisionerResponse{
+		OIDCProviderARN: providerARN,
+		Status:          statusCreated,
+		Message:         "OIDC provider created successfully",
+		Warnings:        warnings,
+	}, nil
+}
+
+// checkThumbprint compares the request's thumbprint with the one its issuer serves. A
+// mismatch fails a strict request and is returned as a warning otherwise. When the
+// issuer cannot be reached, for example from a function without internet access, the
+// thumbprint is used unverified.
+func (h *Handler) checkThumbprint(ctx context.Context, req OIDCProvisionerRequest) ([]string, error) {
+	if h.thumbprints == nil {
+		return nil, nil
+	}
+
+	live, err := h.thumbprints(ctx, req.IssuerURL)
+	if err != nil {
+		fmt.Fprintf(h.logOutput, "Warning: thumbprint for %s not verified: %v\n", req.IssuerURL, err)
+		return nil, nil
+	}
+	if oidc.NormalizeThumbprint(req.Thumbprint) == live {
+		return nil, nil
+	}
+
+	mismatchErr := &oidc.ThumbprintMismatchError{IssuerURL: req.IssuerURL, Supplied: req.Thumbprint, Live: live}
+	if req.StrictThumbprint {
+		return nil, mismatchErr
+	}
+	fmt.Fprintf(h.logOutput, "Warning: %v\n", mismatchErr)
+	return []string{mismatchErr.Error()}, nil
+}
+
+// validateRequest validates the input request
+func (h *Handler) validateRequest(req OIDCProvisionerRequest) error {
+	switch req.Action {
+	case "":
+	case actionBindRoles:
+		if err := h.validateIssuerURL(req.IssuerURL); err != nil {
+			return err
+		}
+		return h.validateRoleBindings(req.Roles)
+	default:
+		return fmt.Errorf("unsupported action: %s", req.Action)
+	}
+
+	if err := h.validateIssuerURL(req.IssuerURL); err != nil {
+		return err
+	}
+
+	if req.Thumbprint == "" {
+		return errors.New("thumbprint is required")
+	}
+
+	if req.ClusterID == "" {
+		return errors.New("cluster_id is required")
+	}
+
+	return nil
+}
+
+// validateIssuerURL checks that an issuer URL is an https URL on an allowed public host
+func (h *Handler) validateIssuerURL(issuerURL string) error {
+	if issuerURL == "" {
+		return errors.New("issuer_url is required")
+	}
+
+	// Validate URL format
+	parsedURL, err := url.Parse(issuerURL)
+	if err != nil {
+		return fmt.Errorf("invalid issuer_url: %w", err)
+	}
+
+	if parsedURL.Scheme != "https" {
+		return errors.New("issuer_url must use https scheme")
+	}
+
+	if parsedURL.Host == "" {
+		return errors.New("issuer_url must have a valid host")
+	}
+
+	// Requests come from another account, so never accept an issuer the function's
+	// own network would resolve to itself or to internal endpoints
+	if !publicIssuerHost(parsedURL.Hostname()) {
+		return fmt.Errorf("issuer_url host %s is not a public address", parsedURL.Hostname())
+	}
+
+	if !h.issuerHostAllowed(parsedURL.Hostname()) {
+		return fmt.Errorf("issuer_url host %s is not allowed", parsedURL.Hostname())
+	}
+
+	return nil
+}
+
+// validateRoleBindings checks the roles of a bind-roles request. Requests come from
+// another account, so only roles under the deployer's bindable path may be updated.
+func (h *Handler) validateRoleBindings(bindings []RoleBinding) error {
+	if h.bindableRolePath == "" {
+		return errors.New("bind-roles is not enabled; redeploy with a bindable role path to allow it")
+	}
+	if len(bindings) == 0 {
+		return errors.New("roles is required")
+	}
+
+	seen := make(map[string]bool, len(bindings))
+	for _, binding := range bindings {
+		roleARN, err := arn.Parse(binding.RoleARN)
+		if err != nil || roleARN.Service != "iam" || !strings.HasPrefix(roleARN.Resource, "role/") {
+			return fmt.Errorf("role_arn %q is not an IAM role ARN", binding.RoleARN)
+		}
+		if !strings.HasPrefix(strings.TrimPrefix(roleARN.Resource, "role"), h.bindableRolePath) {
+			return fmt.Errorf("role %s is not under the bindable path %s", binding.RoleARN, h.bindableRolePath)
+		}
+		if seen[binding.RoleARN] {
+			return fmt.Errorf("role %s is listed more than once", binding.RoleARN)
+		}
+		seen[binding.RoleARN] = true
+
+		if len(binding.ServiceAccounts) == 0 {
+			return fmt.Errorf("role %s: service_accounts is required", binding.RoleARN)
+		}
+		for _, subject := range binding.ServiceAccounts {
+			if err := oidc.ValidateServiceAccountSubject(subject); err != nil {
+				return fmt.Errorf("role %s: %w", binding.RoleARN, err)
+			}
+		}
+	}
+	return nil
+}
+
+// issuerHostAllowed reports whether host matches the issuer allowlist, either exactly
+// or as a subdomain of an allowed host
+func (h *Handler) issuerHostAllowed(host string) bool {
+	if len(h.allowedIssuerHosts) == 0 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, allowed := range h.allowedIssuerHosts {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "."))
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// publicIssuerHost reports whether host may name an issuer: not localhost, and not an
+// IP address in a loopback, private, link-local, multicast, or unspecified range, such
+// as the instance metadata endpoint 169.254.169.254
+func publicIssuerHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return true // A DNS name
+	}
+	addr = addr.Unmap()
+	return !(addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() || addr.IsMulticast() ||
+		addr.IsUnspecified())
+}
+
+// checkProviderExists checks if an OIDC provider with the given issuer URL already exists
+func (h *Handler) checkProviderExists(ctx context.Context, issuerURL string) (string, bool, error) {
+	// Normalize issuer URL (remove trailing slash)
+	normalizedIssuerURL := strings.TrimSuffix(issuerURL, "/")
+
+	var output *iam.ListOpenIDConnectProvidersOutput
+	err := h.retryer.Do(ctx, "ListOpenIDConnectProviders", func(ctx context.Context) error {
+		var err error
+		output, err = h.iamClient.ListOpenIDConnectProviders(ctx, &iam.ListOpenIDConnectProvidersInput{})
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+
+	// Check each provider to see if it matches our issuer URL
+	for _, provider := range output.OpenIDConnectProviderList {
+		// GetOpenIDConnectProvider returns the URL without the "arn:" prefix
+		var getOutput *iam.GetOpenIDConnectProviderOutput
+		err := h.retryer.Do(ctx, "GetOpenIDConnectProvider", func(ctx context.Context) error {
+			var err error
+			getOutput, err = h.iamClient.GetOpenIDConnectProvider(ctx, &iam.GetOpenIDConnectProviderInput{
+				OpenIDConnectProviderArn: provider.Arn,
+			})
+			return err
+		})
+		if err != nil {
+			// Skipping a throttled provider could miss a match and create a duplicate
+			if isThrottle(err) {
+				return "", false, err
+			}
+			// If we can't get details, skip this provider
+			continue
+		}
+
+		if getOutput.Url != nil && strings.TrimSuffix(*getOutput.Url, "/") == normalizedIssuerURL {
+			return *provider.Arn, true, nil
+		}
+	}
+
+	return "", false, nil
+}
+
+// bindRoles updates the trust policies of the request's roles to allow their service
+// accounts' tokens from the issuer's existing OIDC provider to assume them
+func (h *Handler) bindRoles(ctx context.Context, req OIDCProvisionerRequest) (*OIDCProvisionerResponse, error) {
+	issuerURL := strings.TrimSuffix(req.IssuerURL, "/")
+	providerARN, exists, err := h.checkProviderExists(ctx, issuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if provider exists: %w", err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("no OIDC provider exists for issuer %s; provision it first", issuerURL)
+	}
+
+	// The provider's ARN names the account the function manages
+	provider, err := arn.Parse(providerARN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OIDC provider ARN %s: %w", providerARN, err)
+	}
+	for _, binding := range req.Roles {
+		role, _ := arn.Parse(binding.RoleARN)
+		if role.AccountID != provider.AccountID || role.Partition != provider.Partition {
+			return nil, fmt.Errorf("invalid request: role %s is not in the OIDC provider's account %s", binding.RoleARN, provider.AccountID)
+		}
+	}
+
+	var bound []string
+	for _, binding := range req.Roles {
+		changed, err := h.bindRole(ctx, binding, providerARN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to bind role %s: %w", binding.RoleARN, err)
+		}
+		if changed {
+			bound = append(bound, binding.RoleARN)
+		}
+	}
+
+	if len(bound) == 0 {
+		return &OIDCProvisionerResponse{
+			OIDCProviderARN: providerARN,
+			Status:          statusAlreadyBound,
+			Message:         "roles already trust the OIDC provider",
+		}, nil
+	}
+	return &OIDCProvisionerResponse{
+		OIDCProviderARN: providerARN,
+		Status:          statusBound,
+		Message:         fmt.Sprintf("%d of %d roles bound to the OIDC provider", len(bound), len(req.Roles)),
+		BoundRoles:      bound,
+	}, nil
+}
+
+// bindRole adds the binding's service accounts to the role's trust policy and reports
+// whether the policy changed
+func (h *Handler) bindRole(ctx context.Context, binding RoleBinding, providerARN string) (bool, error) {
+	roleName := binding.RoleARN[strings.LastIndex(binding.RoleARN, "/")+1:]
+
+	var output *iam.GetRoleOutput
+	err := h.retryer.Do(ctx, "GetRole", func(ctx context.Context) error {
+		var err error
+		output, err = h.iamClient.GetRole(ctx, &iam.GetRoleInput{RoleName: aws.String(roleName)})
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	// The ARN's path was validated; check the role the name resolved to has it too
+	if aws.ToString(output.Role.Arn) != binding.RoleARN {
+		return false, fmt.Errorf("role %s has ARN %s", roleName, aws.ToString(output.Role.Arn))
+	}
+
+	document, err := oidc.DecodeTrustPolicy(aws.ToString(output.Role.AssumeRolePolicyDocument))
+	if err != nil {
+		return false, err
+	}
+	updated, changed, err := oidc.BindTrustPolicy(document, providerARN, binding.ServiceAccounts, binding.Audience)
+	if err != nil || !changed {
+		return false, err
+	}
+
+	err = h.retryer.Do(ctx, "UpdateAssumeRolePolicy", func(ctx context.Context) error {
+		_, err := h.iamClient.UpdateAssumeRolePolicy(ctx, &iam.UpdateAssumeRolePolicyInput{
+			RoleName:       aws.String(roleName),
+			PolicyDocument: aws.String(updated),
+		})
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// createProvider creates a new OIDC provider
+func (h *Handler) createProvider(ctx context.Context, req OIDCProvisionerRequest) (string, error) {
+	input := &iam.CreateOpenIDConnectProviderInput{
+		Url:            aws.String(strings.TrimSuffix(req.IssuerURL, "/")),
+		ThumbprintList: []string{req.Thumbprint},
+	}
+
+	// Add client IDs if provided
+	if len(req.ClientIDs) > 0 {
+		input.ClientIDList = req.ClientIDs
+	} else {
+		// Use default client IDs for ROSA
+		input.ClientIDList = []string{
+			"openshift",
+			"sts.amazonaws.com",
+		}
+	}
+
+	var output *iam.CreateOpenIDConnectProviderOutput
+	err := h.retryer.Do(ctx, "CreateOpenIDConnectProvider", func(ctx context.Context) error {
+		var err error
+		output, err = h.iamClient.CreateOpenIDConnectProvider(ctx, input)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *output.OpenIDConnectProviderArn, nil
+}
+
+// tagProvider adds tags to the OIDC provider. createdAt is set only for providers
+// this request created, so reconciling an existing provider keeps its original value.
+func (h *Handler) tagProvider(ctx context.Context, providerARN, clusterID, createdAt string) error {
+	// User tags come first, sorted for stable requests
+	keys := make([]string, 0, len(h.providerTags))
+	for key := range h.providerTags {
+		if !h.isHandlerTag(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var tags []types.Tag
+	for _, key := range keys {
+		tags = append(tags, types.Tag{
+			Key:   aws.String(key),
+			Value: aws.String(h.providerTags[key]),
+		})
+	}
+
+	tags = append(tags, types.Tag{
+		Key:   aws.String(h.keys.Key(tagComponentKey)),
+		Value: aws.String(tagComponentValue),
+	})
+
+	if clusterID != "" {
+		tags = append(tags, types.Tag{
+			Key:   aws.String(h.keys.Key(tagClusterKey)),
+			Value: aws.String(clusterID),
+		})
+	}
+
+	metadata := map[string]string{
+		tagDescriptionKey: h.metadata.Description,
+		tagEnvironmentKey: h.metadata.PlatformEnvironment,
+	}
+	if createdAt != "" {
+		metadata[tagCreatedAtKey] = createdAt
+		metadata[tagCreatedByKey] = h.metadata.CreatedBy
+		metadata[tagVersionKey] = h.metadata.RosactlVersion
+	}
+	for _, key := range handlerTagKeys {
+		if value := metadata[key]; value != "" {
+			tags = append(tags, types.Tag{
+				Key:   aws.String(h.keys.Key(key)),
+				Value: aws.String(value),
+			})
+		}
+	}
+
+	return h.retryer.Do(ctx, "TagOpenIDConnectProvider", func(ctx context.Context) error {
+		_, err := h.iamClient.TagOpenIDConnectProvider(ctx, &iam.TagOpenIDConnectProviderInput{
+			OpenIDConnectProviderArn: aws.String(providerARN),
+			Tags:                     tags,
+		})
+		return err
+	})
+}
+
+// isHandlerTag reports whether key names one of the handler's own tags, in any case
+// or in the tag key format
+func (h *Handler) isHandlerTag(key string) bool {
+	for _, handlerKey := range handlerTagKeys {
+		if h.keys.Matches(key, handlerKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// newUUID returns a random (version 4) UUID
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%032x", time.Now().UnixNano())
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
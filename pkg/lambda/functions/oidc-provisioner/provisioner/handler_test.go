@@ -0,0 +1,926 @@
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockIAMClient is a mock implementation of IAMAPI
+type mockIAMClient struct {
+	createOIDCProviderFunc func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error)
+	getOIDCProviderFunc func(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error)
+	listOIDCProvidersFunc func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+		optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error)
+	tagOIDCProviderFunc func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error)
+	getRoleFunc func(ctx context.Context, params *iam.GetRoleInput,
+		optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error)
+	updateAssumeRolePolicyFunc func(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+		optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error)
+}
+
+func (m *mockIAMClient) CreateOpenIDConnectProvider(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+	optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+	if m.createOIDCProviderFunc != nil {
+		return m.createOIDCProviderFunc(ctx, params, optFns...)
+	}
+	return &iam.CreateOpenIDConnectProviderOutput{}, nil
+}
+
+func (m *mockIAMClient) GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+	optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+	if m.getOIDCProviderFunc != nil {
+		return m.getOIDCProviderFunc(ctx, params, optFns...)
+	}
+	return &iam.GetOpenIDConnectProviderOutput{}, nil
+}
+
+func (m *mockIAMClient) ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+	optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+	if m.listOIDCProvidersFunc != nil {
+		return m.listOIDCProvidersFunc(ctx, params, optFns...)
+	}
+	return &iam.ListOpenIDConnectProvidersOutput{}, nil
+}
+
+func (m *mockIAMClient) TagOpenIDConnectProvider(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+	optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+	if m.tagOIDCProviderFunc != nil {
+		return m.tagOIDCProviderFunc(ctx, params, optFns...)
+	}
+	return &iam.TagOpenIDConnectProviderOutput{}, nil
+}
+
+func (m *mockIAMClient) GetRole(ctx context.Context, params *iam.GetRoleInput,
+	optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	if m.getRoleFunc != nil {
+		return m.getRoleFunc(ctx, params, optFns...)
+	}
+	return &iam.GetRoleOutput{}, nil
+}
+
+func (m *mockIAMClient) UpdateAssumeRolePolicy(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+	optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error) {
+	if m.updateAssumeRolePolicyFunc != nil {
+		return m.updateAssumeRolePolicyFunc(ctx, params, optFns...)
+	}
+	return &iam.UpdateAssumeRolePolicyOutput{}, nil
+}
+
+func TestValidateRequest(t *testing.T) {
+	handler := NewHandler(&mockIAMClient{})
+
+	tests := []struct {
+		name        string
+		req         OIDCProvisionerRequest
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name: "valid request",
+			req: OIDCProvisionerRequest{
+				IssuerURL:  "https://example.com",
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			},
+			expectError: false,
+		},
+		{
+			name: "missing issuer URL",
+			req: OIDCProvisionerRequest{
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			},
+			expectError: true,
+			errorMsg:    "issuer_url is required",
+		},
+		{
+			name: "invalid issuer URL format",
+			req: OIDCProvisionerRequest{
+				IssuerURL:  "not-a-url",
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			},
+			expectError: true,
+		},
+		{
+			name: "non-https issuer URL",
+			req: OIDCProvisionerRequest{
+				IssuerURL:  "http://example.com",
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			},
+			expectError: true,
+			errorMsg:    "issuer_url must use https scheme",
+		},
+		{
+			name: "missing thumbprint",
+			req: OIDCProvisionerRequest{
+				IssuerURL: "https://example.com",
+				ClusterID: "test-cluster",
+			},
+			expectError: true,
+			errorMsg:    "thumbprint is required",
+		},
+		{
+			name: "unsupported action",
+			req: OIDCProvisionerRequest{
+				Action:     "delete",
+				IssuerURL:  "https://example.com",
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			},
+			expectError: true,
+			errorMsg:    "unsupported action",
+		},
+		{
+			name: "missing cluster ID",
+			req: OIDCProvisionerRequest{
+				IssuerURL:  "https://example.com",
+				Thumbprint: "abc123",
+			},
+			expectError: true,
+			errorMsg:    "cluster_id is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := handler.validateRequest(tt.req)
+
+			if tt.expectError {
+				assert.Error(t, err)
+				if tt.errorMsg != "" {
+					assert.Contains(t, err.Error(), tt.errorMsg)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHandle_CreateNewProvider(t *testing.T) {
+	ctx := context.Background()
+	expectedARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+
+	mock := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{},
+			}, nil
+		},
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			assert.Equal(t, "https://example.com", *params.Url)
+			assert.Equal(t, "abc123", params.ThumbprintList[0])
+			assert.Contains(t, params.ClientIDList, "openshift")
+			assert.Contains(t, params.ClientIDList, "sts.amazonaws.com")
+
+			return &iam.CreateOpenIDConnectProviderOutput{
+				OpenIDConnectProviderArn: aws.String(expectedARN),
+			}, nil
+		},
+		tagOIDCProviderFunc: func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+			assert.Equal(t, expectedARN, *params.OpenIDConnectProviderArn)
+			assert.Len(t, params.Tags, 3, "component, cluster ID, and created-at")
+			return &iam.TagOpenIDConnectProviderOutput{}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	req := OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	}
+
+	resp, err := handler.Handle(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, expectedARN, resp.OIDCProviderARN)
+	assert.Equal(t, statusCreated, resp.Status)
+}
+
+func TestHandle_ProviderAlreadyExists(t *testing.T) {
+	ctx := context.Background()
+	existingARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+
+	mock := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{
+					{Arn: aws.String(existingARN)},
+				},
+			}, nil
+		},
+		getOIDCProviderFunc: func(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+			return &iam.GetOpenIDConnectProviderOutput{
+				Url: aws.String("https://example.com"),
+			}, nil
+		},
+		tagOIDCProviderFunc: func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+			return &iam.TagOpenIDConnectProviderOutput{}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	req := OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	}
+
+	resp, err := handler.Handle(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, existingARN, resp.OIDCProviderARN)
+	assert.Equal(t, statusAlreadyExists, resp.Status)
+}
+
+func TestHandle_CreateWithCustomClientIDs(t *testing.T) {
+	ctx := context.Background()
+	expectedARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+	customClientIDs := []string{"custom-client-1", "custom-client-2"}
+
+	mock := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{},
+			}, nil
+		},
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			assert.Equal(t, customClientIDs, params.ClientIDList)
+			return &iam.CreateOpenIDConnectProviderOutput{
+				OpenIDConnectProviderArn: aws.String(expectedARN),
+			}, nil
+		},
+		tagOIDCProviderFunc: func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+			return &iam.TagOpenIDConnectProviderOutput{}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	req := OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+		ClientIDs:  customClientIDs,
+	}
+
+	resp, err := handler.Handle(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, expectedARN, resp.OIDCProviderARN)
+}
+
+func TestHandle_ErrorCases(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       OIDCProvisionerRequest
+		mockSetup func() *mockIAMClient
+		expectErr bool
+	}{
+		{
+			name: "list providers error",
+			req: OIDCProvisionerRequest{
+				IssuerURL:  "https://example.com",
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			},
+			mockSetup: func() *mockIAMClient {
+				return &mockIAMClient{
+					listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+						optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+						return nil, errors.New("list error")
+					},
+				}
+			},
+			expectErr: true,
+		},
+		{
+			name: "create provider error",
+			req: OIDCProvisionerRequest{
+				IssuerURL:  "https://example.com",
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			},
+			mockSetup: func() *mockIAMClient {
+				return &mockIAMClient{
+					listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+						optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+						return &iam.ListOpenIDConnectProvidersOutput{}, nil
+					},
+					createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+						optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+						return nil, errors.New("create error")
+					},
+				}
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := tt.mockSetup()
+			handler := NewHandler(mock)
+
+			_, err := handler.Handle(context.Background(), tt.req)
+
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckProviderExists_TrailingSlashHandling(t *testing.T) {
+	ctx := context.Background()
+	existingARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+
+	mock := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{
+					{Arn: aws.String(existingARN)},
+				},
+			}, nil
+		},
+		getOIDCProviderFunc: func(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+			// Provider URL stored without trailing slash
+			return &iam.GetOpenIDConnectProviderOutput{
+				Url: aws.String("https://example.com"),
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+
+	// Test that we find the provider even if the request has a trailing slash
+	arn, exists, err := handler.checkProviderExists(ctx, "https://example.com/")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, existingARN, arn)
+}
+
+func TestHandle_Ping(t *testing.T) {
+	mock := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			t.Fatal("ping must not call IAM")
+			return nil, nil
+		},
+	}
+
+	handler := NewHandler(mock)
+	resp, err := handler.Handle(context.Background(), OIDCProvisionerRequest{Action: "ping"})
+
+	require.NoError(t, err)
+	assert.Equal(t, statusHealthy, resp.Status)
+	assert.Equal(t, "pong", resp.Message)
+}
+
+func TestHandle_ProviderTags(t *testing.T) {
+	var tags []types.Tag
+	mockClient := &mockIAMClient{
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return &iam.CreateOpenIDConnectProviderOutput{
+				OpenIDConnectProviderArn: aws.String("arn:aws:iam::123456789012:oidc-provider/example.com"),
+			}, nil
+		},
+		tagOIDCProviderFunc: func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+			tags = params.Tags
+			return &iam.TagOpenIDConnectProviderOutput{}, nil
+		},
+	}
+
+	createdAt := time.Date(2026, 3, 14, 9, 26, 53, 0, time.FixedZone("EST", -5*60*60))
+	handler := NewHandler(mockClient,
+		WithProviderTags(map[string]string{
+			"team":          "platform",
+			tagComponentKey: "overridden",
+			tagCreatedAtKey: "overridden",
+		}),
+		WithClock(func() time.Time { return createdAt }))
+
+	_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	})
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	for _, tag := range tags {
+		got[*tag.Key] = *tag.Value
+	}
+	assert.Equal(t, map[string]string{
+		"team":          "platform",
+		tagComponentKey: tagComponentValue,
+		tagClusterKey:   "test-cluster",
+		tagCreatedAtKey: "2026-03-14T14:26:53Z",
+	}, got)
+}
+
+func TestHandle_TagKeyFormat(t *testing.T) {
+	var tags []types.Tag
+	mockClient := &mockIAMClient{
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return &iam.CreateOpenIDConnectProviderOutput{
+				OpenIDConnectProviderArn: aws.String("arn:aws:iam::123456789012:oidc-provider/example.com"),
+			}, nil
+		},
+		tagOIDCProviderFunc: func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+			tags = params.Tags
+			return &iam.TagOpenIDConnectProviderOutput{}, nil
+		},
+	}
+
+	createdAt := time.Date(2026, 3, 14, 14, 26, 53, 0, time.UTC)
+	handler := NewHandler(mockClient,
+		WithTagKeyFormat(tagkey.Format{Case: tagkey.CaseUpper}),
+		WithProviderTags(map[string]string{"team": "platform", "ROSA:COMPONENT": "overridden"}),
+		WithClock(func() time.Time { return createdAt }))
+
+	_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	})
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	for _, tag := range tags {
+		got[*tag.Key] = *tag.Value
+	}
+	assert.Equal(t, map[string]string{
+		"team":            "platform",
+		"ROSA:COMPONENT":  tagComponentValue,
+		"ROSA:CLUSTER-ID": "test-cluster",
+		"ROSA:CREATED-AT": "2026-03-14T14:26:53Z",
+	}, got)
+}
+
+func TestHandle_ExistingProviderKeepsCreatedAt(t *testing.T) {
+	providerARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+	var tagKeys []string
+	mock := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{{Arn: aws.String(providerARN)}},
+			}, nil
+		},
+		getOIDCProviderFunc: func(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+			return &iam.GetOpenIDConnectProviderOutput{Url: aws.String("https://example.com")}, nil
+		},
+		tagOIDCProviderFunc: func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+			for _, tag := range params.Tags {
+				tagKeys = append(tagKeys, *tag.Key)
+			}
+			return &iam.TagOpenIDConnectProviderOutput{}, nil
+		},
+	}
+
+	_, err := NewHandler(mock).Handle(context.Background(), OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{tagComponentKey, tagClusterKey}, tagKeys)
+}
+
+func TestHandle_ProviderMetadata(t *testing.T) {
+	providerARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+	metadata := ProviderMetadata{
+		Description:         "ROSA cluster OIDC provider",
+		CreatedBy:           "rosa-oidc-provisioner",
+		RosactlVersion:      "v1.4.0",
+		PlatformEnvironment: "staging",
+	}
+
+	var tags map[string]string
+	tagged := func(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+		tags = make(map[string]string)
+		for _, tag := range params.Tags {
+			tags[*tag.Key] = *tag.Value
+		}
+		return &iam.TagOpenIDConnectProviderOutput{}, nil
+	}
+	req := OIDCProvisionerRequest{IssuerURL: "https://example.com", Thumbprint: "abc123", ClusterID: "test-cluster"}
+
+	created := &mockIAMClient{
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return &iam.CreateOpenIDConnectProviderOutput{OpenIDConnectProviderArn: aws.String(providerARN)}, nil
+		},
+		tagOIDCProviderFunc: tagged,
+	}
+	handler := NewHandler(created,
+		WithProviderMetadata(metadata),
+		WithProviderTags(map[string]string{tagDescriptionKey: "overridden"}),
+		WithClock(func() time.Time { return time.Date(2026, 3, 14, 14, 26, 53, 0, time.UTC) }))
+	_, err := handler.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		tagComponentKey:   tagComponentValue,
+		tagClusterKey:     "test-cluster",
+		tagCreatedAtKey:   "2026-03-14T14:26:53Z",
+		tagDescriptionKey: "ROSA cluster OIDC provider",
+		tagCreatedByKey:   "rosa-oidc-provisioner",
+		tagVersionKey:     "v1.4.0",
+		tagEnvironmentKey: "staging",
+	}, tags)
+
+	// An existing provider was not created by this deployment, so only its description
+	// and environment are reconciled
+	existing := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{{Arn: aws.String(providerARN)}},
+			}, nil
+		},
+		getOIDCProviderFunc: func(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+			return &iam.GetOpenIDConnectProviderOutput{Url: aws.String("https://example.com")}, nil
+		},
+		tagOIDCProviderFunc: tagged,
+	}
+	_, err = NewHandler(existing, WithProviderMetadata(metadata)).Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		tagComponentKey:   tagComponentValue,
+		tagClusterKey:     "test-cluster",
+		tagDescriptionKey: "ROSA cluster OIDC provider",
+		tagEnvironmentKey: "staging",
+	}, tags)
+}
+
+func TestHandle_CorrelationID(t *testing.T) {
+	handler := NewHandler(&mockIAMClient{}, WithIDGenerator(func() string { return "generated-id" }))
+
+	resp, err := handler.Handle(context.Background(), OIDCProvisionerRequest{Action: actionPing})
+	require.NoError(t, err)
+	assert.Equal(t, "generated-id", resp.CorrelationID)
+
+	resp, err = handler.Handle(context.Background(), OIDCProvisionerRequest{Action: actionPing, CorrelationID: "caller-id"})
+	require.NoError(t, err)
+	assert.Equal(t, "caller-id", resp.CorrelationID)
+}
+
+func TestNewUUID(t *testing.T) {
+	id := newUUID()
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.NotEqual(t, id, newUUID())
+}
+
+func TestValidateRequest_IssuerAllowlist(t *testing.T) {
+	handler := NewHandler(&mockIAMClient{}, WithIssuerAllowlist([]string{"oidc.example.com", ".rosa.example.net"}))
+
+	tests := []struct {
+		issuerURL string
+		allowed   bool
+	}{
+		{"https://oidc.example.com/cluster-1", true},
+		{"https://OIDC.example.com:443/cluster-1", true},
+		{"https://a.b.rosa.example.net", true},
+		{"https://rosa.example.net", true},
+		{"https://example.com", false},
+		{"https://evil-oidc.example.com", false},
+		{"https://oidc.example.com.evil.io", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.issuerURL, func(t *testing.T) {
+			err := handler.validateRequest(OIDCProvisionerRequest{
+				IssuerURL:  tt.issuerURL,
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			})
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "is not allowed")
+			}
+		})
+	}
+}
+
+func TestValidateRequest_NonPublicIssuerHost(t *testing.T) {
+	handler := NewHandler(&mockIAMClient{})
+
+	tests := []struct {
+		issuerURL string
+		allowed   bool
+	}{
+		{"https://oidc.example.com", true},
+		{"https://203.0.113.10", true},
+		{"https://[2001:db8::1]", true},
+		{"https://localhost", false},
+		{"https://oidc.localhost.", false},
+		{"https://127.0.0.1", false},
+		{"https://10.0.0.5/cluster-1", false},
+		{"https://192.168.1.1:8443", false},
+		{"https://169.254.169.254", false},
+		{"https://[::1]", false},
+		{"https://[fd00::1]", false},
+		{"https://[fe80::1]", false},
+		{"https://[::ffff:10.0.0.5]", false},
+		{"https://0.0.0.0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.issuerURL, func(t *testing.T) {
+			err := handler.validateRequest(OIDCProvisionerRequest{
+				IssuerURL:  tt.issuerURL,
+				Thumbprint: "abc123",
+				ClusterID:  "test-cluster",
+			})
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "is not a public address")
+			}
+		})
+	}
+}
+
+func TestHandle_LogsRequestOutcome(t *testing.T) {
+	expectedARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+	mock := &mockIAMClient{
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return &iam.CreateOpenIDConnectProviderOutput{
+				OpenIDConnectProviderArn: aws.String(expectedARN),
+			}, nil
+		},
+	}
+
+	var out bytes.Buffer
+	handler := NewHandler(mock, WithIDGenerator(func() string { return "request-1" }))
+	handler.logOutput = &out
+
+	_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	})
+	require.NoError(t, err)
+
+	_, err = handler.Handle(context.Background(), OIDCProvisionerRequest{IssuerURL: "https://example.com"})
+	require.Error(t, err)
+
+	_, err = handler.Handle(context.Background(), OIDCProvisionerRequest{Action: actionPing})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2, "pings are not logged")
+
+	var created, failed requestLogRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &created))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &failed))
+
+	assert.Equal(t, requestLogRecord{
+		Msg:           requestLogMessage,
+		CorrelationID: "request-1",
+		ClusterID:     "test-cluster",
+		IssuerURL:     "https://example.com",
+		Status:        statusCreated,
+		ProviderARN:   expectedARN,
+	}, created)
+	assert.Equal(t, requestLogMessage, failed.Msg)
+	assert.Empty(t, failed.Status)
+	assert.Contains(t, failed.Error, "thumbprint is required")
+}
+
+func TestHandle_ThumbprintCheck(t *testing.T) {
+	const live = "9e99a48a9960b14926bb7f3b02e22da2b0ab7280"
+	tests := []struct {
+		name         string
+		thumbprint   string
+		strict       bool
+		fetchErr     error
+		wantCreated  bool
+		wantWarnings int
+		wantErr      string
+	}{
+		{name: "matches", thumbprint: "9E:99:A4:8A:99:60:B1:49:26:BB:7F:3B:02:E2:2D:A2:B0:AB:72:80", wantCreated: true},
+		{name: "mismatch warns", thumbprint: "abc123", wantCreated: true, wantWarnings: 1},
+		{name: "strict mismatch fails", thumbprint: "abc123", strict: true, wantErr: "invalid request: thumbprint abc123 does not match"},
+		{name: "issuer unreachable", thumbprint: "abc123", strict: true, fetchErr: errors.New("i/o timeout"), wantCreated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := false
+			mock := &mockIAMClient{
+				createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+					optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+					created = true
+					return &iam.CreateOpenIDConnectProviderOutput{
+						OpenIDConnectProviderArn: aws.String("arn:aws:iam::123456789012:oidc-provider/example.com"),
+					}, nil
+				},
+			}
+			handler := NewHandler(mock, WithThumbprintFetcher(func(ctx context.Context, issuerURL string) (string, error) {
+				assert.Equal(t, "https://example.com", issuerURL)
+				return live, tt.fetchErr
+			}))
+			handler.logOutput = &bytes.Buffer{}
+
+			resp, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+				IssuerURL:        "https://example.com",
+				Thumbprint:       tt.thumbprint,
+				ClusterID:        "test-cluster",
+				StrictThumbprint: tt.strict,
+			})
+
+			assert.Equal(t, tt.wantCreated, created)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, resp.Warnings, tt.wantWarnings)
+		})
+	}
+}
+
+// bindRolesMock serves one OIDC provider for https://example.com and roles whose trust
+// policies are kept in policies, keyed by role name
+func bindRolesMock(policies map[string]string) *mockIAMClient {
+	return &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{
+					{Arn: aws.String("arn:aws:iam::123456789012:oidc-provider/example.com")},
+				},
+			}, nil
+		},
+		getOIDCProviderFunc: func(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+			return &iam.GetOpenIDConnectProviderOutput{Url: aws.String("https://example.com")}, nil
+		},
+		getRoleFunc: func(ctx context.Context, params *iam.GetRoleInput,
+			optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+			name := aws.ToString(params.RoleName)
+			return &iam.GetRoleOutput{Role: &types.Role{
+				RoleName:                 params.RoleName,
+				Arn:                      aws.String("arn:aws:iam::123456789012:role/rosa-operators/" + name),
+				AssumeRolePolicyDocument: aws.String(url.QueryEscape(policies[name])),
+			}}, nil
+		},
+		updateAssumeRolePolicyFunc: func(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+			optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error) {
+			policies[aws.ToString(params.RoleName)] = aws.ToString(params.PolicyDocument)
+			return &iam.UpdateAssumeRolePolicyOutput{}, nil
+		},
+	}
+}
+
+func TestHandle_BindRoles(t *testing.T) {
+	const emptyPolicy = `{"Version":"2012-10-17","Statement":[]}`
+	policies := map[string]string{"ingress": emptyPolicy, "registry": emptyPolicy}
+	handler := NewHandler(bindRolesMock(policies), WithBindableRolePath("/rosa-operators/"))
+	handler.logOutput = &bytes.Buffer{}
+
+	req := OIDCProvisionerRequest{
+		Action:    actionBindRoles,
+		IssuerURL: "https://example.com/",
+		Roles: []RoleBinding{
+			{
+				RoleARN:         "arn:aws:iam::123456789012:role/rosa-operators/ingress",
+				ServiceAccounts: []string{"system:serviceaccount:openshift-ingress-operator:ingress-operator"},
+			},
+			{
+				RoleARN:         "arn:aws:iam::123456789012:role/rosa-operators/registry",
+				ServiceAccounts: []string{"system:serviceaccount:openshift-image-registry:registry"},
+				Audience:        "openshift",
+			},
+		},
+	}
+
+	resp, err := handler.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, statusBound, resp.Status)
+	assert.Equal(t, "arn:aws:iam::123456789012:oidc-provider/example.com", resp.OIDCProviderARN)
+	assert.Equal(t, []string{req.Roles[0].RoleARN, req.Roles[1].RoleARN}, resp.BoundRoles)
+	assert.Contains(t, policies["ingress"], `"example.com:sub":["system:serviceaccount:openshift-ingress-operator:ingress-operator"]`)
+	assert.Contains(t, policies["registry"], `"example.com:aud":"openshift"`)
+
+	resp, err = handler.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, statusAlreadyBound, resp.Status)
+	assert.Empty(t, resp.BoundRoles)
+}
+
+func TestHandle_BindRolesRefused(t *testing.T) {
+	binding := RoleBinding{
+		RoleARN:         "arn:aws:iam::123456789012:role/rosa-operators/ingress",
+		ServiceAccounts: []string{"system:serviceaccount:openshift-ingress-operator:ingress-operator"},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		roles   []RoleBinding
+		wantErr string
+	}{
+		{name: "not enabled", roles: []RoleBinding{binding}, wantErr: "bind-roles is not enabled"},
+		{name: "no roles", path: "/rosa-operators/", wantErr: "roles is required"},
+		{
+			name:    "outside the path",
+			path:    "/rosa-operators/",
+			roles:   []RoleBinding{{RoleARN: "arn:aws:iam::123456789012:role/admin", ServiceAccounts: binding.ServiceAccounts}},
+			wantErr: "is not under the bindable path /rosa-operators/",
+		},
+		{
+			name:    "not a role",
+			path:    "/rosa-operators/",
+			roles:   []RoleBinding{{RoleARN: "arn:aws:iam::123456789012:user/rosa-operators/u", ServiceAccounts: binding.ServiceAccounts}},
+			wantErr: "is not an IAM role ARN",
+		},
+		{
+			name:    "wildcard subject",
+			path:    "/rosa-operators/",
+			roles:   []RoleBinding{{RoleARN: binding.RoleARN, ServiceAccounts: []string{"system:serviceaccount:ns:*"}}},
+			wantErr: "must be a service account",
+		},
+		{
+			name:    "other account",
+			path:    "/rosa-operators/",
+			roles:   []RoleBinding{{RoleARN: "arn:aws:iam::210987654321:role/rosa-operators/ingress", ServiceAccounts: binding.ServiceAccounts}},
+			wantErr: "is not in the OIDC provider's account 123456789012",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := bindRolesMock(map[string]string{})
+			mock.updateAssumeRolePolicyFunc = func(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+				optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error) {
+				t.Fatal("trust policy must not be updated")
+				return nil, nil
+			}
+			handler := NewHandler(mock, WithBindableRolePath(tt.path))
+			handler.logOutput = &bytes.Buffer{}
+
+			_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+				Action:    actionBindRoles,
+				IssuerURL: "https://example.com",
+				Roles:     tt.roles,
+			})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestHandle_BindRolesWithoutProvider(t *testing.T) {
+	handler := NewHandler(&mockIAMClient{}, WithBindableRolePath("/rosa-operators/"))
+	handler.logOutput = &bytes.Buffer{}
+
+	_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		Action:    actionBindRoles,
+		IssuerURL: "https://example.com",
+		Roles: []RoleBinding{{
+			RoleARN:         "arn:aws:iam::123456789012:role/rosa-operators/ingress",
+			ServiceAccounts: []string{"system:serviceaccount:openshift-ingress-operator:ingress-operator"},
+		}},
+	})
+	assert.ErrorContains(t, err, "no OIDC provider exists for issuer https://example.com; provision it first")
+}
@@ -0,0 +1,54 @@
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+)
+
+// WithLedger records every provisioning and bind-roles request in the DynamoDB table
+// named table, as rosactl oidc history reads it. Pings and requests that fail
+// validation are not recorded, and a failed write is logged without failing the request.
+func WithLedger(client ledger.WriteAPI, table string) HandlerOption {
+	return func(h *Handler) {
+		h.ledger = client
+		h.ledgerTable = table
+	}
+}
+
+// recordLedger writes the outcome of a provisioning request to the ledger, if enabled.
+// The request's correlation ID keys the entry, so a retried request is recorded once.
+func (h *Handler) recordLedger(ctx context.Context, req OIDCProvisionerRequest, resp *OIDCProvisionerResponse, err error) {
+	if h.ledger == nil || h.validateRequest(req) != nil {
+		return
+	}
+
+	entry := ledger.Entry{
+		IssuerURL:  strings.TrimSuffix(req.IssuerURL, "/"),
+		RequestID:  req.CorrelationID,
+		ClusterID:  req.ClusterID,
+		Action:     ledger.ActionProvision,
+		RecordedAt: h.now().UTC(),
+	}
+	if req.Action == actionBindRoles {
+		entry.Action = ledger.ActionBindRoles
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		entry.LambdaRequestID = lc.AwsRequestID
+	}
+	if resp != nil {
+		entry.Status = resp.Status
+		entry.ProviderARN = resp.OIDCProviderARN
+	}
+	if err != nil {
+		entry.Status = ledger.StatusFailed
+		entry.Error = err.Error()
+	}
+
+	if _, err := ledger.Record(ctx, h.ledger, h.ledgerTable, entry); err != nil {
+		fmt.Fprintf(h.logOutput, "Warning: request %s not recorded in ledger table %s: %v\n", req.CorrelationID, h.ledgerTable, err)
+	}
+}
@@ -0,0 +1,139 @@
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	dynamodbTypes "github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/openshift-online/regional-cli/pkg/ledger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLedger records the items written to the ledger
+type mockLedger struct {
+	puts []*dynamodb.PutItemInput
+	err  error
+}
+
+func (m *mockLedger) PutItem(ctx context.Context, params *dynamodb.PutItemInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	m.puts = append(m.puts, params)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// attribute returns a string attribute of the nth item written
+func (m *mockLedger) attribute(n int, name string) string {
+	if value, ok := m.puts[n].Item[name].(*dynamodbTypes.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
+
+func TestHandle_RecordsLedger(t *testing.T) {
+	expectedARN := "arn:aws:iam::123456789012:oidc-provider/example.com"
+	mock := &mockIAMClient{
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return &iam.CreateOpenIDConnectProviderOutput{OpenIDConnectProviderArn: aws.String(expectedARN)}, nil
+		},
+	}
+	table := &mockLedger{}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	handler := NewHandler(mock, WithLedger(table, "ledger"), WithClock(func() time.Time { return now }))
+	handler.logOutput = &bytes.Buffer{}
+
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{AwsRequestID: "invocation-1"})
+	req := provisionRequest
+	req.IssuerURL = "https://example.com/"
+	_, err := handler.Handle(ctx, req)
+	require.NoError(t, err)
+
+	require.Len(t, table.puts, 1)
+	assert.Equal(t, "ledger", aws.ToString(table.puts[0].TableName))
+	assert.Equal(t, "https://example.com", table.attribute(0, ledger.AttributeIssuerURL), "the issuer is recorded without its trailing slash")
+	assert.Equal(t, "request-1", table.attribute(0, ledger.AttributeRequestID))
+	assert.Equal(t, "invocation-1", table.attribute(0, ledger.AttributeLambdaRequestID))
+	assert.Equal(t, "test-cluster", table.attribute(0, ledger.AttributeClusterID))
+	assert.Equal(t, ledger.ActionProvision, table.attribute(0, ledger.AttributeAction))
+	assert.Equal(t, statusCreated, table.attribute(0, ledger.AttributeStatus))
+	assert.Equal(t, expectedARN, table.attribute(0, ledger.AttributeProviderARN))
+	assert.Equal(t, "2026-03-01T12:00:00.000Z", table.attribute(0, ledger.AttributeRecordedAt))
+	assert.NotNil(t, table.puts[0].ConditionExpression, "writes are conditional")
+}
+
+func TestHandle_RecordsLedgerFailure(t *testing.T) {
+	mock := &mockIAMClient{
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return nil, errors.New("create error")
+		},
+	}
+	table := &mockLedger{}
+	handler := NewHandler(mock, WithLedger(table, "ledger"))
+	handler.logOutput = &bytes.Buffer{}
+
+	_, err := handler.Handle(context.Background(), provisionRequest)
+	require.Error(t, err)
+
+	require.Len(t, table.puts, 1)
+	assert.Equal(t, ledger.StatusFailed, table.attribute(0, ledger.AttributeStatus))
+	assert.Contains(t, table.attribute(0, ledger.AttributeError), "create error")
+}
+
+func TestHandle_LedgerSkipsUnrecordedRequests(t *testing.T) {
+	table := &mockLedger{}
+	handler := NewHandler(&mockIAMClient{}, WithLedger(table, "ledger"))
+	handler.logOutput = &bytes.Buffer{}
+
+	_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{Action: actionPing})
+	require.NoError(t, err)
+	_, err = handler.Handle(context.Background(), OIDCProvisionerRequest{IssuerURL: "http://example.com", ClusterID: "test-cluster"})
+	require.Error(t, err)
+
+	assert.Empty(t, table.puts, "pings and invalid requests are not recorded")
+}
+
+func TestHandle_LedgerBindRoles(t *testing.T) {
+	const emptyPolicy = `{"Version":"2012-10-17","Statement":[]}`
+	table := &mockLedger{}
+	handler := NewHandler(bindRolesMock(map[string]string{"ingress": emptyPolicy}),
+		WithBindableRolePath("/rosa-operators/"), WithLedger(table, "ledger"))
+	handler.logOutput = &bytes.Buffer{}
+
+	_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		Action:    actionBindRoles,
+		IssuerURL: "https://example.com",
+		Roles: []RoleBinding{{
+			RoleARN:         "arn:aws:iam::123456789012:role/rosa-operators/ingress",
+			ServiceAccounts: []string{"system:serviceaccount:openshift-ingress-operator:ingress-operator"},
+		}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, table.puts, 1)
+	assert.Equal(t, ledger.ActionBindRoles, table.attribute(0, ledger.AttributeAction))
+	assert.Equal(t, statusBound, table.attribute(0, ledger.AttributeStatus))
+}
+
+func TestHandle_LedgerWriteErrorOnlyWarns(t *testing.T) {
+	var out bytes.Buffer
+	table := &mockLedger{err: errors.New("access denied")}
+	handler := NewHandler(slowIAMClient(0, func() {}), WithLedger(table, "ledger"))
+	handler.logOutput = &out
+
+	_, err := handler.Handle(context.Background(), provisionRequest)
+	require.NoError(t, err, "a failed ledger write does not fail the request")
+	assert.Contains(t, out.String(), "Warning: request request-1 not recorded in ledger table ledger")
+	assert.Contains(t, out.String(), "access denied")
+}
@@ -0,0 +1,204 @@
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+const (
+	retryMaxAttempts = 8
+	retryBaseDelay   = 100 * time.Millisecond
+	retryMaxDelay    = 5 * time.Second
+
+	// deadlineSafetyMargin is kept free before the invocation deadline so the
+	// handler can still return a useful error instead of being killed
+	deadlineSafetyMargin = 2 * time.Second
+)
+
+// retryer retries throttled and transient IAM errors with jittered exponential
+// backoff. After throttling it also paces subsequent calls, and it never sleeps
+// past the invocation deadline. State is shared across invocations that reuse
+// the same execution environment.
+type retryer struct {
+	mu     sync.Mutex
+	pacing time.Duration // delay before each call while IAM is throttling
+
+	now       func() time.Time
+	sleep     func(ctx context.Context, d time.Duration) error
+	jitter    func(d time.Duration) time.Duration
+	logOutput io.Writer // Receives a warning for each retried attempt
+}
+
+// newRetryer creates a retryer using the wall clock and random jitter
+func newRetryer() *retryer {
+	return &retryer{
+		now:       time.Now,
+		sleep:     sleepContext,
+		logOutput: os.Stdout,
+		jitter: func(d time.Duration) time.Duration {
+			if d <= 0 {
+				return 0
+			}
+			return time.Duration(rand.Int63n(int64(d)))
+		},
+	}
+}
+
+// Do calls fn until it succeeds, fails with a non-retryable error, or the attempt
+// or deadline budget is exhausted
+func (r *retryer) Do(ctx context.Context, operation string, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		if pacing := r.currentPacing(); pacing > 0 && r.withinBudget(ctx, pacing) {
+			if err := r.sleep(ctx, pacing); err != nil {
+				return err
+			}
+		}
+
+		err := fn(ctx)
+		if err == nil {
+			r.recordSuccess()
+			return nil
+		}
+
+		throttled := isThrottle(err)
+		if !throttled && !isRetryable(err) {
+			return err
+		}
+		if throttled {
+			r.recordThrottle()
+		}
+
+		if attempt >= retryMaxAttempts {
+			return fmt.Errorf("%s failed after %d attempts: %w", operation, attempt, err)
+		}
+
+		delay := r.backoff(attempt)
+		if retryAfter, ok := retryAfterDelay(err, r.now()); ok && retryAfter > delay {
+			delay = retryAfter
+		}
+
+		if !r.withinBudget(ctx, delay) {
+			return fmt.Errorf("%s failed, not enough time left before the invocation deadline to retry: %w", operation, err)
+		}
+
+		fmt.Fprintf(r.logOutput, "Warning: %s attempt %d failed, retrying in %s: %v\n", operation, attempt, delay, err)
+		if err := r.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// backoff returns the jittered exponential delay before the given retry attempt
+func (r *retryer) backoff(attempt int) time.Duration {
+	delay := retryBaseDelay << (attempt - 1)
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+	return delay/2 + r.jitter(delay/2)
+}
+
+// withinBudget reports whether waiting d still leaves the safety margin before the deadline
+func (r *retryer) withinBudget(ctx context.Context, d time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return true
+	}
+	return r.now().Add(d + deadlineSafetyMargin).Before(deadline)
+}
+
+func (r *retryer) currentPacing() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.pacing
+}
+
+// recordThrottle slows down subsequent calls
+func (r *retryer) recordThrottle() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.pacing == 0 {
+		r.pacing = retryBaseDelay
+	} else {
+		r.pacing *= 2
+	}
+	if r.pacing > retryMaxDelay {
+		r.pacing = retryMaxDelay
+	}
+}
+
+// recordSuccess speeds calls back up once IAM stops throttling
+func (r *retryer) recordSuccess() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.pacing /= 2
+	if r.pacing < retryBaseDelay/4 {
+		r.pacing = 0
+	}
+}
+
+// isThrottle reports whether err is an AWS throttling error
+func isThrottle(err error) bool {
+	return retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err) == aws.TrueTernary
+}
+
+// isRetryable reports whether err is a transient error worth retrying
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// retryAfterDelay returns the delay requested by a Retry-After response header,
+// given in seconds or as an HTTP date
+func retryAfterDelay(err error, now time.Time) (time.Duration, bool) {
+	var respErr *smithyhttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Response == nil {
+		return 0, false
+	}
+
+	value := respErr.Response.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
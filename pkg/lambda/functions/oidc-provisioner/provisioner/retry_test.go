@@ -0,0 +1,245 @@
+package provisioner
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errThrottled = &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"}
+
+// newTestRetryer returns a retryer with a fixed clock that records sleeps instead of waiting
+func newTestRetryer(now time.Time) (*retryer, *[]time.Duration) {
+	var sleeps []time.Duration
+	r := newRetryer()
+	r.now = func() time.Time { return now }
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		return nil
+	}
+	r.jitter = func(d time.Duration) time.Duration { return 0 }
+	return r, &sleeps
+}
+
+func throttledWithRetryAfter(value string) error {
+	return &smithyhttp.ResponseError{
+		Response: &smithyhttp.Response{Response: &http.Response{
+			StatusCode: http.StatusTooManyRequests,
+			Header:     http.Header{"Retry-After": []string{value}},
+		}},
+		Err: errThrottled,
+	}
+}
+
+func TestRetryer_RetriesThrottling(t *testing.T) {
+	r, sleeps := newTestRetryer(time.Now())
+
+	calls := 0
+	err := r.Do(context.Background(), "ListOpenIDConnectProviders", func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errThrottled
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	// Backoff after each throttle, with pacing before each later attempt
+	assert.Equal(t, []time.Duration{
+		50 * time.Millisecond,  // backoff after attempt 1
+		100 * time.Millisecond, // pacing before attempt 2
+		100 * time.Millisecond, // backoff after attempt 2
+		200 * time.Millisecond, // pacing before attempt 3
+	}, *sleeps)
+	assert.Equal(t, 100*time.Millisecond, r.currentPacing())
+}
+
+func TestRetryer_NonRetryableError(t *testing.T) {
+	r, sleeps := newTestRetryer(time.Now())
+	expected := &smithy.GenericAPIError{Code: "EntityAlreadyExists"}
+
+	calls := 0
+	err := r.Do(context.Background(), "CreateOpenIDConnectProvider", func(ctx context.Context) error {
+		calls++
+		return expected
+	})
+
+	assert.ErrorIs(t, err, expected)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, *sleeps)
+}
+
+func TestRetryer_MaxAttempts(t *testing.T) {
+	r, _ := newTestRetryer(time.Now())
+
+	calls := 0
+	err := r.Do(context.Background(), "ListOpenIDConnectProviders", func(ctx context.Context) error {
+		calls++
+		return errThrottled
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, retryMaxAttempts, calls)
+	assert.True(t, isThrottle(err))
+	assert.Contains(t, err.Error(), "failed after 8 attempts")
+}
+
+func TestRetryer_StopsBeforeDeadline(t *testing.T) {
+	now := time.Now()
+	r, sleeps := newTestRetryer(now)
+
+	ctx, cancel := context.WithDeadline(context.Background(), now.Add(deadlineSafetyMargin+10*time.Millisecond))
+	defer cancel()
+
+	calls := 0
+	err := r.Do(ctx, "ListOpenIDConnectProviders", func(ctx context.Context) error {
+		calls++
+		return errThrottled
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, *sleeps)
+	assert.Contains(t, err.Error(), "invocation deadline")
+}
+
+func TestRetryer_HonorsRetryAfter(t *testing.T) {
+	r, sleeps := newTestRetryer(time.Now())
+
+	calls := 0
+	err := r.Do(context.Background(), "TagOpenIDConnectProvider", func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return throttledWithRetryAfter("3")
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	require.NotEmpty(t, *sleeps)
+	assert.Equal(t, 3*time.Second, (*sleeps)[0])
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	d, ok := retryAfterDelay(throttledWithRetryAfter("2"), now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+
+	d, ok = retryAfterDelay(throttledWithRetryAfter(now.Add(5*time.Second).Format(http.TimeFormat)), now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, d)
+
+	_, ok = retryAfterDelay(throttledWithRetryAfter("soon"), now)
+	assert.False(t, ok)
+
+	_, ok = retryAfterDelay(errThrottled, now)
+	assert.False(t, ok)
+}
+
+func TestRetryer_PacingDecays(t *testing.T) {
+	r, _ := newTestRetryer(time.Now())
+
+	r.recordThrottle()
+	r.recordThrottle()
+	assert.Equal(t, 200*time.Millisecond, r.currentPacing())
+
+	r.recordSuccess()
+	assert.Equal(t, 100*time.Millisecond, r.currentPacing())
+	r.recordSuccess()
+	r.recordSuccess()
+	r.recordSuccess()
+	assert.Zero(t, r.currentPacing())
+}
+
+func TestRetryer_ContextCanceled(t *testing.T) {
+	r, _ := newTestRetryer(time.Now())
+
+	err := r.Do(context.Background(), "ListOpenIDConnectProviders", func(ctx context.Context) error {
+		return context.Canceled
+	})
+
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestHandle_ThrottledListRetried(t *testing.T) {
+	calls := 0
+	mockClient := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			calls++
+			if calls < 3 {
+				return nil, errThrottled
+			}
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{},
+			}, nil
+		},
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			return &iam.CreateOpenIDConnectProviderOutput{
+				OpenIDConnectProviderArn: aws.String("arn:aws:iam::123456789012:oidc-provider/example.com"),
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockClient)
+	handler.retryer, _ = newTestRetryer(time.Now())
+
+	resp, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, statusCreated, resp.Status)
+	assert.Equal(t, 3, calls)
+}
+
+func TestHandle_ThrottledGetDoesNotSkipProvider(t *testing.T) {
+	created := false
+	mockClient := &mockIAMClient{
+		listOIDCProvidersFunc: func(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+			optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+			return &iam.ListOpenIDConnectProvidersOutput{
+				OpenIDConnectProviderList: []types.OpenIDConnectProviderListEntry{
+					{Arn: aws.String("arn:aws:iam::123456789012:oidc-provider/example.com")},
+				},
+			}, nil
+		},
+		getOIDCProviderFunc: func(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+			return nil, errThrottled
+		},
+		createOIDCProviderFunc: func(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+			optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+			created = true
+			return &iam.CreateOpenIDConnectProviderOutput{}, nil
+		},
+	}
+
+	handler := NewHandler(mockClient)
+	handler.retryer, _ = newTestRetryer(time.Now())
+
+	_, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	})
+
+	require.Error(t, err)
+	assert.False(t, created, "provider must not be created when existence could not be checked")
+}
@@ -0,0 +1,163 @@
+package provisioner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+)
+
+// stubAccountID is the account StubIAM's providers are created in by default
+const stubAccountID = "000000000000"
+
+// StubIAM is an in-memory IAMAPI for running the handler without AWS, as
+// rosactl dev invoke-local --dry-run does. Changes are kept only in memory and
+// described on the stub's output as they are made.
+type StubIAM struct {
+	mu        sync.Mutex
+	accountID string
+	output    io.Writer
+	providers map[string]string // Issuer URL by provider ARN
+	roles     map[string]*types.Role
+}
+
+// NewStubIAM creates a StubIAM without providers or roles that describes its
+// changes on output. Its providers are created in accountID, or in 000000000000
+// when accountID is empty.
+func NewStubIAM(accountID string, output io.Writer) *StubIAM {
+	if accountID == "" {
+		accountID = stubAccountID
+	}
+	return &StubIAM{
+		accountID: accountID,
+		output:    output,
+		providers: make(map[string]string),
+		roles:     make(map[string]*types.Role),
+	}
+}
+
+// AddProvider adds an existing OIDC provider for issuerURL and returns its ARN
+func (s *StubIAM) AddProvider(issuerURL string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+	providerARN := s.providerARN(issuerURL)
+	s.providers[providerARN] = issuerURL
+	return providerARN
+}
+
+// AddRole adds an existing role with the given ARN and trust policy
+func (s *StubIAM) AddRole(roleARN, trustPolicy string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	name := roleARN[strings.LastIndex(roleARN, "/")+1:]
+	s.roles[name] = &types.Role{
+		Arn:                      aws.String(roleARN),
+		RoleName:                 aws.String(name),
+		AssumeRolePolicyDocument: aws.String(trustPolicy),
+	}
+}
+
+func (s *StubIAM) CreateOpenIDConnectProvider(ctx context.Context, params *iam.CreateOpenIDConnectProviderInput,
+	optFns ...func(*iam.Options)) (*iam.CreateOpenIDConnectProviderOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issuerURL := aws.ToString(params.Url)
+	providerARN := s.providerARN(issuerURL)
+	if _, ok := s.providers[providerARN]; ok {
+		return nil, &types.EntityAlreadyExistsException{
+			Message: aws.String(fmt.Sprintf("Provider with url %s already exists", issuerURL)),
+		}
+	}
+	s.providers[providerARN] = issuerURL
+	fmt.Fprintf(s.output, "dry run: created OIDC provider %s (client IDs %s, thumbprint %s)\n",
+		providerARN, strings.Join(params.ClientIDList, ", "), strings.Join(params.ThumbprintList, ", "))
+	return &iam.CreateOpenIDConnectProviderOutput{OpenIDConnectProviderArn: aws.String(providerARN)}, nil
+}
+
+func (s *StubIAM) GetOpenIDConnectProvider(ctx context.Context, params *iam.GetOpenIDConnectProviderInput,
+	optFns ...func(*iam.Options)) (*iam.GetOpenIDConnectProviderOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issuerURL, ok := s.providers[aws.ToString(params.OpenIDConnectProviderArn)]
+	if !ok {
+		return nil, &types.NoSuchEntityException{Message: aws.String("OIDC provider not found")}
+	}
+	return &iam.GetOpenIDConnectProviderOutput{Url: aws.String(issuerURL)}, nil
+}
+
+func (s *StubIAM) ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+	optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	arns := make([]string, 0, len(s.providers))
+	for providerARN := range s.providers {
+		arns = append(arns, providerARN)
+	}
+	sort.Strings(arns)
+	output := &iam.ListOpenIDConnectProvidersOutput{}
+	for _, providerARN := range arns {
+		output.OpenIDConnectProviderList = append(output.OpenIDConnectProviderList,
+			types.OpenIDConnectProviderListEntry{Arn: aws.String(providerARN)})
+	}
+	return output, nil
+}
+
+func (s *StubIAM) TagOpenIDConnectProvider(ctx context.Context, params *iam.TagOpenIDConnectProviderInput,
+	optFns ...func(*iam.Options)) (*iam.TagOpenIDConnectProviderOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	providerARN := aws.ToString(params.OpenIDConnectProviderArn)
+	if _, ok := s.providers[providerARN]; !ok {
+		return nil, &types.NoSuchEntityException{Message: aws.String("OIDC provider not found")}
+	}
+	tags := make([]string, 0, len(params.Tags))
+	for _, tag := range params.Tags {
+		tags = append(tags, aws.ToString(tag.Key)+"="+aws.ToString(tag.Value))
+	}
+	fmt.Fprintf(s.output, "dry run: tagged OIDC provider %s with %s\n", providerARN, strings.Join(tags, ", "))
+	return &iam.TagOpenIDConnectProviderOutput{}, nil
+}
+
+func (s *StubIAM) GetRole(ctx context.Context, params *iam.GetRoleInput,
+	optFns ...func(*iam.Options)) (*iam.GetRoleOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	role, ok := s.roles[aws.ToString(params.RoleName)]
+	if !ok {
+		return nil, &types.NoSuchEntityException{
+			Message: aws.String(fmt.Sprintf("The role with name %s cannot be found.", aws.ToString(params.RoleName))),
+		}
+	}
+	// IAM returns trust policies URL-encoded
+	copied := *role
+	copied.AssumeRolePolicyDocument = aws.String(url.QueryEscape(aws.ToString(role.AssumeRolePolicyDocument)))
+	return &iam.GetRoleOutput{Role: &copied}, nil
+}
+
+func (s *StubIAM) UpdateAssumeRolePolicy(ctx context.Context, params *iam.UpdateAssumeRolePolicyInput,
+	optFns ...func(*iam.Options)) (*iam.UpdateAssumeRolePolicyOutput, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	role, ok := s.roles[aws.ToString(params.RoleName)]
+	if !ok {
+		return nil, &types.NoSuchEntityException{
+			Message: aws.String(fmt.Sprintf("The role with name %s cannot be found.", aws.ToString(params.RoleName))),
+		}
+	}
+	role.AssumeRolePolicyDocument = params.PolicyDocument
+	fmt.Fprintf(s.output, "dry run: updated trust policy of role %s:\n%s\n", aws.ToString(role.Arn), aws.ToString(params.PolicyDocument))
+	return &iam.UpdateAssumeRolePolicyOutput{}, nil
+}
+
+// providerARN returns the ARN IAM gives the OIDC provider of issuerURL
+func (s *StubIAM) providerARN(issuerURL string) string {
+	return "arn:aws:iam::" + s.accountID + ":oidc-provider/" + strings.TrimPrefix(issuerURL, "https://")
+}
@@ -0,0 +1,54 @@
+package provisioner
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStubIAM_Provision(t *testing.T) {
+	var changes bytes.Buffer
+	handler := NewHandler(NewStubIAM("", &changes), WithLogOutput(&bytes.Buffer{}))
+	req := OIDCProvisionerRequest{
+		IssuerURL:  "https://oidc.example.com/cluster-1/",
+		Thumbprint: "9e99a48a9960b14926bb7f3b02e22da2b0ab7280",
+		ClusterID:  "cluster-1",
+	}
+
+	resp, err := handler.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, statusCreated, resp.Status)
+	assert.Equal(t, "arn:aws:iam::000000000000:oidc-provider/oidc.example.com/cluster-1", resp.OIDCProviderARN)
+	assert.Contains(t, changes.String(), "dry run: created OIDC provider "+resp.OIDCProviderARN)
+	assert.Contains(t, changes.String(), "rosa:cluster-id=cluster-1")
+
+	resp, err = handler.Handle(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, statusAlreadyExists, resp.Status)
+}
+
+func TestStubIAM_BindRoles(t *testing.T) {
+	const roleARN = "arn:aws:iam::123456789012:role/rosa-operators/ingress"
+	var changes bytes.Buffer
+	stub := NewStubIAM("123456789012", &changes)
+	providerARN := stub.AddProvider("https://oidc.example.com/cluster-1")
+	stub.AddRole(roleARN, `{"Version":"2012-10-17","Statement":[]}`)
+	handler := NewHandler(stub, WithBindableRolePath("/rosa-operators/"), WithLogOutput(&bytes.Buffer{}))
+
+	resp, err := handler.Handle(context.Background(), OIDCProvisionerRequest{
+		Action:    actionBindRoles,
+		IssuerURL: "https://oidc.example.com/cluster-1",
+		Roles: []RoleBinding{{
+			RoleARN:         roleARN,
+			ServiceAccounts: []string{"system:serviceaccount:openshift-ingress-operator:ingress-operator"},
+		}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, statusBound, resp.Status)
+	assert.Equal(t, providerARN, resp.OIDCProviderARN)
+	assert.Contains(t, changes.String(), "dry run: updated trust policy of role "+roleARN)
+	assert.Contains(t, changes.String(), "system:serviceaccount:openshift-ingress-operator:ingress-operator")
+}
@@ -0,0 +1,62 @@
+package provisioner
+
+// The JSON Schemas published by rosactl provisioner schema are generated from the
+// request and response types. The jsonschema struct tags refine them: required,
+// requiredWithout=<field> (required unless <field> is set), enum=<a|b>, and format=<name>.
+//go:generate go run ../schemagen -types types.go -out ../../../schema
+
+// OIDCProvisionerRequest represents the input to the OIDC provisioner Lambda
+type OIDCProvisionerRequest struct {
+	Action      string `json:"action,omitempty" jsonschema:"enum=ping|bind-roles"` // "ping" for health checks, "bind-roles" to federate roles with the issuer's provider; empty provisions a provider
+	IssuerURL   string `json:"issuer_url" jsonschema:"requiredWithout=action,format=uri"` // https URL of the cluster's OIDC issuer
+	Thumbprint  string `json:"thumbprint" jsonschema:"requiredWithout=action"` // SHA-1 thumbprint of the issuer's TLS certificate
+	ClusterID   string `json:"cluster_id" jsonschema:"requiredWithout=action"` // Cluster the provider is tagged for
+	ClientIDs   []string `json:"client_ids,omitempty"` // Audiences of the provider; defaults to sts.amazonaws.com
+
+	// StrictThumbprint rejects the request, instead of warning, when the thumbprint is
+	// not that of the certificate the issuer serves
+	StrictThumbprint bool `json:"strict_thumbprint,omitempty"`
+
+	// Roles are federated with the OIDC provider of issuer_url by a bind-roles request
+	Roles []RoleBinding `json:"roles,omitempty"`
+
+	// CorrelationID ties the request to the caller's logs; one is generated when empty
+	CorrelationID string `json:"correlation_id,omitempty"`
+}
+
+// RoleBinding names a role whose trust policy a bind-roles request updates, and the
+// service accounts whose tokens may assume it
+type RoleBinding struct {
+	RoleARN         string   `json:"role_arn" jsonschema:"required"`
+	ServiceAccounts []string `json:"service_accounts" jsonschema:"required"` // Token subjects, as system:serviceaccount:<namespace>:<name>
+	Audience        string   `json:"audience,omitempty"`                     // Token audience; defaults to sts.amazonaws.com
+}
+
+// OIDCProvisionerResponse represents the output from the OIDC provisioner Lambda
+type OIDCProvisionerResponse struct {
+	OIDCProviderARN string `json:"oidc_provider_arn" jsonschema:"required"` // Empty in response to a ping
+	Status          string `json:"status" jsonschema:"required,enum=created|already_exists|healthy|bound|already_bound"`
+	Message         string `json:"message,omitempty"`
+	CorrelationID   string `json:"correlation_id,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"` // Problems that did not fail the request, such as a thumbprint mismatch
+	BoundRoles      []string `json:"bound_roles,omitempty"` // Roles whose trust policies a bind-roles request updated
+	Runtime         *RuntimeStats `json:"runtime,omitempty"` // Health of the execution environment, in response to a ping
+}
+
+// RuntimeStats describes the function's execution environment: its memory use against
+// the function's memory size, and how long it has been serving invocations
+type RuntimeStats struct {
+	HeapMB        uint64 `json:"heap_mb"`                   // Memory held by live and unswept heap objects
+	SysMB         uint64 `json:"sys_mb"`                    // Memory obtained from the OS, which Lambda compares to the memory size
+	MemoryLimitMB uint64 `json:"memory_limit_mb,omitempty"` // The function's memory size
+	Goroutines    int    `json:"goroutines"`
+	GCCycles      uint32 `json:"gc_cycles"`
+	UptimeSeconds int64  `json:"uptime_seconds"` // Since the execution environment started
+	Invocations   int64  `json:"invocations"`    // Served by the execution environment, including the current one
+}
+
+// OIDCProvisionerError represents an error response
+type OIDCProvisionerError struct {
+	ErrorType    string `json:"error_type"`
+	ErrorMessage string `json:"error_message"`
+}
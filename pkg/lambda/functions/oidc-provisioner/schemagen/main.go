@@ -0,0 +1,49 @@
+// Command schemagen writes the JSON Schemas of the OIDC provisioner's request and
+// response types. It is run by go generate in the provisioner package.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/openshift-online/regional-cli/pkg/lambda/schema"
+)
+
+func main() {
+	typesFile := flag.String("types", "types.go", "Go file declaring the request and response types")
+	outDir := flag.String("out", ".", "Directory to write the schema files to")
+	flag.Parse()
+
+	if err := run(*typesFile, *outDir); err != nil {
+		fmt.Fprintf(os.Stderr, "schemagen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(typesFile, outDir string) error {
+	src, err := os.ReadFile(typesFile)
+	if err != nil {
+		return err
+	}
+
+	schemas, err := schema.Generate(src)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		path := filepath.Join(outDir, schema.FileName(name))
+		if err := os.WriteFile(path, schemas[name], 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+)
+
+// prewarmTimeout bounds pre-warming, which must never delay initialization for long
+const prewarmTimeout = 2 * time.Second
+
+// prewarm resolves the IAM endpoint and opens a connection to it during Lambda's init
+// phase, which runs with a full CPU allocation, so the first request does not pay for
+// endpoint resolution and the TLS handshake. It is best effort: on failure the first
+// request makes the connection itself.
+func prewarm(ctx context.Context, cfg aws.Config) error {
+	ctx, cancel := context.WithTimeout(ctx, prewarmTimeout)
+	defer cancel()
+
+	endpoint, err := iam.NewDefaultEndpointResolverV2().ResolveEndpoint(ctx, iam.EndpointParameters{
+		Region: aws.String(cfg.Region),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to resolve IAM endpoint: %w", err)
+	}
+	return prewarmConnection(ctx, cfg.HTTPClient, endpoint.URI.String())
+}
+
+// prewarmConnection makes an unsigned request to url with client, leaving the
+// connection in the client's pool for the IAM client built from the same config
+func prewarmConnection(ctx context.Context, client aws.HTTPClient, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create pre-warm request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", url, err)
+	}
+	// Any response will do; draining the body returns the connection to the pool
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
@@ -0,0 +1,211 @@
+package insights
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+const (
+	defaultPollInterval = time.Second
+
+	// ptrField is an internal Logs Insights field identifying each result record
+	ptrField = "@ptr"
+)
+
+// LogsInsightsAPI defines the CloudWatch Logs Insights operations needed to run queries
+type LogsInsightsAPI interface {
+	StartQuery(ctx context.Context, params *cloudwatchlogs.StartQueryInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error)
+	GetQueryResults(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error)
+	StopQuery(ctx context.Context, params *cloudwatchlogs.StopQueryInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error)
+}
+
+// SavedQuery is a canned Logs Insights query against the OIDC provisioner log group
+type SavedQuery struct {
+	Name        string
+	Description string
+	Query       string
+}
+
+// savedQueries rely on the "request completed" JSON record the provisioner writes for
+// every request, its crash, memory pressure, and timeout records, the Lambda REPORT
+// line, and the provisioner's retry warnings
+var savedQueries = []SavedQuery{
+	{
+		Name:        "error-rate",
+		Description: "Requests, failures, and failure rate per hour",
+		Query: `filter msg = "request completed"
+| fields ispresent(error) as failed
+| stats count(*) as requests, sum(failed) as errors, avg(failed) as error_rate by bin(1h)`,
+	},
+	{
+		Name:        "creations-per-cluster",
+		Description: "OIDC providers created per cluster",
+		Query: `filter msg = "request completed" and status = "created"
+| stats count(*) as creations, latest(provider_arn) as provider_arn by cluster_id
+| sort creations desc`,
+	},
+	{
+		Name:        "duration",
+		Description: "Invocation count and average, p95, and maximum duration in ms per hour",
+		Query: `filter @type = "REPORT"
+| stats count(*) as invocations, avg(@duration) as avg_ms, pct(@duration, 95) as p95_ms, max(@duration) as max_ms by bin(1h)`,
+	},
+	{
+		Name:        "throttles",
+		Description: "Throttled IAM calls retried by the provisioner, per operation",
+		Query: `filter @message like /retrying in/ and @message like /(?i)(throttl|rate exceeded)/
+| parse @message "Warning: * attempt" as operation
+| stats count(*) as throttles by operation
+| sort throttles desc`,
+	},
+	{
+		Name:        "crashes",
+		Description: "Panics, memory pressure, and timeouts, with memory use, newest first",
+		Query: `filter msg in ["function crashed", "memory pressure", "invocation timing out"] or @message like /Task timed out|Runtime exited/
+| fields @timestamp, coalesce(msg, @message) as event, correlation_id, cluster_id, runtime.sys_mb as sys_mb, runtime.memory_limit_mb as memory_limit_mb, panic
+| sort @timestamp desc
+| limit 100`,
+	},
+}
+
+// SavedQueries returns the canned provisioner queries in display order
+func SavedQueries() []SavedQuery {
+	queries := make([]SavedQuery, len(savedQueries))
+	copy(queries, savedQueries)
+	return queries
+}
+
+// LookupSavedQuery returns the saved query with the given name
+func LookupSavedQuery(name string) (SavedQuery, bool) {
+	for _, q := range savedQueries {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return SavedQuery{}, false
+}
+
+// Result holds the rows returned by a completed query
+type Result struct {
+	Fields         []string   // Column names in the order the query returned them
+	Rows           [][]string // One value per field; missing values are empty
+	RecordsMatched float64
+	RecordsScanned float64
+}
+
+// Runner runs Logs Insights queries and waits for their results
+type Runner struct {
+	client       LogsInsightsAPI
+	pollInterval time.Duration
+}
+
+// RunnerOption configures optional Runner behavior
+type RunnerOption func(*Runner)
+
+// WithPollInterval sets how often query status is polled
+func WithPollInterval(d time.Duration) RunnerOption {
+	return func(r *Runner) {
+		r.pollInterval = d
+	}
+}
+
+// NewRunner creates a new query runner
+func NewRunner(client LogsInsightsAPI, opts ...RunnerOption) *Runner {
+	r := &Runner{
+		client:       client,
+		pollInterval: defaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run starts the query against the log group for the given time range and waits for it to
+// complete. If ctx is cancelled the query is stopped.
+func (r *Runner) Run(ctx context.Context, logGroupName, query string, start, end time.Time) (*Result, error) {
+	startOutput, err := r.client.StartQuery(ctx, &cloudwatchlogs.StartQueryInput{
+		LogGroupName: aws.String(logGroupName),
+		QueryString:  aws.String(query),
+		StartTime:    aws.Int64(start.Unix()),
+		EndTime:      aws.Int64(end.Unix()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start query: %w", err)
+	}
+	queryID := startOutput.QueryId
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		output, err := r.client.GetQueryResults(ctx, &cloudwatchlogs.GetQueryResultsInput{
+			QueryId: queryID,
+		})
+		if err != nil {
+			r.stop(ctx, queryID)
+			return nil, fmt.Errorf("failed to get query results: %w", err)
+		}
+
+		switch output.Status {
+		case types.QueryStatusComplete:
+			return newResult(output), nil
+		case types.QueryStatusFailed, types.QueryStatusCancelled, types.QueryStatusTimeout:
+			return nil, fmt.Errorf("query %s: %s", aws.ToString(queryID), output.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			r.stop(ctx, queryID)
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// stop cancels a running query so it stops consuming the account's concurrent query quota
+func (r *Runner) stop(ctx context.Context, queryID *string) {
+	_, _ = r.client.StopQuery(context.WithoutCancel(ctx), &cloudwatchlogs.StopQueryInput{
+		QueryId: queryID,
+	})
+}
+
+// newResult converts query results to rows, keeping field order and dropping @ptr
+func newResult(output *cloudwatchlogs.GetQueryResultsOutput) *Result {
+	result := &Result{}
+	if output.Statistics != nil {
+		result.RecordsMatched = output.Statistics.RecordsMatched
+		result.RecordsScanned = output.Statistics.RecordsScanned
+	}
+
+	index := make(map[string]int)
+	for _, record := range output.Results {
+		for _, field := range record {
+			name := aws.ToString(field.Field)
+			if _, ok := index[name]; !ok && name != ptrField {
+				index[name] = len(result.Fields)
+				result.Fields = append(result.Fields, name)
+			}
+		}
+	}
+
+	for _, record := range output.Results {
+		row := make([]string, len(result.Fields))
+		for _, field := range record {
+			if i, ok := index[aws.ToString(field.Field)]; ok {
+				row[i] = aws.ToString(field.Value)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result
+}
@@ -0,0 +1,148 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockInsightsClient struct {
+	startQueryFunc      func(ctx context.Context, params *cloudwatchlogs.StartQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error)
+	getQueryResultsFunc func(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error)
+	stopQueryFunc       func(ctx context.Context, params *cloudwatchlogs.StopQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error)
+}
+
+func (m *mockInsightsClient) StartQuery(ctx context.Context, params *cloudwatchlogs.StartQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error) {
+	if m.startQueryFunc != nil {
+		return m.startQueryFunc(ctx, params, optFns...)
+	}
+	return &cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-1")}, nil
+}
+
+func (m *mockInsightsClient) GetQueryResults(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+	if m.getQueryResultsFunc != nil {
+		return m.getQueryResultsFunc(ctx, params, optFns...)
+	}
+	return &cloudwatchlogs.GetQueryResultsOutput{Status: types.QueryStatusComplete}, nil
+}
+
+func (m *mockInsightsClient) StopQuery(ctx context.Context, params *cloudwatchlogs.StopQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error) {
+	if m.stopQueryFunc != nil {
+		return m.stopQueryFunc(ctx, params, optFns...)
+	}
+	return &cloudwatchlogs.StopQueryOutput{}, nil
+}
+
+func field(name, value string) types.ResultField {
+	return types.ResultField{Field: aws.String(name), Value: aws.String(value)}
+}
+
+func TestSavedQueries(t *testing.T) {
+	names := make(map[string]bool)
+	for _, q := range SavedQueries() {
+		assert.NotEmpty(t, q.Description, q.Name)
+		assert.NotEmpty(t, q.Query, q.Name)
+		assert.False(t, names[q.Name], "duplicate query %s", q.Name)
+		names[q.Name] = true
+	}
+
+	for _, name := range []string{"error-rate", "creations-per-cluster", "duration", "throttles", "crashes"} {
+		q, ok := LookupSavedQuery(name)
+		assert.True(t, ok, name)
+		assert.Equal(t, name, q.Name)
+	}
+
+	_, ok := LookupSavedQuery("unknown")
+	assert.False(t, ok)
+}
+
+func TestRun_PollsUntilComplete(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+
+	polls := 0
+	client := &mockInsightsClient{
+		startQueryFunc: func(ctx context.Context, params *cloudwatchlogs.StartQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error) {
+			assert.Equal(t, "/aws/lambda/test-function", *params.LogGroupName)
+			assert.Equal(t, "stats count(*)", *params.QueryString)
+			assert.Equal(t, start.Unix(), *params.StartTime)
+			assert.Equal(t, end.Unix(), *params.EndTime)
+			return &cloudwatchlogs.StartQueryOutput{QueryId: aws.String("query-1")}, nil
+		},
+		getQueryResultsFunc: func(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			assert.Equal(t, "query-1", *params.QueryId)
+			polls++
+			if polls < 3 {
+				return &cloudwatchlogs.GetQueryResultsOutput{Status: types.QueryStatusRunning}, nil
+			}
+			return &cloudwatchlogs.GetQueryResultsOutput{
+				Status:     types.QueryStatusComplete,
+				Statistics: &types.QueryStatistics{RecordsMatched: 3, RecordsScanned: 10},
+				Results: [][]types.ResultField{
+					{field("cluster_id", "a"), field("creations", "2"), field("@ptr", "x")},
+					{field("cluster_id", "b"), field("creations", "1"), field("provider_arn", "arn")},
+				},
+			}, nil
+		},
+	}
+
+	runner := NewRunner(client, WithPollInterval(time.Millisecond))
+	result, err := runner.Run(context.Background(), "/aws/lambda/test-function", "stats count(*)", start, end)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, []string{"cluster_id", "creations", "provider_arn"}, result.Fields)
+	assert.Equal(t, [][]string{{"a", "2", ""}, {"b", "1", "arn"}}, result.Rows)
+	assert.Equal(t, float64(3), result.RecordsMatched)
+	assert.Equal(t, float64(10), result.RecordsScanned)
+}
+
+func TestRun_QueryFailed(t *testing.T) {
+	client := &mockInsightsClient{
+		getQueryResultsFunc: func(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			return &cloudwatchlogs.GetQueryResultsOutput{Status: types.QueryStatusFailed}, nil
+		},
+	}
+
+	_, err := NewRunner(client).Run(context.Background(), "group", "query", time.Now(), time.Now())
+	assert.ErrorContains(t, err, "query query-1: Failed")
+}
+
+func TestRun_StartError(t *testing.T) {
+	client := &mockInsightsClient{
+		startQueryFunc: func(ctx context.Context, params *cloudwatchlogs.StartQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StartQueryOutput, error) {
+			return nil, errors.New("log group does not exist")
+		},
+	}
+
+	_, err := NewRunner(client).Run(context.Background(), "group", "query", time.Now(), time.Now())
+	assert.ErrorContains(t, err, "failed to start query")
+}
+
+func TestRun_CancelStopsQuery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stopped := ""
+	client := &mockInsightsClient{
+		getQueryResultsFunc: func(ctx context.Context, params *cloudwatchlogs.GetQueryResultsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetQueryResultsOutput, error) {
+			cancel()
+			return &cloudwatchlogs.GetQueryResultsOutput{Status: types.QueryStatusRunning}, nil
+		},
+		stopQueryFunc: func(ctx context.Context, params *cloudwatchlogs.StopQueryInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.StopQueryOutput, error) {
+			require.NoError(t, ctx.Err())
+			stopped = *params.QueryId
+			return &cloudwatchlogs.StopQueryOutput{}, nil
+		},
+	}
+
+	_, err := NewRunner(client, WithPollInterval(time.Hour)).Run(ctx, "group", "query", time.Now(), time.Now())
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "query-1", stopped)
+}
@@ -0,0 +1,195 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+const (
+	// ActionPing asks the provisioner to respond without touching IAM
+	ActionPing = "ping"
+
+	// ActionBindRoles asks the provisioner to federate roles with an issuer's provider
+	ActionBindRoles = "bind-roles"
+
+	// StatusHealthy is returned by the provisioner in response to a ping
+	StatusHealthy = "healthy"
+)
+
+// LambdaInvokeAPI defines the Lambda operations needed to invoke the provisioner
+type LambdaInvokeAPI interface {
+	Invoke(ctx context.Context, params *lambda.InvokeInput,
+		optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+// Request mirrors the OIDC provisioner Lambda request contract
+type Request struct {
+	Action           string        `json:"action,omitempty"`
+	IssuerURL        string        `json:"issuer_url,omitempty"`
+	Thumbprint       string        `json:"thumbprint,omitempty"`
+	ClusterID        string        `json:"cluster_id,omitempty"`
+	ClientIDs        []string      `json:"client_ids,omitempty"`
+	StrictThumbprint bool          `json:"strict_thumbprint,omitempty"`
+	Roles            []RoleBinding `json:"roles,omitempty"`
+}
+
+// RoleBinding names a role a bind-roles request federates and the service accounts,
+// as system:serviceaccount:<namespace>:<name>, that may assume it
+type RoleBinding struct {
+	RoleARN         string   `json:"role_arn"`
+	ServiceAccounts []string `json:"service_accounts"`
+	Audience        string   `json:"audience,omitempty"`
+}
+
+// Response mirrors the OIDC provisioner Lambda response contract
+type Response struct {
+	OIDCProviderARN string   `json:"oidc_provider_arn"`
+	Status          string   `json:"status"`
+	Message         string   `json:"message,omitempty"`
+	Warnings        []string `json:"warnings,omitempty"`
+	BoundRoles      []string `json:"bound_roles,omitempty"`
+
+	// Runtime describes the function's execution environment, in response to a ping
+	Runtime *RuntimeStats `json:"runtime,omitempty"`
+}
+
+// RuntimeStats mirrors the execution environment health a ping returns
+type RuntimeStats struct {
+	HeapMB        uint64 `json:"heap_mb"`
+	SysMB         uint64 `json:"sys_mb"`
+	MemoryLimitMB uint64 `json:"memory_limit_mb,omitempty"`
+	Goroutines    int    `json:"goroutines"`
+	GCCycles      uint32 `json:"gc_cycles"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+	Invocations   int64  `json:"invocations"`
+}
+
+// FunctionError is returned when the Lambda function itself reports an error
+type FunctionError struct {
+	Type    string `json:"errorType"`
+	Message string `json:"errorMessage"`
+}
+
+func (e *FunctionError) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("function error: %s", e.Message)
+	}
+	return fmt.Sprintf("function error (%s): %s", e.Type, e.Message)
+}
+
+// Logs identifies an invocation and holds the end of its log, as returned by Lambda
+type Logs struct {
+	RequestID string
+	Tail      []string // The last 4 KB of the invocation's log, one line per entry
+}
+
+// Invoker calls the deployed OIDC provisioner Lambda
+type Invoker struct {
+	client       LambdaInvokeAPI
+	functionName string
+	logs         func(Logs)
+}
+
+// Option configures optional Invoker behavior
+type Option func(*Invoker)
+
+// WithLogs requests the tail of each invocation's log and passes it to fn, also for
+// invocations the function fails
+func WithLogs(fn func(Logs)) Option {
+	return func(i *Invoker) {
+		i.logs = fn
+	}
+}
+
+// NewInvoker creates a new invoker for the given function name or ARN
+func NewInvoker(client LambdaInvokeAPI, functionName string, opts ...Option) *Invoker {
+	i := &Invoker{
+		client:       client,
+		functionName: functionName,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+	return i
+}
+
+// Invoke synchronously invokes the provisioner with the request
+func (i *Invoker) Invoke(ctx context.Context, req Request) (*Response, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	input := &lambda.InvokeInput{
+		FunctionName: aws.String(i.functionName),
+		Payload:      payload,
+	}
+	if i.logs != nil {
+		input.LogType = types.LogTypeTail
+	}
+	output, err := i.client.Invoke(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invoke %s: %w", i.functionName, err)
+	}
+	if i.logs != nil {
+		i.logs(newLogs(output))
+	}
+
+	if output.FunctionError != nil {
+		funcErr := &FunctionError{}
+		if err := json.Unmarshal(output.Payload, funcErr); err != nil || funcErr.Message == "" {
+			funcErr.Type = aws.ToString(output.FunctionError)
+			funcErr.Message = string(output.Payload)
+		}
+		return nil, funcErr
+	}
+
+	var resp Response
+	if err := json.Unmarshal(output.Payload, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// Ping performs a health-check invocation and returns the round-trip latency
+func (i *Invoker) Ping(ctx context.Context) (*Response, time.Duration, error) {
+	start := time.Now()
+	resp, err := i.Invoke(ctx, Request{Action: ActionPing})
+	latency := time.Since(start)
+	if err != nil {
+		return nil, latency, err
+	}
+
+	if resp.Status != StatusHealthy {
+		return resp, latency, fmt.Errorf("unexpected health status %q", resp.Status)
+	}
+
+	return resp, latency, nil
+}
+
+// newLogs decodes the log tail of an invocation made with LogType Tail. The request ID
+// of the Invoke call is the invocation's request ID.
+func newLogs(output *lambda.InvokeOutput) Logs {
+	logs := Logs{}
+	if requestID, ok := awsmiddleware.GetRequestIDMetadata(output.ResultMetadata); ok {
+		logs.RequestID = requestID
+	}
+	tail, err := base64.StdEncoding.DecodeString(aws.ToString(output.LogResult))
+	if err != nil {
+		return logs
+	}
+	if text := strings.TrimRight(string(tail), "\n"); text != "" {
+		logs.Tail = strings.Split(text, "\n")
+	}
+	return logs
+}
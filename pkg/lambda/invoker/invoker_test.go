@@ -0,0 +1,153 @@
+package invoker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLambdaClient struct {
+	invokeFunc func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error)
+}
+
+func (m *mockLambdaClient) Invoke(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+	if m.invokeFunc != nil {
+		return m.invokeFunc(ctx, params, optFns...)
+	}
+	return &lambda.InvokeOutput{}, nil
+}
+
+func TestInvoke_Success(t *testing.T) {
+	mock := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			assert.Equal(t, "rosa-oidc-provisioner", *params.FunctionName)
+
+			var req Request
+			require.NoError(t, json.Unmarshal(params.Payload, &req))
+			assert.Equal(t, "https://example.com", req.IssuerURL)
+
+			return &lambda.InvokeOutput{
+				StatusCode: 200,
+				Payload:    []byte(`{"oidc_provider_arn":"arn:aws:iam::123456789012:oidc-provider/example.com","status":"created"}`),
+			}, nil
+		},
+	}
+
+	resp, err := NewInvoker(mock, "rosa-oidc-provisioner").Invoke(context.Background(), Request{
+		IssuerURL:  "https://example.com",
+		Thumbprint: "abc123",
+		ClusterID:  "test-cluster",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "created", resp.Status)
+	assert.Equal(t, "arn:aws:iam::123456789012:oidc-provider/example.com", resp.OIDCProviderARN)
+}
+
+func TestInvoke_FunctionError(t *testing.T) {
+	mock := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			return &lambda.InvokeOutput{
+				StatusCode:    200,
+				FunctionError: aws.String("Unhandled"),
+				Payload:       []byte(`{"errorMessage":"invalid request: issuer_url is required","errorType":"wrapError"}`),
+			}, nil
+		},
+	}
+
+	_, err := NewInvoker(mock, "fn").Invoke(context.Background(), Request{})
+
+	var funcErr *FunctionError
+	require.True(t, errors.As(err, &funcErr))
+	assert.Equal(t, "wrapError", funcErr.Type)
+	assert.Contains(t, err.Error(), "issuer_url is required")
+}
+
+func TestInvoke_APIError(t *testing.T) {
+	mock := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			return nil, errors.New("AccessDeniedException")
+		},
+	}
+
+	_, err := NewInvoker(mock, "fn").Invoke(context.Background(), Request{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to invoke fn")
+}
+
+func TestPing(t *testing.T) {
+	t.Run("healthy", func(t *testing.T) {
+		mock := &mockLambdaClient{
+			invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+				assert.JSONEq(t, `{"action":"ping"}`, string(params.Payload))
+				return &lambda.InvokeOutput{Payload: []byte(`{"status":"healthy","message":"pong"}`)}, nil
+			},
+		}
+
+		resp, latency, err := NewInvoker(mock, "fn").Ping(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "pong", resp.Message)
+		assert.GreaterOrEqual(t, int64(latency), int64(0))
+	})
+
+	t.Run("outdated function without ping support", func(t *testing.T) {
+		mock := &mockLambdaClient{
+			invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+				return &lambda.InvokeOutput{Payload: []byte(`{"status":"created"}`)}, nil
+			},
+		}
+
+		_, _, err := NewInvoker(mock, "fn").Ping(context.Background())
+		assert.Error(t, err)
+	})
+}
+
+func TestInvoke_WithLogs(t *testing.T) {
+	var metadata middleware.Metadata
+	awsmiddleware.SetRequestIDMetadata(&metadata, "req-1")
+	tail := "START RequestId: req-1\nfunction output\nEND RequestId: req-1\n"
+
+	mock := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			assert.Equal(t, types.LogTypeTail, params.LogType)
+			return &lambda.InvokeOutput{
+				FunctionError:  aws.String("Unhandled"),
+				Payload:        []byte(`{"errorMessage":"boom"}`),
+				LogResult:      aws.String(base64.StdEncoding.EncodeToString([]byte(tail))),
+				ResultMetadata: metadata,
+			}, nil
+		},
+	}
+
+	var logs Logs
+	_, err := NewInvoker(mock, "rosa-oidc-provisioner", WithLogs(func(l Logs) {
+		logs = l
+	})).Invoke(context.Background(), Request{Action: ActionPing})
+
+	require.Error(t, err, "logs are returned for failed invocations too")
+	assert.Equal(t, "req-1", logs.RequestID)
+	assert.Equal(t, []string{"START RequestId: req-1", "function output", "END RequestId: req-1"}, logs.Tail)
+}
+
+func TestInvoke_WithoutLogs(t *testing.T) {
+	mock := &mockLambdaClient{
+		invokeFunc: func(ctx context.Context, params *lambda.InvokeInput, optFns ...func(*lambda.Options)) (*lambda.InvokeOutput, error) {
+			assert.Empty(t, params.LogType)
+			return &lambda.InvokeOutput{Payload: []byte(`{"status":"healthy"}`)}, nil
+		},
+	}
+
+	_, _, err := NewInvoker(mock, "rosa-oidc-provisioner").Ping(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,168 @@
+// Package logtail follows the log of a single Lambda invocation in CloudWatch Logs,
+// from its START line to its REPORT line, as the events are ingested
+package logtail
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+const defaultPollInterval = time.Second
+
+// LogEventsAPI defines the CloudWatch Logs operations needed to read log events
+type LogEventsAPI interface {
+	FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput,
+		optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error)
+}
+
+// Follower polls a log group for the events of one invocation
+type Follower struct {
+	client       LogEventsAPI
+	pollInterval time.Duration
+}
+
+// FollowerOption configures optional Follower behavior
+type FollowerOption func(*Follower)
+
+// WithPollInterval sets how often the log group is polled for new events
+func WithPollInterval(d time.Duration) FollowerOption {
+	return func(f *Follower) {
+		f.pollInterval = d
+	}
+}
+
+// NewFollower creates a new log follower
+func NewFollower(client LogEventsAPI, opts ...FollowerOption) *Follower {
+	f := &Follower{
+		client:       client,
+		pollInterval: defaultPollInterval,
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// LogGroupName returns the log group Lambda writes a function's logs to
+func LogGroupName(functionName string) string {
+	return "/aws/lambda/" + functionName
+}
+
+// Follow passes each line the invocation with requestID logged to emit, in order, until
+// its REPORT line. Only events at or after since are considered. CloudWatch Logs ingests
+// events a few seconds after they are written, so bound ctx to stop waiting for an
+// invocation whose log never arrives.
+func (f *Follower) Follow(ctx context.Context, logGroupName, requestID string, since time.Time, emit func(line string)) error {
+	ticker := time.NewTicker(f.pollInterval)
+	defer ticker.Stop()
+
+	t := &tail{
+		logGroupName: logGroupName,
+		start:        "START RequestId: " + requestID,
+		report:       "REPORT RequestId: " + requestID,
+		from:         since.UnixMilli(),
+		seen:         make(map[string]bool),
+		emit:         emit,
+	}
+	for {
+		done, err := f.poll(ctx, t)
+		if err != nil || done {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// tail tracks the progress of one Follow call across polls
+type tail struct {
+	logGroupName  string
+	start, report string // Prefixes of the invocation's first and last lines
+	stream        string // Log stream holding the invocation, once found
+	from          int64  // Earliest event timestamp to read, in milliseconds
+	started       bool   // Whether the START line has been emitted
+	seen          map[string]bool
+	emit          func(string)
+}
+
+// poll reads the events ingested since the last poll and reports whether the REPORT
+// line was among them
+func (f *Follower) poll(ctx context.Context, t *tail) (bool, error) {
+	if t.stream == "" {
+		// Lambda writes each invocation to a single stream; find it by the START line
+		events, err := f.events(ctx, &cloudwatchlogs.FilterLogEventsInput{
+			LogGroupName:  aws.String(t.logGroupName),
+			StartTime:     aws.Int64(t.from),
+			FilterPattern: aws.String(`"` + t.start + `"`),
+		})
+		if err != nil || len(events) == 0 {
+			return false, err
+		}
+		t.stream = events[0].stream
+		t.from = events[0].timestamp
+	}
+
+	events, err := f.events(ctx, &cloudwatchlogs.FilterLogEventsInput{
+		LogGroupName:   aws.String(t.logGroupName),
+		LogStreamNames: []string{t.stream},
+		StartTime:      aws.Int64(t.from),
+	})
+	if err != nil {
+		return false, err
+	}
+	for _, e := range events {
+		if t.seen[e.id] {
+			continue
+		}
+		t.seen[e.id] = true
+
+		// The stream also holds the invocations before this one
+		if !t.started {
+			if !strings.HasPrefix(e.message, t.start) {
+				continue
+			}
+			t.started = true
+		}
+		t.emit(e.message)
+		if strings.HasPrefix(e.message, t.report) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// event is a log event with its trailing newline removed
+type event struct {
+	id, stream, message string
+	timestamp           int64
+}
+
+// events returns every page of events matching input
+func (f *Follower) events(ctx context.Context, input *cloudwatchlogs.FilterLogEventsInput) ([]event, error) {
+	var events []event
+	paginator := cloudwatchlogs.NewFilterLogEventsPaginator(f.client, input)
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read log events: %w", err)
+		}
+		for _, e := range page.Events {
+			events = append(events, event{
+				id:        aws.ToString(e.EventId),
+				stream:    aws.ToString(e.LogStreamName),
+				message:   strings.TrimRight(aws.ToString(e.Message), "\r\n"),
+				timestamp: aws.ToInt64(e.Timestamp),
+			})
+		}
+	}
+	return events, nil
+}
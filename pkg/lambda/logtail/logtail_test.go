@@ -0,0 +1,121 @@
+package logtail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLogsClient serves the events ingested so far from an in-memory log group
+type mockLogsClient struct {
+	events []types.FilteredLogEvent
+	calls  int
+
+	// ingest is called before every call, to add events as they arrive
+	ingest func(m *mockLogsClient)
+	err    error
+}
+
+func (m *mockLogsClient) FilterLogEvents(ctx context.Context, params *cloudwatchlogs.FilterLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.FilterLogEventsOutput, error) {
+	m.calls++
+	if m.ingest != nil {
+		m.ingest(m)
+	}
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	output := &cloudwatchlogs.FilterLogEventsOutput{}
+	for _, e := range m.events {
+		if aws.ToInt64(e.Timestamp) < aws.ToInt64(params.StartTime) {
+			continue
+		}
+		if len(params.LogStreamNames) > 0 && aws.ToString(e.LogStreamName) != params.LogStreamNames[0] {
+			continue
+		}
+		// Only quoted-phrase filter patterns are supported
+		if phrase := strings.Trim(aws.ToString(params.FilterPattern), `"`); !strings.Contains(aws.ToString(e.Message), phrase) {
+			continue
+		}
+		output.Events = append(output.Events, e)
+	}
+	return output, nil
+}
+
+func (m *mockLogsClient) add(stream string, timestamp int64, message string) {
+	m.events = append(m.events, types.FilteredLogEvent{
+		EventId:       aws.String(fmt.Sprintf("event-%d", len(m.events))),
+		LogStreamName: aws.String(stream),
+		Timestamp:     aws.Int64(timestamp),
+		Message:       aws.String(message + "\n"),
+	})
+}
+
+func TestFollow(t *testing.T) {
+	client := &mockLogsClient{}
+	// An earlier invocation in the same stream, and a concurrent one in another
+	client.add("stream-a", 1000, "START RequestId: earlier Version: $LATEST")
+	client.add("stream-a", 1000, "REPORT RequestId: earlier Duration: 2.00 ms")
+	client.add("stream-b", 1000, "START RequestId: other Version: $LATEST")
+	client.ingest = func(m *mockLogsClient) {
+		// The invocation's events arrive over several polls
+		switch m.calls {
+		case 2:
+			m.add("stream-a", 1000, "START RequestId: req-1 Version: $LATEST")
+			m.add("stream-a", 1001, `{"msg":"request completed"}`)
+		case 4:
+			m.add("stream-b", 1002, "log line from another invocation")
+			m.add("stream-a", 1002, "END RequestId: req-1")
+			m.add("stream-a", 1002, "REPORT RequestId: req-1 Duration: 12.00 ms")
+		}
+	}
+
+	var lines []string
+	err := NewFollower(client, WithPollInterval(time.Millisecond)).Follow(context.Background(),
+		LogGroupName("rosa-oidc-provisioner"), "req-1", time.UnixMilli(1000), func(line string) {
+			lines = append(lines, line)
+		})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"START RequestId: req-1 Version: $LATEST",
+		`{"msg":"request completed"}`,
+		"END RequestId: req-1",
+		"REPORT RequestId: req-1 Duration: 12.00 ms",
+	}, lines)
+}
+
+func TestFollow_Timeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	client := &mockLogsClient{}
+	client.add("stream-a", 1000, "START RequestId: req-1 Version: $LATEST")
+
+	var lines []string
+	err := NewFollower(client, WithPollInterval(time.Millisecond)).Follow(ctx,
+		"/aws/lambda/fn", "req-1", time.UnixMilli(0), func(line string) {
+			lines = append(lines, line)
+		})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, []string{"START RequestId: req-1 Version: $LATEST"}, lines, "lines are emitted only once")
+}
+
+func TestFollow_Error(t *testing.T) {
+	client := &mockLogsClient{err: errors.New("AccessDeniedException")}
+	err := NewFollower(client).Follow(context.Background(), "/aws/lambda/fn", "req-1", time.Now(), func(string) {})
+	assert.ErrorContains(t, err, "failed to read log events")
+}
+
+func TestLogGroupName(t *testing.T) {
+	assert.Equal(t, "/aws/lambda/rosa-oidc-provisioner", LogGroupName("rosa-oidc-provisioner"))
+}
@@ -0,0 +1,238 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+const (
+	lambdaNamespace          = "AWS/Lambda"
+	functionNameDimension    = "FunctionName"
+	resourceDimension        = "Resource"
+	executedVersionDimension = "ExecutedVersion"
+
+	// DefaultErrorRateThreshold flags error rates above 1%
+	DefaultErrorRateThreshold = 0.01
+
+	// DefaultDurationThreshold flags p95 durations above 80% of the function timeout
+	DefaultDurationThreshold = 0.8
+)
+
+// CloudWatchAPI defines the CloudWatch operations needed to read Lambda metrics
+type CloudWatchAPI interface {
+	GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput,
+		optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+// Summary aggregates the provisioner's Lambda metrics over a time window
+type Summary struct {
+	Invocations float64
+	Errors      float64
+	Throttles   float64
+	DurationAvg time.Duration
+	DurationP95 time.Duration // Highest p95 across the window's periods
+	DurationMax time.Duration
+}
+
+// ErrorRate returns errors as a fraction of invocations
+func (s *Summary) ErrorRate() float64 {
+	if s.Invocations == 0 {
+		return 0
+	}
+	return s.Errors / s.Invocations
+}
+
+// Thresholds controls which values are highlighted
+type Thresholds struct {
+	ErrorRate   float64       // Maximum healthy error rate (fraction of invocations)
+	Throttles   float64       // Maximum healthy number of throttled invocations
+	DurationP95 time.Duration // Maximum healthy p95 duration; zero disables the check
+}
+
+// Check is a single evaluated metric
+type Check struct {
+	Name    string
+	Value   string
+	Healthy bool
+	Detail  string // Threshold explanation, set when the check is unhealthy
+}
+
+// Evaluate compares the summary with the thresholds
+func (s *Summary) Evaluate(t Thresholds) []Check {
+	checks := []Check{
+		{
+			Name:    "Invocations",
+			Value:   fmt.Sprintf("%.0f", s.Invocations),
+			Healthy: true,
+		},
+		{
+			Name:    "Errors",
+			Value:   fmt.Sprintf("%.0f (%.1f%% error rate)", s.Errors, s.ErrorRate()*100),
+			Healthy: s.ErrorRate() <= t.ErrorRate,
+			Detail:  fmt.Sprintf("threshold %.1f%%", t.ErrorRate*100),
+		},
+		{
+			Name:    "Throttles",
+			Value:   fmt.Sprintf("%.0f", s.Throttles),
+			Healthy: s.Throttles <= t.Throttles,
+			Detail:  fmt.Sprintf("threshold %.0f", t.Throttles),
+		},
+		{
+			Name: "Duration",
+			Value: fmt.Sprintf("avg %s, p95 %s, max %s",
+				s.DurationAvg.Round(time.Millisecond), s.DurationP95.Round(time.Millisecond), s.DurationMax.Round(time.Millisecond)),
+			Healthy: t.DurationP95 == 0 || s.DurationP95 <= t.DurationP95,
+			Detail:  fmt.Sprintf("p95 threshold %s", t.DurationP95.Round(time.Millisecond)),
+		},
+	}
+
+	for i := range checks {
+		if checks[i].Healthy {
+			checks[i].Detail = ""
+		}
+	}
+
+	return checks
+}
+
+// Collector reads Lambda metrics for a single function
+type Collector struct {
+	client     CloudWatchAPI
+	dimensions []types.Dimension
+}
+
+// NewCollector creates a collector for the named function
+func NewCollector(client CloudWatchAPI, functionName string) *Collector {
+	return &Collector{
+		client: client,
+		dimensions: []types.Dimension{
+			{Name: aws.String(functionNameDimension), Value: aws.String(functionName)},
+		},
+	}
+}
+
+// NewAliasVersionCollector creates a collector for invocations of an alias that ran
+// version, such as the canary share of an alias with weighted routing
+func NewAliasVersionCollector(client CloudWatchAPI, functionName, alias, version string) *Collector {
+	return &Collector{
+		client: client,
+		dimensions: []types.Dimension{
+			{Name: aws.String(functionNameDimension), Value: aws.String(functionName)},
+			{Name: aws.String(resourceDimension), Value: aws.String(functionName + ":" + alias)},
+			{Name: aws.String(executedVersionDimension), Value: aws.String(version)},
+		},
+	}
+}
+
+// metricQuery is a Lambda metric statistic requested from CloudWatch
+type metricQuery struct {
+	id     string
+	metric string
+	stat   string
+}
+
+var summaryQueries = []metricQuery{
+	{id: "invocations", metric: "Invocations", stat: "Sum"},
+	{id: "errors", metric: "Errors", stat: "Sum"},
+	{id: "throttles", metric: "Throttles", stat: "Sum"},
+	{id: "duration_sum", metric: "Duration", stat: "Sum"},
+	{id: "duration_count", metric: "Duration", stat: "SampleCount"},
+	{id: "duration_p95", metric: "Duration", stat: "p95"},
+	{id: "duration_max", metric: "Duration", stat: "Maximum"},
+}
+
+// Summarize reads metrics between start and end. Sums are totalled across periods; the
+// average duration is weighted by sample count.
+func (c *Collector) Summarize(ctx context.Context, start, end time.Time) (*Summary, error) {
+	period := periodFor(end.Sub(start))
+
+	queries := make([]types.MetricDataQuery, 0, len(summaryQueries))
+	for _, q := range summaryQueries {
+		queries = append(queries, types.MetricDataQuery{
+			Id: aws.String(q.id),
+			MetricStat: &types.MetricStat{
+				Metric: &types.Metric{
+					Namespace:  aws.String(lambdaNamespace),
+					MetricName: aws.String(q.metric),
+					Dimensions: c.dimensions,
+				},
+				Period: aws.Int32(period),
+				Stat:   aws.String(q.stat),
+			},
+		})
+	}
+
+	values := make(map[string][]float64)
+	input := &cloudwatch.GetMetricDataInput{
+		StartTime:         aws.Time(start),
+		EndTime:           aws.Time(end),
+		MetricDataQueries: queries,
+	}
+	for {
+		output, err := c.client.GetMetricData(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metric data: %w", err)
+		}
+		for _, result := range output.MetricDataResults {
+			id := aws.ToString(result.Id)
+			values[id] = append(values[id], result.Values...)
+		}
+		if output.NextToken == nil {
+			break
+		}
+		input.NextToken = output.NextToken
+	}
+
+	summary := &Summary{
+		Invocations: sum(values["invocations"]),
+		Errors:      sum(values["errors"]),
+		Throttles:   sum(values["throttles"]),
+		DurationP95: milliseconds(maximum(values["duration_p95"])),
+		DurationMax: milliseconds(maximum(values["duration_max"])),
+	}
+	if count := sum(values["duration_count"]); count > 0 {
+		summary.DurationAvg = milliseconds(sum(values["duration_sum"]) / count)
+	}
+
+	return summary, nil
+}
+
+// periodFor returns a period covering the window in as few datapoints as CloudWatch allows
+func periodFor(window time.Duration) int32 {
+	seconds := int64(math.Ceil(window.Seconds()/60)) * 60
+	if seconds < 60 {
+		seconds = 60
+	}
+	if seconds > math.MaxInt32 {
+		seconds = math.MaxInt32 - math.MaxInt32%60
+	}
+	return int32(seconds)
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
+
+func maximum(values []float64) float64 {
+	var highest float64
+	for _, v := range values {
+		if v > highest {
+			highest = v
+		}
+	}
+	return highest
+}
+
+func milliseconds(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCloudWatchClient struct {
+	getMetricDataFunc func(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error)
+}
+
+func (m *mockCloudWatchClient) GetMetricData(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+	if m.getMetricDataFunc != nil {
+		return m.getMetricDataFunc(ctx, params, optFns...)
+	}
+	return &cloudwatch.GetMetricDataOutput{}, nil
+}
+
+func result(id string, values ...float64) types.MetricDataResult {
+	return types.MetricDataResult{Id: aws.String(id), Values: values}
+}
+
+func TestSummarize(t *testing.T) {
+	end := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	start := end.Add(-24 * time.Hour)
+
+	calls := 0
+	client := &mockCloudWatchClient{
+		getMetricDataFunc: func(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+			calls++
+			assert.Equal(t, start, *params.StartTime)
+			assert.Equal(t, end, *params.EndTime)
+			require.Len(t, params.MetricDataQueries, len(summaryQueries))
+			for _, q := range params.MetricDataQueries {
+				assert.Equal(t, "AWS/Lambda", *q.MetricStat.Metric.Namespace)
+				assert.Equal(t, "test-function", *q.MetricStat.Metric.Dimensions[0].Value)
+				assert.Equal(t, int32(86400), *q.MetricStat.Period)
+			}
+
+			if params.NextToken == nil {
+				return &cloudwatch.GetMetricDataOutput{
+					NextToken: aws.String("page-2"),
+					MetricDataResults: []types.MetricDataResult{
+						result("invocations", 100),
+						result("errors", 2),
+						result("throttles"),
+						result("duration_sum", 50000),
+						result("duration_count", 100),
+						result("duration_p95", 900),
+						result("duration_max", 1200.5),
+					},
+				}, nil
+			}
+			return &cloudwatch.GetMetricDataOutput{
+				MetricDataResults: []types.MetricDataResult{
+					result("invocations", 100),
+					result("errors", 0),
+					result("duration_sum", 150000),
+					result("duration_count", 100),
+					result("duration_p95", 1500),
+					result("duration_max", 800),
+				},
+			}, nil
+		},
+	}
+
+	summary, err := NewCollector(client, "test-function").Summarize(context.Background(), start, end)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, float64(200), summary.Invocations)
+	assert.Equal(t, float64(2), summary.Errors)
+	assert.Equal(t, float64(0), summary.Throttles)
+	assert.Equal(t, time.Second, summary.DurationAvg)
+	assert.Equal(t, 1500*time.Millisecond, summary.DurationP95)
+	assert.Equal(t, 1200500*time.Microsecond, summary.DurationMax)
+	assert.InDelta(t, 0.01, summary.ErrorRate(), 1e-9)
+}
+
+func TestSummarize_Error(t *testing.T) {
+	client := &mockCloudWatchClient{
+		getMetricDataFunc: func(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+			return nil, errors.New("access denied")
+		},
+	}
+
+	_, err := NewCollector(client, "test-function").Summarize(context.Background(), time.Now().Add(-time.Hour), time.Now())
+	assert.ErrorContains(t, err, "failed to get metric data")
+}
+
+func TestSummarize_NoInvocations(t *testing.T) {
+	summary, err := NewCollector(&mockCloudWatchClient{}, "test-function").Summarize(context.Background(), time.Now().Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), summary.ErrorRate())
+	assert.Equal(t, time.Duration(0), summary.DurationAvg)
+}
+
+func TestEvaluate(t *testing.T) {
+	thresholds := Thresholds{
+		ErrorRate:   DefaultErrorRateThreshold,
+		DurationP95: 48 * time.Second,
+	}
+
+	healthy := &Summary{Invocations: 200, Errors: 1, DurationP95: time.Second}
+	for _, check := range healthy.Evaluate(thresholds) {
+		assert.True(t, check.Healthy, check.Name)
+		assert.Empty(t, check.Detail, check.Name)
+	}
+
+	unhealthy := &Summary{Invocations: 100, Errors: 5, Throttles: 3, DurationP95: 50 * time.Second}
+	checks := unhealthy.Evaluate(thresholds)
+	require.Len(t, checks, 4)
+
+	assert.True(t, checks[0].Healthy)
+	assert.False(t, checks[1].Healthy)
+	assert.Equal(t, "5 (5.0% error rate)", checks[1].Value)
+	assert.Equal(t, "threshold 1.0%", checks[1].Detail)
+	assert.False(t, checks[2].Healthy)
+	assert.False(t, checks[3].Healthy)
+	assert.Equal(t, "p95 threshold 48s", checks[3].Detail)
+
+	// Without a duration threshold the duration check always passes
+	assert.True(t, unhealthy.Evaluate(Thresholds{ErrorRate: 1, Throttles: 10})[3].Healthy)
+}
+
+func TestPeriodFor(t *testing.T) {
+	assert.Equal(t, int32(60), periodFor(0))
+	assert.Equal(t, int32(120), periodFor(61*time.Second))
+	assert.Equal(t, int32(86400), periodFor(24*time.Hour))
+}
+
+func TestNewAliasVersionCollector(t *testing.T) {
+	client := &mockCloudWatchClient{
+		getMetricDataFunc: func(ctx context.Context, params *cloudwatch.GetMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricDataOutput, error) {
+			dimensions := map[string]string{}
+			for _, d := range params.MetricDataQueries[0].MetricStat.Metric.Dimensions {
+				dimensions[*d.Name] = *d.Value
+			}
+			assert.Equal(t, map[string]string{
+				"FunctionName":    "test-function",
+				"Resource":        "test-function:live",
+				"ExecutedVersion": "7",
+			}, dimensions)
+			return &cloudwatch.GetMetricDataOutput{}, nil
+		},
+	}
+
+	_, err := NewAliasVersionCollector(client, "test-function", "live", "7").Summarize(context.Background(), time.Now().Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+}
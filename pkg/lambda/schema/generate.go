@@ -0,0 +1,247 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// draft is the JSON Schema dialect of the generated schemas
+const draft = "https://json-schema.org/draft/2020-12/schema"
+
+// idBase prefixes the $id of every generated schema
+const idBase = "https://github.com/openshift-online/regional-cli/schemas/oidc-provisioner/"
+
+// sources maps each published schema to the Go type it is generated from
+var sources = map[string]string{
+	Request:  "OIDCProvisionerRequest",
+	Response: "OIDCProvisionerResponse",
+}
+
+// Schema is the subset of JSON Schema the generator emits
+type Schema struct {
+	Schema               string      `json:"$schema,omitempty"`
+	ID                   string      `json:"$id,omitempty"`
+	Title                string      `json:"title,omitempty"`
+	Description          string      `json:"description,omitempty"`
+	Type                 string      `json:"type,omitempty"`
+	Format               string      `json:"format,omitempty"`
+	Enum                 []string    `json:"enum,omitempty"`
+	Items                *Schema     `json:"items,omitempty"`
+	Properties           Properties  `json:"properties,omitempty"`
+	Required             []string    `json:"required,omitempty"`
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"` // false or a *Schema
+	If                   *Schema     `json:"if,omitempty"`
+	Then                 *Schema     `json:"then,omitempty"`
+	Not                  *Schema     `json:"not,omitempty"`
+}
+
+// Property is a named object property
+type Property struct {
+	Name   string
+	Schema *Schema
+}
+
+// Properties are an object's properties, marshaled in declaration order
+type Properties []Property
+
+// MarshalJSON writes the properties as an object, keeping their order
+func (p Properties) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, property := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(property.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(property.Schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Generate returns the published schemas, keyed by name, generated from the Go source
+// declaring the provisioner's request and response types
+func Generate(src []byte) (map[string][]byte, error) {
+	schemas := make(map[string][]byte, len(sources))
+	for name, typeName := range sources {
+		structType, doc, err := findStruct(src, typeName)
+		if err != nil {
+			return nil, err
+		}
+
+		schema, err := objectSchema(src, structType)
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %w", typeName, err)
+		}
+		schema.Schema = draft
+		schema.ID = idBase + FileName(name)
+		schema.Title = typeName
+		schema.Description = commentText(doc)
+
+		data, err := json.MarshalIndent(schema, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s schema: %w", name, err)
+		}
+		schemas[name] = append(data, '\n')
+	}
+	return schemas, nil
+}
+
+// findStruct returns the declaration of the named struct type in src and its doc comment
+func findStruct(src []byte, name string) (*ast.StructType, *ast.CommentGroup, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "types.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse types: %w", err)
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if typeSpec.Name.Name != name {
+				continue
+			}
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				return nil, nil, fmt.Errorf("type %s is not a struct", name)
+			}
+			if typeSpec.Doc != nil {
+				return structType, typeSpec.Doc, nil
+			}
+			return structType, gen.Doc, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("type %s not found", name)
+}
+
+// objectSchema builds the schema of a struct from its json and jsonschema tags. Fields
+// of struct types declared in src are described by their own object schemas.
+func objectSchema(src []byte, structType *ast.StructType) (*Schema, error) {
+	schema := &Schema{Type: "object", AdditionalProperties: false}
+	var requiredWithout []string
+	without := ""
+
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 || !field.Names[0].IsExported() {
+			continue
+		}
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			raw, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: invalid tag: %w", field.Names[0].Name, err)
+			}
+			tag = reflect.StructTag(raw)
+		}
+
+		name, _, _ := strings.Cut(tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Names[0].Name
+		}
+
+		property, err := typeSchema(src, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Names[0].Name, err)
+		}
+		property.Description = commentText(field.Doc)
+		if property.Description == "" {
+			property.Description = commentText(field.Comment)
+		}
+
+		for _, option := range strings.Split(tag.Get("jsonschema"), ",") {
+			key, value, _ := strings.Cut(option, "=")
+			switch key {
+			case "":
+			case "required":
+				schema.Required = append(schema.Required, name)
+			case "requiredWithout":
+				if without != "" && without != value {
+					return nil, fmt.Errorf("field %s: only one requiredWithout field is supported", field.Names[0].Name)
+				}
+				without = value
+				requiredWithout = append(requiredWithout, name)
+			case "enum":
+				property.Enum = strings.Split(value, "|")
+			case "format":
+				property.Format = value
+			default:
+				return nil, fmt.Errorf("field %s: unknown jsonschema option %q", field.Names[0].Name, key)
+			}
+		}
+
+		schema.Properties = append(schema.Properties, Property{Name: name, Schema: property})
+	}
+
+	if len(requiredWithout) > 0 {
+		schema.If = &Schema{Not: &Schema{Required: []string{without}}}
+		schema.Then = &Schema{Required: requiredWithout}
+	}
+	return schema, nil
+}
+
+// typeSchema maps a Go type expression to a schema
+func typeSchema(src []byte, expr ast.Expr) (*Schema, error) {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeSchema(src, t.X)
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return &Schema{Type: "string"}, nil
+		case "bool":
+			return &Schema{Type: "boolean"}, nil
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return &Schema{Type: "integer"}, nil
+		case "float32", "float64":
+			return &Schema{Type: "number"}, nil
+		}
+		if structType, _, err := findStruct(src, t.Name); err == nil {
+			return objectSchema(src, structType)
+		}
+	case *ast.ArrayType:
+		items, err := typeSchema(src, t.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		if key, ok := t.Key.(*ast.Ident); ok && key.Name == "string" {
+			values, err := typeSchema(src, t.Value)
+			if err != nil {
+				return nil, err
+			}
+			return &Schema{Type: "object", AdditionalProperties: values}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported type %T", expr)
+}
+
+// commentText returns a comment as a single line
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(group.Text()), " ")
+}
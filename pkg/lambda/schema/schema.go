@@ -0,0 +1,37 @@
+// Package schema publishes JSON Schemas for the OIDC provisioner Lambda's request
+// and response payloads, so callers can validate payloads against the contract of
+// the deployed function. The schema files are generated from the Lambda's Go types
+// by go generate in pkg/lambda/functions/oidc-provisioner/provisioner.
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"strings"
+)
+
+// Schema names
+const (
+	Request  = "request"
+	Response = "response"
+)
+
+// Names lists the published schemas
+var Names = []string{Request, Response}
+
+//go:embed *.schema.json
+var files embed.FS
+
+// FileName returns the file a schema is published as
+func FileName(name string) string {
+	return name + ".schema.json"
+}
+
+// Get returns the named schema document
+func Get(name string) ([]byte, error) {
+	data, err := files.ReadFile(FileName(name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown schema %q (valid: %s)", name, strings.Join(Names, ", "))
+	}
+	return data, nil
+}
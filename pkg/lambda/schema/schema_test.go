@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemasUpToDate(t *testing.T) {
+	src, err := os.ReadFile(filepath.Join("..", "functions", "oidc-provisioner", "provisioner", "types.go"))
+	require.NoError(t, err)
+
+	generated, err := Generate(src)
+	require.NoError(t, err)
+	require.Len(t, generated, len(Names))
+	for _, name := range Names {
+		published, err := Get(name)
+		require.NoError(t, err)
+		assert.Equal(t, string(generated[name]), string(published),
+			"%s is stale; run go generate ./pkg/lambda/functions/oidc-provisioner/...", FileName(name))
+	}
+}
+
+func TestGet_Unknown(t *testing.T) {
+	_, err := Get("error")
+	assert.ErrorContains(t, err, `unknown schema "error" (valid: request, response)`)
+}
+
+func TestGenerate(t *testing.T) {
+	src := []byte(`package main
+
+// Payload is a test payload
+type Payload struct {
+	Mode    string            ` + "`" + `json:"mode,omitempty" jsonschema:"enum=a|b"` + "`" + `
+	Target  string            ` + "`" + `json:"target" jsonschema:"requiredWithout=mode,format=uri"` + "`" + ` // Where to send it
+	// Count is how many to send
+	Count   *int              ` + "`" + `json:"count" jsonschema:"required"` + "`" + `
+	Labels  map[string]string ` + "`" + `json:"labels,omitempty"` + "`" + `
+	Skipped string            ` + "`" + `json:"-"` + "`" + `
+	private string
+}
+`)
+	structType, _, err := findStruct(src, "Payload")
+	require.NoError(t, err)
+
+	schema, err := objectSchema(src, structType)
+	require.NoError(t, err)
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"mode": {"type": "string", "enum": ["a", "b"]},
+			"target": {"type": "string", "format": "uri", "description": "Where to send it"},
+			"count": {"type": "integer", "description": "Count is how many to send"},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}}
+		},
+		"required": ["count"],
+		"additionalProperties": false,
+		"if": {"not": {"required": ["mode"]}},
+		"then": {"required": ["target"]}
+	}`, string(data))
+
+	// Property order follows the declaration
+	assert.Regexp(t, `"mode".*"target".*"count".*"labels"`, string(data))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := Generate([]byte("package main\n"))
+	assert.ErrorContains(t, err, "not found")
+
+	src := []byte("package main\ntype T struct {\n\tC chan int `json:\"c\"`\n}\n")
+	structType, _, err := findStruct(src, "T")
+	require.NoError(t, err)
+	_, err = objectSchema(src, structType)
+	assert.ErrorContains(t, err, "field C: unsupported type")
+
+	src = []byte("package main\ntype T struct {\n\tS string `jsonschema:\"minLength=1\"`\n}\n")
+	structType, _, err = findStruct(src, "T")
+	require.NoError(t, err)
+	_, err = objectSchema(src, structType)
+	assert.ErrorContains(t, err, `unknown jsonschema option "minLength"`)
+}
@@ -0,0 +1,297 @@
+// Package ledger records the OIDC provisioner's provisioning actions in a DynamoDB
+// table and reads them back. The provisioner writes an entry for every provisioning
+// and bind-roles request once the deployer passes it a table name, giving an
+// account-local history of which issuers were provisioned for which clusters that
+// outlives the function's log retention.
+package ledger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+const (
+	// EnvVar passes the ledger's table name to the provisioner; the ledger is disabled
+	// when it is unset
+	EnvVar = "ROSA_LEDGER_TABLE"
+
+	// DefaultTableName is the table the deployer creates and rosactl oidc history reads
+	// when no other is named
+	DefaultTableName = "rosa-oidc-provisioner-ledger"
+)
+
+// Attributes of a ledger item. Items are keyed by issuer and request, so the history
+// of an issuer is read with a single query.
+const (
+	AttributeIssuerURL       = "issuer_url" // Partition key
+	AttributeRequestID       = "request_id" // Sort key
+	AttributeLambdaRequestID = "lambda_request_id"
+	AttributeClusterID       = "cluster_id"
+	AttributeAction          = "action"
+	AttributeStatus          = "status"
+	AttributeProviderARN     = "provider_arn"
+	AttributeError           = "error"
+	AttributeRecordedAt      = "recorded_at"
+)
+
+// Actions recorded in the ledger
+const (
+	ActionProvision = "provision"
+	ActionBindRoles = "bind-roles"
+)
+
+// StatusFailed is recorded for requests that failed. A failed entry is replaced when
+// the request is retried; any other entry is written once.
+const StatusFailed = "failed"
+
+// timeFormat renders times in UTC with a fixed width, so they sort as strings
+const timeFormat = "2006-01-02T15:04:05.000Z"
+
+// tableName matches the names DynamoDB accepts for tables
+var tableName = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,255}$`)
+
+// Entry is one provisioning action recorded in the ledger
+type Entry struct {
+	IssuerURL       string    `json:"issuer_url"`
+	RequestID       string    `json:"request_id"`                  // The request's correlation ID, shared by its retries
+	LambdaRequestID string    `json:"lambda_request_id,omitempty"` // The invocation that recorded the entry
+	ClusterID       string    `json:"cluster_id,omitempty"`
+	Action          string    `json:"action"`
+	Status          string    `json:"status"` // The provisioner's response status, or failed
+	ProviderARN     string    `json:"provider_arn,omitempty"`
+	Error           string    `json:"error,omitempty"`
+	RecordedAt      time.Time `json:"recorded_at"`
+}
+
+// WriteAPI defines the DynamoDB operation needed to record entries
+type WriteAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+}
+
+// ReadAPI defines the DynamoDB operations needed to read entries
+type ReadAPI interface {
+	Query(ctx context.Context, params *dynamodb.QueryInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+	Scan(ctx context.Context, params *dynamodb.ScanInput,
+		optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error)
+}
+
+// ValidateTableName checks that name can name a DynamoDB table
+func ValidateTableName(name string) error {
+	if !tableName.MatchString(name) {
+		return fmt.Errorf("ledger table name %q must be 3-255 characters of letters, digits, underscores, hyphens, and periods", name)
+	}
+	return nil
+}
+
+// TableDefinition returns the input creating a ledger table named name, billed per
+// request so an idle ledger costs nothing but storage
+func TableDefinition(name string) *dynamodb.CreateTableInput {
+	return &dynamodb.CreateTableInput{
+		TableName:   aws.String(name),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(AttributeIssuerURL), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(AttributeRequestID), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: KeySchema(),
+	}
+}
+
+// KeySchema returns the key schema of a ledger table
+func KeySchema() []types.KeySchemaElement {
+	return []types.KeySchemaElement{
+		{AttributeName: aws.String(AttributeIssuerURL), KeyType: types.KeyTypeHash},
+		{AttributeName: aws.String(AttributeRequestID), KeyType: types.KeyTypeRange},
+	}
+}
+
+// HasKeySchema reports whether schema is the key schema of a ledger table
+func HasKeySchema(schema []types.KeySchemaElement) bool {
+	want := KeySchema()
+	if len(schema) != len(want) {
+		return false
+	}
+	for i := range want {
+		if aws.ToString(schema[i].AttributeName) != aws.ToString(want[i].AttributeName) || schema[i].KeyType != want[i].KeyType {
+			return false
+		}
+	}
+	return true
+}
+
+// Record writes entry to table and reports whether it was written. The write is
+// conditional, so a retried request is recorded once: an existing entry for the same
+// issuer and request is only replaced when it recorded a failure.
+func Record(ctx context.Context, client WriteAPI, table string, entry Entry) (bool, error) {
+	if entry.IssuerURL == "" || entry.RequestID == "" {
+		return false, errors.New("ledger entries need an issuer URL and a request ID")
+	}
+
+	_, err := client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(table),
+		Item:                marshalEntry(entry),
+		ConditionExpression: aws.String("attribute_not_exists(#request_id) OR #status = :failed"),
+		ExpressionAttributeNames: map[string]string{
+			"#request_id": AttributeRequestID,
+			"#status":     AttributeStatus,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":failed": &types.AttributeValueMemberS{Value: StatusFailed},
+		},
+	})
+	if err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to record ledger entry: %w", err)
+	}
+	return true, nil
+}
+
+// Filter selects the entries List returns. Zero fields select everything.
+type Filter struct {
+	IssuerURL string    // Read only this issuer's entries, with a query rather than a scan
+	ClusterID string    // Entries recorded for this cluster
+	Since     time.Time // Entries recorded at or after this time
+	Limit     int       // Most recent entries to return
+}
+
+// List reads the entries of table matching filter, most recent first
+func List(ctx context.Context, client ReadAPI, table string, filter Filter) ([]Entry, error) {
+	names := map[string]string{}
+	values := map[string]types.AttributeValue{}
+	var conditions []string
+	if filter.ClusterID != "" {
+		names["#cluster_id"] = AttributeClusterID
+		values[":cluster_id"] = &types.AttributeValueMemberS{Value: filter.ClusterID}
+		conditions = append(conditions, "#cluster_id = :cluster_id")
+	}
+	if !filter.Since.IsZero() {
+		names["#recorded_at"] = AttributeRecordedAt
+		values[":since"] = &types.AttributeValueMemberS{Value: filter.Since.UTC().Format(timeFormat)}
+		conditions = append(conditions, "#recorded_at >= :since")
+	}
+	var filterExpression *string
+	if len(conditions) > 0 {
+		filterExpression = aws.String(strings.Join(conditions, " AND "))
+	}
+	if filter.IssuerURL != "" {
+		names["#issuer_url"] = AttributeIssuerURL
+		values[":issuer_url"] = &types.AttributeValueMemberS{Value: filter.IssuerURL}
+	}
+
+	var items []map[string]types.AttributeValue
+	var startKey map[string]types.AttributeValue
+	for {
+		var page []map[string]types.AttributeValue
+		var err error
+		if filter.IssuerURL != "" {
+			var output *dynamodb.QueryOutput
+			output, err = client.Query(ctx, &dynamodb.QueryInput{
+				TableName:                 aws.String(table),
+				KeyConditionExpression:    aws.String("#issuer_url = :issuer_url"),
+				FilterExpression:          filterExpression,
+				ExpressionAttributeNames:  names,
+				ExpressionAttributeValues: values,
+				ExclusiveStartKey:         startKey,
+			})
+			if output != nil {
+				page, startKey = output.Items, output.LastEvaluatedKey
+			}
+		} else {
+			input := &dynamodb.ScanInput{
+				TableName:         aws.String(table),
+				FilterExpression:  filterExpression,
+				ExclusiveStartKey: startKey,
+			}
+			// DynamoDB rejects empty expression maps
+			if len(names) > 0 {
+				input.ExpressionAttributeNames = names
+				input.ExpressionAttributeValues = values
+			}
+			var output *dynamodb.ScanOutput
+			output, err = client.Scan(ctx, input)
+			if output != nil {
+				page, startKey = output.Items, output.LastEvaluatedKey
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ledger table %s: %w", table, err)
+		}
+		items = append(items, page...)
+		if len(startKey) == 0 {
+			break
+		}
+	}
+
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, unmarshalEntry(item))
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].RecordedAt.After(entries[j].RecordedAt)
+	})
+	if filter.Limit > 0 && len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+	return entries, nil
+}
+
+// marshalEntry returns entry as a DynamoDB item, leaving out empty attributes
+func marshalEntry(entry Entry) map[string]types.AttributeValue {
+	item := make(map[string]types.AttributeValue)
+	for name, value := range map[string]string{
+		AttributeIssuerURL:       entry.IssuerURL,
+		AttributeRequestID:       entry.RequestID,
+		AttributeLambdaRequestID: entry.LambdaRequestID,
+		AttributeClusterID:       entry.ClusterID,
+		AttributeAction:          entry.Action,
+		AttributeStatus:          entry.Status,
+		AttributeProviderARN:     entry.ProviderARN,
+		AttributeError:           entry.Error,
+	} {
+		if value != "" {
+			item[name] = &types.AttributeValueMemberS{Value: value}
+		}
+	}
+	if !entry.RecordedAt.IsZero() {
+		item[AttributeRecordedAt] = &types.AttributeValueMemberS{Value: entry.RecordedAt.UTC().Format(timeFormat)}
+	}
+	return item
+}
+
+// unmarshalEntry reads an entry from a DynamoDB item, ignoring attributes it does not know
+func unmarshalEntry(item map[string]types.AttributeValue) Entry {
+	str := func(name string) string {
+		if value, ok := item[name].(*types.AttributeValueMemberS); ok {
+			return value.Value
+		}
+		return ""
+	}
+	entry := Entry{
+		IssuerURL:       str(AttributeIssuerURL),
+		RequestID:       str(AttributeRequestID),
+		LambdaRequestID: str(AttributeLambdaRequestID),
+		ClusterID:       str(AttributeClusterID),
+		Action:          str(AttributeAction),
+		Status:          str(AttributeStatus),
+		ProviderARN:     str(AttributeProviderARN),
+		Error:           str(AttributeError),
+	}
+	if recordedAt, err := time.Parse(timeFormat, str(AttributeRecordedAt)); err == nil {
+		entry.RecordedAt = recordedAt
+	}
+	return entry
+}
@@ -0,0 +1,234 @@
+package ledger
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTable is an in-memory ledger table. It evaluates Record's write condition and
+// pages reads one item at a time, but does not evaluate filter expressions.
+type fakeTable struct {
+	items   map[string]map[string]types.AttributeValue
+	putErr  error
+	queries []*dynamodb.QueryInput
+	scans   []*dynamodb.ScanInput
+}
+
+func newFakeTable() *fakeTable {
+	return &fakeTable{items: make(map[string]map[string]types.AttributeValue)}
+}
+
+func itemKey(item map[string]types.AttributeValue) string {
+	return attr(item, AttributeIssuerURL) + "|" + attr(item, AttributeRequestID)
+}
+
+func attr(item map[string]types.AttributeValue, name string) string {
+	if value, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return value.Value
+	}
+	return ""
+}
+
+func (f *fakeTable) PutItem(ctx context.Context, params *dynamodb.PutItemInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if f.putErr != nil {
+		return nil, f.putErr
+	}
+	key := itemKey(params.Item)
+	if existing, ok := f.items[key]; ok && attr(existing, AttributeStatus) != StatusFailed {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	f.items[key] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// page returns the item after startKey among items, and the key of the next page
+func (f *fakeTable) page(items []map[string]types.AttributeValue,
+	startKey map[string]types.AttributeValue) ([]map[string]types.AttributeValue, map[string]types.AttributeValue) {
+	sort.Slice(items, func(i, j int) bool { return itemKey(items[i]) < itemKey(items[j]) })
+	start := 0
+	if startKey != nil {
+		for i, item := range items {
+			if itemKey(item) == itemKey(startKey) {
+				start = i + 1
+			}
+		}
+	}
+	if start >= len(items) {
+		return nil, nil
+	}
+	if start == len(items)-1 {
+		return items[start:], nil
+	}
+	return items[start : start+1], items[start]
+}
+
+func (f *fakeTable) Query(ctx context.Context, params *dynamodb.QueryInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	f.queries = append(f.queries, params)
+	issuer := attr(params.ExpressionAttributeValues, ":issuer_url")
+	var items []map[string]types.AttributeValue
+	for _, item := range f.items {
+		if attr(item, AttributeIssuerURL) == issuer {
+			items = append(items, item)
+		}
+	}
+	page, next := f.page(items, params.ExclusiveStartKey)
+	return &dynamodb.QueryOutput{Items: page, LastEvaluatedKey: next}, nil
+}
+
+func (f *fakeTable) Scan(ctx context.Context, params *dynamodb.ScanInput,
+	optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	f.scans = append(f.scans, params)
+	var items []map[string]types.AttributeValue
+	for _, item := range f.items {
+		items = append(items, item)
+	}
+	page, next := f.page(items, params.ExclusiveStartKey)
+	return &dynamodb.ScanOutput{Items: page, LastEvaluatedKey: next}, nil
+}
+
+var recordedAt = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func testEntry(issuer, requestID, status string, recordedAt time.Time) Entry {
+	return Entry{
+		IssuerURL:   issuer,
+		RequestID:   requestID,
+		ClusterID:   "test-cluster",
+		Action:      ActionProvision,
+		Status:      status,
+		ProviderARN: "arn:aws:iam::123456789012:oidc-provider/example.com",
+		RecordedAt:  recordedAt,
+	}
+}
+
+func TestValidateTableName(t *testing.T) {
+	assert.NoError(t, ValidateTableName(DefaultTableName))
+	assert.NoError(t, ValidateTableName("team.ledger_v2"))
+	assert.Error(t, ValidateTableName(""))
+	assert.Error(t, ValidateTableName("ab"))
+	assert.Error(t, ValidateTableName("rosa ledger"))
+	assert.Error(t, ValidateTableName("rosa/ledger"))
+}
+
+func TestTableDefinition(t *testing.T) {
+	input := TableDefinition("ledger")
+	assert.Equal(t, "ledger", aws.ToString(input.TableName))
+	assert.Equal(t, types.BillingModePayPerRequest, input.BillingMode)
+	assert.True(t, HasKeySchema(input.KeySchema))
+	assert.Len(t, input.AttributeDefinitions, 2, "only key attributes are defined")
+
+	assert.False(t, HasKeySchema(input.KeySchema[:1]))
+	assert.False(t, HasKeySchema([]types.KeySchemaElement{
+		{AttributeName: aws.String("id"), KeyType: types.KeyTypeHash},
+		{AttributeName: aws.String(AttributeRequestID), KeyType: types.KeyTypeRange},
+	}))
+}
+
+func TestRecord(t *testing.T) {
+	ctx := context.Background()
+	table := newFakeTable()
+
+	entry := testEntry("https://example.com", "request-1", "created", recordedAt)
+	entry.LambdaRequestID = "invocation-1"
+	written, err := Record(ctx, table, "ledger", entry)
+	require.NoError(t, err)
+	assert.True(t, written)
+
+	item := table.items["https://example.com|request-1"]
+	require.NotNil(t, item)
+	assert.Equal(t, "invocation-1", attr(item, AttributeLambdaRequestID))
+	assert.Equal(t, "2026-03-01T12:00:00.000Z", attr(item, AttributeRecordedAt))
+	assert.NotContains(t, item, AttributeError, "empty attributes are left out")
+
+	// A retry of the same request is not recorded again
+	retry := testEntry("https://example.com", "request-1", "exists", recordedAt.Add(time.Minute))
+	written, err = Record(ctx, table, "ledger", retry)
+	require.NoError(t, err)
+	assert.False(t, written)
+	assert.Equal(t, "created", attr(table.items["https://example.com|request-1"], AttributeStatus))
+}
+
+func TestRecord_ReplacesFailure(t *testing.T) {
+	ctx := context.Background()
+	table := newFakeTable()
+
+	failed := testEntry("https://example.com", "request-1", StatusFailed, recordedAt)
+	failed.Error = "throttled"
+	_, err := Record(ctx, table, "ledger", failed)
+	require.NoError(t, err)
+
+	written, err := Record(ctx, table, "ledger", testEntry("https://example.com", "request-1", "created", recordedAt.Add(time.Minute)))
+	require.NoError(t, err)
+	assert.True(t, written, "a retry replaces a failed entry")
+	item := table.items["https://example.com|request-1"]
+	assert.Equal(t, "created", attr(item, AttributeStatus))
+	assert.NotContains(t, item, AttributeError)
+}
+
+func TestRecord_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := Record(ctx, newFakeTable(), "ledger", Entry{IssuerURL: "https://example.com"})
+	assert.Error(t, err, "entries need a request ID")
+
+	table := newFakeTable()
+	table.putErr = errors.New("access denied")
+	_, err = Record(ctx, table, "ledger", testEntry("https://example.com", "request-1", "created", recordedAt))
+	assert.ErrorContains(t, err, "access denied")
+}
+
+func TestList(t *testing.T) {
+	ctx := context.Background()
+	table := newFakeTable()
+	for i, entry := range []Entry{
+		testEntry("https://a.example.com", "request-1", "created", recordedAt),
+		testEntry("https://b.example.com", "request-2", "created", recordedAt.Add(2*time.Hour)),
+		testEntry("https://a.example.com", "request-3", "exists", recordedAt.Add(time.Hour)),
+	} {
+		written, err := Record(ctx, table, "ledger", entry)
+		require.NoError(t, err, "entry %d", i)
+		require.True(t, written)
+	}
+
+	entries, err := List(ctx, table, "ledger", Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3, "every page is read")
+	assert.Equal(t, []string{"request-2", "request-3", "request-1"},
+		[]string{entries[0].RequestID, entries[1].RequestID, entries[2].RequestID}, "most recent first")
+	assert.Equal(t, recordedAt.Add(2*time.Hour), entries[0].RecordedAt)
+	assert.Equal(t, "test-cluster", entries[0].ClusterID)
+	require.Len(t, table.scans, 3)
+	assert.Nil(t, table.scans[0].FilterExpression)
+	assert.Nil(t, table.scans[0].ExpressionAttributeNames, "DynamoDB rejects empty expression maps")
+	assert.Empty(t, table.queries)
+
+	entries, err = List(ctx, table, "ledger", Filter{IssuerURL: "https://a.example.com", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "request-3", entries[0].RequestID, "the limit keeps the most recent entries")
+	require.NotEmpty(t, table.queries)
+	assert.Equal(t, "#issuer_url = :issuer_url", aws.ToString(table.queries[0].KeyConditionExpression))
+}
+
+func TestList_Filters(t *testing.T) {
+	table := newFakeTable()
+	since := time.Date(2026, 3, 1, 14, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	_, err := List(context.Background(), table, "ledger", Filter{ClusterID: "test-cluster", Since: since})
+	require.NoError(t, err)
+	require.Len(t, table.scans, 1)
+	scan := table.scans[0]
+	assert.Equal(t, "#cluster_id = :cluster_id AND #recorded_at >= :since", aws.ToString(scan.FilterExpression))
+	assert.Equal(t, AttributeClusterID, scan.ExpressionAttributeNames["#cluster_id"])
+	assert.Equal(t, "2026-03-01T13:00:00.000Z", attr(scan.ExpressionAttributeValues, ":since"), "times compare in UTC")
+}
@@ -0,0 +1,254 @@
+package oidc
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+)
+
+// Backfill defaults
+const (
+	DefaultBackfillConcurrency = 4
+	DefaultBackfillAttempts    = 3
+	DefaultBackfillBackoff     = time.Second
+)
+
+// backfillColumns are the columns of a backfill CSV file; client_ids is optional
+var backfillColumns = []string{"cluster_id", "issuer_url", "thumbprint", "client_ids"}
+
+// ReadBackfillCSV reads the clusters to backfill from a CSV file whose header names
+// the cluster_id, issuer_url, and thumbprint columns, and optionally client_ids, a
+// space-separated list of audiences. Lines starting with # are ignored. An issuer
+// listed twice is refused, since both rows would race to create the same provider.
+func ReadBackfillCSV(r io.Reader) ([]Expected, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("backfill file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backfill file: %w", err)
+	}
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range backfillColumns[:3] {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("backfill file header must name the %s columns (missing %s)", strings.Join(backfillColumns[:3], ", "), name)
+		}
+	}
+
+	var items []Expected
+	seen := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backfill file: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		item := Expected{
+			ClusterID:  field("cluster_id"),
+			IssuerURL:  field("issuer_url"),
+			Thumbprint: field("thumbprint"),
+			ClientIDs:  strings.Fields(field("client_ids")),
+		}
+		for _, required := range []struct{ name, value string }{
+			{"cluster_id", item.ClusterID},
+			{"issuer_url", item.IssuerURL},
+			{"thumbprint", item.Thumbprint},
+		} {
+			if required.value == "" {
+				return nil, fmt.Errorf("line %d: %s is required", line, required.name)
+			}
+		}
+		issuer := normalizeIssuer(item.IssuerURL)
+		if first, ok := seen[issuer]; ok {
+			return nil, fmt.Errorf("line %d: issuer %s is already listed on line %d", line, item.IssuerURL, first)
+		}
+		seen[issuer] = line
+
+		items = append(items, item)
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("backfill file lists no clusters")
+	}
+	return items, nil
+}
+
+// BackfillOptions bounds a backfill
+type BackfillOptions struct {
+	Concurrency int           // Invocations in flight at once; zero uses DefaultBackfillConcurrency
+	Attempts    int           // Invocations per cluster before it fails; zero uses DefaultBackfillAttempts
+	Backoff     time.Duration // Wait before the first retry, doubled for each further retry; zero uses DefaultBackfillBackoff
+
+	// StrictThumbprint has the provisioner refuse a cluster whose thumbprint does not
+	// match its issuer's certificate, instead of creating the provider with a warning
+	StrictThumbprint bool
+
+	// OnResult, when set, is called as each cluster finishes. Calls are serialized.
+	OnResult func(BackfillResult)
+}
+
+// BackfillResult is the outcome of provisioning one cluster's provider
+type BackfillResult struct {
+	Item        Expected
+	ProviderARN string
+	Status      string // The provisioner's status, created or already_exists; empty on failure
+	Attempts    int
+	Warnings    []string // Problems the provisioner reported without failing, such as a thumbprint mismatch
+	Err         error
+}
+
+// BackfillReport holds a result per cluster, in the order they were given
+type BackfillReport struct {
+	Results []BackfillResult
+}
+
+// Count returns the number of clusters whose provider ended with status, or that
+// failed when status is empty
+func (r *BackfillReport) Count(status string) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Err joins the errors of the clusters that failed
+func (r *BackfillReport) Err() error {
+	var errs []error
+	for _, result := range r.Results {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Backfill provisions the providers of existing clusters through the provisioner,
+// for clusters migrated from elsewhere. At most opts.Concurrency invocations are in
+// flight, and a failed invocation is retried with exponential backoff, except when the
+// provisioner rejected the request as invalid. Once ctx is done no further clusters
+// are started and they are reported with the context error.
+func Backfill(ctx context.Context, provisioner Provisioner, items []Expected, opts BackfillOptions) *BackfillReport {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBackfillConcurrency
+	}
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = DefaultBackfillAttempts
+	}
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = DefaultBackfillBackoff
+	}
+
+	report := &BackfillReport{Results: make([]BackfillResult, len(items))}
+	var mu sync.Mutex
+	finish := func(i int, result BackfillResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		report.Results[i] = result
+		if opts.OnResult != nil {
+			opts.OnResult(result)
+		}
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(items); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				finish(i, backfillOne(ctx, provisioner, items[i], opts.StrictThumbprint, attempts, backoff))
+			}
+		}()
+	}
+
+	for i, item := range items {
+		if ctx.Err() != nil {
+			finish(i, BackfillResult{Item: item, Err: fmt.Errorf("cluster %s not backfilled: %w", item.ClusterID, ctx.Err())})
+			continue
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			finish(i, BackfillResult{Item: item, Err: fmt.Errorf("cluster %s not backfilled: %w", item.ClusterID, ctx.Err())})
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	return report
+}
+
+// backfillOne invokes the provisioner for one cluster, retrying failed invocations
+func backfillOne(ctx context.Context, provisioner Provisioner, item Expected, strict bool, attempts int, backoff time.Duration) BackfillResult {
+	result := BackfillResult{Item: item}
+	for {
+		result.Attempts++
+		resp, err := provisioner.Invoke(ctx, invoker.Request{
+			IssuerURL:        item.IssuerURL,
+			Thumbprint:       item.Thumbprint,
+			ClusterID:        item.ClusterID,
+			ClientIDs:        item.ClientIDs,
+			StrictThumbprint: strict,
+		})
+		if err == nil {
+			result.ProviderARN = resp.OIDCProviderARN
+			result.Status = resp.Status
+			result.Warnings = resp.Warnings
+			return result
+		}
+
+		if result.Attempts >= attempts || isInvalidRequest(err) {
+			result.Err = fmt.Errorf("failed to provision provider for cluster %s after %d attempt(s): %w", item.ClusterID, result.Attempts, err)
+			return result
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			result.Err = fmt.Errorf("failed to provision provider for cluster %s: %w (last error: %v)", item.ClusterID, ctx.Err(), err)
+			return result
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// isInvalidRequest reports whether the provisioner rejected a request as invalid,
+// which retrying cannot fix
+func isInvalidRequest(err error) bool {
+	var funcErr *invoker.FunctionError
+	return errors.As(err, &funcErr) && strings.HasPrefix(funcErr.Message, "invalid request")
+}
@@ -0,0 +1,208 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// funcProvisioner answers invocations with invokeFunc; safe for concurrent use
+type funcProvisioner struct {
+	mu         sync.Mutex
+	invokeFunc func(req invoker.Request, attempt int) (*invoker.Response, error)
+	attempts   map[string]int
+}
+
+func (p *funcProvisioner) Invoke(ctx context.Context, req invoker.Request) (*invoker.Response, error) {
+	p.mu.Lock()
+	if p.attempts == nil {
+		p.attempts = make(map[string]int)
+	}
+	p.attempts[req.ClusterID]++
+	attempt := p.attempts[req.ClusterID]
+	p.mu.Unlock()
+
+	return p.invokeFunc(req, attempt)
+}
+
+func created(req invoker.Request) *invoker.Response {
+	return &invoker.Response{OIDCProviderARN: providerPrefix + strings.TrimPrefix(req.IssuerURL, "https://"), Status: "created"}
+}
+
+const testThumbprint = "9e99a48a9960b14926bb7f3b02e22da2b0ab7280"
+
+func backfillItems(n int) []Expected {
+	items := make([]Expected, n)
+	for i := range items {
+		id := string(rune('a' + i))
+		items[i] = Expected{ClusterID: "c-" + id, IssuerURL: "https://oidc.example.com/c-" + id, Thumbprint: testThumbprint}
+	}
+	return items
+}
+
+func TestReadBackfillCSV(t *testing.T) {
+	input := `# clusters migrated from the legacy fleet
+Cluster_ID, issuer_url, thumbprint, client_ids
+c-1, https://oidc.example.com/c-1, ` + testThumbprint + `, openshift sts.amazonaws.com
+c-2, https://oidc.example.com/c-2/, ` + testThumbprint + `,
+`
+	items, err := ReadBackfillCSV(strings.NewReader(input))
+	require.NoError(t, err)
+
+	require.Len(t, items, 2)
+	assert.Equal(t, Expected{
+		ClusterID:  "c-1",
+		IssuerURL:  "https://oidc.example.com/c-1",
+		Thumbprint: testThumbprint,
+		ClientIDs:  []string{"openshift", "sts.amazonaws.com"},
+	}, items[0])
+	assert.Equal(t, "c-2", items[1].ClusterID)
+	assert.Empty(t, items[1].ClientIDs)
+}
+
+func TestReadBackfillCSV_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			input:   "",
+			wantErr: "backfill file is empty",
+		},
+		{
+			name:    "missing column",
+			input:   "cluster_id,issuer_url\nc-1,https://oidc.example.com/c-1\n",
+			wantErr: "missing thumbprint",
+		},
+		{
+			name:    "no rows",
+			input:   "cluster_id,issuer_url,thumbprint\n",
+			wantErr: "lists no clusters",
+		},
+		{
+			name:    "missing value",
+			input:   "cluster_id,issuer_url,thumbprint\nc-1,,abc\n",
+			wantErr: "line 2: issuer_url is required",
+		},
+		{
+			name: "duplicate issuer",
+			input: "cluster_id,issuer_url,thumbprint\n" +
+				"c-1,https://oidc.example.com/c-1,abc\n" +
+				"c-2,https://oidc.example.com/c-1/,abc\n",
+			wantErr: "line 3: issuer https://oidc.example.com/c-1/ is already listed on line 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadBackfillCSV(strings.NewReader(tt.input))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestBackfill_BoundedConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	provisioner := &funcProvisioner{invokeFunc: func(req invoker.Request, attempt int) (*invoker.Response, error) {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return created(req), nil
+	}}
+
+	items := backfillItems(10)
+	var reported []string
+	report := Backfill(context.Background(), provisioner, items, BackfillOptions{
+		Concurrency: 3,
+		OnResult:    func(result BackfillResult) { reported = append(reported, result.Item.ClusterID) },
+	})
+
+	require.NoError(t, report.Err())
+	assert.LessOrEqual(t, maxInFlight, 3)
+	assert.Len(t, reported, 10)
+	assert.Equal(t, 10, report.Count("created"))
+	for i, result := range report.Results {
+		assert.Equal(t, items[i], result.Item, "results keep the input order")
+		assert.Equal(t, 1, result.Attempts)
+	}
+}
+
+func TestBackfill_Retries(t *testing.T) {
+	provisioner := &funcProvisioner{invokeFunc: func(req invoker.Request, attempt int) (*invoker.Response, error) {
+		switch req.ClusterID {
+		case "c-a":
+			// Throttled twice before succeeding
+			if attempt < 3 {
+				return nil, errors.New("TooManyRequestsException: Rate exceeded")
+			}
+			return &invoker.Response{OIDCProviderARN: created(req).OIDCProviderARN, Status: "already_exists"}, nil
+		case "c-b":
+			return nil, &invoker.FunctionError{Type: "errorString", Message: "invalid request: thumbprint must be 40 hex characters"}
+		default:
+			return nil, errors.New("connection reset")
+		}
+	}}
+
+	report := Backfill(context.Background(), provisioner, backfillItems(3), BackfillOptions{Attempts: 3, Backoff: time.Millisecond})
+
+	assert.Equal(t, "already_exists", report.Results[0].Status)
+	assert.Equal(t, 3, report.Results[0].Attempts)
+	assert.NoError(t, report.Results[0].Err)
+
+	assert.Equal(t, 1, report.Results[1].Attempts, "invalid requests are not retried")
+	assert.ErrorContains(t, report.Results[1].Err, "cluster c-b after 1 attempt(s)")
+
+	assert.Equal(t, 3, report.Results[2].Attempts)
+	assert.ErrorContains(t, report.Results[2].Err, "connection reset")
+
+	assert.Equal(t, 1, report.Count("already_exists"))
+	assert.Equal(t, 2, report.Count(""))
+	assert.ErrorContains(t, report.Err(), "cluster c-c")
+}
+
+func TestBackfill_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	provisioner := &funcProvisioner{invokeFunc: func(req invoker.Request, attempt int) (*invoker.Response, error) {
+		cancel()
+		return nil, errors.New("connection reset")
+	}}
+
+	report := Backfill(ctx, provisioner, backfillItems(4), BackfillOptions{Concurrency: 1, Backoff: time.Hour})
+
+	assert.Equal(t, 4, report.Count(""))
+	for _, result := range report.Results {
+		assert.ErrorIs(t, result.Err, context.Canceled)
+	}
+	assert.Equal(t, 1, provisioner.attempts["c-a"])
+}
+
+func TestBackfill_StrictThumbprint(t *testing.T) {
+	provisioner := &funcProvisioner{invokeFunc: func(req invoker.Request, attempt int) (*invoker.Response, error) {
+		assert.True(t, req.StrictThumbprint)
+		resp := created(req)
+		resp.Warnings = []string{"thumbprint not verified"}
+		return resp, nil
+	}}
+
+	report := Backfill(context.Background(), provisioner, backfillItems(1), BackfillOptions{StrictThumbprint: true})
+
+	require.NoError(t, report.Err())
+	assert.Equal(t, []string{"thumbprint not verified"}, report.Results[0].Warnings)
+}
@@ -0,0 +1,44 @@
+package oidc
+
+import "sort"
+
+// Dependent is a cluster bound to one of the account's OIDC providers
+type Dependent struct {
+	ClusterID   string
+	IssuerURL   string
+	ProviderARN string
+}
+
+// Dependents returns the clusters in expected that rely on a provider in providers,
+// sorted by cluster ID. A cluster is bound to a provider for its issuer, or to a
+// provider tagged rosa:cluster-id for it, so clusters the Platform API reports
+// without an issuer yet are still found once the provisioner has run for them.
+func Dependents(expected []Expected, providers []Provider) []Dependent {
+	byIssuer := make(map[string]Provider, len(providers))
+	byCluster := make(map[string]Provider)
+	for _, p := range providers {
+		byIssuer[normalizeIssuer(p.IssuerURL)] = p
+		if p.ClusterID != "" {
+			byCluster[p.ClusterID] = p
+		}
+	}
+
+	var dependents []Dependent
+	for _, e := range expected {
+		p, ok := byIssuer[normalizeIssuer(e.IssuerURL)]
+		if !ok || e.IssuerURL == "" {
+			p, ok = byCluster[e.ClusterID]
+		}
+		if !ok {
+			continue
+		}
+		dependents = append(dependents, Dependent{
+			ClusterID:   e.ClusterID,
+			IssuerURL:   e.IssuerURL,
+			ProviderARN: p.ARN,
+		})
+	}
+
+	sort.Slice(dependents, func(i, j int) bool { return dependents[i].ClusterID < dependents[j].ClusterID })
+	return dependents
+}
@@ -0,0 +1,29 @@
+package oidc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDependents(t *testing.T) {
+	providers := []Provider{
+		{ARN: providerPrefix + "oidc.example.com/a", IssuerURL: "https://oidc.example.com/a", ClusterID: "a", Managed: true},
+		{ARN: providerPrefix + "oidc.example.com/c", IssuerURL: "https://oidc.example.com/c", ClusterID: "c", Managed: true},
+		{ARN: providerPrefix + "token.actions.githubusercontent.com", IssuerURL: "https://token.actions.githubusercontent.com"},
+	}
+	expected := []Expected{
+		{ClusterID: "c"}, // No issuer yet, bound by its tag
+		{ClusterID: "a", IssuerURL: "https://OIDC.example.com/a/"}, // Bound by issuer
+		{ClusterID: "b", IssuerURL: "https://oidc.example.com/b"},  // Provider in another account
+		{ClusterID: "d"},
+	}
+
+	assert.Equal(t, []Dependent{
+		{ClusterID: "a", IssuerURL: "https://OIDC.example.com/a/", ProviderARN: providerPrefix + "oidc.example.com/a"},
+		{ClusterID: "c", ProviderARN: providerPrefix + "oidc.example.com/c"},
+	}, Dependents(expected, providers))
+
+	assert.Empty(t, Dependents(expected, nil))
+	assert.Empty(t, Dependents(nil, providers))
+}
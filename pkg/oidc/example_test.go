@@ -0,0 +1,74 @@
+package oidc_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/openshift-online/regional-cli/pkg/oidc"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+)
+
+// Reconciles the account's OIDC providers with the clusters the Platform API reports
+func Example() {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	clusters, err := platform.NewClient("https://abc123.execute-api.us-east-1.amazonaws.com", cfg).ListClusters(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var expected []oidc.Expected
+	for _, cluster := range clusters {
+		expected = append(expected, oidc.Expected{
+			ClusterID:  cluster.ID,
+			IssuerURL:  cluster.OIDCIssuerURL,
+			Thumbprint: cluster.OIDCThumbprint,
+			ClientIDs:  cluster.OIDCClientIDs,
+		})
+	}
+
+	iamClient := iam.NewFromConfig(cfg)
+	providers, err := oidc.ListProviders(ctx, iamClient)
+	if err != nil {
+		log.Fatal(err)
+	}
+	plan := oidc.Diff(expected, providers, oidc.IssuerHosts(expected))
+
+	provisioner := invoker.NewInvoker(lambda.NewFromConfig(cfg), "rosa-oidc-provisioner")
+	outcomes, err := oidc.Apply(ctx, plan, iamClient, provisioner)
+	for _, outcome := range outcomes {
+		fmt.Println(outcome.Action.Kind, outcome.Action.ClusterID, outcome.ProviderARN)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleDiff() {
+	expected := []oidc.Expected{
+		{ClusterID: "c-1", IssuerURL: "https://oidc.example.com/c-1", Thumbprint: "9e99a48a9960b14926bb7f3b02e22da2b0ab7280"},
+		{ClusterID: "c-2", IssuerURL: "https://oidc.example.com/c-2", Thumbprint: "9e99a48a9960b14926bb7f3b02e22da2b0ab7280"},
+	}
+	providers := []oidc.Provider{
+		{ARN: "arn:aws:iam::123456789012:oidc-provider/oidc.example.com/c-1", IssuerURL: "https://oidc.example.com/c-1", ClusterID: "c-1", Managed: true},
+		{ARN: "arn:aws:iam::123456789012:oidc-provider/oidc.example.com/c-0", IssuerURL: "https://oidc.example.com/c-0", ClusterID: "c-0", Managed: true},
+	}
+
+	plan := oidc.Diff(expected, providers, oidc.IssuerHosts(expected))
+	for _, action := range plan.Actions {
+		fmt.Printf("%s %s: %s\n", action.Kind, action.IssuerURL, action.Reason)
+	}
+	fmt.Println("in sync:", plan.InSync)
+	// Output:
+	// create https://oidc.example.com/c-2: provider is missing
+	// delete https://oidc.example.com/c-0: cluster c-0 no longer uses the issuer
+	// in sync: 1
+}
@@ -0,0 +1,373 @@
+// Package oidc reconciles the IAM OIDC providers in an account with the clusters the
+// Platform API reports, creating missing providers through the provisioner and
+// deleting rosa-tagged providers no cluster uses.
+//
+// This package is part of the module's stable Go API; see pkg/deployer.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+)
+
+// Tags the OIDC provisioner sets on the providers it creates
+const (
+	TagComponentKey   = "rosa:component"
+	TagComponentValue = "oidc-provider"
+	TagClusterKey     = "rosa:cluster-id"
+
+	// IAM OIDC providers have no description, so the provisioner tags them with metadata
+	TagCreatedAtKey   = "rosa:created-at"
+	TagDescriptionKey = "rosa:description"
+	TagCreatedByKey   = "rosa:created-by"
+	TagVersionKey     = "rosa:rosactl-version"
+	TagEnvironmentKey = "rosa:platform-environment"
+)
+
+// providerARNResource precedes the issuer in an OIDC provider ARN
+const providerARNResource = "oidc-provider/"
+
+// Action kinds in a Plan
+const (
+	ActionCreate = "create" // No provider exists for a cluster's issuer
+	ActionTag    = "tag"    // A provider exists but is not tagged for the cluster
+	ActionDelete = "delete" // A rosa-tagged provider's issuer is used by no cluster
+	ActionSkip   = "skip"   // A difference that cannot be reconciled automatically
+)
+
+// IAMAPI defines the IAM operations needed to read and delete OIDC providers
+type IAMAPI interface {
+	ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput,
+		optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error)
+	ListOpenIDConnectProviderTags(ctx context.Context, params *iam.ListOpenIDConnectProviderTagsInput,
+		optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProviderTagsOutput, error)
+	DeleteOpenIDConnectProvider(ctx context.Context, params *iam.DeleteOpenIDConnectProviderInput,
+		optFns ...func(*iam.Options)) (*iam.DeleteOpenIDConnectProviderOutput, error)
+}
+
+// Provisioner creates and tags OIDC providers; *invoker.Invoker satisfies it
+type Provisioner interface {
+	Invoke(ctx context.Context, req invoker.Request) (*invoker.Response, error)
+}
+
+// Expected is the OIDC provider a cluster needs
+type Expected struct {
+	ClusterID  string
+	IssuerURL  string
+	Thumbprint string
+	ClientIDs  []string
+}
+
+// Provider is an IAM OIDC provider in the account
+type Provider struct {
+	ARN       string
+	IssuerURL string // https:// issuer URL, derived from the ARN
+	ClusterID string // Value of the rosa:cluster-id tag
+	Managed   bool   // Tagged rosa:component=oidc-provider
+	Metadata  Metadata
+}
+
+// Metadata is what the provisioner's tags record about a provider; fields are empty
+// for providers created before it tagged them, or by other tools
+type Metadata struct {
+	Description         string `json:"description,omitempty"`          // rosa:description
+	CreatedAt           string `json:"created_at,omitempty"`           // rosa:created-at, RFC 3339
+	CreatedBy           string `json:"created_by,omitempty"`           // rosa:created-by, the provisioner function
+	RosactlVersion      string `json:"rosactl_version,omitempty"`      // rosa:rosactl-version that deployed the provisioner
+	PlatformEnvironment string `json:"platform_environment,omitempty"` // rosa:platform-environment
+}
+
+// Action is one change needed to converge the account
+type Action struct {
+	Kind        string
+	ClusterID   string
+	IssuerURL   string
+	ProviderARN string // Set for tag and delete actions
+	Reason      string
+
+	expected *Expected
+}
+
+// Plan lists the changes needed to converge the account
+type Plan struct {
+	Actions    []Action
+	InSync     int // Clusters whose provider exists and is tagged for them
+	OutOfScope int // rosa-tagged providers left alone because their issuer host is not in scope
+}
+
+// Changes returns the number of actions that modify the account
+func (p *Plan) Changes() int {
+	changes := 0
+	for _, action := range p.Actions {
+		if action.Kind != ActionSkip {
+			changes++
+		}
+	}
+	return changes
+}
+
+// ListOption configures ListProviders
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	keys tagkey.Format
+}
+
+// WithTagKeyFormat reads the rosa tags in the format the provisioner was deployed
+// with. Tags in the default format, in any case, are always recognized.
+func WithTagKeyFormat(format tagkey.Format) ListOption {
+	return func(o *listOptions) {
+		o.keys = format
+	}
+}
+
+// ListProviders returns the account's OIDC providers with their rosa tags and metadata
+func ListProviders(ctx context.Context, client IAMAPI, opts ...ListOption) ([]Provider, error) {
+	var o listOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	output, err := client.ListOpenIDConnectProviders(ctx, &iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OIDC providers: %w", err)
+	}
+
+	var providers []Provider
+	for _, entry := range output.OpenIDConnectProviderList {
+		arn := aws.ToString(entry.Arn)
+		provider := Provider{ARN: arn, IssuerURL: issuerFromARN(arn)}
+
+		paginator := iam.NewListOpenIDConnectProviderTagsPaginator(client, &iam.ListOpenIDConnectProviderTagsInput{
+			OpenIDConnectProviderArn: entry.Arn,
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tags of %s: %w", arn, err)
+			}
+			for _, tag := range page.Tags {
+				switch key := aws.ToString(tag.Key); {
+				case o.keys.Matches(key, TagComponentKey):
+					provider.Managed = aws.ToString(tag.Value) == TagComponentValue
+				case o.keys.Matches(key, TagClusterKey):
+					provider.ClusterID = aws.ToString(tag.Value)
+				case o.keys.Matches(key, TagCreatedAtKey):
+					provider.Metadata.CreatedAt = aws.ToString(tag.Value)
+				case o.keys.Matches(key, TagDescriptionKey):
+					provider.Metadata.Description = aws.ToString(tag.Value)
+				case o.keys.Matches(key, TagCreatedByKey):
+					provider.Metadata.CreatedBy = aws.ToString(tag.Value)
+				case o.keys.Matches(key, TagVersionKey):
+					provider.Metadata.RosactlVersion = aws.ToString(tag.Value)
+				case o.keys.Matches(key, TagEnvironmentKey):
+					provider.Metadata.PlatformEnvironment = aws.ToString(tag.Value)
+				}
+			}
+		}
+		providers = append(providers, provider)
+	}
+
+	sort.Slice(providers, func(i, j int) bool { return providers[i].ARN < providers[j].ARN })
+	return providers, nil
+}
+
+// Diff plans the changes that converge providers with expected. Only rosa-tagged
+// providers whose issuer host is in issuerHosts are deleted, so providers belonging
+// to another Platform API environment sharing the account are left alone.
+func Diff(expected []Expected, providers []Provider, issuerHosts []string) *Plan {
+	plan := &Plan{}
+
+	byIssuer := make(map[string]Provider, len(providers))
+	for _, provider := range providers {
+		byIssuer[normalizeIssuer(provider.IssuerURL)] = provider
+	}
+
+	sorted := append([]Expected(nil), expected...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ClusterID < sorted[j].ClusterID })
+
+	wanted := make(map[string]string, len(sorted))
+	for i := range sorted {
+		e := &sorted[i]
+		issuer := normalizeIssuer(e.IssuerURL)
+		action := Action{ClusterID: e.ClusterID, IssuerURL: e.IssuerURL, expected: e}
+
+		if owner, ok := wanted[issuer]; ok {
+			action.Kind = ActionSkip
+			action.Reason = fmt.Sprintf("issuer is also used by cluster %s", owner)
+			plan.Actions = append(plan.Actions, action)
+			continue
+		}
+		wanted[issuer] = e.ClusterID
+
+		provider, exists := byIssuer[issuer]
+		switch {
+		case !exists:
+			action.Kind = ActionCreate
+			action.Reason = "provider is missing"
+		case !provider.Managed:
+			action.Kind = ActionTag
+			action.ProviderARN = provider.ARN
+			action.Reason = "provider is not tagged " + TagComponentKey + "=" + TagComponentValue
+		case provider.ClusterID != e.ClusterID:
+			action.Kind = ActionTag
+			action.ProviderARN = provider.ARN
+			action.Reason = fmt.Sprintf("provider is tagged for cluster %q", provider.ClusterID)
+		default:
+			plan.InSync++
+			continue
+		}
+		// The provisioner validates the thumbprint even when it only tags
+		if e.Thumbprint == "" {
+			action.Reason += ", but the Platform API reported no thumbprint"
+			action.Kind = ActionSkip
+		}
+		plan.Actions = append(plan.Actions, action)
+	}
+
+	scope := make(map[string]bool, len(issuerHosts))
+	for _, host := range issuerHosts {
+		scope[strings.ToLower(host)] = true
+	}
+	for _, provider := range providers {
+		if !provider.Managed {
+			continue
+		}
+		issuer := normalizeIssuer(provider.IssuerURL)
+		if _, ok := wanted[issuer]; ok {
+			continue
+		}
+		if !scope[issuerHost(issuer)] {
+			plan.OutOfScope++
+			continue
+		}
+		reason := "no cluster uses the issuer"
+		if provider.ClusterID != "" {
+			reason = fmt.Sprintf("cluster %s no longer uses the issuer", provider.ClusterID)
+		}
+		plan.Actions = append(plan.Actions, Action{
+			Kind:        ActionDelete,
+			ClusterID:   provider.ClusterID,
+			IssuerURL:   provider.IssuerURL,
+			ProviderARN: provider.ARN,
+			Reason:      reason,
+		})
+	}
+
+	return plan
+}
+
+// Outcome is the result of applying one action
+type Outcome struct {
+	Action      Action
+	ProviderARN string
+	Err         error
+}
+
+// Apply carries out the plan's changes. Creating and tagging go through the
+// provisioner so providers get the same thumbprint, client IDs, and tags as when
+// clusters are created; deletes call IAM directly. Every action is attempted; the
+// returned error joins the failures.
+func Apply(ctx context.Context, plan *Plan, client IAMAPI, provisioner Provisioner) ([]Outcome, error) {
+	var outcomes []Outcome
+	var errs []error
+	for _, action := range plan.Actions {
+		if action.Kind == ActionSkip {
+			continue
+		}
+		outcome := Outcome{Action: action, ProviderARN: action.ProviderARN}
+
+		switch action.Kind {
+		case ActionCreate, ActionTag:
+			resp, err := provisioner.Invoke(ctx, invoker.Request{
+				IssuerURL:  action.expected.IssuerURL,
+				Thumbprint: action.expected.Thumbprint,
+				ClusterID:  action.expected.ClusterID,
+				ClientIDs:  action.expected.ClientIDs,
+			})
+			if err != nil {
+				outcome.Err = fmt.Errorf("failed to provision provider for cluster %s: %w", action.ClusterID, err)
+			} else {
+				outcome.ProviderARN = resp.OIDCProviderARN
+			}
+		case ActionDelete:
+			_, err := client.DeleteOpenIDConnectProvider(ctx, &iam.DeleteOpenIDConnectProviderInput{
+				OpenIDConnectProviderArn: aws.String(action.ProviderARN),
+			})
+			if err != nil {
+				outcome.Err = fmt.Errorf("failed to delete %s: %w", action.ProviderARN, err)
+			}
+		}
+
+		if outcome.Err != nil {
+			errs = append(errs, outcome.Err)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, errors.Join(errs...)
+}
+
+// IssuerHosts returns the distinct hosts of the expected issuers
+func IssuerHosts(expected []Expected) []string {
+	seen := make(map[string]bool)
+	var hosts []string
+	for _, e := range expected {
+		host := issuerHost(normalizeIssuer(e.IssuerURL))
+		if host != "" && !seen[host] {
+			seen[host] = true
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// issuerFromARN returns the https:// issuer URL of an OIDC provider ARN
+func issuerFromARN(arn string) string {
+	i := strings.Index(arn, providerARNResource)
+	if i < 0 {
+		return ""
+	}
+	return "https://" + arn[i+len(providerARNResource):]
+}
+
+// normalizeIssuer returns the issuer as IAM stores it: host and path without the
+// scheme or a trailing slash, with the host lowercased
+func normalizeIssuer(issuerURL string) string {
+	issuer := strings.TrimSuffix(strings.TrimPrefix(issuerURL, "https://"), "/")
+	host, path, _ := strings.Cut(issuer, "/")
+	if path == "" {
+		return strings.ToLower(host)
+	}
+	return strings.ToLower(host) + "/" + path
+}
+
+// issuerHost returns the host of a normalized issuer
+func issuerHost(issuer string) string {
+	u, err := url.Parse("https://" + issuer)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// FindProvider returns the provider ref names: its ARN, its issuer URL, or the cluster
+// ID it is tagged for
+func FindProvider(providers []Provider, ref string) (*Provider, bool) {
+	issuer := normalizeIssuer(ref)
+	for i, provider := range providers {
+		if provider.ARN == ref || normalizeIssuer(provider.IssuerURL) == issuer || (provider.ClusterID != "" && provider.ClusterID == ref) {
+			return &providers[i], true
+		}
+	}
+	return nil, false
+}
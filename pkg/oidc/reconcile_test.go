@@ -0,0 +1,197 @@
+package oidc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/openshift-online/regional-cli/pkg/lambda/invoker"
+	"github.com/openshift-online/regional-cli/pkg/tagkey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const providerPrefix = "arn:aws:iam::123456789012:oidc-provider/"
+
+type mockIAMClient struct {
+	tags      map[string][]types.Tag
+	deleted   []string
+	deleteErr error
+}
+
+func (m *mockIAMClient) ListOpenIDConnectProviders(ctx context.Context, params *iam.ListOpenIDConnectProvidersInput, optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProvidersOutput, error) {
+	output := &iam.ListOpenIDConnectProvidersOutput{}
+	for arn := range m.tags {
+		output.OpenIDConnectProviderList = append(output.OpenIDConnectProviderList, types.OpenIDConnectProviderListEntry{Arn: aws.String(arn)})
+	}
+	return output, nil
+}
+
+func (m *mockIAMClient) ListOpenIDConnectProviderTags(ctx context.Context, params *iam.ListOpenIDConnectProviderTagsInput, optFns ...func(*iam.Options)) (*iam.ListOpenIDConnectProviderTagsOutput, error) {
+	return &iam.ListOpenIDConnectProviderTagsOutput{Tags: m.tags[aws.ToString(params.OpenIDConnectProviderArn)]}, nil
+}
+
+func (m *mockIAMClient) DeleteOpenIDConnectProvider(ctx context.Context, params *iam.DeleteOpenIDConnectProviderInput, optFns ...func(*iam.Options)) (*iam.DeleteOpenIDConnectProviderOutput, error) {
+	if m.deleteErr != nil {
+		return nil, m.deleteErr
+	}
+	m.deleted = append(m.deleted, aws.ToString(params.OpenIDConnectProviderArn))
+	return &iam.DeleteOpenIDConnectProviderOutput{}, nil
+}
+
+type mockProvisioner struct {
+	requests []invoker.Request
+}
+
+func (m *mockProvisioner) Invoke(ctx context.Context, req invoker.Request) (*invoker.Response, error) {
+	m.requests = append(m.requests, req)
+	return &invoker.Response{OIDCProviderARN: providerPrefix + req.IssuerURL[len("https://"):], Status: "created"}, nil
+}
+
+func rosaTags(clusterID string) []types.Tag {
+	return []types.Tag{
+		{Key: aws.String(TagComponentKey), Value: aws.String(TagComponentValue)},
+		{Key: aws.String(TagClusterKey), Value: aws.String(clusterID)},
+	}
+}
+
+func TestListProviders(t *testing.T) {
+	client := &mockIAMClient{tags: map[string][]types.Tag{
+		providerPrefix + "oidc.example.com/c-1": rosaTags("c-1"),
+		providerPrefix + "token.actions.githubusercontent.com": {
+			{Key: aws.String("team"), Value: aws.String("ci")},
+		},
+	}}
+
+	providers, err := ListProviders(context.Background(), client)
+	require.NoError(t, err)
+	assert.Equal(t, []Provider{
+		{ARN: providerPrefix + "oidc.example.com/c-1", IssuerURL: "https://oidc.example.com/c-1", ClusterID: "c-1", Managed: true},
+		{ARN: providerPrefix + "token.actions.githubusercontent.com", IssuerURL: "https://token.actions.githubusercontent.com"},
+	}, providers)
+}
+
+func TestListProviders_TagKeyFormat(t *testing.T) {
+	client := &mockIAMClient{tags: map[string][]types.Tag{
+		providerPrefix + "oidc.example.com/c-1": {
+			{Key: aws.String("Org-Component"), Value: aws.String(TagComponentValue)},
+			{Key: aws.String("Org-Cluster-Id"), Value: aws.String("c-1")},
+		},
+		providerPrefix + "oidc.example.com/c-2": rosaTags("c-2"),
+	}}
+
+	providers, err := ListProviders(context.Background(), client,
+		WithTagKeyFormat(tagkey.Format{Prefix: "org-", Case: tagkey.CaseTitle}))
+	require.NoError(t, err)
+	require.Len(t, providers, 2)
+	assert.Equal(t, "c-1", providers[0].ClusterID)
+	assert.True(t, providers[0].Managed)
+	// Providers tagged before the format changed are still recognized
+	assert.Equal(t, "c-2", providers[1].ClusterID)
+	assert.True(t, providers[1].Managed)
+}
+
+func TestListProviders_Metadata(t *testing.T) {
+	client := &mockIAMClient{tags: map[string][]types.Tag{
+		providerPrefix + "oidc.example.com/c-1": append(rosaTags("c-1"),
+			types.Tag{Key: aws.String(TagCreatedAtKey), Value: aws.String("2026-03-14T14:26:53Z")},
+			types.Tag{Key: aws.String(TagDescriptionKey), Value: aws.String("ROSA cluster OIDC provider")},
+			types.Tag{Key: aws.String(TagCreatedByKey), Value: aws.String("rosa-oidc-provisioner")},
+			types.Tag{Key: aws.String(TagVersionKey), Value: aws.String("v1.4.0")},
+			types.Tag{Key: aws.String("ROSA:PLATFORM-ENVIRONMENT"), Value: aws.String("staging")},
+		),
+	}}
+
+	providers, err := ListProviders(context.Background(), client, WithTagKeyFormat(tagkey.Format{Case: tagkey.CaseUpper}))
+	require.NoError(t, err)
+	require.Len(t, providers, 1)
+	assert.Equal(t, Metadata{
+		Description:         "ROSA cluster OIDC provider",
+		CreatedAt:           "2026-03-14T14:26:53Z",
+		CreatedBy:           "rosa-oidc-provisioner",
+		RosactlVersion:      "v1.4.0",
+		PlatformEnvironment: "staging",
+	}, providers[0].Metadata)
+}
+
+func TestFindProvider(t *testing.T) {
+	providers := []Provider{
+		{ARN: providerPrefix + "oidc.example.com/c-1", IssuerURL: "https://oidc.example.com/c-1", ClusterID: "c-1", Managed: true},
+		{ARN: providerPrefix + "token.actions.githubusercontent.com", IssuerURL: "https://token.actions.githubusercontent.com"},
+	}
+
+	for _, ref := range []string{"c-1", providerPrefix + "oidc.example.com/c-1", "https://OIDC.example.com/c-1/"} {
+		provider, ok := FindProvider(providers, ref)
+		require.True(t, ok, ref)
+		assert.Equal(t, "c-1", provider.ClusterID)
+	}
+
+	_, ok := FindProvider(providers, "c-2")
+	assert.False(t, ok)
+}
+
+func TestDiff(t *testing.T) {
+	expected := []Expected{
+		{ClusterID: "c-3", IssuerURL: "https://oidc.example.com/c-3", Thumbprint: "t"},
+		{ClusterID: "c-1", IssuerURL: "https://OIDC.example.com/c-1/", Thumbprint: "t"},
+		{ClusterID: "c-2", IssuerURL: "https://oidc.example.com/c-2", Thumbprint: "t"},
+		{ClusterID: "c-4", IssuerURL: "https://oidc.example.com/c-4", Thumbprint: "t"},
+		{ClusterID: "c-5", IssuerURL: "https://oidc.example.com/c-5"},
+		{ClusterID: "c-6", IssuerURL: "https://oidc.example.com/c-1", Thumbprint: "t"},
+	}
+	providers := []Provider{
+		{ARN: "p-1", IssuerURL: "https://oidc.example.com/c-1", ClusterID: "c-1", Managed: true},
+		{ARN: "p-2", IssuerURL: "https://oidc.example.com/c-2"},
+		{ARN: "p-4", IssuerURL: "https://oidc.example.com/c-4", ClusterID: "old", Managed: true},
+		{ARN: "p-9", IssuerURL: "https://oidc.example.com/c-9", ClusterID: "c-9", Managed: true},
+		{ARN: "p-other", IssuerURL: "https://oidc.other.example.com/c-1", Managed: true},
+		{ARN: "p-github", IssuerURL: "https://token.actions.githubusercontent.com"},
+	}
+
+	plan := Diff(expected, providers, IssuerHosts(expected))
+	assert.Equal(t, 1, plan.InSync)
+	assert.Equal(t, 1, plan.OutOfScope)
+	assert.Equal(t, 4, plan.Changes())
+
+	kinds := map[string]string{}
+	for _, action := range plan.Actions {
+		kinds[action.ClusterID] = action.Kind
+	}
+	assert.Equal(t, map[string]string{
+		"c-2": ActionTag,
+		"c-3": ActionCreate,
+		"c-4": ActionTag,
+		"c-5": ActionSkip,
+		"c-6": ActionSkip,
+		"c-9": ActionDelete,
+	}, kinds)
+	assert.Equal(t, "p-9", plan.Actions[len(plan.Actions)-1].ProviderARN)
+}
+
+func TestApply(t *testing.T) {
+	client := &mockIAMClient{tags: map[string][]types.Tag{
+		providerPrefix + "oidc.example.com/c-9": rosaTags("c-9"),
+	}}
+	expected := []Expected{{ClusterID: "c-1", IssuerURL: "https://oidc.example.com/c-1", Thumbprint: "t", ClientIDs: []string{"openshift"}}}
+
+	providers, err := ListProviders(context.Background(), client)
+	require.NoError(t, err)
+	plan := Diff(expected, providers, IssuerHosts(expected))
+
+	provisioner := &mockProvisioner{}
+	outcomes, err := Apply(context.Background(), plan, client, provisioner)
+	require.NoError(t, err)
+	require.Len(t, outcomes, 2)
+	assert.Equal(t, providerPrefix+"oidc.example.com/c-1", outcomes[0].ProviderARN)
+	assert.Equal(t, []invoker.Request{{IssuerURL: "https://oidc.example.com/c-1", Thumbprint: "t", ClusterID: "c-1", ClientIDs: []string{"openshift"}}}, provisioner.requests)
+	assert.Equal(t, []string{providerPrefix + "oidc.example.com/c-9"}, client.deleted)
+
+	client.deleteErr = errors.New("AccessDenied")
+	outcomes, err = Apply(context.Background(), plan, client, provisioner)
+	assert.ErrorContains(t, err, "failed to delete "+providerPrefix+"oidc.example.com/c-9: AccessDenied")
+	assert.Len(t, outcomes, 2)
+	assert.NoError(t, outcomes[0].Err)
+}
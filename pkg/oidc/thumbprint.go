@@ -0,0 +1,122 @@
+package oidc
+
+import (
+	"context"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ThumbprintMismatchError is returned when a supplied thumbprint is not that of the
+// certificate chain the issuer serves. IAM would create the provider, but it would
+// never validate the issuer's tokens.
+type ThumbprintMismatchError struct {
+	IssuerURL string
+	Supplied  string
+	Live      string
+}
+
+func (e *ThumbprintMismatchError) Error() string {
+	return fmt.Sprintf("thumbprint %s does not match the certificate served by %s (thumbprint %s)", e.Supplied, e.IssuerURL, e.Live)
+}
+
+// ThumbprintOption configures FetchThumbprint
+type ThumbprintOption func(*thumbprintOptions)
+
+type thumbprintOptions struct {
+	rootCAs *x509.CertPool
+	dialer  *net.Dialer
+}
+
+// WithRootCAs verifies the issuer's certificate against pool instead of the system roots
+func WithRootCAs(pool *x509.CertPool) ThumbprintOption {
+	return func(o *thumbprintOptions) {
+		o.rootCAs = pool
+	}
+}
+
+// WithDialer connects to the issuer with dialer, for example to set a Control
+// function that refuses some addresses
+func WithDialer(dialer *net.Dialer) ThumbprintOption {
+	return func(o *thumbprintOptions) {
+		o.dialer = dialer
+	}
+}
+
+// Thumbprint returns the thumbprint IAM records for a certificate: its SHA-1 digest
+// as 40 lowercase hex characters
+func Thumbprint(cert *x509.Certificate) string {
+	sum := sha1.Sum(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeThumbprint lowercases a thumbprint and drops the colons and spaces some
+// tools print between its bytes
+func NormalizeThumbprint(thumbprint string) string {
+	return strings.ToLower(strings.NewReplacer(":", "", " ", "").Replace(strings.TrimSpace(thumbprint)))
+}
+
+// FetchThumbprint connects to the issuer's host and returns the thumbprint of the last
+// certificate in the chain it serves, the top intermediate or root CA, as IAM expects.
+// The chain must verify for the host.
+func FetchThumbprint(ctx context.Context, issuerURL string, opts ...ThumbprintOption) (string, error) {
+	options := &thumbprintOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	parsed, err := url.Parse(issuerURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid issuer URL: %w", err)
+	}
+	if parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return "", fmt.Errorf("issuer URL %s must be an https URL", issuerURL)
+	}
+	port := parsed.Port()
+	if port == "" {
+		port = "443"
+	}
+
+	dialer := &tls.Dialer{NetDialer: options.dialer, Config: &tls.Config{
+		ServerName: parsed.Hostname(),
+		RootCAs:    options.rootCAs,
+		MinVersion: tls.VersionTLS12,
+	}}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(parsed.Hostname(), port))
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", parsed.Host, err)
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return "", fmt.Errorf("%s served no certificates", parsed.Host)
+	}
+	return Thumbprint(certs[len(certs)-1]), nil
+}
+
+// VerifyThumbprint checks a thumbprint against the one the issuer serves now. It
+// returns a *ThumbprintMismatchError when they differ, and other errors when the
+// issuer could not be reached, in which case the thumbprint is unverified.
+func VerifyThumbprint(ctx context.Context, issuerURL, thumbprint string, opts ...ThumbprintOption) error {
+	live, err := FetchThumbprint(ctx, issuerURL, opts...)
+	if err != nil {
+		return err
+	}
+	if NormalizeThumbprint(thumbprint) != live {
+		return &ThumbprintMismatchError{IssuerURL: issuerURL, Supplied: thumbprint, Live: live}
+	}
+	return nil
+}
+
+// IsThumbprintMismatch reports whether err is a *ThumbprintMismatchError
+func IsThumbprintMismatch(err error) bool {
+	var mismatchErr *ThumbprintMismatchError
+	return errors.As(err, &mismatchErr)
+}
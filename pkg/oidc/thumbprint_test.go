@@ -0,0 +1,67 @@
+package oidc
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// issuerServer starts a TLS server and returns its issuer URL, a pool trusting it, and
+// the thumbprint of its certificate
+func issuerServer(t *testing.T) (string, *x509.CertPool, string) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return server.URL + "/cluster", pool, Thumbprint(server.Certificate())
+}
+
+func TestFetchThumbprint(t *testing.T) {
+	issuerURL, pool, want := issuerServer(t)
+
+	got, err := FetchThumbprint(context.Background(), issuerURL, WithRootCAs(pool))
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+	assert.Len(t, got, 40)
+}
+
+func TestFetchThumbprint_Untrusted(t *testing.T) {
+	issuerURL, _, _ := issuerServer(t)
+
+	_, err := FetchThumbprint(context.Background(), issuerURL)
+	assert.ErrorContains(t, err, "failed to connect")
+}
+
+func TestFetchThumbprint_NotHTTPS(t *testing.T) {
+	_, err := FetchThumbprint(context.Background(), "http://oidc.example.com/c-1")
+	assert.ErrorContains(t, err, "must be an https URL")
+}
+
+func TestVerifyThumbprint(t *testing.T) {
+	issuerURL, pool, live := issuerServer(t)
+
+	// Case and byte separators do not matter
+	var colons []string
+	for i := 0; i < len(live); i += 2 {
+		colons = append(colons, strings.ToUpper(live[i:i+2]))
+	}
+	require.NoError(t, VerifyThumbprint(context.Background(), issuerURL, strings.Join(colons, ":"), WithRootCAs(pool)))
+
+	err := VerifyThumbprint(context.Background(), issuerURL, testThumbprint, WithRootCAs(pool))
+	var mismatchErr *ThumbprintMismatchError
+	require.ErrorAs(t, err, &mismatchErr)
+	assert.Equal(t, live, mismatchErr.Live)
+	assert.Equal(t, testThumbprint, mismatchErr.Supplied)
+	assert.True(t, IsThumbprintMismatch(err))
+
+	err = VerifyThumbprint(context.Background(), issuerURL, testThumbprint)
+	assert.Error(t, err)
+	assert.False(t, IsThumbprintMismatch(err), "an unreachable issuer leaves the thumbprint unverified")
+}
@@ -0,0 +1,185 @@
+package oidc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultAudience is the audience of the service account tokens exchanged with STS
+const DefaultAudience = "sts.amazonaws.com"
+
+// webIdentityAction is the action a trust policy grants an OIDC provider's tokens
+const webIdentityAction = "sts:AssumeRoleWithWebIdentity"
+
+// serviceAccountSubject matches the subject of a Kubernetes service account token
+var serviceAccountSubject = regexp.MustCompile(`^system:serviceaccount:[a-z0-9]([-a-z0-9]*[a-z0-9])?:[a-z0-9]([-.a-z0-9]*[a-z0-9])?$`)
+
+// ValidateServiceAccountSubject checks that subject names a single Kubernetes service
+// account, as system:serviceaccount:<namespace>:<name>
+func ValidateServiceAccountSubject(subject string) error {
+	if !serviceAccountSubject.MatchString(subject) {
+		return fmt.Errorf("subject %q must be a service account, as system:serviceaccount:<namespace>:<name>", subject)
+	}
+	return nil
+}
+
+// DecodeTrustPolicy returns a trust policy as IAM's GetRole returns it, URL-encoded,
+// as a JSON document
+func DecodeTrustPolicy(encoded string) (string, error) {
+	document, err := url.QueryUnescape(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode trust policy: %w", err)
+	}
+	return document, nil
+}
+
+// BindTrustPolicy returns the trust policy document with a statement allowing tokens
+// the OIDC provider issues for subjects, with audience, to assume the role, and
+// reports whether the document changed. A statement for the provider conditioned only
+// on its sub and aud, with the same audience, is extended instead of adding another;
+// the other statements are kept as they are.
+func BindTrustPolicy(document, providerARN string, subjects []string, audience string) (string, bool, error) {
+	i := strings.Index(providerARN, providerARNResource)
+	if i < 0 {
+		return "", false, fmt.Errorf("%s is not an OIDC provider ARN", providerARN)
+	}
+	issuer := providerARN[i+len(providerARNResource):]
+	if len(subjects) == 0 {
+		return "", false, errors.New("at least one service account subject is required")
+	}
+	var unique []string
+	for _, subject := range subjects {
+		if err := ValidateServiceAccountSubject(subject); err != nil {
+			return "", false, err
+		}
+		if !slices.Contains(unique, subject) {
+			unique = append(unique, subject)
+		}
+	}
+	subjects = unique
+	if audience == "" {
+		audience = DefaultAudience
+	}
+	subKey, audKey := issuer+":sub", issuer+":aud"
+
+	var policy map[string]interface{}
+	if err := json.Unmarshal([]byte(document), &policy); err != nil {
+		return "", false, fmt.Errorf("failed to parse trust policy: %w", err)
+	}
+	var statements []interface{}
+	switch s := policy["Statement"].(type) {
+	case []interface{}:
+		statements = s
+	case map[string]interface{}:
+		statements = []interface{}{s}
+	case nil:
+	default:
+		return "", false, errors.New("trust policy Statement must be an object or a list")
+	}
+
+	changed := true
+	bound := false
+	for _, raw := range statements {
+		statement, ok := raw.(map[string]interface{})
+		if !ok || !isProviderStatement(statement, providerARN) {
+			continue
+		}
+		// A statement without a sub condition allows every subject; narrowing it
+		// would lock out the service accounts that use it
+		equals, ok := conditionOnly(statement, subKey, audKey)
+		if !ok || equals[subKey] == nil || !slices.Equal(stringList(equals[audKey]), []string{audience}) {
+			continue
+		}
+
+		allowed := stringList(equals[subKey])
+		missing := false
+		for _, subject := range subjects {
+			if !slices.Contains(allowed, subject) {
+				allowed = append(allowed, subject)
+				missing = true
+			}
+		}
+		equals[subKey] = allowed
+		changed, bound = missing, true
+		break
+	}
+	if !bound {
+		statements = append(statements, map[string]interface{}{
+			"Effect":    "Allow",
+			"Principal": map[string]interface{}{"Federated": providerARN},
+			"Action":    webIdentityAction,
+			"Condition": map[string]interface{}{
+				"StringEquals": map[string]interface{}{subKey: subjects, audKey: audience},
+			},
+		})
+	}
+	if !changed {
+		return document, false, nil
+	}
+
+	if _, ok := policy["Version"]; !ok {
+		policy["Version"] = "2012-10-17"
+	}
+	policy["Statement"] = statements
+	updated, err := json.Marshal(policy)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to marshal trust policy: %w", err)
+	}
+	return string(updated), true, nil
+}
+
+// isProviderStatement reports whether statement allows the OIDC provider's tokens to
+// assume the role
+func isProviderStatement(statement map[string]interface{}, providerARN string) bool {
+	if statement["Effect"] != "Allow" {
+		return false
+	}
+	principal, ok := statement["Principal"].(map[string]interface{})
+	if !ok || !slices.Contains(stringList(principal["Federated"]), providerARN) {
+		return false
+	}
+	return slices.Contains(stringList(statement["Action"]), webIdentityAction)
+}
+
+// conditionOnly returns the StringEquals block of a statement whose only conditions are
+// StringEquals on subKey and audKey
+func conditionOnly(statement map[string]interface{}, subKey, audKey string) (map[string]interface{}, bool) {
+	condition, ok := statement["Condition"].(map[string]interface{})
+	if !ok || len(condition) != 1 {
+		return nil, false
+	}
+	equals, ok := condition["StringEquals"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	for key := range equals {
+		if key != subKey && key != audKey {
+			return nil, false
+		}
+	}
+	return equals, true
+}
+
+// stringList returns a policy value that is a string or a list of strings as a list
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	case []string:
+		return v
+	}
+	return nil
+}
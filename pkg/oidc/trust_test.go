@@ -0,0 +1,96 @@
+package oidc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testProviderARN = "arn:aws:iam::123456789012:oidc-provider/oidc.example.com/cluster-1"
+	testSubject     = "system:serviceaccount:openshift-ingress-operator:ingress-operator"
+)
+
+const ec2TrustPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"ec2.amazonaws.com"},"Action":"sts:AssumeRole"}]}`
+
+func statementsOf(t *testing.T, document string) []map[string]interface{} {
+	t.Helper()
+	var policy struct {
+		Statement []map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal([]byte(document), &policy))
+	return policy.Statement
+}
+
+func TestBindTrustPolicy(t *testing.T) {
+	updated, changed, err := BindTrustPolicy(ec2TrustPolicy, testProviderARN, []string{testSubject, testSubject}, "")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	statements := statementsOf(t, updated)
+	require.Len(t, statements, 2)
+	assert.Equal(t, "ec2.amazonaws.com", statements[0]["Principal"].(map[string]interface{})["Service"])
+	assert.Equal(t, map[string]interface{}{
+		"Effect":    "Allow",
+		"Principal": map[string]interface{}{"Federated": testProviderARN},
+		"Action":    "sts:AssumeRoleWithWebIdentity",
+		"Condition": map[string]interface{}{"StringEquals": map[string]interface{}{
+			"oidc.example.com/cluster-1:sub": []interface{}{testSubject},
+			"oidc.example.com/cluster-1:aud": "sts.amazonaws.com",
+		}},
+	}, statements[1])
+
+	again, changed, err := BindTrustPolicy(updated, testProviderARN, []string{testSubject}, DefaultAudience)
+	require.NoError(t, err)
+	assert.False(t, changed, "the subject is already bound")
+	assert.Equal(t, updated, again)
+
+	other := "system:serviceaccount:openshift-image-registry:registry"
+	extended, changed, err := BindTrustPolicy(updated, testProviderARN, []string{other}, "")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	statements = statementsOf(t, extended)
+	require.Len(t, statements, 2, "the provider's statement is extended")
+	assert.Equal(t, []interface{}{testSubject, other},
+		statements[1]["Condition"].(map[string]interface{})["StringEquals"].(map[string]interface{})["oidc.example.com/cluster-1:sub"])
+}
+
+func TestBindTrustPolicy_KeepsBroaderStatements(t *testing.T) {
+	// A statement without a sub condition, or with other conditions, is not narrowed
+	policy := `{"Version":"2012-10-17","Statement":[
+		{"Effect":"Allow","Principal":{"Federated":"` + testProviderARN + `"},"Action":"sts:AssumeRoleWithWebIdentity",
+		 "Condition":{"StringEquals":{"oidc.example.com/cluster-1:aud":"sts.amazonaws.com"}}},
+		{"Effect":"Allow","Principal":{"Federated":"` + testProviderARN + `"},"Action":"sts:AssumeRoleWithWebIdentity",
+		 "Condition":{"StringLike":{"oidc.example.com/cluster-1:sub":"system:serviceaccount:ns:*"}}}
+	]}`
+
+	updated, changed, err := BindTrustPolicy(policy, testProviderARN, []string{testSubject}, "")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	statements := statementsOf(t, updated)
+	require.Len(t, statements, 3)
+	assert.Equal(t, map[string]interface{}{"StringEquals": map[string]interface{}{"oidc.example.com/cluster-1:aud": "sts.amazonaws.com"}},
+		statements[0]["Condition"])
+}
+
+func TestBindTrustPolicy_Invalid(t *testing.T) {
+	_, _, err := BindTrustPolicy(ec2TrustPolicy, "arn:aws:iam::123456789012:role/r", []string{testSubject}, "")
+	assert.ErrorContains(t, err, "is not an OIDC provider ARN")
+
+	_, _, err = BindTrustPolicy(ec2TrustPolicy, testProviderARN, nil, "")
+	assert.ErrorContains(t, err, "at least one service account subject")
+
+	_, _, err = BindTrustPolicy(ec2TrustPolicy, testProviderARN, []string{"system:serviceaccount:ns:*"}, "")
+	assert.ErrorContains(t, err, "must be a service account")
+
+	_, _, err = BindTrustPolicy("{", testProviderARN, []string{testSubject}, "")
+	assert.ErrorContains(t, err, "failed to parse trust policy")
+}
+
+func TestDecodeTrustPolicy(t *testing.T) {
+	document, err := DecodeTrustPolicy("%7B%22Version%22%3A%222012-10-17%22%7D")
+	require.NoError(t, err)
+	assert.Equal(t, `{"Version":"2012-10-17"}`, document)
+}
@@ -0,0 +1,219 @@
+// Package platform is a client for the ROSA Regional HCP Platform API. Requests are
+// signed with SigV4 for the execute-api service using the caller's AWS credentials.
+//
+// This package is part of the module's stable Go API; see pkg/deployer.
+package platform
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/openshift-online/regional-cli/pkg/dualstack"
+	"github.com/openshift-online/regional-cli/pkg/proxy"
+)
+
+const (
+	// basePath is the stage and version prefix of every Platform API resource
+	basePath = "/prod/v0"
+
+	// signingService is the SigV4 service name for API Gateway
+	signingService = "execute-api"
+
+	defaultTimeout = 30 * time.Second
+
+	// maxErrorBody bounds how much of an error response is included in errors
+	maxErrorBody = 1024
+)
+
+// apiRegion matches API Gateway hosts, capturing the region
+var apiRegion = regexp.MustCompile(`execute-api\.([a-z0-9-]+)\.(?:amazonaws\.com|api\.aws|api\.amazonwebservices\.com\.cn)`)
+
+// Cluster is a hosted cluster known to the Platform API
+type Cluster struct {
+	ID             string     `json:"id"`
+	Name           string     `json:"name,omitempty"`
+	State          string     `json:"state,omitempty"` // Such as installing, ready, or uninstalling
+	Region         string     `json:"region,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	OIDCIssuerURL  string     `json:"oidc_issuer_url"`
+	OIDCThumbprint string     `json:"oidc_thumbprint,omitempty"`
+	OIDCClientIDs  []string   `json:"oidc_client_ids,omitempty"`
+}
+
+// listClustersResponse is a page of GET /clusters
+type listClustersResponse struct {
+	Items     []Cluster `json:"items"`
+	NextToken string    `json:"next_token,omitempty"`
+}
+
+// StatusError is returned when the Platform API responds with a non-2xx status
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s returned status %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// Client calls the Platform API
+type Client struct {
+	baseURL    string
+	awsConfig  aws.Config
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// Option configures optional Client behavior
+type Option func(*Client)
+
+// WithDualStack sends requests to the API's dual-stack endpoint, dialing IPv6 first
+func WithDualStack(enabled bool) Option {
+	return func(c *Client) {
+		if enabled {
+			c.baseURL = dualstack.EndpointURL(c.baseURL)
+			transport := dualstack.Transport()
+			transport.Proxy = proxy.FromConfig(c.awsConfig)
+			c.httpClient.Transport = transport
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClient creates a Platform API client for the API at baseURL
+func NewClient(baseURL string, awsConfig aws.Config, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		awsConfig:  awsConfig,
+		httpClient: &http.Client{Timeout: defaultTimeout, Transport: proxy.Transport(awsConfig)},
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// BaseURL returns the API URL requests are sent to
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// ListClusters returns every cluster visible to the caller, which the Platform API
+// scopes to the AWS account of the credentials the requests are signed with
+func (c *Client) ListClusters(ctx context.Context) ([]Cluster, error) {
+	var clusters []Cluster
+	query := url.Values{}
+	for {
+		var page listClustersResponse
+		if err := c.get(ctx, "/clusters", query, &page); err != nil {
+			return nil, fmt.Errorf("failed to list clusters: %w", err)
+		}
+		clusters = append(clusters, page.Items...)
+		if page.NextToken == "" {
+			return clusters, nil
+		}
+		query.Set("next_token", page.NextToken)
+	}
+}
+
+// get sends a signed GET request for path and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// do sends a signed request for path with in, if set, as its JSON body and decodes
+// the JSON response into out, if set
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	if c.baseURL == "" {
+		return errors.New("platform API URL is not configured")
+	}
+
+	endpoint := c.baseURL + basePath + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if err := c.sign(ctx, req, body); err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{Method: req.Method, URL: endpoint, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// sign adds SigV4 headers for the API's region to a request with the given body
+func (c *Client) sign(ctx context.Context, req *http.Request, body []byte) error {
+	credentials, err := c.awsConfig.Credentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve AWS credentials for signing: %w", err)
+	}
+
+	payloadHash := fmt.Sprintf("%x", sha256.Sum256(body))
+	if err := v4.NewSigner().SignHTTP(ctx, credentials, req, payloadHash, signingService, c.signingRegion(), c.now()); err != nil {
+		return fmt.Errorf("failed to sign request: %w", err)
+	}
+	return nil
+}
+
+// signingRegion is the region in the API Gateway host, or the configured region for
+// custom domains
+func (c *Client) signingRegion() string {
+	if matches := apiRegion.FindStringSubmatch(c.baseURL); len(matches) > 1 {
+		return matches[1]
+	}
+	return c.awsConfig.Region
+}
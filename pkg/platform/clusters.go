@@ -0,0 +1,139 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Bounds on cluster names and worker node counts
+const (
+	MaxClusterNameLength = 54
+	MinReplicas          = 2
+	MaxReplicas          = 500
+)
+
+// Cluster states the Platform API reports. StateDeleted is never reported; WatchCluster
+// sets it once an uninstalling cluster is gone.
+const (
+	StateInstalling   = "installing"
+	StateReady        = "ready"
+	StateError        = "error"
+	StateFailed       = "failed"
+	StateUninstalling = "uninstalling"
+	StateDeleted      = "deleted"
+)
+
+// ErrClusterNotFound is returned by GetCluster when the Platform API does not know the cluster
+var ErrClusterNotFound = errors.New("cluster not found")
+
+// clusterName matches names that start with a letter, end with a letter or digit, and
+// hold only lowercase letters, digits, and hyphens
+var clusterName = regexp.MustCompile(`^[a-z]([-a-z0-9]*[a-z0-9])?$`)
+
+// CreateClusterRequest asks for a new hosted cluster
+type CreateClusterRequest struct {
+	Name     string   `json:"name"`
+	Region   string   `json:"region"`
+	NodePool NodePool `json:"node_pool"`
+
+	// OIDCIssuerURL and OIDCProviderARN name the cluster's OIDC issuer and the IAM
+	// provider already created for it; the Platform API picks an issuer when unset
+	OIDCIssuerURL   string `json:"oidc_issuer_url,omitempty"`
+	OIDCProviderARN string `json:"oidc_provider_arn,omitempty"`
+}
+
+// NodePool describes the worker nodes of a cluster
+type NodePool struct {
+	InstanceType string `json:"instance_type"`
+	Replicas     int    `json:"replicas"`
+}
+
+// ValidateClusterName checks that name can name a cluster
+func ValidateClusterName(name string) error {
+	if len(name) > MaxClusterNameLength {
+		return fmt.Errorf("cluster name %q is longer than %d characters", name, MaxClusterNameLength)
+	}
+	if !clusterName.MatchString(name) {
+		return fmt.Errorf("cluster name %q must start with a letter, end with a letter or digit, "+
+			"and contain only lowercase letters, digits, and hyphens", name)
+	}
+	return nil
+}
+
+// ValidateReplicas checks that a cluster's worker node count is within the allowed bounds
+func ValidateReplicas(replicas int) error {
+	if replicas < MinReplicas || replicas > MaxReplicas {
+		return fmt.Errorf("replicas must be between %d and %d, got %d", MinReplicas, MaxReplicas, replicas)
+	}
+	return nil
+}
+
+// CreateCluster asks the Platform API to create a cluster and returns it. Creation
+// continues after CreateCluster returns; the cluster's OIDC issuer may not be known yet.
+func (c *Client) CreateCluster(ctx context.Context, req CreateClusterRequest) (*Cluster, error) {
+	if err := ValidateClusterName(req.Name); err != nil {
+		return nil, err
+	}
+	if req.Region == "" {
+		return nil, errors.New("region is required")
+	}
+	if req.NodePool.InstanceType == "" {
+		return nil, errors.New("instance type is required")
+	}
+	if err := ValidateReplicas(req.NodePool.Replicas); err != nil {
+		return nil, err
+	}
+	if req.OIDCProviderARN != "" && req.OIDCIssuerURL == "" {
+		return nil, errors.New("an OIDC provider ARN requires the issuer URL")
+	}
+
+	var cluster Cluster
+	if err := c.do(ctx, http.MethodPost, "/clusters", nil, req, &cluster); err != nil {
+		return nil, fmt.Errorf("failed to create cluster %s: %w", req.Name, err)
+	}
+	if cluster.ID == "" {
+		return nil, fmt.Errorf("failed to create cluster %s: the Platform API returned no cluster ID", req.Name)
+	}
+	if cluster.Name == "" {
+		cluster.Name = req.Name
+	}
+	return &cluster, nil
+}
+
+// GetCluster returns the cluster with the given ID. A cluster the Platform API does not
+// know, or that the caller's account cannot see, is reported as ErrClusterNotFound.
+func (c *Client) GetCluster(ctx context.Context, clusterID string) (*Cluster, error) {
+	if clusterID == "" {
+		return nil, errors.New("cluster ID is required")
+	}
+
+	var cluster Cluster
+	err := c.get(ctx, "/clusters/"+url.PathEscape(clusterID), nil, &cluster)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrClusterNotFound, clusterID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster %s: %w", clusterID, err)
+	}
+	if cluster.ID == "" {
+		cluster.ID = clusterID
+	}
+	return &cluster, nil
+}
+
+// Failed reports whether the cluster is in an error state
+func (c Cluster) Failed() bool {
+	return strings.EqualFold(c.State, StateError) || strings.EqualFold(c.State, StateFailed)
+}
+
+// Settled reports whether the cluster is in a state it does not leave on its own: ready,
+// failed, or deleted
+func (c Cluster) Settled() bool {
+	return strings.EqualFold(c.State, StateReady) || strings.EqualFold(c.State, StateDeleted) || c.Failed()
+}
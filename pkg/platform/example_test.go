@@ -0,0 +1,58 @@
+package platform_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/openshift-online/regional-cli/pkg/platform"
+)
+
+func ExampleClient_ListClusters() {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Requests are signed with the config's credentials for the region in the URL
+	client := platform.NewClient("https://abc123.execute-api.us-east-1.amazonaws.com", cfg)
+	clusters, err := client.ListClusters(ctx)
+	var statusErr *platform.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusForbidden {
+		log.Fatal("the caller is not allowed to invoke the Platform API")
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, cluster := range clusters {
+		fmt.Println(cluster.ID, cluster.OIDCIssuerURL)
+	}
+}
+
+func ExampleClient_GrantAccess() {
+	ctx := context.Background()
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	duration := time.Hour
+	if err := platform.ValidateAccessDuration(duration); err != nil {
+		log.Fatal(err)
+	}
+	client := platform.NewClient("https://abc123.execute-api.us-east-1.amazonaws.com", cfg)
+	grant, err := client.GrantAccess(ctx, "c-1", platform.GrantAccessRequest{
+		PrincipalARN:    "arn:aws:iam::123456789012:role/oncall",
+		DurationSeconds: int64(duration.Seconds()),
+		Reason:          "INC-1234",
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(grant.ID, grant.ExpiresAt)
+}
@@ -0,0 +1,100 @@
+package platform
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Watch defaults
+const (
+	DefaultWatchInterval  = 30 * time.Second
+	DefaultWatchMaxErrors = 3
+)
+
+// WatchOptions configure WatchCluster
+type WatchOptions struct {
+	Interval  time.Duration // Time between polls; zero uses DefaultWatchInterval
+	MaxErrors int           // Consecutive failed polls tolerated; zero uses DefaultWatchMaxErrors
+
+	// OnChange, when set, is called with the first state read and on each change of
+	// state, with the state before it ("" for the first)
+	OnChange func(previous string, cluster Cluster)
+}
+
+// WatchCluster polls the cluster every opts.Interval until it settles, as Cluster.Settled
+// reports, and returns it in its final state. A cluster that disappears while
+// uninstalling is returned with StateDeleted; one that disappears otherwise is an
+// ErrClusterNotFound error. Failed polls are retried unless the Platform API refused
+// the request, until opts.MaxErrors fail in a row. When ctx is done, the cluster as last
+// read is returned with ctx's error.
+func (c *Client) WatchCluster(ctx context.Context, clusterID string, opts WatchOptions) (*Cluster, error) {
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	maxErrors := opts.MaxErrors
+	if maxErrors <= 0 {
+		maxErrors = DefaultWatchMaxErrors
+	}
+
+	var last *Cluster
+	failures := 0
+	for {
+		cluster, err := c.GetCluster(ctx, clusterID)
+		if errors.Is(err, ErrClusterNotFound) && last != nil && strings.EqualFold(last.State, StateUninstalling) {
+			deleted := *last
+			deleted.State = StateDeleted
+			cluster, err = &deleted, nil
+		}
+		switch {
+		case err == nil:
+			failures = 0
+			if last == nil || !strings.EqualFold(cluster.State, last.State) {
+				previous := ""
+				if last != nil {
+					previous = last.State
+				}
+				if opts.OnChange != nil {
+					opts.OnChange(previous, *cluster)
+				}
+			}
+			last = cluster
+			if cluster.Settled() {
+				return cluster, nil
+			}
+		case ctx.Err() != nil:
+			return last, ctx.Err()
+		case !retryable(err):
+			return last, err
+		default:
+			failures++
+			if failures >= maxErrors {
+				return last, err
+			}
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// retryable reports whether a failed poll may succeed when repeated: the Platform API
+// refusing the request, other than throttling it, is final
+func retryable(err error) bool {
+	if errors.Is(err, ErrClusterNotFound) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	return true
+}
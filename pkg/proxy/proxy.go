@@ -0,0 +1,161 @@
+// Package proxy applies HTTP(S) proxy settings to every client rosactl builds. The
+// proxy comes from the HTTPS_PROXY, HTTP_PROXY, and NO_PROXY environment variables
+// or, when one is given explicitly, from --proxy. It is carried in the aws.Config's
+// HTTP client so clients built from the config use the same proxy as the AWS SDK.
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// Func returns the proxy for a request, or nil to connect directly
+type Func func(*http.Request) (*url.URL, error)
+
+// schemes are the proxy URL schemes net/http supports
+var schemes = map[string]bool{"http": true, "https": true, "socks5": true}
+
+// Parse validates a proxy URL. A bare host:port is treated as an http:// proxy, as
+// curl does.
+func Parse(raw string) (*url.URL, error) {
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if !schemes[u.Scheme] {
+		return nil, fmt.Errorf("invalid proxy URL %q: scheme must be http, https, or socks5", u.Redacted())
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", u.Redacted())
+	}
+	return u, nil
+}
+
+// New returns the proxy function for proxyURL. With a nil proxyURL the proxy comes
+// from the environment; otherwise every request goes through proxyURL except those
+// to hosts excluded by NO_PROXY.
+func New(proxyURL *url.URL) Func {
+	if proxyURL == nil {
+		return http.ProxyFromEnvironment
+	}
+	noProxy := getenv("NO_PROXY", "no_proxy")
+	return func(req *http.Request) (*url.URL, error) {
+		if bypass(req.URL, noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}
+}
+
+// FromConfig returns the proxy function of the config's HTTP client, falling back
+// to the environment when the client does not expose one
+func FromConfig(cfg aws.Config) Func {
+	if client, ok := cfg.HTTPClient.(interface{ GetTransport() *http.Transport }); ok {
+		if transport := client.GetTransport(); transport != nil && transport.Proxy != nil {
+			return transport.Proxy
+		}
+	}
+	return http.ProxyFromEnvironment
+}
+
+// Transport returns an HTTP transport with the default transport's settings that
+// uses the config's proxy
+func Transport(cfg aws.Config) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = FromConfig(cfg)
+	return transport
+}
+
+// ForURL returns the proxy proxyFunc selects for target, or nil for a direct
+// connection
+func ForURL(proxyFunc Func, target string) (*url.URL, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", target, err)
+	}
+	return proxyFunc(&http.Request{Method: http.MethodGet, URL: u, Host: u.Host})
+}
+
+// bypass reports whether target is excluded from proxying by noProxy, a comma- or
+// space-separated list of hosts, domains, IP addresses, and CIDR ranges. Like
+// net/http, loopback addresses are never proxied.
+func bypass(target *url.URL, noProxy string) bool {
+	host := strings.ToLower(target.Hostname())
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+
+	for _, entry := range strings.FieldsFunc(noProxy, func(r rune) bool { return r == ',' || r == ' ' }) {
+		entry = strings.ToLower(entry)
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, port, err := net.SplitHostPort(entry); err == nil {
+			if port != targetPort(target) {
+				continue
+			}
+			entry = h
+		}
+		if entryIP := net.ParseIP(entry); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return true
+			}
+			continue
+		}
+		// "example.com" matches the domain and its subdomains; ".example.com" and
+		// "*.example.com" match only subdomains
+		if strings.HasPrefix(entry, "*.") {
+			entry = entry[1:]
+		}
+		if strings.HasPrefix(entry, ".") {
+			if strings.HasSuffix(host, entry) {
+				return true
+			}
+			continue
+		}
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// targetPort returns the URL's port, defaulting by scheme
+func targetPort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	if u.Scheme == "http" {
+		return "80"
+	}
+	return "443"
+}
+
+// getenv returns the first non-empty environment variable of names
+func getenv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
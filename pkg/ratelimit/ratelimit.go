@@ -0,0 +1,214 @@
+// Package ratelimit throttles AWS API calls on the client side with a token bucket per
+// service, so bulk and multi-region operations stay under low service quotas such as
+// IAM's instead of failing once the SDK's retries are exhausted.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// middlewareID identifies the rate limiting middleware in the SDK's stack
+const middlewareID = "RateLimit"
+
+// Limit is the sustained request rate and burst allowed for a service. A limit with
+// no rate disables limiting for the service.
+type Limit struct {
+	RPS   float64 `yaml:"rps" json:"rps"`
+	Burst int     `yaml:"burst,omitempty" json:"burst,omitempty"`
+}
+
+func (l Limit) String() string {
+	if l.RPS <= 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%g/s, burst %d", l.RPS, l.burst())
+}
+
+// burst is the configured burst, defaulting to one second's worth of requests
+func (l Limit) burst() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return int(math.Max(1, math.Ceil(l.RPS)))
+}
+
+// ParseLimit parses a limit written as RPS or RPS:BURST, such as 5 or 5:10
+func ParseLimit(raw string) (Limit, error) {
+	rps, burst, hasBurst := strings.Cut(raw, ":")
+
+	var limit Limit
+	var err error
+	if limit.RPS, err = strconv.ParseFloat(rps, 64); err != nil || limit.RPS < 0 {
+		return Limit{}, fmt.Errorf("invalid rate %q: expected a non-negative number of requests per second", rps)
+	}
+	if hasBurst {
+		if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst < 1 {
+			return Limit{}, fmt.Errorf("invalid burst %q: expected a positive integer", burst)
+		}
+	}
+	return limit, nil
+}
+
+// Limits maps services, named by ServiceKey, to their limits
+type Limits map[string]Limit
+
+// DefaultLimits keeps IAM and the Lambda control plane, whose quotas are lowest, well
+// under their throttling thresholds. Other services are not limited.
+func DefaultLimits() Limits {
+	return Limits{
+		"iam":    {RPS: 5, Burst: 10},
+		"lambda": {RPS: 10, Burst: 15},
+	}
+}
+
+// Merge returns l with the limits in overrides replacing l's for the same service
+func (l Limits) Merge(overrides Limits) Limits {
+	merged := make(Limits, len(l)+len(overrides))
+	for service, limit := range l {
+		merged[ServiceKey(service)] = limit
+	}
+	for service, limit := range overrides {
+		merged[ServiceKey(service)] = limit
+	}
+	return merged
+}
+
+// Services returns the services with limits, sorted
+func (l Limits) Services() []string {
+	services := make([]string, 0, len(l))
+	for service := range l {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	return services
+}
+
+// ServiceKey normalizes an SDK service ID such as "CloudWatch Logs" to the key used in
+// Limits, "cloudwatchlogs"
+func ServiceKey(serviceID string) string {
+	return strings.ToLower(strings.ReplaceAll(serviceID, " ", ""))
+}
+
+// Bucket is a token bucket. It is safe for concurrent use.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64 // Maximum tokens
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewBucket returns a full bucket for limit
+func NewBucket(limit Limit) *Bucket {
+	burst := float64(limit.burst())
+	return &Bucket{
+		rate:   limit.RPS,
+		burst:  burst,
+		tokens: burst,
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it
+func (b *Bucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// release returns a token taken by a caller that gave up waiting
+func (b *Bucket) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (b *Bucket) Wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.release()
+		return ctx.Err()
+	}
+}
+
+// Limiter holds a bucket per limited service. Clients sharing a Limiter, such as every
+// client built from one aws.Config, share its buckets, so concurrent callers together
+// stay under each limit.
+type Limiter struct {
+	buckets map[string]*Bucket // Read-only after construction
+}
+
+// New creates a limiter for limits; services without a positive rate are not limited
+func New(limits Limits) *Limiter {
+	l := &Limiter{buckets: make(map[string]*Bucket)}
+	for service, limit := range limits {
+		if limit.RPS > 0 {
+			l.buckets[ServiceKey(service)] = NewBucket(limit)
+		}
+	}
+	return l
+}
+
+// Wait blocks until a request to the service, named by its SDK service ID, may be sent
+func (l *Limiter) Wait(ctx context.Context, serviceID string) error {
+	bucket, ok := l.buckets[ServiceKey(serviceID)]
+	if !ok {
+		return nil
+	}
+	return bucket.Wait(ctx)
+}
+
+// AddMiddleware adds the limiter to an SDK client's middleware stack; append it to
+// aws.Config.APIOptions. Every attempt, including retries, waits for a token.
+func (l *Limiter) AddMiddleware(stack *middleware.Stack) error {
+	if len(l.buckets) == 0 {
+		return nil
+	}
+
+	mw := middleware.FinalizeMiddlewareFunc(middlewareID, func(ctx context.Context, in middleware.FinalizeInput,
+		next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+		if err := l.Wait(ctx, awsmiddleware.GetServiceID(ctx)); err != nil {
+			return middleware.FinalizeOutput{}, middleware.Metadata{}, fmt.Errorf("rate limit wait: %w", err)
+		}
+		return next.HandleFinalize(ctx, in)
+	})
+
+	// After retry so each attempt is limited; stacks without a retry step limit the
+	// request once
+	if _, ok := stack.Finalize.Get("Retry"); ok {
+		return stack.Finalize.Insert(mw, "Retry", middleware.After)
+	}
+	return stack.Finalize.Add(mw, middleware.After)
+}
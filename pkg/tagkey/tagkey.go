@@ -0,0 +1,173 @@
+// Package tagkey rewrites the keys of the tags rosactl and the OIDC provisioner set,
+// such as rosa:managed and rosa:component, for AWS Organizations tag policies that
+// require a different prefix or capitalization. Keys outside the rosa: namespace,
+// such as user tags, are never rewritten.
+package tagkey
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultPrefix namespaces the tags rosactl sets
+	DefaultPrefix = "rosa:"
+
+	// EnvVar passes the format to the provisioner, which applies it to the tags it sets
+	// on OIDC providers
+	EnvVar = "ROSA_TAG_KEY_FORMAT"
+)
+
+// Case is the capitalization applied to rewritten keys
+type Case string
+
+const (
+	CaseAsIs  Case = ""      // Keep the key as written, such as rosa:cluster-id
+	CaseLower Case = "lower" // rosa:cluster-id
+	CaseUpper Case = "upper" // ROSA:CLUSTER-ID
+	CaseTitle Case = "title" // Rosa:Cluster-Id
+)
+
+// Cases lists the supported cases for help text
+var Cases = []Case{CaseLower, CaseUpper, CaseTitle}
+
+// ParseCase parses a case name; the empty string keeps keys as written
+func ParseCase(value string) (Case, error) {
+	c := Case(strings.ToLower(value))
+	switch c {
+	case CaseAsIs, CaseLower, CaseUpper, CaseTitle:
+		return c, nil
+	}
+	return "", fmt.Errorf("invalid tag key case %q (expected lower, upper, or title)", value)
+}
+
+// Format describes how rosactl's tag keys are written. The zero Format writes them
+// as rosa:name.
+type Format struct {
+	Prefix string // Replaces DefaultPrefix; empty keeps it
+	Case   Case   // Applied to the whole key after the prefix is replaced
+}
+
+// Validate checks that the format produces keys AWS accepts
+func (f Format) Validate() error {
+	if _, err := ParseCase(string(f.Case)); err != nil {
+		return err
+	}
+	if strings.HasPrefix(strings.ToLower(f.Prefix), "aws:") {
+		return fmt.Errorf("tag key prefix %q uses the prefix reserved by AWS", f.Prefix)
+	}
+	for _, r := range f.Prefix {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.IsSpace(r) && !strings.ContainsRune("_.:/=+-@", r) {
+			return fmt.Errorf("tag key prefix %q contains %q, which AWS does not allow in tag keys", f.Prefix, r)
+		}
+	}
+	return nil
+}
+
+// IsDefault reports whether the format leaves keys unchanged
+func (f Format) IsDefault() bool {
+	return (f.Prefix == "" || f.Prefix == DefaultPrefix) && f.Case == CaseAsIs
+}
+
+// Key returns key as the format writes it. Keys without DefaultPrefix are returned
+// unchanged.
+func (f Format) Key(key string) string {
+	name, ok := strings.CutPrefix(key, DefaultPrefix)
+	if !ok {
+		return key
+	}
+	prefix := f.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return applyCase(prefix+name, f.Case)
+}
+
+// Lookup returns the value of the tag rosactl wrote as key. The formatted key is
+// preferred, but a key matching it or the default key in any capitalization is also
+// accepted, so resources tagged before a case-only policy change are still found.
+func (f Format) Lookup(tags map[string]string, key string) (string, bool) {
+	formatted := f.Key(key)
+	if value, ok := tags[formatted]; ok {
+		return value, true
+	}
+	for k, v := range tags {
+		if strings.EqualFold(k, formatted) || strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// Matches reports whether actual is key written in this format or, ignoring case, in
+// the default format
+func (f Format) Matches(actual, key string) bool {
+	return strings.EqualFold(actual, f.Key(key)) || strings.EqualFold(actual, key)
+}
+
+// HasPrefix reports whether key is in rosactl's namespace, in this format or the default
+func (f Format) HasPrefix(key string) bool {
+	lower := strings.ToLower(key)
+	if strings.HasPrefix(lower, DefaultPrefix) {
+		return true
+	}
+	return f.Prefix != "" && strings.HasPrefix(lower, strings.ToLower(f.Prefix))
+}
+
+// String encodes the format as prefix=<prefix>,case=<case>, the value of EnvVar
+func (f Format) String() string {
+	return fmt.Sprintf("prefix=%s,case=%s", f.Prefix, f.Case)
+}
+
+// Parse decodes a format encoded by String
+func Parse(value string) (Format, error) {
+	var f Format
+	if value == "" {
+		return f, nil
+	}
+	for _, field := range strings.Split(value, ",") {
+		name, v, ok := strings.Cut(field, "=")
+		if !ok {
+			return Format{}, fmt.Errorf("invalid tag key format %q: expected prefix=<prefix>,case=<case>", value)
+		}
+		switch name {
+		case "prefix":
+			f.Prefix = v
+		case "case":
+			f.Case = Case(v)
+		default:
+			return Format{}, fmt.Errorf("invalid tag key format %q: unknown field %q", value, name)
+		}
+	}
+	if err := f.Validate(); err != nil {
+		return Format{}, err
+	}
+	return f, nil
+}
+
+// applyCase capitalizes key; title case capitalizes the first letter of each word,
+// where words are separated by any character other than a letter or number
+func applyCase(key string, c Case) string {
+	switch c {
+	case CaseLower:
+		return strings.ToLower(key)
+	case CaseUpper:
+		return strings.ToUpper(key)
+	case CaseTitle:
+		runes := []rune(strings.ToLower(key))
+		start := true
+		for i, r := range runes {
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				if start {
+					runes[i] = unicode.ToUpper(r)
+				}
+				start = false
+			} else {
+				start = true
+			}
+		}
+		return string(runes)
+	}
+	return key
+}
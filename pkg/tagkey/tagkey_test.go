@@ -0,0 +1,77 @@
+package tagkey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat_Key(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   Format
+		key      string
+		expected string
+	}{
+		{"default", Format{}, "rosa:cluster-id", "rosa:cluster-id"},
+		{"upper", Format{Case: CaseUpper}, "rosa:cluster-id", "ROSA:CLUSTER-ID"},
+		{"title", Format{Case: CaseTitle}, "rosa:cluster-id", "Rosa:Cluster-Id"},
+		{"prefix", Format{Prefix: "org/rosa-"}, "rosa:managed", "org/rosa-managed"},
+		{"prefix and case", Format{Prefix: "acme:", Case: CaseTitle}, "rosa:cli-version", "Acme:Cli-Version"},
+		{"user tag unchanged", Format{Case: CaseUpper}, "team", "team"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.format.Key(tt.key))
+		})
+	}
+}
+
+func TestFormat_Lookup(t *testing.T) {
+	format := Format{Case: CaseTitle}
+
+	value, ok := format.Lookup(map[string]string{"Rosa:Managed": "true"}, "rosa:managed")
+	assert.True(t, ok)
+	assert.Equal(t, "true", value)
+
+	// Keys written before the format changed are still found
+	value, ok = format.Lookup(map[string]string{"ROSA:MANAGED": "true"}, "rosa:managed")
+	assert.True(t, ok)
+	assert.Equal(t, "true", value)
+
+	_, ok = format.Lookup(map[string]string{"team": "sre"}, "rosa:managed")
+	assert.False(t, ok)
+}
+
+func TestFormat_HasPrefix(t *testing.T) {
+	format := Format{Prefix: "acme:"}
+	assert.True(t, format.HasPrefix("rosa:managed"))
+	assert.True(t, format.HasPrefix("ROSA:MANAGED"))
+	assert.True(t, format.HasPrefix("Acme:Managed"))
+	assert.False(t, format.HasPrefix("team"))
+}
+
+func TestFormat_Validate(t *testing.T) {
+	assert.NoError(t, Format{Prefix: "acme/rosa-", Case: CaseLower}.Validate())
+	assert.Error(t, Format{Case: "camel"}.Validate())
+	assert.Error(t, Format{Prefix: "aws:rosa:"}.Validate())
+	assert.Error(t, Format{Prefix: "rosa#"}.Validate())
+}
+
+func TestParse(t *testing.T) {
+	format := Format{Prefix: "acme:", Case: CaseTitle}
+	parsed, err := Parse(format.String())
+	require.NoError(t, err)
+	assert.Equal(t, format, parsed)
+
+	parsed, err = Parse("")
+	require.NoError(t, err)
+	assert.True(t, parsed.IsDefault())
+
+	_, err = Parse("case=camel")
+	assert.Error(t, err)
+	_, err = Parse("upper")
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// middlewareID identifies the tracing middleware in the SDK's stack
+const middlewareID = "OpenTelemetry"
+
+// Attributes recorded on AWS call spans beyond the semantic conventions
+const (
+	AttemptsKey  = attribute.Key("aws.attempts")
+	ErrorCodeKey = attribute.Key("aws.error.code")
+)
+
+// AWSMiddleware returns an SDK stack option that records a client span, named
+// Service.Operation, for each AWS API call; append it to aws.Config.APIOptions. The
+// span covers every attempt, including retries and rate limit waits.
+func AWSMiddleware(tp trace.TracerProvider) func(*middleware.Stack) error {
+	tracer := tp.Tracer(TracerName)
+
+	return func(stack *middleware.Stack) error {
+		mw := middleware.InitializeMiddlewareFunc(middlewareID, func(ctx context.Context, in middleware.InitializeInput,
+			next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			service := awsmiddleware.GetServiceID(ctx)
+			operation := awsmiddleware.GetOperationName(ctx)
+
+			ctx, span := tracer.Start(ctx, service+"."+operation,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.RPCSystemKey.String("aws-api"),
+					semconv.RPCService(service),
+					semconv.RPCMethod(operation),
+					semconv.CloudRegion(awsmiddleware.GetRegion(ctx)),
+				))
+			defer span.End()
+
+			out, metadata, err := next.HandleInitialize(ctx, in)
+
+			if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+				span.SetAttributes(semconv.AWSRequestID(requestID))
+			}
+			if resp, ok := awsmiddleware.GetRawResponse(metadata).(*smithyhttp.Response); ok {
+				span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+			}
+			if attempts, ok := retry.GetAttemptResults(metadata); ok {
+				span.SetAttributes(AttemptsKey.Int(len(attempts.Results)))
+			}
+			if err != nil {
+				var apiErr smithy.APIError
+				if errors.As(err, &apiErr) {
+					span.SetAttributes(ErrorCodeKey.String(apiErr.ErrorCode()))
+				}
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return out, metadata, err
+		})
+
+		// After the service metadata middleware, which names the service and operation
+		return stack.Initialize.Add(mw, middleware.After)
+	}
+}
@@ -0,0 +1,141 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Exit classes of a command run
+const (
+	ExitSuccess   = "success"
+	ExitCancelled = "cancelled" // Interrupted, such as with Ctrl-C
+	ExitTimeout   = "timeout"   // A deadline passed
+	ExitAWSError  = "aws_error" // An AWS API call failed
+	ExitError     = "error"     // Any other failure
+)
+
+// Attributes recorded on command metrics
+const (
+	CommandKey   = attribute.Key("rosactl.command")
+	ExitClassKey = attribute.Key("rosactl.exit_class")
+)
+
+// MetricsProtocols are the OTLP protocols metrics can be exported with
+var MetricsProtocols = []string{"http/protobuf", "grpc"}
+
+// CommandRun describes one invocation of a command
+type CommandRun struct {
+	Command   string // Full command path, such as "rosactl setup-account"
+	Duration  time.Duration
+	ExitClass string
+	Region    string // Empty when no region was configured
+}
+
+// CommandMetrics exports a duration histogram of command runs, labeled with the
+// command, exit class, and region; the CLI version is the service.version resource
+// attribute
+type CommandMetrics struct {
+	provider *sdkmetric.MeterProvider
+	duration metric.Float64Histogram
+}
+
+// ValidateMetricsEndpoint checks an OTLP collector URL and protocol before use, since
+// the exporters silently fall back to localhost for an invalid URL
+func ValidateMetricsEndpoint(endpoint, protocol string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid metrics endpoint %q: expected an http:// or https:// URL", endpoint)
+	}
+	for _, p := range MetricsProtocols {
+		if protocol == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported metrics protocol %q (expected http/protobuf or grpc)", protocol)
+}
+
+// NewCommandMetrics creates metrics exported to the OTLP collector at endpoint, the
+// collector's base URL such as http://collector:4318. For http/protobuf, /v1/metrics
+// is appended when the URL has no path. Callers must Shutdown the metrics before
+// exiting so recorded runs are exported.
+func NewCommandMetrics(ctx context.Context, endpoint, protocol, serviceVersion string) (*CommandMetrics, error) {
+	if err := ValidateMetricsEndpoint(endpoint, protocol); err != nil {
+		return nil, err
+	}
+
+	var exporter sdkmetric.Exporter
+	var err error
+	if protocol == "grpc" {
+		exporter, err = otlpmetricgrpc.New(ctx, otlpmetricgrpc.WithEndpointURL(endpoint))
+	} else {
+		u, _ := url.Parse(endpoint)
+		if u.Path == "" || u.Path == "/" {
+			u.Path = "/v1/metrics"
+		}
+		exporter, err = otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(u.String()))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+	}
+
+	res, err := newResource(ctx, serviceVersion)
+	if err != nil {
+		return nil, err
+	}
+	return newCommandMetrics(sdkmetric.NewPeriodicReader(exporter), res)
+}
+
+// newCommandMetrics creates metrics collected by reader
+func newCommandMetrics(reader sdkmetric.Reader, res *resource.Resource) (*CommandMetrics, error) {
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithResource(res))
+	duration, err := provider.Meter(TracerName).Float64Histogram("rosactl.command.duration",
+		metric.WithDescription("Duration of rosactl command runs"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create command duration histogram: %w", err)
+	}
+	return &CommandMetrics{provider: provider, duration: duration}, nil
+}
+
+// Record records a command run
+func (m *CommandMetrics) Record(ctx context.Context, run CommandRun) {
+	attrs := []attribute.KeyValue{CommandKey.String(run.Command), ExitClassKey.String(run.ExitClass)}
+	if run.Region != "" {
+		attrs = append(attrs, semconv.CloudRegion(run.Region))
+	}
+	m.duration.Record(ctx, run.Duration.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// Shutdown exports recorded runs and stops the exporter
+func (m *CommandMetrics) Shutdown(ctx context.Context) error {
+	return m.provider.Shutdown(ctx)
+}
+
+// ExitClass classifies the error a command returned
+func ExitClass(err error) string {
+	var opErr *smithy.OperationError
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.Is(err, context.Canceled):
+		return ExitCancelled
+	case errors.Is(err, context.DeadlineExceeded):
+		return ExitTimeout
+	case errors.As(err, &opErr):
+		return ExitAWSError
+	default:
+		return ExitError
+	}
+}
@@ -0,0 +1,76 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestValidateMetricsEndpoint(t *testing.T) {
+	assert.NoError(t, ValidateMetricsEndpoint("http://collector:4318", "http/protobuf"))
+	assert.NoError(t, ValidateMetricsEndpoint("https://collector.example.com/otlp/v1/metrics", "http/protobuf"))
+	assert.NoError(t, ValidateMetricsEndpoint("http://collector:4317", "grpc"))
+
+	assert.ErrorContains(t, ValidateMetricsEndpoint("collector:4318", "http/protobuf"), "expected an http:// or https:// URL")
+	assert.ErrorContains(t, ValidateMetricsEndpoint("http://", "http/protobuf"), "invalid metrics endpoint")
+	assert.ErrorContains(t, ValidateMetricsEndpoint("http://collector:4318", "http/json"), `unsupported metrics protocol "http/json"`)
+}
+
+func TestCommandMetrics_Record(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	m, err := newCommandMetrics(reader, resource.Empty())
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	m.Record(ctx, CommandRun{Command: "rosactl setup-account", Duration: 1500 * time.Millisecond, ExitClass: ExitSuccess, Region: "us-east-1"})
+	m.Record(ctx, CommandRun{Command: "rosactl setup-account", Duration: 500 * time.Millisecond, ExitClass: ExitSuccess, Region: "us-east-1"})
+	m.Record(ctx, CommandRun{Command: "rosactl init", Duration: time.Second, ExitClass: ExitAWSError})
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	require.Len(t, rm.ScopeMetrics[0].Metrics, 1)
+
+	duration := rm.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "rosactl.command.duration", duration.Name)
+	assert.Equal(t, "s", duration.Unit)
+
+	histogram, ok := duration.Data.(metricdata.Histogram[float64])
+	require.True(t, ok)
+	runs := make(map[string]metricdata.HistogramDataPoint[float64])
+	for _, point := range histogram.DataPoints {
+		command, _ := point.Attributes.Value(CommandKey)
+		runs[command.AsString()] = point
+	}
+	require.Len(t, runs, 2)
+
+	setup := runs["rosactl setup-account"]
+	assert.Equal(t, uint64(2), setup.Count)
+	assert.Equal(t, 2.0, setup.Sum)
+	region, _ := setup.Attributes.Value("cloud.region")
+	assert.Equal(t, "us-east-1", region.AsString())
+
+	initRun := runs["rosactl init"]
+	exitClass, _ := initRun.Attributes.Value(ExitClassKey)
+	assert.Equal(t, ExitAWSError, exitClass.AsString())
+	assert.False(t, initRun.Attributes.HasValue("cloud.region"), "an unset region is not recorded")
+}
+
+func TestExitClass(t *testing.T) {
+	awsErr := &smithy.OperationError{ServiceID: "STS", OperationName: "GetCallerIdentity", Err: errors.New("denied")}
+
+	assert.Equal(t, ExitSuccess, ExitClass(nil))
+	assert.Equal(t, ExitCancelled, ExitClass(fmt.Errorf("deploy: %w", context.Canceled)))
+	assert.Equal(t, ExitTimeout, ExitClass(context.DeadlineExceeded))
+	assert.Equal(t, ExitAWSError, ExitClass(fmt.Errorf("failed to get caller identity: %w", awsErr)))
+	assert.Equal(t, ExitError, ExitClass(errors.New("invalid flag")))
+}
@@ -0,0 +1,118 @@
+// Package telemetry exports OpenTelemetry traces of deployments and metrics of command
+// runs over OTLP, and records a span for each AWS API call, so provisioning pipelines
+// can see where time goes across many accounts.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// TracerName is the instrumentation scope of rosactl's spans
+const TracerName = "github.com/openshift-online/regional-cli"
+
+// ServiceName is the service.name of exported traces unless OTEL_SERVICE_NAME is set
+const ServiceName = "rosactl"
+
+// Enabled reports whether the environment asks for traces to be exported: an OTLP
+// endpoint is set, the SDK is not disabled, and the traces exporter is otlp
+func Enabled() bool {
+	return enabled(os.Getenv)
+}
+
+func enabled(getenv func(string) string) bool {
+	if strings.EqualFold(getenv("OTEL_SDK_DISABLED"), "true") {
+		return false
+	}
+	if exporter := getenv("OTEL_TRACES_EXPORTER"); exporter != "" && exporter != "otlp" {
+		return false
+	}
+	return getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// protocol returns the OTLP protocol for traces, http/protobuf unless grpc is set
+func protocol(getenv func(string) string) (string, error) {
+	p := getenv("OTEL_EXPORTER_OTLP_TRACES_PROTOCOL")
+	if p == "" {
+		p = getenv("OTEL_EXPORTER_OTLP_PROTOCOL")
+	}
+	switch p {
+	case "", "http/protobuf":
+		return "http/protobuf", nil
+	case "grpc":
+		return p, nil
+	default:
+		return "", fmt.Errorf("unsupported OTLP protocol %q (expected grpc or http/protobuf)", p)
+	}
+}
+
+// Setup creates a tracer provider that batches spans to the OTLP endpoint in the
+// environment. It returns nil when tracing is not enabled. Callers must Shutdown the
+// provider before exiting so buffered spans are exported.
+func Setup(ctx context.Context, serviceVersion string) (*sdktrace.TracerProvider, error) {
+	if !Enabled() {
+		return nil, nil
+	}
+
+	p, err := protocol(os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+
+	// The exporters read the endpoint, headers, TLS, and timeout settings from the
+	// environment themselves
+	var exporter sdktrace.SpanExporter
+	if p == "grpc" {
+		exporter, err = otlptracegrpc.New(ctx)
+	} else {
+		exporter, err = otlptracehttp.New(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := newResource(ctx, serviceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	), nil
+}
+
+// newResource describes rosactl as the source of exported telemetry.
+// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults.
+func newResource(ctx context.Context, serviceVersion string) (*resource.Resource, error) {
+	res, err := resource.New(ctx,
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName(ServiceName), semconv.ServiceVersion(serviceVersion)),
+		resource.WithTelemetrySDK(),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the telemetry resource: %w", err)
+	}
+	return res, nil
+}
+
+// ContextWithEnvParent returns ctx carrying the remote parent span in the TRACEPARENT
+// and TRACESTATE environment variables, so a pipeline that runs rosactl per account
+// can nest each run under its own span
+func ContextWithEnvParent(ctx context.Context) context.Context {
+	carrier := propagation.MapCarrier{
+		"traceparent": os.Getenv("TRACEPARENT"),
+		"tracestate":  os.Getenv("TRACESTATE"),
+	}
+	return propagation.TraceContext{}.Extract(ctx, carrier)
+}
@@ -0,0 +1,75 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// env returns a getenv func reading vars
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		vars     map[string]string
+		expected bool
+	}{
+		{name: "no endpoint", vars: nil, expected: false},
+		{name: "endpoint", vars: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318"}, expected: true},
+		{name: "traces endpoint", vars: map[string]string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "http://localhost:4318/v1/traces"}, expected: true},
+		{name: "otlp exporter", vars: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318", "OTEL_TRACES_EXPORTER": "otlp"}, expected: true},
+		{name: "other exporter", vars: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318", "OTEL_TRACES_EXPORTER": "none"}, expected: false},
+		{name: "SDK disabled", vars: map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "http://localhost:4318", "OTEL_SDK_DISABLED": "TRUE"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, enabled(env(tt.vars)))
+		})
+	}
+}
+
+func TestProtocol(t *testing.T) {
+	p, err := protocol(env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "http/protobuf", p)
+
+	p, err = protocol(env(map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc"}))
+	require.NoError(t, err)
+	assert.Equal(t, "grpc", p)
+
+	p, err = protocol(env(map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "grpc", "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL": "http/protobuf"}))
+	require.NoError(t, err)
+	assert.Equal(t, "http/protobuf", p, "the traces setting wins")
+
+	_, err = protocol(env(map[string]string{"OTEL_EXPORTER_OTLP_PROTOCOL": "http/json"}))
+	assert.ErrorContains(t, err, `unsupported OTLP protocol "http/json"`)
+}
+
+func TestSetup_Disabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	tp, err := Setup(context.Background(), "v1.0.0")
+	require.NoError(t, err)
+	assert.Nil(t, tp)
+}
+
+func TestContextWithEnvParent(t *testing.T) {
+	t.Setenv("TRACEPARENT", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	t.Setenv("TRACESTATE", "")
+
+	parent := trace.SpanContextFromContext(ContextWithEnvParent(context.Background()))
+	assert.True(t, parent.IsRemote())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", parent.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", parent.SpanID().String())
+
+	t.Setenv("TRACEPARENT", "")
+	assert.False(t, trace.SpanContextFromContext(ContextWithEnvParent(context.Background())).IsValid())
+}
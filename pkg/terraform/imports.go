@@ -0,0 +1,109 @@
+// Package terraform renders the terraform import commands and import blocks that
+// hand existing AWS resources over to a Terraform configuration.
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Import is an existing resource to bring under Terraform management
+type Import struct {
+	Address string // Resource address, such as aws_iam_role.provisioner
+	ID      string // Import ID the provider expects for the resource type
+}
+
+// Imports collects imports, giving each a unique address
+type Imports struct {
+	list []Import
+	used map[string]bool
+}
+
+// Add records an import of the resource id of resourceType, such as
+// aws_lambda_function, named after nameParts. A name already in use for the type
+// gets a numeric suffix.
+func (i *Imports) Add(resourceType, id string, nameParts ...string) {
+	if i.used == nil {
+		i.used = make(map[string]bool)
+	}
+	base := resourceType + "." + ResourceName(nameParts...)
+	address := base
+	for n := 2; i.used[address]; n++ {
+		address = fmt.Sprintf("%s_%d", base, n)
+	}
+	i.used[address] = true
+	i.list = append(i.list, Import{Address: address, ID: id})
+}
+
+// List returns the imports in the order they were added
+func (i *Imports) List() []Import {
+	return i.list
+}
+
+// ResourceName joins parts into a valid Terraform resource name: lowercase letters,
+// digits, and underscores, starting with a letter
+func ResourceName(parts ...string) string {
+	var b strings.Builder
+	for _, part := range parts {
+		for _, r := range strings.ToLower(part) {
+			switch {
+			case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+				b.WriteRune(r)
+			case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+				b.WriteByte('_')
+			}
+		}
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+			b.WriteByte('_')
+		}
+	}
+
+	name := strings.TrimSuffix(b.String(), "_")
+	switch {
+	case name == "":
+		return "resource"
+	case name[0] >= '0' && name[0] <= '9':
+		return "r_" + name
+	}
+	return name
+}
+
+// WriteCommands writes a terraform import command for each import, quoted for a
+// POSIX shell
+func WriteCommands(w io.Writer, imports []Import) error {
+	for _, imp := range imports {
+		if _, err := fmt.Fprintf(w, "terraform import %s %s\n", shellQuote(imp.Address), shellQuote(imp.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteImportBlocks writes an import block for each import, for Terraform 1.5 and
+// later to import on the next plan and apply
+func WriteImportBlocks(w io.Writer, imports []Import) error {
+	for n, imp := range imports {
+		if n > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "import {\n  to = %s\n  id = %s\n}\n", imp.Address, hclString(imp.ID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shellQuote single-quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// hclString renders s as an HCL string literal, escaping template sequences so
+// the ID is used literally
+func hclString(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "${", "$${", "%{", "%%{")
+	return `"` + replacer.Replace(s) + `"`
+}
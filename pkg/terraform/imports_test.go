@@ -0,0 +1,70 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		parts    []string
+		expected string
+	}{
+		{"function name", []string{"rosa-oidc-provisioner"}, "rosa_oidc_provisioner"},
+		{"joined parts", []string{"rosa-oidc-provisioner", "live"}, "rosa_oidc_provisioner_live"},
+		{"punctuation collapsed", []string{"/aws/lambda/Rosa--OIDC"}, "aws_lambda_rosa_oidc"},
+		{"leading digit", []string{"123abc"}, "r_123abc"},
+		{"empty", []string{"--"}, "resource"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ResourceName(tt.parts...))
+		})
+	}
+}
+
+func TestImports_UniqueAddresses(t *testing.T) {
+	var imports Imports
+	imports.Add("aws_iam_role", "rosa-provisioner", "rosa-provisioner")
+	imports.Add("aws_iam_role", "rosa_provisioner", "rosa_provisioner")
+	imports.Add("aws_lambda_function", "rosa-provisioner", "rosa-provisioner")
+
+	assert.Equal(t, []Import{
+		{Address: "aws_iam_role.rosa_provisioner", ID: "rosa-provisioner"},
+		{Address: "aws_iam_role.rosa_provisioner_2", ID: "rosa_provisioner"},
+		{Address: "aws_lambda_function.rosa_provisioner", ID: "rosa-provisioner"},
+	}, imports.List())
+}
+
+func TestWriteCommands(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, WriteCommands(&out, []Import{
+		{Address: "aws_cloudwatch_log_group.provisioner", ID: "/aws/lambda/provisioner"},
+		{Address: "aws_iam_role.odd", ID: "it's"},
+	}))
+	assert.Equal(t, "terraform import 'aws_cloudwatch_log_group.provisioner' '/aws/lambda/provisioner'\n"+
+		`terraform import 'aws_iam_role.odd' 'it'\''s'`+"\n", out.String())
+}
+
+func TestWriteImportBlocks(t *testing.T) {
+	var out strings.Builder
+	require.NoError(t, WriteImportBlocks(&out, []Import{
+		{Address: "aws_iam_role_policy.provisioner", ID: "rosa-role:Permissions"},
+		{Address: "aws_iam_role.odd", ID: `a"${b}`},
+	}))
+	assert.Equal(t, `import {
+  to = aws_iam_role_policy.provisioner
+  id = "rosa-role:Permissions"
+}
+
+import {
+  to = aws_iam_role.odd
+  id = "a\"$${b}"
+}
+`, out.String())
+}